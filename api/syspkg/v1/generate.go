@@ -0,0 +1,6 @@
+// Package syspkgv1 is the Go code of the syspkg gRPC API, generated from syspkg.proto: its messages, and the client
+// and server of PackageManagerService. The remote package implements the service for the package managers of a host,
+// served by syspkg-grpcd, and provides a client returning the types of the syspkg library.
+package syspkgv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative syspkg.proto
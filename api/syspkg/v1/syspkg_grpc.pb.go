@@ -0,0 +1,467 @@
+// Protocol buffer definition of the syspkg gRPC API, for the remote control of the package managers of a host by
+// orchestration systems.
+//
+// The messages mirror the types of the syspkg library (manager.PackageInfo, manager.Options, manager.ProgressEvent,
+// manager.Capabilities), and the services its interfaces. Changing operations stream their progress events before
+// their result.
+//
+// The Go code of this package is generated from this file with go generate, which runs protoc, protoc-gen-go and
+// protoc-gen-go-grpc. The syspkg-grpcd command serves the API, and the remote package implements it and calls it.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.27.1
+// source: syspkg.proto
+
+package syspkgv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PackageManagerService_ListPackageManagers_FullMethodName = "/syspkg.v1.PackageManagerService/ListPackageManagers"
+	PackageManagerService_Find_FullMethodName                = "/syspkg.v1.PackageManagerService/Find"
+	PackageManagerService_ListInstalled_FullMethodName       = "/syspkg.v1.PackageManagerService/ListInstalled"
+	PackageManagerService_ListUpgradable_FullMethodName      = "/syspkg.v1.PackageManagerService/ListUpgradable"
+	PackageManagerService_GetPackageInfo_FullMethodName      = "/syspkg.v1.PackageManagerService/GetPackageInfo"
+	PackageManagerService_Install_FullMethodName             = "/syspkg.v1.PackageManagerService/Install"
+	PackageManagerService_Delete_FullMethodName              = "/syspkg.v1.PackageManagerService/Delete"
+	PackageManagerService_Upgrade_FullMethodName             = "/syspkg.v1.PackageManagerService/Upgrade"
+	PackageManagerService_Refresh_FullMethodName             = "/syspkg.v1.PackageManagerService/Refresh"
+)
+
+// PackageManagerServiceClient is the client API for PackageManagerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PackageManagerService runs the operations of the package managers of a host.
+type PackageManagerServiceClient interface {
+	// ListPackageManagers returns the available package managers, with their capabilities.
+	ListPackageManagers(ctx context.Context, in *ListPackageManagersRequest, opts ...grpc.CallOption) (*ListPackageManagersResponse, error)
+	// Find searches packages matching keywords.
+	Find(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (*PackagesResponse, error)
+	// ListInstalled lists the installed packages.
+	ListInstalled(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*PackagesResponse, error)
+	// ListUpgradable lists the packages with a newer version available.
+	ListUpgradable(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*PackagesResponse, error)
+	// GetPackageInfo returns information about a package.
+	GetPackageInfo(ctx context.Context, in *GetPackageInfoRequest, opts ...grpc.CallOption) (*PackagesResponse, error)
+	// Install installs packages, streaming its progress.
+	Install(ctx context.Context, in *PackagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationUpdate], error)
+	// Delete removes packages, streaming its progress.
+	Delete(ctx context.Context, in *PackagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationUpdate], error)
+	// Upgrade upgrades packages, or all the upgradable packages if none are given, streaming its progress.
+	Upgrade(ctx context.Context, in *PackagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationUpdate], error)
+	// Refresh refreshes the package indexes.
+	Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*RefreshResponse, error)
+}
+
+type packageManagerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPackageManagerServiceClient(cc grpc.ClientConnInterface) PackageManagerServiceClient {
+	return &packageManagerServiceClient{cc}
+}
+
+func (c *packageManagerServiceClient) ListPackageManagers(ctx context.Context, in *ListPackageManagersRequest, opts ...grpc.CallOption) (*ListPackageManagersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPackageManagersResponse)
+	err := c.cc.Invoke(ctx, PackageManagerService_ListPackageManagers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packageManagerServiceClient) Find(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (*PackagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackagesResponse)
+	err := c.cc.Invoke(ctx, PackageManagerService_Find_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packageManagerServiceClient) ListInstalled(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*PackagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackagesResponse)
+	err := c.cc.Invoke(ctx, PackageManagerService_ListInstalled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packageManagerServiceClient) ListUpgradable(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*PackagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackagesResponse)
+	err := c.cc.Invoke(ctx, PackageManagerService_ListUpgradable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packageManagerServiceClient) GetPackageInfo(ctx context.Context, in *GetPackageInfoRequest, opts ...grpc.CallOption) (*PackagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackagesResponse)
+	err := c.cc.Invoke(ctx, PackageManagerService_GetPackageInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packageManagerServiceClient) Install(ctx context.Context, in *PackagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PackageManagerService_ServiceDesc.Streams[0], PackageManagerService_Install_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PackagesRequest, OperationUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PackageManagerService_InstallClient = grpc.ServerStreamingClient[OperationUpdate]
+
+func (c *packageManagerServiceClient) Delete(ctx context.Context, in *PackagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PackageManagerService_ServiceDesc.Streams[1], PackageManagerService_Delete_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PackagesRequest, OperationUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PackageManagerService_DeleteClient = grpc.ServerStreamingClient[OperationUpdate]
+
+func (c *packageManagerServiceClient) Upgrade(ctx context.Context, in *PackagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OperationUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PackageManagerService_ServiceDesc.Streams[2], PackageManagerService_Upgrade_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PackagesRequest, OperationUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PackageManagerService_UpgradeClient = grpc.ServerStreamingClient[OperationUpdate]
+
+func (c *packageManagerServiceClient) Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*RefreshResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshResponse)
+	err := c.cc.Invoke(ctx, PackageManagerService_Refresh_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PackageManagerServiceServer is the server API for PackageManagerService service.
+// All implementations must embed UnimplementedPackageManagerServiceServer
+// for forward compatibility.
+//
+// PackageManagerService runs the operations of the package managers of a host.
+type PackageManagerServiceServer interface {
+	// ListPackageManagers returns the available package managers, with their capabilities.
+	ListPackageManagers(context.Context, *ListPackageManagersRequest) (*ListPackageManagersResponse, error)
+	// Find searches packages matching keywords.
+	Find(context.Context, *FindRequest) (*PackagesResponse, error)
+	// ListInstalled lists the installed packages.
+	ListInstalled(context.Context, *ListRequest) (*PackagesResponse, error)
+	// ListUpgradable lists the packages with a newer version available.
+	ListUpgradable(context.Context, *ListRequest) (*PackagesResponse, error)
+	// GetPackageInfo returns information about a package.
+	GetPackageInfo(context.Context, *GetPackageInfoRequest) (*PackagesResponse, error)
+	// Install installs packages, streaming its progress.
+	Install(*PackagesRequest, grpc.ServerStreamingServer[OperationUpdate]) error
+	// Delete removes packages, streaming its progress.
+	Delete(*PackagesRequest, grpc.ServerStreamingServer[OperationUpdate]) error
+	// Upgrade upgrades packages, or all the upgradable packages if none are given, streaming its progress.
+	Upgrade(*PackagesRequest, grpc.ServerStreamingServer[OperationUpdate]) error
+	// Refresh refreshes the package indexes.
+	Refresh(context.Context, *RefreshRequest) (*RefreshResponse, error)
+	mustEmbedUnimplementedPackageManagerServiceServer()
+}
+
+// UnimplementedPackageManagerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPackageManagerServiceServer struct{}
+
+func (UnimplementedPackageManagerServiceServer) ListPackageManagers(context.Context, *ListPackageManagersRequest) (*ListPackageManagersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPackageManagers not implemented")
+}
+func (UnimplementedPackageManagerServiceServer) Find(context.Context, *FindRequest) (*PackagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Find not implemented")
+}
+func (UnimplementedPackageManagerServiceServer) ListInstalled(context.Context, *ListRequest) (*PackagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInstalled not implemented")
+}
+func (UnimplementedPackageManagerServiceServer) ListUpgradable(context.Context, *ListRequest) (*PackagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUpgradable not implemented")
+}
+func (UnimplementedPackageManagerServiceServer) GetPackageInfo(context.Context, *GetPackageInfoRequest) (*PackagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPackageInfo not implemented")
+}
+func (UnimplementedPackageManagerServiceServer) Install(*PackagesRequest, grpc.ServerStreamingServer[OperationUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method Install not implemented")
+}
+func (UnimplementedPackageManagerServiceServer) Delete(*PackagesRequest, grpc.ServerStreamingServer[OperationUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedPackageManagerServiceServer) Upgrade(*PackagesRequest, grpc.ServerStreamingServer[OperationUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method Upgrade not implemented")
+}
+func (UnimplementedPackageManagerServiceServer) Refresh(context.Context, *RefreshRequest) (*RefreshResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refresh not implemented")
+}
+func (UnimplementedPackageManagerServiceServer) mustEmbedUnimplementedPackageManagerServiceServer() {}
+func (UnimplementedPackageManagerServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafePackageManagerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PackageManagerServiceServer will
+// result in compilation errors.
+type UnsafePackageManagerServiceServer interface {
+	mustEmbedUnimplementedPackageManagerServiceServer()
+}
+
+func RegisterPackageManagerServiceServer(s grpc.ServiceRegistrar, srv PackageManagerServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPackageManagerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PackageManagerService_ServiceDesc, srv)
+}
+
+func _PackageManagerService_ListPackageManagers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPackageManagersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageManagerServiceServer).ListPackageManagers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageManagerService_ListPackageManagers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageManagerServiceServer).ListPackageManagers(ctx, req.(*ListPackageManagersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackageManagerService_Find_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageManagerServiceServer).Find(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageManagerService_Find_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageManagerServiceServer).Find(ctx, req.(*FindRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackageManagerService_ListInstalled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageManagerServiceServer).ListInstalled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageManagerService_ListInstalled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageManagerServiceServer).ListInstalled(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackageManagerService_ListUpgradable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageManagerServiceServer).ListUpgradable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageManagerService_ListUpgradable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageManagerServiceServer).ListUpgradable(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackageManagerService_GetPackageInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPackageInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageManagerServiceServer).GetPackageInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageManagerService_GetPackageInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageManagerServiceServer).GetPackageInfo(ctx, req.(*GetPackageInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackageManagerService_Install_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PackagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PackageManagerServiceServer).Install(m, &grpc.GenericServerStream[PackagesRequest, OperationUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PackageManagerService_InstallServer = grpc.ServerStreamingServer[OperationUpdate]
+
+func _PackageManagerService_Delete_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PackagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PackageManagerServiceServer).Delete(m, &grpc.GenericServerStream[PackagesRequest, OperationUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PackageManagerService_DeleteServer = grpc.ServerStreamingServer[OperationUpdate]
+
+func _PackageManagerService_Upgrade_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PackagesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PackageManagerServiceServer).Upgrade(m, &grpc.GenericServerStream[PackagesRequest, OperationUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PackageManagerService_UpgradeServer = grpc.ServerStreamingServer[OperationUpdate]
+
+func _PackageManagerService_Refresh_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackageManagerServiceServer).Refresh(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackageManagerService_Refresh_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackageManagerServiceServer).Refresh(ctx, req.(*RefreshRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PackageManagerService_ServiceDesc is the grpc.ServiceDesc for PackageManagerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PackageManagerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "syspkg.v1.PackageManagerService",
+	HandlerType: (*PackageManagerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPackageManagers",
+			Handler:    _PackageManagerService_ListPackageManagers_Handler,
+		},
+		{
+			MethodName: "Find",
+			Handler:    _PackageManagerService_Find_Handler,
+		},
+		{
+			MethodName: "ListInstalled",
+			Handler:    _PackageManagerService_ListInstalled_Handler,
+		},
+		{
+			MethodName: "ListUpgradable",
+			Handler:    _PackageManagerService_ListUpgradable_Handler,
+		},
+		{
+			MethodName: "GetPackageInfo",
+			Handler:    _PackageManagerService_GetPackageInfo_Handler,
+		},
+		{
+			MethodName: "Refresh",
+			Handler:    _PackageManagerService_Refresh_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Install",
+			Handler:       _PackageManagerService_Install_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Delete",
+			Handler:       _PackageManagerService_Delete_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Upgrade",
+			Handler:       _PackageManagerService_Upgrade_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "syspkg.proto",
+}
@@ -0,0 +1,83 @@
+package syspkg_test
+
+import (
+	"testing"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager/apk"
+	"github.com/bluet/syspkg/manager/apt"
+	"github.com/bluet/syspkg/manager/brew"
+	"github.com/bluet/syspkg/manager/cargo"
+	"github.com/bluet/syspkg/manager/conda"
+	"github.com/bluet/syspkg/manager/flatpak"
+	"github.com/bluet/syspkg/manager/fwupd"
+	"github.com/bluet/syspkg/manager/gem"
+	"github.com/bluet/syspkg/manager/gobin"
+	"github.com/bluet/syspkg/manager/nix"
+	"github.com/bluet/syspkg/manager/npm"
+	"github.com/bluet/syspkg/manager/pacman"
+	"github.com/bluet/syspkg/manager/pip"
+	"github.com/bluet/syspkg/manager/portage"
+	"github.com/bluet/syspkg/manager/snap"
+	"github.com/bluet/syspkg/manager/winget"
+	"github.com/bluet/syspkg/manager/zypper"
+)
+
+// TestCapabilities checks that the capabilities reported by the package managers match the optional interfaces they implement.
+func TestCapabilities(t *testing.T) {
+	pms := []syspkg.PackageManager{
+		&apk.PackageManager{}, &apt.PackageManager{}, &brew.PackageManager{}, &cargo.PackageManager{},
+		&conda.PackageManager{}, &flatpak.PackageManager{}, &fwupd.PackageManager{}, &gem.PackageManager{},
+		&gobin.PackageManager{}, &nix.PackageManager{}, &npm.PackageManager{}, &pacman.PackageManager{},
+		&pip.PackageManager{}, &portage.PackageManager{}, &snap.PackageManager{}, &winget.PackageManager{},
+		&zypper.PackageManager{},
+	}
+	for _, pm := range pms {
+		r, ok := pm.(syspkg.CapabilityReporter)
+		if !ok {
+			t.Errorf("%s does not implement CapabilityReporter", pm.GetPackageManager())
+			continue
+		}
+		got := r.Capabilities()
+
+		_, upgrade := pm.(syspkg.Upgrader)
+		_, downgrade := pm.(syspkg.Downgrader)
+		_, hold := pm.(syspkg.Holder)
+		_, security := pm.(syspkg.SecurityUpdateLister)
+		_, dependencies := pm.(syspkg.DependencyQuerier)
+		_, fileOwner := pm.(syspkg.FileOwnerQuerier)
+		_, fileList := pm.(syspkg.FileLister)
+		_, keys := pm.(syspkg.KeyManager)
+		_, download := pm.(syspkg.Downloader)
+		_, packageNames := pm.(syspkg.PackageNameLister)
+		_, localInstall := pm.(syspkg.LocalInstaller)
+		_, repositories := pm.(syspkg.RepositoryManager)
+		_, reboot := pm.(syspkg.RebootChecker)
+		_, verify := pm.(syspkg.Verifier)
+		_, groups := pm.(syspkg.GroupManager)
+		for _, c := range []struct {
+			name      string
+			got, want bool
+		}{
+			{"Upgrade", got.Upgrade, upgrade},
+			{"Downgrade", got.Downgrade, downgrade},
+			{"Hold", got.Hold, hold},
+			{"SecurityUpdates", got.SecurityUpdates, security},
+			{"Dependencies", got.Dependencies, dependencies},
+			{"FileOwner", got.FileOwner, fileOwner},
+			{"FileList", got.FileList, fileList},
+			{"Keys", got.Keys, keys},
+			{"Download", got.Download, download},
+			{"PackageNames", got.PackageNames, packageNames},
+			{"LocalInstall", got.LocalInstall, localInstall},
+			{"Repositories", got.Repositories, repositories},
+			{"Reboot", got.Reboot, reboot},
+			{"Verify", got.Verify, verify},
+			{"Groups", got.Groups, groups},
+		} {
+			if c.got != c.want {
+				t.Errorf("%s: Capabilities().%s = %v, want %v", pm.GetPackageManager(), c.name, c.got, c.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package syspkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Client is the high-level entry point of the library: it runs the operations of the selected package managers at
+// once, the queries concurrently, with a context interrupting them, and returns their results by package manager.
+// The package managers themselves remain available, for the operations the Client doesn't cover.
+//
+// Example:
+//
+//	client, err := syspkg.NewClient(syspkg.ClientOptions{Include: syspkg.IncludeOptions{AllAvailable: true}})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	results := client.Search(ctx, "vim")
+//	for _, pkg := range results.Packages() {
+//	    fmt.Println(pkg.PackageManager, pkg.Name, pkg.NewVersion)
+//	}
+//	if err := results.Err(); err != nil {
+//	    log.Print(err)
+//	}
+type Client struct {
+	sysPkg   SysPkg
+	pms      map[string]PackageManager
+	priority []string
+	policy   manager.InstallPolicy
+	opts     manager.Options
+}
+
+// ClientOptions configures a Client.
+type ClientOptions struct {
+	// Include selects the package managers of the client, as for New.
+	Include IncludeOptions
+
+	// Options are the options of every operation of the client; their Context is replaced by the one of the
+	// operation.
+	Options manager.Options
+
+	// InstallPolicy selects the package managers installing each package, as for ResolveInstall; the default,
+	// manager.InstallPolicyBest, installs each package with the package manager of highest priority providing it.
+	InstallPolicy manager.InstallPolicy
+}
+
+// Result is the result of an operation of a single package manager.
+type Result struct {
+	PackageManager string                `json:"package_manager"`
+	Packages       []manager.PackageInfo `json:"packages"`
+	Err            error                 `json:"-"`
+
+	// Operation is how the operation ran.
+	Operation OperationResult `json:"operation"`
+}
+
+// OperationResult is how an operation of a package manager ran: how long it took, to find the slow package managers,
+// and the commands it ran, to run them again manually.
+type OperationResult struct {
+	// Duration is how long the operation took; it is in seconds in JSON.
+	Duration time.Duration
+
+	// CommandLine is the command line of the first command of the operation that failed, or of its last command if
+	// none did, with the passwords of its URLs redacted; empty if it ran no command.
+	CommandLine string
+
+	// ExitCode is the exit code of the command of CommandLine, -1 if it was killed by a signal.
+	ExitCode int
+
+	// Commands are all the commands the operation ran, in order.
+	Commands []manager.CommandRecord
+}
+
+// newOperationResult returns the OperationResult of an operation that took duration, running the commands recorded
+// by transcript.
+func newOperationResult(duration time.Duration, transcript *manager.Transcript) OperationResult {
+	result := OperationResult{Duration: duration, Commands: transcript.Commands()}
+	for _, command := range result.Commands {
+		result.CommandLine, result.ExitCode = command.CommandLine, command.ExitCode
+		if command.ExitCode != 0 {
+			break
+		}
+	}
+	return result
+}
+
+// MarshalJSON returns the OperationResult as JSON, with its Duration in seconds.
+func (r OperationResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Duration    float64                 `json:"duration"`
+		CommandLine string                  `json:"command_line,omitempty"`
+		ExitCode    int                     `json:"exit_code"`
+		Commands    []manager.CommandRecord `json:"commands,omitempty"`
+	}{r.Duration.Seconds(), r.CommandLine, r.ExitCode, r.Commands})
+}
+
+// Results are the results of an operation of several package managers, sorted by package manager name.
+type Results []Result
+
+// Packages returns the packages of all the results, in the order of the results.
+func (r Results) Packages() []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	for _, result := range r {
+		packages = append(packages, result.Packages...)
+	}
+	return packages
+}
+
+// Err returns the errors of the package managers that failed, prefixed with their name, or nil if none failed.
+func (r Results) Err() error {
+	var errs []error
+	for _, result := range r {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.PackageManager, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewClient creates a Client with the package managers selected by opts.Include.
+func NewClient(opts ClientOptions) (*Client, error) {
+	s, err := newSysPkg(opts.Include)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{sysPkg: s, pms: s.pms, priority: opts.Include.Priority, policy: opts.InstallPolicy, opts: opts.Options}, nil
+}
+
+// SysPkg returns the SysPkg instance of the client, finding its package managers.
+func (c *Client) SysPkg() SysPkg {
+	return c.sysPkg
+}
+
+// PackageManagers returns the package managers of the client, by name.
+func (c *Client) PackageManagers() map[string]PackageManager {
+	return c.pms
+}
+
+// Search searches the packages matching keywords with every package manager.
+func (c *Client) Search(ctx context.Context, keywords ...string) Results {
+	return c.query(ctx, func(pm PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.Find(keywords, opts)
+	})
+}
+
+// ListInstalled lists the installed packages of every package manager.
+func (c *Client) ListInstalled(ctx context.Context) Results {
+	return c.query(ctx, func(pm PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.ListInstalled(opts)
+	})
+}
+
+// ListUpgradable lists the upgradable packages of every package manager.
+func (c *Client) ListUpgradable(ctx context.Context) Results {
+	return c.query(ctx, func(pm PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.ListUpgradable(opts)
+	})
+}
+
+// Refresh refreshes the package indexes of every package manager, one after the other.
+func (c *Client) Refresh(ctx context.Context) Results {
+	return c.run(ctx, InstallOrder(c.pms, c.priority), func(name string, pm PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return nil, pm.Refresh(opts)
+	})
+}
+
+// InstallPlan returns the changes installing pkgs would make, without making them: the plans of the package managers
+// installing them, following the install policy of the client. The packages no package manager provides are
+// reported in the returned error, along with the package managers failing to plan.
+func (c *Client) InstallPlan(ctx context.Context, pkgs ...string) ([]*manager.Plan, error) {
+	opts := c.opts.WithContext(ctx)
+	resolved, err := ResolveInstall(c.pms, pkgs, c.policy, c.priority, opts)
+	errs := []error{err}
+
+	var plans []*manager.Plan
+	for _, name := range InstallOrder(c.pms, c.priority) {
+		if len(resolved[name]) == 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return plans, errors.Join(append(errs, err)...)
+		}
+		plan, err := PlanInstall(c.pms[name], resolved[name], opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		plans = append(plans, plan)
+	}
+	return plans, errors.Join(errs...)
+}
+
+// Install installs pkgs with the package managers following the install policy of the client, one after the other.
+// The packages no package manager provides are reported in the returned error.
+func (c *Client) Install(ctx context.Context, pkgs ...string) (Results, error) {
+	resolved, err := ResolveInstall(c.pms, pkgs, c.policy, c.priority, c.opts.WithContext(ctx))
+	var names []string
+	for _, name := range InstallOrder(c.pms, c.priority) {
+		if len(resolved[name]) > 0 {
+			names = append(names, name)
+		}
+	}
+	return c.run(ctx, names, func(name string, pm PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.Install(resolved[name], opts)
+	}), err
+}
+
+// UpgradeAll upgrades all the packages of every package manager, one after the other.
+func (c *Client) UpgradeAll(ctx context.Context) Results {
+	return c.run(ctx, InstallOrder(c.pms, c.priority), func(name string, pm PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.UpgradeAll(opts)
+	})
+}
+
+// operation runs an operation of a package manager with the options of the client and the context ctx, recording
+// how it ran in its result, unless ctx is already done.
+func (c *Client) operation(ctx context.Context, name string, operation func(opts *manager.Options) ([]manager.PackageInfo, error)) Result {
+	result := Result{PackageManager: name, Err: ctx.Err()}
+	if result.Err != nil {
+		return result
+	}
+	opts := c.opts.WithContext(ctx)
+	opts.Transcript = &manager.Transcript{}
+	start := time.Now()
+	result.Packages, result.Err = operation(opts)
+	result.Operation = newOperationResult(time.Since(start), opts.Transcript)
+	return result
+}
+
+// query runs a query with every package manager concurrently, and returns their results.
+func (c *Client) query(ctx context.Context, query func(pm PackageManager, opts *manager.Options) ([]manager.PackageInfo, error)) Results {
+	results := make(Results, 0, len(c.pms))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, pm := range c.pms {
+		wg.Add(1)
+		go func(name string, pm PackageManager) {
+			defer wg.Done()
+			result := c.operation(ctx, name, func(opts *manager.Options) ([]manager.PackageInfo, error) {
+				return query(pm, opts)
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+		}(name, pm)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].PackageManager < results[j].PackageManager })
+	return results
+}
+
+// run runs an operation with the package managers names, one after the other, in order, and returns their results,
+// sorted by package manager name. The package managers not run yet when ctx is done fail with its error.
+func (c *Client) run(ctx context.Context, names []string, operation func(name string, pm PackageManager, opts *manager.Options) ([]manager.PackageInfo, error)) Results {
+	results := make(Results, 0, len(names))
+	for _, name := range names {
+		results = append(results, c.operation(ctx, name, func(opts *manager.Options) ([]manager.PackageInfo, error) {
+			return operation(name, c.pms[name], opts)
+		}))
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].PackageManager < results[j].PackageManager })
+	return results
+}
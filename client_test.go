@@ -0,0 +1,104 @@
+package syspkg_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+)
+
+// clientPackageManager is a package manager providing a fixed set of packages, recording the options it is run with.
+type clientPackageManager struct {
+	syspkg.PackageManager
+	name     string
+	packages []string
+	err      error
+	opts     []*manager.Options
+}
+
+func (pm *clientPackageManager) IsAvailable() bool         { return true }
+func (pm *clientPackageManager) GetPackageManager() string { return pm.name }
+
+func (pm *clientPackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{Search: true, DryRun: true}
+}
+
+func (pm *clientPackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	for _, name := range pm.packages {
+		if name == pkg {
+			return manager.PackageInfo{Name: name, PackageManager: pm.name}, nil
+		}
+	}
+	return manager.PackageInfo{}, errors.New("package not found")
+}
+
+func (pm *clientPackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	pm.opts = append(pm.opts, opts)
+	var found []manager.PackageInfo
+	for _, name := range pm.packages {
+		if name == keywords[0] {
+			found = append(found, manager.PackageInfo{Name: name, PackageManager: pm.name})
+		}
+	}
+	return found, pm.err
+}
+
+func (pm *clientPackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	pm.opts = append(pm.opts, opts)
+	var installed []manager.PackageInfo
+	for _, pkg := range pkgs {
+		installed = append(installed, manager.PackageInfo{Name: pkg, NewVersion: "1.0", Status: manager.PackageStatusInstalled, PackageManager: pm.name})
+	}
+	return installed, nil
+}
+
+func TestClient(t *testing.T) {
+	apt := &clientPackageManager{name: "apt", packages: []string{"vim", "htop"}}
+	snap := &clientPackageManager{name: "snap", packages: []string{"vim", "code"}, err: errors.New("snapd is not running")}
+	client, err := syspkg.NewClient(syspkg.ClientOptions{
+		Include: syspkg.IncludeOptions{AllAvailable: true, PackageManagers: map[string]syspkg.PackageManager{"apt": apt, "snap": snap}},
+		Options: manager.Options{Verbose: true},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %+v", err)
+	}
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "test")
+	results := client.Search(ctx, "vim")
+	expected := []manager.PackageInfo{{Name: "vim", PackageManager: "apt"}, {Name: "vim", PackageManager: "snap"}}
+	if !reflect.DeepEqual(results.Packages(), expected) {
+		t.Errorf("Search() = %+v, want %+v", results.Packages(), expected)
+	}
+	if err := results.Err(); err == nil || err.Error() != "snap: snapd is not running" {
+		t.Errorf("Search() error = %+v, want the error of snap", err)
+	}
+	if len(apt.opts) != 1 || apt.opts[0].Context != ctx || !apt.opts[0].Verbose {
+		t.Errorf("Search() ran apt with %+v, want the options of the client with the context", apt.opts)
+	}
+
+	plans, err := client.InstallPlan(ctx, "code", "htop", "emacs")
+	if err == nil {
+		t.Errorf("InstallPlan() error = nil, want an error for emacs")
+	}
+	expectedPlans := []*manager.Plan{
+		{PackageManager: "apt", Entries: []manager.PlanEntry{{Action: manager.PlanActionInstall, Name: "htop", NewVersion: "1.0"}}},
+		{PackageManager: "snap", Entries: []manager.PlanEntry{{Action: manager.PlanActionInstall, Name: "code", NewVersion: "1.0"}}},
+	}
+	if !reflect.DeepEqual(plans, expectedPlans) {
+		t.Errorf("InstallPlan() = %+v, want %+v", plans, expectedPlans)
+	}
+	if last := apt.opts[len(apt.opts)-1]; !last.DryRun {
+		t.Errorf("InstallPlan() ran apt with %+v, want a dry run", last)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	results, _ = client.Install(cancelled, "htop")
+	if len(results) != 1 || !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("Install() = %+v, want apt cancelled", results)
+	}
+}
@@ -0,0 +1,193 @@
+// Command syspkg-grpcd serves the syspkg gRPC API, defined in api/syspkg/v1, with the package managers of the host,
+// for their remote control by orchestration systems, through the client of the remote package.
+//
+// It listens on a Unix socket only root can connect to by default, as the API changes the packages of the system.
+// Listening on TCP requires mutual TLS, only serving the clients with a certificate signed by the CA given with
+// --tls-client-ca, unless --insecure is given, such as behind a proxy authenticating the clients.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/bluet/syspkg"
+	syspkgv1 "github.com/bluet/syspkg/api/syspkg/v1"
+	"github.com/bluet/syspkg/remote"
+)
+
+// DefaultListen is the address syspkg-grpcd listens on by default.
+const DefaultListen = "unix:///run/syspkg/grpcd.sock"
+
+func main() {
+	app := &cli.App{
+		Name:  "syspkg-grpcd",
+		Usage: "Serve the syspkg gRPC API with the package managers of this host",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "listen",
+				Usage: "Address to listen on: unix:///path/to/socket, or host:port for TCP",
+				Value: DefaultListen,
+			},
+			&cli.StringSliceFlag{
+				Name:  "manager",
+				Usage: "Package manager to serve, such as apt; may be repeated (default: all the available ones)",
+			},
+			&cli.StringFlag{
+				Name:  "tls-cert",
+				Usage: "Certificate `file` of the server, for TCP",
+			},
+			&cli.StringFlag{
+				Name:  "tls-key",
+				Usage: "Private key `file` of the server, for TCP",
+			},
+			&cli.StringFlag{
+				Name:  "tls-client-ca",
+				Usage: "Certificate `file` of the CA signing the certificates of the clients allowed to connect, for TCP",
+			},
+			&cli.BoolFlag{
+				Name:  "insecure",
+				Usage: "Listen on TCP without authenticating the clients with TLS",
+			},
+		},
+		Action: serve,
+	}
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// serve serves the API until syspkg-grpcd is interrupted.
+func serve(c *cli.Context) error {
+	pms, err := packageManagers(c.StringSlice("manager"))
+	if err != nil {
+		return err
+	}
+
+	var opts []grpc.ServerOption
+	network, address := listenAddress(c.String("listen"))
+	if network == "tcp" && !c.Bool("insecure") && c.String("tls-client-ca") == "" {
+		return errors.New("listening on TCP requires mutual TLS, with --tls-cert, --tls-key and --tls-client-ca, or --insecure")
+	}
+	if c.String("tls-cert") != "" || c.String("tls-key") != "" || c.String("tls-client-ca") != "" {
+		creds, err := serverCredentials(c.String("tls-cert"), c.String("tls-key"), c.String("tls-client-ca"))
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	l, err := listen(network, address)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer(opts...)
+	syspkgv1.RegisterPackageManagerServiceServer(server, &remote.Server{PackageManagers: pms})
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		// the running operations are interrupted through the contexts of their calls
+		server.Stop()
+	}()
+
+	log.Printf("Serving %d package managers on %s", len(pms), c.String("listen"))
+	return server.Serve(l)
+}
+
+// serverCredentials returns the TLS credentials of the server, with the certificate and key in certFile and keyFile,
+// requiring the clients to present a certificate signed by the CA in clientCAFile, if set.
+func serverCredentials(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCAFile != "" {
+		data, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", clientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// packageManagers returns the available package managers names, or all of them if none are given.
+func packageManagers(names []string) (map[string]syspkg.PackageManager, error) {
+	include := syspkg.IncludeOptions{AllAvailable: true}
+	s, err := syspkg.New(include)
+	if err != nil {
+		return nil, err
+	}
+	available, err := s.FindPackageManagers(include)
+	if err != nil || len(names) == 0 {
+		return available, err
+	}
+
+	pms := make(map[string]syspkg.PackageManager, len(names))
+	for _, name := range names {
+		pm, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("package manager %s is not available", name)
+		}
+		pms[name] = pm
+	}
+	return pms, nil
+}
+
+// listenAddress returns the network and address of listen: a Unix socket for unix:// addresses, TCP otherwise.
+func listenAddress(listen string) (string, string) {
+	if path, ok := strings.CutPrefix(listen, "unix://"); ok {
+		return "unix", path
+	}
+	if path, ok := strings.CutPrefix(listen, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", listen
+}
+
+// listen listens on address. Unix sockets replace a stale socket left by a previous server, and only their owner, root
+// when running as root, can connect to them.
+func listen(network, address string) (net.Listener, error) {
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+	if err := os.MkdirAll(filepath.Dir(address), 0o755); err != nil {
+		return nil, err
+	}
+	if conn, err := net.Dial("unix", address); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another server is listening on %s", address)
+	}
+	_ = os.Remove(address)
+
+	l, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, 0o600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/bluet/syspkg"
+	syspkgv1 "github.com/bluet/syspkg/api/syspkg/v1"
+	"github.com/bluet/syspkg/remote"
+)
+
+// testCertificate returns a certificate and its key, signed by parent, or self-signed if parent is nil.
+func testCertificate(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, any(key)
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// writePEM writes the certificate, and its key if keyFile is set, to PEM files.
+func writePEM(t *testing.T, cert tls.Certificate, certFile, keyFile string) {
+	t.Helper()
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile == "" {
+		return
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerCredentials(t *testing.T) {
+	dir := t.TempDir()
+	ca, otherCA := testCertificate(t, "ca", nil), testCertificate(t, "other-ca", nil)
+	certFile, keyFile, caFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt")
+	writePEM(t, testCertificate(t, "localhost", &ca), certFile, keyFile)
+	writePEM(t, ca, caFile, "")
+
+	creds, err := serverCredentials(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatalf("serverCredentials() error = %+v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.Creds(creds))
+	syspkgv1.RegisterPackageManagerServiceServer(server, &remote.Server{PackageManagers: map[string]syspkg.PackageManager{}})
+	go func() { _ = server.Serve(l) }()
+	defer server.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	tests := []struct {
+		name    string
+		certs   []tls.Certificate
+		wantErr bool
+	}{
+		{name: "client certificate signed by the CA", certs: []tls.Certificate{testCertificate(t, "client", &ca)}},
+		{name: "client certificate signed by another CA", certs: []tls.Certificate{testCertificate(t, "client", &otherCA)}, wantErr: true},
+		{name: "no client certificate", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &tls.Config{RootCAs: roots, Certificates: tt.certs, ServerName: "localhost", MinVersion: tls.VersionTLS12}
+			client, err := remote.Dial(l.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(config)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := client.PackageManagers(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("PackageManagers() error = %+v, wantErr %+v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := serverCredentials(certFile, keyFile, keyFile); err == nil {
+		t.Errorf("serverCredentials() error = nil, want an error for a client CA file without certificates")
+	}
+}
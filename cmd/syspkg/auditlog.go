@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/auditlog"
+)
+
+// auditLog is the audit log configured with audit_log, opened on the first recorded operation; nil if disabled.
+var (
+	auditLog     *auditlog.Log
+	auditLogOnce sync.Once
+)
+
+// recordOperation records an operation of package manager pm on packages, which returned err, in the audit log.
+// Dry runs and unsupported operations, which change nothing, are not recorded.
+func recordOperation(pm string, operation string, packages []string, err error, opts *manager.Options) {
+	if opts.DryRun || errors.Is(err, manager.ErrOperationNotSupported) {
+		return
+	}
+
+	auditLogOnce.Do(func() {
+		l, err := auditlog.Open(cfg.AuditLog)
+		if err != nil {
+			log.Printf("Error while opening the audit log %s: %+v\n", cfg.AuditLog, err)
+			return
+		}
+		auditLog = l
+	})
+	if err := auditLog.Record(auditlog.Entry{PackageManager: pm, Operation: operation, Packages: packages}, err); err != nil {
+		log.Printf("Error while writing the audit log: %+v\n", err)
+	}
+}
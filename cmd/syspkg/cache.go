@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/daemon"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/cache"
+)
+
+// queryCache caches the results of the queries of the CLI, such as searches; it is nil, caching nothing, with --no-cache.
+var queryCache *cache.Cache
+
+// setupCache sets up the query cache, unless it is disabled with --no-cache or in the configuration.
+func setupCache(c *cli.Context) {
+	if c.Bool("no-cache") || cfg.CacheTTL < 0 {
+		queryCache = nil
+		return
+	}
+	queryCache = cache.New(cache.DefaultDir(), cfg.CacheTTL)
+}
+
+// daemonClient is the client of the syspkg daemon, if one is running; see dialDaemon.
+var (
+	daemonClient *daemon.Client
+	daemonOnce   sync.Once
+)
+
+// dialDaemon returns the client of the syspkg daemon, connecting to it on first use, or nil if none is running.
+func dialDaemon() *daemon.Client {
+	daemonOnce.Do(func() {
+		if client, err := daemon.Dial(); err == nil {
+			daemonClient = client
+		}
+	})
+	return daemonClient
+}
+
+// cachedQuery returns the result of query op of package manager pm, with arguments args: from the daemon if one is
+// running and serves pm, otherwise from the query cache, running query and caching its result on a miss.
+func cachedQuery(pm syspkg.PackageManager, op daemon.Op, opts *manager.Options, args []string, query func() ([]manager.PackageInfo, error)) ([]manager.PackageInfo, error) {
+	name := pm.GetPackageManager()
+	// --no-cache also bypasses the daemon, to get fresh results, and so do the queries of licenses, which it doesn't read
+	if queryCache != nil && !opts.Licenses {
+		if client := dialDaemon(); client != nil && client.Serves(name) {
+			packages, err := client.Query(daemon.Request{Op: op, PackageManager: name, Args: args, Environment: opts.Environment, Scope: opts.Scope})
+			if !errors.Is(err, daemon.ErrRequestFailed) {
+				return packages, err
+			}
+			log.Printf("Error while querying the daemon, querying %s directly: %+v\n", name, err)
+		}
+	}
+	return queryCache.Query(name, string(op), cacheKey(opts, args...), query)
+}
+
+// cacheKey returns the key of a cached query with arguments args, which also depends on the environment and the
+// scope operated on, and on whether licenses are read.
+func cacheKey(opts *manager.Options, args ...string) []string {
+	if opts.Environment != "" {
+		args = append(args, "\x00env="+opts.Environment)
+	}
+	if opts.Scope != "" {
+		args = append(args, "\x00scope="+string(opts.Scope))
+	}
+	if opts.Licenses {
+		args = append(args, "\x00licenses")
+	}
+	return args
+}
+
+// invalidateCache removes the cached results of the given package managers after running command,
+// if it may have changed their packages.
+func invalidateCache(command string, dryRun bool, pms map[string]syspkg.PackageManager) {
+	if !privilegedCommands[command] || dryRun {
+		return
+	}
+	// the cache of the user running syspkg is invalidated, even when the command was run as root
+	userCache := queryCache
+	if userCache == nil {
+		userCache = cache.New(cache.DefaultDir(), cfg.CacheTTL)
+	}
+	for name := range pms {
+		if err := userCache.Invalidate(name); err != nil {
+			log.Printf("Error while invalidating the cache of %s: %+v\n", name, err)
+		}
+	}
+	if client := dialDaemon(); client != nil {
+		var names []string
+		for name := range pms {
+			names = append(names, name)
+		}
+		if err := client.Invalidate(names...); err != nil {
+			log.Printf("Error while invalidating the results of the daemon: %+v\n", err)
+		}
+	}
+}
+
+// printCacheStats prints the statistics of the query cache, by package manager.
+func printCacheStats(c *cli.Context) error {
+	stats, err := cache.New(cache.DefaultDir(), cfg.CacheTTL).Stats()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := newOutputFormatter(c, "cache stats")
+	for _, name := range names {
+		s := stats[name]
+		start := out.Start(name)
+		if out.Add(name, nil, nil, start).Cache = &s; out.JSON {
+			continue
+		}
+		fmt.Printf("%s: %d entries (%d expired), %d bytes, %d hits, %d misses\n", name, s.Entries, s.Expired, s.Bytes, s.Hits, s.Misses)
+	}
+	if len(names) == 0 && !out.JSON {
+		fmt.Println("The cache is empty.")
+	}
+	return out.Flush()
+}
+
+// runDaemon serves the queries of the given package managers over the Unix socket given with --socket,
+// until syspkg is interrupted.
+func runDaemon(c *cli.Context, pms map[string]syspkg.PackageManager, opts *manager.Options) error {
+	server := &daemon.Server{
+		PackageManagers: pms,
+		Options:         opts,
+		TTL:             cfg.CacheTTL,
+		RefreshInterval: c.Duration("refresh-interval"),
+		RefreshIndexes:  c.Bool("refresh-indexes"),
+		Concurrency:     cfg.Concurrency,
+		SocketGroup:     c.String("socket-group"),
+	}
+	if server.TTL < 0 {
+		server.TTL = 0
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		_ = server.Close()
+	}()
+
+	log.Printf("Serving %d package managers on %s\n", len(pms), c.String("socket"))
+	return server.ListenAndServe(c.String("socket"))
+}
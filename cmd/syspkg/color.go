@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Modes of the --color flag.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// ANSI colors of the status of the packages.
+const (
+	colorGreen  = "32"
+	colorYellow = "33"
+	colorRed    = "31"
+)
+
+// validateColorMode checks the value of the --color flag.
+func validateColorMode(c *cli.Context, mode string) error {
+	switch mode {
+	case colorAuto, colorAlways, colorNever:
+		return nil
+	}
+	return fmt.Errorf("unknown color mode %q, expected %s, %s or %s", mode, colorAuto, colorAlways, colorNever)
+}
+
+// colorEnabled reports whether text output is colored: always with --color always, never with --color never, and
+// by default only when the standard output is a terminal other than dumb, and NO_COLOR isn't set (https://no-color.org).
+func colorEnabled(c *cli.Context) bool {
+	switch c.String("color") {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	return os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && isTerminal(os.Stdout)
+}
+
+// statusColor returns the ANSI color of the status of a package: green if installed, yellow if upgradable, red if
+// broken, and none otherwise.
+func statusColor(status manager.PackageStatus) string {
+	switch status {
+	case manager.PackageStatusInstalled:
+		return colorGreen
+	case manager.PackageStatusUpgradable:
+		return colorYellow
+	case manager.PackageStatusBroken:
+		return colorRed
+	}
+	return ""
+}
+
+// colorize returns s in the ANSI color, if any.
+func colorize(s string, color string) string {
+	if color == "" || s == "" {
+		return s
+	}
+	return "\033[" + color + "m" + s + "\033[0m"
+}
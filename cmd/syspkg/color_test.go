@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		mode string
+		want bool
+	}{
+		{mode: colorAlways, want: true},
+		{mode: colorNever, want: false},
+		// the standard output of the tests isn't a terminal
+		{mode: colorAuto, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			set := flag.NewFlagSet("syspkg", flag.ContinueOnError)
+			set.String("color", tt.mode, "")
+			if got := colorEnabled(cli.NewContext(cli.NewApp(), set, nil)); got != tt.want {
+				t.Errorf("colorEnabled() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorize(t *testing.T) {
+	tests := []struct {
+		name   string
+		status manager.PackageStatus
+		want   string
+	}{
+		{name: "installed", status: manager.PackageStatusInstalled, want: "\033[32minstalled\033[0m"},
+		{name: "upgradable", status: manager.PackageStatusUpgradable, want: "\033[33mupgradable\033[0m"},
+		{name: "broken", status: manager.PackageStatusBroken, want: "\033[31mbroken\033[0m"},
+		{name: "available", status: manager.PackageStatusAvailable, want: "available"},
+		{name: "empty", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := colorize(string(tt.status), statusColor(tt.status)); got != tt.want {
+				t.Errorf("colorize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/cache"
+)
+
+// completionCacheTTL is how long the package names cached for shell completion are used before being listed again.
+const completionCacheTTL = time.Hour
+
+// Kinds of package names completed by the commands.
+const (
+	namesAvailable = "available"
+	namesInstalled = "installed"
+)
+
+// completedNames are the kinds of package names completed by the commands taking package names, by full command name.
+var completedNames = map[string]string{
+	"install":      namesAvailable,
+	"download":     namesAvailable,
+	"find":         namesAvailable,
+	"deps":         namesAvailable,
+	"tui":          namesAvailable,
+	"show package": namesAvailable,
+	"delete":       namesInstalled,
+	"upgrade":      namesInstalled,
+	"downgrade":    namesInstalled,
+	"hold":         namesInstalled,
+	"unhold":       namesInstalled,
+	"files":        namesInstalled,
+}
+
+// completionScripts are the completion scripts of the shells, calling syspkg __complete with the words of the
+// command line before the cursor, and the word being completed.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for syspkg, load it with: source <(syspkg completion bash)
+_syspkg() {
+	local cur=${COMP_WORDS[COMP_CWORD]}
+	local IFS=$'\n'
+	COMPREPLY=($(syspkg __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" "$cur" 2>/dev/null))
+}
+complete -o default -F _syspkg syspkg
+`,
+	"zsh": `#compdef syspkg
+# zsh completion for syspkg, load it with: source <(syspkg completion zsh)
+_syspkg() {
+	local -a completions
+	completions=(${(f)"$(syspkg __complete ${words[2,CURRENT-1]} ${words[CURRENT]} 2>/dev/null)"})
+	if (( ${#completions} )); then
+		compadd -a completions
+	else
+		_files
+	fi
+}
+compdef _syspkg syspkg
+`,
+	"fish": `# fish completion for syspkg, load it with: syspkg completion fish | source
+function __syspkg_complete
+	set -l tokens (commandline -opc) (commandline -ct)
+	syspkg __complete $tokens[2..-1] 2>/dev/null
+end
+complete -c syspkg -f -a '(__syspkg_complete)'
+`,
+}
+
+// printCompletionScript prints the completion script of a shell.
+func printCompletionScript(shell string) error {
+	script, ok := completionScripts[shell]
+	if !ok {
+		return fmt.Errorf("unknown shell %q, expected bash, zsh or fish", shell)
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// complete prints the completions of the last of words, given the words of the command line before it:
+// the flags or subcommands of the command being typed, or the package names it takes.
+func complete(c *cli.Context, s syspkg.SysPkg, pms map[string]syspkg.PackageManager, words []string) {
+	prefix := ""
+	if len(words) > 0 {
+		prefix, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	// find the command being typed, and the package managers selected with flags
+	var cmd *cli.Command
+	var names []string
+	selected := make(map[string]syspkg.PackageManager)
+	for _, word := range words {
+		if strings.HasPrefix(word, "--") {
+			if pm, ok := pms[strings.TrimPrefix(word, "--")]; ok {
+				selected[strings.TrimPrefix(word, "--")] = pm
+			}
+			continue
+		}
+		if strings.HasPrefix(word, "-") {
+			continue
+		}
+		var next *cli.Command
+		if cmd == nil {
+			next = c.App.Command(word)
+		} else {
+			next = cmd.Command(word)
+		}
+		if next == nil {
+			break
+		}
+		cmd = next
+		names = append(names, cmd.Name)
+	}
+
+	var candidates []string
+	switch {
+	case strings.HasPrefix(prefix, "-"):
+		flags := c.App.Flags
+		if cmd != nil {
+			flags = cmd.Flags
+		}
+		for _, flag := range flags {
+			for _, name := range flag.Names() {
+				if len(name) > 1 {
+					candidates = append(candidates, "--"+name)
+				}
+			}
+		}
+	case cmd == nil || len(cmd.Subcommands) > 0:
+		commands := c.App.Commands
+		if cmd != nil {
+			commands = cmd.Subcommands
+		}
+		for _, command := range commands {
+			if !command.Hidden {
+				candidates = append(candidates, command.Name)
+			}
+		}
+	default:
+		kind, ok := completedNames[strings.Join(names, " ")]
+		if !ok {
+			return
+		}
+		if len(selected) == 0 {
+			selected = filterPackageManager(s, pms, c)
+		}
+		for _, pm := range selected {
+			candidates = append(candidates, cachedPackageNames(pm, kind)...)
+		}
+	}
+
+	sort.Strings(candidates)
+	last := ""
+	for _, candidate := range candidates {
+		if candidate != last && strings.HasPrefix(candidate, prefix) {
+			fmt.Println(candidate)
+		}
+		last = candidate
+	}
+}
+
+// cachedPackageNames returns the names of the available or installed packages of a package manager, from the
+// query cache if they were listed recently enough, so that completing package names is fast.
+// Package managers that can't list the names of the available packages have no available names.
+func cachedPackageNames(pm syspkg.PackageManager, kind string) []string {
+	namesCache := cache.New(cache.DefaultDir(), completionCacheTTL)
+	var names []string
+	if namesCache.Get(pm.GetPackageManager(), "names-"+kind, nil, &names) {
+		return names
+	}
+
+	opts := &manager.Options{Timeout: 10 * time.Second}
+	if kind == namesInstalled {
+		packages, err := pm.ListInstalled(opts)
+		if err != nil {
+			return nil
+		}
+		for _, pkg := range packages {
+			names = append(names, pkg.Name)
+		}
+	} else {
+		lister, ok := pm.(syspkg.PackageNameLister)
+		if !ok {
+			return nil
+		}
+		var err error
+		if names, err = lister.ListPackageNames(opts); err != nil {
+			return nil
+		}
+	}
+
+	// the cache is only an optimization, so failing to write it is not an error
+	_ = namesCache.Put(pm.GetPackageManager(), "names-"+kind, nil, names)
+	return names
+}
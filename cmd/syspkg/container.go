@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/container"
+)
+
+// target is the container or the root filesystem the commands of the package managers run in, given with
+// --container or --root, or nil to run them on this host.
+var target *container.Target
+
+// setupTarget sets up the container or the root filesystem given with --container or --root, and returns the
+// package managers available there, or nil if neither is given. The results of the target aren't cached, not being
+// the ones of this host.
+func setupTarget(c *cli.Context) (syspkg.SysPkg, map[string]syspkg.PackageManager, error) {
+	if !c.IsSet("container") && !c.IsSet("root") {
+		return nil, nil, nil
+	}
+	t := &container.Target{Container: c.String("container"), Root: c.String("root")}
+	if err := t.Validate(); err != nil {
+		return nil, nil, err
+	}
+	p, err := t.Platform()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := syspkg.New(syspkg.IncludeOptions{AllAvailable: true, Platform: &p, Available: t.Available})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", t, err)
+	}
+	pms, err := s.FindPackageManagers(syspkg.IncludeOptions{AllAvailable: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", t, err)
+	}
+	target, queryCache = t, nil
+	return s, pms, nil
+}
+
+// installRootPackageManagers returns the package managers of pms operating on the system whose root directory is
+// given with --install-root, such as an image being built, the ones that can't being left out.
+func installRootPackageManagers(c *cli.Context, pms map[string]syspkg.PackageManager) (map[string]syspkg.PackageManager, error) {
+	if c.IsSet("container") || c.IsSet("root") {
+		return nil, errors.New("--install-root can't be used with --container or --root")
+	}
+	root := c.String("install-root")
+	if !filepath.IsAbs(root) {
+		return nil, fmt.Errorf("--install-root must be an absolute path, got %s", root)
+	}
+	if info, err := os.Stat(root); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("--install-root: %s is not a directory", root)
+	}
+
+	rooted := supportingPackageManagers(pms, func(c manager.Capabilities) bool { return c.RootDir })
+	if len(rooted) == 0 {
+		return nil, errors.New("no available package manager supports --install-root")
+	}
+	// the results of the other system aren't the ones of this host
+	queryCache = nil
+	return rooted, nil
+}
+
+// supportingPackageManagers returns the package managers of pms whose capabilities are supported.
+func supportingPackageManagers(pms map[string]syspkg.PackageManager, supported func(manager.Capabilities) bool) map[string]syspkg.PackageManager {
+	supporting := make(map[string]syspkg.PackageManager)
+	for name, pm := range pms {
+		if supported(syspkg.CapabilitiesOf(pm)) {
+			supporting[name] = pm
+		}
+	}
+	return supporting
+}
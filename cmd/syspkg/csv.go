@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// csvHeader are the columns of the CSV rows of the packages, named as the fields of the JSON output.
+var csvHeader = []string{"package_manager", "name", "version", "new_version", "status", "category", "arch", "origin"}
+
+// writeCSV writes the packages of results to w as CSV, for spreadsheets: the csvHeader row, then a row per package, of
+// the package managers sorted by name, then in the order of their results. The errors of the package managers that
+// failed are written to errw, keeping w to the rows.
+func writeCSV(w io.Writer, errw io.Writer, results map[string]*Result) error {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, name := range names {
+		result := results[name]
+		if result.Error != "" && !result.Unsupported {
+			fmt.Fprintf(errw, "%s: %s\n", name, result.Error)
+		}
+		for _, pkg := range result.Packages {
+			if err := cw.Write([]string{name, pkg.Name, pkg.Version, pkg.NewVersion, string(pkg.Status), pkg.Category, pkg.Arch, pkg.Origin}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	var w, errw bytes.Buffer
+	if err := writeCSV(&w, &errw, testResults()); err != nil {
+		t.Fatalf("writeCSV() error = %+v", err)
+	}
+	want := "package_manager,name,version,new_version,status,category,arch,origin\n" +
+		"apt,vim,2:9.1.0016-1,2:9.1.0016-2,upgradable,noble-updates,amd64,Ubuntu\n" +
+		"apt,\"odd\tname, \"\"quoted\"\"\",,,available,,,\n" +
+		"snap,code,1.95.3,,installed,stable,,\n"
+	if got := w.String(); got != want {
+		t.Errorf("writeCSV() wrote %q, want %q", got, want)
+	}
+	if got, want := errw.String(), "brew: brew: command failed\n"; got != want {
+		t.Errorf("writeCSV() wrote the errors %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/config"
+)
+
+// privilegedCommands are the commands changing the system, which need root privileges with system package managers.
+var privilegedCommands = map[string]bool{
+	"install":          true,
+	"install-local":    true,
+	"delete":           true,
+	"refresh":          true,
+	"upgrade":          true,
+	"downgrade":        true,
+	"download":         true,
+	"hold":             true,
+	"unhold":           true,
+	"apply":            true,
+	"module":           true,
+	"history rollback": true,
+	"snapshot restore": true,
+	"key import":       true,
+	"key remove":       true,
+	"repo add":         true,
+	"group install":    true,
+	"group remove":     true,
+	"repo remove":      true,
+	"tui":              true,
+	// reading the memory maps of the processes of other users needs root privileges too
+	"restarts": true,
+}
+
+// systemCommands are the commands changing the system whatever the package managers, which always need root privileges.
+var systemCommands = map[string]bool{
+	"schedule install": true,
+	"schedule remove":  true,
+}
+
+// privilegedCategories are the categories of package managers that need root privileges to change the system, such
+// as snap, and Flatpak unless installing per user.
+var privilegedCategories = []manager.Category{manager.CategorySystem, manager.CategoryUniversal, manager.CategoryFirmware}
+
+// escalatedEnv are the environment variables passed to syspkg re-executed as root: the settings that only select
+// what the command does, as the command line does. The configuration file, whose hooks run shell commands, the audit
+// log, written to, and the fixtures of SYSPKG_RECORD and SYSPKG_REPLAY aren't passed, so that users allowed to run
+// syspkg as root can't run other commands or write other files as root through them; nor are the unescalatedFlags.
+var escalatedEnv = []string{
+	"SYSPKG_MANAGERS",
+	"SYSPKG_EXCLUDE",
+	"SYSPKG_PRIORITY",
+	"SYSPKG_INSTALL_POLICY",
+	"SYSPKG_TIMEOUT",
+	"SYSPKG_LOCK_WAIT",
+	"SYSPKG_AUTO_REFRESH",
+	"SYSPKG_PROXY",
+	"SYSPKG_NO_PROXY",
+	"SYSPKG_ASSUME_YES",
+	"SYSPKG_OUTPUT",
+	"SYSPKG_CONCURRENCY",
+	"SYSPKG_CACHE_TTL",
+}
+
+// unescalatedFlags are the flags refused when re-executing syspkg as root: the files written or read, the
+// configuration file, and the other systems operated on, for the same reason as escalatedEnv.
+var unescalatedFlags = []string{
+	"config",
+	"root",
+	"install-root",
+	"container",
+	"hosts",
+	"webhook",
+	"report-file",
+	"dir",
+	"checksums",
+}
+
+// sudoMode returns the privilege escalation mode: the --sudo flag, the configured one, or auto.
+func sudoMode(c *cli.Context) string {
+	if c.IsSet("sudo") {
+		return c.String("sudo")
+	}
+	if cfg.Sudo != "" {
+		return cfg.Sudo
+	}
+	return config.SudoAuto
+}
+
+// validateScope checks the value of the --scope flag.
+func validateScope(c *cli.Context, scope string) error {
+	switch manager.Scope(scope) {
+	case manager.ScopeUser, manager.ScopeSystem:
+		return nil
+	}
+	return fmt.Errorf("unknown scope %q, expected %s or %s", scope, manager.ScopeUser, manager.ScopeSystem)
+}
+
+// validateSudoMode checks the value of the --sudo flag.
+func validateSudoMode(c *cli.Context, mode string) error {
+	switch mode {
+	case config.SudoAuto, config.SudoNever, config.SudoAlways:
+		return nil
+	}
+	return fmt.Errorf("unknown sudo mode %q, expected %s, %s or %s", mode, config.SudoAuto, config.SudoNever, config.SudoAlways)
+}
+
+// escalate re-executes syspkg as root, with sudo, doas or pkexec, and exits with its exit status, if the command needs it:
+// with --sudo=always for every command, and with --sudo=auto for the commands changing the system with system package managers.
+// It returns without doing anything when running as root, or with --sudo=never.
+func escalate(c *cli.Context, s syspkg.SysPkg, pms map[string]syspkg.PackageManager) error {
+	mode := sudoMode(c)
+	if mode == config.SudoNever || manager.IsPrivileged() {
+		return nil
+	}
+	if os.Getenv("SYSPKG_ESCALATED") != "" {
+		return errors.New("privilege escalation did not grant root privileges")
+	}
+
+	command, help := invokedCommand(c)
+	if command == "" || help {
+		return nil
+	}
+	if mode == config.SudoAuto && !systemCommands[command] && (!privilegedCommands[command] || c.Bool("dry-run") || !usesPrivilegedManager(c, s, pms)) {
+		return nil
+	}
+
+	if err := checkEscalatedArgs(os.Args[1:]); err != nil {
+		return err
+	}
+	prefix, err := manager.EscalationCommand(!isTerminal(os.Stdin))
+	if err != nil {
+		return fmt.Errorf("%w; run syspkg as root, or with --sudo=never", err)
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// escalation tools reset the environment, so the settings of syspkg are passed through env
+	args := append(prefix[1:], "env", "SYSPKG_ESCALATED=1")
+	for _, name := range escalatedEnv {
+		if value := os.Getenv(name); value != "" {
+			args = append(args, name+"="+value)
+		}
+	}
+	args = append(append(args, executable), os.Args[1:]...)
+
+	cmd := exec.Command(prefix[0], args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err = cmd.Run()
+	invalidateCache(command, c.Bool("dry-run"), withTargets(filterPackageManager(s, pms, c), pms, c.Args().Slice()))
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
+
+// checkEscalatedArgs returns an error if the command line args, passed to syspkg re-executed as root, set one of the
+// unescalatedFlags.
+func checkEscalatedArgs(args []string) error {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if slices.Contains(unescalatedFlags, name) {
+			return fmt.Errorf("--%s is not passed to syspkg re-executed as root; run syspkg as root to use it", name)
+		}
+	}
+	return nil
+}
+
+// invokedCommand returns the full name of the command invoked on the command line, such as "install" or "key import",
+// resolving aliases, and whether help is requested.
+func invokedCommand(c *cli.Context) (string, bool) {
+	var names []string
+	var cmd *cli.Command
+	help := false
+	for _, arg := range c.Args().Slice() {
+		if arg == "-h" || arg == "--help" || arg == "help" {
+			help = true
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		var next *cli.Command
+		if cmd == nil {
+			next = c.App.Command(arg)
+		} else {
+			next = cmd.Command(arg)
+		}
+		if next == nil {
+			// the arguments of the command, such as the package names
+			break
+		}
+		cmd = next
+		names = append(names, cmd.Name)
+	}
+	return strings.Join(names, " "), help
+}
+
+// usesPrivilegedManager reports whether one of the selected package managers needs root privileges to change the system.
+func usesPrivilegedManager(c *cli.Context, s syspkg.SysPkg, pms map[string]syspkg.PackageManager) bool {
+	// an error only means no package manager of these categories is available
+	privileged, _ := s.FindPackageManagers(syspkg.IncludeOptions{Categories: privilegedCategories})
+	// the packages targeted at a package manager, such as apt:vim, are installed by it, selected or not
+	for name, pm := range withTargets(filterPackageManager(s, pms, c), pms, c.Args().Slice()) {
+		// the per-user installations of package managers belong to the user
+		if manager.Scope(c.String("scope")) == manager.ScopeUser && syspkg.CapabilitiesOf(pm).Scope {
+			continue
+		}
+		if _, ok := privileged[name]; ok {
+			return true
+		}
+	}
+	return false
+}
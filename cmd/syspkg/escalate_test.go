@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestCheckEscalatedArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "install", args: []string{"--apt", "install", "-y", "vim"}},
+		{name: "report file", args: []string{"upgrade", "--report-file", "/etc/passwd"}, wantErr: true},
+		{name: "webhook with value", args: []string{"upgrade", "--webhook=http://example.com"}, wantErr: true},
+		{name: "single dash", args: []string{"-root", "/mnt", "install", "vim"}, wantErr: true},
+		{name: "config", args: []string{"--config", "/tmp/syspkg.yaml", "install", "vim"}, wantErr: true},
+		{name: "after terminator", args: []string{"install", "--", "--dir"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkEscalatedArgs(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("checkEscalatedArgs() error = %+v, wantErr %+v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/fleet"
+)
+
+// localFlags are the global flags not passed to the hosts with --hosts: the ones of the fleet mode itself, and the
+// ones of the local output, the hosts always printing JSON envelopes.
+var localFlags = map[string]bool{
+	"hosts":       true,
+	"max-hosts":   true,
+	"config":      true,
+	"output":      true,
+	"json":        true,
+	"json-stream": true,
+}
+
+// FleetEnvelope is the JSON document printed by commands run on several hosts with --hosts and --json.
+type FleetEnvelope struct {
+	// Schema is the version of the envelope, outputSchema.
+	Schema string `json:"schema"`
+
+	// Command is the name of the command, such as "upgrade".
+	Command string `json:"command"`
+
+	// Hosts are the results of the command, by host.
+	Hosts map[string]*HostResult `json:"hosts"`
+}
+
+// HostResult is the result of a command run on a host with --hosts.
+type HostResult struct {
+	// Results are the results of the command on the host, by package manager name, for the commands printing an
+	// envelope.
+	Results map[string]*Result `json:"results,omitempty"`
+
+	// Output is the output of the other commands.
+	Output string `json:"output,omitempty"`
+
+	// ExitStatus is the exit status of syspkg on the host, or -1 if it didn't run.
+	ExitStatus int `json:"exit_status"`
+
+	// Error is the error syspkg failed with on the host, or the reason it didn't run, such as an unreachable host.
+	Error string `json:"error,omitempty"`
+
+	// Duration is how long the command took on the host, in seconds.
+	Duration float64 `json:"duration"`
+}
+
+// runOnHosts runs the command on the hosts listed in the file given with --hosts, over SSH, instead of this host,
+// prints their results by host, and exits with the highest exit status of the hosts, or with exitOperationFailed if
+// some hosts couldn't be reached. The commands changing packages are confirmed once for all hosts, unless
+// --assume-yes is set.
+func runOnHosts(c *cli.Context) error {
+	command, help := invokedCommand(c)
+	if command == "" || help {
+		return nil
+	}
+	if command == "tui" {
+		return errors.New("the tui command can't run with --hosts")
+	}
+	if c.Int("max-hosts") < 0 {
+		return errors.New("--max-hosts must not be negative")
+	}
+	hosts, err := fleet.ReadHosts(c.String("hosts"))
+	if err != nil {
+		return err
+	}
+
+	args := remoteArgs(c)
+	if privilegedCommands[command] && !c.Bool("dry-run") && !c.Bool("assume-yes") {
+		fmt.Fprintf(os.Stderr, "Run syspkg %s on %d hosts: %s? [Y/n]: ", strings.Join(c.Args().Slice(), " "), len(hosts), strings.Join(hosts, ", "))
+		input := ""
+		_, _ = fmt.Scanln(&input)
+		if input = strings.ToLower(input); input != "y" && input != "" {
+			fmt.Fprintln(os.Stderr, "Cancelled.")
+			os.Exit(0)
+		}
+		// the hosts can't prompt, their standard input being closed
+		args = append([]string{"--assume-yes"}, args...)
+	}
+
+	runner := &fleet.Runner{Parallel: c.Int("max-hosts")}
+	results := runner.Run(interrupt, hosts, args)
+
+	envelope := FleetEnvelope{Schema: outputSchema, Command: command, Hosts: make(map[string]*HostResult)}
+	status := 0
+	for _, r := range results {
+		result := &HostResult{ExitStatus: r.ExitStatus, Duration: r.Duration.Seconds()}
+		var remote Envelope
+		switch {
+		case r.Err != nil:
+			result.Error = r.Err.Error()
+			status = max(status, exitOperationFailed)
+		case json.Unmarshal(r.Stdout, &remote) == nil && remote.Schema == outputSchema:
+			result.Results = remote.Results
+		default:
+			result.Output = string(r.Stdout)
+		}
+		if r.Err == nil && r.ExitStatus != 0 {
+			// syspkg prints its errors last
+			result.Error = fleet.LastLine(r.Stderr, fmt.Sprintf("exit status %d", r.ExitStatus))
+			status = max(status, r.ExitStatus)
+		}
+		envelope.Hosts[r.Host] = result
+	}
+
+	if outputFormat(c) != outputText {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(envelope); err != nil {
+			return err
+		}
+	} else {
+		printHostResults(hosts, envelope)
+	}
+	os.Exit(status)
+	return nil
+}
+
+// remoteArgs returns the arguments syspkg runs with on the hosts: the ones of the command line, but for localFlags,
+// printing the results as JSON.
+func remoteArgs(c *cli.Context) []string {
+	takesValue := make(map[string]bool)
+	for _, flag := range c.App.Flags {
+		_, isBool := flag.(*cli.BoolFlag)
+		for _, name := range flag.Names() {
+			takesValue[name] = !isBool
+		}
+	}
+
+	args := []string{"--output", outputJSON}
+	cmdline := os.Args[1:]
+	for i := 0; i < len(cmdline); i++ {
+		arg := cmdline[i]
+		if !strings.HasPrefix(arg, "-") || arg == "--" {
+			// the global flags come before the command
+			return append(args, cmdline[i:]...)
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		n := 1
+		if takesValue[name] && !hasValue && i+1 < len(cmdline) {
+			n = 2
+		}
+		if !localFlags[name] {
+			args = append(args, cmdline[i:i+n]...)
+		}
+		i += n - 1
+	}
+	return args
+}
+
+// printHostResults prints the results of a command run on hosts, host by host, then a summary.
+func printHostResults(hosts []string, envelope FleetEnvelope) {
+	var failed []string
+	for _, host := range hosts {
+		result := envelope.Hosts[host]
+		fmt.Printf("== %s (%s) ==\n", host, (time.Duration(result.Duration*float64(time.Second)) / time.Millisecond * time.Millisecond).String())
+
+		names := make([]string, 0, len(result.Results))
+		for name := range result.Results {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			r := result.Results[name]
+			switch {
+			case r.Unsupported:
+				fmt.Printf("%s: not supported\n", name)
+			case r.Error != "":
+				fmt.Printf("%s: failed: %s\n", name, r.Error)
+			default:
+				fmt.Printf("%s: %d packages\n", name, len(r.Packages))
+			}
+			for _, pkg := range r.Packages {
+				fmt.Printf("  %s\n", describeVersions(pkg))
+			}
+		}
+		fmt.Print(result.Output)
+		if result.Error != "" {
+			fmt.Printf("Error: %s\n", result.Error)
+			failed = append(failed, host)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%s on %d hosts: %d succeeded", envelope.Command, len(hosts), len(hosts)-len(failed))
+	if len(failed) > 0 {
+		fmt.Printf(", %d failed (%s)", len(failed), strings.Join(failed, ", "))
+	}
+	fmt.Println()
+}
+
+// describeVersions returns the name of a package with its version, or its old and new versions, such as
+// "openssl 3.0.14-1 -> 3.0.15-1".
+func describeVersions(pkg manager.PackageInfo) string {
+	s := strings.TrimSpace(pkg.Name + " " + pkg.Version)
+	if pkg.NewVersion != "" && pkg.NewVersion != pkg.Version {
+		s += " -> " + pkg.NewVersion
+	}
+	return s
+}
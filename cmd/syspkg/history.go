@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/history"
+)
+
+// listHistory lists the transactions recorded in the history.
+func listHistory(c *cli.Context) error {
+	transactions, err := history.NewStore(history.DefaultPath()).List()
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range transactions {
+		fmt.Printf("%d\t%s\t%s\t%s\t%s\t%s\n", tx.ID, tx.Time.Local().Format(time.DateTime), tx.PackageManager, tx.Operation, strings.Join(transactionPackages(tx), " "), transactionStatus(tx))
+	}
+	return nil
+}
+
+// rollbackTransaction undoes the transaction whose ID is given as argument, with its package manager, and records the
+// rollback in the history.
+func rollbackTransaction(c *cli.Context, pms map[string]syspkg.PackageManager) error {
+	var opts = getOptions(c)
+
+	id, err := strconv.Atoi(c.Args().First())
+	if err != nil || c.NArg() != 1 {
+		fmt.Println("Please specify one and only one transaction ID.")
+		return nil
+	}
+
+	tx, err := history.NewStore(history.DefaultPath()).Get(id)
+	if err != nil {
+		return err
+	}
+	pm, ok := pms[tx.PackageManager]
+	if !ok {
+		return fmt.Errorf("package manager %s of transaction %d is not available", tx.PackageManager, tx.ID)
+	}
+	op, pkgNames, err := history.Inverse(tx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Rolling back transaction %d: %s %s with %s\n", tx.ID, op, pkgNames, tx.PackageManager)
+
+	var packages []manager.PackageInfo
+	switch op {
+	case history.OperationInstall:
+		packages, err = withHooks(pm.GetPackageManager(), "install", pkgNames, opts, func() ([]manager.PackageInfo, error) {
+			return pm.Install(pkgNames, opts)
+		})
+	case history.OperationDelete:
+		packages, err = withHooks(pm.GetPackageManager(), "delete", pkgNames, opts, func() ([]manager.PackageInfo, error) {
+			return pm.Delete(pkgNames, opts)
+		})
+	default:
+		d, ok := pm.(syspkg.Downgrader)
+		if !ok {
+			return fmt.Errorf("%w: %s can't downgrade packages, to undo transaction %d", history.ErrNotReversible, tx.PackageManager, tx.ID)
+		}
+		packages, err = withHooks(pm.GetPackageManager(), "downgrade", pkgNames, opts, func() ([]manager.PackageInfo, error) {
+			return d.Downgrade(pkgNames, opts)
+		})
+	}
+	recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: op, Requested: pkgNames, Packages: packages, RollbackOf: tx.ID}, err, opts)
+	if err != nil {
+		return fmt.Errorf("error while rolling back transaction %d for %T: %w", tx.ID, pm, err)
+	}
+	log.Printf("Rolled back transaction %d for %T:\n%+v\n", tx.ID, pm, packages)
+	return nil
+}
+
+// recordTransaction records a transaction in the history, unless it is a dry run or the operation is not supported.
+// Failing to record the transaction is logged, but not fatal, as the transaction itself was already performed.
+func recordTransaction(tx history.Transaction, err error, opts *manager.Options) {
+	if opts.DryRun || errors.Is(err, manager.ErrOperationNotSupported) {
+		return
+	}
+	recordOperation(tx.PackageManager, string(tx.Operation), transactionPackages(tx), err, opts)
+	// the history is the one of this host, which rollbacks would change
+	if target != nil || opts.RootDir != "" {
+		return
+	}
+	if err != nil {
+		tx.Error = err.Error()
+	}
+
+	if _, err := history.NewStore(history.DefaultPath()).Record(tx); err != nil {
+		log.Printf("Error while recording the transaction in the history: %+v\n", err)
+	}
+}
+
+// transactionPackages returns the packages changed by a transaction as "name=version", or the requested packages if none were reported.
+func transactionPackages(tx history.Transaction) []string {
+	var pkgs []string
+	for _, pkg := range tx.Packages {
+		version := pkg.NewVersion
+		if version == "" {
+			version = pkg.Version
+		}
+		pkgs = append(pkgs, pkg.Name+"="+version)
+	}
+	if len(pkgs) == 0 {
+		pkgs = tx.Requested
+	}
+	return pkgs
+}
+
+// transactionStatus returns a short description of the outcome of a transaction.
+func transactionStatus(tx history.Transaction) string {
+	status := "ok"
+	if !tx.Success() {
+		status = "failed: " + tx.Error
+	}
+	if tx.RollbackOf != 0 {
+		status += fmt.Sprintf(" (rollback of %d)", tx.RollbackOf)
+	}
+	return status
+}
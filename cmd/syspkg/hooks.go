@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/hooks"
+)
+
+// withHooks runs an operation of package manager pm on the requested packages with run, between the pre and post
+// hooks configured for it. A pre hook failing with the abort policy cancels the operation, and its error is returned.
+// Hooks are not run in dry runs, nor after unsupported operations. The errors of interrupted operations wrap
+// manager.ErrInterrupted.
+func withHooks(pm string, operation string, requested []string, opts *manager.Options, run func() ([]manager.PackageInfo, error)) ([]manager.PackageInfo, error) {
+	if len(cfg.Hooks) == 0 || opts.DryRun {
+		packages, err := run()
+		return packages, manager.Interrupted(opts, err)
+	}
+
+	op := hooks.Operation{PackageManager: pm, Operation: operation}
+	for _, pkg := range requested {
+		spec, _ := manager.ParsePackageSpec(pkg)
+		op.Packages = append(op.Packages, spec.Name)
+	}
+	if err := hooks.Run(cfg.Hooks, hooks.PhasePre, op, opts.Log()); err != nil {
+		return nil, err
+	}
+
+	packages, err := run()
+	err = manager.Interrupted(opts, err)
+	if errors.Is(err, manager.ErrOperationNotSupported) {
+		return packages, err
+	}
+	// the packages reported by the package manager include the dependencies, and the upgraded packages
+	// when upgrading all of them
+	if len(packages) > 0 {
+		op.Packages = nil
+		for _, pkg := range packages {
+			op.Packages = append(op.Packages, pkg.Name)
+		}
+	}
+	if err != nil {
+		op.Error = err.Error()
+	}
+	if err := hooks.Run(cfg.Hooks, hooks.PhasePost, op, opts.Log()); err != nil {
+		log.Printf("Error while running the post-%s hooks for %s: %+v\n", operation, pm, err)
+	}
+	return packages, err
+}
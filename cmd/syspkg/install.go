@@ -0,0 +1,228 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/history"
+)
+
+// installTargets installs the packages given as arguments: the ones prefixed with a package manager with it, and the
+// others with the selected package managers, following the install policy. pms are the selected package managers and
+// the targeted ones.
+func installTargets(c *cli.Context, selected, pms map[string]syspkg.PackageManager) error {
+	var opts = getOptions(c)
+
+	log.Printf("Installing packages for %T...\n", pms)
+
+	policy := cfg.InstallPolicy
+	if c.IsSet("policy") {
+		policy = c.String("policy")
+	}
+	installPolicy, err := manager.ParseInstallPolicy(policy)
+	if err != nil {
+		return err
+	}
+	targets, pkgNames, err := parseTargets(c.Args().Slice())
+	if err != nil {
+		return err
+	}
+
+	out := newOutputFormatter(c, "install")
+	if installPolicy == manager.InstallPolicyFirst {
+		// each package is tried with the package managers in order of priority, until one installs it, and the
+		// failures of the previous ones are then forgotten, not to report the install as failed
+		order := syspkg.InstallOrder(selected, cfg.Priority)
+		for _, pkg := range pkgNames {
+			failures := make(map[string]error)
+			for _, name := range order {
+				err := installPackages(out, name, pms[name], []string{pkg}, opts)
+				if err != nil {
+					failures[name] = err
+					continue
+				}
+				for failed, err := range failures {
+					out.Forget(failed, err)
+				}
+				break
+			}
+		}
+	} else {
+		resolved, err := syspkg.ResolveInstall(selected, pkgNames, installPolicy, cfg.Priority, opts)
+		if errors.Is(err, manager.ErrAmbiguousPackageManager) {
+			return err
+		}
+		if err != nil && !out.JSON {
+			fmt.Printf("Error while choosing the package managers installing the packages: %+v\n", err)
+		}
+		for name, pkgs := range resolved {
+			targets[name] = append(targets[name], pkgs...)
+		}
+	}
+	for name, pkgNames := range targets {
+		_ = installPackages(out, name, pms[name], pkgNames, opts)
+	}
+	return out.Finish()
+}
+
+// installPackages installs pkgNames with pm, named name, or plans it with --dry-run, adds the result to out, and
+// returns the error. A nil pm is a targeted package manager that is not available.
+func installPackages(out *OutputFormatter, name string, pm syspkg.PackageManager, pkgNames []string, opts *manager.Options) error {
+	start := out.Start(name)
+	if pm == nil {
+		err := fmt.Errorf("%s is not available or is excluded, to install %s", name, strings.Join(pkgNames, ", "))
+		if out.Add(name, nil, err, start); !out.JSON {
+			fmt.Printf("Error while installing packages: %+v\n", err)
+		}
+		return err
+	}
+	log.Printf("Installing packages for %T...\n", pm)
+	if opts.DryRun {
+		plan, err := syspkg.PlanInstall(pm, pkgNames, opts)
+		showPlan(out, name, plan, err, start)
+		return err
+	}
+	if err := checkLimits(name, func() (*manager.Plan, error) { return syspkg.PlanInstall(pm, pkgNames, opts) }); err != nil {
+		if out.Add(name, nil, err, start); !out.JSON {
+			fmt.Printf("Error while installing packages for %T: %+v\n", pm, err)
+		}
+		return err
+	}
+	packages, err := withHooks(name, "install", pkgNames, opts, func() ([]manager.PackageInfo, error) {
+		return pm.Install(pkgNames, opts)
+	})
+	recordTransaction(history.Transaction{PackageManager: name, Operation: history.OperationInstall, Requested: pkgNames, Packages: packages}, err, opts)
+	if out.Add(name, packages, err, start); out.JSON {
+		return err
+	}
+	if err != nil {
+		fmt.Printf("Error while installing packages for %T: %+v\n%+v\n", pm, err, packages)
+		return err
+	}
+	log.Printf("Installed packages for %T:\n%+v\n", pm, packages)
+	return nil
+}
+
+// installLocal installs the package files given as arguments, each with the package manager of its type, once
+// their checksums and signatures are verified.
+func installLocal(c *cli.Context, pms map[string]syspkg.PackageManager) error {
+	var opts = getOptions(c)
+	paths := c.Args().Slice()
+
+	if len(paths) == 0 {
+		fmt.Println("Please specify at least one package file to install.")
+		return nil
+	}
+	if results, err := verifyPackageFiles(c, paths, opts); err != nil {
+		if !jsonOutput(c) {
+			printIntegrity(results)
+		}
+		return err
+	}
+
+	// route each file to the package manager of its type
+	files := make(map[string][]string)
+	var names []string
+	for _, path := range paths {
+		name, err := manager.LocalPackageManager(path)
+		if err != nil {
+			return err
+		}
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+		files[name] = append(files[name], path)
+	}
+
+	out := newOutputFormatter(c, "install-local")
+	for _, name := range names {
+		start := out.Start(name)
+		pm, ok := pms[name]
+		if !ok {
+			err := fmt.Errorf("%s is not available or not selected, to install %s", name, strings.Join(files[name], ", "))
+			if out.Add(name, nil, err, start); !out.JSON {
+				fmt.Printf("Error while installing package files: %+v\n", err)
+			}
+			continue
+		}
+		li, ok := pm.(syspkg.LocalInstaller)
+		if !ok {
+			out.Add(name, nil, manager.ErrOperationNotSupported, start)
+			log.Printf("Installing package files is not supported by %T, skipping\n", pm)
+			continue
+		}
+		packages, err := withHooks(name, "install", files[name], opts, func() ([]manager.PackageInfo, error) {
+			return li.InstallLocal(files[name], opts)
+		})
+		recordTransaction(history.Transaction{PackageManager: name, Operation: history.OperationInstall, Requested: files[name], Packages: packages}, err, opts)
+		if out.Add(name, packages, err, start); out.JSON {
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Error while installing package files for %T: %+v\n%+v", pm, err, packages)
+			continue
+		}
+		log.Printf("Installed package files for %T:\n%+v\n", pm, packages)
+	}
+	return out.Finish()
+}
+
+// deletePackages deletes the packages given as arguments with the package managers, expanding the patterns to the
+// installed packages matching them once confirmed.
+func deletePackages(c *cli.Context, pms map[string]syspkg.PackageManager) error {
+	var opts = getOptions(c)
+	pkgNames := c.Args().Slice()
+
+	log.Printf("Deleting packages... for %T\n", pms)
+
+	out := newOutputFormatter(c, "delete")
+	// patterns are expanded to the installed packages of each package manager, rather than passed to them
+	var matching map[string][]string
+	if c.Bool("regex") || slices.ContainsFunc(pkgNames, manager.IsGlob) {
+		patterns, err := parsePatterns(c, pkgNames)
+		if err != nil {
+			return err
+		}
+		if matching = installedMatching(pms, opts, patterns); len(matching) == 0 {
+			return fmt.Errorf("no installed packages match %q", pkgNames)
+		}
+		if !confirmDelete(matching, out.JSON, opts.DryRun || c.Bool("yes") || c.Bool("assume-yes")) {
+			fmt.Println("Delete cancelled.")
+			return nil
+		}
+	}
+	for _, pm := range pms {
+		if matching != nil {
+			if pkgNames = matching[pm.GetPackageManager()]; len(pkgNames) == 0 {
+				continue
+			}
+		}
+		log.Printf("Deleting packages for %T...\n", pm)
+		start := out.Start(pm.GetPackageManager())
+		if opts.DryRun {
+			plan, err := syspkg.PlanRemove(pm, pkgNames, opts)
+			showPlan(out, pm.GetPackageManager(), plan, err, start)
+			continue
+		}
+		packages, err := withHooks(pm.GetPackageManager(), "delete", pkgNames, opts, func() ([]manager.PackageInfo, error) {
+			return pm.Delete(pkgNames, opts)
+		})
+		recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: history.OperationDelete, Requested: pkgNames, Packages: packages}, err, opts)
+		if out.Add(pm.GetPackageManager(), packages, err, start); out.JSON {
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Error while deleting packages for %T: %+v\n%+v\n", pm, err, packages)
+			continue
+		}
+		log.Printf("Deleted packages for %T:\n%+v\n", pm, packages)
+	}
+	return out.Finish()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/integrity"
+)
+
+// integrityFlags are the flags of the commands verifying package files before installing them, or once downloaded.
+var integrityFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "checksums",
+		Usage: "File of the SHA256 checksums of the package files, in the format of sha256sum",
+	},
+	&cli.BoolFlag{
+		Name:  "allow-unverified",
+		Usage: "Allow the .deb and .rpm files that can't be verified, e.g. built locally, rather than refusing them",
+	},
+}
+
+// verifyPackageFiles verifies the package files at paths, with the checksums of the --checksums file, if any. It
+// returns their results, and an error wrapping integrity.ErrVerificationFailed if some failed verification, or
+// couldn't be verified without --allow-unverified.
+func verifyPackageFiles(c *cli.Context, paths []string, opts *manager.Options) ([]integrity.Result, error) {
+	var checksums map[string]string
+	if file := c.String("checksums"); file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		checksums = integrity.ParseChecksums(string(content))
+	}
+
+	results := make([]integrity.Result, 0, len(paths))
+	for _, path := range paths {
+		result, err := integrity.Verify(path, checksums, opts)
+		if err != nil {
+			return nil, fmt.Errorf("cannot verify %s: %w", path, err)
+		}
+		opts.Log().Debug("Verified package file", "path", path, "status", result.Status, "reason", result.Reason)
+		results = append(results, result)
+	}
+	return results, integrity.Check(results, c.Bool("allow-unverified"))
+}
+
+// printIntegrity prints the results of the verification of package files, one per line.
+func printIntegrity(results []integrity.Result) {
+	for _, result := range results {
+		fmt.Printf("%s: %s", result.Path, result.Status)
+		if result.Reason != "" {
+			fmt.Printf(" (%s)", result.Reason)
+		}
+		fmt.Printf(" sha256:%s\n", result.SHA256)
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+)
+
+// listFlags are the flags of the commands listing packages, such as find and show installed, to sort and filter them.
+var listFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "sort",
+		Usage: "Sort the packages by `name|size|version|manager`, listing the packages of all package managers together; size lists the largest first",
+	},
+	&cli.StringSliceFlag{
+		Name: "filter",
+		Usage: "Only list the packages matching the `expression`, such as status=upgradable, name~^lib, manager!=snap or size>100MB; " +
+			"can be repeated, packages must match all of them",
+	},
+}
+
+// regexFlag is the flag of the commands taking patterns of package names, reading them as regular expressions.
+var regexFlag = &cli.BoolFlag{
+	Name:  "regex",
+	Usage: "Read the patterns as regular expressions matching any part of the names, such as lib.*ssl, rather than globs",
+}
+
+// licensesFlag is the flag of the commands listing installed packages, reading their licenses, which some package
+// managers read from a file per package.
+var licensesFlag = &cli.BoolFlag{
+	Name:  "licenses",
+	Usage: "Read the licenses of the packages, which apt reads from the copyright file of each package",
+}
+
+// newPackageListProcessor returns the processor of the packages listed by a command, from its --sort and --filter flags.
+func newPackageListProcessor(c *cli.Context) (*manager.PackageListProcessor, error) {
+	return manager.NewPackageListProcessor(c.String("sort"), c.StringSlice("filter"))
+}
+
+// filtersLicense reports whether the --filter flags filter the packages by license.
+func filtersLicense(c *cli.Context) bool {
+	for _, expr := range c.StringSlice("filter") {
+		if f, err := manager.ParsePackageFilter(expr); err == nil && f.Field == "license" {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePatterns parses the patterns of the package names given as arguments: regular expressions with --regex, or
+// globs, such as python3-*, which match the whole names.
+func parsePatterns(c *cli.Context, args []string) ([]*manager.Pattern, error) {
+	patterns := make([]*manager.Pattern, 0, len(args))
+	for _, arg := range args {
+		pattern, err := manager.ParsePattern(arg, c.Bool("regex"))
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// installedMatching returns the names of the installed packages matching one of the patterns, by package manager,
+// leaving out the package managers with none. The package managers whose packages can't be listed are reported and
+// left out too.
+func installedMatching(pms map[string]syspkg.PackageManager, opts *manager.Options, patterns []*manager.Pattern) map[string][]string {
+	var mu sync.Mutex
+	matching := make(map[string][]string, len(pms))
+	forEachConcurrently(pms, func(name string, pm syspkg.PackageManager) {
+		pkgs, err := pm.ListInstalled(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while listing installed packages for %s: %+v\n", name, err)
+			return
+		}
+		if names := manager.MatchingNames(pkgs, patterns); len(names) > 0 {
+			mu.Lock()
+			defer mu.Unlock()
+			matching[name] = names
+		}
+	})
+	return matching
+}
+
+// confirmDelete lists the packages matching the patterns of a delete, by package manager, and asks for the
+// confirmation to delete them, unless yes is set. The list goes to stderr with JSON output, which it would corrupt.
+func confirmDelete(matching map[string][]string, json, yes bool) bool {
+	w := os.Stdout
+	if json {
+		w = os.Stderr
+	}
+	names := make([]string, 0, len(matching))
+	for name := range matching {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "The following packages will be deleted:")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s: %s\n", name, strings.Join(matching[name], " "))
+	}
+	if yes {
+		return true
+	}
+
+	// unlike the other prompts, the default is no, as patterns may match more packages than expected
+	fmt.Fprint(w, "\nDo you want to delete these packages? [y/N]: ")
+	input := ""
+	_, _ = fmt.Scanln(&input)
+	input = strings.ToLower(input)
+	return input == "y" || input == "yes"
+}
+
+// searchKeywords returns the keywords pm is searched for the packages matching pattern: the pattern itself for the
+// package managers searching regular expressions, when it is portable to them, or its literal part otherwise, the
+// packages found being matched by the pattern afterwards.
+func searchKeywords(pm syspkg.PackageManager, pattern *manager.Pattern) ([]string, error) {
+	if pattern.Native() && syspkg.CapabilitiesOf(pm).RegexSearch {
+		return []string{pattern.Expr}, nil
+	}
+	keyword := pattern.Keyword()
+	if keyword == "" {
+		return nil, fmt.Errorf("can't search %s for %q: the pattern has no literal part to search for", pm.GetPackageManager(), pattern.Expr)
+	}
+	return []string{keyword}, nil
+}
+
+// searchFlags are the flags of the find command, ranking the packages found, in addition to listFlags.
+var searchFlags = append([]cli.Flag{
+	&cli.BoolFlag{
+		Name:  "exact",
+		Usage: "Only list the packages whose name is one of the keywords, case-insensitively",
+	},
+	&cli.IntFlag{
+		Name:  "limit",
+		Usage: "List at most `N` packages, the best matches of all package managers, 0 for no limit",
+	},
+	regexFlag,
+}, listFlags...)
+
+// queryResult is the result of a query of a package manager, kept to process the results of all package managers
+// together before adding them to the output.
+type queryResult struct {
+	packages []manager.PackageInfo
+	err      error
+	elapsed  time.Duration
+	commands []manager.CommandRecord
+}
+
+// queryResults runs query for all the given package managers concurrently, with opts recording the commands each of
+// them runs, and returns their results by package manager name, and all the packages they returned. With --verbose,
+// how long each package manager took and the commands it ran are logged as they finish.
+func queryResults(pms map[string]syspkg.PackageManager, out *OutputFormatter, opts *manager.Options, query func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error)) (map[string]queryResult, []manager.PackageInfo) {
+	var mu sync.Mutex
+	results := make(map[string]queryResult, len(pms))
+	var all []manager.PackageInfo
+	forEachConcurrently(pms, func(name string, pm syspkg.PackageManager) {
+		pmOpts := *opts
+		pmOpts.Transcript = &manager.Transcript{}
+		start := out.Start(name)
+		pkgs, err := query(pm, &pmOpts)
+		result := queryResult{pkgs, err, time.Since(start), pmOpts.Transcript.Commands()}
+		if opts.Verbose && !out.JSON {
+			logOperation(name, result.elapsed, result.commands)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		results[name] = result
+		all = append(all, pkgs...)
+	})
+	return results, all
+}
+
+// logOperation logs how long the operation of package manager name took, and the commands it ran with their exit
+// code, so that slow package managers can be found, and the commands run again manually.
+func logOperation(name string, elapsed time.Duration, commands []manager.CommandRecord) {
+	log.Printf("%s: took %.2fs, running %d command(s)\n", name, elapsed.Seconds(), len(commands))
+	for _, command := range commands {
+		log.Printf("%s: %s (exit code %d)\n", name, command.CommandLine, command.ExitCode)
+	}
+}
+
+// addResult adds the result of a package manager to out, with the packages of kept it returned, in their order.
+func addResult(out *OutputFormatter, name string, result queryResult, kept []manager.PackageInfo) {
+	var pkgs []manager.PackageInfo
+	for _, pkg := range kept {
+		if pkg.PackageManager == name {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	out.Add(name, pkgs, result.err, time.Now().Add(-result.elapsed)).Commands = result.commands
+}
+
+// packageList prints the packages listed by a command as text, once processed, as tables of columns laid out by
+// layout: the packages of each package manager as they come, under their header, or the packages of all package
+// managers together once sorted when --sort is set, or once ranked for searches. With dedup, the packages found in
+// several package managers are printed last. The packages listed for several architectures are printed with their
+// architecture, as name:arch.
+type packageList struct {
+	proc    *manager.PackageListProcessor
+	header  string
+	columns []packageColumn
+	layout  tableLayout
+	dedup   bool
+	sorted  []manager.PackageInfo
+	found   []manager.PackageInfo
+}
+
+// Add prints the packages listed by pm, or keeps them to print them with the others once sorted.
+func (l *packageList) Add(pm syspkg.PackageManager, pkgs []manager.PackageInfo) {
+	if l.dedup {
+		l.found = append(l.found, pkgs...)
+	}
+	if l.proc.Ordered() {
+		l.sorted = append(l.sorted, pkgs...)
+		return
+	}
+	fmt.Printf(l.header, pm)
+	printPackageTable(os.Stdout, manager.QualifyMultiArch(pkgs), l.columns, l.layout)
+}
+
+// Flush prints the packages of all package managers kept by Add, sorted, then the duplicates with dedup.
+func (l *packageList) Flush() {
+	printPackageTable(os.Stdout, manager.QualifyMultiArch(l.proc.Process(l.sorted)), l.columns, l.layout)
+	if duplicates := manager.FindDuplicates(l.found); len(duplicates) > 0 {
+		fmt.Println("\nFound in several package managers:")
+		printDuplicates(duplicates)
+	}
+	l.sorted, l.found = nil, nil
+}
+
+// printDuplicates prints the packages found in several package managers, one per line, with the version of each
+// package manager, and flags the ones installed by several of them.
+func printDuplicates(duplicates []manager.DuplicateInfo) {
+	for _, duplicate := range duplicates {
+		versions := make([]string, 0, len(duplicate.Packages))
+		for _, pkg := range duplicate.Packages {
+			version := pkg.Version
+			if version == "" {
+				version = pkg.NewVersion
+			}
+			versions = append(versions, fmt.Sprintf("%s %s [%s] (%s)", pkg.PackageManager, pkg.Name, version, pkg.Status))
+		}
+		fmt.Printf("%s: %s", duplicate.Name, strings.Join(versions, ", "))
+		if duplicate.Conflict {
+			fmt.Print(" - conflict: installed by several package managers")
+		}
+		fmt.Println()
+	}
+}
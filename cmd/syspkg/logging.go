@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Formats of the logs.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// setupLogger sets the default slog logger, used by the package managers and the log package, from --log-level and
+// --log-format. The level defaults to debug with --verbose or --debug, so that the output of the package managers is
+// shown, and to info otherwise.
+func setupLogger(c *cli.Context) error {
+	level := slog.LevelInfo
+	if c.Bool("verbose") || c.Bool("debug") {
+		level = slog.LevelDebug
+	}
+	if c.IsSet("log-level") {
+		if err := level.UnmarshalText([]byte(c.String("log-level"))); err != nil {
+			return fmt.Errorf("unknown log level %q, expected debug, info, warn or error", c.String("log-level"))
+		}
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(c.String("log-format")) {
+	case logFormatText:
+		handler = slog.NewTextHandler(os.Stderr, handlerOpts)
+	case logFormatJSON:
+		handler = slog.NewJSONHandler(os.Stderr, handlerOpts)
+	default:
+		return fmt.Errorf("unknown log format %q, expected %s or %s", c.String("log-format"), logFormatText, logFormatJSON)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
@@ -2,24 +2,79 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	// "github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v2"
 
 	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/daemon"
 	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/audit"
+	"github.com/bluet/syspkg/manager/cache"
+	"github.com/bluet/syspkg/manager/config"
+	"github.com/bluet/syspkg/manager/fixture"
+	"github.com/bluet/syspkg/manager/history"
+	"github.com/bluet/syspkg/manager/integrity"
+	"github.com/bluet/syspkg/manager/platform"
+	"github.com/bluet/syspkg/manager/restarts"
+	"github.com/bluet/syspkg/manager/sbom"
+	"github.com/bluet/syspkg/manager/schedule"
+	"github.com/bluet/syspkg/manager/snapshot"
 )
 
+// exitVulnerable is the exit status of the audit command when vulnerabilities are found.
+const exitVulnerable = 3
+
+// exitRebootRequired is the exit status of the status command when a reboot is required.
+const exitRebootRequired = 4
+
+// exitVerificationFailed is the exit status of the verify command when packages fail verification.
+const exitVerificationFailed = 5
+
+// exitOperationFailed is the exit status of the commands changing packages when some package managers fail.
+const exitOperationFailed = 6
+
+// exitUntrustedSources is the exit status of the sources audit command when third-party or unsigned sources are found.
+const exitUntrustedSources = 7
+
+// exitInterrupted is the exit status of the commands changing packages when they are interrupted, as for SIGINT.
+const exitInterrupted = 130
+
+// interrupt is done once syspkg is interrupted with Ctrl-C or SIGTERM, to interrupt the commands of the package
+// managers, through manager.Options.Context, and report the operations they left partially applied.
+var interrupt = context.Background()
+
 // main function initializes syspkg and sets up the CLI application.
 func main() {
-	// Check if the user has root privileges.
-	if os.Geteuid() != 0 {
-		fmt.Println("(This command must be run with root privileges. If you got exist codes 100 or 101, please run this command with sudo.)")
-	}
+	// commands recorded or replayed with SYSPKG_RECORD or SYSPKG_REPLAY run through syspkg itself
+	fixture.Main()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	interrupt = ctx
+	go func() {
+		<-ctx.Done()
+		// a second interruption exits at once
+		stop()
+		fmt.Fprintln(os.Stderr, "Interrupted, waiting for the package managers to stop... (interrupt again to quit now)")
+	}()
 
 	// Initialize syspkg and find available package managers.
 	s, err := syspkg.New(
@@ -46,9 +101,54 @@ func main() {
 		EnableBashCompletion:   true,
 		UseShortOptionHandling: true,
 		Suggest:                true,
+		Before: func(c *cli.Context) error {
+			if err := setupLogger(c); err != nil {
+				return err
+			}
+			if err := loadConfig(c); err != nil {
+				return err
+			}
+			// with --hosts, the command runs on the hosts instead
+			if c.IsSet("hosts") {
+				if err := runOnHosts(c); err != nil {
+					return err
+				}
+			}
+			if err := setupLimits(c); err != nil {
+				return err
+			}
+			setupCache(c)
+			// with --container or --root, the package managers are the ones of the target
+			if ts, tpms, err := setupTarget(c); err != nil {
+				return err
+			} else if ts != nil {
+				s, pms = ts, tpms
+			}
+			if c.IsSet("install-root") {
+				if pms, err = installRootPackageManagers(c, pms); err != nil {
+					return err
+				}
+			}
+			// with --arch, the package managers that can't install packages of another architecture are left out
+			if c.IsSet("arch") {
+				if pms = supportingPackageManagers(pms, func(c manager.Capabilities) bool { return c.Architecture }); len(pms) == 0 {
+					return errors.New("no available package manager supports --arch")
+				}
+			}
+			// commands needing root privileges are re-executed with sudo, doas or pkexec
+			return escalate(c, s, pms)
+		},
+		After: func(c *cli.Context) error {
+			progress.Done()
+			// the package managers selected by the command, which may have changed their packages
+			if command, help := invokedCommand(c); !help {
+				invalidateCache(command, c.Bool("dry-run"), pms)
+			}
+			return nil
+		},
 		// Action: func(c *cli.Context) error {
 		// 	var opts = getOptions(c)
-		// 	pms = filterPackageManager(pms, c)
+		// 	pms = filterPackageManager(s, pms, c)
 
 		// 	log.Printf("Listing upgradable packages for %T...\n", pms)
 		// 	listUpgradablePackages(pms, opts)
@@ -57,156 +157,1594 @@ func main() {
 		// DefaultCommand: "show upgradable",
 		Commands: []*cli.Command{
 			{
-				Name:    "install",
-				Aliases: []string{"i"},
-				Usage:   "Install packages",
+				Name:      "install",
+				Aliases:   []string{"i"},
+				Usage:     "Install packages",
+				ArgsUsage: "[manager:]<package>...",
+				Description: "Packages prefixed with a package manager, such as apt:vim, snap:code or flatpak:org.gimp.GIMP, " +
+					"are installed by this package manager. The others are installed by one of the selected package managers, " +
+					"following the install policy: best, the package manager of highest priority providing the package; first, " +
+					"the first one installing it, in order of priority; explicit, only a single selected one; or all of them. " +
+					"The priority is configured with priority, and defaults to the system package managers first.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "policy",
+						Usage: "Install the packages with the `best|first|explicit|all` of the selected package managers (default: configured install_policy, or best)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					available := pms
+					pms = filterPackageManager(s, pms, c)
+					selected := pms
+					// the targeted package managers are selected too, for their cached results to be invalidated
+					pms = withTargets(pms, available, c.Args().Slice())
+					return installTargets(c, selected, pms)
+				},
+			},
+			{
+				Name:        "install-local",
+				Aliases:     []string{"il"},
+				Usage:       "Install package files, such as .deb, .rpm, .apk or .flatpakref files, with the package manager of their type",
+				ArgsUsage:   "<file>...",
+				Description: "The checksums and signatures of the .deb and .rpm files are verified first, and the installation is refused if some fail verification, or can't be verified without --allow-unverified.",
+				Flags:       integrityFlags,
+				Action: func(c *cli.Context) error {
+					pms = filterPackageManager(s, pms, c)
+					return installLocal(c, pms)
+				},
+			},
+			{
+				Name:      "delete",
+				Aliases:   []string{"remove", "uninstall", "d", "rm", "un"},
+				Usage:     "Delete packages",
+				ArgsUsage: "<package|pattern>...",
+				Description: "Globs, such as 'php7.*', or regular expressions with --regex, are expanded to the installed " +
+					"packages matching them, for each package manager. The packages found are listed, and deleted once " +
+					"confirmed, or right away with --yes.",
+				Flags: []cli.Flag{
+					regexFlag,
+					&cli.BoolFlag{
+						Name:  "yes",
+						Usage: "Delete the packages matching the patterns without asking for confirmation",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					pms = filterPackageManager(s, pms, c)
+					return deletePackages(c, pms)
+				},
+			},
+			{
+				Name:    "refresh",
+				Aliases: []string{"update", "r", "re", "u", "up"},
+				Usage:   "Refresh package list",
 				Action: func(c *cli.Context) error {
 					var opts = getOptions(c)
-					pms = filterPackageManager(pms, c)
-
-					log.Printf("Installing packages for %T...\n", pms)
+					pms = filterPackageManager(s, pms, c)
 
-					pkgNames := c.Args().Slice()
+					log.Printf("Refreshing package list... for %T\n", pms)
 					for _, pm := range pms {
-						log.Printf("Installing packages for %T...\n", pm)
-						packages, err := pm.Install(pkgNames, opts)
+						log.Printf("Refreshing package list for %T...\n", pm)
+						_, err := withHooks(pm.GetPackageManager(), "refresh", nil, opts, func() ([]manager.PackageInfo, error) {
+							return nil, pm.Refresh(opts)
+						})
+						recordOperation(pm.GetPackageManager(), "refresh", nil, err, opts)
 						if err != nil {
-							fmt.Printf("Error while installing packages for %T: %+v\n%+v", pm, err, packages)
+							fmt.Printf("Error while updating package list for %T: %+v\n", pm, err)
 							continue
 						}
-						log.Printf("Installed packages for %T:\n%+v\n", pm, packages)
+						log.Printf("Refreshed package list for %T\n", pm)
 					}
 					return nil
 				},
 			},
 			{
-				Name:    "delete",
-				Aliases: []string{"remove", "uninstall", "d", "rm", "un"},
-				Usage:   "Delete packages",
+				Name:    "upgrade",
+				Aliases: []string{"U", "ug"},
+				Usage:   "Upgrade packages",
+				Description: "The packages upgraded, and when, follow the upgrade policy of the configuration file: packages excluded " +
+					"by name or glob, only security updates, a maximum number of packages, and maintenance windows outside of " +
+					"which upgrades are refused. The flags override it.",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "exclude",
+						Usage: "Don't upgrade the packages matching these `globs`, such as kernel* (default: configured upgrade exclude)",
+					},
+					&cli.BoolFlag{
+						Name:  "security-only",
+						Usage: "Only upgrade the packages fixing security issues",
+					},
+					&cli.IntFlag{
+						Name:  "max-packages",
+						Usage: "Refuse to upgrade more than `N` packages of a package manager at once; 0 for no maximum",
+					},
+					&cli.BoolFlag{
+						Name:  "ignore-window",
+						Usage: "Upgrade even outside of the configured maintenance windows",
+					},
+					&cli.StringFlag{
+						Name:  "webhook",
+						Usage: "Post the results of the upgrade to this `URL`, as JSON, in addition to the configured hooks",
+					},
+					&cli.StringFlag{
+						Name:  "report-file",
+						Usage: "Write the report of the upgrade to this `file`, such as upgrade.md to paste into a change ticket",
+					},
+					&cli.StringFlag{
+						Name:   "report-format",
+						Usage:  "Format of the report file: text, markdown or json (default: from the extension of the file, .md or .json)",
+						Action: validateReportFormat,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					pms = filterPackageManager(s, pms, c)
+					return upgradePackages(c, pms)
+				},
+			},
+			{
+				Name:      "downgrade",
+				Aliases:   []string{"dg"},
+				Usage:     "Downgrade packages to an older version",
+				ArgsUsage: "<package>=<version>...",
 				Action: func(c *cli.Context) error {
 					var opts = getOptions(c)
-					pms = filterPackageManager(pms, c)
+					pms = filterPackageManager(s, pms, c)
 					pkgNames := c.Args().Slice()
 
-					log.Printf("Deleting packages... for %T\n", pms)
+					if len(pkgNames) == 0 {
+						fmt.Println("Please specify at least one package, as name=version.")
+						return nil
+					}
+					if err := manager.RequireVersionSpecs(pkgNames); err != nil {
+						return err
+					}
 
+					out := newOutputFormatter(c, "downgrade")
 					for _, pm := range pms {
-						log.Printf("Deleting packages for %T...\n", pm)
-						packages, err := pm.Delete(pkgNames, opts)
-						if err != nil {
-							fmt.Printf("Error while deleting packages for %T: %+v\n%+v\n", pm, err, packages)
-							continue
-						}
-						log.Printf("Deleted packages for %T:\n%+v\n", pm, packages)
+						_ = downgradePackages(pm, pkgNames, opts, out)
 					}
-					return nil
+					return out.Finish()
 				},
 			},
 			{
-				Name:    "refresh",
-				Aliases: []string{"update", "r", "re", "u", "up"},
-				Usage:   "Refresh package list",
+				Name:        "download",
+				Aliases:     []string{"dl"},
+				Usage:       "Download packages and their missing dependencies without installing them",
+				ArgsUsage:   "<package>...",
+				Description: "The checksums and signatures of the downloaded .deb and .rpm files are verified, and the download fails if some fail verification, or can't be verified without --allow-unverified.",
+				Flags: append([]cli.Flag{
+					&cli.StringFlag{
+						Name:  "dir",
+						Usage: "Directory to download the packages into, instead of the cache of each package manager",
+					},
+				}, integrityFlags...),
 				Action: func(c *cli.Context) error {
 					var opts = getOptions(c)
-					pms = filterPackageManager(pms, c)
+					pms = filterPackageManager(s, pms, c)
+					pkgNames := c.Args().Slice()
 
-					log.Printf("Refreshing package list... for %T\n", pms)
+					if len(pkgNames) == 0 {
+						fmt.Println("Please specify at least one package to download.")
+						return nil
+					}
+					if dir := c.String("dir"); dir != "" {
+						var err error
+						if opts.DownloadDir, err = filepath.Abs(dir); err != nil {
+							return err
+						}
+					}
+
+					out := newOutputFormatter(c, "download")
 					for _, pm := range pms {
-						log.Printf("Refreshing package list for %T...\n", pm)
-						err := pm.Refresh(opts)
+						start := out.Start(pm.GetPackageManager())
+						d, ok := pm.(syspkg.Downloader)
+						if !ok {
+							out.Add(pm.GetPackageManager(), nil, manager.ErrOperationNotSupported, start)
+							log.Printf("Downloading packages is not supported by %T, skipping\n", pm)
+							continue
+						}
+						packages, err := d.Download(pkgNames, opts)
+						if err == nil {
+							var paths []string
+							for _, pkg := range packages {
+								if path := pkg.AdditionalData["path"]; path != "" {
+									paths = append(paths, path)
+								}
+							}
+							var results []integrity.Result
+							if results, err = verifyPackageFiles(c, paths, opts); err != nil && !out.JSON {
+								printIntegrity(results)
+							}
+						}
+						if out.Add(pm.GetPackageManager(), packages, err, start); out.JSON {
+							continue
+						}
 						if err != nil {
-							fmt.Printf("Error while updating package list for %T: %+v\n", pm, err)
+							fmt.Printf("Error while downloading packages for %T: %+v\n", pm, err)
 							continue
 						}
-						log.Printf("Refreshed package list for %T\n", pm)
+						for _, pkg := range packages {
+							fmt.Printf("%s: %s [%s] %s\n", pkg.PackageManager, pkg.Name, pkg.Version, pkg.AdditionalData["path"])
+						}
 					}
-					return nil
+					return out.Flush()
 				},
 			},
 			{
-				Name:    "upgrade",
-				Aliases: []string{"U", "ug"},
-				Usage:   "Upgrade packages",
+				Name:        "verify-download",
+				Usage:       "Verify the checksums and signatures of package files, such as downloaded .deb and .rpm files",
+				ArgsUsage:   "<file>...",
+				Description: ".deb files are verified against the checksums of the signed repository indexes of apt, and .rpm files with rpm -K. Exits with status 5 when some files fail verification, or can't be verified without --allow-unverified.",
+				Flags:       integrityFlags,
 				Action: func(c *cli.Context) error {
 					var opts = getOptions(c)
-					pms = filterPackageManager(pms, c)
+					paths := c.Args().Slice()
 
-					log.Printf("Upgrading packages... for %T\n", pms)
-
-					listUpgradablePackages(pms, opts)
-					if !opts.AssumeYes {
-						fmt.Print("\nDo you want to perform the system package upgrade? [Y/n]: ")
-						input := ""
-						_, _ = fmt.Scanln(&input)
-						input = strings.ToLower(input)
+					if len(paths) == 0 {
+						fmt.Println("Please specify at least one package file to verify.")
+						return nil
+					}
 
-						if input != "y" && input != "" {
-							fmt.Println("Upgrade cancelled.")
-							return nil
+					results, err := verifyPackageFiles(c, paths, opts)
+					if results == nil {
+						return err
+					}
+					if jsonOutput(c) {
+						encoder := json.NewEncoder(os.Stdout)
+						encoder.SetIndent("", "  ")
+						if err := encoder.Encode(results); err != nil {
+							return err
 						}
-						log.Println("User confirmed upgrade.")
+					} else {
+						printIntegrity(results)
 					}
-
-					return performUpgrade(pms, opts)
+					if err != nil {
+						return cli.Exit(err.Error(), exitVerificationFailed)
+					}
+					return nil
 				},
 			},
 			{
 				Name:    "find",
 				Aliases: []string{"search", "f"},
 				Usage:   "Find matching packages",
+				Description: "The packages found by all package managers are ranked together: exact name matches first, " +
+					"then names starting with a keyword, names containing one, and the packages found from their description, " +
+					"interleaving the package managers within each rank. --sort orders them by another field instead.\n\n" +
+					"A glob, such as 'python3-*', or a regular expression with --regex, such as 'lib.*ssl', finds the packages " +
+					"whose name matches it. It is passed to the package managers searching regular expressions, and the others " +
+					"are searched for its literal part.",
+				ArgsUsage: "<keywords>|<pattern>",
+				Flags:     searchFlags,
 				Action: func(c *cli.Context) error {
 					var opts = getOptions(c)
-					pms = filterPackageManager(pms, c)
+					pms = filterPackageManager(s, pms, c)
 					keywords := c.Args().Slice()
 
 					if len(keywords) == 0 {
 						fmt.Println("Please specify keywords to search.")
 						return nil
 					}
+					proc, err := newPackageListProcessor(c)
+					if err != nil {
+						return err
+					}
+					proc.Keywords, proc.Exact, proc.Limit = keywords, c.Bool("exact"), c.Int("limit")
+					search := func(pm syspkg.PackageManager) ([]string, error) { return keywords, nil }
+					if c.Bool("regex") || slices.ContainsFunc(keywords, manager.IsGlob) {
+						if len(keywords) > 1 {
+							return fmt.Errorf("only one pattern can be searched at a time, got %q", keywords)
+						}
+						if proc.Patterns, err = parsePatterns(c, keywords); err != nil {
+							return err
+						}
+						// the packages are ranked by the literal part of the pattern
+						proc.Keywords = []string{proc.Patterns[0].Keyword()}
+						search = func(pm syspkg.PackageManager) ([]string, error) { return searchKeywords(pm, proc.Patterns[0]) }
+					}
 					log.Printf("Finding packages for %T: %+v\n", pms, keywords)
 
+					out := newOutputFormatter(c, "find")
+					out.Dedup = len(pms) > 1
+					// the results of all package managers are ranked together, then added to the output
+					results, found := queryResults(pms, out, opts, func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+						keywords, err := search(pm)
+						if err != nil {
+							return nil, err
+						}
+						return cachedQuery(pm, daemon.OpFind, opts, keywords, func() ([]manager.PackageInfo, error) {
+							return pm.Find(keywords, opts)
+						})
+					})
+					found = proc.Process(found)
+
+					layout := newTableLayout(c)
+					list := &packageList{proc: proc, dedup: out.Dedup, columns: packageColumns(layout), layout: layout}
+					for name, result := range results {
+						if addResult(out, name, result, found); out.JSON {
+							continue
+						}
+						if errors.Is(result.err, manager.ErrOperationNotSupported) {
+							log.Printf("Searching packages is not supported by %s, skipping\n", name)
+							continue
+						}
+						if result.err != nil {
+							fmt.Printf("Error while searching packages for %s: %+v\n", name, result.err)
+						}
+					}
+					if !out.JSON {
+						list.Add(nil, found)
+						list.Flush()
+					}
+					return out.Flush()
+				},
+			},
+			{
+				Name:      "deps",
+				Aliases:   []string{"dependencies"},
+				Usage:     "Show the dependencies of a package as a tree",
+				ArgsUsage: "<package>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "reverse",
+						Aliases: []string{"r"},
+						Usage:   "Show the installed packages depending on the package instead",
+					},
+					&cli.IntFlag{
+						Name:  "depth",
+						Value: 1,
+						Usage: "Number of dependency levels to show",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+					pkgNames := c.Args().Slice()
+
+					if len(pkgNames) != 1 {
+						fmt.Println("Please specify one and only one package name.")
+						return nil
+					}
+
+					for _, pm := range pms {
+						dq, ok := pm.(syspkg.DependencyQuerier)
+						if !ok {
+							log.Printf("Querying dependencies is not supported by %T, skipping\n", pm)
+							continue
+						}
+
+						query := dq.GetDependencies
+						if c.Bool("reverse") {
+							query = dq.GetReverseDependencies
+						}
+
+						fmt.Printf("%s: %s\n", pm.GetPackageManager(), pkgNames[0])
+						if err := printDependencyTree(query, pkgNames[0], "", c.Int("depth"), map[string]bool{pkgNames[0]: true}, opts); err != nil {
+							fmt.Printf("Error while querying dependencies for %T: %+v\n", pm, err)
+						}
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "why",
+				Usage:     "Explain why a package is installed, with the chains of dependencies from the explicitly installed packages to it",
+				ArgsUsage: "<package>",
+				Description: "The packages installed explicitly are the ones apt-mark showmanual, pacman -Qe or the apk world file list; " +
+					"with the other package managers, the ones no other installed package depends on.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "Show all the chains, rather than only the shortest ones",
+					},
+					&cli.IntFlag{
+						Name:  "depth",
+						Value: 10,
+						Usage: "Maximum length of the chains of dependencies",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+					pkgNames := c.Args().Slice()
+
+					if len(pkgNames) != 1 {
+						fmt.Println("Please specify one and only one package name.")
+						return nil
+					}
+
+					var found bool
 					for _, pm := range pms {
-						pkgs, err := pm.Find(keywords, opts)
+						installed, err := explainInstalled(pm, pkgNames[0], c.Int("depth"), c.Bool("all"), opts)
 						if err != nil {
-							fmt.Printf("Error while searching packages for %T: %+v\n", pm, err)
+							fmt.Printf("Error while querying dependencies for %T: %+v\n", pm, err)
+						}
+						found = found || installed
+					}
+					if !found {
+						fmt.Printf("%s is not installed\n", pkgNames[0])
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "graph",
+				Usage:     "Export the dependency graph of a package, to visualize it with Graphviz or process it with other tools",
+				ArgsUsage: "<package>",
+				Description: "With several package managers, the DOT output has a graph for each of them, and the JSON output an array of graphs. " +
+					"For example: syspkg --apt graph --depth 3 vim | dot -Tsvg > vim.svg",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Value:   "dot",
+						Usage:   "Format of the graph: dot or json",
+					},
+					&cli.BoolFlag{
+						Name:    "reverse",
+						Aliases: []string{"r"},
+						Usage:   "Export the graph of the installed packages depending on the package instead",
+					},
+					&cli.IntFlag{
+						Name:  "depth",
+						Value: 2,
+						Usage: "Number of dependency levels of the graph",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+					pkgNames := c.Args().Slice()
+
+					if len(pkgNames) != 1 {
+						fmt.Println("Please specify one and only one package name.")
+						return nil
+					}
+					format := c.String("format")
+					if format != "dot" && format != "json" {
+						return fmt.Errorf("unknown graph format %q, expected dot or json", format)
+					}
+
+					var names []string
+					for name := range pms {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+
+					graphs := []*manager.DependencyGraph{}
+					for _, name := range names {
+						dq, ok := pms[name].(syspkg.DependencyQuerier)
+						if !ok {
+							log.Printf("Querying dependencies is not supported by %s, skipping\n", name)
 							continue
 						}
 
-						fmt.Printf("Found results for %T:\n", pm)
-						for _, pkg := range pkgs {
-							fmt.Printf("%s: %s [%s][%s] (%s)\n", pkg.PackageManager, pkg.Name, pkg.Version, pkg.NewVersion, pkg.Status)
+						query := dq.GetDependencies
+						if c.Bool("reverse") {
+							query = dq.GetReverseDependencies
 						}
+						graph, err := manager.BuildDependencyGraph(name, pkgNames[0], func(pkg string) ([]manager.PackageInfo, error) {
+							return query(pkg, opts)
+						}, c.Int("depth"), c.Bool("reverse"))
+						if err != nil {
+							log.Printf("Error while querying dependencies for %s, skipping: %+v\n", name, err)
+							continue
+						}
+						graphs = append(graphs, graph)
+					}
+
+					if format == "json" {
+						encoder := json.NewEncoder(os.Stdout)
+						encoder.SetIndent("", "  ")
+						return encoder.Encode(graphs)
+					}
+					for _, graph := range graphs {
+						fmt.Print(graph.DOT())
 					}
 					return nil
 				},
 			},
 			{
-				Name:        "show",
-				Aliases:     []string{"s"},
-				Usage:       "Please specify a subcommand. " + "Use `syspkg show --help` to see the subcommands.",
-				Description: `Show information. Please specify a subcommand. Use ` + "`syspkg show --help`" + ` to see the subcommands. Usage: ` + "`syspkg show [subcommand]`",
+				Name:      "owns",
+				Usage:     "Show which package owns a file",
+				ArgsUsage: "<path>",
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+					paths := c.Args().Slice()
+
+					if len(paths) != 1 {
+						fmt.Println("Please specify one and only one path.")
+						return nil
+					}
+
+					path, err := filepath.Abs(paths[0])
+					if err != nil {
+						return err
+					}
+					findOwners(pms, path, opts)
+					return nil
+				},
+			},
+			{
+				Name:      "files",
+				Usage:     "List the files installed by a package",
+				ArgsUsage: "<package>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the files as JSON",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+					pkgNames := c.Args().Slice()
+
+					if len(pkgNames) != 1 {
+						fmt.Println("Please specify one and only one package name.")
+						return nil
+					}
+
+					out := newOutputFormatter(c, "files")
+					for _, pm := range pms {
+						start := out.Start(pm.GetPackageManager())
+						fl, ok := pm.(syspkg.FileLister)
+						if !ok {
+							out.Add(pm.GetPackageManager(), nil, manager.ErrOperationNotSupported, start)
+							log.Printf("Listing package files is not supported by %T, skipping\n", pm)
+							continue
+						}
+
+						files, err := fl.ListFiles(pkgNames[0], opts)
+						if out.Add(pm.GetPackageManager(), nil, err, start).Files = files; out.JSON {
+							continue
+						}
+						if err != nil {
+							log.Printf("Error while listing files of %s for %T: %+v\n", pkgNames[0], pm, err)
+							continue
+						}
+						for _, file := range files {
+							fmt.Printf("%s: %s %s\n", pm.GetPackageManager(), pkgNames[0], file)
+						}
+					}
+					return out.Flush()
+				},
+			},
+			{
+				Name:      "tui",
+				Usage:     "Browse packages in a terminal UI, and select the ones to install, remove or upgrade",
+				ArgsUsage: "[keyword]...",
+				Description: "Without keywords, the upgradable packages are listed, or the installed ones with --installed; " +
+					"with keywords, the packages found by searching them are listed.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "installed",
+						Usage: "List the installed packages instead of the upgradable ones",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+					return runTUI(c, pms, opts)
+				},
+			},
+			{
+				Name:      "completion",
+				Usage:     "Print the shell completion script of bash, zsh or fish",
+				ArgsUsage: "bash|zsh|fish",
+				Description: "Load it in the current shell with: source <(syspkg completion bash).\n" +
+					"Package names are completed from a cache, refreshed when it is older than " + completionCacheTTL.String() + ".",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("please specify the shell: bash, zsh or fish")
+					}
+					return printCompletionScript(c.Args().First())
+				},
+			},
+			{
+				Name:            "__complete",
+				Usage:           "Print the completions of the last argument, for the completion scripts",
+				Hidden:          true,
+				SkipFlagParsing: true,
+				Action: func(c *cli.Context) error {
+					complete(c, s, pms, c.Args().Slice())
+					return nil
+				},
+			},
+			{
+				Name:  "cache",
+				Usage: "Manage the cache of search, list and info results",
+				Description: "The results of find, show installed, show upgradable and show package are cached in " + cache.DefaultDir() +
+					" for " + cache.DefaultTTL.String() + " (cache_ttl in the configuration file), and invalidated by the commands changing packages.",
 				Subcommands: []*cli.Command{
 					{
-						Name:    "upgradable",
-						Aliases: []string{"u"},
-						Usage:   "Show upgradable packages",
+						Name:  "clear",
+						Usage: "Remove all the cached results",
 						Action: func(c *cli.Context) error {
-							var opts = getOptions(c)
-							pms = filterPackageManager(pms, c)
+							return cache.New(cache.DefaultDir(), cfg.CacheTTL).Clear()
+						},
+					},
+					{
+						Name:  "stats",
+						Usage: "Show statistics about the cached results, by package manager",
+						Action: func(c *cli.Context) error {
+							return printCacheStats(c)
+						},
+					},
+				},
+			},
+			{
+				Name:  "daemon",
+				Usage: "Serve warm search, list and info results to the CLI over a Unix socket",
+				Description: "The daemon keeps the results of find, show installed, show upgradable and show package in memory, " +
+					"and lists the installed and upgradable packages again on schedule. The CLI uses it when it is running, " +
+					"unless --no-cache is given.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "socket",
+						Usage: "Path of the Unix socket to listen on",
+						Value: daemon.DefaultSocketPath(),
+					},
+					&cli.DurationFlag{
+						Name:  "refresh-interval",
+						Usage: "How often to list the installed and upgradable packages again, 0 to disable",
+						Value: time.Hour,
+					},
+					&cli.BoolFlag{
+						Name:  "refresh-indexes",
+						Usage: "Refresh the package indexes before listing the packages again (needs root privileges)",
+					},
+					&cli.StringFlag{
+						Name:  "socket-group",
+						Usage: "Group of the users allowed to query the daemon; only its own user and group if not set",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					opts := getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+					return runDaemon(c, pms, opts)
+				},
+			},
+			{
+				Name:  "schedule",
+				Usage: "Upgrade the packages of all package managers unattended, on schedule",
+				Description: "Installs a systemd timer, or a cron entry on systems without systemd, running syspkg upgrade as root " +
+					"without prompting, following the upgrade policy of the configuration file. The output of the runs is kept in " +
+					"the journal, or appended to " + schedule.LogFile + " with cron, and the upgrades are recorded in the history.",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "install",
+						Usage: "Install the scheduled upgrades, replacing the installed ones",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "at",
+								Usage: "When the upgrades run: a time of day, on some `days`, such as 03:00, daily 03:00 or Sat,Sun 04:30",
+								Value: "daily 03:00",
+							},
+							&cli.StringFlag{
+								Name:  "backend",
+								Usage: "Run the upgrades with a systemd timer or cron: systemd or cron (default: systemd if running)",
+							},
+							&cli.BoolFlag{
+								Name:  "security-only",
+								Usage: "Only upgrade the packages fixing security issues",
+								Value: true,
+							},
+							&cli.StringFlag{
+								Name:  "webhook",
+								Usage: "Post the results of the upgrades to this `URL`, as JSON",
+							},
+						},
+						Action: installSchedule,
+					},
+					{
+						Name:  "remove",
+						Usage: "Remove the scheduled upgrades",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "backend",
+								Usage: "Remove the systemd timer or the cron entry: systemd or cron (default: systemd if running)",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							s, err := scheduler(c)
+							if err != nil {
+								return err
+							}
+							if err := s.Remove(); err != nil {
+								return err
+							}
+							fmt.Println("Removed the scheduled upgrades.")
+							return nil
+						},
+					},
+					{
+						Name:  "show",
+						Usage: "Show the installed scheduled upgrades",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "backend",
+								Usage: "Show the systemd timer or the cron entry: systemd or cron (default: systemd if running)",
+							},
+						},
+						Action: showSchedule,
+					},
+				},
+			},
+			{
+				Name:  "managers",
+				Usage: "List the available package managers, in order of preference",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "verbose",
+						Aliases: []string{"v"},
+						Usage:   "Show the operations and options supported by each package manager",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					pms = filterPackageManager(s, pms, c)
+					// in order of preference, as configured with priority
+					names := syspkg.InstallOrder(pms, cfg.Priority)
+
+					out := newOutputFormatter(c, "managers")
+					system := platform.Detect()
+					if c.Bool("verbose") && !out.JSON {
+						fmt.Printf("platform: %s\n", system)
+					}
+					for _, name := range names {
+						start := out.Start(name)
+						capabilities := syspkg.CapabilitiesOf(pms[name])
+						result := out.Add(name, nil, nil, start)
+						if result.Capabilities, result.Platform = &capabilities, &system; out.JSON {
+							continue
+						}
+						if !c.Bool("verbose") {
+							fmt.Println(name)
+							continue
+						}
+						fmt.Printf("%s: %s\n", name, strings.Join(capabilities.Names(), ", "))
+					}
+					return out.Flush()
+				},
+			},
+			{
+				Name:        "status",
+				Usage:       "Show the status of the system after upgrades",
+				Description: "Checks whether the system needs a reboot, e.g. after a kernel upgrade, and which packages require it. All checks run unless some are selected with flags. Exits with status 4 when a reboot is required.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "reboot",
+						Usage: "Check whether a reboot is required",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+
+					var names []string
+					for name := range pms {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+
+					required := false
+					out := newOutputFormatter(c, "status")
+					for _, name := range names {
+						start := out.Start(name)
+						r, ok := pms[name].(syspkg.RebootChecker)
+						if !ok {
+							out.Add(name, nil, manager.ErrOperationNotSupported, start)
+							log.Printf("Checking for reboots is not supported by %s, skipping\n", name)
+							continue
+						}
+						status, err := r.NeedsReboot(opts)
+						required = required || status.Required
+						if out.Add(name, nil, err, start).Reboot = &status; out.JSON {
+							continue
+						}
+						switch {
+						case err != nil:
+							fmt.Printf("%s: error while checking for reboots: %+v\n", name, err)
+						case !status.Required:
+							fmt.Printf("%s: no reboot required\n", name)
+						default:
+							fmt.Printf("%s: reboot required: %s", name, strings.Join(status.Reasons, "; "))
+							if len(status.Packages) > 0 {
+								fmt.Printf(" (%s)", strings.Join(status.Packages, ", "))
+							}
+							fmt.Println()
+						}
+					}
+					if err := out.Flush(); err != nil {
+						return err
+					}
+
+					if required {
+						return cli.Exit("reboot required", exitRebootRequired)
+					}
+					return nil
+				},
+			},
+			{
+				Name:        "verify",
+				Usage:       "Verify the installed packages against the files they installed, and their signatures",
+				ArgsUsage:   "[packages...]",
+				Description: "Checks the provided packages, or all installed packages if none are given, for modified or missing files, and missing or invalid signatures, and lists the packages having problems. Modified configuration files are listed, but are not failures. Exits with status 5 when packages fail verification, and 6 when package managers fail to verify them.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "details",
+						Usage: "List the files and signatures of the packages having problems",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+					pkgNames := c.Args().Slice()
+
+					var names []string
+					for name := range pms {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+
+					failed := 0
+					out := newOutputFormatter(c, "verify")
+					for _, name := range names {
+						start := out.Start(name)
+						v, ok := pms[name].(syspkg.Verifier)
+						if !ok {
+							out.Add(name, nil, manager.ErrOperationNotSupported, start)
+							log.Printf("Verifying packages is not supported by %s, skipping\n", name)
+							continue
+						}
+						results, err := v.Verify(pkgNames, opts)
+						for _, result := range results {
+							if result.Failed() {
+								failed++
+							}
+						}
+						if out.Add(name, nil, err, start).Verification = results; out.JSON {
+							continue
+						}
+						if err != nil {
+							fmt.Printf("%s: error while verifying packages: %+v\n", name, err)
+							continue
+						}
+						if len(results) == 0 {
+							fmt.Printf("%s: no problems found\n", name)
+						}
+						for _, result := range results {
+							fmt.Printf("%s: %s: %s\n", name, result.Name, result.Summary())
+							if c.Bool("details") {
+								printVerification(result)
+							}
+						}
+					}
+					if err := out.Flush(); err != nil {
+						return err
+					}
+
+					if failed > 0 {
+						return cli.Exit(fmt.Sprintf("%d packages failed verification", failed), exitVerificationFailed)
+					}
+					return out.Err()
+				},
+			},
+			{
+				Name:        "restarts",
+				Usage:       "Show the processes using deleted libraries of upgraded packages, and restart their services",
+				Description: "Lists the processes still using the old versions of upgraded executables and libraries, and the systemd services they belong to. With --apply, the services are restarted, except the ones ending the user sessions, such as dbus.service, which need a reboot.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "apply",
+						Usage: "Restart the services of the processes with systemctl",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the processes and services as JSON",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+
+					processes, err := restarts.Scan(opts)
+					if err != nil {
+						return err
+					}
+					if processes == nil {
+						processes = []restarts.Process{}
+					}
+
+					restart, skipped := []string{}, []string{}
+					for _, service := range restarts.Services(processes) {
+						if restarts.Restartable(service) {
+							restart = append(restart, service)
+						} else {
+							skipped = append(skipped, service)
+						}
+					}
+
+					if jsonOutput(c) {
+						encoder := json.NewEncoder(os.Stdout)
+						encoder.SetIndent("", "  ")
+						err := encoder.Encode(struct {
+							Processes []restarts.Process `json:"processes"`
+							Restart   []string           `json:"restart"`
+							Reboot    []string           `json:"reboot"`
+						}{processes, restart, skipped})
+						if err != nil {
+							return err
+						}
+					} else {
+						for _, p := range processes {
+							service := p.Service
+							if service == "" {
+								service = "no service"
+							}
+							fmt.Printf("%d %s (%s): %s\n", p.PID, p.Command, service, strings.Join(p.Files, ", "))
+						}
+						if len(restart) > 0 {
+							fmt.Printf("Services to restart: %s\n", strings.Join(restart, " "))
+						}
+						if len(skipped) > 0 {
+							fmt.Printf("Services needing a reboot: %s\n", strings.Join(skipped, " "))
+						}
+					}
+
+					if !c.Bool("apply") || len(restart) == 0 {
+						return nil
+					}
+					if opts.DryRun {
+						log.Printf("Dry run, not restarting %s\n", strings.Join(restart, " "))
+						return nil
+					}
+					log.Printf("Restarting %s...\n", strings.Join(restart, " "))
+					return restarts.Restart(restart, opts)
+				},
+			},
+			{
+				Name:        "history",
+				Usage:       "Show or roll back the transactions performed through syspkg",
+				Description: "The history of the install, delete and upgrade transactions performed through syspkg is stored in " + history.DefaultPath() + ".",
+				Subcommands: []*cli.Command{
+					{
+						Name:    "list",
+						Aliases: []string{"l", "ls"},
+						Usage:   "List the recorded transactions",
+						Action:  listHistory,
+					},
+					{
+						Name:      "rollback",
+						Usage:     "Undo a transaction, by deleting the packages it installed, installing the packages it deleted, or downgrading the packages it upgraded",
+						ArgsUsage: "<transaction-id>",
+						Action: func(c *cli.Context) error {
+							return rollbackTransaction(c, pms)
+						},
+					},
+				},
+			},
+			{
+				Name:        "snapshot",
+				Usage:       "Save or restore the sets of installed packages",
+				Description: "Snapshots are stored in " + snapshot.DefaultDir() + ".",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "save",
+						Usage:     "Save the installed packages of each package manager in a snapshot",
+						ArgsUsage: "<name>",
+						Action: func(c *cli.Context) error {
+							pms = filterPackageManager(s, pms, c)
+							return saveSnapshot(c, pms)
+						},
+					},
+					{
+						Name:      "restore",
+						Usage:     "Install, delete, upgrade and downgrade packages to restore a snapshot",
+						ArgsUsage: "<name>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "keep",
+								Usage: "Keep the packages installed after the snapshot was taken",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							pms = filterPackageManager(s, pms, c)
+							return restoreSnapshot(c, pms)
+						},
+					},
+					{
+						Name:    "list",
+						Aliases: []string{"l", "ls"},
+						Usage:   "List the saved snapshots",
+						Action:  listSnapshots,
+					},
+				},
+			},
+			{
+				Name:      "apply",
+				Usage:     "Install, delete and upgrade packages to match a manifest",
+				ArgsUsage: "<manifest.yaml>",
+				Action: func(c *cli.Context) error {
+					return applyManifest(c, pms)
+				},
+			},
+			{
+				Name:      "module",
+				Usage:     "Run as an Ansible module, with the arguments in a JSON file, printing the result as JSON",
+				ArgsUsage: "<args.json>",
+				Description: "The arguments follow the ones of the package module of Ansible: name, a package or a list of " +
+					"packages, with an optional version as name=version; state, present, absent or latest; use, the package " +
+					"manager, or auto; and update_cache. Check mode is supported. To use syspkg as a module, save a script " +
+					"running it, such as\n\n  #!/bin/sh\n  # WANT_JSON\n  exec syspkg module \"$1\"\n\nas library/syspkg in " +
+					"the directory of the playbook.",
+				Action: func(c *cli.Context) error {
+					return runModule(c, s, pms)
+				},
+			},
+			{
+				Name:        "audit",
+				Usage:       "Scan the installed packages for known vulnerabilities, using the OSV database",
+				Description: "The names and versions of the installed packages are sent to the OSV API (" + audit.DefaultOSVURL + "), unless a local copy of the database is used with --db. Exits with status 3 when vulnerabilities are found.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "db",
+						Usage: "Directory of OSV JSON records to use instead of the OSV API",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the vulnerabilities found as JSON",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+
+					var source audit.Source = &audit.OSVClient{}
+					if dir := c.String("db"); dir != "" {
+						source = &audit.LocalDB{Dir: dir}
+					}
+
+					release, err := audit.ReadOSRelease("/etc/os-release")
+					if err != nil {
+						log.Printf("Error while reading the distribution release, system packages are not scanned: %+v\n", err)
+					}
+
+					findings, err := audit.Scan(source, listInstalled(pms, opts), release)
+					if err != nil {
+						return err
+					}
+
+					if c.Bool("json") {
+						encoder := json.NewEncoder(os.Stdout)
+						encoder.SetIndent("", "  ")
+						if err := encoder.Encode(findings); err != nil {
+							return err
+						}
+					} else {
+						for _, f := range findings {
+							fmt.Println(describeFinding(f))
+						}
+					}
+
+					if len(findings) > 0 {
+						notifyVulnerable(findings)
+						return cli.Exit(fmt.Sprintf("%d vulnerabilities found", len(findings)), exitVulnerable)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "sbom",
+				Usage: "Export a Software Bill of Materials of the installed packages",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "export",
+						Usage: "Export the installed packages of each package manager as a CycloneDX or SPDX JSON document",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"f"},
+								Value:   string(sbom.FormatCycloneDX),
+								Usage:   "Format of the document: cyclonedx or spdx",
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "Write the document to a file instead of the standard output",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							opts.Licenses = true
+							pms = filterPackageManager(s, pms, c)
+
+							format, err := sbom.ParseFormat(c.String("format"))
+							if err != nil {
+								return err
+							}
+
+							doc, err := sbom.Generate(format, listInstalled(pms, opts), time.Now())
+							if err != nil {
+								return err
+							}
+							doc = append(doc, '\n')
+
+							if output := c.String("output"); output != "" {
+								return os.WriteFile(output, doc, 0o644)
+							}
+							_, err = os.Stdout.Write(doc)
+							return err
+						},
+					},
+				},
+			},
+			{
+				Name:  "duplicates",
+				Usage: "Show the packages installed by several package managers, such as firefox installed both by apt and snap",
+				Description: "Packages are matched by name, lowercased, and by the last component of the application IDs of flatpak. " +
+					"Each of them is listed with its version in every package manager installing it.",
+				Action: func(c *cli.Context) error {
+					var opts = getOptions(c)
+					pms = filterPackageManager(s, pms, c)
+
+					out := newOutputFormatter(c, "duplicates")
+					out.Dedup = true
+					results, installed := queryResults(pms, out, opts, func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+						return cachedQuery(pm, daemon.OpInstalled, opts, nil, func() ([]manager.PackageInfo, error) {
+							return pm.ListInstalled(opts)
+						})
+					})
+					duplicates := manager.FindDuplicates(installed)
+
+					names := make(map[string]bool, len(duplicates))
+					for _, duplicate := range duplicates {
+						names[duplicate.Name] = true
+					}
+					var kept []manager.PackageInfo
+					for _, pkg := range installed {
+						if names[manager.DuplicateKey(pkg)] {
+							kept = append(kept, pkg)
+						}
+					}
+					for name, result := range results {
+						addResult(out, name, result, kept)
+						if result.err != nil && !out.JSON {
+							fmt.Printf("Error while listing installed packages for %s: %+v\n", name, result.err)
+						}
+					}
+					if out.JSON {
+						return out.Flush()
+					}
+					printDuplicates(duplicates)
+					return nil
+				},
+			},
+			{
+				Name:  "group",
+				Usage: "Manage groups of packages installed together, such as the tasks of tasksel, the patterns of zypper or the groups of pacman",
+				Subcommands: []*cli.Command{
+					{
+						Name:    "list",
+						Aliases: []string{"ls"},
+						Usage:   "List the groups, and whether they are installed",
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							var groups []manager.GroupInfo
+							for _, pm := range pms {
+								g, ok := pm.(syspkg.GroupManager)
+								if !ok {
+									log.Printf("Managing groups is not supported by %T, skipping\n", pm)
+									continue
+								}
+								pmGroups, err := g.ListGroups(opts)
+								if err != nil {
+									fmt.Printf("Error while listing groups for %T: %+v\n", pm, err)
+									continue
+								}
+								groups = append(groups, pmGroups...)
+							}
+							if jsonOutput(c) {
+								encoder := json.NewEncoder(os.Stdout)
+								encoder.SetIndent("", "  ")
+								return encoder.Encode(groups)
+							}
+							printGroups(groups)
+							return nil
+						},
+					},
+					{
+						Name:      "install",
+						Aliases:   []string{"i"},
+						Usage:     "Install the packages of groups",
+						ArgsUsage: "<group>...",
+						Action: func(c *cli.Context) error {
+							return groupPackages(s, pms, c, true)
+						},
+					},
+					{
+						Name:      "remove",
+						Aliases:   []string{"delete", "rm"},
+						Usage:     "Remove the packages of groups",
+						ArgsUsage: "<group>...",
+						Action: func(c *cli.Context) error {
+							return groupPackages(s, pms, c, false)
+						},
+					},
+				},
+			},
+			{
+				Name:    "repo",
+				Aliases: []string{"remote"},
+				Usage:   "Manage the repositories packages are installed from, such as the remotes of flatpak",
+				Subcommands: []*cli.Command{
+					{
+						Name:    "list",
+						Aliases: []string{"ls"},
+						Usage:   "List the repositories",
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							for _, pm := range pms {
+								r, ok := pm.(syspkg.RepositoryLister)
+								if !ok {
+									log.Printf("Listing repositories is not supported by %T, skipping\n", pm)
+									continue
+								}
+								repos, err := r.ListRepositories(opts)
+								if err != nil {
+									fmt.Printf("Error while listing repositories for %T: %+v\n", pm, err)
+									continue
+								}
+								printRepositories(repos)
+							}
+							return nil
+						},
+					},
+					{
+						Name:      "add",
+						Usage:     "Add a repository",
+						ArgsUsage: "<name> <url>",
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							if c.NArg() != 2 {
+								fmt.Println("Please specify the name and the URL of the repository.")
+								return nil
+							}
+							name, url := c.Args().Get(0), c.Args().Get(1)
+
+							for _, pm := range pms {
+								r, ok := pm.(syspkg.RepositoryManager)
+								if !ok {
+									log.Printf("Managing repositories is not supported by %T, skipping\n", pm)
+									continue
+								}
+								repos, err := r.AddRepository(name, url, opts)
+								recordOperation(pm.GetPackageManager(), "repo add", []string{name}, err, opts)
+								if err != nil {
+									fmt.Printf("Error while adding the repository for %T: %+v\n", pm, err)
+									continue
+								}
+								printRepositories(repos)
+							}
+							return nil
+						},
+					},
+					{
+						Name:      "remove",
+						Aliases:   []string{"delete", "rm"},
+						Usage:     "Remove a repository",
+						ArgsUsage: "<name>",
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							if c.NArg() != 1 {
+								fmt.Println("Please specify one and only one repository name.")
+								return nil
+							}
+
+							for _, pm := range pms {
+								r, ok := pm.(syspkg.RepositoryManager)
+								if !ok {
+									log.Printf("Managing repositories is not supported by %T, skipping\n", pm)
+									continue
+								}
+								repos, err := r.RemoveRepository(c.Args().First(), opts)
+								recordOperation(pm.GetPackageManager(), "repo remove", []string{c.Args().First()}, err, opts)
+								if err != nil {
+									fmt.Printf("Error while removing the repository for %T: %+v\n", pm, err)
+									continue
+								}
+								printRepositories(repos)
+							}
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "sources",
+				Usage: "Review the sources packages are installed from",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "audit",
+						Usage: "List the enabled repositories, flagging the third-party and unsigned ones, with the installed packages coming from them",
+						Description: "Repositories are third-party when their origin isn't one of the distribution or of the default repositories " +
+							"of the package manager, such as PPAs, vendor repositories or other flatpak remotes, and unsigned when their " +
+							"packages aren't verified, such as the apt sources marked trusted=yes. Exits with status 7 when such sources are found.",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "json",
+								Usage: "Print the audit of all the repositories as JSON",
+							},
+							&cli.BoolFlag{
+								Name:  "flagged",
+								Usage: "Only list the third-party and unsigned repositories",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							audits := auditSources(pms, opts)
+							var flagged int
+							for _, a := range audits {
+								if a.Flagged() {
+									flagged++
+								}
+							}
+
+							if c.Bool("json") {
+								encoder := json.NewEncoder(os.Stdout)
+								encoder.SetIndent("", "  ")
+								if err := encoder.Encode(audits); err != nil {
+									return err
+								}
+							} else {
+								for _, a := range audits {
+									if a.Flagged() || !c.Bool("flagged") {
+										fmt.Println(describeSourceAudit(a))
+									}
+								}
+							}
+
+							if flagged > 0 {
+								return cli.Exit(fmt.Sprintf("%d third-party or unsigned sources found", flagged), exitUntrustedSources)
+							}
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "key",
+				Usage: "Manage the signing keys used to verify repositories",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "import",
+						Aliases:   []string{"add"},
+						Usage:     "Import a signing key from a file or an HTTPS URL",
+						ArgsUsage: "<file|url>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "name",
+								Usage: "Name of the keyring (apt) or remote (flatpak) to import the key for, by default the base name of the key file",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							if c.NArg() != 1 {
+								fmt.Println("Please specify one and only one key file or URL.")
+								return nil
+							}
+							source := c.Args().First()
+							name := c.String("name")
+							if name == "" {
+								name = strings.TrimSuffix(path.Base(source), path.Ext(source))
+							}
+
+							file, err := fetchKey(source)
+							if err != nil {
+								return err
+							}
+							if file != source {
+								defer os.Remove(file)
+							}
+
+							for _, pm := range pms {
+								k, ok := pm.(syspkg.KeyManager)
+								if !ok {
+									log.Printf("Managing keys is not supported by %T, skipping\n", pm)
+									continue
+								}
+								keys, err := k.ImportKey(name, file, opts)
+								recordOperation(pm.GetPackageManager(), "key import", []string{name}, err, opts)
+								if err != nil {
+									fmt.Printf("Error while importing the key for %T: %+v\n", pm, err)
+									continue
+								}
+								printKeys(keys)
+							}
+							return nil
+						},
+					},
+					{
+						Name:    "list",
+						Aliases: []string{"ls"},
+						Usage:   "List the trusted signing keys",
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							for _, pm := range pms {
+								k, ok := pm.(syspkg.KeyManager)
+								if !ok {
+									log.Printf("Managing keys is not supported by %T, skipping\n", pm)
+									continue
+								}
+								keys, err := k.ListKeys(opts)
+								if errors.Is(err, manager.ErrOperationNotSupported) {
+									log.Printf("Listing keys is not supported by %T, skipping\n", pm)
+									continue
+								}
+								if err != nil {
+									fmt.Printf("Error while listing keys for %T: %+v\n", pm, err)
+									continue
+								}
+								printKeys(keys)
+							}
+							return nil
+						},
+					},
+					{
+						Name:      "remove",
+						Aliases:   []string{"delete", "rm"},
+						Usage:     "Remove a trusted signing key",
+						ArgsUsage: "<id>",
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							if c.NArg() != 1 {
+								fmt.Println("Please specify one and only one key ID.")
+								return nil
+							}
+
+							for _, pm := range pms {
+								k, ok := pm.(syspkg.KeyManager)
+								if !ok {
+									log.Printf("Managing keys is not supported by %T, skipping\n", pm)
+									continue
+								}
+								keys, err := k.RemoveKey(c.Args().First(), opts)
+								recordOperation(pm.GetPackageManager(), "key remove", []string{c.Args().First()}, err, opts)
+								if errors.Is(err, manager.ErrOperationNotSupported) {
+									log.Printf("Removing keys is not supported by %T, skipping\n", pm)
+									continue
+								}
+								if err != nil {
+									fmt.Printf("Error while removing the key for %T: %+v\n", pm, err)
+									continue
+								}
+								printKeys(keys)
+							}
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:      "hold",
+				Aliases:   []string{"pin", "lock"},
+				Usage:     "Hold packages at their installed version, so that they are not upgraded",
+				ArgsUsage: "<package>...",
+				Action: func(c *cli.Context) error {
+					return holdPackages(s, pms, c, true)
+				},
+			},
+			{
+				Name:      "unhold",
+				Aliases:   []string{"unpin", "unlock"},
+				Usage:     "Release the hold on packages",
+				ArgsUsage: "<package>...",
+				Action: func(c *cli.Context) error {
+					return holdPackages(s, pms, c, false)
+				},
+			},
+			{
+				Name:        "show",
+				Aliases:     []string{"s", "list"},
+				Usage:       "Please specify a subcommand. " + "Use `syspkg show --help` to see the subcommands.",
+				Description: `Show information. Please specify a subcommand. Use ` + "`syspkg show --help`" + ` to see the subcommands. Usage: ` + "`syspkg show [subcommand]`",
+				Subcommands: []*cli.Command{
+					{
+						Name:    "upgradable",
+						Aliases: []string{"u"},
+						Usage:   "Show upgradable packages",
+						Flags:   listFlags,
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+							proc, err := newPackageListProcessor(c)
+							if err != nil {
+								return err
+							}
+
+							log.Println("Showing upgradable packages...")
+
+							out := newOutputFormatter(c, "show upgradable")
+							listUpgradablePackages(pms, opts, out, proc, newTableLayout(c))
+							return out.Flush()
+						},
+					},
+					{
+						Name:    "security",
+						Aliases: []string{"sec"},
+						Usage:   "Show security updates, with the CVEs they fix when known",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "json",
+								Usage: "Print the security updates as JSON",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							log.Println("Showing security updates...")
 
-							log.Println("Showing upgradable packages...")
+							var updates []manager.PackageInfo
+							out := newOutputFormatter(c, "show security")
+							for _, pm := range pms {
+								start := out.Start(pm.GetPackageManager())
+								l, ok := pm.(syspkg.SecurityUpdateLister)
+								if !ok {
+									out.Add(pm.GetPackageManager(), nil, manager.ErrOperationNotSupported, start)
+									log.Printf("Listing security updates is not supported by %T, skipping\n", pm)
+									continue
+								}
+								packages, err := l.ListSecurityUpdates(opts)
+								if out.Add(pm.GetPackageManager(), packages, err, start); out.JSON {
+									continue
+								}
+								if err != nil {
+									fmt.Printf("Error while listing security updates for %T: %+v\n", pm, err)
+									continue
+								}
+								updates = append(updates, packages...)
+							}
 
-							listUpgradablePackages(pms, opts)
+							if out.JSON {
+								return out.Flush()
+							}
+							layout := newTableLayout(c)
+							printPackageTable(os.Stdout, updates, packageColumns(layout,
+								packageColumn{header: "SEVERITY", value: func(pkg manager.PackageInfo) string { return pkg.AdditionalData["severity"] }},
+								packageColumn{header: "CVE", value: func(pkg manager.PackageInfo) string { return pkg.AdditionalData["cve"] }},
+							), layout)
 							return nil
 						},
 					},
 					{
 						Name:    "package",
-						Aliases: []string{"p"},
+						Aliases: []string{"p", "info"},
 						Usage:   "Show package information",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "provenance",
+								Usage: "Show where the package comes from, and whether it is from a third-party source (a PPA, a vendor repository, another flatpak remote...)",
+							},
+						},
 						Action: func(c *cli.Context) error {
 							var opts = getOptions(c)
-							pms = filterPackageManager(pms, c)
+							pms = filterPackageManager(s, pms, c)
 							pkgNames := c.Args().Slice()
 
 							if len(pkgNames) != 1 {
@@ -216,9 +1754,27 @@ func main() {
 
 							log.Println("Showing package information...")
 
+							out := newOutputFormatter(c, "show package")
 							for _, pm := range pms {
 								log.Printf("Showing package information for %T...\n", pm)
-								pkg, err := pm.GetPackageInfo(pkgNames[0], opts)
+								start := out.Start(pm.GetPackageManager())
+								pkgs, err := cachedQuery(pm, daemon.OpInfo, opts, pkgNames[:1], func() ([]manager.PackageInfo, error) {
+									pkg, err := pm.GetPackageInfo(pkgNames[0], opts)
+									if err != nil {
+										return nil, err
+									}
+									return []manager.PackageInfo{pkg}, nil
+								})
+								var pkg manager.PackageInfo
+								if err == nil {
+									pkg = pkgs[0]
+									if c.Bool("provenance") {
+										pkgs = []manager.PackageInfo{withProvenance(pkg)}
+									}
+								}
+								if out.Add(pm.GetPackageManager(), pkgs, err, start); out.JSON {
+									continue
+								}
 								if err != nil {
 									fmt.Printf("Error while showing package info for %T: %+v\n", pm, err)
 									continue
@@ -226,34 +1782,105 @@ func main() {
 
 								fmt.Printf("Search results for %T:\n", pm)
 								fmt.Printf("%s: %s [%s][%s] (%s) %s:%s\n", pkg.PackageManager, pkg.Name, pkg.Version, pkg.NewVersion, pkg.Status, pkg.Category, pkg.Arch)
+								for _, field := range []struct{ name, value string }{
+									{"License", pkg.License},
+									{"Homepage", pkg.Homepage},
+									{"Maintainer", pkg.Maintainer},
+									{"Installed size", formatSize(pkg.SizeInstalled)},
+									{"Download size", formatSize(pkg.SizeDownload)},
+									{"Origin", pkg.Origin},
+								} {
+									if field.value != "" {
+										fmt.Printf("  %s: %s\n", field.name, field.value)
+									}
+								}
+								if c.Bool("provenance") {
+									fmt.Printf("  Provenance: %s\n", describeProvenance(pkg))
+								}
 							}
-							return nil
+							return out.Flush()
+						},
+					},
+					{
+						Name:  "held",
+						Usage: "Show held packages",
+						Action: func(c *cli.Context) error {
+							var opts = getOptions(c)
+							pms = filterPackageManager(s, pms, c)
+
+							log.Println("Showing held packages...")
+
+							out := newOutputFormatter(c, "show held")
+							for _, pm := range pms {
+								start := out.Start(pm.GetPackageManager())
+								h, ok := pm.(syspkg.Holder)
+								if !ok {
+									out.Add(pm.GetPackageManager(), nil, manager.ErrOperationNotSupported, start)
+									log.Printf("Holding packages is not supported by %T, skipping\n", pm)
+									continue
+								}
+
+								pkgs, err := h.ListHeld(opts)
+								if out.Add(pm.GetPackageManager(), pkgs, err, start); out.JSON {
+									continue
+								}
+								if err != nil {
+									fmt.Printf("Error while showing held packages for %T: %+v\n", pm, err)
+									continue
+								}
+								layout := newTableLayout(c)
+								printPackageTable(os.Stdout, pkgs, packageColumns(layout), layout)
+							}
+							return out.Flush()
 						},
 					},
 					{
-						Name:    "installed",
-						Aliases: []string{"i"},
-						Usage:   "Show installed packages",
+						Name:      "installed",
+						Aliases:   []string{"i"},
+						Usage:     "Show installed packages",
+						ArgsUsage: "[pattern...]",
+						Description: "Only the packages whose name matches one of the patterns are shown, if any: globs, such as " +
+							"'python3-*', or regular expressions with --regex.",
+						Flags: append([]cli.Flag{regexFlag, licensesFlag}, listFlags...),
 						Action: func(c *cli.Context) error {
 							var opts = getOptions(c)
-							pms = filterPackageManager(pms, c)
+							pms = filterPackageManager(s, pms, c)
+							proc, err := newPackageListProcessor(c)
+							if err != nil {
+								return err
+							}
+							if proc.Patterns, err = parsePatterns(c, c.Args().Slice()); err != nil {
+								return err
+							}
 
 							log.Println("Showing installed packages...")
 
+							out := newOutputFormatter(c, "show installed")
+							out.Dedup = len(pms) > 1
+							layout := newTableLayout(c)
+							var extra []packageColumn
+							if proc.SortBy == manager.SortKeySize && !layout.wide {
+								extra = append(extra, sizeColumn)
+							}
+							list := &packageList{proc: proc, header: "Search results for %T:\n", dedup: out.Dedup, columns: packageColumns(layout, extra...), layout: layout}
 							for _, pm := range pms {
 								log.Printf("Showing installed packages for %T...\n", pm)
-								pkgs, err := pm.ListInstalled(opts)
+								start := out.Start(pm.GetPackageManager())
+								pkgs, err := cachedQuery(pm, daemon.OpInstalled, opts, nil, func() ([]manager.PackageInfo, error) {
+									return pm.ListInstalled(opts)
+								})
+								pkgs = proc.Process(pkgs)
+								if out.Add(pm.GetPackageManager(), pkgs, err, start); out.JSON {
+									continue
+								}
 								if err != nil {
 									fmt.Printf("Error while showing installed packages for %T: %+v\n", pm, err)
 									continue
 								}
-
-								fmt.Printf("Search results for %T:\n", pm)
-								for _, pkg := range pkgs {
-									fmt.Printf("%s: %s [%s][%s] (%s)\n", pkg.PackageManager, pkg.Name, pkg.Version, pkg.NewVersion, pkg.Status)
-								}
+								list.Add(pm, pkgs)
 							}
-							return nil
+							list.Flush()
+							return out.Flush()
 						},
 					},
 				},
@@ -290,6 +1917,127 @@ func main() {
 				Aliases: []string{"v"},
 				Usage:   "Verbose - Show more information.",
 			},
+			&cli.IntFlag{
+				Name:  "max-concurrent",
+				Usage: "Maximum number of package managers run at the same time, 0 for no limit. (default: concurrency in the configuration file)",
+			},
+			&cli.StringFlag{
+				Name:  "log-level",
+				Usage: "Level of the logs: debug, info, warn or error. (default: debug with --verbose or --debug, info otherwise)",
+			},
+			&cli.StringFlag{
+				Name:  "log-format",
+				Usage: "Format of the logs, written to stderr: text, or json for one JSON object per line.",
+				Value: logFormatText,
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the results as JSON, in a versioned envelope with the result of each package manager. (schema " + outputSchema + ")",
+			},
+			&cli.StringFlag{
+				Name:  "config",
+				Usage: "Configuration file of the defaults of syspkg. (default: $SYSPKG_CONFIG or " + config.DefaultPath() + ")",
+			},
+			&cli.StringFlag{
+				Name:  "container",
+				Usage: "ID or name of a running container to run the command in, with docker exec or podman exec, instead of this host.",
+			},
+			&cli.StringFlag{
+				Name:  "root",
+				Usage: "Directory of a mounted root filesystem, such as an extracted image, to run the command in with chroot, instead of this host.",
+			},
+			&cli.StringFlag{
+				Name:  "install-root",
+				Usage: "Root directory of the system to operate on, such as an image being built, with the package managers of this host supporting it (apt, apk, pacman, zypper).",
+			},
+			&cli.StringFlag{
+				Name:  "arch",
+				Usage: "Architecture of the packages to install or remove, such as i386, with the package managers supporting foreign architectures (apt, zypper).",
+			},
+			&cli.StringFlag{
+				Name:  "hosts",
+				Usage: "File listing the hosts to run the command on over SSH instead of this host, one per line (host, user@host or ssh://user@host:port).",
+			},
+			&cli.IntFlag{
+				Name:  "max-hosts",
+				Usage: "Maximum number of hosts the command runs on at the same time, with --hosts. (default: 10)",
+			},
+			&cli.StringFlag{
+				Name:   "output",
+				Usage:  "Output format: text, json (same as --json), ndjson to stream the events of the command as JSON, one per line, porcelain for the stable tab-separated records of the packages, csv for the packages as CSV rows with a header, or yaml for the JSON envelope as YAML. (porcelain " + porcelainVersion + ")",
+				Value:  outputText,
+				Action: validateOutputFormat,
+			},
+			&cli.StringFlag{
+				Name:   "color",
+				Usage:  "Color the status of the packages in text output: auto (when printing to a terminal, and NO_COLOR is not set), always, or never.",
+				Value:  colorAuto,
+				Action: validateColorMode,
+			},
+			&cli.BoolFlag{
+				Name:  "wide",
+				Usage: "List the architecture, category, origin and size of the packages too, in text output.",
+			},
+			&cli.BoolFlag{
+				Name:  "no-truncate",
+				Usage: "Print the tables of packages in full, rather than truncating their widest columns to fit the terminal.",
+			},
+			&cli.BoolFlag{
+				Name:    "null",
+				Aliases: []string{"z"},
+				Usage:   "With --output porcelain, terminate the records with NUL instead of newlines, such as for xargs -0.",
+			},
+			&cli.BoolFlag{
+				Name:  "json-stream",
+				Usage: "Stream the progress of installs and upgrades as JSON events, one per line.",
+			},
+			&cli.StringFlag{
+				Name:   "scope",
+				Usage:  "Installation to operate on, for package managers having both (flatpak): user or system. (default: the package manager's)",
+				Action: validateScope,
+			},
+			&cli.StringFlag{
+				Name:   "sudo",
+				Usage:  "Run commands changing the system as root with sudo, doas or pkexec: auto (when needed), never, or always. (default: auto)",
+				Action: validateSudoMode,
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Kill the commands of package managers running longer than this duration. (e.g. 90s, 10m; default: no timeout)",
+			},
+			&cli.DurationFlag{
+				Name:  "lock-wait",
+				Usage: "Wait up to this duration for the lock of package managers held by another process, such as unattended-upgrades. (e.g. 5m; default: fail at once)",
+			},
+			&cli.BoolFlag{
+				Name:  "auto-refresh",
+				Usage: "Refresh the package index and retry once when an operation fails because it is stale or missing (apt)",
+			},
+			&cli.StringFlag{
+				Name:  "proxy",
+				Usage: "Download through this HTTP proxy with the package managers. (e.g. http://proxy.example.com:3128)",
+			},
+			&cli.StringFlag{
+				Name:  "max-download",
+				Usage: "Don't install or upgrade packages when the package managers would download more than this size. (e.g. 500MB, 2GiB)",
+			},
+			&cli.StringFlag{
+				Name:  "min-free-space",
+				Usage: "Don't install or upgrade packages when less than this disk space would be left free on /. (e.g. 1GB)",
+			},
+			&cli.BoolFlag{
+				Name:  "no-cache",
+				Usage: "Don't use the cache of search, list and info results",
+			},
+			&cli.StringFlag{
+				Name:  "env",
+				Usage: "Environment to operate on, for package managers with several environments. (e.g. a conda environment name)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "category",
+				Aliases: []string{"c"},
+				Usage:   "Use all package managers of the given categories: system, universal, user, language, container or firmware. (e.g. -c system,universal)",
+			},
 			&cli.BoolFlag{
 				Name:  "apt",
 				Usage: "Use apt package manager",
@@ -300,31 +2048,72 @@ func main() {
 				Usage:  "Use yum package manager",
 				Hidden: true,
 			},
+			&cli.BoolFlag{
+				Name:  "cargo",
+				Usage: "Use cargo package manager (Rust binaries)",
+			},
+			&cli.BoolFlag{
+				Name:  "conda",
+				Usage: "Use conda package manager",
+			},
 			&cli.BoolFlag{
 				Name:   "dnf",
 				Usage:  "Use dnf package manager",
 				Hidden: true,
 			},
 			&cli.BoolFlag{
-				Name:   "pacman",
-				Usage:  "Use pacman package manager",
-				Hidden: true,
+				Name:  "fwupd",
+				Usage: "Use fwupd firmware manager",
 			},
 			&cli.BoolFlag{
-				Name:   "apk",
-				Usage:  "Use apk package manager",
-				Hidden: true,
+				Name:  "gem",
+				Usage: "Use gem package manager (Ruby gems)",
 			},
 			&cli.BoolFlag{
-				Name:   "zypper",
-				Usage:  "Use zypper package manager",
-				Hidden: true,
+				Name:  "gobin",
+				Usage: "Use go install binaries ($GOBIN)",
+			},
+			&cli.BoolFlag{
+				Name:  "nix",
+				Usage: "Use nix package manager (user profile)",
+			},
+			&cli.BoolFlag{
+				Name:  "npm",
+				Usage: "Use npm package manager (global packages)",
+			},
+			&cli.BoolFlag{
+				Name:  "pip",
+				Usage: "Use pip package manager (Python packages)",
+			},
+			&cli.BoolFlag{
+				Name:  "pacman",
+				Usage: "Use pacman package manager",
+			},
+			&cli.BoolFlag{
+				Name:  "portage",
+				Usage: "Use portage (emerge) package manager",
+			},
+			&cli.BoolFlag{
+				Name:  "apk",
+				Usage: "Use apk package manager",
+			},
+			&cli.BoolFlag{
+				Name:  "zypper",
+				Usage: "Use zypper package manager",
+			},
+			&cli.BoolFlag{
+				Name:  "brew",
+				Usage: "Use brew (Homebrew) package manager",
 			},
 			&cli.BoolFlag{
 				Name:  "flatpak",
 				Usage: "Use flatpak package manager",
 				// Hidden: true,
 			},
+			&cli.BoolFlag{
+				Name:  "winget",
+				Usage: "Use winget package manager (Windows)",
+			},
 			&cli.BoolFlag{
 				Name:   "snap",
 				Usage:  "Use snap package manager",
@@ -341,6 +2130,65 @@ func main() {
 	}
 }
 
+// cfg holds the defaults loaded from the configuration file and the environment.
+var cfg = &config.Config{}
+
+// configPath is the absolute path of the loaded configuration file, empty if none exists.
+var configPath string
+
+// loadConfig loads the configuration file given with --config, or the default one if it exists,
+// and applies its defaults to the global flags that are not set on the command line.
+// As root, such as once re-executed with sudo, the configuration file must be owned by root, as its hooks run as root.
+func loadConfig(c *cli.Context) error {
+	path, optional := c.String("config"), false
+	if path == "" {
+		path, optional = config.DefaultPath(), true
+	}
+	load := config.Load
+	if manager.IsPrivileged() {
+		load = config.LoadTrusted
+	}
+	loaded, err := load(path, optional)
+	if err != nil {
+		return err
+	}
+	cfg = loaded
+	if _, err := os.Stat(path); err == nil {
+		if configPath, err = filepath.Abs(path); err != nil {
+			return err
+		}
+	}
+
+	if c.IsSet("max-concurrent") {
+		if c.Int("max-concurrent") < 0 {
+			return errors.New("--max-concurrent must not be negative")
+		}
+		cfg.Concurrency = c.Int("max-concurrent")
+	}
+	if cfg.Output != "" && !c.IsSet("output") {
+		if err := c.Set("output", cfg.Output); err != nil {
+			return err
+		}
+	}
+	if cfg.AssumeYes && !c.IsSet("assume-yes") {
+		if err := c.Set("assume-yes", "true"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commandName returns the full name of the running command, such as "install" or "show upgradable".
+func commandName(c *cli.Context) string {
+	var names []string
+	for _, ctx := range c.Lineage() {
+		if ctx.Command != nil && ctx.Command.Name != "" && ctx.Command.Name != c.App.Name {
+			names = append([]string{ctx.Command.Name}, names...)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
 // getOptions extracts options from the CLI context and returns a manager.Options struct.
 func getOptions(c *cli.Context) *manager.Options {
 	var opts manager.Options
@@ -348,68 +2196,484 @@ func getOptions(c *cli.Context) *manager.Options {
 	opts.DryRun = c.Bool("dry-run")
 	opts.Interactive = c.Bool("interactive")
 	opts.Debug = c.Bool("debug")
+	opts.Environment = c.String("env")
+	opts.RootDir = c.String("install-root")
+	opts.Architecture = c.String("arch")
+	opts.Scope = manager.Scope(c.String("scope"))
+	// some package managers read the license of each package from a file, only when the licenses are asked for
+	opts.Licenses = c.Bool("licenses") || c.Bool("provenance") || filtersLicense(c)
+	opts.Context = interrupt
+	opts.Timeout = cfg.TimeoutOf(commandName(c))
+	if c.IsSet("timeout") {
+		opts.Timeout = c.Duration("timeout")
+	}
+	opts.LockWait = cfg.LockWait
+	if c.IsSet("lock-wait") {
+		opts.LockWait = c.Duration("lock-wait")
+	}
+	opts.AutoRefresh = cfg.AutoRefresh
+	if c.IsSet("auto-refresh") {
+		opts.AutoRefresh = c.Bool("auto-refresh")
+	}
+	opts.Proxy, opts.NoProxy, opts.Mirrors, opts.Env = cfg.Proxy, cfg.NoProxy, cfg.Mirrors, cfg.Env
+	// the commands of the package managers and their results can be recorded to fixture files, and replayed in tests
+	if dir := os.Getenv("SYSPKG_RECORD"); dir != "" {
+		opts.Wrapper = fixture.Record(dir)
+	} else if dir := os.Getenv("SYSPKG_REPLAY"); dir != "" {
+		opts.Wrapper = fixture.Replay(dir)
+	} else if target != nil {
+		opts.Wrapper = target
+	}
+	if c.IsSet("proxy") {
+		opts.Proxy = c.String("proxy")
+	}
 
-	if !opts.Interactive {
+	if !opts.Interactive || c.Bool("assume-yes") {
 		opts.AssumeYes = true
 	}
 
+	// progress is streamed as NDJSON with --json-stream or --output ndjson, and drawn as progress bars when stderr is a terminal,
+	// unless the command runs interactively or prints JSON
+	if c.Bool("json-stream") || outputFormat(c) == outputNDJSON {
+		progress.JSON = true
+		opts.Progress = progress
+	} else if !opts.Interactive && !jsonOutput(c) && isTerminal(os.Stderr) {
+		opts.Progress = progress
+	}
+
 	return &opts
 }
 
 // filterPackageManager filters the available package managers based on user input.
-func filterPackageManager(availablePMs map[string]syspkg.PackageManager, c *cli.Context) map[string]syspkg.PackageManager {
+// Package managers can be selected by name (e.g. --apt) and by category (e.g. -c system).
+func filterPackageManager(s syspkg.SysPkg, availablePMs map[string]syspkg.PackageManager, c *cli.Context) map[string]syspkg.PackageManager {
 	if len(availablePMs) == 0 {
 		log.Fatal("No package managers available!")
 	}
 
-	// if no specific package manager is specified, use all available
-	if !c.Bool("apt") && !c.Bool("flatpak") && !c.Bool("snap") && !c.Bool("yum") && !c.Bool("dnf") && !c.Bool("pacman") && !c.Bool("apk") && !c.Bool("zypper") {
-		return availablePMs
+	categories, err := manager.ParseCategories(c.StringSlice("category"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// excluded package managers are never used
+	if len(cfg.Exclude) > 0 {
+		includedPMs := make(map[string]syspkg.PackageManager)
+		for name, pm := range availablePMs {
+			if !cfg.IsExcluded(name) {
+				includedPMs[name] = pm
+			}
+		}
+		availablePMs = includedPMs
+	}
+
+	// if no specific package manager is specified, use the configured ones, or all available
+	if len(categories) == 0 && !c.Bool("apt") && !c.Bool("brew") && !c.Bool("cargo") && !c.Bool("conda") && !c.Bool("flatpak") && !c.Bool("fwupd") && !c.Bool("gem") && !c.Bool("gobin") && !c.Bool("nix") && !c.Bool("npm") && !c.Bool("pip") && !c.Bool("snap") && !c.Bool("yum") && !c.Bool("dnf") && !c.Bool("pacman") && !c.Bool("portage") && !c.Bool("apk") && !c.Bool("winget") && !c.Bool("zypper") {
+		if len(cfg.Managers) == 0 {
+			return availablePMs
+		}
+		var preferredPMs = make(map[string]syspkg.PackageManager)
+		for _, name := range cfg.Managers {
+			if pm, ok := availablePMs[name]; ok {
+				preferredPMs[name] = pm
+			}
+		}
+		return preferredPMs
+	}
+
+	var inCategories = make(map[string]syspkg.PackageManager)
+	if len(categories) > 0 {
+		// an error only means no package manager of these categories is available
+		inCategories, _ = s.FindPackageManagers(syspkg.IncludeOptions{Categories: categories})
 	}
 
 	var wantedPMs = make(map[string]syspkg.PackageManager)
 	for name, pm := range availablePMs {
-		if c.Bool(name) {
+		if _, ok := inCategories[name]; ok || c.Bool(name) {
 			wantedPMs[name] = pm
 		}
 	}
 	return wantedPMs
 }
 
-// listUpgradablePackages lists upgradable packages for the given package managers.
-func listUpgradablePackages(pms map[string]syspkg.PackageManager, opts *manager.Options) {
+// listUpgradablePackages lists upgradable packages for the given package managers, processed by proc, if not nil,
+// adding them to out, and printing them as tables laid out by layout.
+func listUpgradablePackages(pms map[string]syspkg.PackageManager, opts *manager.Options, out *OutputFormatter, proc *manager.PackageListProcessor, layout tableLayout) {
+	list := &packageList{proc: proc, header: "Upgradable packages for %T:\n", columns: packageColumns(layout), layout: layout}
+	defer list.Flush()
+
 	for _, pm := range pms {
 		log.Printf("Listing upgradable packages for %T...\n", pm)
-		upgradablePackages, err := pm.ListUpgradable(opts)
+		start := out.Start(pm.GetPackageManager())
+		upgradablePackages, err := cachedQuery(pm, daemon.OpUpgradable, opts, nil, func() ([]manager.PackageInfo, error) {
+			return pm.ListUpgradable(opts)
+		})
+		upgradablePackages = proc.Process(upgradablePackages)
+		if out.Add(pm.GetPackageManager(), upgradablePackages, err, start); out.JSON {
+			continue
+		}
+		if errors.Is(err, manager.ErrOperationNotSupported) {
+			log.Printf("Listing upgradable packages is not supported by %T, skipping\n", pm)
+			continue
+		}
 		if err != nil {
 			fmt.Printf("Error while listing upgradable packages for %T: %+v\n", pm, err)
 			continue
 		}
+		list.Add(pm, upgradablePackages)
+	}
+}
+
+// holdPackages holds (or releases the hold on) the packages given as arguments, with the selected package managers.
+func holdPackages(s syspkg.SysPkg, pms map[string]syspkg.PackageManager, c *cli.Context, hold bool) error {
+	var opts = getOptions(c)
+	pms = filterPackageManager(s, pms, c)
+	pkgNames := c.Args().Slice()
+
+	if len(pkgNames) == 0 {
+		fmt.Println("Please specify at least one package name.")
+		return nil
+	}
+
+	for _, pm := range pms {
+		h, ok := pm.(syspkg.Holder)
+		if !ok {
+			log.Printf("Holding packages is not supported by %T, skipping\n", pm)
+			continue
+		}
 
-		fmt.Printf("Upgradable packages for %T:\n", pm)
-		for _, pkg := range upgradablePackages {
-			fmt.Printf("%s: %s %s -> %s (%s)\n", pkg.PackageManager, pkg.Name, pkg.Version, pkg.NewVersion, pkg.Status)
+		var packages []manager.PackageInfo
+		var err error
+		operation := "unhold"
+		if hold {
+			operation = "hold"
+			packages, err = h.Hold(pkgNames, opts)
+		} else {
+			packages, err = h.Unhold(pkgNames, opts)
+		}
+		recordOperation(pm.GetPackageManager(), operation, pkgNames, err, opts)
+		if err != nil {
+			fmt.Printf("Error while holding packages for %T: %+v\n", pm, err)
+			continue
+		}
+		for _, pkg := range packages {
+			fmt.Printf("%s: %s [%s] (%s)\n", pkg.PackageManager, pkg.Name, pkg.Version, pkg.Status)
 		}
 	}
+	return nil
 }
 
-// performUpgrade upgrades packages for the given package managers.
-func performUpgrade(pms map[string]syspkg.PackageManager, opts *manager.Options) error {
-	fmt.Println("Performing package upgrade...")
+// groupPackages installs (or removes) the packages of the groups given as arguments, with the selected package managers.
+func groupPackages(s syspkg.SysPkg, pms map[string]syspkg.PackageManager, c *cli.Context, install bool) error {
+	var opts = getOptions(c)
+	pms = filterPackageManager(s, pms, c)
+	groups := c.Args().Slice()
+
+	if len(groups) == 0 {
+		fmt.Println("Please specify at least one group name.")
+		return nil
+	}
 
+	operation, command := history.OperationDelete, "group remove"
+	if install {
+		operation, command = history.OperationInstall, "group install"
+	}
+	out := newOutputFormatter(c, command)
 	for _, pm := range pms {
-		packages, err := pm.UpgradeAll(opts)
+		start := out.Start(pm.GetPackageManager())
+		g, ok := pm.(syspkg.GroupManager)
+		if !ok {
+			out.Add(pm.GetPackageManager(), nil, manager.ErrOperationNotSupported, start)
+			log.Printf("Managing groups is not supported by %T, skipping\n", pm)
+			continue
+		}
+
+		packages, err := withHooks(pm.GetPackageManager(), string(operation), groups, opts, func() ([]manager.PackageInfo, error) {
+			if install {
+				return g.InstallGroup(groups, opts)
+			}
+			return g.RemoveGroup(groups, opts)
+		})
+		recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: operation, Requested: groups, Packages: packages}, err, opts)
+		if out.Add(pm.GetPackageManager(), packages, err, start); out.JSON {
+			continue
+		}
 		if err != nil {
-			fmt.Printf("Error while upgrading packages for %T: %+v\n%+v", pm, err, packages)
+			fmt.Printf("Error while managing groups for %T: %+v\n", pm, err)
 			continue
 		}
-		// log.Printf("Upgraded packages for %T: %+v", pm, packages)
-		log.Printf("Packages upgraded for %T:\n", pm)
 		for _, pkg := range packages {
-			fmt.Printf("%s: %s -> %s (%s)\n", pkg.PackageManager, pkg.Name, pkg.NewVersion, pkg.Status)
+			fmt.Printf("%s: %s [%s][%s] (%s) @%s\n", pkg.PackageManager, pkg.Name, pkg.Version, pkg.NewVersion, pkg.Status, pkg.AdditionalData["group"])
+		}
+	}
+	return out.Finish()
+}
+
+// downgradePackages downgrades the packages, given as "name=version", with a package manager supporting it.
+// The result is added to out if it is not nil, and only printed as text if out is not in JSON mode. The error of the
+// package manager is returned as well.
+func downgradePackages(pm syspkg.PackageManager, pkgs []string, opts *manager.Options, out *OutputFormatter) error {
+	start := time.Now()
+	if out != nil {
+		start = out.Start(pm.GetPackageManager())
+	}
+	d, ok := pm.(syspkg.Downgrader)
+	if !ok {
+		if out != nil {
+			out.Add(pm.GetPackageManager(), nil, manager.ErrOperationNotSupported, start)
+		}
+		if out == nil || !out.JSON {
+			fmt.Printf("Downgrading packages is not supported by %T, skipping %s\n", pm, pkgs)
+		}
+		return manager.ErrOperationNotSupported
+	}
+
+	packages, err := withHooks(pm.GetPackageManager(), "downgrade", pkgs, opts, func() ([]manager.PackageInfo, error) {
+		return d.Downgrade(pkgs, opts)
+	})
+	recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: history.OperationDowngrade, Requested: pkgs, Packages: packages}, err, opts)
+	if out != nil {
+		if out.Add(pm.GetPackageManager(), packages, err, start); out.JSON {
+			return err
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error while downgrading packages for %T: %+v\n", pm, err)
+		return err
+	}
+	for _, pkg := range packages {
+		fmt.Printf("%s: %s [%s] (%s)\n", pkg.PackageManager, pkg.Name, pkg.Version, pkg.Status)
+	}
+	return nil
+}
+
+// fetchKey returns the path of the key file at source, downloading it to a temporary file first if source is an HTTPS URL.
+// Plain HTTP URLs are refused, as the key would be open to tampering.
+func fetchKey(source string) (string, error) {
+	if strings.HasPrefix(source, "http://") {
+		return "", fmt.Errorf("refusing to download a key over plain HTTP: %s", source)
+	}
+	if !strings.HasPrefix(source, "https://") {
+		return source, nil
+	}
+
+	resp, err := http.Get(source)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s: %s", source, resp.Status)
+	}
+
+	file, err := os.CreateTemp("", "syspkg-key-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// printRepositories prints repositories, one per line.
+func printRepositories(repos []manager.RepositoryInfo) {
+	for _, repo := range repos {
+		fmt.Printf("%s: %s %s", repo.PackageManager, repo.Name, repo.URL)
+		if repo.Scope != "" {
+			fmt.Printf(" [%s]", repo.Scope)
+		}
+		if !repo.Enabled {
+			fmt.Print(" (disabled)")
+		}
+		fmt.Println()
+	}
+}
+
+// printGroups prints the groups of packages, one per line.
+func printGroups(groups []manager.GroupInfo) {
+	for _, group := range groups {
+		status := "available"
+		if group.Installed {
+			status = "installed"
+		}
+		fmt.Printf("%s: %s (%s)", group.PackageManager, group.Name, status)
+		if group.Description != "" {
+			fmt.Printf(" %s", group.Description)
+		}
+		fmt.Println()
+	}
+}
+
+// printVerification prints the files and the signature of a package verified by the verify command, one per line.
+func printVerification(result manager.VerificationResult) {
+	for _, problem := range []struct {
+		kind  string
+		files []string
+	}{
+		{"checksum mismatch", result.ChecksumMismatches},
+		{"missing", result.MissingFiles},
+		{"modified config", result.ModifiedConfigs},
+		{"problem", result.Problems},
+	} {
+		for _, file := range problem.files {
+			fmt.Printf("  %s: %s\n", problem.kind, file)
+		}
+	}
+	if result.Signature != manager.SignatureUnknown {
+		fmt.Printf("  signature: %s\n", result.Signature)
+	}
+}
+
+// printKeys prints signing keys, one per line.
+func printKeys(keys []manager.KeyInfo) {
+	for _, key := range keys {
+		fmt.Printf("%s: %s %s [%s]\n", key.PackageManager, key.ID, key.UserID, key.Keyring)
+	}
+}
+
+// formatSize formats a size in bytes, such as the installed size of a package, or returns an empty string if it is
+// unknown.
+func formatSize(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return manager.FormatSize(n)
+}
+
+// listInstalled lists the installed packages of all the given package managers concurrently, by package manager name.
+// Errors are logged, as the output of the callers is often a document that must not be interleaved with messages.
+func listInstalled(pms map[string]syspkg.PackageManager, opts *manager.Options) map[string][]manager.PackageInfo {
+	var mu sync.Mutex
+	installed := make(map[string][]manager.PackageInfo)
+	forEachConcurrently(pms, func(name string, pm syspkg.PackageManager) {
+		packages, err := pm.ListInstalled(opts)
+		if err != nil {
+			log.Printf("Error while listing installed packages for %s, skipping: %+v\n", name, err)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		installed[name] = packages
+	})
+	return installed
+}
+
+// forEachConcurrently calls f for all the given package managers concurrently, and waits for the calls to return.
+// At most cfg.Concurrency package managers (set by concurrency in the configuration, or --max-concurrent) run at the
+// same time, if set, so that heavy package managers don't all compete for the disk and CPU of small hosts.
+func forEachConcurrently(pms map[string]syspkg.PackageManager, f func(name string, pm syspkg.PackageManager)) {
+	limit := cfg.Concurrency
+	if limit <= 0 {
+		limit = len(pms)
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for name, pm := range pms {
+		wg.Add(1)
+		go func(name string, pm syspkg.PackageManager) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			f(name, pm)
+		}(name, pm)
+	}
+	wg.Wait()
+}
+
+// findOwners queries all the given package managers concurrently for the packages owning path, and prints the owners
+// found. Symbolic links, such as the ones managed by update-alternatives or merging /bin into /usr/bin, are usually
+// not owned by the package of their target, so the owners of the resolved path are queried too, and path is only
+// reported as not owned when neither is.
+func findOwners(pms map[string]syspkg.PackageManager, path string, opts *manager.Options) {
+	paths := []string{path}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+		paths = append(paths, resolved)
+	}
+
+	found := false
+	for _, path := range paths {
+		for _, r := range queryOwners(pms, path, opts) {
+			if r.err != nil {
+				fmt.Printf("Error while querying the owner of %s for %s: %+v\n", path, r.name, r.err)
+				continue
+			}
+			for _, pkg := range r.packages {
+				found = true
+				owner := pkg.Name
+				if pkg.Version != "" {
+					owner += " " + pkg.Version
+				}
+				fmt.Printf("%s: %s is owned by %s\n", pkg.PackageManager, path, owner)
+			}
+		}
+	}
+	if !found {
+		fmt.Printf("%s is not owned by any package\n", strings.Join(paths, " -> "))
+	}
+}
+
+// ownerResult is the owners of a path returned by a package manager.
+type ownerResult struct {
+	name     string
+	packages []manager.PackageInfo
+	err      error
+}
+
+// queryOwners queries all the given package managers concurrently for the packages owning path, and returns their
+// results sorted by package manager.
+func queryOwners(pms map[string]syspkg.PackageManager, path string, opts *manager.Options) []ownerResult {
+	results := make(chan ownerResult, len(pms))
+	forEachConcurrently(pms, func(name string, pm syspkg.PackageManager) {
+		q, ok := pm.(syspkg.FileOwnerQuerier)
+		if !ok {
+			log.Printf("Querying file owners is not supported by %T, skipping\n", pm)
+			return
 		}
+		packages, err := q.Owns(path, opts)
+		results <- ownerResult{name, packages, err}
+	})
+	close(results)
+
+	var sorted []ownerResult
+	for r := range results {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+	return sorted
+}
+
+// printDependencyTree prints the packages returned by query for pkg as a tree, descending up to depth levels.
+// Packages that were already printed are marked with (*) and not expanded again, which also breaks dependency cycles.
+func printDependencyTree(query func(string, *manager.Options) ([]manager.PackageInfo, error), pkg string, prefix string, depth int, seen map[string]bool, opts *manager.Options) error {
+	if depth <= 0 {
+		return nil
+	}
+
+	deps, err := query(pkg, opts)
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("Upgrade completed.")
+	for i, dep := range deps {
+		branch, indent := "├── ", "│   "
+		if i == len(deps)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		if seen[dep.Name] {
+			fmt.Printf("%s%s%s (*)\n", prefix, branch, dep.Name)
+			continue
+		}
+		seen[dep.Name] = true
+
+		fmt.Printf("%s%s%s%s\n", prefix, branch, dep.Name, dep.AdditionalData["constraint"])
+		if err := printDependencyTree(query, dep.Name, prefix+indent, depth-1, seen, opts); err != nil {
+			log.Printf("Error while querying dependencies of %s: %+v\n", dep.Name, err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/history"
+	"github.com/bluet/syspkg/manager/manifest"
+)
+
+// applyManifest installs, deletes and upgrades packages to match the manifest file given as argument, once the steps
+// it takes are listed and confirmed.
+func applyManifest(c *cli.Context, pms map[string]syspkg.PackageManager) error {
+	var opts = getOptions(c)
+
+	if c.NArg() != 1 {
+		fmt.Println("Please specify one and only one manifest file.")
+		return nil
+	}
+
+	m, err := manifest.Load(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	plans := make(map[string][]manifest.Step)
+	for _, name := range m.PackageManagers() {
+		pm, ok := pms[name]
+		if !ok {
+			fmt.Printf("Package manager %s is not available, skipping its packages\n", name)
+			continue
+		}
+
+		installed, err := pm.ListInstalled(opts)
+		if err != nil {
+			fmt.Printf("Error while listing installed packages for %T: %+v\n", pm, err)
+			continue
+		}
+		upgradable, err := pm.ListUpgradable(opts)
+		if err != nil && !errors.Is(err, manager.ErrOperationNotSupported) {
+			fmt.Printf("Error while listing upgradable packages for %T: %+v\n", pm, err)
+			continue
+		}
+
+		for _, step := range m.Plan(name, installed, upgradable) {
+			fmt.Printf("%s: %s %s %s -> %s (%s)\n", step.PackageManager, step.Action, step.Package, step.CurrentVersion, step.Version, step.Reason)
+			plans[name] = append(plans[name], step)
+		}
+	}
+
+	if len(plans) == 0 {
+		fmt.Println("Nothing to do, the installed packages match the manifest.")
+		return nil
+	}
+	if opts.DryRun {
+		return nil
+	}
+	if !opts.AssumeYes {
+		fmt.Print("\nDo you want to apply the manifest? [Y/n]: ")
+		input := ""
+		_, _ = fmt.Scanln(&input)
+		input = strings.ToLower(input)
+
+		if input != "y" && input != "" {
+			fmt.Println("Apply cancelled.")
+			return nil
+		}
+	}
+
+	for name, steps := range plans {
+		_ = applySteps(pms[name], steps, opts)
+	}
+	return nil
+}
+
+// applySteps performs the steps planned to converge a package manager to a manifest, and returns the errors of
+// the ones that failed, joined. Only downgrades to an exact version can be performed, other downgrades are only
+// reported.
+func applySteps(pm syspkg.PackageManager, steps []manifest.Step, opts *manager.Options) error {
+	var install, remove, upgrade, downgrade []string
+	var errs []error
+	for _, step := range steps {
+		switch {
+		case step.Action == manifest.ActionInstall:
+			install = append(install, step.Package)
+		case step.Action == manifest.ActionDelete:
+			remove = append(remove, step.Package)
+		case step.Action == manifest.ActionUpgrade:
+			upgrade = append(upgrade, step.Package)
+		case step.Action == manifest.ActionDowngrade && step.Version != "":
+			downgrade = append(downgrade, manager.PackageSpec{Name: step.Package, Version: step.Version}.String())
+		default:
+			err := fmt.Errorf("cannot %s %s (%s), no exact version is required", step.Action, step.Package, step.Reason)
+			fmt.Printf("%s: %v\n", step.PackageManager, err)
+			errs = append(errs, err)
+		}
+	}
+
+	if len(install) > 0 {
+		packages, err := withHooks(pm.GetPackageManager(), "install", install, opts, func() ([]manager.PackageInfo, error) {
+			return pm.Install(install, opts)
+		})
+		recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: history.OperationInstall, Requested: install, Packages: packages}, err, opts)
+		if err != nil {
+			fmt.Printf("Error while installing packages for %T: %+v\n", pm, err)
+			errs = append(errs, err)
+		}
+	}
+	if len(remove) > 0 {
+		packages, err := withHooks(pm.GetPackageManager(), "delete", remove, opts, func() ([]manager.PackageInfo, error) {
+			return pm.Delete(remove, opts)
+		})
+		recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: history.OperationDelete, Requested: remove, Packages: packages}, err, opts)
+		if err != nil {
+			fmt.Printf("Error while deleting packages for %T: %+v\n", pm, err)
+			errs = append(errs, err)
+		}
+	}
+	if len(upgrade) > 0 {
+		u, ok := pm.(syspkg.Upgrader)
+		if !ok {
+			fmt.Printf("Upgrading specific packages is not supported by %T, skipping %s\n", pm, upgrade)
+			return errors.Join(append(errs, fmt.Errorf("upgrading %s: %w", strings.Join(upgrade, ", "), manager.ErrOperationNotSupported))...)
+		}
+		packages, err := withHooks(pm.GetPackageManager(), "upgrade", upgrade, opts, func() ([]manager.PackageInfo, error) {
+			return u.Upgrade(upgrade, opts)
+		})
+		recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: history.OperationUpgrade, Requested: upgrade, Packages: packages}, err, opts)
+		if err != nil {
+			fmt.Printf("Error while upgrading packages for %T: %+v\n", pm, err)
+			errs = append(errs, err)
+		}
+	}
+	if len(downgrade) > 0 {
+		if err := downgradePackages(pm, downgrade, opts, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/ansible"
+)
+
+// runModule runs syspkg as an Ansible module, with the arguments in the JSON file given as argument, and prints its
+// result as JSON, the only output Ansible reads: the other output of syspkg and of the package managers goes to
+// stderr. The packages are managed by the package manager given with use, or by the selected package manager of
+// highest priority. It exits with status 1 when failing, as Ansible modules do.
+func runModule(c *cli.Context, s syspkg.SysPkg, pms map[string]syspkg.PackageManager) error {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+
+	result := moduleResult(c, s, pms)
+	if err := json.NewEncoder(stdout).Encode(result); err != nil {
+		return err
+	}
+	if result.Failed {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// moduleResult runs the module, and returns its result.
+func moduleResult(c *cli.Context, s syspkg.SysPkg, pms map[string]syspkg.PackageManager) ansible.Result {
+	var opts = getOptions(c)
+	if c.NArg() != 1 {
+		return ansible.Fail(errors.New("expected the path of the arguments file of the module"))
+	}
+	args, err := ansible.LoadArgs(c.Args().First())
+	if err != nil {
+		return ansible.Fail(err)
+	}
+	checkMode := args.CheckMode || opts.DryRun
+
+	name := args.Use
+	if name == ansible.UseAuto {
+		order := syspkg.InstallOrder(filterPackageManager(s, pms, c), cfg.Priority)
+		if len(order) == 0 {
+			return ansible.Fail(errors.New("no package manager is available"))
+		}
+		name = order[0]
+	}
+	pm, ok := pms[name]
+	if !ok {
+		return ansible.Fail(fmt.Errorf("package manager %s is not available", name))
+	}
+
+	if args.UpdateCache && !checkMode {
+		if err := pm.Refresh(opts); err != nil {
+			return ansible.Fail(fmt.Errorf("refreshing the package indexes of %s: %w", name, err))
+		}
+	}
+	if len(args.Names) == 0 {
+		return ansible.NewResult(name, nil, checkMode, nil)
+	}
+
+	m, err := args.Manifest(name)
+	if err != nil {
+		return ansible.Fail(err)
+	}
+	installed, err := pm.ListInstalled(opts)
+	if err != nil {
+		return ansible.Fail(fmt.Errorf("listing the installed packages of %s: %w", name, err))
+	}
+	upgradable, err := pm.ListUpgradable(opts)
+	if err != nil && !errors.Is(err, manager.ErrOperationNotSupported) {
+		return ansible.Fail(fmt.Errorf("listing the upgradable packages of %s: %w", name, err))
+	}
+
+	steps := m.Plan(name, installed, upgradable)
+	if len(steps) == 0 || checkMode {
+		return ansible.NewResult(name, steps, checkMode, nil)
+	}
+	return ansible.NewResult(name, steps, checkMode, applySteps(pm, steps, opts))
+}
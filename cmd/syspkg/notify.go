@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bluet/syspkg/manager/audit"
+	"github.com/bluet/syspkg/manager/notify"
+	"github.com/bluet/syspkg/manager/report"
+)
+
+// sendNotification sends a notification of event to the configured notifiers. Their failures are logged, and don't
+// fail the command.
+func sendNotification(event string, subject string, body string) {
+	if len(cfg.Notify) == 0 {
+		return
+	}
+	_ = notify.Send(cfg.Notify, notify.Message{Event: event, Subject: subject, Body: body}, nil)
+}
+
+// notifyUpgrade sends the report of an upgrade that upgraded packages or failed.
+func notifyUpgrade(rep *report.Report) {
+	if rep.Upgraded() == 0 && len(rep.Failed()) == 0 {
+		return
+	}
+	var body strings.Builder
+	if err := rep.WriteText(&body); err != nil {
+		return
+	}
+	sendNotification(notify.EventUpgrade, fmt.Sprintf("syspkg upgrade on %s: %s", rep.Host, rep.Summary()), body.String())
+}
+
+// notifyVulnerable sends the vulnerabilities found by an audit.
+func notifyVulnerable(findings []audit.Finding) {
+	var body strings.Builder
+	for _, f := range findings {
+		body.WriteString(describeFinding(f) + "\n")
+	}
+	host, _ := os.Hostname()
+	sendNotification(notify.EventVulnerable, fmt.Sprintf("syspkg audit on %s: %d vulnerabilities found", host, len(findings)), body.String())
+}
+
+// describeFinding describes a vulnerability found by an audit on a line, such as
+// "apt: openssl 3.0.14-1 is affected by DSA-5678-1 / CVE-2024-5535 (fixed in 3.0.15-1): ...".
+func describeFinding(f audit.Finding) string {
+	fixed := "no fix available"
+	if len(f.FixedVersions) > 0 {
+		fixed = "fixed in " + strings.Join(f.FixedVersions, ", ")
+	}
+	id := f.ID
+	if len(f.Aliases) > 0 {
+		id += " / " + strings.Join(f.Aliases, " / ")
+	}
+	return fmt.Sprintf("%s: %s %s is affected by %s (%s): %s", f.PackageManager, f.Package, f.Version, id, fixed, f.Summary)
+}
+
+// notifyFailure sends the errors of the package managers that failed to run command, by package manager name.
+func notifyFailure(command string, errs map[string]string) {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var body strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&body, "%s: %s\n", name, errs[name])
+	}
+	host, _ := os.Hostname()
+	sendNotification(notify.EventFailure, fmt.Sprintf("syspkg %s on %s failed for %s", command, host, strings.Join(names, ", ")), body.String())
+}
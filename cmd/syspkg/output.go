@@ -0,0 +1,465 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/cache"
+	"github.com/bluet/syspkg/manager/config"
+	"github.com/bluet/syspkg/manager/platform"
+)
+
+// Output formats of the --output flag.
+const (
+	outputText      = config.OutputText
+	outputJSON      = config.OutputJSON
+	outputNDJSON    = config.OutputNDJSON
+	outputPorcelain = config.OutputPorcelain
+	outputCSV       = config.OutputCSV
+	outputYAML      = config.OutputYAML
+)
+
+// outputSchema identifies the version of the JSON envelope. It changes only when the envelope changes incompatibly.
+const outputSchema = "syspkg/v1"
+
+// Envelope is the JSON document printed by commands run with --json:
+//
+//	{"schema":"syspkg/v1","command":"install","results":{"apt":{"packages":[...],"error":"...","duration":1.52}}}
+type Envelope struct {
+	// Schema is the version of the envelope, outputSchema.
+	Schema string `json:"schema"`
+
+	// Command is the name of the command, such as "install" or "show upgradable".
+	Command string `json:"command"`
+
+	// Results are the results of the command, by package manager name.
+	Results map[string]*Result `json:"results"`
+
+	// Duplicates are the packages found with the same name in several package managers, by the commands listing
+	// the packages of several package managers.
+	Duplicates []manager.DuplicateInfo `json:"duplicates,omitempty"`
+}
+
+// Result is the result of a command for a package manager.
+type Result struct {
+	// Packages are the packages returned by the package manager, never null.
+	Packages []manager.PackageInfo `json:"packages"`
+
+	// Files are the files listed by the files command.
+	Files []string `json:"files,omitempty"`
+
+	// Capabilities are the operations and options supported by the package manager, listed by the managers command.
+	Capabilities *manager.Capabilities `json:"capabilities,omitempty"`
+
+	// Platform is the platform the package manager runs on, listed by the managers command.
+	Platform *platform.Platform `json:"platform,omitempty"`
+
+	// Plan are the changes the command would make, in dry runs of the install, delete and upgrade commands.
+	Plan *manager.Plan `json:"plan,omitempty"`
+
+	// Reboot tells whether the system needs a reboot, checked by the status command.
+	Reboot *manager.RebootStatus `json:"reboot,omitempty"`
+
+	// Verification are the results of the packages having problems, found by the verify command.
+	Verification []manager.VerificationResult `json:"verification,omitempty"`
+
+	// Cache are the statistics of the query cache, listed by the cache stats command.
+	Cache *cache.Stats `json:"cache,omitempty"`
+
+	// Error is the error returned by the package manager, if any.
+	Error string `json:"error,omitempty"`
+
+	// Unsupported is set when the package manager doesn't support the command.
+	Unsupported bool `json:"unsupported,omitempty"`
+
+	// Interrupted is set when the command was interrupted, such as by Ctrl-C, possibly leaving its changes partially
+	// applied: the packages are the ones the package manager reported before.
+	Interrupted bool `json:"interrupted,omitempty"`
+
+	// Duration is the time the package manager took to run the command, in seconds.
+	Duration float64 `json:"duration"`
+
+	// Commands are the commands the package manager ran, with their exit code, by the commands querying several
+	// package managers concurrently, such as find.
+	Commands []manager.CommandRecord `json:"commands,omitempty"`
+}
+
+// Event is a line printed by commands run with --output ndjson, as soon as it happens:
+//
+//	{"type":"started","command":"install","package_manager":"apt"}
+//	{"type":"package","command":"install","package_manager":"apt","package":{"name":"vim",...}}
+//	{"type":"finished","command":"install","package_manager":"apt","finished":{"packages":1,"duration":1.52}}
+//	{"type":"summary","command":"install","schema":"syspkg/v1","summary":{"package_managers":1,"packages":1,"failed":0}}
+//
+// Commands listing the packages of several package managers print duplicate events before the summary, for the
+// packages found in several of them. Progress events, described by ProgressRenderer, are interleaved with them.
+type Event struct {
+	// Type is the type of the event: started, package, finished, duplicate or summary.
+	Type string `json:"type"`
+
+	// Schema is the version of the events, outputSchema. It is only set in the summary event.
+	Schema string `json:"schema,omitempty"`
+
+	// Command is the name of the command, such as "install" or "show upgradable".
+	Command string `json:"command"`
+
+	// PackageManager is the name of the package manager of started, package and finished events.
+	PackageManager string `json:"package_manager,omitempty"`
+
+	// Package is a package of the result of a package manager, in package events.
+	Package *manager.PackageInfo `json:"package,omitempty"`
+
+	// Finished is the outcome of a package manager, in finished events.
+	Finished *Finished `json:"finished,omitempty"`
+
+	// Duplicate is a package found in several package managers, in duplicate events.
+	Duplicate *manager.DuplicateInfo `json:"duplicate,omitempty"`
+
+	// Summary counts the results of the command, in the summary event.
+	Summary *Summary `json:"summary,omitempty"`
+}
+
+// Finished is the outcome of a package manager, in the finished events of --output ndjson.
+// Its packages are printed before, in package events.
+type Finished struct {
+	// Packages is the number of packages returned by the package manager.
+	Packages int `json:"packages"`
+
+	// Error is the error returned by the package manager, if any.
+	Error string `json:"error,omitempty"`
+
+	// Unsupported is set when the package manager doesn't support the command.
+	Unsupported bool `json:"unsupported,omitempty"`
+
+	// Interrupted is set when the command was interrupted.
+	Interrupted bool `json:"interrupted,omitempty"`
+
+	// Duration is the time the package manager took to run the command, in seconds.
+	Duration float64 `json:"duration"`
+}
+
+// Summary counts the results of a command, in the summary event of --output ndjson.
+type Summary struct {
+	// PackageManagers is the number of package managers that ran the command.
+	PackageManagers int `json:"package_managers"`
+
+	// Packages is the number of packages returned by all package managers.
+	Packages int `json:"packages"`
+
+	// Failed is the number of package managers that returned an error, other than unsupported commands.
+	Failed int `json:"failed"`
+}
+
+// OutputFormatter collects the results of a command for each package manager, to print them as a JSON envelope
+// when the --json flag is set, as the same envelope in YAML with --output yaml, or as porcelain records or CSV rows
+// with --output porcelain or csv, or prints them as NDJSON events as they come with --output ndjson. Otherwise,
+// commands print their results as text, and Flush prints nothing.
+type OutputFormatter struct {
+	// JSON is set when the results are printed by the formatter rather than by the commands: as JSON, either as an
+	// envelope or as NDJSON events, or as porcelain records, CSV or YAML.
+	JSON bool
+
+	// NDJSON is set when the results are printed as NDJSON events.
+	NDJSON bool
+
+	// Porcelain is set when the results are printed as porcelain records, described by writePorcelain.
+	Porcelain bool
+
+	// NUL is set when the porcelain records are terminated by NUL rather than by newlines, with -z.
+	NUL bool
+
+	// CSV is set when the packages are printed as CSV rows, described by writeCSV.
+	CSV bool
+
+	// YAML is set when the envelope is printed as YAML rather than JSON.
+	YAML bool
+
+	// Dedup is set to find the packages with the same name in the results of several package managers when
+	// flushing: they are annotated by manager.AnnotateDuplicates and listed in the envelope, or printed as
+	// duplicate events.
+	Dedup bool
+
+	// dryRun is set in dry runs, whose failures aren't notified.
+	dryRun bool
+
+	mu       sync.Mutex
+	envelope Envelope
+}
+
+// newOutputFormatter returns the output formatter of a command, in JSON mode if --json is set on the command or globally,
+// or if --output is json or ndjson.
+func newOutputFormatter(c *cli.Context, command string) *OutputFormatter {
+	format := outputFormat(c)
+	return &OutputFormatter{
+		JSON:      format != outputText,
+		NDJSON:    format == outputNDJSON,
+		Porcelain: format == outputPorcelain,
+		NUL:       c.Bool("null"),
+		CSV:       format == outputCSV,
+		YAML:      format == outputYAML,
+		dryRun:    c.Bool("dry-run"),
+		envelope: Envelope{
+			Schema:  outputSchema,
+			Command: command,
+			Results: make(map[string]*Result),
+		},
+	}
+}
+
+// outputFormat returns the output format of a command: the global --output flag, or json if --json is set.
+func outputFormat(c *cli.Context) string {
+	// the global flag is read from the outermost context defining it, as sbom export has its own --output flag
+	lineage := c.Lineage()
+	for i := len(lineage) - 1; i >= 0; i-- {
+		if format := lineage[i].String("output"); format != "" {
+			if format != outputText {
+				return format
+			}
+			break
+		}
+	}
+	if jsonOutput(c) {
+		return outputJSON
+	}
+	return outputText
+}
+
+// validateOutputFormat checks the value of the --output flag.
+func validateOutputFormat(c *cli.Context, format string) error {
+	if slices.Contains(config.OutputFormats, format) {
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(config.OutputFormats, ", "))
+}
+
+// jsonOutput reports whether --json is set on the command, its parent commands, or globally.
+func jsonOutput(c *cli.Context) bool {
+	// a --json flag of a command shadows the global one, so every level is checked
+	for _, ctx := range c.Lineage() {
+		if ctx.Bool("json") {
+			return true
+		}
+	}
+	return false
+}
+
+// Start returns the time a package manager starts running the command, and prints a started event with --output ndjson.
+// It is safe to call concurrently.
+func (f *OutputFormatter) Start(pm string) time.Time {
+	if f.NDJSON {
+		f.emit(Event{Type: "started", Command: f.envelope.Command, PackageManager: pm})
+	}
+	return time.Now()
+}
+
+// Add records the result of a package manager, which started running the command at start.
+// With --output ndjson, the packages and the result are printed right away.
+// It is safe to call concurrently.
+func (f *OutputFormatter) Add(pm string, packages []manager.PackageInfo, err error, start time.Time) *Result {
+	result := &Result{
+		Packages: packages,
+		Duration: time.Since(start).Seconds(),
+	}
+	if result.Packages == nil {
+		result.Packages = []manager.PackageInfo{}
+	}
+	if errors.Is(err, manager.ErrOperationNotSupported) {
+		result.Unsupported = true
+	}
+	result.Interrupted = errors.Is(err, manager.ErrInterrupted)
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if f.NDJSON {
+		for i := range result.Packages {
+			f.emit(Event{Type: "package", Command: f.envelope.Command, PackageManager: pm, Package: &result.Packages[i]})
+		}
+		finished := &Finished{Packages: len(result.Packages), Error: result.Error, Unsupported: result.Unsupported, Interrupted: result.Interrupted, Duration: result.Duration}
+		f.emit(Event{Type: "finished", Command: f.envelope.Command, PackageManager: pm, Finished: finished})
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// the results of a package manager running the command several times, such as installing packages one by one,
+	// are merged, keeping the first error
+	if previous, ok := f.envelope.Results[pm]; ok {
+		previous.Packages = append(previous.Packages, result.Packages...)
+		previous.Duration += result.Duration
+		if previous.Error == "" {
+			previous.Error, previous.Unsupported, previous.Interrupted = result.Error, result.Unsupported, result.Interrupted
+		}
+		return previous
+	}
+	f.envelope.Results[pm] = result
+	return result
+}
+
+// Forget clears the error err added by Add for package manager pm, once what it failed succeeded otherwise, such as
+// a package installed by the next package manager. The error is kept if another one was added first.
+func (f *OutputFormatter) Forget(pm string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if result, ok := f.envelope.Results[pm]; ok && err != nil && result.Error == err.Error() {
+		result.Error, result.Unsupported, result.Interrupted = "", false, false
+	}
+}
+
+// Flush prints the JSON envelope in JSON mode, or in YAML with --output yaml, the summary event with --output ndjson,
+// or the porcelain records or CSV rows with --output porcelain or csv.
+func (f *OutputFormatter) Flush() error {
+	if !f.JSON {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Porcelain {
+		return writePorcelain(os.Stdout, os.Stderr, f.envelope.Results, f.NUL)
+	}
+	if f.CSV {
+		return writeCSV(os.Stdout, os.Stderr, f.envelope.Results)
+	}
+	if f.Dedup {
+		f.dedup()
+	}
+	if f.NDJSON {
+		summary := &Summary{PackageManagers: len(f.envelope.Results)}
+		for _, result := range f.envelope.Results {
+			summary.Packages += len(result.Packages)
+			if result.Error != "" && !result.Unsupported {
+				summary.Failed++
+			}
+		}
+		return writeEvent(Event{Type: "summary", Schema: outputSchema, Command: f.envelope.Command, Summary: summary})
+	}
+
+	if f.YAML {
+		doc, err := marshalYAML(f.envelope)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(doc)
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(f.envelope)
+}
+
+// Failed returns the names of the package managers whose result has an error, other than unsupported commands,
+// sorted.
+func (f *OutputFormatter) Failed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var failed []string
+	for pm, result := range f.envelope.Results {
+		if result.Error != "" && !result.Unsupported {
+			failed = append(failed, pm)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// Err returns an error exiting with exitOperationFailed if some package managers failed, as returned by Failed, so
+// that the commands changing packages don't report success when some of their package managers failed, or with
+// exitInterrupted if some were interrupted. The failures, other than in dry runs, are notified to the configured
+// notifiers.
+func (f *OutputFormatter) Err() error {
+	if errs := f.errors(); len(errs) > 0 && !f.dryRun {
+		notifyFailure(f.envelope.Command, errs)
+	}
+	if interrupted := f.interrupted(); len(interrupted) > 0 {
+		return cli.Exit(fmt.Sprintf("%s interrupted for %s, the changes may be partially applied; see syspkg history list",
+			f.envelope.Command, strings.Join(interrupted, ", ")), exitInterrupted)
+	}
+	if failed := f.Failed(); len(failed) > 0 {
+		return cli.Exit(fmt.Sprintf("%s failed for %s", f.envelope.Command, strings.Join(failed, ", ")), exitOperationFailed)
+	}
+	return nil
+}
+
+// errors returns the errors of the package managers that failed or were interrupted, other than unsupported commands,
+// by package manager name.
+func (f *OutputFormatter) errors() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	errs := make(map[string]string)
+	for pm, result := range f.envelope.Results {
+		if result.Error != "" && !result.Unsupported {
+			errs[pm] = result.Error
+		}
+	}
+	return errs
+}
+
+// interrupted returns the names of the package managers whose command was interrupted, sorted.
+func (f *OutputFormatter) interrupted() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var interrupted []string
+	for pm, result := range f.envelope.Results {
+		if result.Interrupted {
+			interrupted = append(interrupted, pm)
+		}
+	}
+	sort.Strings(interrupted)
+	return interrupted
+}
+
+// Finish flushes the results, then returns Err.
+func (f *OutputFormatter) Finish() error {
+	if err := f.Flush(); err != nil {
+		return err
+	}
+	return f.Err()
+}
+
+// dedup finds the packages with the same name in the results of several package managers, and annotates them, or
+// prints them as duplicate events with --output ndjson, whose packages were already printed.
+func (f *OutputFormatter) dedup() {
+	var packages []manager.PackageInfo
+	for _, result := range f.envelope.Results {
+		packages = append(packages, result.Packages...)
+	}
+	duplicates := manager.FindDuplicates(packages)
+
+	if f.NDJSON {
+		for i := range duplicates {
+			_ = writeEvent(Event{Type: "duplicate", Command: f.envelope.Command, Duplicate: &duplicates[i]})
+		}
+		return
+	}
+	for _, result := range f.envelope.Results {
+		result.Packages = manager.AnnotateDuplicates(result.Packages, duplicates)
+	}
+	f.envelope.Duplicates = duplicates
+}
+
+// emit prints an NDJSON event.
+func (f *OutputFormatter) emit(event Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_ = writeEvent(event)
+}
+
+// stdoutMu serializes the NDJSON lines written to stdout, by output formatters and the progress renderer.
+var stdoutMu sync.Mutex
+
+// writeEvent prints an NDJSON line to stdout, flushed right away as stdout is unbuffered.
+func writeEvent(event interface{}) error {
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	return json.NewEncoder(os.Stdout).Encode(event)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// newTestContext returns the context of a command run without flags.
+func newTestContext() *cli.Context {
+	return cli.NewContext(cli.NewApp(), flag.NewFlagSet("syspkg", flag.ContinueOnError), nil)
+}
+
+func TestOutputFormatterForget(t *testing.T) {
+	out := newOutputFormatter(newTestContext(), "install")
+	errVim, errNano := errors.New("vim not found"), errors.New("nano not found")
+	out.Add("apt", nil, errVim, time.Now())
+	out.Add("snap", nil, errNano, time.Now())
+	out.Add("flatpak", nil, nil, time.Now())
+
+	// vim was installed by flatpak, but nano by none
+	out.Forget("apt", errVim)
+	out.Forget("snap", errVim)
+	if got, want := out.Failed(), []string{"snap"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed() = %+v, want %+v", got, want)
+	}
+}
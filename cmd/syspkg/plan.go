@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// planSymbols are the markers of the actions in plans printed as text, in the style of a diff.
+var planSymbols = map[manager.PlanAction]string{
+	manager.PlanActionInstall:   "+",
+	manager.PlanActionUpgrade:   "^",
+	manager.PlanActionDowngrade: "v",
+	manager.PlanActionRemove:    "-",
+}
+
+// errLimitExceeded is returned, wrapped, for operations exceeding --max-download or --min-free-space.
+var errLimitExceeded = errors.New("limit exceeded")
+
+// maxDownload and minFreeSpace are the limits set with --max-download and --min-free-space, in bytes; 0 if not set.
+var maxDownload, minFreeSpace int64
+
+// setupLimits parses the limits of --max-download and --min-free-space.
+func setupLimits(c *cli.Context) error {
+	for flag, limit := range map[string]*int64{"max-download": &maxDownload, "min-free-space": &minFreeSpace} {
+		if !c.IsSet(flag) {
+			continue
+		}
+		size, err := manager.ParseSize(c.String(flag))
+		if err != nil {
+			return fmt.Errorf("--%s: %w", flag, err)
+		}
+		*limit = size
+	}
+	return nil
+}
+
+// checkLimits estimates the download size and disk space of an operation of package manager pm with plan, when
+// --max-download or --min-free-space is set, and returns an error wrapping errLimitExceeded if the operation would
+// exceed them. The free disk space is the one of the root filesystem. Operations that can't be estimated are allowed.
+func checkLimits(pm string, plan func() (*manager.Plan, error)) error {
+	if maxDownload == 0 && minFreeSpace == 0 {
+		return nil
+	}
+
+	p, err := plan()
+	if err != nil {
+		log.Printf("Cannot estimate the download size and disk space used by %s, not checking them: %+v\n", pm, err)
+		return nil
+	}
+	if maxDownload > 0 && p.DownloadSize > maxDownload {
+		return fmt.Errorf("%w: %s would download %s, more than --max-download %s", errLimitExceeded, pm,
+			manager.FormatSize(p.DownloadSize), manager.FormatSize(maxDownload))
+	}
+	if minFreeSpace > 0 && p.DiskSpace > 0 {
+		free, err := manager.FreeDiskSpace("/")
+		if err != nil {
+			log.Printf("Cannot get the free disk space, not checking it: %+v\n", err)
+			return nil
+		}
+		if free-p.DiskSpace < minFreeSpace {
+			return fmt.Errorf("%w: %s would use %s of disk space, leaving %s free, less than --min-free-space %s", errLimitExceeded, pm,
+				manager.FormatSize(p.DiskSpace), manager.FormatSize(free-p.DiskSpace), manager.FormatSize(minFreeSpace))
+		}
+	}
+	return nil
+}
+
+// showPlan records the plan of an operation of package manager pm, which started at start, and prints it as text
+// unless the output is JSON. Dry runs of the install, delete and upgrade commands print plans rather than packages.
+func showPlan(out *OutputFormatter, pm string, plan *manager.Plan, err error, start time.Time) {
+	var packages []manager.PackageInfo
+	if plan != nil {
+		packages = plan.Packages()
+	}
+	result := out.Add(pm, packages, err, start)
+	result.Plan = plan
+	if out.JSON {
+		return
+	}
+	if errors.Is(err, manager.ErrOperationNotSupported) {
+		log.Printf("Dry runs are not supported by %s, skipping\n", pm)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error while planning the changes of %s: %+v\n", pm, err)
+		return
+	}
+	printPlan(os.Stdout, plan)
+}
+
+// printPlan prints a plan as a diff: a summary line, then one line per package, such as
+//
+//	apt: 1 to install, 1 to upgrade, 0 to downgrade, 1 to remove
+//	+ vim 2:9.1.0016-1ubuntu7
+//	^ libc6 2.39-0ubuntu8.3 -> 2.39-0ubuntu8.4
+//	- nano 7.2-2build1
+func printPlan(w io.Writer, plan *manager.Plan) {
+	fmt.Fprintf(w, "%s: %d to install, %d to upgrade, %d to downgrade, %d to remove", plan.PackageManager,
+		plan.Count(manager.PlanActionInstall), plan.Count(manager.PlanActionUpgrade),
+		plan.Count(manager.PlanActionDowngrade), plan.Count(manager.PlanActionRemove))
+	if plan.DownloadSize > 0 {
+		fmt.Fprintf(w, ", %s to download", manager.FormatSize(plan.DownloadSize))
+	}
+	if plan.DiskSpace > 0 {
+		fmt.Fprintf(w, ", %s of disk space used", manager.FormatSize(plan.DiskSpace))
+	} else if plan.DiskSpace < 0 {
+		fmt.Fprintf(w, ", %s of disk space freed", manager.FormatSize(-plan.DiskSpace))
+	}
+	fmt.Fprintln(w)
+
+	for _, entry := range plan.Entries {
+		versions := []string{entry.Version}
+		switch {
+		case entry.Version == "":
+			versions = []string{entry.NewVersion}
+		case entry.NewVersion != "":
+			versions = []string{entry.Version, "->", entry.NewVersion}
+		}
+		name := entry.Name
+		if entry.Arch != "" {
+			name += ":" + entry.Arch
+		}
+		fmt.Fprintln(w, strings.TrimSpace(planSymbols[entry.Action]+" "+name+" "+strings.Join(versions, " ")))
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// porcelainVersion is the version of the porcelain records. The columns only change with a new version, which would
+// be selected with a new output format, so that scripts parsing them keep working across releases.
+const porcelainVersion = "v1"
+
+// porcelainEmpty stands for the empty columns of porcelain records, so that they aren't collapsed by shells splitting
+// the records on whitespace, such as with read.
+const porcelainEmpty = "-"
+
+// writePorcelain writes the packages of results to w as porcelain v1 records, for scripts: one record per package, of
+// the package managers sorted by name, then in the order of their results, with the tab-separated columns
+//
+//	package manager, name, version, new version, status, architecture
+//
+// such as "apt\tvim\t2:9.1.0016-1\t2:9.1.0016-2\tupgradable\tamd64", the empty columns being "-". The records are
+// terminated by newlines, or NUL if nul is set. The errors of the package managers that failed are written to errw,
+// keeping w to the records.
+func writePorcelain(w io.Writer, errw io.Writer, results map[string]*Result, nul bool) error {
+	terminator := "\n"
+	if nul {
+		terminator = "\x00"
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		result := results[name]
+		if result.Error != "" && !result.Unsupported {
+			fmt.Fprintf(errw, "%s: %s\n", name, result.Error)
+		}
+		for _, pkg := range result.Packages {
+			columns := []string{name, pkg.Name, pkg.Version, pkg.NewVersion, string(pkg.Status), pkg.Arch}
+			for i, column := range columns {
+				columns[i] = porcelainColumn(column)
+			}
+			if _, err := io.WriteString(w, strings.Join(columns, "\t")+terminator); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// porcelainColumn returns value as a column of a porcelain record: "-" if empty, and with the tabs, newlines and NULs
+// that would break the record replaced by spaces.
+func porcelainColumn(value string) string {
+	if value == "" {
+		return porcelainEmpty
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r == 0 {
+			return ' '
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// testResults are the results of a command listing packages, with a package manager failing and one not supporting it.
+func testResults() map[string]*Result {
+	return map[string]*Result{
+		"snap": {Packages: []manager.PackageInfo{{Name: "code", Version: "1.95.3", Status: manager.PackageStatusInstalled, Category: "stable"}}},
+		"apt": {Packages: []manager.PackageInfo{
+			{Name: "vim", Version: "2:9.1.0016-1", NewVersion: "2:9.1.0016-2", Status: manager.PackageStatusUpgradable, Category: "noble-updates", Arch: "amd64", Origin: "Ubuntu"},
+			{Name: "odd\tname, \"quoted\"", Status: manager.PackageStatusAvailable},
+		}},
+		"brew":   {Packages: []manager.PackageInfo{}, Error: "brew: command failed"},
+		"winget": {Packages: []manager.PackageInfo{}, Error: manager.ErrOperationNotSupported.Error(), Unsupported: true},
+	}
+}
+
+func TestWritePorcelain(t *testing.T) {
+	tests := []struct {
+		name string
+		nul  bool
+		want string
+	}{
+		{
+			name: "newlines",
+			want: "apt\tvim\t2:9.1.0016-1\t2:9.1.0016-2\tupgradable\tamd64\n" +
+				"apt\todd name, \"quoted\"\t-\t-\tavailable\t-\n" +
+				"snap\tcode\t1.95.3\t-\tinstalled\t-\n",
+		},
+		{
+			name: "NUL",
+			nul:  true,
+			want: "apt\tvim\t2:9.1.0016-1\t2:9.1.0016-2\tupgradable\tamd64\x00" +
+				"apt\todd name, \"quoted\"\t-\t-\tavailable\t-\x00" +
+				"snap\tcode\t1.95.3\t-\tinstalled\t-\x00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w, errw bytes.Buffer
+			if err := writePorcelain(&w, &errw, testResults(), tt.nul); err != nil {
+				t.Fatalf("writePorcelain() error = %+v", err)
+			}
+			if got := w.String(); got != tt.want {
+				t.Errorf("writePorcelain() wrote %q, want %q", got, tt.want)
+			}
+			if got, want := errw.String(), "brew: brew: command failed\n"; got != want {
+				t.Errorf("writePorcelain() wrote the errors %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// progressBarWidth is the number of characters of the progress bars.
+const progressBarWidth = 30
+
+// ProgressRenderer reports the progress of package manager operations, either as a progress bar per package manager
+// and phase on stderr, or as NDJSON events on stdout when --json-stream or --output ndjson is set:
+//
+//	{"type":"progress","package_manager":"apt","phase":"install","package":"vim","percent":42.8571,"message":"Unpacking vim (amd64)"}
+type ProgressRenderer struct {
+	// JSON is set when the events are printed as NDJSON.
+	JSON bool
+
+	mu sync.Mutex
+	// current is the package manager and phase of the progress bar being drawn, if any.
+	current string
+}
+
+// progress renders the progress of the commands of the CLI.
+var progress = &ProgressRenderer{}
+
+// progressEvent is a progress event printed as an NDJSON line.
+type progressEvent struct {
+	Type string `json:"type"`
+	manager.ProgressEvent
+}
+
+// Report implements manager.ProgressReporter. It is safe to call concurrently.
+func (r *ProgressRenderer) Report(event manager.ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.JSON {
+		_ = writeEvent(progressEvent{Type: "progress", ProgressEvent: event})
+		return
+	}
+
+	// every package manager and phase gets its own line
+	key := event.PackageManager + " " + string(event.Phase)
+	if r.current != "" && r.current != key {
+		fmt.Fprintln(os.Stderr)
+	}
+	r.current = key
+	drawProgressBar(os.Stderr, event)
+}
+
+// Done ends the progress bar being drawn, if any, so that the next output starts on a new line.
+func (r *ProgressRenderer) Done() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current != "" {
+		fmt.Fprintln(os.Stderr)
+		r.current = ""
+	}
+}
+
+// drawProgressBar redraws the current line of w with the progress bar of event, e.g.
+//
+//	apt      install  [############..................]  42% Unpacking vim (amd64)
+func drawProgressBar(w io.Writer, event manager.ProgressEvent) {
+	percent := event.Percent
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	filled := int(percent / 100 * progressBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled)
+
+	// \r returns to the start of the line, and \033[K clears the rest of the previous message
+	fmt.Fprintf(w, "\r%-8s %-8s [%s] %3.0f%% %s\033[K", event.PackageManager, event.Phase, bar, percent, event.Message)
+}
+
+// isTerminal reports whether f is a terminal, where progress bars can be drawn and passwords prompted.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// the null device is a character device too
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// describeProvenance describes where a package comes from, for show package --provenance.
+func describeProvenance(pkg manager.PackageInfo) string {
+	_, classified := manager.OfficialOrigins[pkg.PackageManager]
+	switch {
+	case pkg.Origin == "" && classified:
+		return "in no known repository, such as a package installed from a file, or no longer available"
+	case pkg.Origin == "":
+		return "unknown"
+	case manager.IsThirdParty(pkg.PackageManager, pkg.Origin):
+		return fmt.Sprintf("third-party source (%s)", pkg.Origin)
+	case classified:
+		return fmt.Sprintf("official source (%s)", pkg.Origin)
+	}
+	return pkg.Origin
+}
+
+// withProvenance returns pkg with whether it comes from a third-party source in AdditionalData["third_party"], for
+// the JSON output of show package --provenance.
+func withProvenance(pkg manager.PackageInfo) manager.PackageInfo {
+	data := make(map[string]string, len(pkg.AdditionalData)+1)
+	for key, value := range pkg.AdditionalData {
+		data[key] = value
+	}
+	data["third_party"] = strconv.FormatBool(manager.IsThirdParty(pkg.PackageManager, pkg.Origin))
+	pkg.AdditionalData = data
+	return pkg
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg/manager/schedule"
+)
+
+// scheduler returns the scheduler of the backend selected with --backend, or the detected one.
+func scheduler(c *cli.Context) (*schedule.Scheduler, error) {
+	switch backend := c.String("backend"); backend {
+	case "":
+		return schedule.Detect(), nil
+	case schedule.BackendSystemd, schedule.BackendCron:
+		return &schedule.Scheduler{Backend: backend}, nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q, expected %s or %s", backend, schedule.BackendSystemd, schedule.BackendCron)
+	}
+}
+
+// scheduledCommand returns the command line of the scheduled upgrades: syspkg upgrading the packages of the
+// package managers selected by the configuration, or of the categories given with -c, without prompting, logging
+// warnings and errors only. It is given the path of the configuration file loaded now, explicit or the default one,
+// whose upgrade policy applies, as the default one may differ when the scheduled command runs.
+func scheduledCommand(c *cli.Context) ([]string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	command := []string{executable, "--assume-yes", "--log-level", "warn"}
+	if configPath != "" {
+		command = append(command, "--config", configPath)
+	}
+	for _, category := range c.StringSlice("category") {
+		command = append(command, "--category", category)
+	}
+
+	command = append(command, "upgrade")
+	if c.Bool("security-only") {
+		command = append(command, "--security-only")
+	}
+	if c.IsSet("webhook") {
+		command = append(command, "--webhook", c.String("webhook"))
+	}
+	return command, nil
+}
+
+// installSchedule installs the scheduled upgrades, at the time given with --at.
+func installSchedule(c *cli.Context) error {
+	at, err := schedule.ParseTime(c.String("at"))
+	if err != nil {
+		return err
+	}
+	s, err := scheduler(c)
+	if err != nil {
+		return err
+	}
+	command, err := scheduledCommand(c)
+	if err != nil {
+		return err
+	}
+
+	files, err := s.Install(at, command)
+	for _, file := range files {
+		fmt.Println("Installed", file)
+	}
+	if err != nil {
+		return err
+	}
+	if s.Backend == schedule.BackendSystemd {
+		fmt.Printf("Upgrades run at %s; see their output with journalctl -u %s\n", at.OnCalendar(), schedule.Name)
+	} else {
+		fmt.Printf("Upgrades run at %s; their output is appended to %s\n", at.Cron(), schedule.LogFile)
+	}
+	return nil
+}
+
+// showSchedule prints the files of the installed scheduled upgrades.
+func showSchedule(c *cli.Context) error {
+	s, err := scheduler(c)
+	if err != nil {
+		return err
+	}
+	installed, err := s.Installed()
+	if errors.Is(err, schedule.ErrNotInstalled) {
+		fmt.Println("No scheduled upgrades are installed.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, 0, len(installed))
+	for file := range installed {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		fmt.Printf("==> %s <==\n%s\n", file, installed[file])
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/history"
+	"github.com/bluet/syspkg/manager/snapshot"
+)
+
+// saveSnapshot saves the installed packages of the package managers in the snapshot named as argument.
+func saveSnapshot(c *cli.Context, pms map[string]syspkg.PackageManager) error {
+	var opts = getOptions(c)
+
+	if c.NArg() != 1 {
+		fmt.Println("Please specify one and only one snapshot name.")
+		return nil
+	}
+
+	snap := snapshot.New(c.Args().First())
+	for name, pm := range pms {
+		installed, err := pm.ListInstalled(opts)
+		if err != nil {
+			fmt.Printf("Error while listing installed packages for %T, not saved in the snapshot: %+v\n", pm, err)
+			continue
+		}
+		snap.Add(name, installed)
+	}
+
+	if err := snapshot.Save(snapshot.DefaultDir(), snap); err != nil {
+		return err
+	}
+	log.Printf("Saved snapshot %s\n", snap.Name)
+	return nil
+}
+
+// restoreSnapshot restores the snapshot named as argument, once the operations it takes are listed and confirmed.
+func restoreSnapshot(c *cli.Context, pms map[string]syspkg.PackageManager) error {
+	var opts = getOptions(c)
+
+	if c.NArg() != 1 {
+		fmt.Println("Please specify one and only one snapshot name.")
+		return nil
+	}
+
+	snap, err := snapshot.Load(snapshot.DefaultDir(), c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	plans := make(map[string][]snapshot.Operation)
+	for name, pm := range pms {
+		installed, err := pm.ListInstalled(opts)
+		if err != nil {
+			fmt.Printf("Error while listing installed packages for %T: %+v\n", pm, err)
+			continue
+		}
+		for _, op := range snap.Plan(name, installed) {
+			if op.Action == snapshot.ActionDelete && c.Bool("keep") {
+				continue
+			}
+			fmt.Printf("%s: %s %s %s -> %s\n", op.PackageManager, op.Action, op.Package, op.CurrentVersion, op.Version)
+			plans[name] = append(plans[name], op)
+		}
+	}
+
+	if len(plans) == 0 {
+		fmt.Println("Nothing to do, the installed packages match the snapshot.")
+		return nil
+	}
+	if opts.DryRun {
+		return nil
+	}
+	if !opts.AssumeYes {
+		fmt.Print("\nDo you want to restore the snapshot? [Y/n]: ")
+		input := ""
+		_, _ = fmt.Scanln(&input)
+		input = strings.ToLower(input)
+
+		if input != "y" && input != "" {
+			fmt.Println("Restore cancelled.")
+			return nil
+		}
+	}
+
+	for name, operations := range plans {
+		restoreOperations(pms[name], operations, opts)
+	}
+	return nil
+}
+
+// restoreOperations performs the operations planned to restore a snapshot with a package manager:
+// missing packages are installed, and packages installed after the snapshot are deleted.
+// Packages upgraded after the snapshot are downgraded back to their saved version, if the package manager supports it.
+// Upgrading to a specific version is not supported yet, so upgrades are only reported.
+func restoreOperations(pm syspkg.PackageManager, operations []snapshot.Operation, opts *manager.Options) {
+	var install, remove, downgrade []string
+	for _, op := range operations {
+		switch op.Action {
+		case snapshot.ActionInstall:
+			install = append(install, op.Package)
+		case snapshot.ActionDelete:
+			remove = append(remove, op.Package)
+		case snapshot.ActionDowngrade:
+			downgrade = append(downgrade, manager.PackageSpec{Name: op.Package, Version: op.Version}.String())
+		default:
+			fmt.Printf("%s: cannot %s %s to %s, installing a specific version is not supported\n", op.PackageManager, op.Action, op.Package, op.Version)
+		}
+	}
+
+	if len(install) > 0 {
+		packages, err := withHooks(pm.GetPackageManager(), "install", install, opts, func() ([]manager.PackageInfo, error) {
+			return pm.Install(install, opts)
+		})
+		recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: history.OperationInstall, Requested: install, Packages: packages}, err, opts)
+		if err != nil {
+			fmt.Printf("Error while installing packages for %T: %+v\n", pm, err)
+		}
+	}
+	if len(remove) > 0 {
+		packages, err := withHooks(pm.GetPackageManager(), "delete", remove, opts, func() ([]manager.PackageInfo, error) {
+			return pm.Delete(remove, opts)
+		})
+		recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: history.OperationDelete, Requested: remove, Packages: packages}, err, opts)
+		if err != nil {
+			fmt.Printf("Error while deleting packages for %T: %+v\n", pm, err)
+		}
+	}
+	if len(downgrade) > 0 {
+		_ = downgradePackages(pm, downgrade, opts, nil)
+	}
+}
+
+// listSnapshots lists the saved snapshots.
+func listSnapshots(c *cli.Context) error {
+	names, err := snapshot.List(snapshot.DefaultDir())
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+)
+
+// auditSources audits the enabled repositories of the package managers able to list them, for sources audit. The
+// origins of the installed packages come from ListOrigins, for the package managers whose ListInstalled doesn't tell
+// them.
+func auditSources(pms map[string]syspkg.PackageManager, opts *manager.Options) []manager.SourceAudit {
+	var mu sync.Mutex
+	var audits []manager.SourceAudit
+	forEachConcurrently(pms, func(name string, pm syspkg.PackageManager) {
+		lister, ok := pm.(syspkg.RepositoryLister)
+		if !ok {
+			log.Printf("Listing repositories is not supported by %s, skipping\n", name)
+			return
+		}
+		repos, err := lister.ListRepositories(opts)
+		if err != nil {
+			log.Printf("Error while listing repositories for %s, skipping: %+v\n", name, err)
+			return
+		}
+
+		origins, err := installedOrigins(pm, opts)
+		if err != nil {
+			log.Printf("Error while listing the origins of the installed packages for %s: %+v\n", name, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		audits = append(audits, manager.AuditSources(repos, origins)...)
+	})
+
+	sort.SliceStable(audits, func(i, j int) bool {
+		return audits[i].Repository.PackageManager < audits[j].Repository.PackageManager
+	})
+	return audits
+}
+
+// installedOrigins returns the origins of the installed packages of pm, by package name.
+func installedOrigins(pm syspkg.PackageManager, opts *manager.Options) (map[string]string, error) {
+	if lister, ok := pm.(syspkg.OriginLister); ok {
+		return lister.ListOrigins(opts)
+	}
+	packages, err := pm.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	origins := make(map[string]string, len(packages))
+	for _, pkg := range packages {
+		if pkg.Origin != "" {
+			origins[pkg.Name] = pkg.Origin
+		}
+	}
+	return origins, nil
+}
+
+// describeSourceAudit describes an audited repository, with the installed packages coming from it if it is flagged.
+func describeSourceAudit(a manager.SourceAudit) string {
+	repo := a.Repository
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", repo.PackageManager, repo.Name)
+	if repo.URL != "" && !strings.HasPrefix(repo.Name, repo.URL) {
+		fmt.Fprintf(&b, " %s", repo.URL)
+	}
+	if repo.Origin != "" && repo.Origin != repo.Name {
+		fmt.Fprintf(&b, " (origin %s)", repo.Origin)
+	}
+
+	var flags []string
+	if a.ThirdParty {
+		flags = append(flags, "third-party")
+	}
+	if repo.Unsigned {
+		flags = append(flags, "unsigned")
+	}
+	if len(flags) == 0 {
+		b.WriteString(" [ok]")
+		return b.String()
+	}
+	fmt.Fprintf(&b, " [%s]", strings.Join(flags, ", "))
+
+	if len(a.Packages) == 0 {
+		b.WriteString("\n  no installed packages")
+	} else {
+		fmt.Fprintf(&b, "\n  installed packages (%d): %s", len(a.Packages), strings.Join(a.Packages, " "))
+	}
+	return b.String()
+}
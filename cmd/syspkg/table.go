@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// tableSeparator separates the columns of tables.
+const tableSeparator = "  "
+
+// tableMinColumnWidth is the width under which the columns of tables aren't truncated to fit the terminal.
+const tableMinColumnWidth = 8
+
+// tableLayout is how the tables of packages are printed, from the --wide and --no-truncate flags.
+type tableLayout struct {
+	// width is the width the tables are truncated to, that of the terminal, or 0 for no truncation.
+	width int
+
+	// wide adds the columns of the details of the packages: architecture, category, origin and size.
+	wide bool
+
+	// color colors the cells of the columns having a color, such as the status of the packages, from --color.
+	color bool
+}
+
+// newTableLayout returns the layout of the tables of a command: they are truncated to the width of the terminal,
+// $COLUMNS if set, unless --no-truncate is set or the standard output isn't a terminal, such as a pipe, and colored
+// as set by --color.
+func newTableLayout(c *cli.Context) tableLayout {
+	layout := tableLayout{wide: c.Bool("wide"), color: colorEnabled(c)}
+	if c.Bool("no-truncate") || !isTerminal(os.Stdout) {
+		return layout
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		layout.width = columns
+		return layout
+	}
+	_, layout.width = terminalSize(os.Stdout)
+	return layout
+}
+
+// packageColumn is a column of the tables of packages, whose cells have the ANSI color returned by color, if set.
+type packageColumn struct {
+	header string
+	value  func(pkg manager.PackageInfo) string
+	color  func(pkg manager.PackageInfo) string
+}
+
+// packageColumns returns the columns of the tables of packages: the package manager, name, versions and status of
+// the packages, then with layout.wide their details, then extra.
+func packageColumns(layout tableLayout, extra ...packageColumn) []packageColumn {
+	columns := []packageColumn{
+		{header: "MANAGER", value: func(pkg manager.PackageInfo) string { return pkg.PackageManager }},
+		{header: "NAME", value: func(pkg manager.PackageInfo) string { return pkg.Name }},
+		{header: "VERSION", value: func(pkg manager.PackageInfo) string { return pkg.Version }},
+		{header: "NEW VERSION", value: func(pkg manager.PackageInfo) string { return pkg.NewVersion }},
+		{
+			header: "STATUS",
+			value:  func(pkg manager.PackageInfo) string { return string(pkg.Status) },
+			color:  func(pkg manager.PackageInfo) string { return statusColor(pkg.Status) },
+		},
+	}
+	if layout.wide {
+		columns = append(columns,
+			packageColumn{header: "ARCH", value: func(pkg manager.PackageInfo) string { return pkg.Arch }},
+			packageColumn{header: "CATEGORY", value: func(pkg manager.PackageInfo) string { return pkg.Category }},
+			packageColumn{header: "ORIGIN", value: func(pkg manager.PackageInfo) string { return pkg.Origin }},
+			sizeColumn,
+		)
+	}
+	return append(columns, extra...)
+}
+
+// sizeColumn is the column of the installed size of the packages.
+var sizeColumn = packageColumn{header: "SIZE", value: func(pkg manager.PackageInfo) string { return formatSize(pkg.SizeInstalled) }}
+
+// printPackageTable prints pkgs to w as a table of columns, laid out by layout.
+func printPackageTable(w io.Writer, pkgs []manager.PackageInfo, columns []packageColumn, layout tableLayout) {
+	if len(pkgs) == 0 {
+		return
+	}
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.header
+	}
+	rows := make([][]string, 0, len(pkgs))
+	var colors [][]string
+	for _, pkg := range pkgs {
+		row := make([]string, len(columns))
+		rowColors := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.value(pkg)
+			if layout.color && column.color != nil {
+				rowColors[i] = column.color(pkg)
+			}
+		}
+		rows = append(rows, row)
+		colors = append(colors, rowColors)
+	}
+	renderTable(w, header, rows, colors, layout.width)
+}
+
+// renderTable prints the rows of cells to w under header, with the columns aligned, and the cells in the ANSI colors
+// of colors, by row and column, if set. If width is set, the widest columns are shrunk until the rows fit in width,
+// down to tableMinColumnWidth, their cells truncated with an ellipsis. The widths of the cells are the ones they take
+// on terminals, such as two for CJK characters.
+func renderTable(w io.Writer, header []string, rows [][]string, colors [][]string, width int) {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+	if width > 0 {
+		fitColumns(widths, width-len(tableSeparator)*(len(widths)-1))
+	}
+
+	for r, row := range append([][]string{header}, rows...) {
+		var b strings.Builder
+		for i, cell := range row {
+			cell = truncateCell(cell, widths[i])
+			padding := strings.Repeat(" ", widths[i]-displayWidth(cell))
+			if r > 0 && r-1 < len(colors) {
+				cell = colorize(cell, colors[r-1][i])
+			}
+			b.WriteString(cell)
+			if i < len(row)-1 {
+				b.WriteString(padding + tableSeparator)
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+}
+
+// fitColumns shrinks the widest of widths, one character at a time, until their sum fits in width, or they are all
+// at most tableMinColumnWidth.
+func fitColumns(widths []int, width int) {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	for total > width {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= tableMinColumnWidth {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// truncateCell returns cell truncated to width, ending with an ellipsis when truncated.
+func truncateCell(cell string, width int) string {
+	if displayWidth(cell) <= width {
+		return cell
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range cell {
+		if used+runeWidth(r) > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += runeWidth(r)
+	}
+	return b.String() + "…"
+}
+
+// displayWidth returns the number of columns s takes on a terminal.
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth returns the number of columns r takes on a terminal: none for combining marks and format characters,
+// two for wide East Asian characters and emoji, and one otherwise.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1100 && r <= 0x115f, r >= 0x2e80 && r <= 0xa4cf && r != 0x303f, r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff, r >= 0xfe30 && r <= 0xfe4f, r >= 0xff00 && r <= 0xff60, r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f, r >= 0x1f900 && r <= 0x1f9ff, r >= 0x20000 && r <= 0x3fffd:
+		return 2
+	}
+	return 1
+}
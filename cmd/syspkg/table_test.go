@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestRenderTable(t *testing.T) {
+	header := []string{"NAME", "VERSION"}
+	tests := []struct {
+		name   string
+		rows   [][]string
+		colors [][]string
+		width  int
+		want   string
+	}{
+		{
+			name: "aligned",
+			rows: [][]string{{"vim", "2:9.1"}, {"libreoffice-core", "7.3"}},
+			want: "NAME              VERSION\n" +
+				"vim               2:9.1\n" +
+				"libreoffice-core  7.3\n",
+		},
+		{
+			name:  "truncated to the width",
+			rows:  [][]string{{"vim", "2:9.1"}, {"libreoffice-core", "7.3"}},
+			width: 20,
+			want: "NAME         VERSION\n" +
+				"vim          2:9.1\n" +
+				"libreoffic…  7.3\n",
+		},
+		{
+			name: "wide characters",
+			rows: [][]string{{"中文输入法", "1.0"}, {"vim", ""}},
+			want: "NAME        VERSION\n" +
+				"中文输入法  1.0\n" +
+				"vim\n",
+		},
+		{
+			name:   "colored",
+			rows:   [][]string{{"vim", "2:9.1"}, {"nano", "7.2"}},
+			colors: [][]string{{"", colorYellow}, {colorGreen, ""}},
+			want: "NAME  VERSION\n" +
+				"vim   \033[33m2:9.1\033[0m\n" +
+				"\033[32mnano\033[0m  7.2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			renderTable(&w, header, tt.rows, tt.colors, tt.width)
+			if got := w.String(); got != tt.want {
+				t.Errorf("renderTable() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		widths []int
+		width  int
+		want   []int
+	}{
+		{name: "fitting", widths: []int{10, 20}, width: 40, want: []int{10, 20}},
+		{name: "widest shrunk", widths: []int{10, 20}, width: 25, want: []int{10, 15}},
+		{name: "both shrunk", widths: []int{12, 20}, width: 20, want: []int{10, 10}},
+		{name: "down to the minimum", widths: []int{10, 20}, width: 5, want: []int{tableMinColumnWidth, tableMinColumnWidth}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fitColumns(tt.widths, tt.width)
+			if !reflect.DeepEqual(tt.widths, tt.want) {
+				t.Errorf("fitColumns() = %+v, want %+v", tt.widths, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintPackageTable(t *testing.T) {
+	pkgs := []manager.PackageInfo{
+		{PackageManager: "apt", Name: "vim", Version: "2:9.1.0016-1", NewVersion: "2:9.1.0016-2", Status: manager.PackageStatusUpgradable, Arch: "amd64", SizeInstalled: 4096},
+		{PackageManager: "snap", Name: "code", Version: "1.95.3", Status: manager.PackageStatusInstalled},
+	}
+	tests := []struct {
+		name   string
+		layout tableLayout
+		want   string
+	}{
+		{
+			name: "default",
+			want: "MANAGER  NAME  VERSION       NEW VERSION   STATUS\n" +
+				"apt      vim   2:9.1.0016-1  2:9.1.0016-2  upgradable\n" +
+				"snap     code  1.95.3                      installed\n",
+		},
+		{
+			name:   "colored",
+			layout: tableLayout{color: true},
+			want: "MANAGER  NAME  VERSION       NEW VERSION   STATUS\n" +
+				"apt      vim   2:9.1.0016-1  2:9.1.0016-2  \033[33mupgradable\033[0m\n" +
+				"snap     code  1.95.3                      \033[32minstalled\033[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			printPackageTable(&w, pkgs, packageColumns(tt.layout), tt.layout)
+			if got := w.String(); got != tt.want {
+				t.Errorf("printPackageTable() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
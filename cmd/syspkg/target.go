@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bluet/syspkg"
+)
+
+// packageManagerNames are the names of the package managers syspkg supports, which packages can be targeted at.
+var packageManagerNames = []string{
+	"apk", "apt", "brew", "cargo", "conda", "flatpak", "fwupd", "gem", "gobin", "nix", "npm", "pacman", "pip", "portage",
+	"snap", "winget", "zypper",
+}
+
+// parseTarget splits a package targeted at a package manager, as manager:package, such as apt:vim or
+// flatpak:org.gimp.GIMP, and returns an empty manager for the other packages. Names whose prefix is not a package
+// manager, such as vim:amd64 or the epoch of vim=2:9.0, are not targets.
+func parseTarget(arg string) (pm, pkg string, err error) {
+	prefix, name, ok := strings.Cut(arg, ":")
+	if !ok || !slices.Contains(packageManagerNames, prefix) {
+		return "", arg, nil
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("invalid target %q, expected a package after %s:", arg, prefix)
+	}
+	return prefix, name, nil
+}
+
+// parseTargets splits the packages of a command into the packages targeted at a package manager, such as apt:vim,
+// by package manager name, including the package managers that are not available, which are reported by the caller,
+// and the other packages, left to the selected package managers.
+func parseTargets(args []string) (map[string][]string, []string, error) {
+	targets := make(map[string][]string)
+	var untargeted []string
+	for _, arg := range args {
+		pm, pkg, err := parseTarget(arg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pm == "" {
+			untargeted = append(untargeted, pkg)
+			continue
+		}
+		targets[pm] = append(targets[pm], pkg)
+	}
+	return targets, untargeted, nil
+}
+
+// withTargets returns the selected package managers, with the available ones that packages of args are targeted at,
+// such as apt for apt:vim, unless they are excluded.
+func withTargets(selected, available map[string]syspkg.PackageManager, args []string) map[string]syspkg.PackageManager {
+	pms := make(map[string]syspkg.PackageManager, len(selected))
+	for name, pm := range selected {
+		pms[name] = pm
+	}
+	for _, arg := range args {
+		if name, _, _ := parseTarget(arg); available[name] != nil && !cfg.IsExcluded(name) {
+			pms[name] = available[name]
+		}
+	}
+	return pms
+}
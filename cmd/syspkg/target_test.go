@@ -0,0 +1,113 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager/apt"
+	"github.com/bluet/syspkg/manager/config"
+	"github.com/bluet/syspkg/manager/flatpak"
+	"github.com/bluet/syspkg/manager/snap"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		wantPM  string
+		wantPkg string
+		wantErr bool
+	}{
+		{name: "targeted", arg: "apt:vim", wantPM: "apt", wantPkg: "vim"},
+		{name: "flatpak application ID", arg: "flatpak:org.gimp.GIMP", wantPM: "flatpak", wantPkg: "org.gimp.GIMP"},
+		{name: "untargeted", arg: "vim", wantPkg: "vim"},
+		{name: "architecture", arg: "vim:amd64", wantPkg: "vim:amd64"},
+		{name: "epoch", arg: "vim=2:9.0", wantPkg: "vim=2:9.0"},
+		{name: "unknown package manager", arg: "foo:vim", wantPkg: "foo:vim"},
+		{name: "empty package", arg: "apt:", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, pkg, err := parseTarget(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTarget() error = %+v, wantErr %+v", err, tt.wantErr)
+			}
+			if pm != tt.wantPM || pkg != tt.wantPkg {
+				t.Errorf("parseTarget() = %q, %q, want %q, %q", pm, pkg, tt.wantPM, tt.wantPkg)
+			}
+		})
+	}
+}
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		wantTargets    map[string][]string
+		wantUntargeted []string
+		wantErr        bool
+	}{
+		{
+			name:           "targeted and untargeted",
+			args:           []string{"apt:vim", "nano", "snap:code", "apt:git"},
+			wantTargets:    map[string][]string{"apt": {"vim", "git"}, "snap": {"code"}},
+			wantUntargeted: []string{"nano"},
+		},
+		{
+			name:        "duplicate targets are kept",
+			args:        []string{"apt:vim", "apt:vim"},
+			wantTargets: map[string][]string{"apt": {"vim", "vim"}},
+		},
+		{
+			name:        "no packages",
+			wantTargets: map[string][]string{},
+		},
+		{
+			name:    "empty target",
+			args:    []string{"nano", "snap:"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, untargeted, err := parseTargets(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTargets() error = %+v, wantErr %+v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(targets, tt.wantTargets) || !reflect.DeepEqual(untargeted, tt.wantUntargeted) {
+				t.Errorf("parseTargets() = %+v, %+v, want %+v, %+v", targets, untargeted, tt.wantTargets, tt.wantUntargeted)
+			}
+		})
+	}
+}
+
+func TestWithTargets(t *testing.T) {
+	aptManager, snapManager, flatpakManager := &apt.PackageManager{}, &snap.PackageManager{}, &flatpak.PackageManager{}
+	selected := map[string]syspkg.PackageManager{"apt": aptManager}
+	available := map[string]syspkg.PackageManager{"apt": aptManager, "snap": snapManager, "flatpak": flatpakManager}
+
+	defer func(previous *config.Config) { cfg = previous }(cfg)
+	cfg = &config.Config{Exclude: []string{"flatpak"}}
+
+	tests := []struct {
+		name string
+		args []string
+		want map[string]syspkg.PackageManager
+	}{
+		{name: "untargeted", args: []string{"vim"}, want: map[string]syspkg.PackageManager{"apt": aptManager}},
+		{name: "targeted", args: []string{"vim", "snap:code"}, want: map[string]syspkg.PackageManager{"apt": aptManager, "snap": snapManager}},
+		{name: "unavailable", args: []string{"brew:wget"}, want: map[string]syspkg.PackageManager{"apt": aptManager}},
+		{name: "excluded", args: []string{"flatpak:org.gimp.GIMP"}, want: map[string]syspkg.PackageManager{"apt": aptManager}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withTargets(selected, available, tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withTargets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if len(selected) != 1 {
+		t.Errorf("withTargets() changed the selected package managers: %+v", selected)
+	}
+}
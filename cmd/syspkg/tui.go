@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/history"
+)
+
+// tuiAction is the operation chosen in the terminal UI for the selected packages.
+type tuiAction string
+
+// Operations of the terminal UI.
+const (
+	tuiInstall tuiAction = "install"
+	tuiRemove  tuiAction = "delete"
+	tuiUpgrade tuiAction = "upgrade"
+	tuiQuit    tuiAction = ""
+)
+
+// tuiItem is a package listed in the terminal UI.
+type tuiItem struct {
+	pkg      manager.PackageInfo
+	selected bool
+}
+
+// tuiModel is the state of the terminal UI: the listed packages, the cursor, and the first package shown.
+type tuiModel struct {
+	title  string
+	items  []tuiItem
+	cursor int
+	offset int
+}
+
+// runTUI lists the packages of the selected package managers in a terminal UI: the search results for keywords if any,
+// otherwise the installed or upgradable packages, and installs, removes or upgrades the packages selected in it.
+func runTUI(c *cli.Context, pms map[string]syspkg.PackageManager, opts *manager.Options) error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return errors.New("the terminal UI needs a terminal, use the other commands in scripts")
+	}
+
+	keywords := c.Args().Slice()
+	model := &tuiModel{}
+	var packages []manager.PackageInfo
+	switch {
+	case len(keywords) > 0:
+		model.title = "Search results for " + strings.Join(keywords, " ")
+		packages = queryAll(pms, func(pm syspkg.PackageManager) ([]manager.PackageInfo, error) { return pm.Find(keywords, opts) })
+	case c.Bool("installed"):
+		model.title = "Installed packages"
+		for _, installed := range listInstalled(pms, opts) {
+			packages = append(packages, installed...)
+		}
+	default:
+		model.title = "Upgradable packages"
+		packages = queryAll(pms, func(pm syspkg.PackageManager) ([]manager.PackageInfo, error) { return pm.ListUpgradable(opts) })
+	}
+	if len(packages) == 0 {
+		fmt.Println("No packages found.")
+		return nil
+	}
+	sort.SliceStable(packages, func(i, j int) bool {
+		if packages[i].PackageManager != packages[j].PackageManager {
+			return packages[i].PackageManager < packages[j].PackageManager
+		}
+		return packages[i].Name < packages[j].Name
+	})
+	for _, pkg := range packages {
+		model.items = append(model.items, tuiItem{pkg: pkg})
+	}
+
+	action, err := model.run()
+	if err != nil || action == tuiQuit {
+		return err
+	}
+	return applyTUIAction(c, pms, action, model.selected(), opts)
+}
+
+// queryAll runs query for all the given package managers concurrently, and returns the packages they found.
+func queryAll(pms map[string]syspkg.PackageManager, query func(pm syspkg.PackageManager) ([]manager.PackageInfo, error)) []manager.PackageInfo {
+	var mu sync.Mutex
+	var packages []manager.PackageInfo
+	forEachConcurrently(pms, func(name string, pm syspkg.PackageManager) {
+		found, err := query(pm)
+		if err != nil {
+			log.Printf("Error while querying packages for %s, skipping: %+v\n", name, err)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		packages = append(packages, found...)
+	})
+	return packages
+}
+
+// selected returns the names of the selected packages, by package manager name.
+func (m *tuiModel) selected() map[string][]string {
+	selected := make(map[string][]string)
+	for _, item := range m.items {
+		if item.selected {
+			selected[item.pkg.PackageManager] = append(selected[item.pkg.PackageManager], item.pkg.Name)
+		}
+	}
+	return selected
+}
+
+// run shows the terminal UI until an action is chosen, or the user quits.
+func (m *tuiModel) run() (tuiAction, error) {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return tuiQuit, err
+	}
+	defer tty.Close()
+
+	state, err := stty(tty, "-g")
+	if err != nil {
+		return tuiQuit, fmt.Errorf("can't set up the terminal: %w", err)
+	}
+	if _, err := stty(tty, "-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return tuiQuit, fmt.Errorf("can't set up the terminal: %w", err)
+	}
+	// the logs would garble the screen
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	// switch to the alternate screen, and hide the cursor
+	fmt.Print("\033[?1049h\033[?25l")
+	defer func() {
+		fmt.Print("\033[?25h\033[?1049l")
+		slog.SetDefault(logger)
+		_, _ = stty(tty, strings.TrimSpace(state))
+	}()
+
+	keys := bufio.NewReader(tty)
+	for {
+		rows, cols := terminalSize(tty)
+		m.render(os.Stdout, rows, cols)
+
+		key, err := readKey(keys)
+		if err != nil {
+			return tuiQuit, err
+		}
+		switch key {
+		case "up", "k":
+			m.move(-1, rows)
+		case "down", "j":
+			m.move(1, rows)
+		case "pgup":
+			m.move(-listHeight(rows), rows)
+		case "pgdown":
+			m.move(listHeight(rows), rows)
+		case " ":
+			m.items[m.cursor].selected = !m.items[m.cursor].selected
+			m.move(1, rows)
+		case "a":
+			// select all, or none if all are selected
+			all := true
+			for _, item := range m.items {
+				all = all && item.selected
+			}
+			for i := range m.items {
+				m.items[i].selected = !all
+			}
+		case "i", "r", "u":
+			if len(m.selected()) == 0 {
+				continue
+			}
+			return map[string]tuiAction{"i": tuiInstall, "r": tuiRemove, "u": tuiUpgrade}[key], nil
+		case "q", "esc", "ctrl-c":
+			return tuiQuit, nil
+		}
+	}
+}
+
+// listHeight returns the number of packages shown on a terminal of rows lines, below the title and above the help line.
+func listHeight(rows int) int {
+	if rows < 4 {
+		return 1
+	}
+	return rows - 3
+}
+
+// move moves the cursor by delta packages, scrolling the list to keep it visible.
+func (m *tuiModel) move(delta int, rows int) {
+	m.cursor += delta
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if m.cursor >= len(m.items) {
+		m.cursor = len(m.items) - 1
+	}
+
+	height := listHeight(rows)
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+}
+
+// render draws the terminal UI on w, for a terminal of rows lines and cols columns:
+//
+//	Upgradable packages (2 selected)
+//
+//	> [x] apt      vim                  2:8.2.3995-1ubuntu2.15 -> 2:8.2.3995-1ubuntu2.16
+//	  [ ] apt      openssl              3.0.2-0ubuntu1.10 -> 3.0.2-0ubuntu1.12
+//	space: select  a: all  i: install  r: remove  u: upgrade  q: quit
+func (m *tuiModel) render(w io.Writer, rows int, cols int) {
+	selected := 0
+	for _, item := range m.items {
+		if item.selected {
+			selected++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J")
+	fmt.Fprintf(&b, "\033[1m%s\033[0m (%d/%d, %d selected)\r\n\r\n", truncate(m.title, cols-30), m.cursor+1, len(m.items), selected)
+	for i := m.offset; i < len(m.items) && i < m.offset+listHeight(rows); i++ {
+		item := m.items[i]
+		cursor, check := " ", " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		if item.selected {
+			check = "x"
+		}
+		version := item.pkg.Version
+		if item.pkg.NewVersion != "" {
+			if version != "" {
+				version += " -> "
+			}
+			version += item.pkg.NewVersion
+		}
+		line := fmt.Sprintf("%s [%s] %-8s %-20s %s", cursor, check, item.pkg.PackageManager, item.pkg.Name, version)
+		if i == m.cursor {
+			// reverse video for the package under the cursor
+			fmt.Fprintf(&b, "\033[7m%s\033[0m\r\n", truncate(line, cols))
+		} else {
+			fmt.Fprintf(&b, "%s\r\n", truncate(line, cols))
+		}
+	}
+	fmt.Fprintf(&b, "\033[%d;1H%s", rows, truncate("space: select  a: all  i: install  r: remove  u: upgrade  q: quit", cols))
+	_, _ = io.WriteString(w, b.String())
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// readKey reads a key press: a character, or the name of a special key such as "up", "pgdown", "esc" or "ctrl-c".
+func readKey(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case 3:
+		return "ctrl-c", nil
+	case '\r', '\n':
+		return "enter", nil
+	case 27:
+		// escape sequences of the special keys are sent at once, while a lone escape is a key press of its own
+		if r.Buffered() == 0 {
+			return "esc", nil
+		}
+		seq := make([]byte, 0, 4)
+		for r.Buffered() > 0 && len(seq) < cap(seq) {
+			c, _ := r.ReadByte()
+			seq = append(seq, c)
+		}
+		switch string(seq) {
+		case "[A", "OA":
+			return "up", nil
+		case "[B", "OB":
+			return "down", nil
+		case "[5~":
+			return "pgup", nil
+		case "[6~":
+			return "pgdown", nil
+		}
+		return "", nil
+	}
+	return string(b), nil
+}
+
+// stty runs stty with args on the terminal tty, and returns its output.
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// terminalSize returns the number of lines and columns of the terminal tty, or 24x80 if unknown.
+func terminalSize(tty *os.File) (int, int) {
+	out, err := stty(tty, "size")
+	if err != nil {
+		return 24, 80
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 24, 80
+	}
+	rows, err1 := strconv.Atoi(fields[0])
+	cols, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil || rows <= 0 || cols <= 0 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+// applyTUIAction installs, removes or upgrades the packages selected in the terminal UI, by package manager name.
+func applyTUIAction(c *cli.Context, pms map[string]syspkg.PackageManager, action tuiAction, selected map[string][]string, opts *manager.Options) error {
+	out := newOutputFormatter(c, "tui "+string(action))
+	for name, pkgNames := range selected {
+		pm := pms[name]
+		start := out.Start(name)
+
+		var packages []manager.PackageInfo
+		var err error
+		var operation history.Operation
+		switch action {
+		case tuiInstall:
+			operation = history.OperationInstall
+			packages, err = withHooks(name, "install", pkgNames, opts, func() ([]manager.PackageInfo, error) {
+				return pm.Install(pkgNames, opts)
+			})
+		case tuiRemove:
+			operation = history.OperationDelete
+			packages, err = withHooks(name, "delete", pkgNames, opts, func() ([]manager.PackageInfo, error) {
+				return pm.Delete(pkgNames, opts)
+			})
+		case tuiUpgrade:
+			operation = history.OperationUpgrade
+			if u, ok := pm.(syspkg.Upgrader); ok {
+				packages, err = withHooks(name, "upgrade", pkgNames, opts, func() ([]manager.PackageInfo, error) {
+					return u.Upgrade(pkgNames, opts)
+				})
+			} else {
+				err = manager.ErrOperationNotSupported
+			}
+		}
+		recordTransaction(history.Transaction{PackageManager: name, Operation: operation, Requested: pkgNames, Packages: packages}, err, opts)
+		if out.Add(name, packages, err, start); out.JSON {
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Error while running %s for %s: %+v\n", action, name, err)
+			continue
+		}
+		for _, pkg := range packages {
+			fmt.Printf("%s: %s %s (%s)\n", pkg.PackageManager, pkg.Name, pkg.Version, pkg.Status)
+		}
+	}
+	return out.Flush()
+}
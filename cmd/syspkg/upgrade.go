@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/history"
+	"github.com/bluet/syspkg/manager/hooks"
+	"github.com/bluet/syspkg/manager/report"
+)
+
+// upgradePolicy returns the upgrade policy of the configuration, with the settings given with the flags of the upgrade
+// command overriding it.
+func upgradePolicy(c *cli.Context) (*manager.UpgradePolicy, error) {
+	policy := cfg.Upgrade
+	if c.IsSet("exclude") {
+		policy.Exclude = nil
+		for _, expr := range c.StringSlice("exclude") {
+			p, err := manager.ParsePattern(expr, false)
+			if err != nil {
+				return nil, fmt.Errorf("--exclude: %w", err)
+			}
+			policy.Exclude = append(policy.Exclude, p)
+		}
+	}
+	if c.IsSet("security-only") {
+		policy.SecurityOnly = c.Bool("security-only")
+	}
+	if c.IsSet("max-packages") {
+		if c.Int("max-packages") < 0 {
+			return nil, errors.New("--max-packages must not be negative")
+		}
+		policy.MaxPackages = c.Int("max-packages")
+	}
+	if c.Bool("ignore-window") {
+		policy.Windows = nil
+	}
+	return &policy, nil
+}
+
+// policyUpgrades returns the packages of package manager pm to upgrade under the upgrade policy: nil to upgrade all
+// of them, or the selected ones, possibly none, when the policy is Selective. The excluded packages are logged.
+func policyUpgrades(pm syspkg.PackageManager, policy *manager.UpgradePolicy, opts *manager.Options) ([]string, error) {
+	if !policy.Selective() && policy.MaxPackages == 0 {
+		return nil, nil
+	}
+	if _, ok := pm.(syspkg.Upgrader); policy.Selective() && !ok {
+		return nil, fmt.Errorf("%w: upgrading specific packages, required by the upgrade policy", manager.ErrOperationNotSupported)
+	}
+
+	selected, excluded, err := syspkg.SelectUpgrades(pm, policy, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range excluded {
+		log.Printf("Not upgrading %s of %s, excluded by the upgrade policy\n", pkg.Name, pm.GetPackageManager())
+	}
+	if !policy.Selective() {
+		return nil, nil
+	}
+	names := []string{}
+	for _, pkg := range selected {
+		names = append(names, pkg.Name)
+	}
+	return names, nil
+}
+
+// planPolicyUpgrade returns the changes upgrading the packages of pm allowed by the upgrade policy would make.
+func planPolicyUpgrade(pm syspkg.PackageManager, policy *manager.UpgradePolicy, opts *manager.Options) (*manager.Plan, error) {
+	pkgs, err := policyUpgrades(pm, policy, opts)
+	if err != nil {
+		return nil, err
+	}
+	if pkgs != nil && len(pkgs) == 0 {
+		return manager.NewPlan(pm.GetPackageManager(), manager.PlanActionUpgrade, nil), nil
+	}
+	return syspkg.PlanUpgrade(pm, pkgs, opts)
+}
+
+// validateReportFormat checks the value of the --report-format flag.
+func validateReportFormat(c *cli.Context, format string) error {
+	switch format {
+	case report.FormatText, report.FormatMarkdown, report.FormatJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown report format %q, expected %s, %s or %s", format, report.FormatText, report.FormatMarkdown, report.FormatJSON)
+}
+
+// upgradePackages upgrades the packages of the package managers, as allowed by the upgrade policy, once the upgradable
+// packages are listed and confirmed, or shows the plan of the upgrade with --dry-run.
+func upgradePackages(c *cli.Context, pms map[string]syspkg.PackageManager) error {
+	var opts = getOptions(c)
+
+	policy, err := upgradePolicy(c)
+	if err != nil {
+		return err
+	}
+	if c.IsSet("webhook") {
+		hook := hooks.Hook{Event: "post-upgrade", Webhook: c.String("webhook")}
+		if err := hook.Validate(); err != nil {
+			return fmt.Errorf("--webhook: %w", err)
+		}
+		cfg.Hooks = append(cfg.Hooks, hook)
+	}
+	if err := policy.Allow(time.Now()); err != nil {
+		if !opts.DryRun {
+			return err
+		}
+		log.Printf("%v, the upgrade would be refused\n", err)
+	}
+
+	log.Printf("Upgrading packages... for %T\n", pms)
+
+	out := newOutputFormatter(c, "upgrade")
+	if opts.DryRun {
+		for _, pm := range pms {
+			start := out.Start(pm.GetPackageManager())
+			plan, err := planPolicyUpgrade(pm, policy, opts)
+			showPlan(out, pm.GetPackageManager(), plan, err, start)
+		}
+		return out.Finish()
+	}
+	if !out.JSON {
+		listUpgradablePackages(pms, opts, newOutputFormatter(c, "show upgradable"), nil, newTableLayout(c))
+	}
+	if !opts.AssumeYes {
+		fmt.Print("\nDo you want to perform the system package upgrade? [Y/n]: ")
+		input := ""
+		_, _ = fmt.Scanln(&input)
+		input = strings.ToLower(input)
+
+		if input != "y" && input != "" {
+			fmt.Println("Upgrade cancelled.")
+			return nil
+		}
+		log.Println("User confirmed upgrade.")
+	}
+
+	return performUpgrade(pms, policy, opts, out, c.String("report-file"), c.String("report-format"))
+}
+
+// performUpgrade upgrades packages for the given package managers, as allowed by the upgrade policy, and prints the
+// report of the upgrade, also written to reportFile in reportFormat if set.
+func performUpgrade(pms map[string]syspkg.PackageManager, policy *manager.UpgradePolicy, opts *manager.Options, out *OutputFormatter, reportFile string, reportFormat string) error {
+	if !out.JSON {
+		fmt.Println("Performing package upgrade...")
+	}
+
+	rep := report.New()
+	for _, pm := range pms {
+		start := out.Start(pm.GetPackageManager())
+		pkgs, err := policyUpgrades(pm, policy, opts)
+		if err == nil {
+			err = checkLimits(pm.GetPackageManager(), func() (*manager.Plan, error) { return syspkg.PlanUpgrade(pm, pkgs, opts) })
+		}
+		if err != nil {
+			rep.Add(pm.GetPackageManager(), nil, err, time.Since(start))
+			if out.Add(pm.GetPackageManager(), nil, err, start); !out.JSON {
+				fmt.Printf("Error while upgrading packages for %T: %+v\n", pm, err)
+			}
+			continue
+		}
+		if pkgs != nil && len(pkgs) == 0 {
+			rep.Add(pm.GetPackageManager(), nil, nil, time.Since(start))
+			if out.Add(pm.GetPackageManager(), nil, nil, start); !out.JSON {
+				log.Printf("No packages to upgrade for %T under the upgrade policy\n", pm)
+			}
+			continue
+		}
+		packages, err := withHooks(pm.GetPackageManager(), "upgrade", pkgs, opts, func() ([]manager.PackageInfo, error) {
+			if pkgs == nil {
+				return pm.UpgradeAll(opts)
+			}
+			return pm.(syspkg.Upgrader).Upgrade(pkgs, opts)
+		})
+		recordTransaction(history.Transaction{PackageManager: pm.GetPackageManager(), Operation: history.OperationUpgrade, Requested: pkgs, Packages: packages}, err, opts)
+		entry := rep.Add(pm.GetPackageManager(), packages, err, time.Since(start))
+		if r, ok := pm.(syspkg.RebootChecker); ok && err == nil && len(packages) > 0 {
+			if status, err := r.NeedsReboot(opts); err == nil {
+				entry.Reboot = &status
+			} else {
+				log.Printf("Cannot check whether %s requires a reboot: %+v\n", pm.GetPackageManager(), err)
+			}
+		}
+		if out.Add(pm.GetPackageManager(), packages, err, start); !out.JSON && err != nil {
+			fmt.Printf("Error while upgrading packages for %T: %+v\n%+v", pm, err, packages)
+		}
+	}
+
+	rep.Finish()
+	notifyUpgrade(rep)
+	if !out.JSON {
+		fmt.Println()
+		if err := rep.WriteText(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if reportFile != "" {
+		if err := rep.WriteFile(reportFile, reportFormat); err != nil {
+			return fmt.Errorf("--report-file: %w", err)
+		}
+		log.Printf("Wrote the upgrade report to %s\n", reportFile)
+	}
+	return out.Finish()
+}
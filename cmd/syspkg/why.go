@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+)
+
+// explainInstalled prints why the package pkg is installed with pm, for why: the chains of dependencies from the
+// packages installed explicitly to pkg, as trees. It reports whether pkg is installed with pm.
+func explainInstalled(pm syspkg.PackageManager, pkg string, depth int, all bool, opts *manager.Options) (bool, error) {
+	name := pm.GetPackageManager()
+	dq, ok := pm.(syspkg.DependencyQuerier)
+	if !ok {
+		log.Printf("Querying dependencies is not supported by %s, skipping\n", name)
+		return false, nil
+	}
+
+	installed, err := pm.ListInstalled(opts)
+	if err != nil {
+		return false, err
+	}
+	if !containsPackage(installed, pkg) {
+		return false, nil
+	}
+
+	// the package managers not telling the explicitly installed packages get the ones nothing depends on
+	var explicit map[string]bool
+	if el, ok := pm.(syspkg.ExplicitLister); ok {
+		names, err := el.ListExplicit(opts)
+		if err != nil {
+			return true, err
+		}
+		explicit = make(map[string]bool, len(names))
+		for _, n := range names {
+			explicit[n] = true
+		}
+	}
+
+	chains, err := manager.WhyInstalled(pkg, explicit, func(pkg string) ([]manager.PackageInfo, error) {
+		return dq.GetReverseDependencies(pkg, opts)
+	}, depth, all)
+	if err != nil {
+		return true, err
+	}
+
+	switch {
+	case len(chains) == 1 && len(chains[0]) == 1 && explicit != nil:
+		fmt.Printf("%s: %s is installed explicitly\n", name, pkg)
+	case len(chains) == 1 && len(chains[0]) == 1:
+		fmt.Printf("%s: %s is installed, and no other installed package depends on it\n", name, pkg)
+	case len(chains) == 0:
+		fmt.Printf("%s: %s is installed as a dependency, but no explicitly installed package depends on it within %d levels; it may be left behind by a removed package\n", name, pkg, depth)
+	default:
+		fmt.Printf("%s: %s is installed as a dependency of\n", name, pkg)
+		for _, chain := range chains {
+			printChain(chain, explicit != nil)
+		}
+	}
+	return true, nil
+}
+
+// printChain prints a chain of dependencies as a tree, from the package installed explicitly to the explained one.
+func printChain(chain []string, explicit bool) {
+	if explicit {
+		fmt.Printf("%s (explicitly installed)\n", chain[0])
+	} else {
+		fmt.Printf("%s (required by no other package)\n", chain[0])
+	}
+	for i, name := range chain[1:] {
+		fmt.Printf("%s└── %s\n", strings.Repeat("    ", i), name)
+	}
+}
+
+// containsPackage reports whether packages include the package named name.
+func containsPackage(packages []manager.PackageInfo, name string) bool {
+	for _, pkg := range packages {
+		if pkg.Name == name {
+			return true
+		}
+	}
+	return false
+}
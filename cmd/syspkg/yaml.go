@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// yamlField is a field of a YAML mapping, kept in the order of the JSON object it comes from.
+type yamlField struct {
+	key   string
+	value any
+}
+
+// marshalYAML returns v as a YAML document, with the fields and names of its JSON encoding, in the same order, so
+// that --output yaml prints the same envelope as --json.
+func marshalYAML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := decodeYAMLNode(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	if isYAMLScalar(node) {
+		b.WriteString(yamlScalar(node) + "\n")
+	} else {
+		writeYAMLNode(&b, node, 0)
+	}
+	return []byte(b.String()), nil
+}
+
+// decodeYAMLNode decodes the next JSON value of decoder: objects as []yamlField, arrays as []any, and scalars as
+// returned by decoder.Token.
+func decodeYAMLNode(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		fields := []yamlField{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeYAMLNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, yamlField{key.(string), value})
+		}
+		_, err = decoder.Token()
+		return fields, err
+	case json.Delim('['):
+		items := []any{}
+		for decoder.More() {
+			item, err := decodeYAMLNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err = decoder.Token()
+		return items, err
+	}
+	return token, nil
+}
+
+// isYAMLScalar reports whether node is written on the line of its key or dash: a scalar, or an empty mapping or
+// sequence, written in flow style.
+func isYAMLScalar(node any) bool {
+	switch node := node.(type) {
+	case []yamlField:
+		return len(node) == 0
+	case []any:
+		return len(node) == 0
+	}
+	return true
+}
+
+// writeYAMLNode writes the non-empty mapping or sequence node to b in block style, indented by indent spaces.
+func writeYAMLNode(b *strings.Builder, node any, indent int) {
+	prefix := strings.Repeat(" ", indent)
+	switch node := node.(type) {
+	case []yamlField:
+		for _, field := range node {
+			b.WriteString(prefix + yamlScalar(field.key) + ":")
+			if isYAMLScalar(field.value) {
+				b.WriteString(" " + yamlScalar(field.value) + "\n")
+				continue
+			}
+			b.WriteString("\n")
+			writeYAMLNode(b, field.value, indent+2)
+		}
+	case []any:
+		for _, item := range node {
+			if isYAMLScalar(item) {
+				b.WriteString(prefix + "- " + yamlScalar(item) + "\n")
+				continue
+			}
+			// the item is indented past the dash, which replaces the indentation of its first line
+			var nested strings.Builder
+			writeYAMLNode(&nested, item, indent+2)
+			b.WriteString(prefix + "- " + nested.String()[indent+2:])
+		}
+	}
+}
+
+// yamlReserved are the plain scalars YAML parsers read as other values than strings, case-insensitively.
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true, "y": true, "n": true,
+	"null": true, "~": true,
+}
+
+// yamlScalar returns the scalar node as YAML: null, booleans and numbers as is, empty mappings and sequences in flow
+// style, and strings plain when they can't be read as anything else, or double-quoted otherwise.
+func yamlScalar(node any) string {
+	switch node := node.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if node {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return node.String()
+	case []yamlField:
+		return "{}"
+	case []any:
+		return "[]"
+	case string:
+		if isPlainYAML(node) {
+			return node
+		}
+		var b bytes.Buffer
+		encoder := json.NewEncoder(&b)
+		encoder.SetEscapeHTML(false)
+		_ = encoder.Encode(node)
+		// JSON strings are valid double-quoted YAML scalars
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+	return ""
+}
+
+// isPlainYAML reports whether s can be written as a plain YAML scalar: it starts with a letter, has only letters,
+// digits, spaces and a few punctuation marks that aren't YAML indicators there, doesn't end with a space, and isn't
+// a reserved word.
+func isPlainYAML(s string) bool {
+	if s == "" || yamlReserved[strings.ToLower(s)] || strings.HasSuffix(s, " ") {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || strings.ContainsRune(" _.-/+()", r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
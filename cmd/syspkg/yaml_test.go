@@ -0,0 +1,93 @@
+package main
+
+import "testing"
+
+func TestMarshalYAML(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name"`
+		Files []string `json:"files"`
+	}
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{
+			name: "mapping",
+			v: struct {
+				Name     string            `json:"name"`
+				Version  string            `json:"version"`
+				Reserved string            `json:"reserved"`
+				Empty    string            `json:"empty"`
+				Spaced   string            `json:"spaced"`
+				Quoted   string            `json:"quoted"`
+				Count    int               `json:"count"`
+				Enabled  bool              `json:"enabled"`
+				Missing  *string           `json:"missing"`
+				Tags     []string          `json:"tags"`
+				None     []string          `json:"none"`
+				Object   map[string]string `json:"object"`
+				Items    []item            `json:"items"`
+			}{
+				Name:     "vim",
+				Version:  "2:9.1.0016-1",
+				Reserved: "yes",
+				Spaced:   "trailing ",
+				Quoted:   "say \"hi\" <now>\n",
+				Count:    3,
+				Enabled:  true,
+				Tags:     []string{"editor", "#vi"},
+				None:     []string{},
+				Object:   map[string]string{},
+				Items:    []item{{Name: "vim", Files: []string{"/usr/bin/vim"}}, {Name: "vim runtime", Files: []string{}}},
+			},
+			want: `name: vim
+version: "2:9.1.0016-1"
+reserved: "yes"
+empty: ""
+spaced: "trailing "
+quoted: "say \"hi\" <now>\n"
+count: 3
+enabled: true
+missing: null
+tags:
+  - editor
+  - "#vi"
+none: []
+object: {}
+items:
+  - name: vim
+    files:
+      - "/usr/bin/vim"
+  - name: vim runtime
+    files: []
+`,
+		},
+		{
+			name: "nested sequences",
+			v:    [][]string{{"a", "b"}, {}},
+			want: "- - a\n  - b\n- []\n",
+		},
+		{
+			name: "reserved scalar",
+			v:    "No",
+			want: "\"No\"\n",
+		},
+		{
+			name: "null",
+			v:    nil,
+			want: "null\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := marshalYAML(tt.v)
+			if err != nil {
+				t.Fatalf("marshalYAML() error = %+v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("marshalYAML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package syspkg
+
+import (
+	"context"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ContextPackageManager is the context-based form of PackageManager: its operations take the context interrupting
+// their commands as first argument, rather than in Options.Context. New package managers may implement it instead of
+// PackageManager, and be used wherever a PackageManager is expected through LegacyAdapter; the existing ones are used
+// as a ContextPackageManager through ContextAdapter.
+type ContextPackageManager interface {
+	// IsAvailable checks if the package manager is available on the current system.
+	IsAvailable() bool
+
+	// GetPackageManager returns the name of the package manager.
+	GetPackageManager() string
+
+	// Install installs the specified packages.
+	Install(ctx context.Context, pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// Delete removes the specified packages.
+	Delete(ctx context.Context, pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// Find searches for packages using the specified keywords.
+	Find(ctx context.Context, keywords []string, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// ListInstalled lists all installed packages.
+	ListInstalled(ctx context.Context, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// ListUpgradable lists all upgradable packages.
+	ListUpgradable(ctx context.Context, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// UpgradeAll upgrades all packages.
+	UpgradeAll(ctx context.Context, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// Refresh refreshes the package index.
+	Refresh(ctx context.Context, opts *manager.Options) error
+
+	// GetPackageInfo returns information about the specified package.
+	GetPackageInfo(ctx context.Context, pkg string, opts *manager.Options) (manager.PackageInfo, error)
+}
+
+// ContextAdapter returns pm as a ContextPackageManager, running its operations with a copy of their options whose
+// Context is the context of the operation. It returns the ContextPackageManager adapted by LegacyAdapter as is.
+func ContextAdapter(pm PackageManager) ContextPackageManager {
+	if legacy, ok := pm.(*legacyAdapter); ok {
+		return legacy.pm
+	}
+	return &contextAdapter{pm: pm}
+}
+
+// LegacyAdapter returns pm as a PackageManager, running its operations with the Context of their options, or the
+// background context if not set. It returns the PackageManager adapted by ContextAdapter as is. The optional
+// interfaces, such as Upgrader, aren't adapted.
+func LegacyAdapter(pm ContextPackageManager) PackageManager {
+	if adapter, ok := pm.(*contextAdapter); ok {
+		return adapter.pm
+	}
+	return &legacyAdapter{pm: pm}
+}
+
+// contextAdapter is a PackageManager adapted to ContextPackageManager.
+type contextAdapter struct {
+	pm PackageManager
+}
+
+func (a *contextAdapter) IsAvailable() bool         { return a.pm.IsAvailable() }
+func (a *contextAdapter) GetPackageManager() string { return a.pm.GetPackageManager() }
+
+func (a *contextAdapter) Install(ctx context.Context, pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.Install(pkgs, opts.WithContext(ctx))
+}
+
+func (a *contextAdapter) Delete(ctx context.Context, pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.Delete(pkgs, opts.WithContext(ctx))
+}
+
+func (a *contextAdapter) Find(ctx context.Context, keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.Find(keywords, opts.WithContext(ctx))
+}
+
+func (a *contextAdapter) ListInstalled(ctx context.Context, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.ListInstalled(opts.WithContext(ctx))
+}
+
+func (a *contextAdapter) ListUpgradable(ctx context.Context, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.ListUpgradable(opts.WithContext(ctx))
+}
+
+func (a *contextAdapter) UpgradeAll(ctx context.Context, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.UpgradeAll(opts.WithContext(ctx))
+}
+
+func (a *contextAdapter) Refresh(ctx context.Context, opts *manager.Options) error {
+	return a.pm.Refresh(opts.WithContext(ctx))
+}
+
+func (a *contextAdapter) GetPackageInfo(ctx context.Context, pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	return a.pm.GetPackageInfo(pkg, opts.WithContext(ctx))
+}
+
+// legacyAdapter is a ContextPackageManager adapted to PackageManager.
+type legacyAdapter struct {
+	pm ContextPackageManager
+}
+
+func (a *legacyAdapter) IsAvailable() bool         { return a.pm.IsAvailable() }
+func (a *legacyAdapter) GetPackageManager() string { return a.pm.GetPackageManager() }
+
+func (a *legacyAdapter) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.Install(opts.OperationContext(), pkgs, opts)
+}
+
+func (a *legacyAdapter) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.Delete(opts.OperationContext(), pkgs, opts)
+}
+
+func (a *legacyAdapter) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.Find(opts.OperationContext(), keywords, opts)
+}
+
+func (a *legacyAdapter) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.ListInstalled(opts.OperationContext(), opts)
+}
+
+func (a *legacyAdapter) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.ListUpgradable(opts.OperationContext(), opts)
+}
+
+func (a *legacyAdapter) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pm.UpgradeAll(opts.OperationContext(), opts)
+}
+
+func (a *legacyAdapter) Refresh(opts *manager.Options) error {
+	return a.pm.Refresh(opts.OperationContext(), opts)
+}
+
+func (a *legacyAdapter) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	return a.pm.GetPackageInfo(opts.OperationContext(), pkg, opts)
+}
@@ -0,0 +1,74 @@
+package syspkg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+)
+
+// contextPackageManager is a context-based package manager, recording the contexts it is run with.
+type contextPackageManager struct {
+	syspkg.ContextPackageManager
+	contexts []context.Context
+}
+
+func (pm *contextPackageManager) GetPackageManager() string { return "context" }
+
+func (pm *contextPackageManager) Find(ctx context.Context, keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	pm.contexts = append(pm.contexts, ctx)
+	return []manager.PackageInfo{{Name: keywords[0], PackageManager: "context"}}, nil
+}
+
+func TestContextAdapter(t *testing.T) {
+	pm := &clientPackageManager{name: "apt", packages: []string{"vim"}}
+	adapted := syspkg.ContextAdapter(pm)
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "test")
+	opts := &manager.Options{Verbose: true}
+	if _, err := adapted.Find(ctx, []string{"vim"}, opts); err != nil {
+		t.Fatalf("Find() error = %+v", err)
+	}
+	if len(pm.opts) != 1 || pm.opts[0].Context != ctx || !pm.opts[0].Verbose {
+		t.Errorf("Find() ran apt with %+v, want the options with the context", pm.opts)
+	}
+	if opts.Context != nil {
+		t.Errorf("Find() set the context of the options of the caller")
+	}
+
+	if _, err := adapted.Find(ctx, []string{"vim"}, nil); err != nil || pm.opts[1].Context != ctx {
+		t.Errorf("Find() with nil options ran apt with %+v, error = %+v, want the default options with the context", pm.opts[1], err)
+	}
+
+	if legacy := syspkg.LegacyAdapter(adapted); legacy != pm {
+		t.Errorf("LegacyAdapter(ContextAdapter(pm)) = %+v, want pm", legacy)
+	}
+}
+
+func TestLegacyAdapter(t *testing.T) {
+	pm := &contextPackageManager{}
+	adapted := syspkg.LegacyAdapter(pm)
+	if adapted.GetPackageManager() != "context" {
+		t.Errorf("GetPackageManager() = %s, want context", adapted.GetPackageManager())
+	}
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "test")
+	packages, err := adapted.Find([]string{"vim"}, &manager.Options{Context: ctx})
+	if err != nil || len(packages) != 1 || packages[0].Name != "vim" {
+		t.Errorf("Find() = %+v, %+v, want vim", packages, err)
+	}
+	if _, err := adapted.Find([]string{"vim"}, nil); err != nil {
+		t.Errorf("Find() with nil options error = %+v", err)
+	}
+	expected := []context.Context{ctx, context.Background()}
+	if len(pm.contexts) != 2 || pm.contexts[0] != expected[0] || pm.contexts[1] != expected[1] {
+		t.Errorf("Find() ran with the contexts %+v, want %+v", pm.contexts, expected)
+	}
+
+	if contextBased := syspkg.ContextAdapter(adapted); contextBased != pm {
+		t.Errorf("ContextAdapter(LegacyAdapter(pm)) = %+v, want pm", contextBased)
+	}
+}
@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ErrNoDaemon is returned by Dial when no daemon is listening.
+var ErrNoDaemon = errors.New("daemon: no daemon is running")
+
+// ErrRequestFailed wraps the failures to talk to the daemon, as opposed to the errors of the package managers.
+var ErrRequestFailed = errors.New("daemon: request failed")
+
+// Client queries a daemon over its Unix socket.
+type Client struct {
+	path string
+	http *http.Client
+
+	// PackageManagers are the names of the package managers served by the daemon.
+	PackageManagers []string
+}
+
+// Dial connects to the daemon listening on the first of paths where one answers (SocketPaths if none are given),
+// and returns ErrNoDaemon if none does.
+func Dial(paths ...string) (*Client, error) {
+	if len(paths) == 0 {
+		paths = SocketPaths()
+	}
+	for _, path := range paths {
+		path := path
+		c := &Client{
+			path: path,
+			http: &http.Client{
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						var d net.Dialer
+						return d.DialContext(ctx, "unix", path)
+					},
+				},
+			},
+		}
+
+		// the ping must be fast, as the CLI falls back to running the package managers itself
+		c.http.Timeout = 200 * time.Millisecond
+		var pong struct {
+			PackageManagers []string `json:"package_managers"`
+		}
+		if err := c.do(http.MethodGet, "/v1/ping", nil, &pong); err != nil {
+			continue
+		}
+		c.http.Timeout = 0
+		c.PackageManagers = pong.PackageManagers
+		return c, nil
+	}
+	return nil, ErrNoDaemon
+}
+
+// Path returns the path of the socket of the daemon.
+func (c *Client) Path() string {
+	return c.path
+}
+
+// Serves reports whether the daemon serves the named package manager.
+func (c *Client) Serves(pm string) bool {
+	for _, name := range c.PackageManagers {
+		if name == pm {
+			return true
+		}
+	}
+	return false
+}
+
+// Query runs a query on the daemon. Errors of the package manager are returned as errors, with
+// manager.ErrOperationNotSupported for unsupported queries.
+func (c *Client) Query(req Request) ([]manager.PackageInfo, error) {
+	var resp Response
+	if err := c.do(http.MethodPost, "/v1/query", req, &resp); err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.Unsupported:
+		return resp.Packages, manager.ErrOperationNotSupported
+	case resp.Error != "":
+		return resp.Packages, errors.New(resp.Error)
+	}
+	return resp.Packages, nil
+}
+
+// Invalidate makes the daemon drop the results of the named package managers, after their packages changed.
+func (c *Client) Invalidate(pms ...string) error {
+	return c.do(http.MethodPost, "/v1/invalidate", map[string][]string{"package_managers": pms}, &struct{}{})
+}
+
+// do sends a request to the daemon, with body encoded as JSON if not nil, and decodes the response into v.
+// Its errors wrap ErrRequestFailed.
+func (c *Client) do(method string, path string, body any, v any) error {
+	if err := c.roundTrip(method, path, body, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrRequestFailed, err)
+	}
+	return nil
+}
+
+// roundTrip sends a request to the daemon and decodes its response, see do.
+func (c *Client) roundTrip(method string, path string, body any, v any) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, "http://syspkg"+path, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure Response
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		return fmt.Errorf("%s: %s", resp.Status, failure.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,486 @@
+// Package daemon provides a long-running syspkg service, which keeps the results of package manager queries warm
+// and serves them over a Unix socket, and the client used by the syspkg CLI to query it.
+//
+// The API is HTTP with JSON bodies over the Unix socket:
+//
+//	GET  /v1/ping                                                        -> {"package_managers":["apt","snap"]}
+//	POST /v1/query       {"op":"find","package_manager":"apt","args":["vim"]} -> {"packages":[...],"error":"..."}
+//	POST /v1/invalidate  {"package_managers":["apt"]}                    -> {}
+//
+// Only queries are served: the daemon never changes the packages of the system, so its socket can be shared by the users
+// of a group (Server.SocketGroup).
+//
+// This package is part of the syspkg library.
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+)
+
+// DefaultTTL is how long the daemon keeps the results of queries, unless they are refreshed on schedule.
+const DefaultTTL = 15 * time.Minute
+
+// BackgroundNice is the niceness of the commands run by the background refreshes.
+const BackgroundNice = 10
+
+// DefaultMaxEntries is how many query results the daemon keeps at most, unless Server.MaxEntries is set.
+const DefaultMaxEntries = 1024
+
+// Op is a query served by the daemon.
+type Op string
+
+// Queries served by the daemon.
+const (
+	// OpFind searches packages, with the keywords as arguments.
+	OpFind Op = "find"
+
+	// OpInstalled lists the installed packages.
+	OpInstalled Op = "installed"
+
+	// OpUpgradable lists the upgradable packages.
+	OpUpgradable Op = "upgradable"
+
+	// OpInfo returns information about the package given as argument.
+	OpInfo Op = "info"
+)
+
+// ErrUnknownOp is returned for queries the daemon doesn't serve.
+var ErrUnknownOp = errors.New("daemon: unknown query")
+
+// ErrInvalidArgument is returned for queries with an argument starting with "-", which the package managers would
+// take as an option.
+var ErrInvalidArgument = errors.New("daemon: arguments must not start with -")
+
+// Request is a query sent to the daemon.
+type Request struct {
+	// Op is the query.
+	Op Op `json:"op"`
+
+	// PackageManager is the name of the package manager to query.
+	PackageManager string `json:"package_manager"`
+
+	// Args are the arguments of the query, such as the keywords of a search.
+	Args []string `json:"args,omitempty"`
+
+	// Environment is the environment to operate on, for package managers with several environments (Options.Environment).
+	Environment string `json:"environment,omitempty"`
+
+	// Scope is the installation to query, for package managers with per-user and system-wide installations (Options.Scope).
+	Scope manager.Scope `json:"scope,omitempty"`
+}
+
+// Response is the result of a query.
+type Response struct {
+	// Packages are the packages returned by the package manager.
+	Packages []manager.PackageInfo `json:"packages"`
+
+	// Error is the error returned by the package manager, if any.
+	Error string `json:"error,omitempty"`
+
+	// Unsupported is set when the package manager doesn't support the query.
+	Unsupported bool `json:"unsupported,omitempty"`
+}
+
+// entry is a query result kept by the daemon.
+type entry struct {
+	response Response
+	time     time.Time
+}
+
+// Server is the daemon, serving the queries of package managers from its warm results.
+type Server struct {
+	// PackageManagers are the package managers served, by name.
+	PackageManagers map[string]syspkg.PackageManager
+
+	// Options are the options of the queries run by the daemon; nil for the defaults.
+	Options *manager.Options
+
+	// TTL is how long the results of queries are kept; DefaultTTL if zero.
+	TTL time.Duration
+
+	// RefreshInterval is how often the installed and upgradable packages are listed again in the background,
+	// after refreshing the package indexes if RefreshIndexes is set. Zero disables the background refreshes.
+	RefreshInterval time.Duration
+
+	// RefreshIndexes makes the background refreshes refresh the package indexes first, which needs root privileges
+	// with system package managers.
+	RefreshIndexes bool
+
+	// Concurrency is the maximum number of queries run at the same time while warming the results; no limit if zero.
+	Concurrency int
+
+	// MaxEntries is how many query results are kept at most, the oldest being dropped first; DefaultMaxEntries if zero.
+	MaxEntries int
+
+	// SocketGroup is the group owning the socket of ListenAndServe, whose users can query the daemon. If empty, only
+	// the user running the daemon and its primary group can.
+	SocketGroup string
+
+	mu      sync.Mutex
+	entries map[string]entry
+	http    *http.Server
+	done    chan struct{}
+}
+
+// ListenAndServe listens on the Unix socket at path, replacing a stale socket left by a previous daemon,
+// and serves queries until Close is called.
+func (s *Server) ListenAndServe(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return errors.New("daemon: another daemon is listening on " + path)
+	}
+	_ = os.Remove(path)
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	// queries don't change the system, but they run as the user of the daemon, so only the users of its group can
+	// use the daemon
+	if err := chownGroup(path, s.SocketGroup); err != nil {
+		l.Close()
+		return err
+	}
+	if err := os.Chmod(path, 0o660); err != nil {
+		l.Close()
+		return err
+	}
+	defer os.Remove(path)
+	return s.Serve(l)
+}
+
+// Serve serves queries on l until Close is called, warming the results first, and refreshing them on schedule.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.entries == nil {
+		s.entries = make(map[string]entry)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/ping", s.handlePing)
+	mux.HandleFunc("/v1/query", s.handleQuery)
+	mux.HandleFunc("/v1/invalidate", s.handleInvalidate)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	s.http = srv
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	go s.refreshLoop()
+	err := srv.Serve(l)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close stops serving queries and the background refreshes.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.http == nil {
+		return nil
+	}
+	close(s.done)
+	err := s.http.Close()
+	s.http = nil
+	return err
+}
+
+// Warm lists the installed and upgradable packages of all package managers, so that these queries are served at once.
+// Its commands run at a low priority, like the background refreshes.
+func (s *Server) Warm() {
+	limit := s.Concurrency
+	if limit <= 0 {
+		limit = 2 * len(s.PackageManagers)
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for name := range s.PackageManagers {
+		for _, op := range []Op{OpInstalled, OpUpgradable} {
+			wg.Add(1)
+			go func(req Request) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				s.store(req, s.run(req, true))
+			}(Request{Op: op, PackageManager: name})
+		}
+	}
+	wg.Wait()
+}
+
+// refreshLoop warms the results, and refreshes them every RefreshInterval until the server is closed.
+func (s *Server) refreshLoop() {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+
+	s.Warm()
+	if s.RefreshInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		if s.RefreshIndexes {
+			for name, pm := range s.PackageManagers {
+				if err := pm.Refresh(s.options(Request{}, true)); err != nil {
+					s.Options.Log().Error("Failed to refresh the package index", "package_manager", name, "error", err)
+				}
+			}
+		}
+		// results older than the refresh are dropped, as the package indexes may have changed
+		s.invalidate(nil)
+		s.Warm()
+	}
+}
+
+// Query returns the result of a query, from the warm results if recent enough, otherwise by running it.
+func (s *Server) Query(req Request) Response {
+	if resp, ok := s.lookup(req); ok {
+		return resp
+	}
+	resp := s.run(req, false)
+	s.store(req, resp)
+	return resp
+}
+
+// run runs a query with its package manager, at a low priority for the background refreshes.
+func (s *Server) run(req Request, background bool) Response {
+	pm, ok := s.PackageManagers[req.PackageManager]
+	if !ok {
+		return Response{Packages: []manager.PackageInfo{}, Error: "daemon: unknown package manager " + req.PackageManager}
+	}
+	opts := s.options(req, background)
+	for _, arg := range req.Args {
+		if strings.HasPrefix(arg, "-") {
+			return Response{Packages: []manager.PackageInfo{}, Error: ErrInvalidArgument.Error()}
+		}
+	}
+
+	var packages []manager.PackageInfo
+	var err error
+	switch req.Op {
+	case OpFind:
+		packages, err = pm.Find(req.Args, opts)
+	case OpInstalled:
+		packages, err = pm.ListInstalled(opts)
+	case OpUpgradable:
+		packages, err = pm.ListUpgradable(opts)
+	case OpInfo:
+		if len(req.Args) != 1 {
+			err = errors.New("daemon: info expects one package name")
+			break
+		}
+		var pkg manager.PackageInfo
+		if pkg, err = pm.GetPackageInfo(req.Args[0], opts); err == nil {
+			packages = []manager.PackageInfo{pkg}
+		}
+	default:
+		err = ErrUnknownOp
+	}
+
+	resp := Response{Packages: packages}
+	if resp.Packages == nil {
+		resp.Packages = []manager.PackageInfo{}
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		resp.Unsupported = errors.Is(err, manager.ErrOperationNotSupported)
+	}
+	return resp
+}
+
+// options returns the options of query req, run in its environment and scope, and of the background refreshes, whose
+// commands run at BackgroundNice and in the idle I/O scheduling class, so that they don't slow down the system.
+func (s *Server) options(req Request, background bool) *manager.Options {
+	var opts manager.Options
+	if s.Options != nil {
+		opts = *s.Options
+	}
+	if req.Environment != "" {
+		opts.Environment = req.Environment
+	}
+	if req.Scope != "" {
+		opts.Scope = req.Scope
+	}
+	if background {
+		opts.Nice = max(opts.Nice, BackgroundNice)
+		opts.IdleIO = true
+	}
+	return &opts
+}
+
+// key returns the key of the result of a query.
+func key(req Request) string {
+	return strings.Join(append([]string{req.PackageManager, string(req.Op), req.Environment, string(req.Scope)}, req.Args...), "\x00")
+}
+
+// lookup returns the kept result of a query, if it is recent enough.
+func (s *Server) lookup(req Request) (Response, bool) {
+	ttl := s.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key(req)]
+	if !ok || time.Since(e.time) >= ttl {
+		return Response{}, false
+	}
+	return e.response, true
+}
+
+// store keeps the result of a query, unless it failed for another reason than being unsupported, dropping the oldest
+// result if MaxEntries are already kept.
+func (s *Server) store(req Request, resp Response) {
+	if resp.Error != "" && !resp.Unsupported {
+		return
+	}
+	maxEntries := s.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]entry)
+	}
+	k := key(req)
+	if _, ok := s.entries[k]; !ok && len(s.entries) >= maxEntries {
+		var oldest string
+		for ek, e := range s.entries {
+			if oldest == "" || e.time.Before(s.entries[oldest].time) {
+				oldest = ek
+			}
+		}
+		delete(s.entries, oldest)
+	}
+	s.entries[k] = entry{response: resp, time: time.Now()}
+}
+
+// invalidate drops the results of the given package managers, or of all package managers if pms is nil.
+func (s *Server) invalidate(pms []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pms == nil {
+		s.entries = make(map[string]entry)
+		return
+	}
+	for k := range s.entries {
+		for _, pm := range pms {
+			if strings.HasPrefix(k, pm+"\x00") {
+				delete(s.entries, k)
+			}
+		}
+	}
+}
+
+// handlePing answers the pings of clients, with the names of the package managers served.
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(s.PackageManagers))
+	for name := range s.PackageManagers {
+		names = append(names, name)
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"package_managers": names})
+}
+
+// handleQuery serves a query.
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "daemon: expected POST"})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "daemon: invalid request: " + err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Query(req))
+}
+
+// handleInvalidate drops the results of the package managers whose packages changed, and warms them again in the background.
+func (s *Server) handleInvalidate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PackageManagers []string `json:"package_managers"`
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "daemon: expected POST"})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "daemon: invalid request: " + err.Error()})
+		return
+	}
+	s.invalidate(req.PackageManagers)
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// chownGroup makes group the group owning the file at path, if set.
+func chownGroup(path, group string) error {
+	if group == "" {
+		return nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return err
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return err
+	}
+	return os.Chown(path, -1, gid)
+}
+
+// writeJSON writes v as the JSON body of the response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// DefaultSocketPath returns the path of the socket of the daemon: /run/syspkg.sock for root,
+// and syspkg.sock in $XDG_RUNTIME_DIR for other users, if set.
+func DefaultSocketPath() string {
+	if runtime.GOOS != "windows" && os.Geteuid() == 0 {
+		return "/run/syspkg.sock"
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "syspkg.sock")
+	}
+	return filepath.Join(os.TempDir(), "syspkg-"+strconv.Itoa(os.Getuid())+".sock")
+}
+
+// SocketPaths returns the paths where clients look for a daemon, in order: the socket of the user's own daemon,
+// then the one of the system daemon.
+func SocketPaths() []string {
+	paths := []string{DefaultSocketPath()}
+	if paths[0] != "/run/syspkg.sock" {
+		paths = append(paths, "/run/syspkg.sock")
+	}
+	return paths
+}
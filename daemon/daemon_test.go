@@ -0,0 +1,123 @@
+package daemon_test
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/daemon"
+	"github.com/bluet/syspkg/manager"
+)
+
+// fakePackageManager is a package manager counting the searches it runs, and supporting no other query.
+type fakePackageManager struct {
+	mu    sync.Mutex
+	finds int
+}
+
+func (f *fakePackageManager) IsAvailable() bool         { return true }
+func (f *fakePackageManager) GetPackageManager() string { return "fake" }
+func (f *fakePackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+func (f *fakePackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+func (f *fakePackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.finds++
+	return []manager.PackageInfo{{Name: keywords[0], NewVersion: "1.0", Status: manager.PackageStatusAvailable, PackageManager: "fake"}}, nil
+}
+func (f *fakePackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+func (f *fakePackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+func (f *fakePackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+func (f *fakePackageManager) Refresh(opts *manager.Options) error { return nil }
+func (f *fakePackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	return manager.PackageInfo{}, manager.ErrOperationNotSupported
+}
+
+func TestServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "syspkg.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm := &fakePackageManager{}
+	server := &daemon.Server{PackageManagers: map[string]syspkg.PackageManager{"fake": pm}}
+	go func() { _ = server.Serve(l) }()
+	defer server.Close()
+
+	client, err := daemon.Dial(path)
+	if err != nil {
+		t.Fatalf("Dial() error = %+v", err)
+	}
+	if !client.Serves("fake") {
+		t.Errorf("Serves() = false, want true")
+	}
+
+	want := []manager.PackageInfo{{Name: "vim", NewVersion: "1.0", Status: manager.PackageStatusAvailable, PackageManager: "fake"}}
+	for i := 0; i < 2; i++ {
+		got, err := client.Query(daemon.Request{Op: daemon.OpFind, PackageManager: "fake", Args: []string{"vim"}})
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Query() = %+v, %+v, want %+v", got, err, want)
+		}
+	}
+	if pm.finds != 1 {
+		t.Errorf("the package manager ran %d searches, want 1", pm.finds)
+	}
+
+	if err := client.Invalidate("fake"); err != nil {
+		t.Fatalf("Invalidate() error = %+v", err)
+	}
+	if _, err := client.Query(daemon.Request{Op: daemon.OpFind, PackageManager: "fake", Args: []string{"vim"}}); err != nil || pm.finds != 2 {
+		t.Errorf("the package manager ran %d searches after Invalidate(), want 2", pm.finds)
+	}
+	// the results of another scope are kept apart
+	if _, err := client.Query(daemon.Request{Op: daemon.OpFind, PackageManager: "fake", Args: []string{"vim"}, Scope: manager.ScopeUser}); err != nil || pm.finds != 3 {
+		t.Errorf("the package manager ran %d searches in the user scope, want 3", pm.finds)
+	}
+
+	if _, err := client.Query(daemon.Request{Op: daemon.OpInstalled, PackageManager: "fake"}); !errors.Is(err, manager.ErrOperationNotSupported) {
+		t.Errorf("Query() error = %+v, want %+v", err, manager.ErrOperationNotSupported)
+	}
+}
+
+func TestServerQueries(t *testing.T) {
+	pm := &fakePackageManager{}
+	server := &daemon.Server{PackageManagers: map[string]syspkg.PackageManager{"fake": pm}, MaxEntries: 2}
+
+	// arguments starting with "-" would be options of the package manager, run as the user of the daemon
+	if resp := server.Query(daemon.Request{Op: daemon.OpFind, PackageManager: "fake", Args: []string{"--config=/tmp/x"}}); resp.Error != daemon.ErrInvalidArgument.Error() || pm.finds != 0 {
+		t.Errorf("Query() = %+v, want the error %+v without searching", resp, daemon.ErrInvalidArgument)
+	}
+
+	// the oldest result is dropped once MaxEntries are kept
+	for _, keyword := range []string{"vim", "nano", "emacs"} {
+		server.Query(daemon.Request{Op: daemon.OpFind, PackageManager: "fake", Args: []string{keyword}})
+	}
+	server.Query(daemon.Request{Op: daemon.OpFind, PackageManager: "fake", Args: []string{"emacs"}})
+	if pm.finds != 3 {
+		t.Errorf("the package manager ran %d searches, want 3", pm.finds)
+	}
+	server.Query(daemon.Request{Op: daemon.OpFind, PackageManager: "fake", Args: []string{"vim"}})
+	if pm.finds != 4 {
+		t.Errorf("the package manager ran %d searches after the result was dropped, want 4", pm.finds)
+	}
+}
+
+func TestDialNoDaemon(t *testing.T) {
+	if _, err := daemon.Dial(filepath.Join(t.TempDir(), "missing.sock")); !errors.Is(err, daemon.ErrNoDaemon) {
+		t.Errorf("Dial() error = %+v, want %+v", err, daemon.ErrNoDaemon)
+	}
+}
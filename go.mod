@@ -2,10 +2,18 @@ module github.com/bluet/syspkg
 
 go 1.21
 
-require github.com/urfave/cli/v2 v2.27.5 // direct
+require (
+	github.com/urfave/cli/v2 v2.27.5
+	google.golang.org/grpc v1.66.3
+	google.golang.org/protobuf v1.34.2
+)
 
 require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.5 // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/xrash/smetrics v0.0.0-20240521201337-686a1a2994c1 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117 // indirect
 )
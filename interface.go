@@ -35,22 +35,158 @@ type PackageManager interface {
 	GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error)
 }
 
+// Upgrader is implemented by package managers that can upgrade specific packages, rather than only all of them.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type Upgrader interface {
+	// Upgrade upgrades the specified packages, or all packages if none are specified.
+	Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error)
+}
+
+// Downgrader is implemented by package managers that can downgrade installed packages to an older version.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type Downgrader interface {
+	// Downgrade installs the specified packages, given as "name=version", at that version, even if it is older than the installed one.
+	Downgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error)
+}
+
+// SecurityUpdateLister is implemented by package managers that can tell security updates apart from other updates.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type SecurityUpdateLister interface {
+	// ListSecurityUpdates lists the upgradable packages (or patches) fixing security issues. When the package manager
+	// provides them, the fixed CVE IDs are set in AdditionalData["cve"] (comma-separated) and the severity in AdditionalData["severity"].
+	ListSecurityUpdates(opts *manager.Options) ([]manager.PackageInfo, error)
+}
+
+// Holder is implemented by package managers that can hold packages at their installed version, so that they are not upgraded.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type Holder interface {
+	// Hold holds the specified packages at their installed version.
+	Hold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// Unhold releases the hold on the specified packages.
+	Unhold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// ListHeld lists the held packages.
+	ListHeld(opts *manager.Options) ([]manager.PackageInfo, error)
+}
+
+// DependencyQuerier is implemented by package managers that can query the dependencies of a package.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type DependencyQuerier interface {
+	// GetDependencies returns the packages the specified package directly depends on.
+	GetDependencies(pkg string, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// GetReverseDependencies returns the installed packages that directly depend on the specified package.
+	GetReverseDependencies(pkg string, opts *manager.Options) ([]manager.PackageInfo, error)
+}
+
+// ExplicitLister is implemented by package managers that tell the packages installed explicitly from the ones installed
+// as dependencies of other packages.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type ExplicitLister interface {
+	// ListExplicit returns the names of the packages installed explicitly, rather than as dependencies.
+	ListExplicit(opts *manager.Options) ([]string, error)
+}
+
+// FileOwnerQuerier is implemented by package managers that can find the installed package owning a file.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type FileOwnerQuerier interface {
+	// Owns returns the installed packages owning the file or directory at the specified absolute path,
+	// or no packages if the path is not owned by any package of this package manager.
+	Owns(path string, opts *manager.Options) ([]manager.PackageInfo, error)
+}
+
+// FileLister is implemented by package managers that can list the files installed by a package.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type FileLister interface {
+	// ListFiles returns the absolute paths of the files and directories installed by the specified package.
+	ListFiles(pkg string, opts *manager.Options) ([]string, error)
+}
+
+// Downloader is implemented by package managers that can download packages without installing them,
+// e.g. to install them later on an air-gapped system. With Options.DownloadOnly, Install downloads the packages too.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type Downloader interface {
+	// Download downloads the specified packages, and the dependencies they need, into opts.DownloadDir or the cache of
+	// the package manager, and returns them with the path of the downloaded file in AdditionalData["path"].
+	Download(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error)
+}
+
+// LocalInstaller is implemented by package managers that can install package files, such as .deb or .rpm files,
+// rather than packages from their repositories, e.g. on air-gapped systems. Use manager.LocalPackageManager
+// to find the package manager installing a file.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type LocalInstaller interface {
+	// InstallLocal installs the package files at paths, and the dependencies they need from the configured repositories, if any.
+	InstallLocal(paths []string, opts *manager.Options) ([]manager.PackageInfo, error)
+}
+
+// PackageNameLister is implemented by package managers that can quickly list the names of all the packages
+// available in their repositories, e.g. to complete package names in shells.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type PackageNameLister interface {
+	// ListPackageNames returns the names of the packages available in the repositories, in no particular order.
+	ListPackageNames(opts *manager.Options) ([]string, error)
+}
+
+// RebootChecker is implemented by package managers that can tell whether the system needs a reboot to use the
+// packages they upgraded, such as the kernel.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type RebootChecker interface {
+	// NeedsReboot returns whether a reboot is required, why, and the packages requiring it when known.
+	NeedsReboot(opts *manager.Options) (manager.RebootStatus, error)
+}
+
+// Verifier is implemented by package managers that can verify the installed packages against the files they installed,
+// and their signatures.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type Verifier interface {
+	// Verify verifies the provided packages, or all installed packages if none are given, and returns the results of
+	// the packages having problems, such as modified or missing files.
+	Verify(pkgs []string, opts *manager.Options) ([]manager.VerificationResult, error)
+}
+
+// GroupManager is implemented by package managers that can install groups of packages together, such as the tasks
+// of tasksel for apt, the patterns of zypper or the groups of pacman. The packages installed or removed with a group
+// have its name in their AdditionalData, as "group".
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type GroupManager interface {
+	// ListGroups lists the available groups, and whether they are installed.
+	ListGroups(opts *manager.Options) ([]manager.GroupInfo, error)
+
+	// InstallGroup installs the packages of the specified groups.
+	InstallGroup(groups []string, opts *manager.Options) ([]manager.PackageInfo, error)
+
+	// RemoveGroup removes the packages of the specified groups.
+	RemoveGroup(groups []string, opts *manager.Options) ([]manager.PackageInfo, error)
+}
+
+// CapabilityReporter is implemented by package managers that describe the operations and options they support.
+// It is optional: use CapabilitiesOf to get the capabilities of any PackageManager.
+type CapabilityReporter interface {
+	// Capabilities returns the operations and options supported by the package manager.
+	Capabilities() manager.Capabilities
+}
+
 // SysPkg is the interface that defines the methods for interacting with the SysPkg library.
 type SysPkg interface {
 	// FindPackageManagers returns a map of available package managers based on the specified IncludeOptions.
 	// If the AllAvailable option is set to true, all available package managers will be returned.
 	// Otherwise, only the specified package managers will be returned.
 	// If no suitable package managers are found, an error is returned.
+	// The availability of the package managers is cached by the instance, for IncludeOptions.AvailabilityTTL.
 	FindPackageManagers(include IncludeOptions) (map[string]PackageManager, error)
 
-	// RefreshPackageManagers refreshes the internal package manager list based on the specified IncludeOptions, and returns the new list.
+	// RefreshPackageManagers refreshes the internal package manager list based on the specified IncludeOptions, probing
+	// the availability of the package managers again rather than using the cached one, and returns the new list.
 	// If the AllAvailable option is set to true, all available package managers will be included.
 	// Otherwise, only the specified package managers will be included.
 	// If no suitable package managers are found, an error is returned.
 	RefreshPackageManagers(include IncludeOptions) (map[string]PackageManager, error)
 
 	// GetPackageManager returns a PackageManager instance based on the specified name, from the list of available package managers specified in the IncludeOptions.
-	// If the name is empty, the first available package manager will be returned.
+	// If the name is empty, the available package manager of highest priority will be returned, as ordered by
+	// IncludeOptions.Priority and manager.DefaultPriority.
 	// If no suitable package manager is found, an error is returned.
 	// Note: only package managers that are specified in the IncludeOptions when creating the SysPkg instance (with New() method) will be returned. If you want to use package managers that are not specified in the IncludeOptions, you should use the FindPackageManagers() method to get a list of all available package managers, or use RefreshPackageManagers() with the IncludeOptions parameter to refresh the package manager list.
 	GetPackageManager(name string) PackageManager
@@ -64,3 +200,48 @@ type SysPkg interface {
 	// Refresh(opts *manager.Options) error
 	// GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error)
 }
+
+// RepositoryLister is implemented by package managers that can list the repositories they install packages from,
+// such as the apt sources or the zypper repositories, even if they can't manage them.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type RepositoryLister interface {
+	// ListRepositories lists the configured repositories, with the origin of their packages, as in
+	// manager.PackageInfo.Origin, when known.
+	ListRepositories(opts *manager.Options) ([]manager.RepositoryInfo, error)
+}
+
+// OriginLister is implemented by package managers whose ListInstalled doesn't set the origins of the installed
+// packages, which they can tell at a higher cost, such as apt.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type OriginLister interface {
+	// ListOrigins returns the origins of the installed packages, by package name, leaving out the packages
+	// installed from no repository, such as the ones installed from files.
+	ListOrigins(opts *manager.Options) (map[string]string, error)
+}
+
+// RepositoryManager is implemented by package managers that can manage the repositories they install packages from,
+// such as the remotes of flatpak.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type RepositoryManager interface {
+	RepositoryLister
+
+	// AddRepository adds the repository at url, named name. Adding a repository that already exists does nothing.
+	AddRepository(name string, url string, opts *manager.Options) ([]manager.RepositoryInfo, error)
+
+	// RemoveRepository removes the repository with the specified name.
+	RemoveRepository(name string, opts *manager.Options) ([]manager.RepositoryInfo, error)
+}
+
+// KeyManager is implemented by package managers that can manage the signing keys used to verify their repositories.
+// It is optional: use a type assertion on a PackageManager to check whether it is supported.
+type KeyManager interface {
+	// ImportKey imports the OpenPGP key file at source. name identifies where the key is stored, when the package manager
+	// uses several keyrings, e.g. the keyring file of an apt repository or the flatpak remote it verifies.
+	ImportKey(name string, source string, opts *manager.Options) ([]manager.KeyInfo, error)
+
+	// ListKeys lists the trusted signing keys.
+	ListKeys(opts *manager.Options) ([]manager.KeyInfo, error)
+
+	// RemoveKey removes the trusted signing key with the specified ID.
+	RemoveKey(id string, opts *manager.Options) ([]manager.KeyInfo, error)
+}
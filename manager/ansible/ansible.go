@@ -0,0 +1,246 @@
+// Package ansible implements the protocol of Ansible modules, so that syspkg can back a package module working
+// across distributions: it reads the arguments of the module from the JSON file Ansible passes to the modules
+// declaring WANT_JSON, such as
+//
+//	{"name": ["vim", "curl=7.81.0-1"], "state": "present", "use": "auto", "_ansible_check_mode": false}
+//
+// and returns the results Ansible expects, such as
+//
+//	{"changed": true, "failed": false, "msg": "1 change applied with apt", "results": ["apt: install vim (not installed)"]}
+//
+// The arguments follow the ones of the package module of Ansible: name, a package or a list of packages (a string
+// being split on commas), with an optional exact version as name=version; state, present (or installed), absent
+// (or removed) or latest; use, the package manager, or auto for the preferred one; and update_cache, to refresh the
+// package indexes first. In check mode, the changes are planned but not applied.
+//
+// This package is part of the syspkg library.
+package ansible
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/manifest"
+)
+
+// UseAuto is the value of the use argument selecting the preferred package manager.
+const UseAuto = "auto"
+
+// ErrInvalidArgs is returned, wrapped with the details, for arguments the module doesn't accept.
+var ErrInvalidArgs = errors.New("ansible: invalid module arguments")
+
+// states are the states of the arguments, with their aliases, and the states of manifests they mean.
+var states = map[string]manifest.State{
+	"present":   manifest.StatePresent,
+	"installed": manifest.StatePresent,
+	"absent":    manifest.StateAbsent,
+	"removed":   manifest.StateAbsent,
+	"latest":    manifest.StateLatest,
+}
+
+// Args are the arguments of the module.
+type Args struct {
+	// Names are the packages, with an optional exact version as name=version.
+	Names []string
+
+	// State is the desired state of the packages.
+	State manifest.State
+
+	// Use is the name of the package manager of the packages, or UseAuto.
+	Use string
+
+	// UpdateCache is set to refresh the package indexes before changing the packages.
+	UpdateCache bool
+
+	// CheckMode is set when Ansible runs in check mode, where the changes are only planned.
+	CheckMode bool
+}
+
+// LoadArgs reads the arguments from the JSON file at path.
+func LoadArgs(path string) (*Args, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseArgs(data)
+}
+
+// ParseArgs parses the JSON arguments of the module. The internal arguments of Ansible, prefixed with _ansible_,
+// are ignored, but for _ansible_check_mode.
+func ParseArgs(data []byte) (*Args, error) {
+	var params map[string]any
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArgs, err)
+	}
+
+	args := &Args{State: manifest.StatePresent, Use: UseAuto}
+	var unsupported []string
+	var err error
+	for key, value := range params {
+		switch key {
+		case "name", "pkg", "package":
+			args.Names, err = names(value)
+		case "state":
+			s, ok := value.(string)
+			if args.State, ok = states[s]; !ok {
+				err = fmt.Errorf("state: invalid state %v, expected present, absent or latest", value)
+			}
+		case "use":
+			if s, ok := value.(string); ok && s != "" {
+				args.Use = s
+			} else if value != nil {
+				err = fmt.Errorf("use: expected a package manager name, got %v", value)
+			}
+		case "update_cache":
+			args.UpdateCache, err = boolean(key, value)
+		case "_ansible_check_mode":
+			args.CheckMode, err = boolean(key, value)
+		default:
+			if !strings.HasPrefix(key, "_ansible_") {
+				unsupported = append(unsupported, key)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArgs, err)
+		}
+	}
+
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return nil, fmt.Errorf("%w: unsupported parameters %s, expected name, state, use or update_cache", ErrInvalidArgs, strings.Join(unsupported, ", "))
+	}
+	if len(args.Names) == 0 && !args.UpdateCache {
+		return nil, fmt.Errorf("%w: name: expected a package or a list of packages", ErrInvalidArgs)
+	}
+	return args, nil
+}
+
+// names returns the package names of the name argument: a list of names, or a string of names separated by commas.
+func names(value any) ([]string, error) {
+	var items []any
+	switch v := value.(type) {
+	case string:
+		for _, name := range strings.Split(v, ",") {
+			items = append(items, name)
+		}
+	case []any:
+		items = v
+	case nil:
+	default:
+		return nil, fmt.Errorf("name: expected a package or a list of packages, got %v", value)
+	}
+
+	var names []string
+	for _, item := range items {
+		name, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("name: expected package names, got %v", item)
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// boolean returns a boolean argument, given as a JSON boolean, or as a string in the ways YAML writes them.
+func boolean(key string, value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "yes", "y", "true", "on", "1":
+			return true, nil
+		case "no", "n", "false", "off", "0", "":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%s: expected a boolean, got %v", key, value)
+}
+
+// Manifest returns the manifest of the packages of the arguments, managed by package manager pm.
+func (a *Args) Manifest(pm string) (*manifest.Manifest, error) {
+	m := &manifest.Manifest{Version: manifest.Version, Packages: make(map[string][]manifest.Package)}
+	for _, name := range a.Names {
+		spec, err := manager.ParsePackageSpec(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: name: %v", ErrInvalidArgs, err)
+		}
+		pkg := manifest.Package{Name: spec.Name, State: a.State}
+		if spec.Version != "" {
+			if a.State != manifest.StatePresent {
+				return nil, fmt.Errorf("%w: name: a version can only be requested with the present state, got %q", ErrInvalidArgs, name)
+			}
+			pkg.Constraint = manifest.Constraint{Operator: "=", Version: spec.Version}
+		}
+		m.Packages[pm] = append(m.Packages[pm], pkg)
+	}
+	return m, nil
+}
+
+// Result is the result of the module, as Ansible expects it.
+type Result struct {
+	// Changed is set when the packages were changed, or would be in check mode.
+	Changed bool `json:"changed"`
+
+	// Failed is set when the module failed.
+	Failed bool `json:"failed"`
+
+	// Msg describes the result, or the error the module failed with.
+	Msg string `json:"msg"`
+
+	// Results describe the changes, one per line.
+	Results []string `json:"results"`
+
+	// Changes are the changes needed to reach the desired state, applied unless in check mode.
+	Changes []manifest.Step `json:"changes"`
+}
+
+// NewResult returns the result of the module having planned steps with package manager pm, and applied them unless
+// in check mode, failing with err if not nil.
+func NewResult(pm string, steps []manifest.Step, checkMode bool, err error) Result {
+	result := Result{Changed: len(steps) > 0, Results: []string{}, Changes: steps}
+	if result.Changes == nil {
+		result.Changes = []manifest.Step{}
+	}
+	for _, step := range steps {
+		line := fmt.Sprintf("%s: %s %s", step.PackageManager, step.Action, step.Package)
+		if step.Version != "" {
+			line += " " + step.Version
+		}
+		result.Results = append(result.Results, line+" ("+step.Reason+")")
+	}
+
+	switch {
+	case err != nil:
+		result.Failed, result.Msg = true, err.Error()
+	case len(steps) == 0:
+		result.Msg = "All packages are in the desired state"
+	case checkMode:
+		result.Msg = fmt.Sprintf("%s would be applied with %s", changes(len(steps)), pm)
+	default:
+		result.Msg = fmt.Sprintf("%s applied with %s", changes(len(steps)), pm)
+	}
+	return result
+}
+
+// Fail returns the result of the module failing with err before planning any change.
+func Fail(err error) Result {
+	return Result{Failed: true, Msg: err.Error(), Results: []string{}, Changes: []manifest.Step{}}
+}
+
+// changes returns n changes, in the singular or the plural.
+func changes(n int) string {
+	if n == 1 {
+		return "1 change"
+	}
+	return fmt.Sprintf("%d changes", n)
+}
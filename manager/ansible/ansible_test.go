@@ -0,0 +1,100 @@
+package ansible_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager/ansible"
+	"github.com/bluet/syspkg/manager/manifest"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ansible.Args
+	}{
+		{
+			`{"name": ["vim", "curl=7.81.0-1"], "state": "installed", "_ansible_check_mode": true, "_ansible_verbosity": 2}`,
+			ansible.Args{Names: []string{"vim", "curl=7.81.0-1"}, State: manifest.StatePresent, Use: ansible.UseAuto, CheckMode: true},
+		},
+		{
+			`{"pkg": "vim, nano", "state": "latest", "use": "apt", "update_cache": "yes"}`,
+			ansible.Args{Names: []string{"vim", "nano"}, State: manifest.StateLatest, Use: "apt", UpdateCache: true},
+		},
+		{
+			`{"update_cache": true}`,
+			ansible.Args{State: manifest.StatePresent, Use: ansible.UseAuto, UpdateCache: true},
+		},
+	}
+	for _, tt := range tests {
+		actual, err := ansible.ParseArgs([]byte(tt.input))
+		if err != nil {
+			t.Errorf("ParseArgs(%s) error = %+v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(*actual, tt.expected) {
+			t.Errorf("ParseArgs(%s) = %+v, want %+v", tt.input, *actual, tt.expected)
+		}
+	}
+
+	for _, input := range []string{
+		`[]`,
+		`{}`,
+		`{"name": "vim", "state": "purged"}`,
+		`{"name": "vim", "autoremove": true}`,
+		`{"name": "vim", "update_cache": "maybe"}`,
+		`{"name": [1]}`,
+	} {
+		if _, err := ansible.ParseArgs([]byte(input)); !errors.Is(err, ansible.ErrInvalidArgs) {
+			t.Errorf("ParseArgs(%s) error = %+v, want %+v", input, err, ansible.ErrInvalidArgs)
+		}
+	}
+}
+
+func TestManifest(t *testing.T) {
+	args := &ansible.Args{Names: []string{"vim", "curl=7.81.0-1"}, State: manifest.StatePresent}
+	actual, err := args.Manifest("apt")
+	if err != nil {
+		t.Fatalf("Manifest() error = %+v", err)
+	}
+	expected := &manifest.Manifest{Version: manifest.Version, Packages: map[string][]manifest.Package{
+		"apt": {
+			{Name: "vim", State: manifest.StatePresent},
+			{Name: "curl", State: manifest.StatePresent, Constraint: manifest.Constraint{Operator: "=", Version: "7.81.0-1"}},
+		},
+	}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Manifest() = %+v, want %+v", actual, expected)
+	}
+
+	args.State = manifest.StateAbsent
+	if _, err := args.Manifest("apt"); !errors.Is(err, ansible.ErrInvalidArgs) {
+		t.Errorf("Manifest() error = %+v, want %+v", err, ansible.ErrInvalidArgs)
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	steps := []manifest.Step{{PackageManager: "apt", Action: manifest.ActionInstall, Package: "vim", Reason: "not installed"}}
+
+	actual := ansible.NewResult("apt", steps, true, nil)
+	expected := ansible.Result{
+		Changed: true,
+		Msg:     "1 change would be applied with apt",
+		Results: []string{"apt: install vim (not installed)"},
+		Changes: steps,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("NewResult() = %+v, want %+v", actual, expected)
+	}
+
+	actual = ansible.NewResult("apt", nil, false, nil)
+	if actual.Changed || actual.Failed || actual.Msg != "All packages are in the desired state" {
+		t.Errorf("NewResult() = %+v, want no changes", actual)
+	}
+
+	actual = ansible.NewResult("apt", steps, false, errors.New("exit status 100"))
+	if !actual.Failed || actual.Msg != "exit status 100" {
+		t.Errorf("NewResult() = %+v, want a failure", actual)
+	}
+}
@@ -0,0 +1,535 @@
+// Package apk provides an implementation of the syspkg manager interface for the apk package manager.
+// It provides a Go (golang) API interface for interacting with the Alpine Package Keeper (apk).
+// This package is a wrapper around the apk command line tool.
+//
+// apk is the package manager of Alpine Linux and postmarketOS.
+// Instead of installing and removing packages one by one, apk maintains the set of packages the user asked for
+// in the "world" file (/etc/apk/world), and each command changes that set and brings the system in line with it:
+// packages that are no longer needed by the world are removed automatically.
+//
+// For more information about apk, visit:
+// - https://wiki.alpinelinux.org/wiki/Alpine_Package_Keeper
+// - https://man.archlinux.org/man/apk.8
+//
+// This package is part of the syspkg library.
+package apk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "apk"
+
+// Constants used for apk commands
+const (
+	ArgsDryRun         string = "--simulate"
+	ArgsPurge          string = "--purge"
+	ArgsInteractive    string = "--interactive"
+	ArgsInstalled      string = "--installed"
+	ArgsUpgradable     string = "--upgradable"
+	ArgsRdepends       string = "--rdepends"
+	ArgsDepends        string = "--depends"
+	ArgsWhoOwns        string = "--who-owns"
+	ArgsContents       string = "--contents"
+	ArgsPackages       string = "--packages"
+	ArgsSystem         string = "--system"
+	ArgsAllowUntrusted string = "--allow-untrusted"
+)
+
+// worldFile is the file listing the packages explicitly requested by the user.
+var worldFile string = "/etc/apk/world"
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for apk.
+var ENV_NonInteractive []string = []string{"LC_ALL=C"}
+
+// PackageManager implements the manager.PackageManager interface for the apk package manager.
+type PackageManager struct{}
+
+// IsAvailable checks if the apk package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(pm)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the apk package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by apk.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:           true,
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		RootDir:          true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+		Hold:             true,
+		Dependencies:     true,
+		FileOwner:        true,
+		FileList:         true,
+		LocalInstall:     true,
+		Verify:           true,
+	}
+}
+
+// Install installs the provided packages using apk, and adds them to the world.
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// apk installs a specific version with name=version
+	pkgs, err := manager.TranslatePackageSpecs(pkgs, manager.PackageSpec.String)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"add"}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseTransactionOutput(string(out), opts), nil
+}
+
+// InstallLocal installs the provided .apk files using apk, and adds them to the world.
+// The files are not required to be signed by a trusted key, as locally built packages usually aren't.
+func (a *PackageManager) InstallLocal(paths []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	files, err := manager.LocalPackageFiles(paths, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"add", ArgsAllowUntrusted}, files...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseTransactionOutput(string(out), opts), nil
+}
+
+// Delete removes the provided packages from the world using apk, along with the dependencies no longer needed.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"del"}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseTransactionOutput(string(out), opts), nil
+}
+
+// Refresh updates the package index of the configured repositories using apk.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	out, err := a.run([]string{"update"}, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Verbose && out != nil {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
+
+// Find searches for packages whose name contains any of the provided keywords using apk.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// apk list matches package names against glob patterns
+	args := []string{"list"}
+	for _, keyword := range keywords {
+		args = append(args, "*"+keyword+"*")
+	}
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListOutput(string(out), opts), nil
+}
+
+// ListInstalled lists all installed packages using apk.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "list", ArgsInstalled)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListOutput(string(out), opts), nil
+}
+
+// ListUpgradable lists all upgradable packages using apk.
+// The result is based on the local copy of the package index, so Refresh should be called first for up-to-date results.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "list", ArgsUpgradable)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	packages := ParseListOutput(string(out), opts)
+
+	// held packages are listed as upgradable, but apk upgrade keeps them at their pinned version
+	held, err := a.ListHeld(opts)
+	if err != nil {
+		return nil, err
+	}
+	return markHeld(packages, held), nil
+}
+
+// Upgrade upgrades the provided packages using apk.
+// If no packages are given, all installed packages are upgraded.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"upgrade"}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseTransactionOutput(string(out), opts), nil
+}
+
+// UpgradeAll upgrades all installed packages using apk.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// Clean removes the packages that are no longer needed from the package cache.
+func (a *PackageManager) Clean(opts *manager.Options) error {
+	if opts == nil {
+		opts = &manager.Options{}
+	}
+
+	out, err := a.run([]string{"cache", "clean"}, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Verbose && out != nil {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
+
+// AutoRemove removes orphaned packages, i.e. installed packages that are neither in the world nor required by another package.
+// apk normally removes such packages by itself, but they can be left behind when the world file is edited by hand.
+func (a *PackageManager) AutoRemove(opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(installed) == 0 {
+		return nil, nil
+	}
+
+	world, err := os.ReadFile(worldFile)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"info", ArgsRdepends}
+	for _, pkg := range installed {
+		args = append(args, pkg.Name)
+	}
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := FindOrphans(string(out), ParseWorldFile(string(world)))
+	if len(orphans) == 0 {
+		return nil, nil
+	}
+
+	args = append([]string{"del", ArgsPurge}, orphans...)
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	out, err = a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseTransactionOutput(string(out), opts), nil
+}
+
+// Verify checks the files of the installed packages against the checksums recorded in the package database using apk audit,
+// and returns the results of the packages with modified or missing files, which apk audit --packages doesn't tell.
+// If no packages are given, all packages with modified files are returned.
+// Note that apk audit also reports modified configuration files, which is expected on most systems.
+func (a *PackageManager) Verify(pkgs []string, opts *manager.Options) ([]manager.VerificationResult, error) {
+	cmd := manager.Command(opts, pm, "audit", ArgsPackages, ArgsSystem)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return manager.FilterVerificationResults(ParseAuditPackagesOutput(string(out), opts), pkgs), nil
+}
+
+// GetPackageInfo retrieves package information for the specified package using apk.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "list", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+
+	for _, info := range ParseListOutput(string(out), opts) {
+		if info.Name == pkg {
+			return info, nil
+		}
+	}
+	return manager.PackageInfo{Name: pkg, Status: manager.PackageStatusUnknown, PackageManager: pm}, nil
+}
+
+// GetDependencies returns the packages the specified package directly depends on, using apk info --depends.
+// Dependencies on shared libraries and commands are returned as their provides name, such as "so:libc.musl-x86_64.so.1".
+func (a *PackageManager) GetDependencies(pkg string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "info", ArgsDepends, pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDependsOutput(string(out), opts), nil
+}
+
+// GetReverseDependencies returns the installed packages that directly depend on the specified package, using apk info --rdepends.
+func (a *PackageManager) GetReverseDependencies(pkg string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "info", ArgsRdepends, pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReverseDependsOutput(string(out), opts), nil
+}
+
+// Owns returns the installed package owning the specified path, using apk info --who-owns.
+func (a *PackageManager) Owns(path string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "info", ArgsWhoOwns, path)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// apk exits with the number of paths it could not find an owner for
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseOwnsOutput(string(out), opts), nil
+}
+
+// ListFiles returns the files installed by the specified package, using apk info --contents.
+// apk does not record directories, so only files are returned.
+func (a *PackageManager) ListFiles(pkg string, opts *manager.Options) ([]string, error) {
+	cmd := manager.Command(opts, pm, "info", ArgsContents, pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListFilesOutput(string(out), opts), nil
+}
+
+// Hold holds the provided installed packages at their installed version, by pinning their exact version in the world file
+// (apk add name=version). apk upgrade then keeps them at this version.
+func (a *PackageManager) Hold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []manager.PackageInfo
+	args := []string{"add"}
+	for _, name := range pkgs {
+		found := filterPackages(installed, []string{name})
+		if len(found) == 0 {
+			return nil, fmt.Errorf("%s: package %s is not installed", pm, name)
+		}
+		args = append(args, name+"="+found[0].Version)
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Version:        found[0].Version,
+			Status:         manager.PackageStatusHeld,
+			PackageManager: pm,
+		})
+	}
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	if _, err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+// Unhold releases the hold on the provided packages, by removing their version constraint from the world file (apk add name).
+func (a *PackageManager) Unhold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	args := append([]string{"add"}, pkgs...)
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+	if _, err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+
+	var packages []manager.PackageInfo
+	for _, name := range pkgs {
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+	return packages, nil
+}
+
+// ListExplicit returns the names of the packages explicitly requested in the world file, the other installed packages
+// being their dependencies.
+func (a *PackageManager) ListExplicit(opts *manager.Options) ([]string, error) {
+	world, err := os.ReadFile(worldFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range ParseWorldFile(string(world)) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ListHeld lists the packages pinned at an exact version in the world file.
+func (a *PackageManager) ListHeld(opts *manager.Options) ([]manager.PackageInfo, error) {
+	world, err := os.ReadFile(worldFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseHeldPackages(string(world)), nil
+}
+
+// run runs apk with the given arguments, either attached to the terminal in interactive mode,
+// in which case no output is returned, or non-interactively, returning the standard output.
+func (a *PackageManager) run(args []string, opts *manager.Options) ([]byte, error) {
+	if opts.Interactive {
+		cmd := manager.Command(opts, pm, append(args, ArgsInteractive)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return nil, manager.Run(cmd)
+	}
+
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	return manager.Output(cmd)
+}
@@ -0,0 +1,401 @@
+// Package apk provides a package manager implementation for Alpine Linux
+// using apk as the underlying package management tool.
+package apk
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// listPattern matches the lines of `apk list`, e.g. "curl-8.5.0-r0 x86_64 {curl} (curl) [installed]",
+// capturing the package name with its version, the architecture, the origin, the license and the optional status.
+var listPattern = regexp.MustCompile(`^(\S+) (\S+) \{(.+?)\} \((.*)\)(?: \[([^\]]+)\])?$`)
+
+// transactionPattern matches the steps of an apk transaction, e.g. "(1/3) Upgrading musl (1.2.4-r1 -> 1.2.4-r2)",
+// capturing the action, the package name and the version (or version change).
+var transactionPattern = regexp.MustCompile(`^\(\d+/\d+\) (\w+) (\S+) \((.+)\)$`)
+
+// versionPattern splits a "name-version-rN" package string, as the version never contains a hyphen except before the release.
+var versionPattern = regexp.MustCompile(`^(.+)-([^-]+-r\d+)$`)
+
+// ParseTransactionOutput parses the output of the apk commands that change the installed packages
+// (add, del, upgrade, including with --simulate) and returns the list of packages affected by the transaction.
+// Installed and upgraded packages are marked as installed, removed packages as available.
+// Example msg:
+//
+//	(1/3) Upgrading musl (1.2.4_git20230717-r4 -> 1.2.4_git20230717-r5)
+//	(2/3) Installing libcurl (8.5.0-r0)
+//	(3/3) Purging wget (1.21.4-r0)
+//	Executing busybox-1.36.1-r15.trigger
+//	OK: 12 MiB in 17 packages
+func ParseTransactionOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		match := transactionPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			if opts != nil && opts.Verbose && line != "" {
+				opts.Log().Debug("Output", "package_manager", pm, "line", line)
+			}
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           match[2],
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		}
+
+		switch match[1] {
+		case "Purging", "Deleting":
+			packageInfo.Version = match[3]
+			packageInfo.Status = manager.PackageStatusAvailable
+		case "Upgrading", "Downgrading", "Replacing":
+			oldVersion, newVersion, found := strings.Cut(match[3], " -> ")
+			if !found {
+				newVersion = oldVersion
+				oldVersion = ""
+			}
+			packageInfo.Version = oldVersion
+			packageInfo.NewVersion = newVersion
+		case "Installing", "Reinstalling":
+			packageInfo.Version = match[3]
+		default:
+			if opts != nil && opts.Verbose {
+				opts.Log().Debug("Unknown action", "package_manager", pm, "action", match[1], "package", match[2])
+			}
+			continue
+		}
+
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseListOutput parses the output of `apk list` command (with or without --installed or --upgradable)
+// and returns the list of packages. The status is derived from the bracketed status at the end of each line.
+// Example msg:
+//
+//	curl-8.5.0-r0 x86_64 {curl} (curl) [installed]
+//	musl-1.2.4_git20230717-r5 x86_64 {musl} (MIT) [upgradable from: musl-1.2.4_git20230717-r4]
+//	wget-1.21.4-r0 x86_64 {wget} (GPL-3.0-or-later)
+func ParseListOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		match := listPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		name, version := splitPackageVersion(match[1])
+		packageInfo := manager.PackageInfo{
+			Name:           name,
+			Arch:           match[2],
+			PackageManager: pm,
+			License:        match[4],
+			AdditionalData: map[string]string{"origin": match[3]},
+		}
+
+		switch status := match[5]; {
+		case status == "installed":
+			packageInfo.Version = version
+			packageInfo.Status = manager.PackageStatusInstalled
+		case strings.HasPrefix(status, "upgradable from: "):
+			_, packageInfo.Version = splitPackageVersion(strings.TrimPrefix(status, "upgradable from: "))
+			packageInfo.NewVersion = version
+			packageInfo.Status = manager.PackageStatusUpgradable
+		case status == "":
+			packageInfo.NewVersion = version
+			packageInfo.Status = manager.PackageStatusAvailable
+		default:
+			packageInfo.Version = version
+			packageInfo.Status = manager.PackageStatusUnknown
+		}
+
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// AuditProblem is the problem reported for the packages listed by apk audit --packages, which doesn't tell the files.
+var AuditProblem string = "files differ from the package database"
+
+// ParseAuditPackagesOutput parses the output of `apk audit --packages` command,
+// which lists the names of the packages with files that differ from the package database,
+// and returns their results, with AuditProblem.
+// Example msg:
+//
+//	alpine-baselayout
+//	busybox
+func ParseAuditPackagesOutput(msg string, opts *manager.Options) []manager.VerificationResult {
+	var results []manager.VerificationResult
+
+	for _, line := range strings.Split(msg, "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+
+		results = append(results, manager.VerificationResult{
+			Name:           name,
+			PackageManager: pm,
+			Problems:       []string{AuditProblem},
+		})
+	}
+
+	return results
+}
+
+// ParseWorldFile parses the content of the world file (/etc/apk/world) and returns the names of the packages it lists,
+// without their version constraints or repository tags (e.g. "curl" for "curl>8.0" or "curl@edge").
+func ParseWorldFile(content string) map[string]bool {
+	world := make(map[string]bool)
+	for _, field := range strings.Fields(content) {
+		// "!pkg" forbids a package, it does not require it
+		if strings.HasPrefix(field, "!") {
+			continue
+		}
+		if idx := strings.IndexAny(field, "<>=~@"); idx > 0 {
+			field = field[:idx]
+		}
+		world[field] = true
+	}
+	return world
+}
+
+// ParseHeldPackages parses the content of the world file (/etc/apk/world) and returns the packages pinned at an exact version
+// ("name=version"), with their status set to held.
+func ParseHeldPackages(content string) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	for _, field := range strings.Fields(content) {
+		name, version, found := strings.Cut(field, "=")
+		if !found || strings.ContainsAny(name, "<>~!") || version == "" {
+			continue
+		}
+		// drop the repository tag, e.g. "curl=8.5.0-r0@edge"
+		version, _, _ = strings.Cut(version, "@")
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Version:        version,
+			Status:         manager.PackageStatusHeld,
+			PackageManager: pm,
+		})
+	}
+	return packages
+}
+
+// FindOrphans parses the output of `apk info --rdepends` command for the installed packages
+// and returns the names of the packages that are not required by any other package nor listed in the world.
+// Example msg:
+//
+//	curl-8.5.0-r0 is required by:
+//
+//	libcurl-8.5.0-r0 is required by:
+//	curl-8.5.0-r0
+func FindOrphans(msg string, world map[string]bool) []string {
+	var orphans []string
+	var current string
+	required := false
+
+	flush := func() {
+		if current != "" && !required && !world[current] {
+			orphans = append(orphans, current)
+		}
+		current = ""
+		required = false
+	}
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasSuffix(line, " is required by:") {
+			flush()
+			current, _ = splitPackageVersion(strings.TrimSuffix(line, " is required by:"))
+			continue
+		}
+		required = true
+	}
+	flush()
+
+	return orphans
+}
+
+// ParseDependsOutput parses the output of `apk info --depends packageName` command and returns the direct dependencies of the package.
+// Version constraints are kept in AdditionalData["constraint"].
+// apk prints a block for each known version of the package; dependencies are only returned once.
+// Example msg:
+//
+//	vim-9.0.2127-r0 depends on:
+//	xxd
+//	musl>=1.2.4
+//	so:libc.musl-x86_64.so.1
+//	so:libncursesw.so.6
+func ParseDependsOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, " depends on:") || seen[line] {
+			continue
+		}
+		seen[line] = true
+
+		packageInfo := manager.PackageInfo{
+			Name:           line,
+			Status:         manager.PackageStatusUnknown,
+			PackageManager: pm,
+		}
+		if idx := strings.IndexAny(line, "<>=~"); idx > 0 {
+			packageInfo.Name = line[:idx]
+			packageInfo.AdditionalData = map[string]string{"constraint": line[idx:]}
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseReverseDependsOutput parses the output of `apk info --rdepends packageName` command
+// and returns the installed packages that depend on the package.
+// Example msg:
+//
+//	musl-1.2.4-r2 is required by:
+//	busybox-1.36.1-r15
+//	vim-9.0.2127-r0
+func ParseReverseDependsOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, " is required by:") || seen[line] {
+			continue
+		}
+		seen[line] = true
+
+		name, version := splitPackageVersion(line)
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Version:        version,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseOwnsOutput parses the output of `apk info --who-owns path` command and returns the packages owning the path.
+// Example msg:
+//
+//	/bin/busybox is owned by busybox-1.36.1-r15
+func ParseOwnsOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		_, owner, found := strings.Cut(strings.TrimSpace(line), " is owned by ")
+		if !found {
+			continue
+		}
+
+		name, version := splitPackageVersion(owner)
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Version:        version,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseListFilesOutput parses the output of `apk info --contents packageName` command and returns the installed files.
+// apk lists the files relative to the root directory; they are returned as absolute paths.
+// Example msg:
+//
+//	vim-9.0.2127-r0 contains:
+//	usr/bin/ex
+//	usr/bin/vim
+func ParseListFilesOutput(msg string, opts *manager.Options) []string {
+	var files []string
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, " contains:") {
+			continue
+		}
+		files = append(files, "/"+line)
+	}
+
+	return files
+}
+
+// splitPackageVersion splits a "name-version-rN" package string into its name and version.
+func splitPackageVersion(s string) (string, string) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return s, ""
+	}
+	return match[1], match[2]
+}
+
+// filterPackages returns the packages whose name is one of the given names, or all packages if no names are given.
+func filterPackages(packages []manager.PackageInfo, names []string) []manager.PackageInfo {
+	if len(names) == 0 {
+		return packages
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var filtered []manager.PackageInfo
+	for _, pkg := range packages {
+		if wanted[pkg.Name] {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
+
+// markHeld sets the status of the packages that are held to held.
+func markHeld(packages []manager.PackageInfo, held []manager.PackageInfo) []manager.PackageInfo {
+	isHeld := make(map[string]bool)
+	for _, pkg := range held {
+		isHeld[pkg.Name] = true
+	}
+
+	for i := range packages {
+		if isHeld[packages[i].Name] {
+			packages[i].Status = manager.PackageStatusHeld
+		}
+	}
+	return packages
+}
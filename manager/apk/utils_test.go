@@ -0,0 +1,242 @@
+package apk_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/apk"
+)
+
+// The fixtures below were captured on Alpine Linux 3.19.
+
+func TestParseTransactionOutput(t *testing.T) {
+	var inputParseTransactionOutput string = strings.Join([]string{
+		`(1/3) Upgrading musl (1.2.4_git20230717-r4 -> 1.2.4_git20230717-r5)`,
+		`(2/3) Installing libcurl (8.5.0-r0)`,
+		`(3/3) Purging wget (1.21.4-r0)`,
+		`Executing busybox-1.36.1-r15.trigger`,
+		`OK: 12 MiB in 17 packages`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "musl",
+			Version:        "1.2.4_git20230717-r4",
+			NewVersion:     "1.2.4_git20230717-r5",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "apk",
+		},
+		{
+			Name:           "libcurl",
+			Version:        "8.5.0-r0",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "apk",
+		},
+		{
+			Name:           "wget",
+			Version:        "1.21.4-r0",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "apk",
+		},
+	}
+
+	actualPackageInfo := apk.ParseTransactionOutput(inputParseTransactionOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseTransactionOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListOutput(t *testing.T) {
+	var inputParseListOutput string = strings.Join([]string{
+		`curl-8.5.0-r0 x86_64 {curl} (curl) [installed]`,
+		`musl-1.2.4_git20230717-r5 x86_64 {musl} (MIT) [upgradable from: musl-1.2.4_git20230717-r4]`,
+		`wget-1.21.4-r0 x86_64 {wget} (GPL-3.0-or-later)`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "curl",
+			Version:        "8.5.0-r0",
+			Status:         manager.PackageStatusInstalled,
+			Arch:           "x86_64",
+			PackageManager: "apk",
+			License:        "curl",
+			AdditionalData: map[string]string{"origin": "curl"},
+		},
+		{
+			Name:           "musl",
+			Version:        "1.2.4_git20230717-r4",
+			NewVersion:     "1.2.4_git20230717-r5",
+			Status:         manager.PackageStatusUpgradable,
+			Arch:           "x86_64",
+			PackageManager: "apk",
+			License:        "MIT",
+			AdditionalData: map[string]string{"origin": "musl"},
+		},
+		{
+			Name:           "wget",
+			NewVersion:     "1.21.4-r0",
+			Status:         manager.PackageStatusAvailable,
+			Arch:           "x86_64",
+			PackageManager: "apk",
+			License:        "GPL-3.0-or-later",
+			AdditionalData: map[string]string{"origin": "wget"},
+		},
+	}
+
+	actualPackageInfo := apk.ParseListOutput(inputParseListOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseAuditPackagesOutput(t *testing.T) {
+	var inputParseAuditPackagesOutput string = strings.Join([]string{
+		`alpine-baselayout`,
+		`busybox`,
+	}, "\n")
+
+	var expectedResults = []manager.VerificationResult{
+		{
+			Name:           "alpine-baselayout",
+			PackageManager: "apk",
+			Problems:       []string{apk.AuditProblem},
+		},
+		{
+			Name:           "busybox",
+			PackageManager: "apk",
+			Problems:       []string{apk.AuditProblem},
+		},
+	}
+
+	actualResults := apk.ParseAuditPackagesOutput(inputParseAuditPackagesOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedResults, actualResults) {
+		t.Errorf("ParseAuditPackagesOutput() = %+v, want %+v", actualResults, expectedResults)
+	}
+}
+
+func TestFindOrphans(t *testing.T) {
+	var inputWorldFile string = strings.Join([]string{
+		`alpine-base`,
+		`curl>8`,
+		`!wget`,
+	}, "\n")
+
+	var inputRdependsOutput string = strings.Join([]string{
+		`alpine-base-3.19.0-r0 is required by:`,
+		``,
+		`curl-8.5.0-r0 is required by:`,
+		``,
+		`libcurl-8.5.0-r0 is required by:`,
+		`curl-8.5.0-r0`,
+		``,
+		`nghttp2-libs-1.58.0-r0 is required by:`,
+		``,
+	}, "\n")
+
+	expectedOrphans := []string{"nghttp2-libs"}
+
+	actualOrphans := apk.FindOrphans(inputRdependsOutput, apk.ParseWorldFile(inputWorldFile))
+
+	if !reflect.DeepEqual(expectedOrphans, actualOrphans) {
+		t.Errorf("FindOrphans() = %+v, want %+v", actualOrphans, expectedOrphans)
+	}
+}
+
+func TestParseDependsOutput(t *testing.T) {
+	var inputParseDependsOutput string = strings.Join([]string{
+		`vim-9.0.2127-r0 depends on:`,
+		`xxd`,
+		`musl>=1.2.4`,
+		`so:libc.musl-x86_64.so.1`,
+		``,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "xxd", Status: manager.PackageStatusUnknown, PackageManager: "apk"},
+		{Name: "musl", Status: manager.PackageStatusUnknown, PackageManager: "apk", AdditionalData: map[string]string{"constraint": ">=1.2.4"}},
+		{Name: "so:libc.musl-x86_64.so.1", Status: manager.PackageStatusUnknown, PackageManager: "apk"},
+	}
+
+	actualPackageInfo := apk.ParseDependsOutput(inputParseDependsOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDependsOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseReverseDependsOutput(t *testing.T) {
+	var inputParseReverseDependsOutput string = strings.Join([]string{
+		`musl-1.2.4-r2 is required by:`,
+		`busybox-1.36.1-r15`,
+		`vim-9.0.2127-r0`,
+		``,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "busybox", Version: "1.36.1-r15", Status: manager.PackageStatusInstalled, PackageManager: "apk"},
+		{Name: "vim", Version: "9.0.2127-r0", Status: manager.PackageStatusInstalled, PackageManager: "apk"},
+	}
+
+	actualPackageInfo := apk.ParseReverseDependsOutput(inputParseReverseDependsOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseReverseDependsOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseOwnsOutput(t *testing.T) {
+	var inputParseOwnsOutput string = "/bin/busybox is owned by busybox-1.36.1-r15\n"
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "busybox", Version: "1.36.1-r15", Status: manager.PackageStatusInstalled, PackageManager: "apk"},
+	}
+
+	actualPackageInfo := apk.ParseOwnsOutput(inputParseOwnsOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseOwnsOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListFilesOutput(t *testing.T) {
+	var inputParseListFilesOutput string = strings.Join([]string{
+		`vim-9.0.2127-r0 contains:`,
+		`usr/bin/ex`,
+		`usr/bin/vim`,
+		``,
+	}, "\n")
+
+	var expectedFiles = []string{"/usr/bin/ex", "/usr/bin/vim"}
+
+	actualFiles := apk.ParseListFilesOutput(inputParseListFilesOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedFiles, actualFiles) {
+		t.Errorf("ParseListFilesOutput() = %+v, want %+v", actualFiles, expectedFiles)
+	}
+}
+
+func TestParseHeldPackages(t *testing.T) {
+	var inputWorldFile string = strings.Join([]string{
+		`alpine-base`,
+		`curl=8.5.0-r0@edge`,
+		`musl>=1.2`,
+		`vim=9.0.2127-r0`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "curl", Version: "8.5.0-r0", Status: manager.PackageStatusHeld, PackageManager: "apk"},
+		{Name: "vim", Version: "9.0.2127-r0", Status: manager.PackageStatusHeld, PackageManager: "apk"},
+	}
+
+	actualPackageInfo := apk.ParseHeldPackages(inputWorldFile)
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseHeldPackages() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
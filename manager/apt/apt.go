@@ -15,9 +15,14 @@
 package apt
 
 import (
-	"log"
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	// "github.com/rs/zerolog"
 	// "github.com/rs/zerolog/log"
@@ -37,6 +42,52 @@ const (
 	ArgsPurge        string = "--purge"
 	ArgsAutoRemove   string = "--autoremove"
 	ArgsShowProgress string = "--show-progress"
+	ArgsInstalled    string = "--installed"
+
+	ArgsAllowDowngrades string = "--allow-downgrades"
+	ArgsDownloadOnly    string = "--download-only"
+	ArgsVerboseVersions string = "-V"
+	ArgsPrintURIs       string = "--print-uris"
+)
+
+// ArgsStatusFd makes apt write machine-readable progress lines to its standard output, parsed by ParseStatusLine.
+var ArgsStatusFd []string = []string{"-o", "APT::Status-Fd=1"}
+
+// LockMessages are the messages of apt and dpkg failing because another process holds their lock.
+var LockMessages []string = []string{"Could not get lock", "Unable to acquire the dpkg frontend lock", "Unable to lock"}
+
+// StaleIndexMessages are the messages of apt failing because its package index is missing or stale, e.g. when
+// packages were superseded in the repositories since the last refresh.
+var StaleIndexMessages []string = []string{"Unable to locate package", "has no installation candidate", "E: Version '",
+	"404  Not Found", "maybe run apt-get update"}
+
+// ArgsDependsFilter limits `apt-cache depends` and `apt-cache rdepends` to hard dependencies (Depends and PreDepends).
+var ArgsDependsFilter []string = []string{"--no-recommends", "--no-suggests", "--no-conflicts", "--no-breaks", "--no-replaces", "--no-enhances"}
+
+// KeyringsDir is where the keyrings imported with ImportKey are stored, to be referenced with signed-by in the repository sources.
+// KeyringsDirs are all the directories of keyrings listed by ListKeys, trusted either for all repositories or with signed-by.
+var (
+	KeyringsDir  string   = "/etc/apt/keyrings"
+	KeyringsDirs []string = []string{"/etc/apt/keyrings", "/usr/share/keyrings", "/etc/apt/trusted.gpg.d"}
+)
+
+// DocDir is where the packages install their documentation, including their copyright file, read for their license.
+var DocDir string = "/usr/share/doc"
+
+// SourcesFile and SourcesDir are the sources of apt, in the one-line format (.list) or in the deb822 format (.sources),
+// read for the repositories marked trusted, whose packages aren't verified.
+var (
+	SourcesFile string = "/etc/apt/sources.list"
+	SourcesDir  string = "/etc/apt/sources.list.d"
+)
+
+// ArchivesDir is the cache of apt where packages are downloaded, unless Options.DownloadDir is set.
+var ArchivesDir string = "/var/cache/apt/archives"
+
+// RebootRequiredFile is created by the packages needing a reboot once installed or upgraded, which are listed in RebootRequiredPkgsFile.
+var (
+	RebootRequiredFile     string = "/var/run/reboot-required"
+	RebootRequiredPkgsFile string = "/var/run/reboot-required.pkgs"
 )
 
 // ENV_NonInteractive contains environment variables used to set non-interactive mode for apt and dpkg.
@@ -56,8 +107,83 @@ func (a *PackageManager) GetPackageManager() string {
 	return pm
 }
 
+// Capabilities returns the operations and options supported by apt.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:           true,
+		RegexSearch:      true,
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		RootDir:          true,
+		Architecture:     true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+		Downgrade:        true,
+		Hold:             true,
+		SecurityUpdates:  true,
+		Dependencies:     true,
+		FileOwner:        true,
+		FileList:         true,
+		Keys:             true,
+		Download:         true,
+		PackageNames:     true,
+		LocalInstall:     true,
+		Reboot:           true,
+		Verify:           true,
+		Groups:           true,
+	}
+}
+
 // Install installs the provided packages using the apt package manager.
 func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// apt installs a specific version with name=version
+	pkgs, err := manager.TranslatePackageSpecs(manager.QualifyArchitecture(pkgs, opts, qualifyArch), manager.PackageSpec.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.install(pkgs, opts)
+}
+
+// Download downloads the provided packages and their missing dependencies using apt install --download-only.
+// Packages that are already installed, or already downloaded, are not downloaded again.
+func (a *PackageManager) Download(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var downloadOpts manager.Options
+	if opts != nil {
+		downloadOpts = *opts
+	}
+	downloadOpts.DownloadOnly = true
+	return a.Install(pkgs, &downloadOpts)
+}
+
+// InstallLocal installs the provided .deb files using apt install, which also installs the dependencies they need,
+// like dpkg -i followed by apt install -f.
+func (a *PackageManager) InstallLocal(paths []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	files, err := manager.LocalPackageFiles(paths, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.install(files, opts)
+}
+
+// Downgrade installs the provided packages, given as "name=version", at that version using the apt package manager,
+// allowing it to be older than the installed one.
+func (a *PackageManager) Downgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if err := manager.RequireVersionSpecs(pkgs); err != nil {
+		return nil, err
+	}
+	pkgs, err := manager.TranslatePackageSpecs(manager.QualifyArchitecture(pkgs, opts, qualifyArch), manager.PackageSpec.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.install(append([]string{ArgsAllowDowngrades}, pkgs...), opts)
+}
+
+// install runs apt install with the provided arguments, which are the packages to install and extra options.
+func (a *PackageManager) install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
 	args := append([]string{"install", ArgsFixBroken}, pkgs...)
 
 	if opts == nil {
@@ -76,21 +202,49 @@ func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manage
 	if !opts.Interactive {
 		args = append(args, ArgsAssumeYes)
 	}
+	if !opts.Interactive && opts.Progress != nil {
+		args = append(args, ArgsStatusFd...)
+	}
+	args = append(args, lockArgs(opts)...)
+	args = append(args, proxyArgs(opts)...)
 
-	cmd := exec.Command(pm, args...)
+	archivesDir := ArchivesDir
+	if opts.DownloadOnly {
+		args = append(args, ArgsDownloadOnly)
+		if opts.DownloadDir != "" {
+			dir, err := filepath.Abs(opts.DownloadDir)
+			if err != nil {
+				return nil, err
+			}
+			// apt downloads into the partial subdirectory first, and fails if it is missing
+			if err := os.MkdirAll(filepath.Join(dir, "partial"), 0o755); err != nil {
+				return nil, err
+			}
+			archivesDir = dir
+			args = append(args, "-o", "Dir::Cache::archives="+dir)
+		}
+	}
+
+	cmd := manager.Command(opts, pm, args...)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	} else {
-		cmd.Env = ENV_NonInteractive
-		out, err := cmd.Output()
+		cmd.Env = manager.Env(opts, ENV_NonInteractive)
+		out, err := output(cmd, opts)
 		if err != nil {
 			return nil, err
 		}
+		if opts.DownloadOnly {
+			return ParseDownloadOutput(string(out), archivesDir, opts), nil
+		}
+		if opts.DryRun {
+			return ParseDryRunOutput(string(out), opts), nil
+		}
 		return ParseInstallOutput(string(out), opts), nil
 	}
 }
@@ -98,7 +252,7 @@ func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manage
 // Delete removes the provided packages using the apt package manager.
 func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
 	// args := append([]string{"remove", ArgsFixBroken, ArgsPurge, ArgsAutoRemove}, pkgs...)
-	args := append([]string{"remove", ArgsFixBroken, ArgsAutoRemove}, pkgs...)
+	args := append([]string{"remove", ArgsFixBroken, ArgsAutoRemove}, manager.QualifyArchitecture(pkgs, opts, qualifyArch)...)
 	if opts == nil {
 		opts = &manager.Options{
 			DryRun:      false,
@@ -113,29 +267,36 @@ func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager
 	if !opts.Interactive {
 		args = append(args, ArgsAssumeYes)
 	}
+	if !opts.Interactive && opts.Progress != nil {
+		args = append(args, ArgsStatusFd...)
+	}
+	args = append(args, lockArgs(opts)...)
 
-	cmd := exec.Command(pm, args...)
+	cmd := manager.Command(opts, pm, args...)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	} else {
-		cmd.Env = ENV_NonInteractive
-		out, err := cmd.Output()
+		cmd.Env = manager.Env(opts, ENV_NonInteractive)
+		out, err := output(cmd, opts)
 		if err != nil {
 			return nil, err
 		}
+		if opts.DryRun {
+			return ParseDryRunOutput(string(out), opts), nil
+		}
 		return ParseDeletedOutput(string(out), opts), nil
 	}
 }
 
 // Refresh updates the package list using the apt package manager.
 func (a *PackageManager) Refresh(opts *manager.Options) error {
-	cmd := exec.Command(pm, "update")
-	cmd.Env = ENV_NonInteractive
+	cmd := manager.Command(opts, pm, append([]string{"update"}, proxyArgs(opts)...)...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
 
 	if opts == nil {
 		opts = &manager.Options{
@@ -148,15 +309,15 @@ func (a *PackageManager) Refresh(opts *manager.Options) error {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return err
 	} else {
-		out, err := cmd.Output()
+		out, err := output(cmd, opts)
 		if err != nil {
 			return err
 		}
 		if opts.Verbose {
-			log.Println(string(out))
+			opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
 		}
 		return nil
 	}
@@ -165,10 +326,10 @@ func (a *PackageManager) Refresh(opts *manager.Options) error {
 // Find searches for packages matching the provided keywords using the apt package manager.
 func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
 	args := append([]string{"search"}, keywords...)
-	cmd := exec.Command("apt", args...)
-	cmd.Env = ENV_NonInteractive
+	cmd := manager.Command(opts, "apt", args...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
 
-	out, err := cmd.Output()
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -178,25 +339,50 @@ func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manag
 
 // ListInstalled lists all installed packages using the apt package manager.
 func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
-	cmd := exec.Command("dpkg-query", "-W", "-f", "${binary:Package} ${Version}\n")
+	cmd := manager.Command(opts, "dpkg-query", "-W", "-f", "${binary:Package}\t${Version}\t${Homepage}\t${Maintainer}\t${Installed-Size}\t${Architecture}\n")
 	// NOTE: can also use `apt list --installed`, but it's slower
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	// the list of a large system is parsed as it is written, rather than kept whole in memory
+	var packages []manager.PackageInfo
+	err := manager.StreamLines(cmd, func(line string) error {
+		if packageInfo, ok := parseInstalledLine(line); ok {
+			packageInfo.License = copyrightLicense(packageInfo.Name, opts)
+			packages = append(packages, packageInfo)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return ParseListInstalledOutput(string(out), opts), nil
+	return packages, nil
 }
 
 // ListUpgradable lists all upgradable packages using the apt package manager.
 func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
-	cmd := exec.Command(pm, "list", "--upgradable")
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	cmd := manager.Command(opts, pm, "list", "--upgradable")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	packages := ParseListUpgradableOutput(string(out), opts)
+
+	// held packages are listed as upgradable, but apt upgrade keeps them back
+	held, err := a.ListHeld(opts)
 	if err != nil {
 		return nil, err
 	}
-	return ParseListUpgradableOutput(string(out), opts), nil
+	return markHeld(packages, held), nil
+}
+
+// ListSecurityUpdates lists the upgradable packages available from a security archive, such as jammy-security.
+// apt doesn't provide the CVEs fixed by the updates.
+func (a *PackageManager) ListSecurityUpdates(opts *manager.Options) ([]manager.PackageInfo, error) {
+	packages, err := a.ListUpgradable(opts)
+	if err != nil {
+		return nil, err
+	}
+	return FilterSecurityUpdates(packages), nil
 }
 
 // Upgrade upgrades the provided packages using the apt package manager.
@@ -220,27 +406,78 @@ func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manage
 	if !opts.Interactive {
 		args = append(args, ArgsAssumeYes)
 	}
+	if !opts.Interactive && opts.Progress != nil {
+		args = append(args, ArgsStatusFd...)
+	}
+	args = append(args, lockArgs(opts)...)
+	args = append(args, proxyArgs(opts)...)
 
-	cmd := exec.Command(pm, args...)
+	cmd := manager.Command(opts, pm, args...)
 
-	log.Printf("Running command: %s %s", pm, args)
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	}
 
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := output(cmd, opts)
 	if err != nil {
 		return nil, err
 	}
+	if opts.DryRun {
+		return ParseDryRunOutput(string(out), opts), nil
+	}
 	return ParseInstallOutput(string(out), opts), nil
 }
 
+// PlanInstall returns the changes installing the provided packages would make, with their versions and sizes.
+// It uses apt install --print-uris, which resolves the transaction without root privileges, and prints the
+// files it would download rather than making changes.
+func (a *PackageManager) PlanInstall(pkgs []string, opts *manager.Options) (*manager.Plan, error) {
+	pkgs, err := manager.TranslatePackageSpecs(manager.QualifyArchitecture(pkgs, opts, qualifyArch), manager.PackageSpec.String)
+	if err != nil {
+		return nil, err
+	}
+	return a.plan(append([]string{"install"}, pkgs...), opts)
+}
+
+// PlanUpgrade returns the changes upgrading the provided packages, or all packages if none are given, would make.
+func (a *PackageManager) PlanUpgrade(pkgs []string, opts *manager.Options) (*manager.Plan, error) {
+	return a.plan(append([]string{"upgrade"}, pkgs...), opts)
+}
+
+// PlanRemove returns the changes removing the provided packages, and the dependencies they no longer need, would make.
+func (a *PackageManager) PlanRemove(pkgs []string, opts *manager.Options) (*manager.Plan, error) {
+	return a.plan(append([]string{"remove", ArgsAutoRemove}, manager.QualifyArchitecture(pkgs, opts, qualifyArch)...), opts)
+}
+
+// qualifyArch qualifies the name of a package with the architecture arch, as name:arch, unless it already has one.
+func qualifyArch(name, arch string) string {
+	if strings.Contains(name, ":") {
+		return name
+	}
+	return name + ":" + arch
+}
+
+// plan runs the apt command args with --print-uris, and returns the plan of the transaction it prints.
+func (a *PackageManager) plan(args []string, opts *manager.Options) (*manager.Plan, error) {
+	args = append(args, ArgsVerboseVersions, ArgsPrintURIs, ArgsAssumeYes)
+	args = append(args, proxyArgs(opts)...)
+	cmd := manager.Command(opts, pm, args...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlanOutput(string(out), opts), nil
+}
+
 // UpgradeAll upgrades all installed packages using the apt package manager.
 func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
 	// TODO: add support for upgrade specific packages
@@ -249,8 +486,8 @@ func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInf
 
 // Clean cleans the local package cache used by the apt package manager.
 func (a *PackageManager) Clean(opts *manager.Options) error {
-	cmd := exec.Command(pm, "autoclean")
-	cmd.Env = ENV_NonInteractive
+	cmd := manager.Command(opts, pm, "autoclean")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
 
 	if opts == nil {
 		opts = &manager.Options{
@@ -263,15 +500,15 @@ func (a *PackageManager) Clean(opts *manager.Options) error {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return err
 	} else {
-		out, err := cmd.Output()
+		out, err := output(cmd, opts)
 		if err != nil {
 			return err
 		}
 		if opts.Verbose {
-			log.Println(string(out))
+			opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
 		}
 		return nil
 	}
@@ -279,13 +516,126 @@ func (a *PackageManager) Clean(opts *manager.Options) error {
 
 // GetPackageInfo retrieves package information for the specified package using the apt package manager.
 func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
-	cmd := exec.Command("apt-cache", "show", pkg)
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	cmd := manager.Command(opts, "apt-cache", "show", pkg)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return manager.PackageInfo{}, err
 	}
-	return ParsePackageInfoOutput(string(out), opts), nil
+	info := ParsePackageInfoOutput(string(out), opts)
+	// the indexes of Debian and Ubuntu have no License field, but the copyright files of installed packages tell it
+	if info.License == "" {
+		info.License = copyrightLicense(info.Name, opts)
+	}
+	info.Origin = policyOrigin(pkg, opts)
+	return info, nil
+}
+
+// policyOrigin returns the origin of the repository the installed or candidate version of a package comes from,
+// from apt-cache policy, or an empty string if it is in no repository, or if apt-cache fails.
+func policyOrigin(pkg string, opts *manager.Options) string {
+	cmd := manager.Command(opts, "apt-cache", "policy", pkg)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return ""
+	}
+	source := ParsePackagePolicyOutput(string(out), opts)
+	if source == "" {
+		return ""
+	}
+
+	cmd = manager.Command(opts, "apt-cache", "policy")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	if out, err = manager.Output(cmd); err != nil {
+		return ""
+	}
+	for _, repo := range ParsePolicyOutput(string(out), opts) {
+		if repo.Name == source {
+			return repo.Origin
+		}
+	}
+	return ""
+}
+
+// ListRepositories lists the repositories apt installs packages from, using apt-cache policy, with the origins of their
+// Release files. The repositories marked trusted in the sources of apt are unsigned.
+func (a *PackageManager) ListRepositories(opts *manager.Options) ([]manager.RepositoryInfo, error) {
+	cmd := manager.Command(opts, "apt-cache", "policy")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	repos := ParsePolicyOutput(string(out), opts)
+
+	trusted := trustedSources(opts)
+	for i := range repos {
+		repos[i].Unsigned = slices.Contains(trusted, strings.TrimSuffix(repos[i].URL, "/"))
+	}
+	return repos, nil
+}
+
+// ListOrigins returns the origins of the installed packages, as in GetPackageInfo, from the archives apt list tells
+// their installed versions to be in, or from apt-cache policy for the archives of several origins, such as the ones
+// of PPAs, named after the release of Ubuntu they are built for.
+func (a *PackageManager) ListOrigins(opts *manager.Options) (map[string]string, error) {
+	cmd := manager.Command(opts, "apt-cache", "policy")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	policy := string(out)
+	archives, ambiguous := ParseArchiveOrigins(policy)
+
+	cmd = manager.Command(opts, "apt", "list", "--installed")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	if out, err = manager.Output(cmd); err != nil {
+		return nil, err
+	}
+	origins, unresolved := ParseListOriginsOutput(string(out), archives, ambiguous, opts)
+	if len(unresolved) == 0 {
+		return origins, nil
+	}
+
+	cmd = manager.Command(opts, "apt-cache", append([]string{"policy"}, unresolved...)...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	if out, err = manager.Output(cmd); err != nil {
+		return nil, err
+	}
+	repos := make(map[string]string)
+	for _, repo := range ParsePolicyOutput(policy, opts) {
+		repos[repo.Name] = repo.Origin
+	}
+	for name, source := range ParsePackagesPolicyOutput(string(out), opts) {
+		if origin := repos[source]; origin != "" {
+			origins[name] = origin
+		}
+	}
+	return origins, nil
+}
+
+// trustedSources returns the URIs of the repositories marked trusted in the sources of apt, in SourcesFile and
+// SourcesDir, under opts.RootDir if set. Unreadable sources are skipped.
+func trustedSources(opts *manager.Options) []string {
+	root := "/"
+	if opts != nil && opts.RootDir != "" {
+		root = opts.RootDir
+	}
+	files := []string{filepath.Join(root, SourcesFile)}
+	for _, pattern := range []string{"*.list", "*.sources"} {
+		matches, _ := filepath.Glob(filepath.Join(root, SourcesDir, pattern))
+		files = append(files, matches...)
+	}
+
+	var uris []string
+	for _, file := range files {
+		if content, err := os.ReadFile(file); err == nil {
+			uris = append(uris, ParseTrustedSources(string(content))...)
+		}
+	}
+	return uris
 }
 
 // AutoRemove removes unused packages and dependencies using the apt package manager.
@@ -305,21 +655,527 @@ func (a *PackageManager) AutoRemove(opts *manager.Options) ([]manager.PackageInf
 	if !opts.Interactive {
 		args = append(args, ArgsAssumeYes)
 	}
+	if !opts.Interactive && opts.Progress != nil {
+		args = append(args, ArgsStatusFd...)
+	}
+	args = append(args, lockArgs(opts)...)
 
-	cmd := exec.Command(pm, args...)
+	cmd := manager.Command(opts, pm, args...)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	} else {
-		cmd.Env = ENV_NonInteractive
-		out, err := cmd.Output()
+		cmd.Env = manager.Env(opts, ENV_NonInteractive)
+		out, err := output(cmd, opts)
 		if err != nil {
 			return nil, err
 		}
+		if opts.DryRun {
+			return ParseDryRunOutput(string(out), opts), nil
+		}
 		return ParseDeletedOutput(string(out), opts), nil
 	}
 }
+
+// GetDependencies returns the packages the specified package directly depends on (Depends and PreDepends), using apt-cache depends.
+func (a *PackageManager) GetDependencies(pkg string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append(append([]string{"depends"}, ArgsDependsFilter...), pkg)
+	cmd := manager.Command(opts, "apt-cache", args...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDependsOutput(string(out), opts), nil
+}
+
+// ListPackageNames returns the names of the packages available in the repositories, using apt-cache pkgnames.
+func (a *PackageManager) ListPackageNames(opts *manager.Options) ([]string, error) {
+	cmd := manager.Command(opts, "apt-cache", "pkgnames")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// GetReverseDependencies returns the installed packages that directly depend on the specified package, using apt-cache rdepends.
+func (a *PackageManager) GetReverseDependencies(pkg string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append(append([]string{"rdepends", ArgsInstalled}, ArgsDependsFilter...), pkg)
+	cmd := manager.Command(opts, "apt-cache", args...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReverseDependsOutput(string(out), opts), nil
+}
+
+// Owns returns the installed packages owning the specified path, using dpkg -S.
+// Note that symbolic links managed by update-alternatives, such as /usr/bin/vim, are not owned by any package.
+func (a *PackageManager) Owns(path string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, "dpkg", "-S", path)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// dpkg exits with 1 when no package owns the path
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseOwnsOutput(string(out), opts), nil
+}
+
+// ListFiles returns the files and directories installed by the specified package, using dpkg -L.
+func (a *PackageManager) ListFiles(pkg string, opts *manager.Options) ([]string, error) {
+	cmd := manager.Command(opts, "dpkg", "-L", pkg)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListFilesOutput(string(out), opts), nil
+}
+
+// ListGroups lists the tasks of tasksel, such as "ssh-server" or "desktop", which install the packages having the
+// task in their Task field, and whether they are installed.
+func (a *PackageManager) ListGroups(opts *manager.Options) ([]manager.GroupInfo, error) {
+	cmd := manager.Command(opts, "tasksel", "--list-tasks")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListTasksOutput(string(out), opts), nil
+}
+
+// InstallGroup installs the packages of the provided tasks of tasksel using apt install with the task^ syntax,
+// one task at a time, so that the packages are reported with the task they were installed with.
+func (a *PackageManager) InstallGroup(groups []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	for _, group := range groups {
+		installed, err := a.install([]string{group + "^"}, opts)
+		packages = append(packages, manager.SetGroup(installed, group)...)
+		if err != nil {
+			return packages, err
+		}
+	}
+	return packages, nil
+}
+
+// RemoveGroup removes the packages of the provided tasks of tasksel using apt remove with the task^ syntax,
+// one task at a time, like Delete.
+func (a *PackageManager) RemoveGroup(groups []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	for _, group := range groups {
+		removed, err := a.Delete([]string{group + "^"}, opts)
+		packages = append(packages, manager.SetGroup(removed, group)...)
+		if err != nil {
+			return packages, err
+		}
+	}
+	return packages, nil
+}
+
+// Verify checks the files of the provided packages, or of all installed packages if none are given, against the
+// checksums recorded by dpkg, using dpkg -V, and returns the results of the packages with modified or missing files.
+// dpkg -V doesn't tell the packages of the files, so they are verified one by one, once found with dpkg -S when all
+// packages are verified. dpkg doesn't record the signatures of installed packages.
+func (a *PackageManager) Verify(pkgs []string, opts *manager.Options) ([]manager.VerificationResult, error) {
+	if len(pkgs) == 0 {
+		out, err := dpkgVerify("", opts)
+		if err != nil {
+			return nil, err
+		}
+		paths := manager.VerifiedPaths(out)
+		if len(paths) == 0 {
+			return nil, nil
+		}
+
+		cmd := manager.Command(opts, "dpkg", append([]string{"-S"}, paths...)...)
+		cmd.Env = manager.Env(opts, ENV_NonInteractive)
+		// dpkg exits with 1 when no package owns some of the paths, e.g. the ones diverted
+		owners, err := manager.Output(cmd)
+		if exitErr, ok := err.(*exec.ExitError); err != nil && (!ok || exitErr.ExitCode() != 1) {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, owner := range ParseOwnsOutput(string(owners), opts) {
+			name := strings.TrimSuffix(owner.Name+":"+owner.Arch, ":")
+			if !seen[name] {
+				seen[name] = true
+				pkgs = append(pkgs, name)
+			}
+		}
+	}
+
+	var results []manager.VerificationResult
+	for _, pkg := range pkgs {
+		out, err := dpkgVerify(pkg, opts)
+		if err != nil {
+			return nil, err
+		}
+		result := manager.VerificationResult{Name: pkg, PackageManager: pm}
+		manager.ParseVerifyOutput(out, &result)
+		if len(manager.VerifiedPaths(out)) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// NeedsReboot returns whether the system needs a reboot, as requested by the upgraded packages with RebootRequiredFile,
+// or because a kernel newer than the running one is installed.
+func (a *PackageManager) NeedsReboot(opts *manager.Options) (manager.RebootStatus, error) {
+	var status manager.RebootStatus
+	if _, err := os.Stat(RebootRequiredFile); err == nil {
+		// the packages are listed once per upgrade requiring the reboot, so they may be repeated
+		out, err := os.ReadFile(RebootRequiredPkgsFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return status, err
+		}
+		status.Add(RebootRequiredFile+" exists", strings.Fields(string(out))...)
+	}
+	err := manager.CheckKernel(&status, a.Owns, opts)
+	return status, err
+}
+
+// Hold holds the provided packages at their installed version using apt-mark hold, so that apt upgrade keeps them back.
+func (a *PackageManager) Hold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.mark("hold", pkgs, opts)
+}
+
+// Unhold releases the hold on the provided packages using apt-mark unhold.
+func (a *PackageManager) Unhold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.mark("unhold", pkgs, opts)
+}
+
+// ListHeld lists the held packages using apt-mark showhold.
+func (a *PackageManager) ListHeld(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, "apt-mark", "showhold")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []manager.PackageInfo
+	for _, name := range strings.Fields(string(out)) {
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Status:         manager.PackageStatusHeld,
+			PackageManager: pm,
+		})
+	}
+	return packages, nil
+}
+
+// ListExplicit returns the names of the packages installed manually, rather than automatically as dependencies, using
+// apt-mark showmanual.
+func (a *PackageManager) ListExplicit(opts *manager.Options) ([]string, error) {
+	cmd := manager.Command(opts, "apt-mark", "showmanual")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// mark runs apt-mark with the given command (hold or unhold) for the provided packages.
+func (a *PackageManager) mark(command string, pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{command}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	cmd := manager.Command(opts, "apt-mark", args...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMarkOutput(string(out), opts), nil
+}
+
+// ImportKey imports the OpenPGP key file at source into the keyring /etc/apt/keyrings/<name>.gpg, dearmoring it with gpg
+// if it is ASCII-armored. If name is empty, the base name of source is used. The keyring must then be referenced with
+// signed-by in the sources of the repository. In dry-run mode, the keys are only read from source.
+func (a *PackageManager) ImportKey(name string, source string, opts *manager.Options) ([]manager.KeyInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	}
+	if name == "" || name == "." || strings.ContainsRune(name, filepath.Separator) {
+		return nil, fmt.Errorf("invalid keyring name %q", name)
+	}
+	keyring := filepath.Join(KeyringsDir, name+".gpg")
+
+	keys, err := a.showKeys(source, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no OpenPGP key found in %s", source)
+	}
+	for i := range keys {
+		keys[i].Keyring = keyring
+	}
+	if opts.DryRun {
+		return keys, nil
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")) {
+		cmd := manager.Command(opts, "gpg", "--dearmor")
+		cmd.Stdin = bytes.NewReader(data)
+		if data, err = manager.Output(cmd); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(KeyringsDir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyring, data, 0o644); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ListKeys lists the keys of the keyrings in /etc/apt/keyrings, /usr/share/keyrings and /etc/apt/trusted.gpg.d,
+// and of the legacy /etc/apt/trusted.gpg keyring.
+func (a *PackageManager) ListKeys(opts *manager.Options) ([]manager.KeyInfo, error) {
+	keyrings := []string{"/etc/apt/trusted.gpg"}
+	for _, dir := range KeyringsDirs {
+		for _, pattern := range []string{"*.gpg", "*.asc"} {
+			matches, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				return nil, err
+			}
+			keyrings = append(keyrings, matches...)
+		}
+	}
+
+	var keys []manager.KeyInfo
+	for _, keyring := range keyrings {
+		if _, err := os.Stat(keyring); err != nil {
+			continue
+		}
+		found, err := a.showKeys(keyring, opts)
+		if err != nil {
+			opts.Log().Warn("Skipping keyring", "package_manager", pm, "keyring", keyring, "error", err)
+			continue
+		}
+		keys = append(keys, found...)
+	}
+	return keys, nil
+}
+
+// RemoveKey removes the keyring files containing the key with the specified fingerprint or key ID (its last 16 digits),
+// or the keyring with the specified name in /etc/apt/keyrings. Other keys of the removed keyrings are removed too,
+// and returned along with the requested one. Keys of the legacy /etc/apt/trusted.gpg keyring can't be removed.
+func (a *PackageManager) RemoveKey(id string, opts *manager.Options) ([]manager.KeyInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	keys, err := a.ListKeys(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	named := filepath.Join(KeyringsDir, id+".gpg")
+	remove := make(map[string]bool)
+	for _, key := range keys {
+		if key.Keyring == named || (len(id) >= 8 && strings.HasSuffix(key.ID, strings.ToUpper(id))) {
+			remove[key.Keyring] = true
+		}
+	}
+	if len(remove) == 0 {
+		return nil, fmt.Errorf("no key %s found", id)
+	}
+	if remove["/etc/apt/trusted.gpg"] {
+		return nil, fmt.Errorf("key %s is in the legacy keyring /etc/apt/trusted.gpg, which can't be removed", id)
+	}
+
+	var removed []manager.KeyInfo
+	for _, key := range keys {
+		if remove[key.Keyring] {
+			removed = append(removed, key)
+		}
+	}
+	if opts.DryRun {
+		return removed, nil
+	}
+
+	for keyring := range remove {
+		if err := os.Remove(keyring); err != nil {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
+
+// showKeys returns the keys of a key file or keyring, using gpg --show-keys.
+func (a *PackageManager) showKeys(path string, opts *manager.Options) ([]manager.KeyInfo, error) {
+	cmd := manager.Command(opts, "gpg", "--show-keys", "--with-colons", path)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseShowKeysOutput(string(out), path, opts), nil
+}
+
+// copyrightLicense returns the license of the installed package name, read from its machine-readable copyright file
+// in the system at opts.RootDir if opts.Licenses is set, or an empty string if it is not installed or its copyright
+// file is not machine-readable. Commands run through a wrapper, such as in a container, operate on another system
+// than the one whose files are read, so no license is read then.
+func copyrightLicense(name string, opts *manager.Options) string {
+	if opts == nil || !opts.Licenses || opts.Wrapper != nil {
+		return ""
+	}
+	content, err := os.ReadFile(filepath.Join(opts.RootDir, DocDir, name, "copyright"))
+	if err != nil {
+		return ""
+	}
+	return ParseCopyrightLicense(string(content))
+}
+
+// dpkgVerify returns the output of dpkg -V for pkg, or for all installed packages if pkg is "".
+func dpkgVerify(pkg string, opts *manager.Options) (string, error) {
+	args := []string{"-V"}
+	if pkg != "" {
+		args = append(args, pkg)
+	}
+	cmd := manager.Command(opts, "dpkg", args...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// output runs a non-interactive apt command and returns its standard output,
+// reporting the progress lines enabled by ArgsStatusFd to opts.Progress, if set.
+// The command is retried while another process holds the lock of apt, for up to opts.LockWait, and with
+// opts.AutoRefresh, once after refreshing the package index if it failed because the index is stale.
+func output(cmd *exec.Cmd, opts *manager.Options) ([]byte, error) {
+	out, err := outputLocked(cmd, opts)
+	if err == nil || !opts.AutoRefresh || opts.DryRun {
+		return out, err
+	}
+	msg := staleMessage(err)
+	if msg == "" {
+		return out, err
+	}
+
+	opts.Log().Warn("The package index is stale, refreshing it", "package_manager", pm, "error", msg)
+	refreshOpts := *opts
+	refreshOpts.AutoRefresh, refreshOpts.Interactive, refreshOpts.Progress = false, false, nil
+	if refreshErr := (&PackageManager{}).Refresh(&refreshOpts); refreshErr != nil {
+		return out, fmt.Errorf("%s, and refreshing the package index failed: %w", msg, refreshErr)
+	}
+
+	// a command can only run once, so the retry runs a copy of it
+	return outputLocked(manager.Rerun(opts, cmd), opts)
+}
+
+// outputLocked runs a non-interactive apt command like output, retrying it while another process holds the lock of apt.
+func outputLocked(cmd *exec.Cmd, opts *manager.Options) ([]byte, error) {
+	return manager.RetryLocked(cmd, opts, isLocked, func(cmd *exec.Cmd) ([]byte, error) {
+		if opts.Progress == nil {
+			return manager.Output(cmd)
+		}
+		return manager.StreamOutput(cmd, func(line string) {
+			if event, ok := ParseStatusLine(line); ok {
+				opts.Progress.Report(event)
+			}
+		})
+	})
+}
+
+// isLocked reports whether an apt command failed because another process holds the lock of apt or dpkg.
+func isLocked(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 100 {
+		return false
+	}
+	for _, msg := range LockMessages {
+		if bytes.Contains(exitErr.Stderr, []byte(msg)) {
+			return true
+		}
+	}
+	return false
+}
+
+// staleMessage returns the line of the standard error of an apt command telling that it failed because the package index
+// is stale, or "" if it failed for another reason.
+func staleMessage(err error) string {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 100 {
+		return ""
+	}
+	for _, line := range strings.Split(string(exitErr.Stderr), "\n") {
+		for _, msg := range StaleIndexMessages {
+			if strings.Contains(line, msg) {
+				return strings.TrimSpace(line)
+			}
+		}
+	}
+	return ""
+}
+
+// lockArgs returns the arguments making apt itself wait for the lock of dpkg for up to opts.LockWait, if set.
+func lockArgs(opts *manager.Options) []string {
+	if opts.LockWait <= 0 {
+		return nil
+	}
+	return []string{"-o", fmt.Sprintf("DPkg::Lock::Timeout=%d", int(opts.LockWait.Seconds()))}
+}
+
+// proxyArgs returns the arguments making apt download through opts.Proxy, if set, and from the hosts of opts.NoProxy
+// directly. apt commands run with ENV_NonInteractive as their only environment, so the proxy is set with options.
+func proxyArgs(opts *manager.Options) []string {
+	if opts == nil || opts.Proxy == "" {
+		return nil
+	}
+	args := []string{"-o", "Acquire::http::Proxy=" + opts.Proxy, "-o", "Acquire::https::Proxy=" + opts.Proxy}
+	for _, host := range strings.Split(opts.NoProxy, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			args = append(args, "-o", "Acquire::http::Proxy::"+host+"=DIRECT", "-o", "Acquire::https::Proxy::"+host+"=DIRECT")
+		}
+	}
+	return args
+}
@@ -2,8 +2,11 @@
 package apt_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/bluet/syspkg/manager"
 	"github.com/bluet/syspkg/manager/apt"
 )
 
@@ -16,3 +19,68 @@ func TestAptPackageManager(t *testing.T) {
 		t.Fatal("AptPackageManager is not available")
 	}
 }
+
+func TestInstallAutoRefresh(t *testing.T) {
+	// a fake apt, failing to install until the package index is refreshed
+	dir := t.TempDir()
+	refreshed := filepath.Join(dir, "refreshed")
+	script := `#!/bin/sh
+case "$1" in
+update) touch "` + refreshed + `" ;;
+install)
+	if [ ! -e "` + refreshed + `" ]; then echo "E: Unable to locate package nano" >&2; exit 100; fi
+	echo "Setting up nano (7.2-1) ..." ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "apt"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	aptManager := &apt.PackageManager{}
+	if _, err := aptManager.Install([]string{"nano"}, &manager.Options{}); err == nil {
+		t.Errorf("Install() error = nil, want the error of the stale package index without AutoRefresh")
+	}
+	packages, err := aptManager.Install([]string{"nano"}, &manager.Options{AutoRefresh: true})
+	if err != nil || len(packages) != 1 || packages[0].Name != "nano" {
+		t.Errorf("Install() = %+v, %+v, want nano installed after refreshing the package index", packages, err)
+	}
+}
+
+func TestListInstalledLicenses(t *testing.T) {
+	// a fake dpkg-query, and the copyright file of syspkg-test in the system at root, not on the host
+	dir, root := t.TempDir(), t.TempDir()
+	script := "#!/bin/sh\nprintf 'syspkg-test\\t1.0-1\\t\\t\\t3800\\tamd64\\n'\n"
+	if err := os.WriteFile(filepath.Join(dir, "dpkg-query"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	docDir := filepath.Join(root, apt.DocDir, "syspkg-test")
+	if err := os.MkdirAll(docDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	copyright := "Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/\n\nFiles: *\nLicense: MIT\n"
+	if err := os.WriteFile(filepath.Join(docDir, "copyright"), []byte(copyright), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	aptManager := &apt.PackageManager{}
+	for _, tt := range []struct {
+		name     string
+		opts     *manager.Options
+		expected string
+	}{
+		{name: "not requested", opts: &manager.Options{RootDir: root}, expected: ""},
+		{name: "requested", opts: &manager.Options{RootDir: root, Licenses: true}, expected: "MIT"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			packages, err := aptManager.ListInstalled(tt.opts)
+			if err != nil || len(packages) != 1 {
+				t.Fatalf("ListInstalled() = %+v, %+v, want syspkg-test", packages, err)
+			}
+			if packages[0].License != tt.expected {
+				t.Errorf("ListInstalled() license = %q, want %q", packages[0].License, tt.expected)
+			}
+		})
+	}
+}
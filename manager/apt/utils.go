@@ -5,9 +5,11 @@ package apt
 import (
 	"bytes"
 	"fmt"
-	"log"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	// "github.com/rs/zerolog"
@@ -18,6 +20,7 @@ import (
 
 // ParseInstallOutput parses the output of `apt install packageName` command and returns a list of installed packages.
 // It extracts the package name, package architecture, and version from the lines that start with "Setting up ".
+// The packages upgraded or downgraded, unpacked over an installed version, have it as Version, and the new one as NewVersion.
 // Example msg:
 //
 //	Preparing to unpack .../openssl_3.0.2-0ubuntu1.9_amd64.deb ...
@@ -36,10 +39,17 @@ func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo
 	var lines []string = strings.Split(string(msg), "\n")
 
 	packageInfoPattern := regexp.MustCompile(`Setting up ([\w\d.-]+):?([\w\d]+)? \(([\w\d\.-]+)\)`)
+	unpackPattern := regexp.MustCompile(`^Unpacking (\S+) \(\S+\) over \((\S+)\)`)
+	oldVersions := make(map[string]string)
 
 	for _, line := range lines {
 		if opts.Verbose {
-			log.Printf("apt: %s", line)
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if match := unpackPattern.FindStringSubmatch(line); match != nil {
+			oldVersions[match[1]] = match[2]
+			continue
 		}
 
 		match := packageInfoPattern.FindStringSubmatch(line)
@@ -62,6 +72,9 @@ func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo
 				Status:         manager.PackageStatusInstalled,
 				PackageManager: pm,
 			}
+			if oldVersion, ok := oldVersions[strings.TrimSuffix(name+":"+arch, ":")]; ok {
+				packageInfo.Version = oldVersion
+			}
 			packages = append(packages, packageInfo)
 		}
 	}
@@ -80,14 +93,14 @@ func ParseDeletedOutput(msg string, opts *manager.Options) []manager.PackageInfo
 
 	for _, line := range lines {
 		if opts.Verbose {
-			log.Printf("apt: %s", line)
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
 		}
 
 		// TODO: rewrite this using regexp
 		if strings.HasPrefix(line, "Removing") {
 			parts := strings.Fields(line)
 			if opts.Verbose {
-				log.Printf("apt: parts: %s", parts)
+				opts.Log().Debug("Fields", "package_manager", pm, "fields", parts)
 			}
 			var name, arch string
 			if strings.Contains(parts[1], ":") {
@@ -118,6 +131,65 @@ func ParseDeletedOutput(msg string, opts *manager.Options) []manager.PackageInfo
 	return packages
 }
 
+// dryRunPattern matches the lines of the simulated transaction printed by apt with --dry-run: the action, the package
+// name with its architecture if not native, the installed version in brackets, and the new version in parentheses
+// with its release and architecture.
+var dryRunPattern = regexp.MustCompile(`^(Inst|Conf|Remv|Purg) ([^\s:]+)(?::(\S+))?(?: \[([^\]]+)\])?(?: \((\S+)[^\[]*(?:\[([^\]]+)\])?\))?`)
+
+// ParseDryRunOutput parses the output of apt install, upgrade, remove and autoremove commands run with --dry-run,
+// and returns the packages the transaction would install, upgrade or remove, with their versions. Installed and
+// upgraded packages have the status they would have after the transaction, like the ones returned by
+// ParseInstallOutput, and removed packages like the ones returned by ParseDeletedOutput.
+// Example msg:
+//
+//	Inst libc6 [2.36-9+deb12u7] (2.36-9+deb12u9 Debian:12.9/stable [amd64]) []
+//	Inst nano (7.2-1+deb12u1 Debian:12.9/stable [amd64])
+//	Remv vim [2:9.0.1378-2+deb12u2]
+//	Conf libc6 (2.36-9+deb12u9 Debian:12.9/stable [amd64])
+//	Conf nano (7.2-1+deb12u1 Debian:12.9/stable [amd64])
+func ParseDryRunOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		match := dryRunPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		action, name, arch, installed, version := match[1], match[2], match[3], match[4], match[5]
+		if arch == "" {
+			arch = match[6]
+		}
+
+		// packages are configured after being unpacked, and only the ones configured alone are listed once more
+		key := name + ":" + arch
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		pkg := manager.PackageInfo{Name: name, Arch: arch, PackageManager: pm}
+		switch action {
+		case "Remv", "Purg":
+			pkg.Version = installed
+			pkg.Status = manager.PackageStatusAvailable
+		default:
+			pkg.Version, pkg.NewVersion = version, version
+			if installed != "" {
+				pkg.Version = installed
+			}
+			pkg.Status = manager.PackageStatusInstalled
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages
+}
+
 // ParseFindOutput parses the output of `apt search packageName` command
 // and returns a list of available packages that match the search query. It extracts package
 // information such as name, version, architecture, and category from the
@@ -173,17 +245,21 @@ func ParseFindOutput(msg string, opts *manager.Options) []manager.PackageInfo {
 		return packages
 	}
 
-	packages, err := getPackageStatus(packagesDict)
+	packages, err := getPackageStatus(packagesDict, opts)
 	if err != nil {
-		log.Printf("apt: getPackageStatus error: %s\n", err)
+		opts.Log().Error("Failed to get the package status", "package_manager", pm, "error", err)
 	}
 
 	return packages
 }
 
-// ParseListInstalledOutput parses the output of `dpkg-query -W -f '${binary:Package} ${Version}\n'` command
-// and returns a list of installed packages. It extracts the package name, version,
-// and architecture from the output and stores them in a list of manager.PackageInfo objects.
+// ParseListInstalledOutput parses the output of
+// `dpkg-query -W -f '${binary:Package}\t${Version}\t${Homepage}\t${Maintainer}\t${Installed-Size}\t${Architecture}\n'`
+// command and returns a list of installed packages. It extracts the package name, version, architecture, homepage,
+// maintainer and installed size, in KiB, from the output and stores them in a list of manager.PackageInfo objects.
+// The architecture is the one qualifying the names of the packages of foreign architectures, such as libc6:i386, or
+// the last column for the native ones, so that the packages installed for several architectures are told apart.
+// Lines with only the name and the version, separated by spaces, are accepted too.
 func ParseListInstalledOutput(msg string, opts *manager.Options) []manager.PackageInfo {
 	var packages []manager.PackageInfo
 
@@ -192,28 +268,7 @@ func ParseListInstalledOutput(msg string, opts *manager.Options) []manager.Packa
 	lines := strings.Split(string(msg), "\n")
 
 	for _, line := range lines {
-		if len(line) > 0 {
-			parts := strings.Fields(line)
-
-			// if name is empty, it might be not what we want
-			if parts[0] == "" {
-				continue
-			}
-			var name, arch string
-			if strings.Contains(parts[0], ":") {
-				name = strings.Split(parts[0], ":")[0]
-				arch = strings.Split(parts[0], ":")[1]
-			} else {
-				name = parts[0]
-			}
-
-			packageInfo := manager.PackageInfo{
-				Name:           name,
-				Version:        parts[1],
-				Status:         manager.PackageStatusInstalled,
-				Arch:           arch,
-				PackageManager: pm,
-			}
+		if packageInfo, ok := parseInstalledLine(line); ok {
 			packages = append(packages, packageInfo)
 		}
 	}
@@ -221,6 +276,45 @@ func ParseListInstalledOutput(msg string, opts *manager.Options) []manager.Packa
 	return packages
 }
 
+// parseInstalledLine parses a line of the output of dpkg-query, as parsed by ParseListInstalledOutput, and reports
+// whether it is a package.
+func parseInstalledLine(line string) (manager.PackageInfo, bool) {
+	parts := strings.Split(line, "\t")
+	if len(parts) == 1 {
+		parts = strings.Fields(line)
+	}
+
+	// if name is empty, it might be not what we want
+	if len(parts) < 2 || parts[0] == "" {
+		return manager.PackageInfo{}, false
+	}
+	var name, arch string
+	if strings.Contains(parts[0], ":") {
+		name = strings.Split(parts[0], ":")[0]
+		arch = strings.Split(parts[0], ":")[1]
+	} else {
+		name = parts[0]
+	}
+
+	packageInfo := manager.PackageInfo{
+		Name:           name,
+		Version:        parts[1],
+		Status:         manager.PackageStatusInstalled,
+		Arch:           arch,
+		PackageManager: pm,
+	}
+	if len(parts) >= 4 {
+		packageInfo.Homepage, packageInfo.Maintainer = parts[2], parts[3]
+	}
+	if len(parts) >= 5 {
+		packageInfo.SizeInstalled = parseInstalledSize(parts[4])
+	}
+	if len(parts) >= 6 && packageInfo.Arch == "" {
+		packageInfo.Arch = parts[5]
+	}
+	return packageInfo, true
+}
+
 // ParseListUpgradableOutput parses the output of `apt list --upgradable` command
 // and returns a list of upgradable packages. It extracts the package name, version, new version,
 // category, and architecture from the output and stores them in a list of manager.PackageInfo objects.
@@ -269,10 +363,25 @@ func ParseListUpgradableOutput(msg string, opts *manager.Options) []manager.Pack
 	return packages
 }
 
+// FilterSecurityUpdates returns the upgradable packages, as parsed by ParseListUpgradableOutput, whose new version is available
+// from a security archive: their category lists the archives providing it, such as "jammy-updates,jammy-security".
+func FilterSecurityUpdates(packages []manager.PackageInfo) []manager.PackageInfo {
+	var updates []manager.PackageInfo
+	for _, pkg := range packages {
+		for _, archive := range strings.Split(pkg.Category, ",") {
+			if strings.HasSuffix(archive, "-security") || strings.HasSuffix(archive, "/updates") {
+				updates = append(updates, pkg)
+				break
+			}
+		}
+	}
+	return updates
+}
+
 // getPackageStatus takes a map of package names and manager.PackageInfo objects, and returns a list
 // of manager.PackageInfo objects with their statuses updated using the output of `dpkg-query` command.
 // It also adds any packages not found by dpkg-query to the list with their status set to unknown.
-func getPackageStatus(packages map[string]manager.PackageInfo) ([]manager.PackageInfo, error) {
+func getPackageStatus(packages map[string]manager.PackageInfo, opts *manager.Options) ([]manager.PackageInfo, error) {
 	var packageNames []string
 	var packagesList []manager.PackageInfo
 
@@ -286,11 +395,11 @@ func getPackageStatus(packages map[string]manager.PackageInfo) ([]manager.Packag
 
 	args := []string{"-W", "--showformat", "${binary:Package} ${Status} ${Version}\n"}
 	args = append(args, packageNames...)
-	cmd := exec.Command("dpkg-query", args...)
-	cmd.Env = ENV_NonInteractive
+	cmd := manager.Command(opts, "dpkg-query", args...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
 
 	// dpkg-query might exit with status 1, which is not an error when some packages are not found
-	out, err := cmd.CombinedOutput()
+	out, err := manager.CombinedOutput(cmd)
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if exitErr.ExitCode() != 1 && !strings.Contains(string(out), "no packages found matching") {
@@ -299,14 +408,14 @@ func getPackageStatus(packages map[string]manager.PackageInfo) ([]manager.Packag
 		}
 	}
 
-	packagesList, err = ParseDpkgQueryOutput(out, packages)
+	packagesList, err = ParseDpkgQueryOutputWithOptions(out, packages, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse dpkg-query output: %+v", err)
 	}
 
 	// for all the packages that are not found, set their status to unknown, if any
 	for _, pkg := range packages {
-		fmt.Printf("apt: package not found by dpkg-query: %s", pkg.Name)
+		opts.Log().Debug("Package not found by dpkg-query", "package_manager", pm, "package", pkg.Name)
 		pkg.Status = manager.PackageStatusUnknown
 		packagesList = append(packagesList, pkg)
 	}
@@ -318,6 +427,11 @@ func getPackageStatus(packages map[string]manager.PackageInfo) ([]manager.Packag
 // and version of the packages in the provided map of package names and manager.PackageInfo objects.
 // It returns a list of manager.PackageInfo objects with their statuses and versions updated.
 func ParseDpkgQueryOutput(output []byte, packages map[string]manager.PackageInfo) ([]manager.PackageInfo, error) {
+	return ParseDpkgQueryOutputWithOptions(output, packages, nil)
+}
+
+// ParseDpkgQueryOutputWithOptions is ParseDpkgQueryOutput logging through the logger of opts.
+func ParseDpkgQueryOutputWithOptions(output []byte, packages map[string]manager.PackageInfo, opts *manager.Options) ([]manager.PackageInfo, error) {
 	var packagesList []manager.PackageInfo
 
 	// remove the last empty line
@@ -384,7 +498,7 @@ func ParseDpkgQueryOutput(output []byte, packages map[string]manager.PackageInfo
 
 			packagesList = append(packagesList, pkg)
 		} else {
-			log.Println("apt: line is empty")
+			opts.Log().Debug("Empty line in the dpkg-query output", "package_manager", pm)
 		}
 	}
 
@@ -393,7 +507,9 @@ func ParseDpkgQueryOutput(output []byte, packages map[string]manager.PackageInfo
 
 // ParsePackageInfoOutput parses the output of `apt-cache show packageName` command
 // and returns a manager.PackageInfo object containing package information such as name, version,
-// architecture, and category. This function is useful for getting detailed package information.
+// architecture, category, homepage, maintainer, installed and download sizes, and license for the repositories
+// providing it.
+// This function is useful for getting detailed package information.
 func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageInfo {
 	var pkg manager.PackageInfo
 
@@ -421,6 +537,18 @@ func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageIn
 				pkg.Arch = value
 			case "Section":
 				pkg.Category = value
+			case "Homepage":
+				pkg.Homepage = value
+			case "Maintainer":
+				pkg.Maintainer = value
+			case "License":
+				pkg.License = value
+			case "Installed-Size":
+				pkg.SizeInstalled = parseInstalledSize(value)
+			case "Size":
+				pkg.SizeDownload, _ = strconv.ParseInt(value, 10, 64)
+			case "Download-Size":
+				pkg.SizeDownload, _ = manager.ParseSize(value)
 			}
 		}
 	}
@@ -429,3 +557,851 @@ func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageIn
 
 	return pkg
 }
+
+// ParseCopyrightLicense parses a machine-readable copyright file, as installed by Debian packages in
+// /usr/share/doc/packageName/copyright, and returns the license of the files of the package, declared by its
+// "Files: *" paragraph. It returns an empty string if the file is not machine-readable, or has no such paragraph.
+// Example content:
+//
+//	Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+//	Upstream-Name: bash
+//
+//	Files: *
+//	Copyright: (C) 1987-2022 Free Software Foundation, Inc.
+//	License: GPL-3+
+//
+//	Files: examples/shellmath/*
+//	License: BSD-3-clause
+func ParseCopyrightLicense(content string) string {
+	if !strings.HasPrefix(content, "Format:") {
+		return ""
+	}
+
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		var files, license string
+		for _, line := range strings.Split(paragraph, "\n") {
+			key, value, found := strings.Cut(line, ":")
+			// continuation lines, such as the text of the license, start with a space
+			if !found || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				continue
+			}
+			switch key {
+			case "Files":
+				files = strings.TrimSpace(value)
+			case "License":
+				license = strings.TrimSpace(value)
+			}
+		}
+		if files == "*" {
+			return license
+		}
+	}
+	return ""
+}
+
+// ParseDependsOutput parses the output of `apt-cache depends packageName` command and returns the direct dependencies of the package.
+// The dependency type (Depends or PreDepends) is kept in AdditionalData["type"]. Virtual packages, shown in angle brackets,
+// are returned by name with AdditionalData["virtual"] set, and the packages providing them are skipped.
+// Example msg:
+//
+//	vim
+//	  Depends: vim-common
+//	  Depends: vim-runtime
+//	 |Depends: libgpm2
+//	  Depends: <awk>
+//	    mawk
+//	  PreDepends: libc6
+func ParseDependsOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	seen := make(map[string]bool)
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		parts := strings.SplitN(strings.TrimLeft(line, " |"), ": ", 2)
+		if len(parts) != 2 || (parts[0] != "Depends" && parts[0] != "PreDepends") {
+			continue
+		}
+
+		name := strings.TrimSpace(parts[1])
+		additionalData := map[string]string{"type": parts[0]}
+		if strings.HasPrefix(name, "<") && strings.HasSuffix(name, ">") {
+			name = strings.Trim(name, "<>")
+			additionalData["virtual"] = "true"
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Status:         manager.PackageStatusUnknown,
+			PackageManager: pm,
+			AdditionalData: additionalData,
+		})
+	}
+
+	return packages
+}
+
+// ParsePolicyOutput parses the output of `apt-cache policy` command, without packages, and returns the repositories
+// packages are installed from, with the origin of their Release file (o=), or their host if they have none. The
+// repositories are named by their URL and distribution, such as "http://deb.debian.org/debian bookworm/main", as
+// returned by ParsePackagePolicyOutput, and titled by the label of their Release file (l=). The dpkg status file,
+// listing the installed packages, isn't a repository.
+// Example msg:
+//
+//	Package files:
+//	 100 /var/lib/dpkg/status
+//	     release a=now
+//	 500 https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main amd64 Packages
+//	     release v=22.04,o=LP-PPA-deadsnakes,a=jammy,n=jammy,l=New Python Versions,c=main,b=amd64
+//	     origin ppa.launchpadcontent.net
+//	 500 http://archive.ubuntu.com/ubuntu jammy/main amd64 Packages
+//	     release v=22.04,o=Ubuntu,a=jammy,n=jammy,l=Ubuntu,c=main,b=amd64
+//	     origin archive.ubuntu.com
+//	Pinned packages:
+func ParsePolicyOutput(msg string, opts *manager.Options) []manager.RepositoryInfo {
+	var repos []manager.RepositoryInfo
+	seen := make(map[string]bool)
+	var repo *manager.RepositoryInfo
+
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+		if strings.HasPrefix(line, "Pinned packages:") {
+			break
+		}
+
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 3 && strings.Contains(fields[1], "://"):
+			repo = nil
+			// the repositories are listed once per architecture
+			if name := fields[1] + " " + fields[2]; !seen[name] {
+				seen[name] = true
+				repos = append(repos, manager.RepositoryInfo{Name: name, URL: fields[1], Enabled: true, PackageManager: pm})
+				repo = &repos[len(repos)-1]
+			}
+		case len(fields) >= 2 && fields[0] == "release" && repo != nil:
+			// the values may contain spaces, such as l=New Python Versions
+			for _, field := range strings.Split(strings.TrimPrefix(strings.TrimSpace(line), "release "), ",") {
+				key, value, _ := strings.Cut(field, "=")
+				switch key {
+				case "o":
+					repo.Origin = value
+				case "l":
+					repo.Title = value
+				}
+			}
+		case len(fields) == 2 && fields[0] == "origin" && repo != nil && repo.Origin == "":
+			repo.Origin = fields[1]
+		}
+	}
+
+	return repos
+}
+
+// ParsePackagePolicyOutput parses the output of `apt-cache policy packageName` command, and returns the name of the
+// repository the installed version of the package comes from, or the candidate version if it isn't installed, as
+// named by ParsePolicyOutput. It returns an empty name if the version is in no repository, such as a package
+// installed from a local file, or one no longer available.
+// Example msg:
+//
+//	python3.12:
+//	  Installed: 3.12.4-1+jammy1
+//	  Candidate: 3.12.4-1+jammy1
+//	  Version table:
+//	 *** 3.12.4-1+jammy1 500
+//	        500 https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main amd64 Packages
+//	        100 /var/lib/dpkg/status
+func ParsePackagePolicyOutput(msg string, opts *manager.Options) string {
+	for _, source := range ParsePackagesPolicyOutput(msg, opts) {
+		return source
+	}
+	return ""
+}
+
+// ParsePackagesPolicyOutput parses the output of `apt-cache policy packageName...` command, with several packages, and
+// returns the names of the repositories their versions come from, as ParsePackagePolicyOutput does, by package name.
+// The packages whose version is in no repository are left out.
+func ParsePackagesPolicyOutput(msg string, opts *manager.Options) map[string]string {
+	sources := make(map[string]string)
+	var name, wanted, version string
+
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+		if !strings.HasPrefix(line, " ") && strings.HasSuffix(line, ":") {
+			name, wanted, version = strings.TrimSuffix(line, ":"), "", ""
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "*** "))
+		switch {
+		case len(fields) == 2 && fields[0] == "Installed:" && fields[1] != "(none)":
+			wanted = fields[1]
+		case len(fields) == 2 && fields[0] == "Candidate:" && wanted == "":
+			wanted = fields[1]
+		case len(fields) >= 2 && (strings.HasPrefix(fields[1], "/") || strings.Contains(fields[1], "://")):
+			// a source of the version, with its priority: a repository, or the dpkg status file
+			if _, found := sources[name]; !found && version == wanted && len(fields) >= 3 {
+				sources[name] = fields[1] + " " + fields[2]
+			}
+		case len(fields) == 2:
+			// a version of the version table, with its priority
+			version = fields[0]
+		}
+	}
+
+	return sources
+}
+
+// ParseArchiveOrigins parses the output of `apt-cache policy` command, without packages, and returns the origins of
+// the repositories, as returned by ParsePolicyOutput, by archive (a=) and codename (n=), the ones `apt list` tells the
+// versions of the packages to be in, such as "jammy" or "stable". The archives of several origins, such as the
+// "jammy" of both Ubuntu and a PPA, are ambiguous, and returned apart.
+// Example msg:
+//
+//	Package files:
+//	 100 /var/lib/dpkg/status
+//	     release a=now
+//	 500 https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main amd64 Packages
+//	     release v=22.04,o=LP-PPA-deadsnakes,a=jammy,n=jammy,l=New Python Versions,c=main,b=amd64
+//	     origin ppa.launchpadcontent.net
+//	 500 https://deb.nodesource.com/node_20.x nodistro/main amd64 Packages
+//	     release o=. nodistro,a=nodistro,n=nodistro,l=. nodistro,c=main,b=amd64
+//	     origin deb.nodesource.com
+func ParseArchiveOrigins(msg string) (origins map[string]string, ambiguous map[string]bool) {
+	origins, ambiguous = make(map[string]string), make(map[string]bool)
+	var archives []string
+	var origin string
+	var inRepository bool
+
+	add := func() {
+		if origin == "" {
+			archives = nil
+		}
+		for _, archive := range archives {
+			if previous, found := origins[archive]; found && previous != origin {
+				ambiguous[archive] = true
+			}
+			origins[archive] = origin
+		}
+		archives, origin, inRepository = nil, "", false
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if strings.HasPrefix(line, "Pinned packages:") {
+			break
+		}
+
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && strings.HasPrefix(fields[1], "/"):
+			// the dpkg status file, listing the installed packages, isn't a repository
+			add()
+		case len(fields) >= 3 && strings.Contains(fields[1], "://"):
+			add()
+			inRepository = true
+		case len(fields) >= 2 && fields[0] == "release" && inRepository:
+			for _, field := range strings.Split(strings.TrimPrefix(strings.TrimSpace(line), "release "), ",") {
+				key, value, _ := strings.Cut(field, "=")
+				switch key {
+				case "o":
+					origin = value
+				case "a", "n":
+					archives = append(archives, value)
+				}
+			}
+		case len(fields) == 2 && fields[0] == "origin" && inRepository && origin == "":
+			origin = fields[1]
+		}
+	}
+	add()
+
+	for archive := range ambiguous {
+		delete(origins, archive)
+	}
+	return origins, ambiguous
+}
+
+// ParseListOriginsOutput parses the output of `apt list --installed` command, and returns the origins of the installed
+// packages by name, from the origins of the archives their installed version is in, as returned by
+// ParseArchiveOrigins. The packages only in ambiguous archives are returned apart, to be looked up with
+// ParsePackagesPolicyOutput, and the ones in no archive, such as the ones installed from files, are left out.
+// Example msg:
+//
+//	Listing...
+//	nodejs/nodistro,now 20.19.5-1nodesource1 amd64 [installed]
+//	python3.12/jammy,now 3.12.4-1+jammy1 amd64 [installed]
+//	zoom/now 6.1.1.443 amd64 [installed,local]
+func ParseListOriginsOutput(msg string, archives map[string]string, ambiguous map[string]bool, opts *manager.Options) (origins map[string]string, unresolved []string) {
+	origins = make(map[string]string)
+
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name, list, found := strings.Cut(fields[0], "/")
+		if !found {
+			continue
+		}
+		var isAmbiguous bool
+		for _, archive := range strings.Split(list, ",") {
+			if origin, found := archives[archive]; found {
+				origins[name] = origin
+				break
+			}
+			isAmbiguous = isAmbiguous || ambiguous[archive]
+		}
+		if _, found := origins[name]; !found && isAmbiguous {
+			unresolved = append(unresolved, name)
+		}
+	}
+
+	return origins, unresolved
+}
+
+// ParseTrustedSources parses an apt sources file, either in the one-line format of the .list files or in the deb822
+// format of the .sources files, and returns the URIs of its repositories marked trusted, whose packages are installed
+// without verifying the signature of their Release file. The URIs have no trailing slash.
+// Example content:
+//
+//	deb [trusted=yes] http://repo.example.com/debian/ ./
+//	deb [arch=amd64 signed-by=/etc/apt/keyrings/docker.asc] https://download.docker.com/linux/debian bookworm stable
+//
+//	Types: deb
+//	URIs: http://mirror.example.com/internal
+//	Suites: bookworm
+//	Trusted: yes
+func ParseTrustedSources(content string) []string {
+	var uris []string
+	var paragraph []string
+	var trusted bool
+
+	endParagraph := func() {
+		if trusted {
+			uris = append(uris, paragraph...)
+		}
+		paragraph, trusted = nil, false
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			endParagraph()
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch {
+		case fields[0] == "deb" || fields[0] == "deb-src":
+			// the options are in brackets, such as [arch=amd64 trusted=yes], and followed by the URI
+			rest := strings.TrimSpace(strings.TrimPrefix(line, fields[0]))
+			var options string
+			if strings.HasPrefix(rest, "[") {
+				options, rest, _ = strings.Cut(strings.TrimPrefix(rest, "["), "]")
+			}
+			if uri := strings.Fields(rest); len(uri) > 0 && slices.Contains(strings.Fields(options), "trusted=yes") {
+				uris = append(uris, strings.TrimSuffix(uri[0], "/"))
+			}
+		default:
+			key, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			switch strings.ToLower(key) {
+			case "uris":
+				for _, uri := range strings.Fields(value) {
+					paragraph = append(paragraph, strings.TrimSuffix(uri, "/"))
+				}
+			case "trusted":
+				trusted = strings.TrimSpace(value) == "yes"
+			}
+		}
+	}
+	endParagraph()
+
+	return uris
+}
+
+// ParseReverseDependsOutput parses the output of `apt-cache rdepends --installed packageName` command
+// and returns the installed packages that depend on the package.
+// Example msg:
+//
+//	libgpm2
+//	Reverse Depends:
+//	 |vim
+//	  libncurses6
+//	  vim
+func ParseReverseDependsOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	seen := make(map[string]bool)
+	inSection := false
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if line == "Reverse Depends:" {
+			inSection = true
+			continue
+		}
+		if !inSection || !strings.HasPrefix(line, " ") {
+			continue
+		}
+
+		name := strings.TrimLeft(line, " |")
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseOwnsOutput parses the output of `dpkg -S path` command and returns the packages owning the path.
+// Diversion notes are skipped.
+// Example msg:
+//
+//	diversion by dash from: /bin/sh
+//	diversion by dash to: /bin/sh.distrib
+//	dash: /bin/sh
+//	libc6:amd64, libc6:i386: /usr/share/doc/libc6
+func ParseOwnsOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if strings.HasPrefix(line, "diversion by ") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ": ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		for _, owner := range strings.Split(parts[0], ", ") {
+			name, arch, _ := strings.Cut(owner, ":")
+			packages = append(packages, manager.PackageInfo{
+				Name:           name,
+				Arch:           arch,
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+			})
+		}
+	}
+
+	return packages
+}
+
+// ParseListFilesOutput parses the output of `dpkg -L packageName` command and returns the installed paths.
+// The root directory and diversion notes are skipped.
+// Example msg:
+//
+//	/.
+//	/usr
+//	/usr/bin
+//	/usr/bin/vim.basic
+//	diverted by foo to: /usr/bin/vim.basic.real
+func ParseListFilesOutput(msg string, opts *manager.Options) []string {
+	var files []string
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if !strings.HasPrefix(line, "/") || line == "/." {
+			continue
+		}
+		files = append(files, line)
+	}
+
+	return files
+}
+
+// ParseMarkOutput parses the output of `apt-mark hold` and `apt-mark unhold` commands and returns the marked packages,
+// with their status set to held, or installed once their hold is released.
+// Example msg:
+//
+//	vim set on hold.
+//	curl was already set on hold.
+//	Canceled hold on nano.
+//	htop was already not on hold.
+func ParseMarkOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		var name string
+		status := manager.PackageStatusHeld
+		switch {
+		case strings.HasSuffix(line, " was already set on hold."):
+			name = strings.TrimSuffix(line, " was already set on hold.")
+		case strings.HasSuffix(line, " set on hold."):
+			name = strings.TrimSuffix(line, " set on hold.")
+		case strings.HasSuffix(line, " was already not on hold."):
+			name, status = strings.TrimSuffix(line, " was already not on hold."), manager.PackageStatusInstalled
+		case strings.HasPrefix(line, "Canceled hold on "):
+			name, status = strings.TrimSuffix(strings.TrimPrefix(line, "Canceled hold on "), "."), manager.PackageStatusInstalled
+		default:
+			continue
+		}
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Status:         status,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseListTasksOutput parses the output of `tasksel --list-tasks` command and returns the tasks, installed ("i")
+// or not ("u"), with their description.
+// Example msg:
+//
+//	u desktop	Debian desktop environment
+//	u web-server	web server
+//	i ssh-server	SSH server
+//	i standard	standard system utilities
+func ParseListTasksOutput(msg string, opts *manager.Options) []manager.GroupInfo {
+	var groups []manager.GroupInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		status, task, found := strings.Cut(line, " ")
+		if !found || (status != "i" && status != "u") {
+			continue
+		}
+		name, description, _ := strings.Cut(task, "\t")
+
+		groups = append(groups, manager.GroupInfo{
+			Name:           strings.TrimSpace(name),
+			Description:    strings.TrimSpace(description),
+			Installed:      status == "i",
+			PackageManager: pm,
+		})
+	}
+
+	return groups
+}
+
+// ParseShowKeysOutput parses the output of `gpg --show-keys --with-colons keyring` command and returns the keys of the keyring,
+// identified by the fingerprint of their primary key, with their first user ID.
+// Example msg:
+//
+//	pub:-:4096:1:8D81803C0EBFCD88:1487788586:::-:::scESA::::::23::0:
+//	fpr:::::::::9DC858229FC7DD38854AE2D88D81803C0EBFCD88:
+//	uid:-::::1487792064::B5A08F01796E7F521861B449372D1FF271F2DD50::Docker Release (CE deb) <docker@docker.com>::::::::::0:
+//	sub:-:4096:1:7EA0A9C3F273FCD8:1487788586::::::s::::::23:
+//	fpr:::::::::D3306A018370199E527AE7317EA0A9C3F273FCD8:
+func ParseShowKeysOutput(msg string, keyring string, opts *manager.Options) []manager.KeyInfo {
+	var keys []manager.KeyInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	// the fingerprint and user ID records following a pub record belong to its primary key, until a sub record
+	inPrimary := false
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		fields := strings.Split(line, ":")
+		if len(fields) < 10 {
+			continue
+		}
+
+		switch fields[0] {
+		case "pub":
+			keys = append(keys, manager.KeyInfo{Keyring: keyring, PackageManager: pm})
+			inPrimary = true
+		case "sub", "ssb":
+			inPrimary = false
+		case "fpr":
+			if inPrimary && keys[len(keys)-1].ID == "" {
+				keys[len(keys)-1].ID = fields[9]
+			}
+		case "uid":
+			if inPrimary && keys[len(keys)-1].UserID == "" {
+				keys[len(keys)-1].UserID = fields[9]
+			}
+		}
+	}
+
+	return keys
+}
+
+// ParseDownloadOutput parses the output of `apt install --download-only packageName` command and returns the downloaded packages,
+// with the path of their file in dir (the archives directory of apt) in AdditionalData["path"].
+// It extracts the package name, architecture and version from the lines that start with "Get:".
+// Example msg:
+//
+//	Need to get 8,592 kB of archives.
+//	Get:1 http://deb.debian.org/debian bookworm/main amd64 vim-runtime all 2:9.0.1378-2 [7,025 kB]
+//	Get:2 http://deb.debian.org/debian bookworm/main amd64 vim amd64 2:9.0.1378-2 [1,567 kB]
+//	Fetched 8,592 kB in 1s (10.1 MB/s)
+//	Download complete and in download only mode
+func ParseDownloadOutput(msg string, dir string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if !strings.HasPrefix(line, "Get:") {
+			continue
+		}
+		// remove the download size
+		if idx := strings.LastIndex(line, " ["); idx > 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		name, arch, version := fields[len(fields)-3], fields[len(fields)-2], fields[len(fields)-1]
+
+		// the epoch separator of the version is escaped in the file names of apt
+		file := fmt.Sprintf("%s_%s_%s.deb", name, strings.ReplaceAll(version, ":", "%3a"), arch)
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Version:        version,
+			Arch:           arch,
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: pm,
+			AdditionalData: map[string]string{"path": filepath.Join(dir, file)},
+		})
+	}
+
+	return packages
+}
+
+// planSections are the actions of the packages listed by apt under each heading of its summary of a transaction.
+// The packages of other headings, such as suggested packages, are not changed.
+var planSections = map[string]manager.PlanAction{
+	"The following NEW packages will be installed:": manager.PlanActionInstall,
+	"The following packages will be upgraded:":      manager.PlanActionUpgrade,
+	"The following packages will be DOWNGRADED:":    manager.PlanActionDowngrade,
+	"The following packages will be REMOVED:":       manager.PlanActionRemove,
+}
+
+// ParsePlanOutput parses the output of `apt install|upgrade|remove -V --print-uris` commands, and returns the plan of the
+// transaction: the packages it installs, upgrades, downgrades and removes, with their versions and download sizes,
+// and the total download size and disk space used.
+// Example msg:
+//
+//	The following NEW packages will be installed:
+//	   nano (7.2-1+deb12u1)
+//	The following packages will be upgraded:
+//	   libc6:amd64 (2.36-9+deb12u7 => 2.36-9+deb12u9)
+//	The following packages will be REMOVED:
+//	   vim* (2:9.0.1378-2+deb12u2)
+//	1 upgraded, 1 newly installed, 1 to remove and 0 not upgraded.
+//	Need to get 3,512 kB of archives.
+//	After this operation, 2871 kB of additional disk space will be used.
+//	'http://deb.debian.org/debian/pool/main/n/nano/nano_7.2-1%2bdeb12u1_amd64.deb' nano_7.2-1+deb12u1_amd64.deb 689556 MD5Sum:03eeaaa603022ccbd061c8a4751fc947
+func ParsePlanOutput(msg string, opts *manager.Options) *manager.Plan {
+	plan := &manager.Plan{PackageManager: pm, Entries: []manager.PlanEntry{}}
+	sizes := make(map[string]int64)
+
+	// packages are listed one per line with -V, and several per line otherwise
+	entryPattern := regexp.MustCompile(`^([^\s:*(]+)(?::(\S+?))?\*?(?:\s+\((.+?)(?: => (.+?))?\))?$`)
+	var action manager.PlanAction
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		switch {
+		case strings.HasPrefix(line, " "):
+			if action == "" {
+				continue
+			}
+			items := []string{strings.TrimSpace(line)}
+			if !strings.Contains(line, "(") {
+				items = strings.Fields(line)
+			}
+			for _, item := range items {
+				match := entryPattern.FindStringSubmatch(item)
+				if match == nil {
+					continue
+				}
+				entry := manager.PlanEntry{Action: action, Name: match[1], Arch: match[2]}
+				switch {
+				case match[4] != "":
+					entry.Version, entry.NewVersion = match[3], match[4]
+				case action == manager.PlanActionRemove:
+					entry.Version = match[3]
+				default:
+					entry.NewVersion = match[3]
+				}
+				plan.Entries = append(plan.Entries, entry)
+			}
+			continue
+		case strings.HasPrefix(line, "Need to get "):
+			// "Need to get 1,024 kB/3,512 kB of archives." when some archives are already downloaded
+			size, _, _ := strings.Cut(strings.TrimPrefix(line, "Need to get "), "/")
+			plan.DownloadSize, _ = manager.ParseSize(strings.TrimSuffix(size, " of archives."))
+		case strings.HasPrefix(line, "After this operation, "):
+			size := strings.TrimPrefix(line, "After this operation, ")
+			if freed := strings.HasSuffix(size, " disk space will be freed."); freed {
+				n, _ := manager.ParseSize(strings.TrimSuffix(size, " disk space will be freed."))
+				plan.DiskSpace = -n
+			} else {
+				plan.DiskSpace, _ = manager.ParseSize(strings.TrimSuffix(size, " of additional disk space will be used."))
+			}
+		case strings.HasPrefix(line, "'"):
+			// 'uri' name_version_arch.deb size hash
+			if fields := strings.Fields(line); len(fields) >= 3 {
+				name, _, _ := strings.Cut(fields[1], "_")
+				sizes[name], _ = strconv.ParseInt(fields[2], 10, 64)
+			}
+		}
+		action = planSections[line]
+	}
+
+	for i, entry := range plan.Entries {
+		plan.Entries[i].DownloadSize = sizes[entry.Name]
+	}
+	return plan
+}
+
+// ParseStatusLine parses a progress line written by apt to its status file descriptor (`-o APT::Status-Fd=1`),
+// and returns the progress event it reports. ok is false for lines that are not progress lines.
+// Example lines:
+//
+//	dlstatus:1:9.0909:Retrieving file 1 of 3
+//	pmstatus:dpkg-exec:0.0000:Running dpkg
+//	pmstatus:libssl3:amd64:20.0000:Preparing libssl3:amd64 (amd64)
+//	pmstatus:vim:85.7143:Removing vim (amd64)
+//	pmerror:/var/cache/apt/archives/vim_2%3a8.2.3995-1ubuntu2.15_amd64.deb:50.0000:trying to overwrite '/usr/bin/vim'
+func ParseStatusLine(line string) (event manager.ProgressEvent, ok bool) {
+	fields := strings.Split(line, ":")
+	if len(fields) < 4 {
+		return event, false
+	}
+
+	switch fields[0] {
+	case "dlstatus":
+		event.Phase = manager.ProgressPhaseDownload
+	case "pmstatus":
+		event.Phase = manager.ProgressPhaseInstall
+	case "pmerror":
+		event.Phase = manager.ProgressPhaseError
+	default:
+		return event, false
+	}
+
+	// the item may contain colons (e.g. libssl3:amd64), but the percentage is the first number after it
+	for i := 2; i < len(fields)-1; i++ {
+		percent, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			continue
+		}
+
+		event.PackageManager = pm
+		event.Percent = percent
+		event.Message = strings.Join(fields[i+1:], ":")
+		if event.Phase != manager.ProgressPhaseDownload {
+			// dlstatus items are indexes, and dpkg-exec is not a package
+			if item := strings.Join(fields[1:i], ":"); item != "dpkg-exec" {
+				event.Package = item
+			}
+		}
+		if event.Phase == manager.ProgressPhaseInstall && (strings.HasPrefix(event.Message, "Removing") || strings.HasPrefix(event.Message, "Removed")) {
+			event.Phase = manager.ProgressPhaseRemove
+		}
+		return event, true
+	}
+	return manager.ProgressEvent{}, false
+}
+
+// parseInstalledSize parses the Installed-Size field of Debian packages, in KiB, or a size with its unit, as printed
+// by apt show, and returns it in bytes, or 0 if it is not a size.
+func parseInstalledSize(s string) int64 {
+	if n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil {
+		return n << 10
+	}
+	n, _ := manager.ParseSize(s)
+	return n
+}
+
+// markHeld sets the status of the packages that are held to held.
+func markHeld(packages []manager.PackageInfo, held []manager.PackageInfo) []manager.PackageInfo {
+	isHeld := make(map[string]bool)
+	for _, pkg := range held {
+		isHeld[pkg.Name] = true
+	}
+
+	for i := range packages {
+		if isHeld[packages[i].Name] {
+			packages[i].Status = manager.PackageStatusHeld
+		}
+	}
+	return packages
+}
@@ -57,6 +57,22 @@ func TestParseInstallOutput(t *testing.T) {
 	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
 		t.Errorf("ParseInstallOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
 	}
+
+	// upgraded packages are unpacked over the installed version
+	actualPackageInfo = apt.ParseInstallOutput(strings.Join([]string{
+		`Preparing to unpack .../libssl3_3.0.2-0ubuntu1.9_amd64.deb ...`,
+		`Unpacking libssl3:amd64 (3.0.2-0ubuntu1.9) over (3.0.2-0ubuntu1.8) ...`,
+		`Unpacking openssl (3.0.2-0ubuntu1.9) over (3.0.2-0ubuntu1.8) ...`,
+		`Setting up libssl3:amd64 (3.0.2-0ubuntu1.9) ...`,
+		`Setting up openssl (3.0.2-0ubuntu1.9) ...`,
+	}, "\n"), &manager.Options{})
+	expectedPackageInfo = []manager.PackageInfo{
+		{Name: "libssl3", Arch: "amd64", Version: "3.0.2-0ubuntu1.8", NewVersion: "3.0.2-0ubuntu1.9", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "openssl", Version: "3.0.2-0ubuntu1.8", NewVersion: "3.0.2-0ubuntu1.9", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+	}
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseInstallOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
 }
 
 func TestParseDeletedOutput(t *testing.T) {
@@ -115,6 +131,44 @@ func TestParseDeletedOutput(t *testing.T) {
 	}
 }
 
+func TestParseDryRunOutput(t *testing.T) {
+	var inputParseDryRunOutput string = strings.Join([]string{
+		`Reading package lists...`,
+		`The following packages will be REMOVED:`,
+		`  vim`,
+		`1 upgraded, 2 newly installed, 1 to remove and 0 not upgraded.`,
+		`Inst libc6 [2.36-9+deb12u7] (2.36-9+deb12u9 Debian:12.9/stable [amd64]) []`,
+		`Inst libc6:i386 (2.36-9+deb12u9 Debian:12.9/stable [i386])`,
+		`Inst nano (7.2-1+deb12u1 Debian:12.9/stable [amd64])`,
+		`Remv vim [2:9.0.1378-2+deb12u2]`,
+		`Purg less [590-2.1~deb12u2]`,
+		`Conf libc6 (2.36-9+deb12u9 Debian:12.9/stable [amd64])`,
+		`Conf libc6:i386 (2.36-9+deb12u9 Debian:12.9/stable [i386])`,
+		`Conf nano (7.2-1+deb12u1 Debian:12.9/stable [amd64])`,
+	}, "\n")
+
+	expectedPackageInfo := []manager.PackageInfo{
+		{Name: "libc6", Version: "2.36-9+deb12u7", NewVersion: "2.36-9+deb12u9", Arch: "amd64", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "libc6", Version: "2.36-9+deb12u9", NewVersion: "2.36-9+deb12u9", Arch: "i386", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "nano", Version: "7.2-1+deb12u1", NewVersion: "7.2-1+deb12u1", Arch: "amd64", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "vim", Version: "2:9.0.1378-2+deb12u2", Status: manager.PackageStatusAvailable, PackageManager: "apt"},
+		{Name: "less", Version: "590-2.1~deb12u2", Status: manager.PackageStatusAvailable, PackageManager: "apt"},
+	}
+
+	actualPackageInfo := apt.ParseDryRunOutput(inputParseDryRunOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDryRunOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+
+	// the plan of the packages is the one printed by apt
+	expectedPlan := []manager.PlanAction{manager.PlanActionUpgrade, manager.PlanActionInstall, manager.PlanActionInstall}
+	for i, entry := range manager.NewPlan("apt", manager.PlanActionInstall, actualPackageInfo[:3]).Entries {
+		if entry.Action != expectedPlan[i] {
+			t.Errorf("NewPlan() entry %d = %+v, want %s", i, entry, expectedPlan[i])
+		}
+	}
+}
+
 func TestParseFindOutput(t *testing.T) {
 	var inputParseSearchOutput string = strings.Join([]string{
 		`Sorting...`,
@@ -162,7 +216,10 @@ func TestParseInstalledOutput(t *testing.T) {
 	var inputParseInstalledOutput = strings.Join([]string{
 		`bind9-libs:amd64 1:9.18.12-0ubuntu0.22.04.1`,
 		`binfmt-support 2.2.1-2`,
-		`binutils 2.38-4ubuntu2.1`,
+		"binutils\t2.38-4ubuntu2.1\thttps://www.gnu.org/software/binutils/\tUbuntu Core developers <ubuntu-devel-discuss@lists.ubuntu.com>\t26242",
+		// a package installed for several architectures, only the foreign one being qualified
+		"libc6\t2.35-0ubuntu3.8\thttps://www.gnu.org/software/libc/libc.html\tUbuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>\t13303\tamd64",
+		"libc6:i386\t2.35-0ubuntu3.8\thttps://www.gnu.org/software/libc/libc.html\tUbuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>\t12430\ti386",
 	}, "\n")
 
 	var expectedPackageInfo = []manager.PackageInfo{
@@ -191,6 +248,29 @@ func TestParseInstalledOutput(t *testing.T) {
 			Status:         manager.PackageStatusInstalled,
 			Category:       "",
 			Arch:           "",
+			Homepage:       "https://www.gnu.org/software/binutils/",
+			Maintainer:     "Ubuntu Core developers <ubuntu-devel-discuss@lists.ubuntu.com>",
+			SizeInstalled:  26871808,
+			PackageManager: "apt",
+		},
+		{
+			Name:           "libc6",
+			Version:        "2.35-0ubuntu3.8",
+			Status:         manager.PackageStatusInstalled,
+			Arch:           "amd64",
+			Homepage:       "https://www.gnu.org/software/libc/libc.html",
+			Maintainer:     "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>",
+			SizeInstalled:  13622272,
+			PackageManager: "apt",
+		},
+		{
+			Name:           "libc6",
+			Version:        "2.35-0ubuntu3.8",
+			Status:         manager.PackageStatusInstalled,
+			Arch:           "i386",
+			Homepage:       "https://www.gnu.org/software/libc/libc.html",
+			Maintainer:     "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>",
+			SizeInstalled:  12728320,
 			PackageManager: "apt",
 		},
 	}
@@ -270,6 +350,11 @@ func TestParsePackageInfoOutput(t *testing.T) {
 		Status:         "",
 		Category:       "default",
 		Arch:           "",
+		License:        "Apache License Version 2.0",
+		Homepage:       "https://github.com/cloudflare/cloudflared",
+		Maintainer:     "Cloudflare <support@cloudflare.com>",
+		SizeInstalled:  36100000,
+		SizeDownload:   17500000,
 		PackageManager: "apt",
 	}
 
@@ -336,3 +421,473 @@ func TestParseDpkgQueryOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDependsOutput(t *testing.T) {
+	var inputParseDependsOutput string = strings.Join([]string{
+		`vim`,
+		`  Depends: vim-common`,
+		`  Depends: vim-runtime`,
+		` |Depends: libgpm2`,
+		`  Depends: <awk>`,
+		`    mawk`,
+		`    original-awk`,
+		`  PreDepends: libc6`,
+		`  Depends: vim-common`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "vim-common", Status: manager.PackageStatusUnknown, PackageManager: "apt", AdditionalData: map[string]string{"type": "Depends"}},
+		{Name: "vim-runtime", Status: manager.PackageStatusUnknown, PackageManager: "apt", AdditionalData: map[string]string{"type": "Depends"}},
+		{Name: "libgpm2", Status: manager.PackageStatusUnknown, PackageManager: "apt", AdditionalData: map[string]string{"type": "Depends"}},
+		{Name: "awk", Status: manager.PackageStatusUnknown, PackageManager: "apt", AdditionalData: map[string]string{"type": "Depends", "virtual": "true"}},
+		{Name: "libc6", Status: manager.PackageStatusUnknown, PackageManager: "apt", AdditionalData: map[string]string{"type": "PreDepends"}},
+	}
+
+	actualPackageInfo := apt.ParseDependsOutput(inputParseDependsOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDependsOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseReverseDependsOutput(t *testing.T) {
+	var inputParseReverseDependsOutput string = strings.Join([]string{
+		`libgpm2`,
+		`Reverse Depends:`,
+		` |vim`,
+		`  libncurses6`,
+		`  vim`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "vim", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "libncurses6", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+	}
+
+	actualPackageInfo := apt.ParseReverseDependsOutput(inputParseReverseDependsOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseReverseDependsOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseOwnsOutput(t *testing.T) {
+	var inputParseOwnsOutput string = strings.Join([]string{
+		`diversion by dash from: /bin/sh`,
+		`diversion by dash to: /bin/sh.distrib`,
+		`dash: /bin/sh`,
+		`libc6:amd64, libc6:i386: /usr/share/doc/libc6`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "dash", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "libc6", Arch: "amd64", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "libc6", Arch: "i386", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+	}
+
+	actualPackageInfo := apt.ParseOwnsOutput(inputParseOwnsOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseOwnsOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListFilesOutput(t *testing.T) {
+	var inputParseListFilesOutput string = strings.Join([]string{
+		`/.`,
+		`/usr`,
+		`/usr/bin`,
+		`/usr/bin/vim.basic`,
+		`diverted by foo to: /usr/bin/vim.basic.real`,
+	}, "\n")
+
+	var expectedFiles = []string{"/usr", "/usr/bin", "/usr/bin/vim.basic"}
+
+	actualFiles := apt.ParseListFilesOutput(inputParseListFilesOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedFiles, actualFiles) {
+		t.Errorf("ParseListFilesOutput() = %+v, want %+v", actualFiles, expectedFiles)
+	}
+}
+
+func TestParseMarkOutput(t *testing.T) {
+	var inputParseMarkOutput string = strings.Join([]string{
+		`vim set on hold.`,
+		`curl was already set on hold.`,
+		`Canceled hold on nano.`,
+		`htop was already not on hold.`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "vim", Status: manager.PackageStatusHeld, PackageManager: "apt"},
+		{Name: "curl", Status: manager.PackageStatusHeld, PackageManager: "apt"},
+		{Name: "nano", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "htop", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+	}
+
+	actualPackageInfo := apt.ParseMarkOutput(inputParseMarkOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseMarkOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseShowKeysOutput(t *testing.T) {
+	var inputParseShowKeysOutput string = strings.Join([]string{
+		`pub:-:4096:1:8D81803C0EBFCD88:1487788586:::-:::scESA::::::23::0:`,
+		`fpr:::::::::9DC858229FC7DD38854AE2D88D81803C0EBFCD88:`,
+		`uid:-::::1487792064::B5A08F01796E7F521861B449372D1FF271F2DD50::Docker Release (CE deb) <docker@docker.com>::::::::::0:`,
+		`sub:-:4096:1:7EA0A9C3F273FCD8:1487788586::::::s::::::23:`,
+		`fpr:::::::::D3306A018370199E527AE7317EA0A9C3F273FCD8:`,
+		`pub:-:4096:1:23E7166788B63E1E:1458137539:::-:::scSC::::::23::0:`,
+		`fpr:::::::::0A0FAB860D48560332EFB581B1B4C5E8F37B4E7E:`,
+		`sub:-:4096:1:9B1F3E8BA3F3A5F1:1458137539::::::e::::::23:`,
+		`fpr:::::::::1E2B2B9D9A8F3E4E5C6D7F8091A2B3C4D5E6F7A8:`,
+		`uid:-::::1458137539::5D4C3B2A19080706F5E4D3C2B1A0998877665544::Subkey Owner <subkey@example.com>::::::::::0:`,
+	}, "\n")
+
+	var expectedKeys = []manager.KeyInfo{
+		{ID: "9DC858229FC7DD38854AE2D88D81803C0EBFCD88", UserID: "Docker Release (CE deb) <docker@docker.com>", Keyring: "/etc/apt/keyrings/docker.gpg", PackageManager: "apt"},
+		{ID: "0A0FAB860D48560332EFB581B1B4C5E8F37B4E7E", Keyring: "/etc/apt/keyrings/docker.gpg", PackageManager: "apt"},
+	}
+
+	actualKeys := apt.ParseShowKeysOutput(inputParseShowKeysOutput, "/etc/apt/keyrings/docker.gpg", &manager.Options{})
+
+	if !reflect.DeepEqual(expectedKeys, actualKeys) {
+		t.Errorf("ParseShowKeysOutput() = %+v, want %+v", actualKeys, expectedKeys)
+	}
+}
+
+func TestFilterSecurityUpdates(t *testing.T) {
+	var inputPackages = []manager.PackageInfo{
+		{Name: "openssl", Category: "jammy-updates,jammy-security", Status: manager.PackageStatusUpgradable, PackageManager: "apt"},
+		{Name: "libllvm15", Category: "jammy-updates", Status: manager.PackageStatusUpgradable, PackageManager: "apt"},
+		{Name: "curl", Category: "bookworm-security", Status: manager.PackageStatusUpgradable, PackageManager: "apt"},
+		{Name: "cloudflared", Category: "unknown", Status: manager.PackageStatusUpgradable, PackageManager: "apt"},
+	}
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "openssl", Category: "jammy-updates,jammy-security", Status: manager.PackageStatusUpgradable, PackageManager: "apt"},
+		{Name: "curl", Category: "bookworm-security", Status: manager.PackageStatusUpgradable, PackageManager: "apt"},
+	}
+
+	actualPackageInfo := apt.FilterSecurityUpdates(inputPackages)
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("FilterSecurityUpdates() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseStatusLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want manager.ProgressEvent
+		ok   bool
+	}{
+		{"dlstatus:1:9.0909:Retrieving file 1 of 3", manager.ProgressEvent{PackageManager: "apt", Phase: manager.ProgressPhaseDownload, Percent: 9.0909, Message: "Retrieving file 1 of 3"}, true},
+		{"pmstatus:dpkg-exec:0.0000:Running dpkg", manager.ProgressEvent{PackageManager: "apt", Phase: manager.ProgressPhaseInstall, Message: "Running dpkg"}, true},
+		{"pmstatus:libssl3:amd64:20.0000:Preparing libssl3:amd64 (amd64)", manager.ProgressEvent{PackageManager: "apt", Phase: manager.ProgressPhaseInstall, Package: "libssl3:amd64", Percent: 20, Message: "Preparing libssl3:amd64 (amd64)"}, true},
+		{"pmstatus:vim:85.7143:Removing vim (amd64)", manager.ProgressEvent{PackageManager: "apt", Phase: manager.ProgressPhaseRemove, Package: "vim", Percent: 85.7143, Message: "Removing vim (amd64)"}, true},
+		{"pmerror:/var/cache/apt/archives/vim.deb:50.0000:trying to overwrite '/usr/bin/vim'", manager.ProgressEvent{PackageManager: "apt", Phase: manager.ProgressPhaseError, Package: "/var/cache/apt/archives/vim.deb", Percent: 50, Message: "trying to overwrite '/usr/bin/vim'"}, true},
+		{"Setting up vim (2:8.2.3995-1ubuntu2.15) ...", manager.ProgressEvent{}, false},
+		{"pmconffile:/etc/vim/vimrc:60.0000:'/etc/vim/vimrc' '/etc/vim/vimrc.dpkg-new' 1 1", manager.ProgressEvent{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := apt.ParseStatusLine(tt.line)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseStatusLine(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseDownloadOutput(t *testing.T) {
+	var inputParseDownloadOutput string = strings.Join([]string{
+		`Need to get 8,592 kB of archives.`,
+		`After this operation, 39.5 MB of additional disk space will be used.`,
+		`Get:1 http://deb.debian.org/debian bookworm/main amd64 vim-runtime all 2:9.0.1378-2 [7,025 kB]`,
+		`Get:2 http://deb.debian.org/debian bookworm/main amd64 vim amd64 2:9.0.1378-2 [1,567 kB]`,
+		`Fetched 8,592 kB in 1s (10.1 MB/s)`,
+		`Download complete and in download only mode`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "vim-runtime", Version: "2:9.0.1378-2", Arch: "all", Status: manager.PackageStatusAvailable, PackageManager: "apt", AdditionalData: map[string]string{"path": "/srv/debs/vim-runtime_2%3a9.0.1378-2_all.deb"}},
+		{Name: "vim", Version: "2:9.0.1378-2", Arch: "amd64", Status: manager.PackageStatusAvailable, PackageManager: "apt", AdditionalData: map[string]string{"path": "/srv/debs/vim_2%3a9.0.1378-2_amd64.deb"}},
+	}
+
+	actualPackageInfo := apt.ParseDownloadOutput(inputParseDownloadOutput, "/srv/debs", &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDownloadOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParsePlanOutput(t *testing.T) {
+	var inputParsePlanOutput string = strings.Join([]string{
+		`Reading package lists...`,
+		`Suggested packages:`,
+		`   hunspell (1.7.1-1)`,
+		`The following NEW packages will be installed:`,
+		`   nano (7.2-1+deb12u1)`,
+		`The following packages will be upgraded:`,
+		`   libc6:amd64 (2.36-9+deb12u7 => 2.36-9+deb12u9)`,
+		`The following packages will be REMOVED:`,
+		`   vim* (2:9.0.1378-2+deb12u2)`,
+		`1 upgraded, 1 newly installed, 1 to remove and 0 not upgraded.`,
+		`Need to get 1,024 kB/3,512 kB of archives.`,
+		`After this operation, 2871 kB of additional disk space will be used.`,
+		`'http://deb.debian.org/debian/pool/main/n/nano/nano_7.2-1%2bdeb12u1_amd64.deb' nano_7.2-1+deb12u1_amd64.deb 689556 MD5Sum:03eeaaa603022ccbd061c8a4751fc947`,
+	}, "\n")
+
+	expectedPlan := &manager.Plan{
+		PackageManager: "apt",
+		Entries: []manager.PlanEntry{
+			{Action: manager.PlanActionInstall, Name: "nano", NewVersion: "7.2-1+deb12u1", DownloadSize: 689556},
+			{Action: manager.PlanActionUpgrade, Name: "libc6", Arch: "amd64", Version: "2.36-9+deb12u7", NewVersion: "2.36-9+deb12u9"},
+			{Action: manager.PlanActionRemove, Name: "vim", Version: "2:9.0.1378-2+deb12u2"},
+		},
+		DownloadSize: 1024000,
+		DiskSpace:    2871000,
+	}
+
+	actualPlan := apt.ParsePlanOutput(inputParsePlanOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedPlan, actualPlan) {
+		t.Errorf("ParsePlanOutput() = %+v, want %+v", actualPlan, expectedPlan)
+	}
+
+	// without -V, packages are listed without versions, several per line
+	actualPlan = apt.ParsePlanOutput("The following packages will be REMOVED:\n  vim vim-runtime\nAfter this operation, 41.8 MB disk space will be freed.\n", &manager.Options{})
+	expectedPlan = &manager.Plan{
+		PackageManager: "apt",
+		Entries: []manager.PlanEntry{
+			{Action: manager.PlanActionRemove, Name: "vim"},
+			{Action: manager.PlanActionRemove, Name: "vim-runtime"},
+		},
+		DiskSpace: -41800000,
+	}
+	if !reflect.DeepEqual(expectedPlan, actualPlan) {
+		t.Errorf("ParsePlanOutput() = %+v, want %+v", actualPlan, expectedPlan)
+	}
+}
+
+func TestParseListTasksOutput(t *testing.T) {
+	var inputParseListTasksOutput string = strings.Join([]string{
+		"u desktop\tDebian desktop environment",
+		"u web-server\tweb server",
+		"i ssh-server\tSSH server",
+		"i standard\tstandard system utilities",
+	}, "\n")
+
+	expectedGroups := []manager.GroupInfo{
+		{Name: "desktop", Description: "Debian desktop environment", PackageManager: "apt"},
+		{Name: "web-server", Description: "web server", PackageManager: "apt"},
+		{Name: "ssh-server", Description: "SSH server", Installed: true, PackageManager: "apt"},
+		{Name: "standard", Description: "standard system utilities", Installed: true, PackageManager: "apt"},
+	}
+
+	actualGroups := apt.ParseListTasksOutput(inputParseListTasksOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedGroups, actualGroups) {
+		t.Errorf("ParseListTasksOutput() = %+v, want %+v", actualGroups, expectedGroups)
+	}
+}
+
+func TestParseCopyrightLicense(t *testing.T) {
+	var inputCopyright string = strings.Join([]string{
+		`Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/`,
+		`Upstream-Name: bash`,
+		``,
+		`Files: examples/shellmath/*`,
+		`License: BSD-3-clause`,
+		``,
+		`Files: *`,
+		`Copyright: (C) 1987-2022 Free Software Foundation, Inc.`,
+		`License: GPL-3+`,
+		``,
+		`License: GPL-3+`,
+		` This program is free software: you can redistribute it and/or modify`,
+		` it under the terms of the GNU General Public License as published by`,
+	}, "\n")
+
+	if license := apt.ParseCopyrightLicense(inputCopyright); license != "GPL-3+" {
+		t.Errorf("ParseCopyrightLicense() = %q, want %q", license, "GPL-3+")
+	}
+	if license := apt.ParseCopyrightLicense("This package was debianized by someone.\n\nLicense: GPL\n"); license != "" {
+		t.Errorf("ParseCopyrightLicense() = %q, want an empty license for copyright files that are not machine-readable", license)
+	}
+}
+
+func TestParsePolicyOutput(t *testing.T) {
+	var inputParsePolicyOutput string = strings.Join([]string{
+		`Package files:`,
+		` 100 /var/lib/dpkg/status`,
+		`     release a=now`,
+		` 500 https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main i386 Packages`,
+		`     release v=22.04,o=LP-PPA-deadsnakes,a=jammy,n=jammy,l=New Python Versions,c=main,b=i386`,
+		`     origin ppa.launchpadcontent.net`,
+		` 500 https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main amd64 Packages`,
+		`     release v=22.04,o=LP-PPA-deadsnakes,a=jammy,n=jammy,l=New Python Versions,c=main,b=amd64`,
+		`     origin ppa.launchpadcontent.net`,
+		` 500 https://repo.example.com/apt stable/main amd64 Packages`,
+		`     origin repo.example.com`,
+		` 500 http://archive.ubuntu.com/ubuntu jammy/main amd64 Packages`,
+		`     release v=22.04,o=Ubuntu,a=jammy,n=jammy,l=Ubuntu,c=main,b=amd64`,
+		`     origin archive.ubuntu.com`,
+		`Pinned packages:`,
+	}, "\n")
+
+	expectedRepositories := []manager.RepositoryInfo{
+		{Name: "https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main", URL: "https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu",
+			Title: "New Python Versions", Origin: "LP-PPA-deadsnakes", Enabled: true, PackageManager: "apt"},
+		{Name: "https://repo.example.com/apt stable/main", URL: "https://repo.example.com/apt", Origin: "repo.example.com", Enabled: true, PackageManager: "apt"},
+		{Name: "http://archive.ubuntu.com/ubuntu jammy/main", URL: "http://archive.ubuntu.com/ubuntu", Title: "Ubuntu", Origin: "Ubuntu", Enabled: true, PackageManager: "apt"},
+	}
+	actualRepositories := apt.ParsePolicyOutput(inputParsePolicyOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedRepositories, actualRepositories) {
+		t.Errorf("ParsePolicyOutput() = %+v, want %+v", actualRepositories, expectedRepositories)
+	}
+}
+
+func TestParsePackagePolicyOutput(t *testing.T) {
+	var inputInstalled string = strings.Join([]string{
+		`python3.12:`,
+		`  Installed: 3.12.4-1+jammy1`,
+		`  Candidate: 3.12.5-1+jammy1`,
+		`  Version table:`,
+		`     3.12.5-1+jammy1 500`,
+		`        500 https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main amd64 Packages`,
+		` *** 3.12.4-1+jammy1 100`,
+		`        100 /var/lib/dpkg/status`,
+		`        500 http://archive.ubuntu.com/ubuntu jammy/main amd64 Packages`,
+	}, "\n")
+	if actual, expected := apt.ParsePackagePolicyOutput(inputInstalled, &manager.Options{}), "http://archive.ubuntu.com/ubuntu jammy/main"; actual != expected {
+		t.Errorf("ParsePackagePolicyOutput() = %q, want %q", actual, expected)
+	}
+
+	var inputNotInstalled string = strings.Join([]string{
+		`python3.13:`,
+		`  Installed: (none)`,
+		`  Candidate: 3.13.0-1+jammy1`,
+		`  Version table:`,
+		`     3.13.0-1+jammy1 500`,
+		`        500 https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main amd64 Packages`,
+	}, "\n")
+	if actual, expected := apt.ParsePackagePolicyOutput(inputNotInstalled, &manager.Options{}), "https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main"; actual != expected {
+		t.Errorf("ParsePackagePolicyOutput() = %q, want %q", actual, expected)
+	}
+
+	var inputLocal string = strings.Join([]string{
+		`google-chrome-stable:`,
+		`  Installed: 126.0.6478.126-1`,
+		`  Candidate: 126.0.6478.126-1`,
+		`  Version table:`,
+		` *** 126.0.6478.126-1 100`,
+		`        100 /var/lib/dpkg/status`,
+	}, "\n")
+	if actual := apt.ParsePackagePolicyOutput(inputLocal, &manager.Options{}); actual != "" {
+		t.Errorf("ParsePackagePolicyOutput() = %q, want no repository", actual)
+	}
+}
+
+func TestParsePackagesPolicyOutput(t *testing.T) {
+	var inputParsePackagesPolicyOutput string = strings.Join([]string{
+		`python3.12:`,
+		`  Installed: 3.12.4-1+jammy1`,
+		`  Candidate: 3.12.4-1+jammy1`,
+		`  Version table:`,
+		` *** 3.12.4-1+jammy1 500`,
+		`        500 https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main amd64 Packages`,
+		`        100 /var/lib/dpkg/status`,
+		`vim:`,
+		`  Installed: 2:8.2.3995-1ubuntu2`,
+		`  Candidate: 2:8.2.3995-1ubuntu2`,
+		`  Version table:`,
+		` *** 2:8.2.3995-1ubuntu2 500`,
+		`        500 http://archive.ubuntu.com/ubuntu jammy/main amd64 Packages`,
+		`        100 /var/lib/dpkg/status`,
+		`google-chrome-stable:`,
+		`  Installed: 126.0.6478.126-1`,
+		`  Candidate: 126.0.6478.126-1`,
+		`  Version table:`,
+		` *** 126.0.6478.126-1 100`,
+		`        100 /var/lib/dpkg/status`,
+	}, "\n")
+
+	expectedSources := map[string]string{
+		"python3.12": "https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main",
+		"vim":        "http://archive.ubuntu.com/ubuntu jammy/main",
+	}
+	actualSources := apt.ParsePackagesPolicyOutput(inputParsePackagesPolicyOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedSources, actualSources) {
+		t.Errorf("ParsePackagesPolicyOutput() = %+v, want %+v", actualSources, expectedSources)
+	}
+}
+
+func TestParseListOriginsOutput(t *testing.T) {
+	var inputPolicy string = strings.Join([]string{
+		`Package files:`,
+		` 100 /var/lib/dpkg/status`,
+		`     release a=now`,
+		` 500 https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main amd64 Packages`,
+		`     release v=22.04,o=LP-PPA-deadsnakes,a=jammy,n=jammy,l=New Python Versions,c=main,b=amd64`,
+		`     origin ppa.launchpadcontent.net`,
+		` 500 https://deb.nodesource.com/node_20.x nodistro/main amd64 Packages`,
+		`     release o=. nodistro,a=nodistro,n=nodistro,l=. nodistro,c=main,b=amd64`,
+		`     origin deb.nodesource.com`,
+		` 500 http://archive.ubuntu.com/ubuntu jammy-updates/main amd64 Packages`,
+		`     release v=22.04,o=Ubuntu,a=jammy-updates,n=jammy,l=Ubuntu,c=main,b=amd64`,
+		`     origin archive.ubuntu.com`,
+		` 500 http://archive.ubuntu.com/ubuntu jammy/main amd64 Packages`,
+		`     release v=22.04,o=Ubuntu,a=jammy,n=jammy,l=Ubuntu,c=main,b=amd64`,
+		`     origin archive.ubuntu.com`,
+		`Pinned packages:`,
+	}, "\n")
+
+	expectedArchives := map[string]string{"nodistro": ". nodistro", "jammy-updates": "Ubuntu"}
+	expectedAmbiguous := map[string]bool{"jammy": true}
+	actualArchives, actualAmbiguous := apt.ParseArchiveOrigins(inputPolicy)
+	if !reflect.DeepEqual(expectedArchives, actualArchives) || !reflect.DeepEqual(expectedAmbiguous, actualAmbiguous) {
+		t.Errorf("ParseArchiveOrigins() = %+v, %+v, want %+v, %+v", actualArchives, actualAmbiguous, expectedArchives, expectedAmbiguous)
+	}
+
+	var inputList string = strings.Join([]string{
+		`Listing...`,
+		`nodejs/nodistro,now 20.19.5-1nodesource1 amd64 [installed]`,
+		`python3.12/jammy,now 3.12.4-1+jammy1 amd64 [installed]`,
+		`vim/jammy-updates,jammy,now 2:8.2.3995-1ubuntu2.17 amd64 [installed]`,
+		`zoom/now 6.1.1.443 amd64 [installed,local]`,
+	}, "\n")
+
+	expectedOrigins := map[string]string{"nodejs": ". nodistro", "vim": "Ubuntu"}
+	expectedUnresolved := []string{"python3.12"}
+	actualOrigins, actualUnresolved := apt.ParseListOriginsOutput(inputList, actualArchives, actualAmbiguous, &manager.Options{})
+	if !reflect.DeepEqual(expectedOrigins, actualOrigins) || !reflect.DeepEqual(expectedUnresolved, actualUnresolved) {
+		t.Errorf("ParseListOriginsOutput() = %+v, %+v, want %+v, %+v", actualOrigins, actualUnresolved, expectedOrigins, expectedUnresolved)
+	}
+}
+
+func TestParseTrustedSources(t *testing.T) {
+	var inputParseTrustedSources string = strings.Join([]string{
+		`# the local mirror`,
+		`deb [trusted=yes] http://repo.example.com/debian/ ./`,
+		`deb [arch=amd64 signed-by=/etc/apt/keyrings/docker.asc] https://download.docker.com/linux/debian bookworm stable`,
+		`deb http://deb.debian.org/debian bookworm main`,
+		``,
+		`Types: deb`,
+		`URIs: http://mirror.example.com/internal/`,
+		`Suites: bookworm`,
+		`Components: main`,
+		`Trusted: yes`,
+		``,
+		`Types: deb`,
+		`URIs: http://deb.debian.org/debian`,
+		`Suites: bookworm bookworm-updates`,
+		`Signed-By: /usr/share/keyrings/debian-archive-keyring.gpg`,
+	}, "\n")
+
+	expectedURIs := []string{"http://repo.example.com/debian", "http://mirror.example.com/internal"}
+	actualURIs := apt.ParseTrustedSources(inputParseTrustedSources)
+	if !reflect.DeepEqual(expectedURIs, actualURIs) {
+		t.Errorf("ParseTrustedSources() = %+v, want %+v", actualURIs, expectedURIs)
+	}
+}
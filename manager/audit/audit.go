@@ -0,0 +1,299 @@
+// Package audit scans installed packages for known vulnerabilities, using the OSV database (https://osv.dev).
+//
+// Packages are looked up by their OSV ecosystem, name and version, either through the OSV API or in a local copy
+// of the database: a directory of OSV JSON files, such as the extracted all.zip export of an ecosystem.
+// Scanning is opt-in: package coordinates are only sent to the OSV API when Scan is called with an OSVClient.
+//
+// Only package managers with an OSV ecosystem are scanned. Advisories of Linux distributions are keyed by source package,
+// so binary packages named differently than their source package (e.g. libssl3, built from openssl) are not matched.
+//
+// This package is part of the syspkg library.
+package audit
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Query identifies a package version to look up in the vulnerability database.
+type Query struct {
+	// Ecosystem is the OSV ecosystem of the package, such as "PyPI" or "Debian:12".
+	Ecosystem string
+
+	// Name is the name of the package.
+	Name string
+
+	// Version is the installed version of the package.
+	Version string
+}
+
+// Source is a vulnerability database, such as the OSV API or a local copy of it.
+type Source interface {
+	// Query returns the vulnerabilities affecting each of the queried package versions, in the same order.
+	Query(queries []Query) ([][]Vulnerability, error)
+}
+
+// Vulnerability is an OSV vulnerability record, limited to the fields used by syspkg.
+// See https://ossf.github.io/osv-schema/ for the full schema.
+type Vulnerability struct {
+	ID       string     `json:"id"`
+	Summary  string     `json:"summary,omitempty"`
+	Aliases  []string   `json:"aliases,omitempty"`
+	Severity []Severity `json:"severity,omitempty"`
+	Affected []Affected `json:"affected,omitempty"`
+}
+
+// Severity is a severity score of a vulnerability, such as a CVSS vector.
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// Affected describes the versions of a package affected by a vulnerability.
+type Affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []Range  `json:"ranges,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+// Range is a range of affected versions, as a sequence of events where versions start or stop being affected.
+type Range struct {
+	Type   string `json:"type"`
+	Events []struct {
+		Introduced   string `json:"introduced,omitempty"`
+		Fixed        string `json:"fixed,omitempty"`
+		LastAffected string `json:"last_affected,omitempty"`
+	} `json:"events"`
+}
+
+// matches reports whether the affected package is the queried package. Ecosystems match by their prefix,
+// so that "Debian" affected entries match "Debian:12" queries.
+func (a Affected) matches(q Query) bool {
+	if a.Package.Name != q.Name {
+		return false
+	}
+	return a.Package.Ecosystem == q.Ecosystem || strings.HasPrefix(q.Ecosystem, a.Package.Ecosystem+":")
+}
+
+// Affects reports whether the queried package version is affected by the vulnerability, according to its listed versions
+// and its ECOSYSTEM and SEMVER ranges, compared with manager.CompareVersions. GIT ranges are ignored.
+func (v Vulnerability) Affects(q Query) bool {
+	for _, a := range v.Affected {
+		if !a.matches(q) {
+			continue
+		}
+		for _, version := range a.Versions {
+			if version == q.Version {
+				return true
+			}
+		}
+		for _, r := range a.Ranges {
+			if r.Type == "GIT" {
+				continue
+			}
+
+			affected := false
+			for _, e := range r.Events {
+				switch {
+				case e.Introduced != "":
+					if e.Introduced == "0" || manager.CompareVersions(q.Version, e.Introduced) >= 0 {
+						affected = true
+					}
+				case e.Fixed != "":
+					if manager.CompareVersions(q.Version, e.Fixed) >= 0 {
+						affected = false
+					}
+				case e.LastAffected != "":
+					if manager.CompareVersions(q.Version, e.LastAffected) > 0 {
+						affected = false
+					}
+				}
+			}
+			if affected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FixedVersions returns the versions of the queried package fixing the vulnerability, sorted, or none if there is no fix.
+func (v Vulnerability) FixedVersions(q Query) []string {
+	seen := make(map[string]bool)
+	var fixed []string
+	for _, a := range v.Affected {
+		if !a.matches(q) {
+			continue
+		}
+		for _, r := range a.Ranges {
+			if r.Type == "GIT" {
+				continue
+			}
+			for _, e := range r.Events {
+				if e.Fixed != "" && !seen[e.Fixed] {
+					seen[e.Fixed] = true
+					fixed = append(fixed, e.Fixed)
+				}
+			}
+		}
+	}
+	sort.Slice(fixed, func(i, j int) bool { return manager.CompareVersions(fixed[i], fixed[j]) < 0 })
+	return fixed
+}
+
+// Finding is an installed package affected by a vulnerability.
+type Finding struct {
+	// PackageManager is the name of the package manager the package was installed with.
+	PackageManager string `json:"package_manager"`
+
+	// Package is the name of the affected package.
+	Package string `json:"package"`
+
+	// Version is the installed version of the package.
+	Version string `json:"version"`
+
+	// ID is the OSV identifier of the vulnerability, such as "GHSA-j8r2-6x86-q33q" or "DSA-5678-1".
+	ID string `json:"id"`
+
+	// Aliases are the other identifiers of the vulnerability, such as CVE IDs.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Summary is a one-line description of the vulnerability.
+	Summary string `json:"summary,omitempty"`
+
+	// FixedVersions are the versions of the package fixing the vulnerability, if any.
+	FixedVersions []string `json:"fixed_versions,omitempty"`
+}
+
+// OSRelease identifies the running Linux distribution, as read from /etc/os-release.
+type OSRelease struct {
+	// ID is the distribution identifier, such as "debian", "ubuntu" or "alpine".
+	ID string
+
+	// VersionID is the distribution version, such as "12", "22.04" or "3.19.1".
+	VersionID string
+}
+
+// ReadOSRelease reads the os-release file at path, usually /etc/os-release.
+func ReadOSRelease(path string) (OSRelease, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return OSRelease{}, err
+	}
+	defer file.Close()
+
+	var release OSRelease
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			release.ID = value
+		case "VERSION_ID":
+			release.VersionID = value
+		}
+	}
+	return release, scanner.Err()
+}
+
+// ecosystems maps the names of language package managers to their OSV ecosystem.
+var ecosystems = map[string]string{
+	"cargo": "crates.io",
+	"gem":   "RubyGems",
+	"gobin": "Go",
+	"npm":   "npm",
+	"pip":   "PyPI",
+}
+
+// Ecosystem returns the OSV ecosystem of the packages of the named package manager, which depends on the distribution
+// for system package managers, or an empty string if OSV doesn't cover it.
+func Ecosystem(pm string, release OSRelease) string {
+	if ecosystem, ok := ecosystems[pm]; ok {
+		return ecosystem
+	}
+
+	switch {
+	case pm == "apt" && release.ID == "debian" && release.VersionID != "":
+		return "Debian:" + release.VersionID
+	case pm == "apt" && release.ID == "ubuntu" && release.VersionID != "":
+		// LTS releases are the April releases of even years, e.g. 22.04
+		if year, month, _ := strings.Cut(release.VersionID, "."); month == "04" && len(year) == 2 && (year[1]-'0')%2 == 0 {
+			return "Ubuntu:" + release.VersionID + ":LTS"
+		}
+		return "Ubuntu:" + release.VersionID
+	case pm == "apk" && release.ID == "alpine" && release.VersionID != "":
+		// Alpine advisories are per minor release, e.g. v3.19
+		parts := strings.SplitN(release.VersionID, ".", 3)
+		if len(parts) < 2 {
+			return ""
+		}
+		return "Alpine:v" + parts[0] + "." + parts[1]
+	}
+	return ""
+}
+
+// Scan looks up the installed packages, by package manager name, in the vulnerability database, and returns the findings
+// sorted by package manager, package and vulnerability ID. Packages of package managers without OSV ecosystem are skipped.
+func Scan(source Source, packages map[string][]manager.PackageInfo, release OSRelease) ([]Finding, error) {
+	var queries []Query
+	var owners []string
+	for pm, pkgs := range packages {
+		ecosystem := Ecosystem(pm, release)
+		if ecosystem == "" {
+			continue
+		}
+		for _, pkg := range pkgs {
+			if pkg.Version == "" {
+				continue
+			}
+			queries = append(queries, Query{Ecosystem: ecosystem, Name: pkg.Name, Version: pkg.Version})
+			owners = append(owners, pm)
+		}
+	}
+	if len(queries) == 0 {
+		return nil, nil
+	}
+
+	results, err := source.Query(queries)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for i, vulns := range results {
+		for _, v := range vulns {
+			findings = append(findings, Finding{
+				PackageManager: owners[i],
+				Package:        queries[i].Name,
+				Version:        queries[i].Version,
+				ID:             v.ID,
+				Aliases:        v.Aliases,
+				Summary:        v.Summary,
+				FixedVersions:  v.FixedVersions(queries[i]),
+			})
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.PackageManager != b.PackageManager {
+			return a.PackageManager < b.PackageManager
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.ID < b.ID
+	})
+	return findings, nil
+}
@@ -0,0 +1,211 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/audit"
+)
+
+// records are OSV records, by ID, affecting the packages of the tests.
+var records = map[string]string{
+	"GHSA-j8r2-6x86-q33q": `{
+		"id": "GHSA-j8r2-6x86-q33q",
+		"summary": "Unintended leak of Proxy-Authorization header in requests",
+		"aliases": ["CVE-2023-32681"],
+		"affected": [{
+			"package": {"ecosystem": "PyPI", "name": "requests"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.3.0"}, {"fixed": "2.31.0"}]}]
+		}]
+	}`,
+	"DSA-5435-1": `{
+		"id": "DSA-5435-1",
+		"summary": "openssl - security update",
+		"affected": [{
+			"package": {"ecosystem": "Debian:12", "name": "openssl"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "3.0.9-1"}]}]
+		}, {
+			"package": {"ecosystem": "Debian:11", "name": "openssl"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.1.1n-0+deb11u5"}]}]
+		}]
+	}`,
+	"GHSA-c2qf-rxjj-qqgw": `{
+		"id": "GHSA-c2qf-rxjj-qqgw",
+		"summary": "semver vulnerable to Regular Expression Denial of Service",
+		"affected": [{
+			"package": {"ecosystem": "npm", "name": "semver"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "7.0.0"}, {"fixed": "7.5.2"}]}]
+		}]
+	}`,
+}
+
+var packages = map[string][]manager.PackageInfo{
+	"pip": {
+		{Name: "requests", Version: "2.28.1"},
+		{Name: "urllib3", Version: "2.0.7"},
+	},
+	"apt": {
+		{Name: "openssl", Version: "3.0.8-1"},
+	},
+	"npm": {
+		{Name: "semver", Version: "7.5.4"},
+	},
+	"snap": {
+		{Name: "core22", Version: "20240111"},
+	},
+}
+
+var release = audit.OSRelease{ID: "debian", VersionID: "12"}
+
+var expectedFindings = []audit.Finding{
+	{PackageManager: "apt", Package: "openssl", Version: "3.0.8-1", ID: "DSA-5435-1", Summary: "openssl - security update", FixedVersions: []string{"3.0.9-1"}},
+	{PackageManager: "pip", Package: "requests", Version: "2.28.1", ID: "GHSA-j8r2-6x86-q33q", Aliases: []string{"CVE-2023-32681"}, Summary: "Unintended leak of Proxy-Authorization header in requests", FixedVersions: []string{"2.31.0"}},
+}
+
+func TestScanLocalDB(t *testing.T) {
+	dir := t.TempDir()
+	for id, record := range records {
+		if err := os.WriteFile(filepath.Join(dir, id+".json"), []byte(record), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	findings, err := audit.Scan(&audit.LocalDB{Dir: dir}, packages, release)
+	if err != nil {
+		t.Fatalf("Scan() error = %+v", err)
+	}
+	if !reflect.DeepEqual(findings, expectedFindings) {
+		t.Errorf("Scan() = %+v, want %+v", findings, expectedFindings)
+	}
+}
+
+func TestScanOSVClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/querybatch" {
+			record, ok := records[filepath.Base(r.URL.Path)]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(record))
+			return
+		}
+
+		var request struct {
+			Queries []struct {
+				Package struct {
+					Name      string `json:"name"`
+					Ecosystem string `json:"ecosystem"`
+				} `json:"package"`
+				Version string `json:"version"`
+			} `json:"queries"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		type vuln struct {
+			ID string `json:"id"`
+		}
+		type result struct {
+			Vulns []vuln `json:"vulns,omitempty"`
+		}
+		var response struct {
+			Results []result `json:"results"`
+		}
+		for _, q := range request.Queries {
+			var res result
+			for id, record := range records {
+				var v audit.Vulnerability
+				_ = json.Unmarshal([]byte(record), &v)
+				if v.Affects(audit.Query{Ecosystem: q.Package.Ecosystem, Name: q.Package.Name, Version: q.Version}) {
+					res.Vulns = append(res.Vulns, vuln{id})
+				}
+			}
+			response.Results = append(response.Results, res)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	findings, err := audit.Scan(&audit.OSVClient{BaseURL: server.URL}, packages, release)
+	if err != nil {
+		t.Fatalf("Scan() error = %+v", err)
+	}
+	if !reflect.DeepEqual(findings, expectedFindings) {
+		t.Errorf("Scan() = %+v, want %+v", findings, expectedFindings)
+	}
+}
+
+func TestAffects(t *testing.T) {
+	var v audit.Vulnerability
+	if err := json.Unmarshal([]byte(`{
+		"id": "TEST-1",
+		"affected": [{
+			"package": {"ecosystem": "Alpine", "name": "busybox"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "1.30.0"}, {"last_affected": "1.36.1-r1"}]}],
+			"versions": ["1.29.3-r10"]
+		}]
+	}`), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"1.29.3-r10", true},
+		{"1.29.3-r11", false},
+		{"1.30.0", true},
+		{"1.36.1-r1", true},
+		{"1.36.1-r2", false},
+	}
+	for _, tt := range tests {
+		q := audit.Query{Ecosystem: "Alpine:v3.19", Name: "busybox", Version: tt.version}
+		if got := v.Affects(q); got != tt.want {
+			t.Errorf("Affects(%+v) = %v, want %v", q, got, tt.want)
+		}
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	tests := []struct {
+		pm      string
+		release audit.OSRelease
+		want    string
+	}{
+		{"pip", audit.OSRelease{}, "PyPI"},
+		{"gobin", audit.OSRelease{}, "Go"},
+		{"apt", audit.OSRelease{ID: "debian", VersionID: "12"}, "Debian:12"},
+		{"apt", audit.OSRelease{ID: "ubuntu", VersionID: "22.04"}, "Ubuntu:22.04:LTS"},
+		{"apt", audit.OSRelease{ID: "ubuntu", VersionID: "23.10"}, "Ubuntu:23.10"},
+		{"apk", audit.OSRelease{ID: "alpine", VersionID: "3.19.1"}, "Alpine:v3.19"},
+		{"apt", audit.OSRelease{ID: "linuxmint", VersionID: "21.3"}, ""},
+		{"snap", audit.OSRelease{}, ""},
+	}
+	for _, tt := range tests {
+		if got := audit.Ecosystem(tt.pm, tt.release); got != tt.want {
+			t.Errorf("Ecosystem(%q, %+v) = %q, want %q", tt.pm, tt.release, got, tt.want)
+		}
+	}
+}
+
+func TestReadOSRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "os-release")
+	content := "PRETTY_NAME=\"Ubuntu 22.04.4 LTS\"\nNAME=\"Ubuntu\"\nVERSION_ID=\"22.04\"\nID=ubuntu\nID_LIKE=debian\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := audit.OSRelease{ID: "ubuntu", VersionID: "22.04"}
+	if got, err := audit.ReadOSRelease(path); err != nil || got != want {
+		t.Errorf("ReadOSRelease() = %+v, %+v, want %+v", got, err, want)
+	}
+}
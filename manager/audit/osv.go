@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultOSVURL is the base URL of the public OSV API.
+const DefaultOSVURL = "https://api.osv.dev"
+
+// osvBatchSize is the maximum number of queries of an OSV querybatch request.
+const osvBatchSize = 1000
+
+// OSVClient queries the OSV API. The batch query endpoint only returns the IDs of the vulnerabilities,
+// so their details are then fetched one by one, once per vulnerability.
+type OSVClient struct {
+	// BaseURL is the base URL of the API, DefaultOSVURL if empty.
+	BaseURL string
+
+	// HTTPClient is the client used to send the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// osvQuery is a query of an OSV querybatch request.
+type osvQuery struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Version string `json:"version"`
+}
+
+// Query implements Source.
+func (c *OSVClient) Query(queries []Query) ([][]Vulnerability, error) {
+	results := make([][]Vulnerability, len(queries))
+	details := make(map[string]Vulnerability)
+
+	for start := 0; start < len(queries); start += osvBatchSize {
+		end := start + osvBatchSize
+		if end > len(queries) {
+			end = len(queries)
+		}
+
+		var request struct {
+			Queries []osvQuery `json:"queries"`
+		}
+		for _, q := range queries[start:end] {
+			var oq osvQuery
+			oq.Package.Name, oq.Package.Ecosystem, oq.Version = q.Name, q.Ecosystem, q.Version
+			request.Queries = append(request.Queries, oq)
+		}
+
+		var response struct {
+			Results []struct {
+				Vulns []struct {
+					ID string `json:"id"`
+				} `json:"vulns"`
+			} `json:"results"`
+		}
+		if err := c.post("/v1/querybatch", request, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Results) != end-start {
+			return nil, fmt.Errorf("audit: OSV returned %d results for %d queries", len(response.Results), end-start)
+		}
+
+		for i, result := range response.Results {
+			for _, v := range result.Vulns {
+				vuln, ok := details[v.ID]
+				if !ok {
+					if err := c.get("/v1/vulns/"+url.PathEscape(v.ID), &vuln); err != nil {
+						return nil, err
+					}
+					details[v.ID] = vuln
+				}
+				results[start+i] = append(results[start+i], vuln)
+			}
+		}
+	}
+	return results, nil
+}
+
+// post sends a JSON request to the API, and decodes the JSON response into response.
+func (c *OSVClient) post(path string, request interface{}, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url(path), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, response)
+}
+
+// get sends a GET request to the API, and decodes the JSON response into response.
+func (c *OSVClient) get(path string, response interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.url(path), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, response)
+}
+
+// do sends a request to the API, and decodes the JSON response into response.
+func (c *OSVClient) do(req *http.Request, response interface{}) error {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("audit: OSV request %s %s failed: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// url returns the URL of an API endpoint.
+func (c *OSVClient) url(path string) string {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultOSVURL
+	}
+	return strings.TrimSuffix(base, "/") + path
+}
+
+// LocalDB is a local copy of the OSV database: a directory of OSV JSON files, one per vulnerability,
+// searched recursively, such as the extracted all.zip exports of the ecosystems to scan.
+type LocalDB struct {
+	// Dir is the directory of the database.
+	Dir string
+}
+
+// Query implements Source. The whole database is loaded for every call, so queries should be batched.
+func (db *LocalDB) Query(queries []Query) ([][]Vulnerability, error) {
+	results := make([][]Vulnerability, len(queries))
+
+	err := filepath.WalkDir(db.Dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var vuln Vulnerability
+		if err := json.Unmarshal(data, &vuln); err != nil {
+			return fmt.Errorf("audit: invalid OSV record %s: %w", path, err)
+		}
+
+		for i, q := range queries {
+			if vuln.Affects(q) {
+				results[i] = append(results[i], vuln)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
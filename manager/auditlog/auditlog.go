@@ -0,0 +1,196 @@
+// Package auditlog writes an append-only audit log of the operations changing the packages of the system, such as
+// installs, removals and upgrades, recording who ran them, when, and with which result, for compliance.
+//
+// Unlike the history, which only records the transactions that can be rolled back, the audit log records every
+// changing operation, including failed ones, and is never rewritten. Entries are written as JSON lines to a file,
+// or to the local syslog daemon.
+//
+// This package is part of the syspkg library.
+package auditlog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Syslog is the destination selecting the local syslog daemon instead of a file, see Open.
+const Syslog = "syslog"
+
+// Results of the operations.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// ErrSyslogUnsupported is returned by NewSyslogWriter on platforms without syslog.
+var ErrSyslogUnsupported = errors.New("auditlog: syslog is not supported on this platform")
+
+// Entry is an operation recorded in the audit log.
+type Entry struct {
+	// Time is the time the operation finished, set by Record if zero.
+	Time time.Time `json:"time"`
+
+	// User is the name of the user who ran the operation: the user who invoked sudo, doas or pkexec when syspkg
+	// was re-executed with elevated privileges, set by Record if empty.
+	User string `json:"user"`
+
+	// UID is the effective user ID the operation ran as, set by Record.
+	UID int `json:"uid"`
+
+	// PackageManager is the name of the package manager that ran the operation.
+	PackageManager string `json:"package_manager"`
+
+	// Operation is the operation, such as "install", "delete" or "refresh".
+	Operation string `json:"operation"`
+
+	// Packages are the packages the operation was run on, if any.
+	Packages []string `json:"packages,omitempty"`
+
+	// Result is ResultSuccess or ResultFailure.
+	Result string `json:"result"`
+
+	// ExitCode is the exit code of the package manager command: 0 on success, -1 if the operation failed without one.
+	ExitCode int `json:"exit_code"`
+
+	// Error is the error returned by the package manager, if the operation failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Writer writes the entries of an audit log.
+type Writer interface {
+	Write(entry Entry) error
+	Close() error
+}
+
+// Log records operations in an audit log. A nil Log records nothing.
+type Log struct {
+	w Writer
+}
+
+// New returns a Log writing its entries to w.
+func New(w Writer) *Log {
+	return &Log{w: w}
+}
+
+// Open opens the audit log at destination: Syslog for the local syslog daemon, otherwise the path of a file.
+// An empty destination disables the audit log, returning a nil Log.
+func Open(destination string) (*Log, error) {
+	switch destination {
+	case "":
+		return nil, nil
+	case Syslog:
+		w, err := NewSyslogWriter()
+		if err != nil {
+			return nil, err
+		}
+		return New(w), nil
+	default:
+		return New(NewFileWriter(destination)), nil
+	}
+}
+
+// Record completes the entry of an operation that returned err, and writes it to the audit log.
+func (l *Log) Record(entry Entry, err error) error {
+	if l == nil {
+		return nil
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	if entry.User == "" {
+		entry.User = invokingUser()
+	}
+	entry.UID = os.Geteuid()
+	entry.Result = ResultSuccess
+	if err != nil {
+		entry.Result = ResultFailure
+		entry.Error = err.Error()
+	}
+	entry.ExitCode = ExitCode(err)
+	return l.w.Write(entry)
+}
+
+// Close closes the audit log.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.w.Close()
+}
+
+// ExitCode returns the exit code of the command that caused err: 0 if err is nil, and -1 if err wasn't caused by a
+// command exiting with a status.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// invokingUser returns the name of the user running syspkg, or who invoked sudo, doas or pkexec to run it.
+func invokingUser() string {
+	for _, name := range []string{"SUDO_USER", "DOAS_USER"} {
+		if u := os.Getenv(name); u != "" {
+			return u
+		}
+	}
+	if uid := os.Getenv("PKEXEC_UID"); uid != "" {
+		if u, err := user.LookupId(uid); err == nil {
+			return u.Username
+		}
+		return uid
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(os.Getuid())
+}
+
+// FileWriter appends the entries to a file as JSON lines.
+type FileWriter struct {
+	path string
+}
+
+// NewFileWriter returns a FileWriter appending to the file at path. The file and its directory are created on the
+// first Write, readable only by their owner, as the audit log tells what is installed on the system.
+func NewFileWriter(path string) *FileWriter {
+	return &FileWriter{path: path}
+}
+
+// Write appends the entry to the file. Each entry is written with a single append, so that the entries of concurrent
+// processes are not interleaved.
+func (w *FileWriter) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Close does nothing, as the file is only open while writing an entry.
+func (w *FileWriter) Close() error {
+	return nil
+}
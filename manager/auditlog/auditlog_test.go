@@ -0,0 +1,79 @@
+package auditlog_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bluet/syspkg/manager/auditlog"
+)
+
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	l, err := auditlog.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %+v", err)
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	failed := exec.Command("sh", "-c", "exit 100").Run()
+	entries := []auditlog.Entry{
+		{Time: now, User: "alice", PackageManager: "apt", Operation: "install", Packages: []string{"vim=2:8.2.3995-1ubuntu2.16"}},
+		{Time: now, User: "alice", PackageManager: "apt", Operation: "delete", Packages: []string{"nonexistent"}},
+		{Time: now, User: "bob", PackageManager: "snap", Operation: "refresh"},
+	}
+	for i, err := range []error{nil, failed, errors.New("snap: not running")} {
+		if err := l.Record(entries[i], err); err != nil {
+			t.Fatalf("Record() error = %+v", err)
+		}
+	}
+
+	uid := os.Geteuid()
+	want := []auditlog.Entry{
+		{Time: now, User: "alice", UID: uid, PackageManager: "apt", Operation: "install", Packages: []string{"vim=2:8.2.3995-1ubuntu2.16"}, Result: auditlog.ResultSuccess},
+		{Time: now, User: "alice", UID: uid, PackageManager: "apt", Operation: "delete", Packages: []string{"nonexistent"}, Result: auditlog.ResultFailure, ExitCode: 100, Error: "exit status 100"},
+		{Time: now, User: "bob", UID: uid, PackageManager: "snap", Operation: "refresh", Result: auditlog.ResultFailure, ExitCode: -1, Error: "snap: not running"},
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []auditlog.Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry auditlog.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, entry)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Record() wrote %+v, want %+v", got, want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("the audit log has mode %v, want %v", info.Mode().Perm(), os.FileMode(0o600))
+	}
+}
+
+func TestOpenDisabled(t *testing.T) {
+	l, err := auditlog.Open("")
+	if l != nil || err != nil {
+		t.Errorf("Open() = %+v, %+v, want nil", l, err)
+	}
+	if err := l.Record(auditlog.Entry{PackageManager: "apt", Operation: "install"}, nil); err != nil {
+		t.Errorf("Record() error = %+v, want nil for a disabled audit log", err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build windows || plan9
+
+package auditlog
+
+// SyslogWriter is not supported on this platform.
+type SyslogWriter struct{}
+
+// NewSyslogWriter returns ErrSyslogUnsupported, as there is no syslog on this platform.
+func NewSyslogWriter() (*SyslogWriter, error) {
+	return nil, ErrSyslogUnsupported
+}
+
+// Write does nothing.
+func (w *SyslogWriter) Write(entry Entry) error {
+	return ErrSyslogUnsupported
+}
+
+// Close does nothing.
+func (w *SyslogWriter) Close() error {
+	return nil
+}
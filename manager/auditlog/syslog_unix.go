@@ -0,0 +1,39 @@
+//go:build !windows && !plan9
+
+package auditlog
+
+import (
+	"encoding/json"
+	"log/syslog"
+)
+
+// SyslogWriter sends the entries to the local syslog daemon as JSON, with the authpriv facility.
+type SyslogWriter struct {
+	w *syslog.Writer
+}
+
+// NewSyslogWriter connects to the local syslog daemon.
+func NewSyslogWriter() (*SyslogWriter, error) {
+	w, err := syslog.New(syslog.LOG_AUTHPRIV|syslog.LOG_NOTICE, "syspkg")
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogWriter{w: w}, nil
+}
+
+// Write sends the entry to the syslog daemon, as a warning for failed operations.
+func (w *SyslogWriter) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if entry.Result == ResultFailure {
+		return w.w.Warning(string(line))
+	}
+	return w.w.Notice(string(line))
+}
+
+// Close closes the connection to the syslog daemon.
+func (w *SyslogWriter) Close() error {
+	return w.w.Close()
+}
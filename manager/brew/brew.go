@@ -0,0 +1,347 @@
+// Package brew provides an implementation of the syspkg manager interface for the Homebrew package manager.
+// It provides a Go (golang) API interface for interacting with Homebrew.
+// This package is a wrapper around the brew command line tool.
+//
+// Homebrew is a free and open-source package manager for macOS and Linux (where it is also known as Linuxbrew).
+// It installs packages ("formulae" for command line software and "casks" for macOS applications) into their own directory
+// under a user-owned prefix, such as /opt/homebrew or /home/linuxbrew/.linuxbrew, and symlinks their files into that prefix.
+// Homebrew refuses to run as root, and does not replace the native package manager of the system.
+//
+// For more information about Homebrew, visit:
+// - https://brew.sh/
+// - https://docs.brew.sh/Manpage
+//
+// This package is part of the syspkg library.
+package brew
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "brew"
+
+// Constants used for brew commands
+const (
+	ArgsDryRun  string = "--dry-run"
+	ArgsJSONV2  string = "--json=v2"
+	ArgsVerbose string = "--verbose"
+	ArgsQuiet   string = "--quiet"
+)
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for brew.
+// Automatic updates are disabled so that each command only does what it was asked to do; use Refresh to update Homebrew.
+var ENV_NonInteractive []string = []string{"LC_ALL=C", "NONINTERACTIVE=1", "HOMEBREW_NO_AUTO_UPDATE=1", "HOMEBREW_NO_ENV_HINTS=1", "HOMEBREW_NO_COLOR=1"}
+
+// PackageManager implements the manager.PackageManager interface for the Homebrew package manager.
+type PackageManager struct{}
+
+// IsAvailable checks if the Homebrew package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(pm)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the Homebrew package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by brew.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:         true,
+		Delete:         true,
+		DryRun:         true,
+		ListUpgradable: true,
+		Upgrade:        true,
+		Hold:           true,
+	}
+}
+
+// Install installs the provided formulae or casks using Homebrew.
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if err := manager.RejectVersionSpecs(pkgs); err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"install"}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return ParseDryRunOutput(string(out), opts), nil
+	}
+	return ParseInstallOutput(string(out), opts), nil
+}
+
+// Delete uninstalls the provided formulae or casks using Homebrew.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"uninstall"}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// brew uninstall has no dry-run mode, so only report what is installed
+	if opts.DryRun {
+		var packages []manager.PackageInfo
+		for _, pkg := range pkgs {
+			info, err := a.GetPackageInfo(pkg, opts)
+			if err != nil {
+				return nil, err
+			}
+			if info.Status == manager.PackageStatusInstalled || info.Status == manager.PackageStatusUpgradable {
+				info.Status = manager.PackageStatusAvailable
+				info.NewVersion = ""
+				packages = append(packages, info)
+			}
+		}
+		return packages, nil
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseDeletedOutput(string(out), opts), nil
+}
+
+// Refresh fetches the newest version of Homebrew and of all formulae and casks definitions.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	out, err := a.run([]string{"update"}, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Verbose && out != nil {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
+
+// Find searches for formulae and casks matching the provided keywords using Homebrew.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"search"}, keywords...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// brew exits with 1 when nothing matches
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && len(out) == 0 {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ParseFindOutput(string(out), opts), nil
+}
+
+// ListInstalled lists all installed formulae and casks using Homebrew.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "info", ArgsJSONV2, "--installed")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInfoJSONOutput(out, opts)
+}
+
+// ListUpgradable lists all outdated formulae and casks using Homebrew.
+// The result is based on the local copy of the formulae and casks definitions, so Refresh should be called first for up-to-date results.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "outdated", ArgsJSONV2)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// brew exits with 1 when some packages are outdated and no names were given
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 || len(out) == 0 {
+			return nil, err
+		}
+	}
+	return ParseOutdatedOutput(out, opts)
+}
+
+// Upgrade upgrades the provided formulae and casks using Homebrew.
+// If no packages are given, all outdated packages are upgraded.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"upgrade"}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseUpgradeOutput(string(out), opts), nil
+}
+
+// UpgradeAll upgrades all outdated formulae and casks using Homebrew.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// Clean removes stale lock files, outdated downloads and old versions of installed formulae and casks.
+func (a *PackageManager) Clean(opts *manager.Options) error {
+	if opts == nil {
+		opts = &manager.Options{}
+	}
+
+	args := []string{"cleanup"}
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Verbose && out != nil {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
+
+// AutoRemove uninstalls formulae that were only installed as dependencies and are no longer needed.
+func (a *PackageManager) AutoRemove(opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{}
+	}
+
+	args := []string{"autoremove"}
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseDeletedOutput(string(out), opts), nil
+}
+
+// GetPackageInfo retrieves information about the specified formula or cask using Homebrew.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "info", ArgsJSONV2, pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+
+	packages, err := ParseInfoJSONOutput(out, opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	if len(packages) == 0 {
+		return manager.PackageInfo{Name: pkg, Status: manager.PackageStatusUnknown, PackageManager: pm}, nil
+	}
+	return packages[0], nil
+}
+
+// Hold pins the provided formulae at their installed version using brew pin, so that brew upgrade skips them.
+// Only formulae can be pinned, not casks. Pins are not simulated in dry-run mode: the formulae that would be pinned are returned.
+func (a *PackageManager) Hold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pin("pin", pkgs, manager.PackageStatusHeld, opts)
+}
+
+// Unhold unpins the provided formulae using brew unpin.
+func (a *PackageManager) Unhold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.pin("unpin", pkgs, manager.PackageStatusInstalled, opts)
+}
+
+// ListHeld lists the pinned formulae using brew list --pinned.
+func (a *PackageManager) ListHeld(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "list", "--pinned", "--versions")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePinnedOutput(string(out), opts), nil
+}
+
+// pin runs the given pin command (pin or unpin) for the provided formulae, and returns them with the given status.
+func (a *PackageManager) pin(command string, pkgs []string, status manager.PackageStatus, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if !opts.DryRun {
+		if _, err := a.run(append([]string{command}, pkgs...), opts); err != nil {
+			return nil, err
+		}
+	}
+
+	var packages []manager.PackageInfo
+	for _, name := range pkgs {
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Status:         status,
+			PackageManager: pm,
+			AdditionalData: map[string]string{"type": TypeFormula},
+		})
+	}
+	return packages, nil
+}
+
+// run runs brew with the given arguments, either attached to the terminal in interactive mode,
+// in which case no output is returned, or non-interactively, returning the standard output.
+func (a *PackageManager) run(args []string, opts *manager.Options) ([]byte, error) {
+	cmd := manager.Command(opts, pm, args...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return nil, manager.Run(cmd)
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	return manager.Output(cmd)
+}
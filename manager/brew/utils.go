@@ -0,0 +1,490 @@
+// Package brew provides a package manager implementation for macOS and Linux using
+// Homebrew as the underlying package management tool.
+package brew
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Homebrew package types, reported in PackageInfo.AdditionalData["type"].
+const (
+	TypeFormula string = "formula"
+	TypeCask    string = "cask"
+)
+
+// cellarPattern matches the installation summary of a formula or cask, e.g.
+// "🍺  /opt/homebrew/Cellar/vim/9.0.1650: 2,104 files, 38.9MB".
+var cellarPattern = regexp.MustCompile(`/(Cellar|Caskroom)/([^/\s]+)/([^/:\s]+): `)
+
+// uninstallPattern matches the uninstallation message of a formula, e.g.
+// "Uninstalling /opt/homebrew/Cellar/vim/9.0.1650... (2,104 files, 38.9MB)".
+var uninstallPattern = regexp.MustCompile(`^Uninstalling \S*/(Cellar|Caskroom)/([^/\s]+)/(\S+?)\.\.\.`)
+
+// upgradePattern matches a version transition line, e.g. "vim 9.0.1600 -> 9.0.1650" or "  9.0.1600 -> 9.0.1650".
+var upgradePattern = regexp.MustCompile(`^\s*(\S+ )?(\S+) -> (\S+)$`)
+
+// ParseInstallOutput parses the output of `brew install packageName` command and returns a list of installed packages.
+// It extracts the package names and versions from the installation summary lines.
+// Example msg:
+//
+//	==> Fetching vim
+//	==> Downloading https://ghcr.io/v2/homebrew/core/vim/manifests/9.0.1650
+//	==> Pouring vim--9.0.1650.arm64_ventura.bottle.tar.gz
+//	🍺  /opt/homebrew/Cellar/vim/9.0.1650: 2,104 files, 38.9MB
+//	==> Installing Cask firefox
+//	🍺  firefox was successfully installed!
+func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if match := cellarPattern.FindStringSubmatch(line); match != nil {
+			packages = append(packages, manager.PackageInfo{
+				Name:           match[2],
+				Version:        match[3],
+				NewVersion:     match[3],
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"type": packageType(match[1])},
+			})
+		} else if strings.HasSuffix(line, " was successfully installed!") {
+			fields := strings.Fields(strings.TrimSuffix(line, " was successfully installed!"))
+			if len(fields) == 0 {
+				continue
+			}
+			packages = append(packages, manager.PackageInfo{
+				Name:           fields[len(fields)-1],
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"type": TypeCask},
+			})
+		}
+	}
+
+	return packages
+}
+
+// ParseDryRunOutput parses the output of `brew install --dry-run packageName` command
+// and returns the packages that would be installed.
+// Example msg:
+//
+//	==> Would install 1 formula:
+//	vim
+//	==> Would install 3 dependencies for vim:
+//	gettext libsodium lua
+func ParseDryRunOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	var inSection bool
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if strings.HasPrefix(line, "==> ") {
+			inSection = strings.HasPrefix(line, "==> Would install")
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		for _, name := range strings.Fields(line) {
+			packages = append(packages, manager.PackageInfo{
+				Name:           name,
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+			})
+		}
+	}
+
+	return packages
+}
+
+// ParseDeletedOutput parses the output of `brew uninstall packageName` and `brew autoremove` commands
+// and returns a list of removed packages.
+// Example msg:
+//
+//	==> Uninstalling 1 unneeded formula:
+//	lua
+//	Uninstalling /opt/homebrew/Cellar/lua/5.4.6... (29 files, 789.0KB)
+//	Uninstalling /opt/homebrew/Cellar/vim/9.0.1650... (2,104 files, 38.9MB)
+//	==> Uninstalling Cask firefox
+//	==> Purging files for version 115.0.2 of Cask firefox
+func ParseDeletedOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if match := uninstallPattern.FindStringSubmatch(line); match != nil {
+			packages = append(packages, manager.PackageInfo{
+				Name:           match[2],
+				Version:        match[3],
+				Status:         manager.PackageStatusAvailable,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"type": packageType(match[1])},
+			})
+		} else if strings.HasPrefix(line, "==> Uninstalling Cask ") {
+			packages = append(packages, manager.PackageInfo{
+				Name:           strings.TrimSpace(strings.TrimPrefix(line, "==> Uninstalling Cask ")),
+				Status:         manager.PackageStatusAvailable,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"type": TypeCask},
+			})
+		}
+	}
+
+	return packages
+}
+
+// ParseUpgradeOutput parses the output of `brew upgrade` command, with or without `--dry-run`,
+// and returns the list of upgraded packages with their old and new versions.
+// Example msg:
+//
+//	==> Upgrading 2 outdated packages:
+//	vim 9.0.1600 -> 9.0.1650
+//	git 2.41.0 -> 2.41.0_1
+//	==> Upgrading vim
+//	  9.0.1600 -> 9.0.1650
+//	🍺  /opt/homebrew/Cellar/vim/9.0.1650: 2,104 files, 38.9MB
+func ParseUpgradeOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	var seen = make(map[string]bool)
+	var current string
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if strings.HasPrefix(line, "==> Upgrading ") {
+			fields := strings.Fields(strings.TrimPrefix(line, "==> Upgrading "))
+			current = ""
+			if len(fields) == 1 {
+				current = fields[0]
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "==> ") {
+			continue
+		}
+
+		match := upgradePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		name := strings.TrimSpace(match[1])
+		if name == "" {
+			name = current
+		}
+
+		// if name is empty, it might be not what we want
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Version:        match[2],
+			NewVersion:     match[3],
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseFindOutput parses the output of `brew search keyword` command
+// and returns a list of formulae and casks that match the search query.
+// Installed packages are marked with a check mark by brew.
+// Example msg:
+//
+//	==> Formulae
+//	vim ✔
+//	neovim
+//
+//	==> Casks
+//	macvim
+func ParseFindOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	var pkgType = TypeFormula
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		switch {
+		case line == "==> Formulae":
+			pkgType = TypeFormula
+			continue
+		case line == "==> Casks":
+			pkgType = TypeCask
+			continue
+		case strings.HasPrefix(line, "==>") || strings.HasPrefix(line, "If you meant"):
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		status := manager.PackageStatusAvailable
+		if len(fields) > 1 && fields[1] == "✔" {
+			status = manager.PackageStatusInstalled
+		}
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           fields[0],
+			Status:         status,
+			PackageManager: pm,
+			AdditionalData: map[string]string{"type": pkgType},
+		})
+	}
+
+	return packages
+}
+
+// infoJSON is the output of `brew info --json=v2`.
+type infoJSON struct {
+	Formulae []struct {
+		Name     string `json:"name"`
+		Tap      string `json:"tap"`
+		Desc     string `json:"desc"`
+		License  string `json:"license"`
+		Homepage string `json:"homepage"`
+		Versions struct {
+			Stable string `json:"stable"`
+		} `json:"versions"`
+		Installed []struct {
+			Version string `json:"version"`
+		} `json:"installed"`
+		Outdated bool `json:"outdated"`
+		Pinned   bool `json:"pinned"`
+	} `json:"formulae"`
+	Casks []struct {
+		Token     string  `json:"token"`
+		Tap       string  `json:"tap"`
+		Desc      string  `json:"desc"`
+		Homepage  string  `json:"homepage"`
+		Version   string  `json:"version"`
+		Installed *string `json:"installed"`
+		Outdated  bool    `json:"outdated"`
+	} `json:"casks"`
+}
+
+// ParseInfoJSONOutput parses the output of `brew info --json=v2 [--installed | packageName]` command
+// and returns a list of packages with their installed and latest versions, license and homepage.
+// Example msg:
+//
+//	{
+//	  "formulae": [
+//	    {"name": "vim", "tap": "homebrew/core", "versions": {"stable": "9.0.1650"},
+//	     "installed": [{"version": "9.0.1600"}], "outdated": true}
+//	  ],
+//	  "casks": [
+//	    {"token": "firefox", "tap": "homebrew/cask", "version": "116.0", "installed": "116.0", "outdated": false}
+//	  ]
+//	}
+func ParseInfoJSONOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var info infoJSON
+
+	if err := json.Unmarshal(msg, &info); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	for _, f := range info.Formulae {
+		packageInfo := manager.PackageInfo{
+			Name:           f.Name,
+			NewVersion:     f.Versions.Stable,
+			Category:       f.Tap,
+			Origin:         f.Tap,
+			Status:         manager.PackageStatusAvailable,
+			License:        f.License,
+			Homepage:       f.Homepage,
+			PackageManager: pm,
+			AdditionalData: map[string]string{"type": TypeFormula},
+		}
+		if len(f.Installed) > 0 {
+			packageInfo.Version = f.Installed[len(f.Installed)-1].Version
+			packageInfo.Status = manager.PackageStatusInstalled
+			if f.Outdated {
+				packageInfo.Status = manager.PackageStatusUpgradable
+			} else {
+				packageInfo.NewVersion = ""
+			}
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	for _, c := range info.Casks {
+		packageInfo := manager.PackageInfo{
+			Name:           c.Token,
+			NewVersion:     c.Version,
+			Category:       c.Tap,
+			Origin:         c.Tap,
+			Status:         manager.PackageStatusAvailable,
+			Homepage:       c.Homepage,
+			PackageManager: pm,
+			AdditionalData: map[string]string{"type": TypeCask},
+		}
+		if c.Installed != nil {
+			packageInfo.Version = *c.Installed
+			packageInfo.Status = manager.PackageStatusInstalled
+			if c.Outdated {
+				packageInfo.Status = manager.PackageStatusUpgradable
+			} else {
+				packageInfo.NewVersion = ""
+			}
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// outdatedJSON is the output of `brew outdated --json=v2`.
+type outdatedJSON struct {
+	Formulae []outdatedPackageJSON `json:"formulae"`
+	Casks    []outdatedPackageJSON `json:"casks"`
+}
+
+// outdatedPackageJSON describes a single outdated formula or cask.
+type outdatedPackageJSON struct {
+	Name              string   `json:"name"`
+	InstalledVersions []string `json:"installed_versions"`
+	CurrentVersion    string   `json:"current_version"`
+	Pinned            bool     `json:"pinned"`
+}
+
+// ParseOutdatedOutput parses the output of `brew outdated --json=v2` command
+// and returns a list of upgradable packages. Pinned formulae are not upgraded by brew, so their status is set to held.
+// Example msg:
+//
+//	{
+//	  "formulae": [
+//	    {"name": "vim", "installed_versions": ["9.0.1600"], "current_version": "9.0.1650", "pinned": false, "pinned_version": null}
+//	  ],
+//	  "casks": [
+//	    {"name": "firefox", "installed_versions": ["115.0.2"], "current_version": "116.0"}
+//	  ]
+//	}
+func ParseOutdatedOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var outdated outdatedJSON
+
+	if err := json.Unmarshal(msg, &outdated); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	sections := []struct {
+		pkgType  string
+		packages []outdatedPackageJSON
+	}{
+		{TypeFormula, outdated.Formulae},
+		{TypeCask, outdated.Casks},
+	}
+
+	for _, section := range sections {
+		for _, p := range section.packages {
+			status := manager.PackageStatusUpgradable
+			if p.Pinned {
+				status = manager.PackageStatusHeld
+			}
+
+			var version string
+			if len(p.InstalledVersions) > 0 {
+				version = p.InstalledVersions[len(p.InstalledVersions)-1]
+			}
+
+			packages = append(packages, manager.PackageInfo{
+				Name:           p.Name,
+				Version:        version,
+				NewVersion:     p.CurrentVersion,
+				Status:         status,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"type": section.pkgType},
+			})
+		}
+	}
+
+	return packages, nil
+}
+
+// ParsePinnedOutput parses the output of `brew list --pinned --versions` command
+// and returns the pinned formulae, with their status set to held.
+// Example msg:
+//
+//	node 20.3.0
+//	python@3.11 3.11.4_1
+func ParsePinnedOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           fields[0],
+			Status:         manager.PackageStatusHeld,
+			PackageManager: pm,
+			AdditionalData: map[string]string{"type": TypeFormula},
+		}
+		if len(fields) > 1 {
+			packageInfo.Version = fields[len(fields)-1]
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// packageType returns the Homebrew package type for the directory a package is installed in.
+func packageType(dir string) string {
+	if dir == "Caskroom" {
+		return TypeCask
+	}
+	return TypeFormula
+}
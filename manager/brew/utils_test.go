@@ -0,0 +1,195 @@
+package brew_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/brew"
+)
+
+func TestParseOutdatedOutput(t *testing.T) {
+	var inputParseOutdatedOutput string = `{
+  "formulae": [
+    {"name": "vim", "installed_versions": ["9.0.1600"], "current_version": "9.0.1650", "pinned": false, "pinned_version": null},
+    {"name": "node", "installed_versions": ["20.3.0"], "current_version": "20.5.0", "pinned": true, "pinned_version": "20.3.0"}
+  ],
+  "casks": [
+    {"name": "firefox", "installed_versions": ["115.0.2"], "current_version": "116.0"}
+  ]
+}`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "vim",
+			Version:        "9.0.1600",
+			NewVersion:     "9.0.1650",
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: "brew",
+			AdditionalData: map[string]string{"type": brew.TypeFormula},
+		},
+		{
+			Name:           "node",
+			Version:        "20.3.0",
+			NewVersion:     "20.5.0",
+			Status:         manager.PackageStatusHeld,
+			PackageManager: "brew",
+			AdditionalData: map[string]string{"type": brew.TypeFormula},
+		},
+		{
+			Name:           "firefox",
+			Version:        "115.0.2",
+			NewVersion:     "116.0",
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: "brew",
+			AdditionalData: map[string]string{"type": brew.TypeCask},
+		},
+	}
+
+	actualPackageInfo, err := brew.ParseOutdatedOutput([]byte(inputParseOutdatedOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseOutdatedOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseOutdatedOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseInfoJSONOutput(t *testing.T) {
+	var inputParseInfoJSONOutput string = `{
+  "formulae": [
+    {"name": "vim", "tap": "homebrew/core", "license": "Vim", "homepage": "https://www.vim.org/", "versions": {"stable": "9.0.1650"}, "installed": [{"version": "9.0.1600"}], "outdated": true},
+    {"name": "neovim", "tap": "homebrew/core", "versions": {"stable": "0.9.1"}, "installed": [], "outdated": false}
+  ],
+  "casks": [
+    {"token": "firefox", "tap": "homebrew/cask", "version": "116.0", "installed": "116.0", "outdated": false}
+  ]
+}`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "vim",
+			Version:        "9.0.1600",
+			NewVersion:     "9.0.1650",
+			Status:         manager.PackageStatusUpgradable,
+			Category:       "homebrew/core",
+			Origin:         "homebrew/core",
+			License:        "Vim",
+			Homepage:       "https://www.vim.org/",
+			PackageManager: "brew",
+			AdditionalData: map[string]string{"type": brew.TypeFormula},
+		},
+		{
+			Name:           "neovim",
+			NewVersion:     "0.9.1",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "homebrew/core",
+			Origin:         "homebrew/core",
+			PackageManager: "brew",
+			AdditionalData: map[string]string{"type": brew.TypeFormula},
+		},
+		{
+			Name:           "firefox",
+			Version:        "116.0",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "homebrew/cask",
+			Origin:         "homebrew/cask",
+			PackageManager: "brew",
+			AdditionalData: map[string]string{"type": brew.TypeCask},
+		},
+	}
+
+	actualPackageInfo, err := brew.ParseInfoJSONOutput([]byte(inputParseInfoJSONOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseInfoJSONOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseInfoJSONOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseUpgradeOutput(t *testing.T) {
+	var inputParseUpgradeOutput string = strings.Join([]string{
+		`==> Upgrading 2 outdated packages:`,
+		`vim 9.0.1600 -> 9.0.1650`,
+		`git 2.41.0 -> 2.41.0_1`,
+		`==> Fetching vim`,
+		`==> Upgrading vim`,
+		`  9.0.1600 -> 9.0.1650`,
+		`🍺  /opt/homebrew/Cellar/vim/9.0.1650: 2,104 files, 38.9MB`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "vim",
+			Version:        "9.0.1600",
+			NewVersion:     "9.0.1650",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "brew",
+		},
+		{
+			Name:           "git",
+			Version:        "2.41.0",
+			NewVersion:     "2.41.0_1",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "brew",
+		},
+	}
+
+	actualPackageInfo := brew.ParseUpgradeOutput(inputParseUpgradeOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseUpgradeOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseDeletedOutput(t *testing.T) {
+	var inputParseDeletedOutput string = strings.Join([]string{
+		`Uninstalling /opt/homebrew/Cellar/vim/9.0.1650... (2,104 files, 38.9MB)`,
+		`==> Uninstalling Cask firefox`,
+		`==> Purging files for version 116.0 of Cask firefox`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "vim",
+			Version:        "9.0.1650",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "brew",
+			AdditionalData: map[string]string{"type": brew.TypeFormula},
+		},
+		{
+			Name:           "firefox",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "brew",
+			AdditionalData: map[string]string{"type": brew.TypeCask},
+		},
+	}
+
+	actualPackageInfo := brew.ParseDeletedOutput(inputParseDeletedOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDeletedOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParsePinnedOutput(t *testing.T) {
+	var inputParsePinnedOutput string = strings.Join([]string{
+		`node 20.3.0`,
+		`python@3.11 3.11.4_1`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "node", Version: "20.3.0", Status: manager.PackageStatusHeld, PackageManager: "brew", AdditionalData: map[string]string{"type": brew.TypeFormula}},
+		{Name: "python@3.11", Version: "3.11.4_1", Status: manager.PackageStatusHeld, PackageManager: "brew", AdditionalData: map[string]string{"type": brew.TypeFormula}},
+	}
+
+	actualPackageInfo := brew.ParsePinnedOutput(inputParsePinnedOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParsePinnedOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
@@ -0,0 +1,269 @@
+// Package cache stores the results of package manager queries, such as searches and lists of installed packages,
+// so that repeating them doesn't run the package managers again.
+//
+// Results are stored as JSON files in a directory per package manager, named after the operation and a hash of its
+// arguments, and are used until they are older than the TTL of the cache. The results of a package manager should be
+// invalidated whenever its packages change, e.g. after an install or a refresh.
+//
+// This package is part of the syspkg library.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// DefaultTTL is how long results are cached by default.
+const DefaultTTL = 15 * time.Minute
+
+// statsFile is the file of a package manager directory counting the cache hits and misses.
+const statsFile = "stats.json"
+
+// Cache is a cache of query results stored in a directory. A nil Cache caches nothing.
+// It is safe to use concurrently, including by several processes.
+type Cache struct {
+	dir string
+	ttl time.Duration
+
+	mu sync.Mutex
+}
+
+// entry is a cached result, stored as a JSON file.
+type entry struct {
+	Time  time.Time       `json:"time"`
+	Key   []string        `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Stats are statistics about the cached results of a package manager.
+type Stats struct {
+	// Entries is the number of cached results, including expired ones.
+	Entries int `json:"entries"`
+
+	// Expired is the number of cached results older than the TTL.
+	Expired int `json:"expired"`
+
+	// Bytes is the size of the cached results.
+	Bytes int64 `json:"bytes"`
+
+	// Hits and Misses count the lookups that found a result, or not, since the cache was last cleared.
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// New returns a Cache storing results in dir, and using them until they are older than ttl (DefaultTTL if zero).
+func New(dir string, ttl time.Duration) *Cache {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// DefaultDir returns the default cache directory: /var/cache/syspkg for root, and syspkg in the user cache directory
+// (~/.cache on Linux, honoring $XDG_CACHE_HOME) for other users.
+func DefaultDir() string {
+	if runtime.GOOS != "windows" && os.Geteuid() == 0 {
+		return "/var/cache/syspkg"
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "syspkg")
+}
+
+// Dir returns the directory of the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Get reads the cached result of operation op of package manager pm, with arguments key, into v.
+// The order of the arguments doesn't matter. It reports whether a result was found that is not older than the TTL.
+func (c *Cache) Get(pm string, op string, key []string, v any) bool {
+	if c == nil {
+		return false
+	}
+
+	var e entry
+	data, err := os.ReadFile(c.path(pm, op, key))
+	found := err == nil && json.Unmarshal(data, &e) == nil && time.Since(e.Time) < c.ttl && json.Unmarshal(e.Value, v) == nil
+	c.count(pm, found)
+	return found
+}
+
+// Put caches v as the result of operation op of package manager pm, with arguments key.
+func (c *Cache) Put(pm string, op string, key []string, v any) error {
+	if c == nil {
+		return nil
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry{Time: time.Now().UTC(), Key: key, Value: value})
+	if err != nil {
+		return err
+	}
+
+	path := c.path(pm, op, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// write to a temporary file renamed into place, so that concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Query returns the cached packages of operation op of package manager pm, with arguments key, if any;
+// otherwise it runs query, and caches its result if it succeeds. Failing to cache a result is not an error.
+func (c *Cache) Query(pm string, op string, key []string, query func() ([]manager.PackageInfo, error)) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	if c.Get(pm, op, key, &packages) {
+		return packages, nil
+	}
+
+	packages, err := query()
+	if err == nil {
+		_ = c.Put(pm, op, key, packages)
+	}
+	return packages, err
+}
+
+// Invalidate removes the cached results of package manager pm, keeping its statistics.
+func (c *Cache) Invalidate(pm string) error {
+	if c == nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(c.dir, pm))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Name() == statsFile {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, pm, e.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear removes all the cached results and statistics.
+func (c *Cache) Clear() error {
+	if c == nil {
+		return nil
+	}
+	return os.RemoveAll(c.dir)
+}
+
+// Stats returns statistics about the cached results, by package manager name.
+func (c *Cache) Stats() (map[string]Stats, error) {
+	stats := make(map[string]Stats)
+	if c == nil {
+		return stats, nil
+	}
+
+	dirs, err := os.ReadDir(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		pm := dir.Name()
+		s := c.readStats(pm)
+		entries, err := os.ReadDir(filepath.Join(c.dir, pm))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Name() == statsFile || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			s.Entries++
+			s.Bytes += info.Size()
+			if time.Since(info.ModTime()) >= c.ttl {
+				s.Expired++
+			}
+		}
+		stats[pm] = s
+	}
+	return stats, nil
+}
+
+// path returns the path of the file caching the result of operation op of package manager pm, with arguments key.
+func (c *Cache) path(pm string, op string, key []string) string {
+	sorted := append([]string(nil), key...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\x00")))
+	return filepath.Join(c.dir, pm, op+"-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// count records a cache hit or miss in the statistics of package manager pm.
+// The statistics are best effort: concurrent processes may lose some counts.
+func (c *Cache) count(pm string, hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.readStats(pm)
+	if hit {
+		s.Hits++
+	} else {
+		s.Misses++
+	}
+	data, err := json.Marshal(struct {
+		Hits   int64 `json:"hits"`
+		Misses int64 `json:"misses"`
+	}{s.Hits, s.Misses})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Join(c.dir, pm), 0o755); err == nil {
+		_ = os.WriteFile(filepath.Join(c.dir, pm, statsFile), data, 0o644)
+	}
+}
+
+// readStats reads the hits and misses of package manager pm.
+func (c *Cache) readStats(pm string) Stats {
+	var s Stats
+	if data, err := os.ReadFile(filepath.Join(c.dir, pm, statsFile)); err == nil {
+		_ = json.Unmarshal(data, &s)
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package cache_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/cache"
+)
+
+func TestQuery(t *testing.T) {
+	c := cache.New(t.TempDir(), time.Hour)
+	want := []manager.PackageInfo{{Name: "vim", Version: "2:8.2.3995-1ubuntu2.15", Status: manager.PackageStatusInstalled, PackageManager: "apt"}}
+
+	calls := 0
+	query := func() ([]manager.PackageInfo, error) {
+		calls++
+		return want, nil
+	}
+	for i := 0; i < 2; i++ {
+		got, err := c.Query("apt", "find", []string{"vim", "editor"}, query)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Query() = %+v, %+v, want %+v", got, err, want)
+		}
+	}
+	// the order of the keywords doesn't matter
+	if _, err := c.Query("apt", "find", []string{"editor", "vim"}, query); err != nil || calls != 1 {
+		t.Errorf("Query() ran the query %d times, want 1", calls)
+	}
+
+	if err := c.Invalidate("apt"); err != nil {
+		t.Fatalf("Invalidate() error = %+v", err)
+	}
+	if _, err := c.Query("apt", "find", []string{"vim", "editor"}, query); err != nil || calls != 2 {
+		t.Errorf("Query() ran the query %d times after Invalidate(), want 2", calls)
+	}
+
+	stats, err := c.Stats()
+	wantStats := cache.Stats{Entries: 1, Hits: 2, Misses: 2}
+	if err != nil || stats["apt"].Entries != wantStats.Entries || stats["apt"].Hits != wantStats.Hits || stats["apt"].Misses != wantStats.Misses {
+		t.Errorf("Stats() = %+v, %+v, want %+v", stats["apt"], err, wantStats)
+	}
+
+	// failed queries are not cached
+	failure := errors.New("apt is locked")
+	if _, err := c.Query("apt", "list", nil, func() ([]manager.PackageInfo, error) { return nil, failure }); !errors.Is(err, failure) {
+		t.Errorf("Query() error = %+v, want %+v", err, failure)
+	}
+	var packages []manager.PackageInfo
+	if c.Get("apt", "list", nil, &packages) {
+		t.Errorf("Get() found the result of a failed query")
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	c := cache.New(t.TempDir(), time.Nanosecond)
+	if err := c.Put("pip", "list", nil, []string{"requests"}); err != nil {
+		t.Fatalf("Put() error = %+v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	var names []string
+	if c.Get("pip", "list", nil, &names) {
+		t.Errorf("Get() = %+v, want no result once expired", names)
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	var c *cache.Cache
+	calls := 0
+	for i := 0; i < 2; i++ {
+		_, _ = c.Query("apt", "find", []string{"vim"}, func() ([]manager.PackageInfo, error) {
+			calls++
+			return nil, nil
+		})
+	}
+	if calls != 2 {
+		t.Errorf("Query() ran the query %d times with a nil cache, want 2", calls)
+	}
+}
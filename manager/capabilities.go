@@ -0,0 +1,117 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+// Capabilities describes the operations and options supported by a package manager, so that callers can check for
+// them up front, rather than get ErrOperationNotSupported or ErrVersionNotSupported errors.
+type Capabilities struct {
+	// Search is set if Find can search packages.
+	Search bool `json:"search"`
+
+	// RegexSearch is set if Find accepts POSIX extended regular expressions as keywords, such as "lib.*ssl".
+	RegexSearch bool `json:"regex_search"`
+
+	// Delete is set if Delete can remove packages.
+	Delete bool `json:"delete"`
+
+	// VersionedInstall is set if Install accepts "name=version" specifiers.
+	VersionedInstall bool `json:"versioned_install"`
+
+	// DryRun is set if Options.DryRun reports the changes without making them.
+	DryRun bool `json:"dry_run"`
+
+	// Scope is set if Options.Scope selects the per-user or system-wide installation.
+	Scope bool `json:"scope"`
+
+	// RootDir is set if Options.RootDir selects the system to operate on.
+	RootDir bool `json:"root_dir"`
+
+	// Architecture is set if Options.Architecture selects the architecture of the packages to install or remove.
+	Architecture bool `json:"architecture"`
+
+	// ListUpgradable is set if ListUpgradable can list the packages having a newer version.
+	ListUpgradable bool `json:"list_upgradable"`
+
+	// Upgrade is set if specific packages can be upgraded (syspkg.Upgrader).
+	Upgrade bool `json:"upgrade"`
+
+	// Downgrade is set if packages can be downgraded (syspkg.Downgrader).
+	Downgrade bool `json:"downgrade"`
+
+	// Hold is set if packages can be held at their version (syspkg.Holder).
+	Hold bool `json:"hold"`
+
+	// SecurityUpdates is set if security updates can be told apart (syspkg.SecurityUpdateLister).
+	SecurityUpdates bool `json:"security_updates"`
+
+	// Dependencies is set if the dependencies of packages can be queried (syspkg.DependencyQuerier).
+	Dependencies bool `json:"dependencies"`
+
+	// FileOwner is set if the package owning a file can be found (syspkg.FileOwnerQuerier).
+	FileOwner bool `json:"file_owner"`
+
+	// FileList is set if the files of packages can be listed (syspkg.FileLister).
+	FileList bool `json:"file_list"`
+
+	// Keys is set if repository signing keys can be managed (syspkg.KeyManager).
+	Keys bool `json:"keys"`
+
+	// Download is set if packages can be downloaded without installing them (syspkg.Downloader).
+	Download bool `json:"download"`
+
+	// PackageNames is set if the names of the available packages can be listed (syspkg.PackageNameLister).
+	PackageNames bool `json:"package_names"`
+
+	// LocalInstall is set if package files can be installed (syspkg.LocalInstaller).
+	LocalInstall bool `json:"local_install"`
+
+	// Repositories is set if the repositories can be managed (syspkg.RepositoryManager).
+	Repositories bool `json:"repositories"`
+
+	// Reboot is set if the reboots required by upgrades can be detected (syspkg.RebootChecker).
+	Reboot bool `json:"reboot"`
+
+	// Verify is set if the installed packages can be verified (syspkg.Verifier).
+	Verify bool `json:"verify"`
+
+	// Groups is set if groups of packages can be installed (syspkg.GroupManager).
+	Groups bool `json:"groups"`
+}
+
+// Names returns the names of the supported capabilities, as in JSON, e.g. ["search", "delete", "dry_run"].
+func (c Capabilities) Names() []string {
+	var names []string
+	for _, capability := range []struct {
+		name      string
+		supported bool
+	}{
+		{"search", c.Search},
+		{"regex_search", c.RegexSearch},
+		{"delete", c.Delete},
+		{"versioned_install", c.VersionedInstall},
+		{"dry_run", c.DryRun},
+		{"scope", c.Scope},
+		{"root_dir", c.RootDir},
+		{"architecture", c.Architecture},
+		{"list_upgradable", c.ListUpgradable},
+		{"upgrade", c.Upgrade},
+		{"downgrade", c.Downgrade},
+		{"hold", c.Hold},
+		{"security_updates", c.SecurityUpdates},
+		{"dependencies", c.Dependencies},
+		{"file_owner", c.FileOwner},
+		{"file_list", c.FileList},
+		{"keys", c.Keys},
+		{"download", c.Download},
+		{"package_names", c.PackageNames},
+		{"local_install", c.LocalInstall},
+		{"repositories", c.Repositories},
+		{"reboot", c.Reboot},
+		{"verify", c.Verify},
+		{"groups", c.Groups},
+	} {
+		if capability.supported {
+			names = append(names, capability.name)
+		}
+	}
+	return names
+}
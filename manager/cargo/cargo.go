@@ -0,0 +1,326 @@
+// Package cargo provides an implementation of the syspkg manager interface for Rust binaries installed with cargo.
+// It provides a Go (golang) API interface for interacting with cargo, the Rust package manager.
+// This package is a wrapper around the cargo command line tool.
+//
+// Besides building Rust projects, cargo can install the binaries of crates published on crates.io (cargo install),
+// which is how many command line tools written in Rust are distributed. Only these installed binaries are managed by this package.
+// cargo itself cannot tell which installed crates are outdated; if the cargo-update plugin (cargo install-update) is installed,
+// it is used to list and upgrade outdated crates.
+//
+// For more information about cargo, visit:
+// - https://doc.rust-lang.org/cargo/commands/cargo-install.html
+// - https://github.com/nabijaczleweli/cargo-update
+//
+// This package is part of the syspkg library.
+package cargo
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "cargo"
+
+// Constants used for cargo commands
+const (
+	ArgsList  string = "--list"
+	ArgsLimit string = "--limit"
+	ArgsAll   string = "--all"
+)
+
+// cargoUpdate is the executable of the cargo-update plugin, run as `cargo install-update`.
+var cargoUpdate string = "cargo-install-update"
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for cargo.
+var ENV_NonInteractive []string = []string{"LC_ALL=C", "CARGO_TERM_COLOR=never", "CARGO_TERM_PROGRESS_WHEN=never"}
+
+// PackageManager implements the manager.PackageManager interface for Rust binaries installed with cargo.
+type PackageManager struct{}
+
+// IsAvailable checks if the cargo package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(pm)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the cargo package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by cargo.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:           true,
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		ListUpgradable:   a.HasCargoUpdate(),
+		Upgrade:          true,
+	}
+}
+
+// HasCargoUpdate checks if the cargo-update plugin is installed, which is required to list outdated crates.
+func (a *PackageManager) HasCargoUpdate() bool {
+	_, err := exec.LookPath(cargoUpdate)
+	return err == nil
+}
+
+// Install builds and installs the provided crates using cargo.
+// Crates can be given with a version, e.g. "ripgrep@13.0.0".
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// cargo installs a specific version with name@version
+	pkgs, err := manager.TranslatePackageSpecs(pkgs, func(spec manager.PackageSpec) string { return spec.Name + "@" + spec.Version })
+	if err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// cargo install has no dry-run mode, so only report what was requested
+	if opts.DryRun {
+		var packages []manager.PackageInfo
+		for _, pkg := range pkgs {
+			name, version := splitCrateSpec(pkg)
+			packages = append(packages, manager.PackageInfo{
+				Name:           name,
+				Version:        version,
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+			})
+		}
+		return packages, nil
+	}
+
+	args := append([]string{"install"}, pkgs...)
+	if err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+
+	// cargo install reports progress on stderr only, so look up the installed versions
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	return filterPackages(installed, pkgs), nil
+}
+
+// Delete uninstalls the provided crates using cargo.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// look up the installed versions first, as they can't be queried after removal
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	packages := filterPackages(installed, pkgs)
+	for i := range packages {
+		packages[i].Status = manager.PackageStatusAvailable
+	}
+	if opts.DryRun {
+		return packages, nil
+	}
+
+	args := append([]string{"uninstall"}, pkgs...)
+	if err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+	return packages, nil
+}
+
+// Refresh does nothing for cargo, as cargo updates the crates.io index whenever it needs it.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	return nil
+}
+
+// Find searches crates.io for crates matching the provided keywords.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"search", ArgsLimit, "50"}, keywords...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseFindOutput(string(out), opts), nil
+}
+
+// ListInstalled lists all crates installed with cargo install.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "install", ArgsList)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseListInstalledOutput(string(out), opts), nil
+}
+
+// ListUpgradable lists all installed crates that have a newer version on crates.io.
+// It requires the cargo-update plugin, and returns manager.ErrOperationNotSupported if it is not installed.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	if !a.HasCargoUpdate() {
+		return nil, manager.ErrOperationNotSupported
+	}
+
+	cmd := manager.Command(opts, pm, "install-update", ArgsList)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseInstallUpdateListOutput(string(out), opts), nil
+}
+
+// Upgrade upgrades the provided crates to their newest version.
+// If no crates are given, all installed crates are upgraded.
+// The cargo-update plugin is used if it is installed; otherwise the crates are reinstalled with cargo install,
+// which only rebuilds crates that have a newer version.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	before, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		if !a.HasCargoUpdate() {
+			return nil, manager.ErrOperationNotSupported
+		}
+		outdated, err := a.ListUpgradable(opts)
+		if err != nil {
+			return nil, err
+		}
+		return filterPackages(outdated, pkgs), nil
+	}
+
+	var args []string
+	switch {
+	case a.HasCargoUpdate() && len(pkgs) == 0:
+		args = []string{"install-update", ArgsAll}
+	case a.HasCargoUpdate():
+		args = append([]string{"install-update"}, pkgs...)
+	default:
+		names := pkgs
+		if len(names) == 0 {
+			for _, pkg := range before {
+				names = append(names, pkg.Name)
+			}
+		}
+		if len(names) == 0 {
+			return nil, nil
+		}
+		args = append([]string{"install"}, names...)
+	}
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	if err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+
+	after, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	return diffVersions(before, after), nil
+}
+
+// UpgradeAll upgrades all installed crates.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// GetPackageInfo retrieves information about the specified crate from crates.io,
+// along with the installed version, if any.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	found, err := a.Find([]string{pkg}, opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+
+	info := manager.PackageInfo{Name: pkg, Status: manager.PackageStatusUnknown, PackageManager: pm}
+	for _, p := range found {
+		if p.Name == pkg {
+			info = p
+			break
+		}
+	}
+
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	for _, p := range installed {
+		if p.Name != pkg {
+			continue
+		}
+		info.Version = p.Version
+		info.AdditionalData = p.AdditionalData
+		info.Status = manager.PackageStatusInstalled
+		if info.NewVersion != "" && info.NewVersion != info.Version {
+			info.Status = manager.PackageStatusUpgradable
+		} else {
+			info.NewVersion = ""
+		}
+	}
+	return info, nil
+}
+
+// run runs cargo with the given arguments, either attached to the terminal in interactive mode,
+// or non-interactively, logging the output in verbose mode.
+// cargo reports its progress on stderr, so both stdout and stderr are captured.
+func (a *PackageManager) run(args []string, opts *manager.Options) error {
+	cmd := manager.Command(opts, pm, args...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return manager.Run(cmd)
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.CombinedOutput(cmd)
+	if opts.Verbose || err != nil {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return err
+}
@@ -0,0 +1,193 @@
+// Package cargo provides a package manager implementation for Rust binaries
+// using cargo as the underlying package management tool.
+package cargo
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// installedPattern matches the crate lines of `cargo install --list`, e.g. "ripgrep v13.0.0:" or
+// "foo v0.1.0 (https://github.com/user/foo#0a1b2c3d):", capturing the name, the version and the optional source.
+var installedPattern = regexp.MustCompile(`^(\S+) v(\S+?)(?: \((.+)\))?:$`)
+
+// searchPattern matches the result lines of `cargo search`, e.g. `ripgrep = "13.0.0"    # ripgrep is a line-oriented search tool`.
+var searchPattern = regexp.MustCompile(`^(\S+) = "([^"]+)"\s*(?:#\s*(.*))?$`)
+
+// ParseListInstalledOutput parses the output of `cargo install --list` command
+// and returns a list of installed crates. The binaries installed by each crate are kept in AdditionalData["binaries"].
+// Example msg:
+//
+//	bat v0.23.0:
+//	    bat
+//	ripgrep v13.0.0:
+//	    rg
+//	foo v0.1.0 (https://github.com/user/foo#0a1b2c3d):
+//	    foo
+//	    foo-cli
+func ParseListInstalledOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	var binaries []string
+
+	flush := func() {
+		if len(packages) > 0 && len(binaries) > 0 {
+			packages[len(packages)-1].AdditionalData["binaries"] = strings.Join(binaries, " ")
+		}
+		binaries = nil
+	}
+
+	for _, line := range strings.Split(msg, "\n") {
+		if line == "" {
+			continue
+		}
+
+		// binaries are indented below their crate
+		if strings.HasPrefix(line, " ") {
+			binaries = append(binaries, strings.TrimSpace(line))
+			continue
+		}
+
+		match := installedPattern.FindStringSubmatch(line)
+		if match == nil {
+			if opts != nil && opts.Verbose {
+				opts.Log().Debug("Unexpected line", "package_manager", pm, "line", line)
+			}
+			continue
+		}
+
+		flush()
+		packageInfo := manager.PackageInfo{
+			Name:           match[1],
+			Version:        match[2],
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+			AdditionalData: map[string]string{},
+		}
+		if match[3] != "" {
+			packageInfo.AdditionalData["source"] = match[3]
+		}
+		packages = append(packages, packageInfo)
+	}
+	flush()
+
+	return packages
+}
+
+// ParseFindOutput parses the output of `cargo search keyword` command
+// and returns a list of crates that match the search query.
+// Example msg:
+//
+//	ripgrep = "13.0.0"              # ripgrep is a line-oriented search tool that recursively searches the current dire…
+//	ripgrep_all = "0.9.6"           # rga: ripgrep, but also search in PDFs, E-Books, Office documents, zip, tar.gz, etc.
+//	... and 94 crates more (use --limit N to see more)
+func ParseFindOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		match := searchPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           match[1],
+			NewVersion:     match[2],
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: pm,
+		}
+		if match[3] != "" {
+			packageInfo.AdditionalData = map[string]string{"description": match[3]}
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseInstallUpdateListOutput parses the output of `cargo install-update --list` command (from the cargo-update plugin)
+// and returns a list of crates that need an update.
+// Example msg:
+//
+//	    Updating registry 'https://github.com/rust-lang/crates.io-index'
+//
+//	Package         Installed  Latest   Needs update
+//	bat             v0.23.0    v0.24.0  Yes
+//	ripgrep         v13.0.0    v13.0.0  No
+func ParseInstallUpdateListOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	inTable := false
+
+	for _, line := range strings.Split(msg, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == "Package" {
+			inTable = true
+			continue
+		}
+		if !inTable || len(fields) < 4 {
+			continue
+		}
+		if fields[len(fields)-1] != "Yes" {
+			continue
+		}
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           fields[0],
+			Version:        strings.TrimPrefix(fields[1], "v"),
+			NewVersion:     strings.TrimPrefix(fields[2], "v"),
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// splitCrateSpec splits a crate specification as accepted by cargo install, e.g. "ripgrep@13.0.0", into its name and version.
+func splitCrateSpec(spec string) (string, string) {
+	name, version, _ := strings.Cut(spec, "@")
+	return name, version
+}
+
+// filterPackages returns the packages whose name is one of the given crates, or all packages if no crates are given.
+func filterPackages(packages []manager.PackageInfo, specs []string) []manager.PackageInfo {
+	if len(specs) == 0 {
+		return packages
+	}
+
+	wanted := make(map[string]bool)
+	for _, spec := range specs {
+		name, _ := splitCrateSpec(spec)
+		wanted[name] = true
+	}
+
+	var filtered []manager.PackageInfo
+	for _, pkg := range packages {
+		if wanted[pkg.Name] {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
+
+// diffVersions returns the crates whose version changed between the before and after lists,
+// with Version set to the old version and NewVersion set to the new one.
+func diffVersions(before, after []manager.PackageInfo) []manager.PackageInfo {
+	oldVersions := make(map[string]string)
+	for _, pkg := range before {
+		oldVersions[pkg.Name] = pkg.Version
+	}
+
+	var packages []manager.PackageInfo
+	for _, pkg := range after {
+		oldVersion, ok := oldVersions[pkg.Name]
+		if !ok || oldVersion == pkg.Version {
+			continue
+		}
+		pkg.NewVersion = pkg.Version
+		pkg.Version = oldVersion
+		packages = append(packages, pkg)
+	}
+	return packages
+}
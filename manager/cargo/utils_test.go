@@ -0,0 +1,99 @@
+package cargo_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/cargo"
+)
+
+func TestParseListInstalledOutput(t *testing.T) {
+	var inputParseListInstalledOutput string = strings.Join([]string{
+		`bat v0.23.0:`,
+		`    bat`,
+		`foo v0.1.0 (https://github.com/user/foo#0a1b2c3d):`,
+		`    foo`,
+		`    foo-cli`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "bat",
+			Version:        "0.23.0",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "cargo",
+			AdditionalData: map[string]string{"binaries": "bat"},
+		},
+		{
+			Name:           "foo",
+			Version:        "0.1.0",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "cargo",
+			AdditionalData: map[string]string{"binaries": "foo foo-cli", "source": "https://github.com/user/foo#0a1b2c3d"},
+		},
+	}
+
+	actualPackageInfo := cargo.ParseListInstalledOutput(inputParseListInstalledOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListInstalledOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseFindOutput(t *testing.T) {
+	var inputParseFindOutput string = strings.Join([]string{
+		`ripgrep = "13.0.0"              # ripgrep is a line-oriented search tool`,
+		`ripgrep_all = "0.9.6"`,
+		`... and 94 crates more (use --limit N to see more)`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "ripgrep",
+			NewVersion:     "13.0.0",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "cargo",
+			AdditionalData: map[string]string{"description": "ripgrep is a line-oriented search tool"},
+		},
+		{
+			Name:           "ripgrep_all",
+			NewVersion:     "0.9.6",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "cargo",
+		},
+	}
+
+	actualPackageInfo := cargo.ParseFindOutput(inputParseFindOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseFindOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseInstallUpdateListOutput(t *testing.T) {
+	var inputParseInstallUpdateListOutput string = strings.Join([]string{
+		`    Updating registry 'https://github.com/rust-lang/crates.io-index'`,
+		``,
+		`Package         Installed  Latest   Needs update`,
+		`bat             v0.23.0    v0.24.0  Yes`,
+		`ripgrep         v13.0.0    v13.0.0  No`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "bat",
+			Version:        "0.23.0",
+			NewVersion:     "0.24.0",
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: "cargo",
+		},
+	}
+
+	actualPackageInfo := cargo.ParseInstallUpdateListOutput(inputParseInstallUpdateListOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseInstallUpdateListOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
@@ -0,0 +1,56 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Category describes the kind of software a package manager is responsible for.
+// It is used to select groups of package managers, e.g. only the ones managing the operating system itself.
+type Category string
+
+// Category constants define the known package manager categories.
+const (
+	// CategorySystem represents the native package managers of the operating system, such as apt, pacman or zypper.
+	CategorySystem Category = "system"
+
+	// CategoryUniversal represents the package managers of sandboxed applications running on any distribution, such
+	// as Flatpak or snap.
+	CategoryUniversal Category = "universal"
+
+	// CategoryUser represents package managers that install software into a user-owned prefix rather than the base system, such as Homebrew or nix profiles.
+	CategoryUser Category = "user"
+
+	// CategoryLanguage represents the package managers of programming language ecosystems, such as npm or pip.
+	CategoryLanguage Category = "language"
+
+	// CategoryContainer represents the managers of container images and the applications they run, such as
+	// distrobox or toolbox containers.
+	CategoryContainer Category = "container"
+
+	// CategoryFirmware represents the managers of device firmware, such as fwupd.
+	CategoryFirmware Category = "firmware"
+)
+
+// Categories are the known package manager categories.
+var Categories = []Category{CategorySystem, CategoryUniversal, CategoryUser, CategoryLanguage, CategoryContainer, CategoryFirmware}
+
+// ParseCategories parses package manager categories, each value being one category or a comma-separated list of
+// them, such as "system,universal".
+func ParseCategories(values []string) ([]Category, error) {
+	var categories []Category
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			category := Category(strings.TrimSpace(name))
+			if !slices.Contains(Categories, category) {
+				return nil, fmt.Errorf("unknown category %q, expected one of %v", name, Categories)
+			}
+			if !slices.Contains(categories, category) {
+				categories = append(categories, category)
+			}
+		}
+	}
+	return categories, nil
+}
@@ -0,0 +1,23 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestParseCategories(t *testing.T) {
+	expectedCategories := []manager.Category{manager.CategorySystem, manager.CategoryUniversal, manager.CategoryLanguage}
+	actualCategories, err := manager.ParseCategories([]string{"system,universal", "language", "system"})
+	if err != nil {
+		t.Fatalf("ParseCategories() error = %+v", err)
+	}
+	if !reflect.DeepEqual(actualCategories, expectedCategories) {
+		t.Errorf("ParseCategories() = %+v, want %+v", actualCategories, expectedCategories)
+	}
+
+	if _, err := manager.ParseCategories([]string{"system,sandbox"}); err == nil {
+		t.Errorf("ParseCategories(system,sandbox) error = nil, want an error")
+	}
+}
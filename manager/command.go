@@ -0,0 +1,241 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// InterruptGracePeriod is how long interrupted commands are given to exit cleanly, such as apt finishing to configure
+// the package being installed, before they are killed.
+var InterruptGracePeriod = 30 * time.Second
+
+// CommandWrapper runs the commands of package managers through another command.
+type CommandWrapper interface {
+	// CommandPrefix returns the command and arguments the commands run through, which are followed by the name and
+	// the arguments of each command.
+	CommandPrefix() []string
+}
+
+// Command returns the exec.Cmd running a package manager command with the given options.
+// If opts.Timeout is set, the command is killed when it runs longer than that.
+// If opts.Context is done, such as when syspkg is interrupted, the command and the processes it started are sent
+// SIGINT, and the command is killed after InterruptGracePeriod.
+// If opts.Nice or opts.IdleIO are set, the command runs at a lower priority, through nice and ionice, and if
+// opts.Wrapper is set, through its command.
+// If opts.Proxy or opts.Env are set, the command runs with them: callers setting the environment of the command
+// must use SetEnv or Env. If opts.RootDir is set, the commands supporting it operate on the system at that root.
+// If opts.Transcript is set, the command is recorded in it.
+// The command is run with Output, CombinedOutput, Run, StreamLines or StreamOutput, which release it once done, or
+// released by the caller with Release.
+func Command(opts *Options, name string, args ...string) *exec.Cmd {
+	if root := rootArgs(opts, name); len(root) > 0 {
+		args = append(root, args...)
+	}
+	if prefix := commandPrefix(opts); len(prefix) > 0 {
+		name, args = prefix[0], append(append(prefix[1:], name), args...)
+	}
+	ctx := opts.OperationContext()
+	var cancel context.CancelFunc
+	if opts != nil && opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	if cancel != nil {
+		timeouts.Store(cmd, cancel)
+		// past its deadline, the command no longer holds a timer, whether it was released or not
+		context.AfterFunc(ctx, func() { timeouts.Delete(cmd) })
+	}
+	if opts != nil && opts.Context != nil {
+		interruptible(cmd, opts.Interactive)
+		cmd.WaitDelay = InterruptGracePeriod
+	}
+
+	if opts != nil && (opts.Proxy != "" || len(opts.Env) > 0) {
+		SetEnv(cmd, opts, nil)
+	}
+	if opts != nil && opts.Transcript != nil {
+		opts.Transcript.add(cmd)
+	}
+	return cmd
+}
+
+// timeouts are the functions releasing the timers of the commands with a timeout, by command.
+var timeouts sync.Map
+
+// Release releases the resources of cmd, a command returned by Command that completed: the timer of its timeout.
+func Release(cmd *exec.Cmd) {
+	if cancel, ok := timeouts.LoadAndDelete(cmd); ok {
+		cancel.(context.CancelFunc)()
+	}
+}
+
+// Output runs cmd, a command returned by Command, and returns its standard output, like cmd.Output, then releases it.
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	defer Release(cmd)
+	return cmd.Output()
+}
+
+// CombinedOutput runs cmd, a command returned by Command, and returns its standard output and error, like
+// cmd.CombinedOutput, then releases it.
+func CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	defer Release(cmd)
+	return cmd.CombinedOutput()
+}
+
+// Run runs cmd, a command returned by Command, like cmd.Run, then releases it.
+func Run(cmd *exec.Cmd) error {
+	defer Release(cmd)
+	return cmd.Run()
+}
+
+// Rerun returns a copy of cmd, a command returned by Command that already ran, to run it again, such as when it
+// failed because of a lock.
+func Rerun(opts *Options, cmd *exec.Cmd) *exec.Cmd {
+	args := cmd.Args[len(commandPrefix(opts)):]
+	next := Command(opts, args[0], args[1+len(rootArgs(opts, args[0])):]...)
+	next.Env, next.Dir = cmd.Env, cmd.Dir
+	if r, ok := cmd.Stdin.(*bytes.Reader); ok {
+		_, _ = r.Seek(0, io.SeekStart)
+		next.Stdin = r
+	}
+	return next
+}
+
+// SetEnv sets the environment of cmd: the one of syspkg, with the proxy of opts, then env, such as the
+// ENV_NonInteractive variables of the package manager, and extra, then opts.Env, which overrides them.
+func SetEnv(cmd *exec.Cmd, opts *Options, env []string, extra ...string) {
+	cmd.Env = append(append(append(os.Environ(), ProxyEnv(opts)...), env...), extra...)
+	cmd.Env = append(cmd.Env, optionsEnv(opts)...)
+}
+
+// Env returns env followed by opts.Env, which overrides it, for commands running with env as their whole
+// environment, such as the ENV_NonInteractive variables of apt.
+func Env(opts *Options, env []string) []string {
+	return append(slices.Clip(env), optionsEnv(opts)...)
+}
+
+// optionsEnv returns the variables of opts.Env, sorted by name.
+func optionsEnv(opts *Options) []string {
+	if opts == nil || len(opts.Env) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(opts.Env))
+	for name := range opts.Env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		env = append(env, name+"="+opts.Env[name])
+	}
+	return env
+}
+
+// rootOptions return the options of the commands of package managers operating on the system at root, by command
+// name.
+var rootOptions = map[string]func(root string) []string{
+	"apt":        aptRoot,
+	"apt-cache":  aptRoot,
+	"apt-get":    aptRoot,
+	"apt-mark":   aptRoot,
+	"dpkg":       dpkgRoot,
+	"dpkg-query": dpkgRoot,
+	"apk":        rootOption,
+	"pacman":     rootOption,
+	"rpm":        rootOption,
+	"zypper":     rootOption,
+}
+
+// aptRoot returns the options of apt operating on the system at root: its files, and the ones of dpkg, are the ones
+// under root.
+func aptRoot(root string) []string {
+	return []string{"-o", "Dir=" + root, "-o", "DPkg::Options::=--root=" + root}
+}
+
+// dpkgRoot returns the option of dpkg operating on the system at root.
+func dpkgRoot(root string) []string {
+	return []string{"--root=" + root}
+}
+
+// rootOption returns the --root option of the commands operating on the system at root.
+func rootOption(root string) []string {
+	return []string{"--root", root}
+}
+
+// rootArgs returns the options of command name operating on the system at opts.RootDir, if set and supported.
+func rootArgs(opts *Options, name string) []string {
+	if opts == nil || opts.RootDir == "" || opts.RootDir == "/" {
+		return nil
+	}
+	if options, ok := rootOptions[name]; ok {
+		return options(opts.RootDir)
+	}
+	return nil
+}
+
+// commandPrefix returns the commands the commands of opts run through: the ones lowering their priority, then the
+// one of opts.Wrapper, so that wrappers see the commands of the package managers.
+func commandPrefix(opts *Options) []string {
+	prefix := priorityPrefix(opts)
+	if opts != nil && opts.Wrapper != nil {
+		prefix = append(prefix, opts.Wrapper.CommandPrefix()...)
+	}
+	return prefix
+}
+
+// priorityPrefix returns the commands running the commands of opts at a lower priority: nice with opts.Nice, and
+// ionice with opts.IdleIO, if available.
+func priorityPrefix(opts *Options) []string {
+	var prefix []string
+	if opts == nil {
+		return prefix
+	}
+	if opts.Nice > 0 {
+		if _, err := exec.LookPath("nice"); err == nil {
+			prefix = append(prefix, "nice", "-n", strconv.Itoa(opts.Nice))
+		}
+	}
+	if opts.IdleIO {
+		if _, err := exec.LookPath("ionice"); err == nil {
+			prefix = append(prefix, "ionice", "-c", "3")
+		}
+	}
+	return prefix
+}
+
+// Interrupted returns err wrapped with ErrInterrupted if the operation that returned it was interrupted through
+// opts.Context, and err otherwise.
+func Interrupted(opts *Options, err error) error {
+	if err == nil || opts == nil || opts.Context == nil || opts.Context.Err() == nil {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrInterrupted, err)
+}
+
+// OperationContext returns the context of the options: Context if set, otherwise the background context.
+// It can be called on nil options.
+func (o *Options) OperationContext() context.Context {
+	if o == nil || o.Context == nil {
+		return context.Background()
+	}
+	return o.Context
+}
+
+// WithContext returns a copy of the options, or of the default ones if nil, whose Context is ctx.
+func (o *Options) WithContext(ctx context.Context) *Options {
+	var copied Options
+	if o != nil {
+		copied = *o
+	}
+	copied.Context = ctx
+	return &copied
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package manager
+
+import "os/exec"
+
+// interruptible keeps the default cancellation of cmd, killing it, as processes can't be sent SIGINT on this platform.
+func interruptible(cmd *exec.Cmd, interactive bool) {}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package manager
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// interruptible makes cmd interrupted with SIGINT, like Ctrl-C, along with the processes it starts, such as the dpkg
+// processes of apt. Non-interactive commands run in their own process group, which is signaled as a whole, so that
+// they are only interrupted by syspkg, once it is ready to report the interrupted operation. Interactive commands
+// stay in the process group of the terminal, which sends them Ctrl-C directly.
+func interruptible(cmd *exec.Cmd, interactive bool) {
+	if interactive {
+		cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGINT) }
+		return
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT) }
+}
@@ -0,0 +1,279 @@
+// Package conda provides an implementation of the syspkg manager interface for the conda package manager.
+// It provides a Go (golang) API interface for interacting with conda and its faster reimplementation, mamba.
+// This package is a wrapper around the conda (or mamba) command line tool.
+//
+// conda is a cross-platform package and environment manager, widely used for Python and data science.
+// It installs packages from channels (such as conda-forge) into environments, which are isolated directories
+// with their own packages. By default, the active environment (or "base") is managed;
+// another environment can be selected by name with manager.Options.Environment.
+// conda provides JSON output (--json) for all of its commands, which is used by this package to parse results.
+//
+// For more information about conda, visit:
+// - https://docs.conda.io/projects/conda/en/stable/commands/index.html
+// - https://mamba.readthedocs.io/
+//
+// This package is part of the syspkg library.
+package conda
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "conda"
+
+// commands contains the names of the conda executable, in order of preference.
+var commands = []string{"conda", "mamba"}
+
+// Constants used for conda commands
+const (
+	ArgsAssumeYes string = "--yes"
+	ArgsJSON      string = "--json"
+	ArgsDryRun    string = "--dry-run"
+	ArgsName      string = "--name"
+	ArgsAll       string = "--all"
+)
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for conda.
+var ENV_NonInteractive []string = []string{"LC_ALL=C", "CONDA_ALWAYS_YES=true", "CONDA_NOTIFY_OUTDATED_CONDA=false"}
+
+// PackageManager implements the manager.PackageManager interface for the conda package manager.
+type PackageManager struct{}
+
+// IsAvailable checks if the conda package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	return a.command() != ""
+}
+
+// GetPackageManager returns the name of the conda package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by conda.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:           true,
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+	}
+}
+
+// Install installs the provided packages into the environment using conda.
+// Packages can be given with a version specification, e.g. "numpy=1.25" or "conda-forge::numpy".
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// conda installs a specific version with name=version
+	pkgs, err := manager.TranslatePackageSpecs(pkgs, manager.PackageSpec.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.transaction("install", pkgs, opts)
+}
+
+// Delete removes the provided packages from the environment using conda.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.transaction("remove", pkgs, opts)
+}
+
+// Refresh does nothing for conda, as conda downloads the channel indexes again when its cached copy expires.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	return nil
+}
+
+// Find searches the configured channels for packages matching the provided keywords using conda.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	for _, keyword := range keywords {
+		// conda search matches package names against a pattern
+		out, err := a.search("*"+keyword+"*", opts)
+		if err == ErrPackagesNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found, err := ParseSearchOutput(out, opts)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, found...)
+	}
+	return packages, nil
+}
+
+// ListInstalled lists all packages installed in the environment using conda.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	out, err := a.query([]string{"list"}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListOutput(out, opts)
+}
+
+// ListUpgradable lists all packages of the environment that an update would upgrade, using conda update --all --dry-run.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	out, err := a.query([]string{"update", ArgsAll, ArgsDryRun}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	packages, err := ParseTransactionOutput(out, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var upgradable []manager.PackageInfo
+	for _, pkg := range packages {
+		if pkg.Status == manager.PackageStatusUpgradable {
+			upgradable = append(upgradable, pkg)
+		}
+	}
+	return upgradable, nil
+}
+
+// Upgrade upgrades the provided packages of the environment using conda.
+// If no packages are given, all packages of the environment are upgraded.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if len(pkgs) == 0 {
+		return a.transaction("update", []string{ArgsAll}, opts)
+	}
+	return a.transaction("update", pkgs, opts)
+}
+
+// UpgradeAll upgrades all packages of the environment using conda.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// GetPackageInfo retrieves information about the specified package from the configured channels,
+// along with the version installed in the environment, if any.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	info := manager.PackageInfo{Name: pkg, Status: manager.PackageStatusUnknown, PackageManager: pm}
+
+	out, err := a.search(pkg, opts)
+	if err != nil && err != ErrPackagesNotFound {
+		return manager.PackageInfo{}, err
+	}
+	if err == nil {
+		found, err := ParseSearchOutput(out, opts)
+		if err != nil {
+			return manager.PackageInfo{}, err
+		}
+		if len(found) > 0 {
+			info = found[len(found)-1]
+		}
+	}
+
+	out, err = a.query([]string{"list", "^" + pkg + "$"}, opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	installed, err := ParseListOutput(out, opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	if len(installed) > 0 {
+		newVersion := info.NewVersion
+		info = installed[0]
+		if newVersion != "" && newVersion != info.Version {
+			info.NewVersion = newVersion
+			info.Status = manager.PackageStatusUpgradable
+		}
+	}
+	return info, nil
+}
+
+// transaction runs a conda command changing the packages of the environment (install, remove, update),
+// and returns the packages it linked and unlinked.
+func (a *PackageManager) transaction(command string, pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	args := append([]string{command}, environmentArgs(opts)...)
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+	args = append(args, pkgs...)
+
+	if command == "update" {
+		opts.Log().Info("Running command", "package_manager", pm, "command", a.command(), "args", args)
+	}
+
+	if opts.Interactive {
+		cmd := manager.Command(opts, a.command(), args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return nil, manager.Run(cmd)
+	}
+
+	cmd := manager.Command(opts, a.command(), append(args, ArgsAssumeYes, ArgsJSON)...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, CheckError(out, err)
+	}
+	return ParseTransactionOutput(out, opts)
+}
+
+// query runs a read-only conda command with JSON output, in the environment selected by opts if any.
+func (a *PackageManager) query(args []string, opts *manager.Options) ([]byte, error) {
+	if opts != nil {
+		args = append(args, environmentArgs(opts)...)
+	}
+	return a.jsonOutput(args, opts)
+}
+
+// search searches the configured channels for the packages matching pattern with conda search, whose results don't
+// depend on the environment, which isn't selected.
+func (a *PackageManager) search(pattern string, opts *manager.Options) ([]byte, error) {
+	return a.jsonOutput([]string{"search", pattern}, opts)
+}
+
+// jsonOutput runs a read-only conda command with JSON output.
+func (a *PackageManager) jsonOutput(args []string, opts *manager.Options) ([]byte, error) {
+	args = append(args, ArgsJSON)
+	cmd := manager.Command(opts, a.command(), args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, CheckError(out, err)
+	}
+	return out, nil
+}
+
+// command returns the conda executable to use, or an empty string if conda is not available.
+// The CONDA_EXE environment variable, set by an activated conda installation, takes precedence over PATH.
+func (a *PackageManager) command() string {
+	if exe := os.Getenv("CONDA_EXE"); exe != "" {
+		if _, err := os.Stat(exe); err == nil {
+			return exe
+		}
+	}
+
+	for _, name := range commands {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// environmentArgs returns the arguments selecting the environment given in the options, if any.
+func environmentArgs(opts *manager.Options) []string {
+	if opts.Environment == "" {
+		return nil
+	}
+	return []string{ArgsName, opts.Environment}
+}
@@ -0,0 +1,210 @@
+// Package conda provides a package manager implementation for conda environments
+// using conda as the underlying package management tool.
+package conda
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ErrPackagesNotFound is returned when conda can't find the requested packages in the configured channels.
+var ErrPackagesNotFound = errors.New("conda: some of the requested packages were not found")
+
+// CheckError returns a descriptive error for a failed conda command, using the JSON error that conda prints with --json.
+// Example msg:
+//
+//	{"error": "PackagesNotFoundError: The following packages are not available from current channels: ...", "exception_name": "PackagesNotFoundError"}
+func CheckError(msg []byte, err error) error {
+	var result struct {
+		Error         string `json:"error"`
+		ExceptionName string `json:"exception_name"`
+	}
+	if jsonErr := json.Unmarshal(msg, &result); jsonErr != nil || result.Error == "" {
+		return err
+	}
+
+	if result.ExceptionName == "PackagesNotFoundError" {
+		return ErrPackagesNotFound
+	}
+	return fmt.Errorf("%s: %s: %w", pm, result.Error, err)
+}
+
+// ParseListOutput parses the output of `conda list --json` command and returns a list of installed packages.
+// The channel is kept in Category and Origin, and the build string in AdditionalData["build"].
+// Example msg:
+//
+//	[
+//	  {"base_url": "https://repo.anaconda.com/pkgs/main", "build_number": 0, "build_string": "py311h08b1b3b_0",
+//	   "channel": "pkgs/main", "dist_name": "numpy-1.25.2-py311h08b1b3b_0", "name": "numpy", "platform": "linux-64", "version": "1.25.2"}
+//	]
+func ParseListOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var results []struct {
+		Name        string `json:"name"`
+		Version     string `json:"version"`
+		BuildString string `json:"build_string"`
+		Channel     string `json:"channel"`
+		Platform    string `json:"platform"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &results); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	for _, r := range results {
+		packages = append(packages, manager.PackageInfo{
+			Name:           r.Name,
+			Version:        r.Version,
+			Status:         manager.PackageStatusInstalled,
+			Category:       r.Channel,
+			Origin:         r.Channel,
+			Arch:           r.Platform,
+			PackageManager: pm,
+			AdditionalData: map[string]string{"build": r.BuildString},
+		})
+	}
+
+	return packages, nil
+}
+
+// ParseSearchOutput parses the output of `conda search --json pattern` command
+// and returns the newest version of each package that matches the search query, sorted by name.
+// Example msg:
+//
+//	{
+//	  "numpy": [
+//	    {"name": "numpy", "version": "1.25.0", "build": "py311h08b1b3b_0", "channel": "pkgs/main", "subdir": "linux-64"},
+//	    {"name": "numpy", "version": "1.25.2", "build": "py311h08b1b3b_0", "channel": "pkgs/main", "subdir": "linux-64"}
+//	  ]
+//	}
+func ParseSearchOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var results map[string][]struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Build   string `json:"build"`
+		Channel string `json:"channel"`
+		Subdir  string `json:"subdir"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &results); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		versions := results[name]
+		if len(versions) == 0 {
+			continue
+		}
+		// conda lists the versions of each package from the oldest to the newest
+		newest := versions[len(versions)-1]
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			NewVersion:     newest.Version,
+			Status:         manager.PackageStatusAvailable,
+			Category:       newest.Channel,
+			Origin:         newest.Channel,
+			Arch:           newest.Subdir,
+			PackageManager: pm,
+			AdditionalData: map[string]string{"build": newest.Build},
+		})
+	}
+
+	return packages, nil
+}
+
+// ParseTransactionOutput parses the output of the conda commands that change an environment
+// (install, remove, update, with --json and optionally --dry-run), and returns the packages affected by the transaction.
+// Packages that are both unlinked and linked are upgraded (or downgraded), and are marked as upgradable in dry-run mode,
+// and as installed otherwise. Linked packages are marked as installed, and unlinked packages as available.
+// Example msg:
+//
+//	{
+//	  "actions": {
+//	    "LINK": [{"name": "numpy", "version": "1.25.2", "build_string": "py311h08b1b3b_0", "channel": "pkgs/main"}],
+//	    "UNLINK": [{"name": "numpy", "version": "1.24.3", "build_string": "py311h08b1b3b_1", "channel": "pkgs/main"}]
+//	  },
+//	  "dry_run": true,
+//	  "success": true
+//	}
+func ParseTransactionOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	type action struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Channel string `json:"channel"`
+	}
+	var packages []manager.PackageInfo
+	var result struct {
+		Actions struct {
+			Link   []action `json:"LINK"`
+			Unlink []action `json:"UNLINK"`
+		} `json:"actions"`
+		DryRun bool `json:"dry_run"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &result); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	unlinked := make(map[string]string)
+	for _, a := range result.Actions.Unlink {
+		unlinked[a.Name] = a.Version
+	}
+
+	linked := make(map[string]bool)
+	for _, a := range result.Actions.Link {
+		linked[a.Name] = true
+		packageInfo := manager.PackageInfo{
+			Name:           a.Name,
+			Version:        a.Version,
+			Status:         manager.PackageStatusInstalled,
+			Category:       a.Channel,
+			Origin:         a.Channel,
+			PackageManager: pm,
+		}
+		if oldVersion, ok := unlinked[a.Name]; ok {
+			packageInfo.Version = oldVersion
+			packageInfo.NewVersion = a.Version
+			if result.DryRun {
+				packageInfo.Status = manager.PackageStatusUpgradable
+			}
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	for _, a := range result.Actions.Unlink {
+		if linked[a.Name] {
+			continue
+		}
+		packages = append(packages, manager.PackageInfo{
+			Name:           a.Name,
+			Version:        a.Version,
+			Status:         manager.PackageStatusAvailable,
+			Category:       a.Channel,
+			Origin:         a.Channel,
+			PackageManager: pm,
+		})
+	}
+
+	return packages, nil
+}
@@ -0,0 +1,138 @@
+package conda_test
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/conda"
+)
+
+func TestParseListOutput(t *testing.T) {
+	var inputParseListOutput string = `[
+  {"base_url": "https://repo.anaconda.com/pkgs/main", "build_number": 0, "build_string": "py311h08b1b3b_0", "channel": "pkgs/main", "dist_name": "numpy-1.25.2-py311h08b1b3b_0", "name": "numpy", "platform": "linux-64", "version": "1.25.2"}
+]`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "numpy",
+			Version:        "1.25.2",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "pkgs/main",
+			Origin:         "pkgs/main",
+			Arch:           "linux-64",
+			PackageManager: "conda",
+			AdditionalData: map[string]string{"build": "py311h08b1b3b_0"},
+		},
+	}
+
+	actualPackageInfo, err := conda.ParseListOutput([]byte(inputParseListOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseListOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseSearchOutput(t *testing.T) {
+	var inputParseSearchOutput string = `{
+  "numpy": [
+    {"name": "numpy", "version": "1.25.0", "build": "py311h08b1b3b_0", "channel": "pkgs/main", "subdir": "linux-64"},
+    {"name": "numpy", "version": "1.25.2", "build": "py311h08b1b3b_0", "channel": "pkgs/main", "subdir": "linux-64"}
+  ]
+}`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "numpy",
+			NewVersion:     "1.25.2",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "pkgs/main",
+			Origin:         "pkgs/main",
+			Arch:           "linux-64",
+			PackageManager: "conda",
+			AdditionalData: map[string]string{"build": "py311h08b1b3b_0"},
+		},
+	}
+
+	actualPackageInfo, err := conda.ParseSearchOutput([]byte(inputParseSearchOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseSearchOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseSearchOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseTransactionOutput(t *testing.T) {
+	var inputParseTransactionOutput string = `{
+  "actions": {
+    "LINK": [
+      {"name": "numpy", "version": "1.25.2", "build_string": "py311h08b1b3b_0", "channel": "pkgs/main"},
+      {"name": "pandas", "version": "2.0.3", "build_string": "py311ha02d727_0", "channel": "pkgs/main"}
+    ],
+    "UNLINK": [
+      {"name": "numpy", "version": "1.24.3", "build_string": "py311h08b1b3b_1", "channel": "pkgs/main"},
+      {"name": "six", "version": "1.16.0", "build_string": "pyhd3eb1b0_1", "channel": "pkgs/main"}
+    ]
+  },
+  "dry_run": true,
+  "success": true
+}`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "numpy",
+			Version:        "1.24.3",
+			NewVersion:     "1.25.2",
+			Status:         manager.PackageStatusUpgradable,
+			Category:       "pkgs/main",
+			Origin:         "pkgs/main",
+			PackageManager: "conda",
+		},
+		{
+			Name:           "pandas",
+			Version:        "2.0.3",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "pkgs/main",
+			Origin:         "pkgs/main",
+			PackageManager: "conda",
+		},
+		{
+			Name:           "six",
+			Version:        "1.16.0",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "pkgs/main",
+			Origin:         "pkgs/main",
+			PackageManager: "conda",
+		},
+	}
+
+	actualPackageInfo, err := conda.ParseTransactionOutput([]byte(inputParseTransactionOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseTransactionOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseTransactionOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestCheckError(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+
+	err := conda.CheckError([]byte(`{"error": "PackagesNotFoundError: ...", "exception_name": "PackagesNotFoundError"}`), exitErr)
+	if err != conda.ErrPackagesNotFound {
+		t.Errorf("CheckError() = %+v, want %+v", err, conda.ErrPackagesNotFound)
+	}
+
+	err = conda.CheckError([]byte(`not json`), exitErr)
+	if !errors.Is(err, exitErr) {
+		t.Errorf("CheckError() = %+v, want %+v", err, exitErr)
+	}
+}
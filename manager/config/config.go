@@ -0,0 +1,784 @@
+// Package config loads the defaults of syspkg from a configuration file, so that users don't have to repeat flags.
+//
+// The configuration file is a YAML (or JSON) document such as:
+//
+//	# package managers to use when none is selected with a flag
+//	managers: [apt, flatpak]
+//	# package managers to never use
+//	exclude: [snap]
+//	# which of the selected package managers install a package: best, first, explicit or all,
+//	# and their order of preference
+//	install_policy: best
+//	priority: [apt, flatpak, snap]
+//	timeout: 10m
+//	# timeouts of specific commands, overriding the default one
+//	timeouts:
+//	  find: 30s
+//	  upgrade: 1h
+//	# how long to wait for the lock of a package manager held by another process
+//	lock_wait: 5m
+//	# refresh the package index and retry once when an operation fails because it is stale
+//	auto_refresh: true
+//	# download through an HTTP proxy, and from mirrors of the registries of some package managers
+//	proxy: http://proxy.example.com:3128
+//	no_proxy: localhost,.example.com
+//	mirrors:
+//	  pip: https://pypi.example.com/simple
+//	  npm: https://npm.example.com
+//	# environment variables of the commands of the package managers, overriding their defaults
+//	env:
+//	  PIP_INDEX_URL: https://pypi.example.com/simple
+//	assume_yes: true
+//	output: json
+//	concurrency: 4
+//	sudo: auto
+//	# how long search, list and info results are cached; 0 disables the cache
+//	cache_ttl: 15m
+//	# append-only log of the operations changing packages: a file, or syslog; disabled if empty
+//	audit_log: /var/log/syspkg/audit.log
+//	# commands and webhooks run before or after operations (see the hooks package)
+//	hooks:
+//	  - event: post-upgrade
+//	    webhook: https://hooks.slack.com/services/T000/B000/XXXX
+//	  - event: post-upgrade
+//	    packages: [nginx]
+//	    command: systemctl restart nginx
+//	    timeout: 30s
+//	    on_failure: warn
+//	# which packages upgrades of all packages upgrade, and when (see manager.UpgradePolicy)
+//	upgrade:
+//	  exclude: ["kernel*", docker-ce]
+//	  security_only: false
+//	  max_packages: 50
+//	  maintenance_windows: ["Sat,Sun 02:00-06:00"]
+//	# notifications of completed upgrades, vulnerabilities found and failures (see the notify package)
+//	notify:
+//	  - events: [upgrade, failure]
+//	    webhook: https://hooks.slack.com/services/T000/B000/XXXX
+//	  - events: [vulnerable, failure]
+//	    to: [ops@example.com]
+//	    from: syspkg@example.com
+//	    smtp: smtp.example.com:587
+//	    username: syspkg
+//	    password_env: SMTP_PASSWORD
+//
+// Every setting can be overridden by an environment variable: SYSPKG_MANAGERS, SYSPKG_EXCLUDE and SYSPKG_PRIORITY
+// (comma-separated), SYSPKG_INSTALL_POLICY, SYSPKG_TIMEOUT, SYSPKG_LOCK_WAIT, SYSPKG_AUTO_REFRESH, SYSPKG_PROXY, SYSPKG_NO_PROXY, SYSPKG_ASSUME_YES, SYSPKG_OUTPUT,
+// SYSPKG_CONCURRENCY, SYSPKG_SUDO, SYSPKG_CACHE_TTL and SYSPKG_AUDIT_LOG, except the timeouts of specific commands, the mirrors, the environment variables, the hooks, the upgrade policy and the notifications.
+//
+// This package is part of the syspkg library.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/hooks"
+	"github.com/bluet/syspkg/manager/internal/yaml"
+	"github.com/bluet/syspkg/manager/notify"
+)
+
+// ErrInvalidConfig is returned, wrapped with the details, for configuration files or environment variables with invalid settings.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Sudo modes, which select when commands are run with elevated privileges.
+const (
+	SudoAuto   = "auto"
+	SudoNever  = "never"
+	SudoAlways = "always"
+)
+
+// Output formats.
+const (
+	OutputText      = "text"
+	OutputJSON      = "json"
+	OutputNDJSON    = "ndjson"
+	OutputPorcelain = "porcelain"
+	OutputCSV       = "csv"
+	OutputYAML      = "yaml"
+)
+
+// OutputFormats are the output formats, in the order they are listed in errors.
+var OutputFormats = []string{OutputText, OutputJSON, OutputNDJSON, OutputPorcelain, OutputCSV, OutputYAML}
+
+// Config holds the defaults of syspkg. Zero values mean that the setting is not configured.
+type Config struct {
+	// Managers are the package managers used when none is selected on the command line.
+	Managers []string
+
+	// Exclude are package managers that are never used, even when selected by category.
+	Exclude []string
+
+	// InstallPolicy selects which of the selected package managers install a package: best, first, explicit or all
+	// (see manager.InstallPolicy).
+	InstallPolicy string
+
+	// Priority are package managers in order of preference to install packages, before manager.DefaultPriority.
+	Priority []string
+
+	// Timeout is the default timeout of package manager operations.
+	Timeout time.Duration
+
+	// Timeouts are the timeouts of specific commands, by command name (e.g. "find" or "show upgradable"), overriding Timeout.
+	Timeouts map[string]time.Duration
+
+	// LockWait is how long to wait for the lock of a package manager held by another process before failing.
+	LockWait time.Duration
+
+	// AutoRefresh refreshes the package index and retries once when an operation fails because it is stale.
+	AutoRefresh bool
+
+	// Proxy is the URL of the HTTP proxy the package managers download through.
+	Proxy string
+
+	// NoProxy is a comma-separated list of hosts and domains downloaded from without Proxy.
+	NoProxy string
+
+	// Mirrors are the URLs of the repositories or registries to download from, by package manager name.
+	Mirrors map[string]string
+
+	// Env are the environment variables of the commands of the package managers, by name, overriding their defaults.
+	Env map[string]string
+
+	// AssumeYes answers yes to all prompts, even in interactive mode.
+	AssumeYes bool
+
+	// Output is the default output format: text, json, ndjson, porcelain, csv or yaml.
+	Output string
+
+	// Concurrency is the maximum number of package managers queried at the same time.
+	Concurrency int
+
+	// Sudo selects when commands are run with elevated privileges: auto, never or always.
+	Sudo string
+
+	// CacheTTL is how long the results of queries are cached. Zero means the default TTL,
+	// and a negative value, set by cache_ttl: 0, disables the cache.
+	CacheTTL time.Duration
+
+	// AuditLog is where the operations changing packages are logged: the path of a file, or "syslog".
+	// The audit log is disabled if empty.
+	AuditLog string
+
+	// Hooks are the commands and webhooks run before or after operations, in order.
+	Hooks []hooks.Hook
+
+	// Upgrade restricts the packages upgraded by upgrades of all packages, and when they run.
+	Upgrade manager.UpgradePolicy
+
+	// Notify are the notifiers alerting of the outcomes of operations, by email or webhook.
+	Notify []notify.Notifier
+}
+
+// DefaultPath returns the path of the configuration file: $SYSPKG_CONFIG if set,
+// otherwise syspkg/config.yaml in the user configuration directory (~/.config on Linux, honoring $XDG_CONFIG_HOME).
+func DefaultPath() string {
+	if path := os.Getenv("SYSPKG_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "syspkg", "config.yaml")
+}
+
+// Load reads and parses the configuration file at path, and applies the environment variable overrides.
+// A missing file is not an error when optional is set: the configuration then only comes from the environment.
+func Load(path string, optional bool) (*Config, error) {
+	return load(path, optional, nil)
+}
+
+// ErrUntrustedConfig is returned by LoadTrusted for configuration files that users other than root may have written.
+var ErrUntrustedConfig = errors.New("configuration file not owned by root, or writable by other users")
+
+// LoadTrusted is Load for syspkg running as root on behalf of another user, such as after escalating its privileges
+// with sudo: the configuration file, whose hooks run as root, must be owned by root and not writable by other users,
+// otherwise ErrUntrustedConfig is returned.
+func LoadTrusted(path string, optional bool) (*Config, error) {
+	return load(path, optional, checkOwner)
+}
+
+// load reads and parses the configuration file at path, checked by check if set, and applies the environment
+// variable overrides.
+func load(path string, optional bool, check func(info os.FileInfo) error) (*Config, error) {
+	c := &Config{}
+	data, err := readFile(path, check)
+	switch {
+	case err == nil:
+		if c, err = Parse(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case !(optional && errors.Is(err, os.ErrNotExist)):
+		return nil, err
+	}
+
+	if err := c.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// readFile returns the content of the file at path, checked by check if set, once opened so that it can't be replaced
+// in between.
+func readFile(path string, check func(info os.FileInfo) error) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if check != nil {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if err := check(info); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return io.ReadAll(f)
+}
+
+// Parse parses and validates a configuration file. JSON documents are parsed as JSON, anything else as YAML.
+func Parse(data []byte) (*Config, error) {
+	var doc any
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) {
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+	} else {
+		var err error
+		if doc, err = yaml.Parse(data); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+	}
+
+	c := &Config{}
+	if doc == nil {
+		return c, nil
+	}
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: the configuration must be a mapping", ErrInvalidConfig)
+	}
+	for key, value := range root {
+		var err error
+		switch key {
+		case "timeouts":
+			c.Timeouts, err = durations(value)
+		case "mirrors":
+			c.Mirrors, err = urls(value)
+		case "env":
+			c.Env, err = variables(value)
+		case "hooks":
+			c.Hooks, err = parseHooks(value)
+		case "upgrade":
+			c.Upgrade, err = parseUpgradePolicy(value)
+		case "notify":
+			c.Notify, err = parseNotifiers(value)
+		case "managers", "exclude", "priority":
+			var names []string
+			if names, err = list(value); err == nil {
+				err = c.setList(key, names)
+			}
+		default:
+			var s string
+			if s, err = scalar(value); err == nil && (s != "" || env[key] == "") {
+				err = c.set(key, s)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, key, err)
+		}
+	}
+	return c, nil
+}
+
+// env maps the settings to their environment variables.
+var env = map[string]string{
+	"managers":       "SYSPKG_MANAGERS",
+	"exclude":        "SYSPKG_EXCLUDE",
+	"priority":       "SYSPKG_PRIORITY",
+	"install_policy": "SYSPKG_INSTALL_POLICY",
+	"timeout":        "SYSPKG_TIMEOUT",
+	"lock_wait":      "SYSPKG_LOCK_WAIT",
+	"auto_refresh":   "SYSPKG_AUTO_REFRESH",
+	"proxy":          "SYSPKG_PROXY",
+	"no_proxy":       "SYSPKG_NO_PROXY",
+	"assume_yes":     "SYSPKG_ASSUME_YES",
+	"output":         "SYSPKG_OUTPUT",
+	"concurrency":    "SYSPKG_CONCURRENCY",
+	"sudo":           "SYSPKG_SUDO",
+	"cache_ttl":      "SYSPKG_CACHE_TTL",
+	"audit_log":      "SYSPKG_AUDIT_LOG",
+}
+
+// ApplyEnv overrides the settings with the non-empty environment variables, as returned by lookup (usually os.LookupEnv).
+func (c *Config) ApplyEnv(lookup func(key string) (string, bool)) error {
+	for key, name := range env {
+		value, ok := lookup(name)
+		if !ok || value == "" {
+			continue
+		}
+
+		var err error
+		switch key {
+		case "managers", "exclude", "priority":
+			err = c.setList(key, splitList(value))
+		default:
+			err = c.set(key, value)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, name, err)
+		}
+	}
+	return nil
+}
+
+// setList sets a list of package manager names, which must be the ones of supported package managers, so that a
+// misspelled excluded package manager is not used.
+func (c *Config) setList(key string, names []string) error {
+	for _, name := range names {
+		if !slices.Contains(manager.DefaultPriority, name) {
+			return fmt.Errorf("unknown package manager %q", name)
+		}
+	}
+	switch key {
+	case "managers":
+		c.Managers = names
+	case "exclude":
+		c.Exclude = names
+	case "priority":
+		c.Priority = names
+	}
+	return nil
+}
+
+// set sets a scalar setting from its string value.
+func (c *Config) set(key string, value string) error {
+	var err error
+	switch key {
+	case "timeout":
+		if c.Timeout, err = time.ParseDuration(value); err != nil || c.Timeout < 0 {
+			return fmt.Errorf("invalid duration %q, expected e.g. 90s or 10m", value)
+		}
+	case "lock_wait":
+		if c.LockWait, err = time.ParseDuration(value); err != nil || c.LockWait < 0 {
+			return fmt.Errorf("invalid duration %q, expected e.g. 90s or 10m", value)
+		}
+	case "auto_refresh":
+		if c.AutoRefresh, err = strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+	case "proxy":
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL %q, expected e.g. http://proxy.example.com:3128", value)
+		}
+		c.Proxy = value
+	case "no_proxy":
+		c.NoProxy = value
+	case "install_policy":
+		if _, err := manager.ParseInstallPolicy(value); err != nil {
+			return err
+		}
+		c.InstallPolicy = value
+	case "assume_yes":
+		if c.AssumeYes, err = strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+	case "output":
+		if !slices.Contains(OutputFormats, value) {
+			return fmt.Errorf("unknown output format %q, expected one of %s", value, strings.Join(OutputFormats, ", "))
+		}
+		c.Output = value
+	case "concurrency":
+		if c.Concurrency, err = strconv.Atoi(value); err != nil || c.Concurrency < 0 {
+			return fmt.Errorf("invalid concurrency %q, expected a positive number", value)
+		}
+	case "sudo":
+		if value != SudoAuto && value != SudoNever && value != SudoAlways {
+			return fmt.Errorf("unknown sudo mode %q, expected %s, %s or %s", value, SudoAuto, SudoNever, SudoAlways)
+		}
+		c.Sudo = value
+	case "cache_ttl":
+		if c.CacheTTL, err = time.ParseDuration(value); err != nil || c.CacheTTL < 0 {
+			return fmt.Errorf("invalid duration %q, expected e.g. 90s or 10m", value)
+		}
+		if c.CacheTTL == 0 {
+			// an explicit 0 disables the cache, unlike an unset TTL
+			c.CacheTTL = -1
+		}
+	case "audit_log":
+		c.AuditLog = value
+	default:
+		return errors.New("unknown setting")
+	}
+	return nil
+}
+
+// IsExcluded reports whether the named package manager is excluded.
+func (c *Config) IsExcluded(pm string) bool {
+	for _, name := range c.Exclude {
+		if name == pm {
+			return true
+		}
+	}
+	return false
+}
+
+// durations returns a mapping of durations of a parsed document.
+func durations(value any) (map[string]time.Duration, error) {
+	if value == nil {
+		return nil, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("expected a mapping of command names to durations")
+	}
+
+	m := make(map[string]time.Duration)
+	for key, v := range fields {
+		s, err := scalar(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%s: invalid duration %q, expected e.g. 90s or 10m", key, s)
+		}
+		m[key] = d
+	}
+	return m, nil
+}
+
+// urls returns the mapping of package manager names to URLs of a parsed document.
+func urls(value any) (map[string]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("expected a mapping of package manager names to URLs")
+	}
+
+	m := make(map[string]string)
+	for key, v := range fields {
+		s, err := scalar(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("%s: invalid URL %q", key, s)
+		}
+		m[key] = s
+	}
+	return m, nil
+}
+
+// variables returns the mapping of environment variable names to values of a parsed document.
+func variables(value any) (map[string]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("expected a mapping of environment variable names to values")
+	}
+
+	m := make(map[string]string)
+	for key, v := range fields {
+		s, err := scalar(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		if key == "" || strings.ContainsAny(key, "=\x00") || strings.ContainsRune(s, 0) {
+			return nil, fmt.Errorf("invalid environment variable %q", key)
+		}
+		m[key] = s
+	}
+	return m, nil
+}
+
+// parseHooks returns the hooks of a parsed document, a sequence of mappings.
+func parseHooks(value any) ([]hooks.Hook, error) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, errors.New("expected a list of hooks")
+	}
+
+	var parsed []hooks.Hook
+	for i, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("hook %d: expected a mapping", i+1)
+		}
+		var h hooks.Hook
+		for key, v := range fields {
+			var err error
+			switch key {
+			case "managers":
+				h.PackageManagers, err = list(v)
+			case "packages":
+				h.Packages, err = list(v)
+			default:
+				var s string
+				if s, err = scalar(v); err == nil {
+					err = setHook(&h, key, s)
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("hook %d: %s: %v", i+1, key, err)
+			}
+		}
+		if err := h.Validate(); err != nil {
+			return nil, fmt.Errorf("hook %d: %v", i+1, err)
+		}
+		parsed = append(parsed, h)
+	}
+	return parsed, nil
+}
+
+// parseUpgradePolicy returns the upgrade policy of a parsed document, a mapping.
+func parseUpgradePolicy(value any) (manager.UpgradePolicy, error) {
+	var policy manager.UpgradePolicy
+	if value == nil {
+		return policy, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return policy, errors.New("expected a mapping")
+	}
+
+	for key, v := range fields {
+		var err error
+		switch key {
+		case "exclude":
+			var patterns []string
+			if patterns, err = list(v); err == nil {
+				policy.Exclude, err = parsePatterns(patterns)
+			}
+		case "maintenance_windows":
+			// windows contain commas, such as "Sat,Sun 02:00-06:00", so a single one isn't split as a list
+			var windows []string
+			if s, ok := v.(string); ok {
+				windows = append(windows, s)
+			} else {
+				windows, err = list(v)
+			}
+			if err == nil {
+				policy.Windows, err = parseWindows(windows)
+			}
+		case "security_only":
+			var s string
+			if s, err = scalar(v); err == nil {
+				if policy.SecurityOnly, err = strconv.ParseBool(s); err != nil {
+					err = fmt.Errorf("invalid boolean %q", s)
+				}
+			}
+		case "max_packages":
+			var s string
+			if s, err = scalar(v); err == nil {
+				if policy.MaxPackages, err = strconv.Atoi(s); err != nil || policy.MaxPackages < 0 {
+					err = fmt.Errorf("invalid number of packages %q, expected a positive number", s)
+				}
+			}
+		default:
+			err = errors.New("unknown setting")
+		}
+		if err != nil {
+			return policy, fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return policy, nil
+}
+
+// parsePatterns parses the glob patterns of package names.
+func parsePatterns(exprs []string) ([]*manager.Pattern, error) {
+	var patterns []*manager.Pattern
+	for _, expr := range exprs {
+		p, err := manager.ParsePattern(expr, false)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// parseWindows parses maintenance windows.
+func parseWindows(specs []string) ([]manager.MaintenanceWindow, error) {
+	var windows []manager.MaintenanceWindow
+	for _, spec := range specs {
+		w, err := manager.ParseMaintenanceWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// setHook sets a scalar setting of a hook from its string value.
+func setHook(h *hooks.Hook, key string, value string) error {
+	switch key {
+	case "event":
+		h.Event = value
+	case "command":
+		h.Command = value
+	case "webhook":
+		h.Webhook = value
+	case "on_failure":
+		h.OnFailure = value
+	case "timeout":
+		var err error
+		if h.Timeout, err = time.ParseDuration(value); err != nil || h.Timeout < 0 {
+			return fmt.Errorf("invalid duration %q, expected e.g. 90s or 10m", value)
+		}
+	default:
+		return errors.New("unknown setting")
+	}
+	return nil
+}
+
+// parseNotifiers returns the notifiers of a parsed document, a sequence of mappings.
+func parseNotifiers(value any) ([]notify.Notifier, error) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, errors.New("expected a list of notifiers")
+	}
+
+	var parsed []notify.Notifier
+	for i, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("notifier %d: expected a mapping", i+1)
+		}
+		var n notify.Notifier
+		for key, v := range fields {
+			var err error
+			switch key {
+			case "events":
+				n.Events, err = list(v)
+			case "to":
+				n.To, err = list(v)
+			default:
+				var s string
+				if s, err = scalar(v); err == nil {
+					err = setNotifier(&n, key, s)
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("notifier %d: %s: %v", i+1, key, err)
+			}
+		}
+		if err := n.Validate(); err != nil {
+			return nil, fmt.Errorf("notifier %d: %v", i+1, err)
+		}
+		parsed = append(parsed, n)
+	}
+	return parsed, nil
+}
+
+// setNotifier sets a scalar setting of a notifier from its string value.
+func setNotifier(n *notify.Notifier, key string, value string) error {
+	switch key {
+	case "webhook":
+		n.Webhook = value
+	case "format":
+		n.Format = value
+	case "from":
+		n.From = value
+	case "smtp":
+		n.SMTP = value
+	case "username":
+		n.Username = value
+	case "password":
+		n.Password = value
+	case "password_env":
+		// the password is read from the environment, so that it isn't stored in the configuration file
+		n.Password = os.Getenv(value)
+	case "timeout":
+		var err error
+		if n.Timeout, err = time.ParseDuration(value); err != nil || n.Timeout < 0 {
+			return fmt.Errorf("invalid duration %q, expected e.g. 90s or 10m", value)
+		}
+	default:
+		return errors.New("unknown setting")
+	}
+	return nil
+}
+
+// TimeoutOf returns the timeout of a command, by name: its specific timeout if set, or the default one.
+func (c *Config) TimeoutOf(command string) time.Duration {
+	if timeout, ok := c.Timeouts[command]; ok {
+		return timeout
+	}
+	return c.Timeout
+}
+
+// list returns a list of names of a parsed document, either a sequence or a comma-separated scalar.
+func list(value any) ([]string, error) {
+	items, ok := value.([]any)
+	if !ok {
+		s, err := scalar(value)
+		if err != nil {
+			return nil, err
+		}
+		return splitList(s), nil
+	}
+
+	var names []string
+	for _, item := range items {
+		s, err := scalar(item)
+		if err != nil || s == "" {
+			return nil, errors.New("expected a list of names")
+		}
+		names = append(names, s)
+	}
+	return names, nil
+}
+
+// splitList splits a comma-separated list of names.
+func splitList(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// scalar returns a scalar value of a parsed document as a string.
+func scalar(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", errors.New("expected a scalar value")
+	}
+}
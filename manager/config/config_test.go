@@ -0,0 +1,228 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/config"
+	"github.com/bluet/syspkg/manager/hooks"
+	"github.com/bluet/syspkg/manager/notify"
+)
+
+func TestParse(t *testing.T) {
+	var inputConfig string = strings.Join([]string{
+		`# syspkg defaults`,
+		`managers: [apt, flatpak]`,
+		`exclude:`,
+		`  - snap`,
+		`install_policy: first`,
+		`priority: [flatpak, apt]`,
+		`timeout: 10m`,
+		`timeouts:`,
+		`  find: 30s`,
+		`  show upgradable: 1m`,
+		`lock_wait: 5m`,
+		`auto_refresh: true`,
+		`proxy: http://proxy.example.com:3128`,
+		`no_proxy: localhost,.example.com`,
+		`mirrors:`,
+		`  pip: https://pypi.example.com/simple`,
+		`env:`,
+		`  PIP_DEFAULT_TIMEOUT: 60`,
+		`assume_yes: true`,
+		`output: json`,
+		`concurrency: 4`,
+		`sudo:`,
+		`cache_ttl: 5m`,
+		`audit_log: /var/log/syspkg/audit.log`,
+		`hooks:`,
+		`  - event: post-upgrade`,
+		`    webhook: https://hooks.example.com/syspkg`,
+		`  - event: post-upgrade`,
+		`    managers: [apt]`,
+		`    packages: [nginx]`,
+		`    command: systemctl restart nginx`,
+		`    timeout: 30s`,
+		`    on_failure: abort`,
+		`upgrade:`,
+		`  exclude: ["kernel*", docker-ce]`,
+		`  security_only: true`,
+		`  max_packages: 50`,
+		`  maintenance_windows: Sat,Sun 02:00-06:00`,
+		`notify:`,
+		`  - events: [upgrade, failure]`,
+		`    webhook: https://hooks.example.com/syspkg`,
+		`  - to: ops@example.com`,
+		`    from: syspkg@example.com`,
+		`    smtp: smtp.example.com:587`,
+		`    username: syspkg`,
+		`    password_env: SYSPKG_TEST_SMTP_PASSWORD`,
+	}, "\n")
+	t.Setenv("SYSPKG_TEST_SMTP_PASSWORD", "secret")
+
+	kernel, _ := manager.ParsePattern("kernel*", false)
+	docker, _ := manager.ParsePattern("docker-ce", false)
+
+	want := &config.Config{
+		Managers:      []string{"apt", "flatpak"},
+		Exclude:       []string{"snap"},
+		InstallPolicy: "first",
+		Priority:      []string{"flatpak", "apt"},
+		Timeout:       10 * time.Minute,
+		Timeouts:      map[string]time.Duration{"find": 30 * time.Second, "show upgradable": time.Minute},
+		LockWait:      5 * time.Minute,
+		AutoRefresh:   true,
+		Proxy:         "http://proxy.example.com:3128",
+		NoProxy:       "localhost,.example.com",
+		Mirrors:       map[string]string{"pip": "https://pypi.example.com/simple"},
+		Env:           map[string]string{"PIP_DEFAULT_TIMEOUT": "60"},
+		AssumeYes:     true,
+		Output:        config.OutputJSON,
+		Concurrency:   4,
+		CacheTTL:      5 * time.Minute,
+		AuditLog:      "/var/log/syspkg/audit.log",
+		Hooks: []hooks.Hook{
+			{Event: "post-upgrade", Webhook: "https://hooks.example.com/syspkg"},
+			{Event: "post-upgrade", PackageManagers: []string{"apt"}, Packages: []string{"nginx"}, Command: "systemctl restart nginx", Timeout: 30 * time.Second, OnFailure: hooks.FailureAbort},
+		},
+		Upgrade: manager.UpgradePolicy{
+			Exclude:      []*manager.Pattern{kernel, docker},
+			SecurityOnly: true,
+			MaxPackages:  50,
+			Windows:      []manager.MaintenanceWindow{{Days: []time.Weekday{time.Saturday, time.Sunday}, Start: 2 * time.Hour, End: 6 * time.Hour}},
+		},
+		Notify: []notify.Notifier{
+			{Events: []string{notify.EventUpgrade, notify.EventFailure}, Webhook: "https://hooks.example.com/syspkg"},
+			{To: []string{"ops@example.com"}, From: "syspkg@example.com", SMTP: "smtp.example.com:587", Username: "syspkg", Password: "secret"},
+		},
+	}
+
+	got, err := config.Parse([]byte(inputConfig))
+	if err != nil {
+		t.Fatalf("Parse() error = %+v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+
+	if timeout := got.TimeoutOf("find"); timeout != 30*time.Second {
+		t.Errorf("TimeoutOf() = %s, want %s", timeout, 30*time.Second)
+	}
+	if timeout := got.TimeoutOf("upgrade"); timeout != 10*time.Minute {
+		t.Errorf("TimeoutOf() = %s, want %s", timeout, 10*time.Minute)
+	}
+
+	if got, err := config.Parse([]byte(`{"exclude": "snap, brew", "assume_yes": false}`)); err != nil || !reflect.DeepEqual(got, &config.Config{Exclude: []string{"snap", "brew"}}) {
+		t.Errorf("Parse() = %+v, %+v, want the JSON configuration", got, err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{
+		`timeout: soon`,
+		`output: xml`,
+		`install_policy: everywhere`,
+		`exclude: [snapd]`,
+		`sudo: sometimes`,
+		`concurrency: -1`,
+		"timeouts:\n  find: never",
+		`proxy: proxy.example.com`,
+		"mirrors:\n  pip: pypi",
+		"env:\n  A=B: c",
+		`editor: vim`,
+		`- apt`,
+		"hooks:\n  - event: install\n    command: true",
+		"hooks:\n  - event: pre-install",
+		"hooks:\n  - event: pre-install\n    command: true\n    on_failure: retry",
+		"upgrade:\n  maintenance_windows: [weekends 02:00-06:00]",
+		"upgrade:\n  max_packages: many",
+		"upgrade:\n  reboot: true",
+		"notify:\n  - events: [install]\n    webhook: https://hooks.example.com",
+		"notify:\n  - to: ops@example.com",
+	} {
+		if _, err := config.Parse([]byte(input)); !errors.Is(err, config.ErrInvalidConfig) {
+			t.Errorf("Parse(%q) error = %+v, want %+v", input, err, config.ErrInvalidConfig)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	environment := map[string]string{
+		"SYSPKG_MANAGERS":   "pip,npm",
+		"SYSPKG_SUDO":       "never",
+		"SYSPKG_ASSUME_YES": "",
+		"SYSPKG_CACHE_TTL":  "0",
+		"SYSPKG_PRIORITY":   "snap,apt",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := environment[key]
+		return value, ok
+	}
+
+	c := &config.Config{Managers: []string{"apt"}, AssumeYes: true, Sudo: config.SudoAuto}
+	if err := c.ApplyEnv(lookup); err != nil {
+		t.Fatalf("ApplyEnv() error = %+v", err)
+	}
+	want := &config.Config{Managers: []string{"pip", "npm"}, Priority: []string{"snap", "apt"}, AssumeYes: true, Sudo: config.SudoNever, CacheTTL: -1}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("ApplyEnv() = %+v, want %+v", c, want)
+	}
+
+	environment["SYSPKG_TIMEOUT"] = "forever"
+	if err := c.ApplyEnv(lookup); !errors.Is(err, config.ErrInvalidConfig) {
+		t.Errorf("ApplyEnv() error = %+v, want %+v", err, config.ErrInvalidConfig)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if _, err := config.Load(path, true); err != nil {
+		t.Errorf("Load() error = %+v, want no error for a missing optional file", err)
+	}
+	if _, err := config.Load(path, false); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() error = %+v, want %+v", err, os.ErrNotExist)
+	}
+
+	if err := os.WriteFile(path, []byte("output: xml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(path, true); !errors.Is(err, config.ErrInvalidConfig) {
+		t.Errorf("Load() error = %+v, want %+v", err, config.ErrInvalidConfig)
+	}
+}
+
+func TestLoadTrusted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("files aren't owned by root on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if _, err := config.LoadTrusted(path, true); err != nil {
+		t.Errorf("LoadTrusted() error = %+v, want no error for a missing optional file", err)
+	}
+
+	if err := os.WriteFile(path, []byte("output: json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var expected error
+	if os.Geteuid() != 0 {
+		// the file is owned by the user running the tests
+		expected = config.ErrUntrustedConfig
+	}
+	if _, err := config.LoadTrusted(path, true); !errors.Is(err, expected) {
+		t.Errorf("LoadTrusted() error = %+v, want %+v", err, expected)
+	}
+
+	if err := os.Chmod(path, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.LoadTrusted(path, true); !errors.Is(err, config.ErrUntrustedConfig) {
+		t.Errorf("LoadTrusted() error = %+v, want %+v for a file writable by other users", err, config.ErrUntrustedConfig)
+	}
+}
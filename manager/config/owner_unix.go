@@ -0,0 +1,17 @@
+//go:build unix
+
+package config
+
+import (
+	"os"
+	"syscall"
+)
+
+// checkOwner returns ErrUntrustedConfig unless the file of info is owned by root and not writable by other users.
+func checkOwner(info os.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st.Uid != 0 || info.Mode().Perm()&0o022 != 0 {
+		return ErrUntrustedConfig
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Package container runs the commands of package managers inside a running container, with docker exec or podman
+// exec, or in a root filesystem mounted at another directory, such as an extracted image, with chroot, so that the
+// packages of containers and images are listed, searched and audited like the ones of the host.
+//
+// A Target is a manager.CommandWrapper, set in manager.Options.Wrapper, and finds the package managers of the
+// target with Available, set in syspkg.IncludeOptions.Available.
+//
+// This package is part of the syspkg library.
+package container
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/platform"
+)
+
+// Runtimes are the container runtimes running the commands in containers, in order of preference.
+var Runtimes = []string{"docker", "podman"}
+
+// Commands are the commands the package managers are found by in targets, by package manager name. The other package
+// managers, which find their commands on the host, such as pip, aren't supported in targets.
+var Commands = map[string]string{
+	"apk":     "apk",
+	"apt":     "apt",
+	"gem":     "gem",
+	"npm":     "npm",
+	"pacman":  "pacman",
+	"portage": "emerge",
+	"zypper":  "zypper",
+}
+
+// binDirs are the directories the commands of package managers are looked for in root filesystems.
+var binDirs = []string{"usr/local/sbin", "usr/local/bin", "usr/sbin", "usr/bin", "sbin", "bin"}
+
+// ErrNoRuntime is returned when no container runtime is installed.
+var ErrNoRuntime = errors.New("container: neither docker nor podman is installed")
+
+// Target is a container, or a root filesystem, the commands of package managers run in.
+type Target struct {
+	// Container is the ID or name of a running container.
+	Container string
+
+	// Runtime is the container runtime running the commands in Container, one of Runtimes; the first one installed
+	// if empty.
+	Runtime string
+
+	// Root is the directory a root filesystem is mounted at, if Container is empty.
+	Root string
+
+	once      sync.Once
+	available map[string]bool
+}
+
+// make sure Target implements manager.CommandWrapper
+var _ manager.CommandWrapper = (*Target)(nil)
+
+// Validate checks that the target is either a container, with an installed runtime, which it sets if empty, or the
+// directory of a root filesystem.
+func (t *Target) Validate() error {
+	if (t.Container == "") == (t.Root == "") {
+		return errors.New("container: expected either a container or a root directory")
+	}
+	if t.Root != "" {
+		info, err := os.Stat(t.Root)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("container: %s is not a directory", t.Root)
+		}
+		return nil
+	}
+
+	if t.Runtime != "" {
+		_, err := exec.LookPath(t.Runtime)
+		return err
+	}
+	for _, runtime := range Runtimes {
+		if _, err := exec.LookPath(runtime); err == nil {
+			t.Runtime = runtime
+			return nil
+		}
+	}
+	return ErrNoRuntime
+}
+
+// CommandPrefix returns the command running commands in the target: runtime exec in a container, in the C locale the
+// package managers parse the output of their commands in, or chroot in a root filesystem.
+func (t *Target) CommandPrefix() []string {
+	if t.Root != "" {
+		return []string{"chroot", t.Root}
+	}
+	return []string{t.Runtime, "exec", "-i", "-e", "LC_ALL=C", t.Container}
+}
+
+// String returns the target, such as "container web" or "root /mnt/image".
+func (t *Target) String() string {
+	if t.Root != "" {
+		return "root " + t.Root
+	}
+	return "container " + t.Container
+}
+
+// Platform returns the platform of the target, from its os-release file.
+func (t *Target) Platform() (platform.Platform, error) {
+	if t.Root != "" {
+		return platform.DetectAt(t.Root), nil
+	}
+	output, err := t.run("cat", "/etc/os-release")
+	if err != nil {
+		return platform.Platform{}, fmt.Errorf("container: reading the os-release of %s: %w", t.Container, err)
+	}
+	return platform.FromOSRelease(output), nil
+}
+
+// Available reports whether the named package manager is available in the target: whether it is supported in
+// targets, and its command is installed there.
+func (t *Target) Available(name string) bool {
+	command, ok := Commands[name]
+	if !ok {
+		return false
+	}
+	if t.Root != "" {
+		for _, dir := range binDirs {
+			// the commands are often symbolic links, absolute ones pointing inside the root filesystem
+			if _, err := os.Lstat(filepath.Join(t.Root, dir, command)); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	// the commands of all package managers are looked for at once, running a single command in the container
+	t.once.Do(func() {
+		t.available = make(map[string]bool)
+		args := []string{"sh", "-c", `for c; do command -v "$c"; done; true`, "sh"}
+		for _, command := range Commands {
+			args = append(args, command)
+		}
+		output, _ := t.run(args...)
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				t.available[path.Base(line)] = true
+			}
+		}
+	})
+	return t.available[command]
+}
+
+// run runs a command in the container, and returns its output.
+func (t *Target) run(args ...string) ([]byte, error) {
+	prefix := t.CommandPrefix()
+	return exec.Command(prefix[0], append(prefix[1:], args...)...).Output()
+}
@@ -0,0 +1,89 @@
+package container_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/bluet/syspkg/manager/container"
+	"github.com/bluet/syspkg/manager/platform"
+)
+
+func TestRoot(t *testing.T) {
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		"etc/os-release": "ID=debian\nVERSION_ID=\"12\"\nPRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\n",
+		"usr/bin/dpkg":   "",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// an absolute symbolic link, pointing inside the root filesystem
+	if err := os.Symlink("/usr/bin/apt-real", filepath.Join(root, "usr/bin/apt")); err != nil {
+		t.Fatal(err)
+	}
+
+	target := &container.Target{Root: root}
+	if err := target.Validate(); err != nil {
+		t.Fatalf("Validate() error = %+v", err)
+	}
+	if expected := []string{"chroot", root}; !reflect.DeepEqual(target.CommandPrefix(), expected) {
+		t.Errorf("CommandPrefix() = %+v, want %+v", target.CommandPrefix(), expected)
+	}
+	if !target.Available("apt") || target.Available("apk") || target.Available("pip") {
+		t.Errorf("Available() found apt %v, apk %v and pip %v, want only apt", target.Available("apt"), target.Available("apk"), target.Available("pip"))
+	}
+	if p, err := target.Platform(); err != nil || p.ID != "debian" || p.VersionID != "12" {
+		t.Errorf("Platform() = %+v, %+v, want Debian 12", p, err)
+	}
+
+	for _, invalid := range []*container.Target{{}, {Root: filepath.Join(root, "missing")}, {Root: root, Container: "web"}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate(%+v) error = nil, want an error", invalid)
+		}
+	}
+}
+
+func TestContainer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake container runtime is a shell script")
+	}
+	// a fake container runtime, run as "docker exec -i -e LC_ALL=C <container> <command>..."
+	docker := filepath.Join(t.TempDir(), "docker")
+	script := `#!/bin/sh
+shift 5
+case "$1" in
+cat) printf 'ID=alpine\nVERSION_ID=3.19.1\nPRETTY_NAME="Alpine Linux v3.19"\n' ;;
+sh) echo /sbin/apk; echo /usr/bin/npm ;;
+esac
+`
+	if err := os.WriteFile(docker, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	target := &container.Target{Container: "web", Runtime: docker}
+	if err := target.Validate(); err != nil {
+		t.Fatalf("Validate() error = %+v", err)
+	}
+	if expected := []string{docker, "exec", "-i", "-e", "LC_ALL=C", "web"}; !reflect.DeepEqual(target.CommandPrefix(), expected) {
+		t.Errorf("CommandPrefix() = %+v, want %+v", target.CommandPrefix(), expected)
+	}
+	if !target.Available("apk") || !target.Available("npm") || target.Available("apt") {
+		t.Errorf("Available() found apk %v, npm %v and apt %v, want apk and npm", target.Available("apk"), target.Available("npm"), target.Available("apt"))
+	}
+
+	actual, err := target.Platform()
+	if err != nil {
+		t.Fatalf("Platform() error = %+v", err)
+	}
+	expected := platform.Platform{OS: "linux", ID: "alpine", VersionID: "3.19.1", Name: "Alpine Linux v3.19", Virtualization: platform.VirtualizationContainer}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Platform() = %#v, want %#v", actual, expected)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package manager
+
+// FreeDiskSpace returns ErrOperationNotSupported, as the free disk space isn't queried on this platform.
+func FreeDiskSpace(path string) (int64, error) {
+	return 0, ErrOperationNotSupported
+}
@@ -0,0 +1,98 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"sort"
+	"strings"
+)
+
+// DuplicateInfo is a package found with the same name in several package managers, such as firefox installed both
+// as a deb by apt and as a snap.
+type DuplicateInfo struct {
+	// Name is the name shared by the packages, lowercased, as returned by DuplicateKey.
+	Name string `json:"name"`
+
+	// Packages are the packages with this name, with their versions and statuses, sorted by package manager.
+	Packages []PackageInfo `json:"packages"`
+
+	// Conflict is set when the package is installed by several package managers, which may shadow each other,
+	// such as two firefox commands in the PATH.
+	Conflict bool `json:"conflict"`
+}
+
+// DuplicateKey returns the name packages are grouped by to find duplicates: their lowercased name, or the last
+// component of the application IDs of flatpak, such as "firefox" for org.mozilla.firefox.
+func DuplicateKey(pkg PackageInfo) string {
+	name := strings.ToLower(pkg.Name)
+	if pkg.PackageManager == "flatpak" {
+		name = name[strings.LastIndex(name, ".")+1:]
+	}
+	return name
+}
+
+// FindDuplicates groups the packages of several package managers by DuplicateKey, and returns the groups with packages
+// of more than one package manager, sorted by name.
+func FindDuplicates(packages []PackageInfo) []DuplicateInfo {
+	groups := make(map[string][]PackageInfo)
+	for _, pkg := range packages {
+		key := DuplicateKey(pkg)
+		groups[key] = append(groups[key], pkg)
+	}
+
+	var duplicates []DuplicateInfo
+	for name, group := range groups {
+		managers := make(map[string]bool)
+		installed := make(map[string]bool)
+		for _, pkg := range group {
+			managers[pkg.PackageManager] = true
+			if pkg.Status != PackageStatusAvailable && pkg.Status != PackageStatusUnknown && pkg.Status != "" {
+				installed[pkg.PackageManager] = true
+			}
+		}
+		if len(managers) < 2 {
+			continue
+		}
+
+		sort.SliceStable(group, func(i, j int) bool { return group[i].PackageManager < group[j].PackageManager })
+		duplicates = append(duplicates, DuplicateInfo{Name: name, Packages: group, Conflict: len(installed) > 1})
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool { return duplicates[i].Name < duplicates[j].Name })
+	return duplicates
+}
+
+// AnnotateDuplicates records in the AdditionalData of the packages found in duplicates, as "duplicates", the other
+// package managers providing them with their versions, such as "snap 1.86.0, flatpak 1.86.1", and returns the packages.
+func AnnotateDuplicates(packages []PackageInfo, duplicates []DuplicateInfo) []PackageInfo {
+	byName := make(map[string]DuplicateInfo, len(duplicates))
+	for _, duplicate := range duplicates {
+		byName[duplicate.Name] = duplicate
+	}
+
+	for i := range packages {
+		duplicate, ok := byName[DuplicateKey(packages[i])]
+		if !ok {
+			continue
+		}
+
+		var others []string
+		for _, other := range duplicate.Packages {
+			if other.PackageManager == packages[i].PackageManager {
+				continue
+			}
+			version := other.Version
+			if version == "" {
+				version = other.NewVersion
+			}
+			others = append(others, strings.TrimSpace(other.PackageManager+" "+version))
+		}
+		// the map is copied, as it is shared with the packages of duplicates
+		data := make(map[string]string, len(packages[i].AdditionalData)+1)
+		for k, v := range packages[i].AdditionalData {
+			data[k] = v
+		}
+		data["duplicates"] = strings.Join(others, ", ")
+		packages[i].AdditionalData = data
+	}
+	return packages
+}
@@ -0,0 +1,41 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	packages := []manager.PackageInfo{
+		{Name: "code", Version: "1.85.1-1702462158", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "vim", Version: "2:9.0.1378-2", Status: manager.PackageStatusInstalled, PackageManager: "apt"},
+		{Name: "firefox", NewVersion: "115.6.0esr-1", Status: manager.PackageStatusAvailable, PackageManager: "apt"},
+		{Name: "code", Version: "1.86.0", Status: manager.PackageStatusInstalled, PackageManager: "snap"},
+		{Name: "firefox", Version: "122.0-2", Status: manager.PackageStatusInstalled, PackageManager: "snap"},
+		{Name: "org.mozilla.firefox", Version: "122.0", Status: manager.PackageStatusInstalled, PackageManager: "flatpak"},
+	}
+
+	expectedDuplicates := []manager.DuplicateInfo{
+		{Name: "code", Conflict: true, Packages: []manager.PackageInfo{packages[0], packages[3]}},
+		{Name: "firefox", Conflict: true, Packages: []manager.PackageInfo{packages[2], packages[5], packages[4]}},
+	}
+
+	actualDuplicates := manager.FindDuplicates(packages)
+	if !reflect.DeepEqual(expectedDuplicates, actualDuplicates) {
+		t.Errorf("FindDuplicates() = %+v, want %+v", actualDuplicates, expectedDuplicates)
+	}
+
+	annotated := manager.AnnotateDuplicates(packages, actualDuplicates)
+	expectedAnnotations := []string{"snap 1.86.0", "", "flatpak 122.0, snap 122.0-2", "apt 1.85.1-1702462158", "apt 115.6.0esr-1, flatpak 122.0", "apt 115.6.0esr-1, snap 122.0-2"}
+	for i, pkg := range annotated {
+		if pkg.AdditionalData["duplicates"] != expectedAnnotations[i] {
+			t.Errorf("AnnotateDuplicates() of %s %s = %q, want %q", pkg.PackageManager, pkg.Name, pkg.AdditionalData["duplicates"], expectedAnnotations[i])
+		}
+	}
+
+	if duplicates := manager.FindDuplicates(packages[:3]); duplicates != nil {
+		t.Errorf("FindDuplicates() = %+v, want nil", duplicates)
+	}
+}
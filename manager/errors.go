@@ -0,0 +1,19 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import "errors"
+
+// ErrOperationNotSupported is returned by package managers for operations the underlying tool cannot perform,
+// e.g. searching packages with pip, whose registry no longer provides a search API.
+var ErrOperationNotSupported = errors.New("operation not supported by this package manager")
+
+// ErrVersionNotSupported is returned by package managers that can't install a specific version of a package,
+// when one is requested with a "name=version" specifier.
+var ErrVersionNotSupported = errors.New("installing a specific version is not supported by this package manager")
+
+// ErrInvalidPackageSpec is returned for malformed package specifiers, such as "name=" or "=version".
+var ErrInvalidPackageSpec = errors.New("invalid package specifier")
+
+// ErrInterrupted is returned, wrapped, by Interrupted for operations whose commands were interrupted, such as by
+// Ctrl-C, through Options.Context. Their changes may be partially applied.
+var ErrInterrupted = errors.New("interrupted")
@@ -0,0 +1,236 @@
+// Package fixture records the commands run by package managers, with the environment syspkg sets for them, their
+// output and their exit status, to fixture files, and replays the recorded results instead of running the commands,
+// so that package managers can be tested end to end without being installed, and their fixtures regenerated on new
+// releases of the distributions by running the same operations again.
+//
+// Commands are recorded and replayed by the executable running syspkg, which runs them through itself, and must call
+// Main at the start of its main function, or of TestMain in tests:
+//
+//	func TestMain(m *testing.M) {
+//		fixture.Main()
+//		os.Exit(m.Run())
+//	}
+//
+//	opts := &manager.Options{Wrapper: fixture.Replay("testdata/debian-12")}
+//	packages, err := apt.NewPackageManager().ListInstalled(opts)
+//
+// This package is part of the syspkg library.
+package fixture
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// flag is the first argument of the commands run through the executable running syspkg, which Main handles.
+const flag = "-syspkg-fixture"
+
+// exitNotRecorded is the exit status of the commands replayed without a fixture, as of commands not found by a shell.
+const exitNotRecorded = 127
+
+// ErrNotRecorded is returned when replaying a command that has no fixture.
+var ErrNotRecorded = errors.New("fixture: the command was not recorded")
+
+// Fixture is the recorded result of a command.
+type Fixture struct {
+	// Command is the name of the command, such as dpkg-query.
+	Command string `json:"command"`
+
+	// Args are the arguments of the command.
+	Args []string `json:"args"`
+
+	// Env are the environment variables set for the command by syspkg, such as LC_ALL=C, leaving out the ones it
+	// inherited unchanged.
+	Env []string `json:"env,omitempty"`
+
+	// Stdout and Stderr are the outputs of the command.
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+
+	// ExitCode is the exit status of the command, or -1 if it was killed by a signal.
+	ExitCode int `json:"exit_code"`
+
+	// Delay and Hang inject faults in the replayed command, to test the timeouts, the retries and the interruptions
+	// of operations: Delay is how long the command waits before writing its outputs, and Hang makes it wait until
+	// it is killed or interrupted after writing them, rather than exit. Recorded fixtures have neither.
+	Delay time.Duration `json:"delay,omitempty"`
+	Hang  bool          `json:"hang,omitempty"`
+}
+
+// Runner records the commands run by operations to the fixture files of Dir, or replays them. It implements
+// manager.CommandWrapper, to be set in manager.Options.Wrapper.
+type Runner struct {
+	// Dir is the directory of the fixture files.
+	Dir string
+
+	// Replay replays the fixtures instead of running the commands.
+	Replay bool
+
+	// inherited are the fingerprints of the environment of syspkg, which the recorded environments leave out.
+	inherited string
+}
+
+var _ manager.CommandWrapper = (*Runner)(nil)
+
+// Record returns a Runner running the commands, and recording them to the fixture files of dir, replacing the fixtures
+// of the same commands.
+func Record(dir string) *Runner {
+	return &Runner{Dir: dir, inherited: fingerprints(os.Environ())}
+}
+
+// Replay returns a Runner replaying the fixtures of dir instead of running the commands. Commands without a fixture
+// fail with exit status 127, printing ErrNotRecorded.
+func Replay(dir string) *Runner {
+	return &Runner{Dir: dir, Replay: true}
+}
+
+// CommandPrefix returns the executable running syspkg, and the arguments making Main record or replay the command.
+func (r *Runner) CommandPrefix() []string {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = os.Args[0]
+	}
+	mode := "record"
+	if r.Replay {
+		mode = "replay"
+	}
+	return []string{executable, flag, mode, r.Dir, r.inherited, "--"}
+}
+
+// Main records or replays the command of the arguments of the process and exits, if it was run through a Runner,
+// and returns otherwise.
+func Main() {
+	if len(os.Args) < 7 || os.Args[1] != flag || os.Args[5] != "--" {
+		return
+	}
+	mode, dir, inherited, name, args := os.Args[2], os.Args[3], os.Args[4], os.Args[6], os.Args[7:]
+
+	var code int
+	var err error
+	if mode == "replay" {
+		code, err = replay(dir, name, args)
+	} else {
+		code, err = record(dir, name, args, inherited)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if code == 0 {
+			code = exitNotRecorded
+		}
+	}
+	os.Exit(code)
+}
+
+// Path returns the path of the fixture file of a command in dir, named after the command and a hash of its arguments.
+// Commands run by their path, such as the pip of a virtual environment, share the fixtures of their name.
+func Path(dir, name string, args []string) string {
+	name = filepath.Base(name)
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{name}, args...), "\x00")))
+	return filepath.Join(dir, fmt.Sprintf("%s-%x.json", name, sum[:6]))
+}
+
+// Load returns the fixture of a command in dir, or an error wrapping ErrNotRecorded.
+func Load(dir, name string, args []string) (Fixture, error) {
+	var f Fixture
+	data, err := os.ReadFile(Path(dir, name, args))
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, fmt.Errorf("%w: %s in %s", ErrNotRecorded, strings.Join(append([]string{name}, args...), " "), dir)
+	}
+	if err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("fixture: %s: %w", Path(dir, name, args), err)
+	}
+	return f, nil
+}
+
+// Save writes the fixture file of a command to dir, creating it if needed. Tests use it to replay the results of
+// commands they define, such as failures or hung commands.
+func Save(dir string, f Fixture) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(Path(dir, f.Command, f.Args), append(data, '\n'), 0o644)
+}
+
+// record runs a command, passing its input and outputs through, and saves its fixture.
+func record(dir, name string, args []string, inherited string) (int, error) {
+	// the command is interrupted along with syspkg, and its result recorded
+	signal.Notify(make(chan os.Signal, 1), os.Interrupt)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(os.Stdout, &stdout)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	err := cmd.Run()
+	code := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		return exitNotRecorded, err
+	}
+
+	var env []string
+	known := strings.Split(inherited, ",")
+	for _, kv := range os.Environ() {
+		if !slices.Contains(known, fingerprint(kv)) {
+			env = append(env, kv)
+		}
+	}
+	f := Fixture{Command: name, Args: args, Env: env, Stdout: stdout.String(), Stderr: stderr.String(), ExitCode: code}
+	return code, Save(dir, f)
+}
+
+// replay writes the recorded outputs of a command, and returns its recorded exit status.
+func replay(dir, name string, args []string) (int, error) {
+	f, err := Load(dir, name, args)
+	if err != nil {
+		return exitNotRecorded, err
+	}
+	time.Sleep(f.Delay)
+	_, _ = io.WriteString(os.Stdout, f.Stdout)
+	_, _ = io.WriteString(os.Stderr, f.Stderr)
+	for f.Hang {
+		time.Sleep(time.Hour)
+	}
+	return f.ExitCode, nil
+}
+
+// fingerprints returns the fingerprints of the variables of env, comma-separated, which the recorded commands receive
+// as an argument rather than the values of the variables.
+func fingerprints(env []string) string {
+	prints := make([]string, 0, len(env))
+	for _, kv := range env {
+		prints = append(prints, fingerprint(kv))
+	}
+	return strings.Join(prints, ",")
+}
+
+// fingerprint returns a short hash of an environment variable, as NAME=value.
+func fingerprint(kv string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(kv))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
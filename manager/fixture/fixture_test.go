@@ -0,0 +1,119 @@
+package fixture_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/fixture"
+)
+
+func TestMain(m *testing.M) {
+	fixture.Main()
+	os.Exit(m.Run())
+}
+
+func TestRecordReplay(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "ran")
+	args := []string{"-c", `touch "$1"; echo "installed $LC_ALL"; echo warning >&2; exit 3`, "sh", marker}
+
+	// recorded commands run, and their results are saved
+	opts := &manager.Options{Wrapper: fixture.Record(dir)}
+	cmd := manager.Command(opts, "sh", args...)
+	cmd.Env = append(cmd.Environ(), "LC_ALL=C")
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 || string(out) != "installed C\n" {
+		t.Fatalf("Output() = %q, %+v, want the output and the exit status of the command", out, err)
+	}
+
+	expectedFixture := fixture.Fixture{Command: "sh", Args: args, Env: []string{"LC_ALL=C"}, Stdout: "installed C\n", Stderr: "warning\n", ExitCode: 3}
+	actualFixture, err := fixture.Load(dir, "sh", args)
+	if err != nil {
+		t.Fatalf("Load() error = %+v", err)
+	}
+	if !reflect.DeepEqual(actualFixture, expectedFixture) {
+		t.Errorf("Load() = %+v, want %+v", actualFixture, expectedFixture)
+	}
+
+	// replayed commands don't run
+	if err := os.Remove(marker); err != nil {
+		t.Fatalf("the recorded command didn't run: %+v", err)
+	}
+	opts = &manager.Options{Wrapper: fixture.Replay(dir)}
+	out, err = manager.Command(opts, "sh", args...).Output()
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 || string(exitErr.Stderr) != "warning\n" || string(out) != "installed C\n" {
+		t.Errorf("Output() = %q, %+v, want the recorded results", out, err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Errorf("the replayed command ran")
+	}
+
+	out, err = manager.Command(opts, "sh", "-c", "exit 0").Output()
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 127 || !strings.Contains(string(exitErr.Stderr), fixture.ErrNotRecorded.Error()) {
+		t.Errorf("Output() = %q, %+v, want the command not recorded", out, err)
+	}
+}
+
+func TestReplayFaults(t *testing.T) {
+	dir := t.TempDir()
+	faults := []fixture.Fixture{
+		{Command: "apt", Args: []string{"list", "--installed"}, Stdout: "Listing...\n", Delay: 300 * time.Millisecond},
+		{Command: "apt", Args: []string{"install", "vim"}, Stdout: "Unpacking vim (2:9.0.1378-2) ...\n", Hang: true},
+		{Command: "apt", Args: []string{"remove", "vim"}, Stderr: "E: Could not get lock /var/lib/dpkg/lock-frontend\n", ExitCode: 100},
+	}
+	for _, f := range faults {
+		if err := fixture.Save(dir, f); err != nil {
+			t.Fatalf("Save() error = %+v", err)
+		}
+	}
+	replay := fixture.Replay(dir)
+
+	// delayed responses
+	start := time.Now()
+	out, err := manager.Command(&manager.Options{Wrapper: replay}, "apt", "list", "--installed").Output()
+	if err != nil || string(out) != "Listing...\n" || time.Since(start) < 300*time.Millisecond {
+		t.Errorf("Output() = %q, %+v after %s, want the delayed output", out, err, time.Since(start))
+	}
+
+	// hung commands are killed after the timeout, and interrupted with the context
+	start = time.Now()
+	out, err = manager.Command(&manager.Options{Wrapper: replay, Timeout: 200 * time.Millisecond}, "apt", "install", "vim").Output()
+	if err == nil || string(out) != "Unpacking vim (2:9.0.1378-2) ...\n" || time.Since(start) > 5*time.Second {
+		t.Errorf("Output() = %q, %+v after %s, want the partial output and the command killed", out, err, time.Since(start))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := &manager.Options{Wrapper: replay, Context: ctx}
+	time.AfterFunc(200*time.Millisecond, cancel)
+	_, err = manager.Command(opts, "apt", "install", "vim").Output()
+	if err = manager.Interrupted(opts, err); !errors.Is(err, manager.ErrInterrupted) {
+		t.Errorf("Output() error = %+v, want %+v", err, manager.ErrInterrupted)
+	}
+
+	// commands failing because of a lock are retried until LockWait elapsed
+	opts = &manager.Options{Wrapper: replay, LockWait: 1500 * time.Millisecond}
+	attempts := 0
+	locked := func(err error) bool {
+		var exitErr *exec.ExitError
+		return errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "Could not get lock")
+	}
+	_, err = manager.RetryLocked(manager.Command(opts, "apt", "remove", "vim"), opts, locked, func(cmd *exec.Cmd) ([]byte, error) {
+		attempts++
+		return cmd.Output()
+	})
+	if !errors.Is(err, manager.ErrLocked) || attempts < 2 {
+		t.Errorf("RetryLocked() error = %+v after %d attempts, want %+v after retries", err, attempts, manager.ErrLocked)
+	}
+}
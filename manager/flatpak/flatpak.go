@@ -12,9 +12,10 @@
 package flatpak
 
 import (
-	"log"
+	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 
 	// "github.com/rs/zerolog"
 	// "github.com/rs/zerolog/log"
@@ -38,8 +39,41 @@ const (
 	ArgsNonInteractive string = "--noninteractive"
 	ArgsVerbose        string = "--verbose"
 	ArgsUpsert         string = "--or-update"
+	ArgsGPGImport      string = "--gpg-import"
+	ArgsFrom           string = "--from"
+	ArgsBundle         string = "--bundle"
+	ArgsUser           string = "--user"
+	ArgsSystem         string = "--system"
+	ArgsIfNotExists    string = "--if-not-exists"
+	ArgsShowDisabled   string = "--show-disabled"
+	ArgsUpdates        string = "--updates"
+	ArgsApp            string = "--app"
+	ArgsRuntime        string = "--runtime"
+	ArgsRepairDryRun   string = "--dry-run"
 )
 
+// RemotesColumns are the columns of flatpak remotes parsed by ParseRemotesOutput, in order.
+var RemotesColumns string = "--columns=name,options,url,title"
+
+// The columns of flatpak list, remote-ls and search parsed by ParseListInstalledOutput, ParseListUpgradableOutput and
+// ParseFindOutput, in order. Selecting them explicitly keeps the output the same across the versions of flatpak,
+// whose default columns differ.
+var (
+	ListColumns    string = "--columns=application,version,branch,arch,origin,installation,size"
+	UpdatesColumns string = "--columns=application,version,branch,arch,origin"
+	SearchColumns  string = "--columns=application,version,branch,remotes,name,description"
+)
+
+// Kinds maps the arguments of flatpak list and remote-ls selecting the kind of refs to the "kind" of the packages
+// listed with them, in AdditionalData.
+var Kinds = []struct {
+	Args string
+	Kind string
+}{
+	{ArgsApp, "app"},
+	{ArgsRuntime, "runtime"},
+}
+
 // ENV_NonInteractive is an environment variable that sets the locale to C for non-interactive mode.
 var ENV_NonInteractive []string = []string{"LC_ALL=C"}
 
@@ -57,9 +91,58 @@ func (a *PackageManager) GetPackageManager() string {
 	return pm
 }
 
+// Capabilities returns the operations and options supported by flatpak.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:         true,
+		Delete:         true,
+		DryRun:         true,
+		Scope:          true,
+		ListUpgradable: true,
+		Keys:           true,
+		LocalInstall:   true,
+		Repositories:   true,
+		Verify:         true,
+	}
+}
+
 // Install installs the given packages using Flatpak with the provided options.
 func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
-	args := append([]string{"install", ArgsFixBroken, ArgsUpsert, ArgsVerbose}, pkgs...)
+	if err := manager.RejectVersionSpecs(pkgs); err != nil {
+		return nil, err
+	}
+
+	return a.install(pkgs, opts)
+}
+
+// InstallLocal installs the provided .flatpakref files, which reference an application in a remote, and .flatpak
+// bundles, which contain it and can be installed offline, using flatpak install --from and --bundle.
+func (a *PackageManager) InstallLocal(paths []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	files, err := manager.LocalPackageFiles(paths, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	// flatpak installs a single file at a time
+	var packages []manager.PackageInfo
+	for _, file := range files {
+		kind := ArgsFrom
+		if strings.HasSuffix(strings.ToLower(file), ".flatpak") {
+			kind = ArgsBundle
+		}
+		installed, err := a.install([]string{kind, file}, opts)
+		packages = append(packages, installed...)
+		if err != nil {
+			return packages, err
+		}
+	}
+	return packages, nil
+}
+
+// install runs flatpak install with the provided arguments, which are the packages to install and extra options.
+func (a *PackageManager) install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"install", ArgsFixBroken, ArgsUpsert, ArgsVerbose}, scopeArgs(opts)...)
+	args = append(args, pkgs...)
 
 	if opts == nil {
 		opts = &manager.Options{
@@ -82,17 +165,17 @@ func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manage
 		args = append(args, ArgsVerbose)
 	}
 
-	cmd := exec.Command(pm, args...)
+	cmd := manager.Command(opts, pm, args...)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	} else {
-		cmd.Env = ENV_NonInteractive
-		out, err := cmd.Output()
+		cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+		out, err := manager.Output(cmd)
 		if err != nil {
 			return nil, err
 		}
@@ -102,7 +185,8 @@ func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manage
 
 // Delete removes the given packages using Flatpak with the provided options.
 func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
-	args := append([]string{"uninstall", ArgsFixBroken, ArgsVerbose}, pkgs...)
+	args := append([]string{"uninstall", ArgsFixBroken, ArgsVerbose}, scopeArgs(opts)...)
+	args = append(args, pkgs...)
 
 	if opts == nil {
 		opts = &manager.Options{
@@ -125,17 +209,17 @@ func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager
 		args = append(args, ArgsVerbose)
 	}
 
-	cmd := exec.Command(pm, args...)
+	cmd := manager.Command(opts, pm, args...)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	} else {
-		cmd.Env = ENV_NonInteractive
-		out, err := cmd.Output()
+		cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+		out, err := manager.Output(cmd)
 		if err != nil {
 			return nil, err
 		}
@@ -152,7 +236,8 @@ func (a *PackageManager) Refresh(opts *manager.Options) error {
 
 // Find searches for packages matching the given keywords using Flatpak with the provided options.
 func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
-	args := append([]string{"search", ArgsVerbose}, keywords...)
+	args := append([]string{"search", SearchColumns}, scopeArgs(opts)...)
+	args = append(args, keywords...)
 
 	if opts == nil {
 		opts = &manager.Options{
@@ -166,17 +251,17 @@ func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manag
 		args = append(args, ArgsVerbose)
 	}
 
-	cmd := exec.Command(pm, args...)
+	cmd := manager.Command(opts, pm, args...)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	} else {
-		cmd.Env = ENV_NonInteractive
-		out, err := cmd.Output()
+		cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+		out, err := manager.Output(cmd)
 		if err != nil {
 			return nil, err
 		}
@@ -184,31 +269,41 @@ func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manag
 	}
 }
 
-// ListInstalled lists installed packages using Flatpak with the provided options.
+// ListInstalled lists installed applications and runtimes using Flatpak with the provided options.
 func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
-	cmd := exec.Command("flatpak", "list")
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-	return ParseListInstalledOutput(string(out), opts), nil
+	return list(append([]string{"list", ListColumns}, scopeArgs(opts)...), ParseListInstalledOutput, opts)
 }
 
-// ListUpgradable lists upgradable packages using Flatpak with the provided options.
+// ListUpgradable lists upgradable applications and runtimes using Flatpak with the provided options.
 func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
-	cmd := exec.Command(pm, "remote-ls", "--updates")
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, err
+	return list(append([]string{"remote-ls", ArgsUpdates, UpdatesColumns}, scopeArgs(opts)...), ParseListUpgradableOutput, opts)
+}
+
+// list runs flatpak with args once per kind of Kinds, and returns the packages parsed from its output by parse,
+// with their kind set in AdditionalData.
+func list(args []string, parse func(msg string, opts *manager.Options) []manager.PackageInfo, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	for _, kind := range Kinds {
+		cmd := manager.Command(opts, pm, append(args, kind.Args)...)
+		cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+		out, err := manager.Output(cmd)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range parse(string(out), opts) {
+			if pkg.AdditionalData == nil {
+				pkg.AdditionalData = make(map[string]string)
+			}
+			pkg.AdditionalData["kind"] = kind.Kind
+			packages = append(packages, pkg)
+		}
 	}
-	return ParseListUpgradableOutput(string(out), opts), nil
+	return packages, nil
 }
 
 // UpgradeAll upgrades all packages using Flatpak with the provided options.
 func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
-	args := []string{"update"}
+	args := append([]string{"update"}, scopeArgs(opts)...)
 	if opts == nil {
 		opts = &manager.Options{
 			Verbose:     false,
@@ -224,20 +319,20 @@ func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInf
 		args = append(args, ArgsAssumeYes)
 	}
 
-	cmd := exec.Command(pm, args...)
+	cmd := manager.Command(opts, pm, args...)
 
-	log.Printf("Running command: %s %s", pm, args)
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	}
 
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -246,11 +341,154 @@ func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInf
 
 // GetPackageInfo retrieves package information for a single package using Flatpak with the provided options.
 func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
-	cmd := exec.Command(pm, "info", pkg)
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	cmd := manager.Command(opts, pm, append(append([]string{"info"}, scopeArgs(opts)...), pkg)...)
+	cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return manager.PackageInfo{}, err
 	}
 	return ParsePackageInfoOutput(string(out), opts), nil
 }
+
+// Verify checks the objects of the provided refs, or of all installed refs if none are given, using
+// flatpak repair --dry-run, and returns the results of the refs with missing or invalid objects. The objects are the
+// files of the refs stored in the OSTree repository of the installation. The signatures of the refs are checked by
+// flatpak when they are pulled from their remotes.
+func (a *PackageManager) Verify(pkgs []string, opts *manager.Options) ([]manager.VerificationResult, error) {
+	cmd := manager.Command(opts, pm, append([]string{"repair", ArgsRepairDryRun}, scopeArgs(opts)...)...)
+	cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+
+	// the objects found missing or invalid are reported on the standard error
+	out, err := manager.CombinedOutput(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, out)
+	}
+	return manager.FilterVerificationResults(ParseRepairOutput(string(out), opts), pkgs), nil
+}
+
+// ImportKey imports the OpenPGP key file at source for the remote name, using flatpak remote-modify --gpg-import,
+// so that its repository is verified with the key. The remote is returned as the keyring of the key, whose ID is unknown.
+func (a *PackageManager) ImportKey(name string, source string, opts *manager.Options) ([]manager.KeyInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("the remote to import the key for must be specified")
+	}
+
+	keys := []manager.KeyInfo{{Keyring: name, PackageManager: pm}}
+	if opts.DryRun {
+		return keys, nil
+	}
+
+	args := append([]string{"remote-modify", ArgsGPGImport + "=" + source}, scopeArgs(opts)...)
+	args = append(args, name)
+	cmd := manager.Command(opts, pm, args...)
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+	if out, err := manager.CombinedOutput(cmd); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, out)
+	}
+	return keys, nil
+}
+
+// ListKeys is not supported by Flatpak, which doesn't list the keys of its remotes.
+func (a *PackageManager) ListKeys(opts *manager.Options) ([]manager.KeyInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+
+// RemoveKey is not supported by Flatpak, whose remotes keep their keys until they are removed.
+func (a *PackageManager) RemoveKey(id string, opts *manager.Options) ([]manager.KeyInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+
+// ListRepositories lists the remotes of flatpak, including the disabled ones, using flatpak remotes.
+func (a *PackageManager) ListRepositories(opts *manager.Options) ([]manager.RepositoryInfo, error) {
+	args := append([]string{"remotes", ArgsShowDisabled, RemotesColumns}, scopeArgs(opts)...)
+	cmd := manager.Command(opts, pm, args...)
+	cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRemotesOutput(string(out), opts), nil
+}
+
+// AddRepository adds a remote named name, using flatpak remote-add. url is either the URL of the repository,
+// or of a .flatpakrepo file describing it, such as https://dl.flathub.org/repo/flathub.flatpakrepo.
+func (a *PackageManager) AddRepository(name string, url string, opts *manager.Options) ([]manager.RepositoryInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	repos := []manager.RepositoryInfo{{Name: name, URL: url, Enabled: true, Scope: opts.Scope, PackageManager: pm}}
+	if opts.DryRun {
+		return repos, nil
+	}
+
+	args := append([]string{"remote-add", ArgsIfNotExists}, scopeArgs(opts)...)
+	args = append(args, name, url)
+	cmd := manager.Command(opts, pm, args...)
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+	if out, err := manager.CombinedOutput(cmd); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, out)
+	}
+	return repos, nil
+}
+
+// RemoveRepository removes the remote named name, using flatpak remote-delete.
+// It fails if applications installed from the remote are still installed.
+func (a *PackageManager) RemoveRepository(name string, opts *manager.Options) ([]manager.RepositoryInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	repos := []manager.RepositoryInfo{{Name: name, Scope: opts.Scope, PackageManager: pm}}
+	if opts.DryRun {
+		return repos, nil
+	}
+
+	args := append([]string{"remote-delete"}, scopeArgs(opts)...)
+	args = append(args, name)
+	cmd := manager.Command(opts, pm, args...)
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	cmd.Env = manager.Env(opts, append(manager.ProxyEnv(opts), ENV_NonInteractive...))
+	if out, err := manager.CombinedOutput(cmd); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, out)
+	}
+	return repos, nil
+}
+
+// scopeArgs returns the arguments selecting the installation of opts.Scope, per-user or system-wide, if set.
+// Without them, flatpak operates on both installations, and installs system-wide.
+func scopeArgs(opts *manager.Options) []string {
+	if opts == nil {
+		return nil
+	}
+	switch opts.Scope {
+	case manager.ScopeUser:
+		return []string{ArgsUser}
+	case manager.ScopeSystem:
+		return []string{ArgsSystem}
+	}
+	return nil
+}
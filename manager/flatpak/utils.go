@@ -1,8 +1,6 @@
 package flatpak
 
 import (
-	"fmt"
-	"log"
 	"strings"
 
 	// "github.com/rs/zerolog"
@@ -53,7 +51,7 @@ func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo
 
 	for _, line := range lines {
 		if opts.Verbose {
-			log.Printf("%s: %s", pm, line)
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
 		}
 		if strings.HasPrefix(line, "marking op ") {
 			var status manager.PackageStatus = manager.PackageStatusInstalled
@@ -74,7 +72,7 @@ func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo
 			if msgParts[4] != "resolved" {
 				status = manager.PackageStatusUnknown
 				// TODO: this might be an error
-				fmt.Printf("package install/update unresolved: %s", line)
+				opts.Log().Warn("Unresolved install or update", "package_manager", pm, "line", line)
 			} else if strings.HasPrefix(action, "install") || strings.HasPrefix(action, "update") {
 				status = manager.PackageStatusInstalled
 			} else if strings.HasPrefix(action, "uninstall") {
@@ -89,6 +87,7 @@ func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo
 				Category:       category,
 				Status:         status,
 				PackageManager: pm,
+				AdditionalData: metadata("kind", category),
 			}
 			packages = append(packages, packageInfo)
 		}
@@ -97,126 +96,125 @@ func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo
 	return packages
 }
 
-// ParseFindOutput parses the output of the flatpak search command and returns a slice of PackageInfo.
+// ParseFindOutput parses the output of `flatpak search --columns=application,version,branch,remotes,name,description`
+// command and returns a slice of PackageInfo. The columns are separated by tabs, and the description, which may contain
+// anything, comes last.
+//
+// Example output:
+// com.freerdp.FreeRDP	2.10.0	stable	flathub	FreeRDP Remote Desktop Client	FreeRDP (Remote Desktop Protocol) Client for Linux.
+// com.fightcade.Fightcade	2.2	stable	flathub	Fightcade	Play arcade games online.
 func ParseFindOutput(msg string, opts *manager.Options) []manager.PackageInfo {
-	// FreeRDP Remote Desktop Client	FreeRDP (Remote Desktop Protocol) Client for Linux.	com.freerdp.FreeRDP	2.10.0	stable	flathub
-	// Fightcade	Play arcade games online.	com.fightcade.Fightcade	2.2	stable	flathub
-	// White House	Using the magic of CSS, hack your world into a unique burst of color and light revealing hidden objects and clues.	com.endlessnetwork.whitehouse	1.175	stable	flathub
 	var packages []manager.PackageInfo
 
-	msg = strings.TrimSuffix(msg, "\n")
-	var lines []string = strings.Split(string(msg), "\n")
-
-	for _, line := range lines {
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
 		if opts.Verbose {
-			log.Printf("%s: %s", pm, line)
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
 		}
 
-		if len(line) == 0 || strings.HasPrefix(line, "Name\t") {
+		columns := strings.SplitN(line, "\t", 6)
+		// "No matches found" is printed when nothing matches
+		if len(columns) < 4 || columns[0] == "" {
 			continue
 		}
 
-		var parts []string = strings.Split(line, "\t")
-		var name string = parts[2]
-		// var arch string = ""
-		var version string = parts[3]
-		// var category string = parts[5]
-
-		packageInfo := manager.PackageInfo{
-			Name: name,
-			// Arch:           arch,
-			Version:    version,
-			NewVersion: version,
-			// Category:       category,
+		packages = append(packages, manager.PackageInfo{
+			Name:           strings.TrimSpace(columns[0]),
+			Version:        strings.TrimSpace(columns[1]),
+			NewVersion:     strings.TrimSpace(columns[1]),
 			Status:         manager.PackageStatusAvailable,
+			Origin:         strings.TrimSpace(columns[3]),
 			PackageManager: pm,
-		}
-		packages = append(packages, packageInfo)
+			AdditionalData: metadata("branch", columns[2]),
+		})
 	}
 
 	return packages
 }
 
-// ParseListInstalledOutput parses the output of the flatpak list command for installed packages and returns a slice of PackageInfo.
+// ParseListInstalledOutput parses the output of
+// `flatpak list --columns=application,version,branch,arch,origin,installation,size` command for installed packages
+// and returns a slice of PackageInfo. The columns are separated by tabs, and the size column is optional.
+// Whether the packages are applications or runtimes is set by the caller, which lists them with --app or --runtime.
+//
+// Example output:
+// org.gimp.GIMP	2.10.36	stable	x86_64	flathub	system	314.1 MB
+// org.gnome.Platform	45	45	x86_64	flathub	user	1.1 GB
 func ParseListInstalledOutput(msg string, opts *manager.Options) []manager.PackageInfo {
 	var packages []manager.PackageInfo
 
-	msg = strings.TrimSuffix(msg, "\n")
-	var lines []string = strings.Split(string(msg), "\n")
-
-	for _, line := range lines {
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
 		if opts.Verbose {
-			log.Printf("%s: %s", pm, line)
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
 		}
 
-		if len(line) == 0 || strings.HasPrefix(line, "Name\t") {
+		columns := strings.Split(line, "\t")
+		if len(columns) < 6 || columns[0] == "" {
 			continue
 		}
 
-		var parts []string = strings.Split(line, "\t")
-		var name string = parts[1]
-		// var arch string = ""
-		var version string = parts[2]
-		// var category string = parts[5]
-
-		packageInfo := manager.PackageInfo{
-			Name: name,
-			// Arch:           arch,
-			Version: version,
-			// NewVersion:     version,
-			// Category:       category,
+		pkg := manager.PackageInfo{
+			Name:           strings.TrimSpace(columns[0]),
+			Version:        strings.TrimSpace(columns[1]),
+			Arch:           strings.TrimSpace(columns[3]),
 			Status:         manager.PackageStatusInstalled,
+			Origin:         strings.TrimSpace(columns[4]),
 			PackageManager: pm,
+			AdditionalData: metadata("branch", columns[2], "installation", columns[5]),
+		}
+		if len(columns) > 6 {
+			pkg.SizeInstalled, _ = manager.ParseSize(columns[6])
 		}
-		packages = append(packages, packageInfo)
+		packages = append(packages, pkg)
 	}
 
 	return packages
 }
 
-// ParseListUpgradableOutput parses the output of the flatpak list command for upgradable packages and returns a slice of PackageInfo.
+// ParseListUpgradableOutput parses the output of `flatpak remote-ls --updates --columns=application,version,branch,arch,origin`
+// command for upgradable packages and returns a slice of PackageInfo. The columns are separated by tabs, and the version
+// is the one available in the remote, which many runtimes don't set.
+//
+// Example output:
+// org.gimp.GIMP	2.10.38	stable	x86_64	flathub
+// org.freedesktop.Platform.GL.default		23.08	x86_64	flathub
 func ParseListUpgradableOutput(msg string, opts *manager.Options) []manager.PackageInfo {
 	var packages []manager.PackageInfo
 
-	msg = strings.TrimSuffix(msg, "\n")
-	var lines []string = strings.Split(string(msg), "\n")
-
-	for _, line := range lines {
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
 		if opts.Verbose {
-			log.Printf("%s: %s", pm, line)
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
 		}
 
-		if len(line) == 0 || strings.HasPrefix(line, "Name\t") {
+		columns := strings.Split(line, "\t")
+		if len(columns) < 5 || columns[0] == "" {
 			continue
 		}
 
-		var parts []string = strings.Split(line, "\t")
-		var name string = parts[1]
-		var arch string = parts[4]
-		var version string = parts[2]
+		version := strings.TrimSpace(columns[1])
 		if version == "" {
 			version = "unknown"
 		}
-		// var category string = parts[5]
 
-		packageInfo := manager.PackageInfo{
-			Name: name,
-			Arch: arch,
-			// Version:        version,
-			NewVersion: version,
-			// Category:       category,
+		packages = append(packages, manager.PackageInfo{
+			Name:           strings.TrimSpace(columns[0]),
+			NewVersion:     version,
+			Arch:           strings.TrimSpace(columns[3]),
 			Status:         manager.PackageStatusInstalled,
+			Origin:         strings.TrimSpace(columns[4]),
 			PackageManager: pm,
-		}
-		packages = append(packages, packageInfo)
+			AdditionalData: metadata("branch", columns[2]),
+		})
 	}
 
 	return packages
 }
 
 // ParsePackageInfoOutput parses the output of the flatpak info command and returns a PackageInfo struct.
+// Whether the package is an application or a runtime is read from its ref, such as app/org.gimp.GIMP/x86_64/stable.
+// flatpak info reports the license and the installed size of the ref, but not its homepage or maintainer.
 func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageInfo {
 	var pkg manager.PackageInfo
+	var data []string
 
 	// remove the last empty line
 	msg = strings.TrimSuffix(msg, "\n")
@@ -242,13 +240,145 @@ func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageIn
 				pkg.Version = value
 			case "Arch":
 				pkg.Arch = value
-				// case "Section":
-				// 	pkg.Category = value
+			case "License":
+				pkg.License = value
+			case "Installed":
+				pkg.SizeInstalled, _ = manager.ParseSize(value)
+			case "Ref":
+				data = append(data, "kind", strings.SplitN(value, "/", 2)[0])
+			case "Branch":
+				data = append(data, "branch", value)
+			case "Origin":
+				pkg.Origin = value
+			case "Installation":
+				data = append(data, "installation", value)
 			}
 		}
 	}
 
 	pkg.PackageManager = "flatpak"
+	pkg.AdditionalData = metadata(data...)
 
 	return pkg
 }
+
+// ParseRemotesOutput parses the output of `flatpak remotes --show-disabled --columns=name,options,url,title`
+// command and returns the remotes, which are the origins of their packages. The columns are separated by tabs, and
+// the options list the installation of the remote, whether it is disabled, and whether it is verified.
+//
+// Example output:
+// flathub	system	https://dl.flathub.org/repo/	Flathub
+// fedora	system,oci	oci+https://registry.fedoraproject.org	Fedora Flatpaks
+// gnome-nightly	user,disabled	https://nightly.gnome.org/repo/
+// local	user,no-gpg-verify	file:///srv/flatpak/repo
+func ParseRemotesOutput(msg string, opts *manager.Options) []manager.RepositoryInfo {
+	var repos []manager.RepositoryInfo
+
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+		columns := strings.Split(line, "\t")
+		if len(columns) < 3 || columns[0] == "" {
+			continue
+		}
+
+		repo := manager.RepositoryInfo{
+			Name:           strings.TrimSpace(columns[0]),
+			URL:            strings.TrimSpace(columns[2]),
+			Origin:         strings.TrimSpace(columns[0]),
+			Enabled:        true,
+			PackageManager: pm,
+		}
+		if len(columns) > 3 {
+			repo.Title = strings.TrimSpace(columns[3])
+		}
+		for _, option := range strings.Split(columns[1], ",") {
+			switch option = strings.TrimSpace(option); option {
+			case "disabled":
+				repo.Enabled = false
+			case "no-gpg-verify":
+				repo.Unsigned = true
+			case string(manager.ScopeUser), string(manager.ScopeSystem):
+				repo.Scope = manager.Scope(option)
+			}
+		}
+		repos = append(repos, repo)
+	}
+
+	return repos
+}
+
+// metadata returns the AdditionalData of a package from pairs of keys and values, skipping the empty values,
+// or nil if all of them are empty.
+func metadata(pairs ...string) map[string]string {
+	var data map[string]string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		value := strings.TrimSpace(pairs[i+1])
+		if value == "" {
+			continue
+		}
+		if data == nil {
+			data = make(map[string]string)
+		}
+		data[pairs[i]] = value
+	}
+	return data
+}
+
+// ParseRepairOutput parses the output of `flatpak repair --dry-run` command, and returns the results of the refs with
+// missing or invalid objects, reported after the ref being verified. The objects missing are reported as missing files,
+// and the invalid ones as checksum mismatches.
+//
+// Example output:
+// Verifying flathub:app/org.gimp.GIMP/x86_64/stable…
+// Object missing: 0f8e2e5b7d3c0c5b1d0f3a1e4e0c2b7a9d8f6e5c4b3a2918273645546372819a.file
+// Object invalid: 5c4b3a2918273645546372819a0f8e2e5b7d3c0c5b1d0f3a1e4e0c2b7a9d8f6e.dirtree
+// Dry run: Deleting ref flathub:app/org.gimp.GIMP/x86_64/stable due to missing objects
+// Verifying flathub:runtime/org.gnome.Platform/x86_64/45…
+func ParseRepairOutput(msg string, opts *manager.Options) []manager.VerificationResult {
+	var results []manager.VerificationResult
+	index := make(map[string]int)
+
+	// result returns the result of ref, adding it when it is not found yet
+	result := func(ref string) *manager.VerificationResult {
+		// [remote:]kind/name/arch/branch
+		if _, after, found := strings.Cut(ref, ":"); found {
+			ref = after
+		}
+		parts := strings.Split(ref, "/")
+		if len(parts) < 2 {
+			return nil
+		}
+		i, ok := index[parts[1]]
+		if !ok {
+			i = len(results)
+			index[parts[1]] = i
+			results = append(results, manager.VerificationResult{Name: parts[1], PackageManager: pm})
+		}
+		return &results[i]
+	}
+
+	var current string
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "Verifying "):
+			current = strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(line, "Verifying "), "…"), "...")
+		case strings.HasPrefix(line, "Object missing: "):
+			if r := result(current); r != nil {
+				r.MissingFiles = append(r.MissingFiles, strings.TrimPrefix(line, "Object missing: "))
+			}
+		case strings.HasPrefix(line, "Object invalid: "):
+			if r := result(current); r != nil {
+				r.ChecksumMismatches = append(r.ChecksumMismatches, strings.TrimPrefix(line, "Object invalid: "))
+			}
+		}
+	}
+
+	return results
+}
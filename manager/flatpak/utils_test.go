@@ -0,0 +1,157 @@
+package flatpak_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/flatpak"
+)
+
+func TestParseRemotesOutput(t *testing.T) {
+	var inputParseRemotesOutput string = strings.Join([]string{
+		"flathub\tsystem\thttps://dl.flathub.org/repo/\tFlathub",
+		"fedora\tsystem,oci\toci+https://registry.fedoraproject.org\tFedora Flatpaks",
+		"gnome-nightly\tuser,disabled\thttps://nightly.gnome.org/repo/\t",
+		"local\tuser,no-gpg-verify\tfile:///srv/flatpak/repo\t",
+	}, "\n")
+
+	expectedRepos := []manager.RepositoryInfo{
+		{Name: "flathub", URL: "https://dl.flathub.org/repo/", Title: "Flathub", Origin: "flathub", Enabled: true, Scope: manager.ScopeSystem, PackageManager: "flatpak"},
+		{Name: "fedora", URL: "oci+https://registry.fedoraproject.org", Title: "Fedora Flatpaks", Origin: "fedora", Enabled: true, Scope: manager.ScopeSystem, PackageManager: "flatpak"},
+		{Name: "gnome-nightly", URL: "https://nightly.gnome.org/repo/", Origin: "gnome-nightly", Enabled: false, Scope: manager.ScopeUser, PackageManager: "flatpak"},
+		{Name: "local", URL: "file:///srv/flatpak/repo", Origin: "local", Enabled: true, Unsigned: true, Scope: manager.ScopeUser, PackageManager: "flatpak"},
+	}
+
+	actualRepos := flatpak.ParseRemotesOutput(inputParseRemotesOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedRepos, actualRepos) {
+		t.Errorf("ParseRemotesOutput() = %+v, want %+v", actualRepos, expectedRepos)
+	}
+}
+
+func TestParseListInstalledOutput(t *testing.T) {
+	// flatpak list --columns=application,version,branch,arch,origin,installation,size, the same in flatpak 1.12 and
+	// 1.14, whose default columns differ
+	var inputParseListInstalledOutput string = strings.Join([]string{
+		"org.gimp.GIMP\t2.10.30\tstable\tx86_64\tflathub\tsystem\t314.1\u00a0MB",
+		"net.davidotek.pupgui2\t\tstable\tx86_64\tflathub\tuser",
+		"",
+	}, "\n")
+
+	expectedPackageInfo := []manager.PackageInfo{
+		{Name: "org.gimp.GIMP", Version: "2.10.30", Arch: "x86_64", Status: manager.PackageStatusInstalled, SizeInstalled: 314100000,
+			PackageManager: "flatpak", Origin: "flathub", AdditionalData: map[string]string{"branch": "stable", "installation": "system"}},
+		{Name: "net.davidotek.pupgui2", Arch: "x86_64", Status: manager.PackageStatusInstalled, PackageManager: "flatpak",
+			Origin: "flathub", AdditionalData: map[string]string{"branch": "stable", "installation": "user"}},
+	}
+
+	actualPackageInfo := flatpak.ParseListInstalledOutput(inputParseListInstalledOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListInstalledOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListUpgradableOutput(t *testing.T) {
+	var inputParseListUpgradableOutput string = strings.Join([]string{
+		"org.gimp.GIMP\t2.10.38\tstable\tx86_64\tflathub",
+		"org.freedesktop.Platform.GL.default\t\t23.08\tx86_64\tflathub",
+	}, "\n")
+
+	expectedPackageInfo := []manager.PackageInfo{
+		{Name: "org.gimp.GIMP", NewVersion: "2.10.38", Arch: "x86_64", Status: manager.PackageStatusInstalled, PackageManager: "flatpak",
+			Origin: "flathub", AdditionalData: map[string]string{"branch": "stable"}},
+		{Name: "org.freedesktop.Platform.GL.default", NewVersion: "unknown", Arch: "x86_64", Status: manager.PackageStatusInstalled, PackageManager: "flatpak",
+			Origin: "flathub", AdditionalData: map[string]string{"branch": "23.08"}},
+	}
+
+	actualPackageInfo := flatpak.ParseListUpgradableOutput(inputParseListUpgradableOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListUpgradableOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseFindOutput(t *testing.T) {
+	var inputParseFindOutput string = strings.Join([]string{
+		"com.freerdp.FreeRDP\t2.10.0\tstable\tflathub\tFreeRDP Remote Desktop Client\tFreeRDP (Remote Desktop Protocol) Client for Linux.",
+		"com.fightcade.Fightcade\t2.2\tstable\tflathub,flathub-beta\tFightcade\tPlay arcade games online.",
+	}, "\n")
+
+	expectedPackageInfo := []manager.PackageInfo{
+		{Name: "com.freerdp.FreeRDP", Version: "2.10.0", NewVersion: "2.10.0", Status: manager.PackageStatusAvailable, PackageManager: "flatpak",
+			Origin: "flathub", AdditionalData: map[string]string{"branch": "stable"}},
+		{Name: "com.fightcade.Fightcade", Version: "2.2", NewVersion: "2.2", Status: manager.PackageStatusAvailable, PackageManager: "flatpak",
+			Origin: "flathub,flathub-beta", AdditionalData: map[string]string{"branch": "stable"}},
+	}
+
+	actualPackageInfo := flatpak.ParseFindOutput(inputParseFindOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseFindOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+	if packages := flatpak.ParseFindOutput("No matches found\n", &manager.Options{}); len(packages) != 0 {
+		t.Errorf("ParseFindOutput() = %+v, want no packages", packages)
+	}
+}
+
+func TestParsePackageInfoOutput(t *testing.T) {
+	// flatpak info of flatpak 1.14, 1.12 doesn't print the Subject and Date of the commit
+	var inputParsePackageInfoOutput string = strings.Join([]string{
+		"",
+		"GNU Image Manipulation Program - Create images and edit photographs",
+		"",
+		"          ID: org.gimp.GIMP",
+		"         Ref: app/org.gimp.GIMP/x86_64/stable",
+		"        Arch: x86_64",
+		"      Branch: stable",
+		"     Version: 2.10.36",
+		"     License: GPL-3.0+ and LGPL-3.0+",
+		"      Origin: flathub",
+		"  Collection: org.flathub.Stable",
+		"Installation: system",
+		"   Installed: 314.1 MB",
+		"     Runtime: org.gnome.Platform/x86_64/45",
+		"         Sdk: org.gnome.Sdk/x86_64/45",
+		"",
+		"      Commit: 1f0be4fdd04f9eaa3ad7f7330bd2ea1ea7b38a1b8a3c3e4a0e9d1d27f0a1d2b3",
+		"     Subject: Update to 2.10.36",
+		"        Date: 2023-11-07 21:38:22 +0000",
+	}, "\n")
+
+	expectedPackageInfo := manager.PackageInfo{
+		Name:           "org.gimp.GIMP",
+		Version:        "2.10.36",
+		Arch:           "x86_64",
+		License:        "GPL-3.0+ and LGPL-3.0+",
+		SizeInstalled:  314100000,
+		PackageManager: "flatpak",
+		Origin:         "flathub", AdditionalData: map[string]string{"kind": "app", "branch": "stable", "installation": "system"},
+	}
+
+	actualPackageInfo := flatpak.ParsePackageInfoOutput(inputParsePackageInfoOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParsePackageInfoOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseRepairOutput(t *testing.T) {
+	var inputParseRepairOutput string = strings.Join([]string{
+		"Verifying flathub:app/org.gimp.GIMP/x86_64/stable…",
+		"Object missing: 0f8e2e5b7d3c0c5b1d0f3a1e4e0c2b7a9d8f6e5c4b3a2918273645546372819a.file",
+		"Object invalid: 5c4b3a2918273645546372819a0f8e2e5b7d3c0c5b1d0f3a1e4e0c2b7a9d8f6e.dirtree",
+		"Dry run: Deleting ref flathub:app/org.gimp.GIMP/x86_64/stable due to missing objects",
+		"Verifying flathub:runtime/org.gnome.Platform/x86_64/45…",
+		"Checking remotes...",
+	}, "\n")
+
+	expectedResults := []manager.VerificationResult{{
+		Name:               "org.gimp.GIMP",
+		PackageManager:     "flatpak",
+		MissingFiles:       []string{"0f8e2e5b7d3c0c5b1d0f3a1e4e0c2b7a9d8f6e5c4b3a2918273645546372819a.file"},
+		ChecksumMismatches: []string{"5c4b3a2918273645546372819a0f8e2e5b7d3c0c5b1d0f3a1e4e0c2b7a9d8f6e.dirtree"},
+	}}
+
+	actualResults := flatpak.ParseRepairOutput(inputParseRepairOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedResults, actualResults) {
+		t.Errorf("ParseRepairOutput() = %+v, want %+v", actualResults, expectedResults)
+	}
+}
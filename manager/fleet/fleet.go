@@ -0,0 +1,185 @@
+// Package fleet runs syspkg on remote hosts over SSH, several at a time, so that a single command upgrades or
+// audits a fleet of machines, each host running its own syspkg with its own configuration.
+//
+// The hosts are reached with the ssh command, in batch mode, so they must accept the key of the user, and their
+// configuration in ~/.ssh/config applies. Changing packages needs root on the hosts, or passwordless sudo.
+//
+// This package is part of the syspkg library.
+package fleet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// DefaultParallel is the number of hosts commands run on at the same time when the runner has no limit.
+const DefaultParallel = 10
+
+// exitSSHFailed is the exit status of ssh when it fails, such as when the host can't be reached.
+const exitSSHFailed = 255
+
+// DefaultSSH is the ssh command line the hosts are reached with: never prompting for passwords or host keys, which
+// would hang with several hosts at a time, and giving up on unreachable hosts after 10 seconds.
+var DefaultSSH = []string{"ssh", "-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+
+// ReadHosts reads the hosts listed in the file at path, as parsed by ParseHosts.
+func ReadHosts(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hosts, err := ParseHosts(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return hosts, nil
+}
+
+// ParseHosts parses a list of hosts, one per line, as given to ssh: a host name or address, user@host, or
+// ssh://user@host:port. Comments start with #, and duplicates are ignored.
+func ParseHosts(r io.Reader) ([]string, error) {
+	var hosts []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) > 1:
+			return nil, fmt.Errorf("line %d: expected a single host, got %q", n, strings.TrimSpace(line))
+		case strings.HasPrefix(fields[0], "-"):
+			return nil, fmt.Errorf("line %d: invalid host %q", n, fields[0])
+		}
+		if !seen[fields[0]] {
+			seen[fields[0]] = true
+			hosts = append(hosts, fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("no hosts listed")
+	}
+	return hosts, nil
+}
+
+// Result is the result of running syspkg on a host.
+type Result struct {
+	// Host is the host, as listed.
+	Host string
+
+	// Stdout is the output of syspkg.
+	Stdout []byte
+
+	// Stderr is the error output of syspkg, or of ssh.
+	Stderr []byte
+
+	// ExitStatus is the exit status of syspkg, or -1 if it didn't run.
+	ExitStatus int
+
+	// Err is set when syspkg couldn't run on the host, such as when the host is unreachable.
+	Err error
+
+	// Duration is how long the command took, connection included.
+	Duration time.Duration
+}
+
+// Runner runs syspkg on remote hosts.
+type Runner struct {
+	// SSH is the ssh command line; DefaultSSH if empty. The host and the remote command are appended to it.
+	SSH []string
+
+	// Syspkg is the syspkg command on the hosts; "syspkg" if empty, found in the PATH of the remote user.
+	Syspkg string
+
+	// Parallel is the number of hosts the command runs on at the same time; DefaultParallel if zero.
+	Parallel int
+}
+
+// Run runs syspkg with args on each host, and returns the results in the order of hosts. The commands are
+// interrupted, closing their connection, when ctx is done.
+func (r *Runner) Run(ctx context.Context, hosts []string, args []string) []Result {
+	parallel := r.Parallel
+	if parallel <= 0 {
+		parallel = DefaultParallel
+	}
+	results := make([]Result, len(hosts))
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i] = r.run(ctx, host, args)
+		}(i, host)
+	}
+	wg.Wait()
+	return results
+}
+
+// run runs syspkg with args on host.
+func (r *Runner) run(ctx context.Context, host string, args []string) Result {
+	ssh := r.SSH
+	if len(ssh) == 0 {
+		ssh = DefaultSSH
+	}
+	syspkg := r.Syspkg
+	if syspkg == "" {
+		syspkg = "syspkg"
+	}
+
+	// ssh passes the command to the shell of the remote user, as a single string
+	words := []string{syspkg}
+	for _, arg := range args {
+		words = append(words, manager.QuoteShell(arg))
+	}
+	command := append(append(append([]string{}, ssh[1:]...), host), strings.Join(words, " "))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ssh[0], command...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	start := time.Now()
+	err := cmd.Run()
+	result := Result{Host: host, Stdout: stdout.Bytes(), Stderr: stderr.Bytes(), ExitStatus: cmd.ProcessState.ExitCode(), Duration: time.Since(start)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		result.Err = ctx.Err()
+	case errors.As(err, &exitErr) && result.ExitStatus == exitSSHFailed:
+		result.Err = fmt.Errorf("ssh failed: %s", LastLine(stderr.Bytes(), "exit status 255"))
+	case err != nil && !errors.As(err, &exitErr):
+		result.Err = err
+	}
+	if result.Err != nil {
+		result.ExitStatus = -1
+	}
+	return result
+}
+
+// LastLine returns the last non-empty line of output, where commands write the error they fail with, or fallback if
+// there is none.
+func LastLine(output []byte, fallback string) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+		return line
+	}
+	return fallback
+}
@@ -0,0 +1,61 @@
+package fleet_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager/fleet"
+)
+
+func TestParseHosts(t *testing.T) {
+	input := `# web servers
+web1
+admin@web2.example.com   # the old one
+web1
+
+ssh://admin@10.0.0.3:2222
+`
+	actual, err := fleet.ParseHosts(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHosts() error = %+v", err)
+	}
+	expected := []string{"web1", "admin@web2.example.com", "ssh://admin@10.0.0.3:2222"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ParseHosts() = %+v, want %+v", actual, expected)
+	}
+
+	for _, input := range []string{"", "# no hosts\n", "web1 web2\n", "-oProxyCommand=evil\n"} {
+		if _, err := fleet.ParseHosts(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseHosts(%q) error = nil, want an error", input)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	// a fake ssh running the remote command locally, failing to connect to the host named down
+	runner := &fleet.Runner{
+		SSH: []string{"sh", "-c", `if [ "$1" = down ]; then echo "ssh: connect to host down port 22: Connection refused" >&2; exit 255; fi; eval "$2"`, "ssh"},
+		// the remote command is "echo", printing the arguments it is given
+		Syspkg:   "echo",
+		Parallel: 2,
+	}
+	results := runner.Run(context.Background(), []string{"web1", "down", "web2"}, []string{"--output", "json", "install", "it's"})
+
+	if len(results) != 3 {
+		t.Fatalf("Run() returned %d results, want 3", len(results))
+	}
+	for _, i := range []int{0, 2} {
+		if results[i].Err != nil || results[i].ExitStatus != 0 {
+			t.Errorf("Run() on %s = %+v, want success", results[i].Host, results[i])
+		}
+		if expected := "--output json install it's\n"; string(results[i].Stdout) != expected {
+			t.Errorf("Run() on %s printed %q, want %q", results[i].Host, results[i].Stdout, expected)
+		}
+	}
+	if results[1].Host != "down" || results[1].Err == nil || results[1].ExitStatus != -1 ||
+		results[1].Err.Error() != "ssh failed: ssh: connect to host down port 22: Connection refused" {
+		t.Errorf("Run() on down = %+v, want the error of ssh", results[1])
+	}
+}
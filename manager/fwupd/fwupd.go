@@ -0,0 +1,251 @@
+// Package fwupd provides an implementation of the syspkg manager interface for the fwupd firmware update daemon.
+// It provides a Go (golang) API interface for listing and updating device firmware.
+// This package is a wrapper around the fwupdmgr command line tool.
+//
+// fwupd updates the firmware of devices (system firmware, SSDs, docks, peripherals, ...) on Linux,
+// using firmware published by vendors on the Linux Vendor Firmware Service (LVFS).
+// This package maps firmware onto packages: each device is a package named after the device,
+// whose version is the firmware version, and which is upgradable when a newer firmware release is available.
+// Devices are identified by their name or, more precisely, by their device ID (AdditionalData["device_id"]).
+// Firmware can't be removed, and there is no catalog to search, so Delete and Find are not supported.
+//
+// For more information about fwupd, visit:
+// - https://fwupd.org/
+// - https://github.com/fwupd/fwupd
+//
+// This package is part of the syspkg library.
+package fwupd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "fwupd"
+
+// fwupdmgr is the command line client of fwupd.
+var fwupdmgr string = "fwupdmgr"
+
+// Constants used for fwupdmgr commands
+const (
+	ArgsJSON          string = "--json"
+	ArgsAssumeYes     string = "--assume-yes"
+	ArgsNoRebootCheck string = "--no-reboot-check"
+	ArgsNoUnreported  string = "--no-unreported-check"
+	ArgsNoMetadata    string = "--no-metadata-check"
+	ArgsForce         string = "--force"
+)
+
+// Exit codes returned by fwupdmgr.
+const (
+	ExitOK          int = 0
+	ExitFailure     int = 1
+	ExitNothingToDo int = 2
+	ExitNotFound    int = 3
+)
+
+// ErrDeviceNotFound is returned when the requested device is not known to fwupd.
+var ErrDeviceNotFound = errors.New("fwupd: device not found")
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for fwupdmgr.
+var ENV_NonInteractive []string = []string{"LC_ALL=C", "NO_COLOR=1"}
+
+// PackageManager implements the manager.PackageManager interface for the fwupd firmware update daemon.
+type PackageManager struct{}
+
+// IsAvailable checks if fwupdmgr is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(fwupdmgr)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the fwupd package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by fwupd.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		DryRun:         true,
+		ListUpgradable: true,
+		Upgrade:        true,
+	}
+}
+
+// Install installs the provided firmware archives (.cab files) using fwupdmgr install.
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	var packages []manager.PackageInfo
+	for _, pkg := range pkgs {
+		if !opts.DryRun {
+			if err := a.run([]string{"install", pkg}, opts); err != nil {
+				return nil, err
+			}
+		}
+		packages = append(packages, manager.PackageInfo{
+			Name:           pkg,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	if opts.Interactive {
+		return nil, nil
+	}
+	return packages, nil
+}
+
+// Delete is not supported, as firmware can't be removed from a device.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+
+// Refresh downloads the latest firmware metadata from the configured remotes (such as LVFS) using fwupdmgr refresh.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+	return a.run([]string{"refresh"}, opts)
+}
+
+// Find is not supported, as fwupd has no catalog of firmware to search; use ListInstalled to list the devices.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+
+// ListInstalled lists all devices known to fwupd with their current firmware version.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	out, err := a.query("get-devices", opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseDevicesOutput(out, opts)
+}
+
+// ListUpgradable lists all devices with a newer firmware release available.
+// The result is based on the local copy of the firmware metadata, so Refresh should be called first for up-to-date results.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	out, err := a.query("get-updates", opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseDevicesOutput(out, opts)
+}
+
+// Upgrade updates the firmware of the provided devices, given by name or device ID, using fwupdmgr update.
+// If no devices are given, all devices with an update are updated.
+// Many firmware updates are only applied on the next reboot, which is not performed by this method.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	upgradable, err := a.ListUpgradable(opts)
+	if err != nil {
+		return nil, err
+	}
+	upgradable = filterPackages(upgradable, pkgs)
+	if opts.DryRun || len(upgradable) == 0 {
+		return upgradable, nil
+	}
+
+	var commands [][]string
+	if len(pkgs) == 0 {
+		commands = append(commands, []string{"update"})
+	}
+	for _, pkg := range upgradable {
+		if len(pkgs) > 0 {
+			commands = append(commands, []string{"update", pkg.AdditionalData["device_id"]})
+		}
+	}
+
+	for _, args := range commands {
+		opts.Log().Info("Running command", "package_manager", pm, "command", fwupdmgr, "args", args)
+
+		if err := a.run(args, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Interactive {
+		return nil, nil
+	}
+	return upgradable, nil
+}
+
+// UpgradeAll updates the firmware of all devices with an update using fwupdmgr.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// GetPackageInfo retrieves the firmware information of the specified device, given by name or device ID,
+// including the newest available release, if any.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+
+	found := filterPackages(installed, []string{pkg})
+	if len(found) == 0 {
+		return manager.PackageInfo{}, ErrDeviceNotFound
+	}
+	return found[0], nil
+}
+
+// query runs a fwupdmgr command with JSON output. It returns no output and no error when fwupdmgr has nothing to report.
+func (a *PackageManager) query(command string, opts *manager.Options) ([]byte, error) {
+	cmd := manager.Command(opts, fwupdmgr, command, ArgsJSON, ArgsNoUnreported, ArgsNoMetadata)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == ExitNothingToDo {
+		return nil, nil
+	}
+	return out, err
+}
+
+// run runs fwupdmgr with the given arguments, either attached to the terminal in interactive mode,
+// or non-interactively, logging the output in verbose mode. "Nothing to do" is not treated as an error.
+func (a *PackageManager) run(args []string, opts *manager.Options) error {
+	var err error
+	if opts.Interactive {
+		cmd := manager.Command(opts, fwupdmgr, args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		err = manager.Run(cmd)
+	} else {
+		cmd := manager.Command(opts, fwupdmgr, append(args, ArgsAssumeYes, ArgsNoRebootCheck, ArgsNoUnreported)...)
+		manager.SetEnv(cmd, opts, ENV_NonInteractive)
+		var out []byte
+		out, err = manager.CombinedOutput(cmd)
+		if opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+		}
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == ExitNothingToDo {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+// Package fwupd provides a package manager implementation for device firmware
+// using fwupd as the underlying firmware management tool.
+package fwupd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ParseDevicesOutput parses the output of `fwupdmgr get-devices --json` and `fwupdmgr get-updates --json` commands,
+// and returns a list of devices with their firmware version. Devices with a release newer than the current firmware
+// are marked as upgradable, with the newest release in NewVersion; devices without a firmware version are skipped.
+// The device ID, vendor and plugin are kept in AdditionalData.
+// Example msg:
+//
+//	{
+//	  "Devices": [
+//	    {
+//	      "Name": "Thunderbolt Controller", "DeviceId": "2ea7a6c5d7e2a1b7f0c1a7b1e5a3c6d9b4f8e2a1", "Plugin": "thunderbolt",
+//	      "Vendor": "Lenovo", "Version": "20.00", "Flags": ["updatable", "internal"],
+//	      "Releases": [{"Version": "21.00", "Summary": "Thunderbolt controller firmware", "Urgency": "high"}]
+//	    }
+//	  ]
+//	}
+func ParseDevicesOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var result struct {
+		Devices []struct {
+			Name     string   `json:"Name"`
+			DeviceID string   `json:"DeviceId"`
+			Plugin   string   `json:"Plugin"`
+			Vendor   string   `json:"Vendor"`
+			Version  string   `json:"Version"`
+			Flags    []string `json:"Flags"`
+			Releases []struct {
+				Version string `json:"Version"`
+				Summary string `json:"Summary"`
+				Urgency string `json:"Urgency"`
+			} `json:"Releases"`
+		} `json:"Devices"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &result); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	for _, device := range result.Devices {
+		if device.Version == "" {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           device.Name,
+			Version:        device.Version,
+			Status:         manager.PackageStatusInstalled,
+			Category:       device.Plugin,
+			PackageManager: pm,
+			AdditionalData: map[string]string{
+				"device_id": device.DeviceID,
+				"vendor":    device.Vendor,
+				"flags":     strings.Join(device.Flags, ","),
+			},
+		}
+
+		// releases are listed from the newest to the oldest
+		for _, release := range device.Releases {
+			if release.Version == device.Version {
+				break
+			}
+			packageInfo.NewVersion = release.Version
+			packageInfo.Status = manager.PackageStatusUpgradable
+			if release.Urgency != "" {
+				packageInfo.AdditionalData["urgency"] = release.Urgency
+			}
+			if release.Summary != "" {
+				packageInfo.AdditionalData["summary"] = release.Summary
+			}
+			break
+		}
+
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// filterPackages returns the devices whose name or device ID is one of the given names, or all devices if no names are given.
+func filterPackages(packages []manager.PackageInfo, names []string) []manager.PackageInfo {
+	if len(names) == 0 {
+		return packages
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var filtered []manager.PackageInfo
+	for _, pkg := range packages {
+		if wanted[pkg.Name] || wanted[pkg.AdditionalData["device_id"]] {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
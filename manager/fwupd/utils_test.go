@@ -0,0 +1,76 @@
+package fwupd_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/fwupd"
+)
+
+func TestParseDevicesOutput(t *testing.T) {
+	var inputParseDevicesOutput string = `{
+  "Devices": [
+    {
+      "Name": "Thunderbolt Controller",
+      "DeviceId": "2ea7a6c5d7e2a1b7f0c1a7b1e5a3c6d9b4f8e2a1",
+      "Plugin": "thunderbolt",
+      "Vendor": "Lenovo",
+      "Version": "20.00",
+      "Flags": ["updatable", "internal"],
+      "Releases": [{"Version": "21.00", "Summary": "Thunderbolt controller firmware", "Urgency": "high"}]
+    },
+    {
+      "Name": "Samsung SSD 980 PRO",
+      "DeviceId": "71b677ca0f1bc2c5b804fa1d59e52064ce589293",
+      "Plugin": "nvme",
+      "Vendor": "Samsung",
+      "Version": "5B2QGXA7",
+      "Flags": ["internal"]
+    },
+    {
+      "Name": "USB Hub",
+      "DeviceId": "5b4f0b8c2d6a8e3f1a9c7b2d4e6f8a0b1c3d5e7f"
+    }
+  ]
+}`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "Thunderbolt Controller",
+			Version:        "20.00",
+			NewVersion:     "21.00",
+			Status:         manager.PackageStatusUpgradable,
+			Category:       "thunderbolt",
+			PackageManager: "fwupd",
+			AdditionalData: map[string]string{
+				"device_id": "2ea7a6c5d7e2a1b7f0c1a7b1e5a3c6d9b4f8e2a1",
+				"vendor":    "Lenovo",
+				"flags":     "updatable,internal",
+				"urgency":   "high",
+				"summary":   "Thunderbolt controller firmware",
+			},
+		},
+		{
+			Name:           "Samsung SSD 980 PRO",
+			Version:        "5B2QGXA7",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "nvme",
+			PackageManager: "fwupd",
+			AdditionalData: map[string]string{
+				"device_id": "71b677ca0f1bc2c5b804fa1d59e52064ce589293",
+				"vendor":    "Samsung",
+				"flags":     "internal",
+			},
+		},
+	}
+
+	actualPackageInfo, err := fwupd.ParseDevicesOutput([]byte(inputParseDevicesOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseDevicesOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDevicesOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
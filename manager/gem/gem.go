@@ -0,0 +1,343 @@
+// Package gem provides an implementation of the syspkg manager interface for the RubyGems package manager.
+// It provides a Go (golang) API interface for interacting with RubyGems, the package manager of Ruby.
+// This package is a wrapper around the gem command line tool.
+//
+// RubyGems installs gems (Ruby libraries and the command line tools they provide) from rubygems.org.
+// Gems are installed either system-wide, into the installation directory of Ruby (which usually requires root),
+// or into the home directory of the user with --user-install. This package detects which one applies with InstallScope,
+// and installs gems for the user when the system installation directory is not writable.
+//
+// For more information about RubyGems, visit:
+// - https://guides.rubygems.org/command-reference/
+// - https://guides.rubygems.org/faqs/#user-install
+//
+// This package is part of the syspkg library.
+package gem
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "gem"
+
+// Constants used for gem commands
+const (
+	ArgsNoDocument  string = "--no-document"
+	ArgsUserInstall string = "--user-install"
+	ArgsLocal       string = "--local"
+	ArgsRemote      string = "--remote"
+	ArgsExact       string = "--exact"
+	ArgsAllVersions string = "--all"
+	ArgsExecutables string = "--executables"
+)
+
+// Install scopes returned by InstallScope.
+const (
+	ScopeSystem string = "system"
+	ScopeUser   string = "user"
+)
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for gem.
+var ENV_NonInteractive []string = []string{"LC_ALL=C"}
+
+// PackageManager implements the manager.PackageManager interface for the RubyGems package manager.
+type PackageManager struct{}
+
+// IsAvailable checks if the gem package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(pm)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the gem package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by gem.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:           true,
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+	}
+}
+
+// InstallScope reports where gems are installed: ScopeSystem if the installation directory of Ruby is writable
+// (e.g. when running as root, or with a Ruby installed in the home directory by rbenv or RVM), ScopeUser otherwise,
+// in which case gems are installed with --user-install. The installation directory is returned as well.
+func (a *PackageManager) InstallScope() (string, string, error) {
+	cmd := exec.Command(pm, "environment", "gemdir")
+	manager.SetEnv(cmd, nil, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return "", "", err
+	}
+
+	dir := strings.TrimSpace(string(out))
+	if isWritable(dir) {
+		return ScopeSystem, dir, nil
+	}
+
+	cmd = exec.Command(pm, "environment", "user_gemhome")
+	manager.SetEnv(cmd, nil, ENV_NonInteractive)
+	out, err = manager.Output(cmd)
+	if err != nil {
+		return "", "", err
+	}
+	return ScopeUser, strings.TrimSpace(string(out)), nil
+}
+
+// Install installs the provided gems using gem, for the user if the system installation directory is not writable.
+// Gems can be given with a version, e.g. "rake:13.0.6".
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// gem installs a specific version with name:version
+	pkgs, err := manager.TranslatePackageSpecs(pkgs, func(spec manager.PackageSpec) string { return spec.Name + ":" + spec.Version })
+	if err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// gem install has no dry-run mode, so only report what was requested
+	if opts.DryRun {
+		var packages []manager.PackageInfo
+		for _, pkg := range pkgs {
+			name, version, _ := strings.Cut(pkg, ":")
+			packages = append(packages, manager.PackageInfo{
+				Name:           name,
+				Version:        version,
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+			})
+		}
+		return packages, nil
+	}
+
+	args, err := a.installArgs("install", pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseInstallOutput(string(out), opts), nil
+}
+
+// Delete uninstalls all versions of the provided gems, along with their executables, using gem.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// gem uninstall has no dry-run mode, so only report what is installed
+	if opts.DryRun {
+		installed, err := a.ListInstalled(opts)
+		if err != nil {
+			return nil, err
+		}
+		var packages []manager.PackageInfo
+		for _, pkg := range filterPackages(installed, pkgs) {
+			pkg.Status = manager.PackageStatusAvailable
+			packages = append(packages, pkg)
+		}
+		return packages, nil
+	}
+
+	args := append([]string{"uninstall", ArgsAllVersions, ArgsExecutables}, pkgs...)
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseDeletedOutput(string(out), opts), nil
+}
+
+// Refresh does nothing for gem, as gem always queries rubygems.org directly.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	return nil
+}
+
+// Find searches rubygems.org for gems whose name matches the provided keywords using gem.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	for _, keyword := range keywords {
+		cmd := manager.Command(opts, pm, "search", ArgsRemote, keyword)
+		manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+		out, err := manager.Output(cmd)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, ParseListOutput(string(out), manager.PackageStatusAvailable, opts)...)
+	}
+	return packages, nil
+}
+
+// ListInstalled lists all installed gems, of the system and of the user, using gem.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "list", ArgsLocal)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListOutput(string(out), manager.PackageStatusInstalled, opts), nil
+}
+
+// ListUpgradable lists all installed gems that have a newer version on rubygems.org using gem outdated.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "outdated")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOutdatedOutput(string(out), opts), nil
+}
+
+// Upgrade upgrades the provided gems using gem update.
+// If no gems are given, all outdated gems are upgraded.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	outdated, err := a.ListUpgradable(opts)
+	if err != nil {
+		return nil, err
+	}
+	outdated = filterPackages(outdated, pkgs)
+	if opts.DryRun || len(outdated) == 0 {
+		return outdated, nil
+	}
+
+	var names []string
+	for _, pkg := range outdated {
+		names = append(names, pkg.Name)
+	}
+	args, err := a.installArgs("update", names)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+
+	oldVersions := make(map[string]string)
+	for _, pkg := range outdated {
+		oldVersions[pkg.Name] = pkg.Version
+	}
+	upgraded := ParseInstallOutput(string(out), opts)
+	for i := range upgraded {
+		upgraded[i].NewVersion = upgraded[i].Version
+		upgraded[i].Version = oldVersions[upgraded[i].Name]
+	}
+	return upgraded, nil
+}
+
+// UpgradeAll upgrades all outdated gems using gem.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// GetPackageInfo retrieves information about the specified gem using gem info,
+// along with the latest version on rubygems.org.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "info", ArgsLocal, ArgsExact, pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	info := ParsePackageInfoOutput(string(out), opts)
+
+	cmd = manager.Command(opts, pm, "search", ArgsRemote, ArgsExact, pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err = manager.Output(cmd)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	remote := ParseListOutput(string(out), manager.PackageStatusAvailable, opts)
+
+	switch {
+	case info.Name == "" && len(remote) > 0:
+		return remote[0], nil
+	case info.Name == "":
+		return manager.PackageInfo{Name: pkg, Status: manager.PackageStatusUnknown, PackageManager: pm}, nil
+	case len(remote) > 0 && remote[0].NewVersion != info.Version:
+		info.NewVersion = remote[0].NewVersion
+		info.Status = manager.PackageStatusUpgradable
+	}
+	return info, nil
+}
+
+// installArgs returns the arguments of a gem command installing gems, with --user-install when
+// the system installation directory is not writable.
+func (a *PackageManager) installArgs(command string, pkgs []string) ([]string, error) {
+	args := []string{command, ArgsNoDocument}
+
+	scope, _, err := a.InstallScope()
+	if err != nil {
+		return nil, err
+	}
+	if scope == ScopeUser {
+		args = append(args, ArgsUserInstall)
+	}
+	return append(args, pkgs...), nil
+}
+
+// run runs gem with the given arguments, either attached to the terminal in interactive mode,
+// in which case no output is returned, or non-interactively, returning the standard output.
+func (a *PackageManager) run(args []string, opts *manager.Options) ([]byte, error) {
+	cmd := manager.Command(opts, pm, args...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return nil, manager.Run(cmd)
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	return manager.Output(cmd)
+}
+
+// isWritable reports whether the current user can create files in the given directory.
+func isWritable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".syspkg-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
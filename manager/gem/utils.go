@@ -0,0 +1,210 @@
+// Package gem provides a package manager implementation for Ruby gems
+// using gem as the underlying package management tool.
+package gem
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// gemPattern matches the gem lines of `gem list` and `gem search`, e.g. "bundler (2.4.10, default: 2.4.1)",
+// capturing the name and the list of versions.
+var gemPattern = regexp.MustCompile(`^(\S+) \((.+)\)$`)
+
+// outdatedPattern matches the lines of `gem outdated`, e.g. "rake (13.0.6 < 13.1.0)".
+var outdatedPattern = regexp.MustCompile(`^(\S+) \((\S+) < (\S+)\)$`)
+
+// ParseListOutput parses the output of `gem list` and `gem search` commands
+// and returns a list of gems with the given status, with the newest of their versions.
+// Installed gems get their version in Version, and available gems in NewVersion.
+// Example msg:
+//
+//	*** LOCAL GEMS ***
+//
+//	bundler (2.4.10, default: 2.4.1)
+//	nokogiri (1.15.4 x86_64-linux)
+//	rake (13.0.6)
+func ParseListOutput(msg string, status manager.PackageStatus, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		match := gemPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		// versions are listed from the newest to the oldest; "default:" marks the version bundled with Ruby
+		newest := strings.TrimSpace(strings.Split(match[2], ",")[0])
+		newest = strings.TrimPrefix(newest, "default: ")
+		version, platform, _ := strings.Cut(newest, " ")
+
+		packageInfo := manager.PackageInfo{
+			Name:           match[1],
+			Status:         status,
+			PackageManager: pm,
+		}
+		if status == manager.PackageStatusAvailable {
+			packageInfo.NewVersion = version
+		} else {
+			packageInfo.Version = version
+		}
+		if platform != "" {
+			packageInfo.Arch = platform
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseOutdatedOutput parses the output of `gem outdated` command and returns a list of upgradable gems.
+// Example msg:
+//
+//	rake (13.0.6 < 13.1.0)
+//	rdoc (6.5.0 < 6.5.1)
+func ParseOutdatedOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		match := outdatedPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           match[1],
+			Version:        match[2],
+			NewVersion:     match[3],
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseInstallOutput parses the output of `gem install` and `gem update` commands and returns a list of installed gems.
+// Example msg:
+//
+//	Fetching rake-13.1.0.gem
+//	Successfully installed rake-13.1.0
+//	1 gem installed
+func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	return parseSuccessLines(msg, "Successfully installed ", manager.PackageStatusInstalled, opts)
+}
+
+// ParseDeletedOutput parses the output of `gem uninstall` command and returns a list of removed gems.
+// Example msg:
+//
+//	Removing rake
+//	Successfully uninstalled rake-13.0.6
+func ParseDeletedOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	return parseSuccessLines(msg, "Successfully uninstalled ", manager.PackageStatusAvailable, opts)
+}
+
+// ParsePackageInfoOutput parses the output of `gem info --local --exact gemName` command
+// and returns a manager.PackageInfo object containing the information of the installed gem.
+// The authors are the maintainer of the gem, and its installation directory is kept in AdditionalData.
+// Example msg:
+//
+//	*** LOCAL GEMS ***
+//
+//	rake (13.0.6)
+//	    Author: Hiroshi SHIBATA, Eric Hodel, Jim Weirich
+//	    Homepage: https://github.com/ruby/rake
+//	    License: MIT
+//	    Installed at (default): /usr/lib/ruby/gems/3.1.0
+//
+//	    Rake is a Make-like program implemented in Ruby
+func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageInfo {
+	var pkg manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+
+		if pkg.Name == "" {
+			if installed := ParseListOutput(line, manager.PackageStatusInstalled, opts); len(installed) > 0 {
+				pkg = installed[0]
+				pkg.AdditionalData = map[string]string{}
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ": ")
+		if !found {
+			continue
+		}
+		switch {
+		case key == "Homepage":
+			pkg.Homepage = value
+		case key == "License", key == "Licenses":
+			pkg.License = value
+		case key == "Author", key == "Authors":
+			pkg.Maintainer = value
+		case strings.HasPrefix(key, "Installed at"):
+			pkg.AdditionalData["installed_at"] = value
+		}
+	}
+
+	return pkg
+}
+
+// parseSuccessLines returns the gems reported by the lines starting with the given prefix, e.g. "Successfully installed rake-13.1.0".
+func parseSuccessLines(msg string, prefix string, status manager.PackageStatus, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, prefix) {
+			if opts != nil && opts.Verbose && line != "" {
+				opts.Log().Debug("Output", "package_manager", pm, "line", line)
+			}
+			continue
+		}
+
+		name, version := splitNameVersion(strings.TrimPrefix(line, prefix))
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Version:        version,
+			Status:         status,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// splitNameVersion splits a "name-version" gem string, e.g. "net-http-0.4.1"; gem versions never contain a hyphen,
+// except before a platform (e.g. "nokogiri-1.15.4-x86_64-linux"), which is removed.
+func splitNameVersion(s string) (string, string) {
+	fields := strings.Split(s, "-")
+	for i := len(fields) - 1; i > 0; i-- {
+		if fields[i] != "" && fields[i][0] >= '0' && fields[i][0] <= '9' {
+			return strings.Join(fields[:i], "-"), fields[i]
+		}
+	}
+	return s, ""
+}
+
+// filterPackages returns the gems whose name is one of the given names, or all gems if no names are given.
+func filterPackages(packages []manager.PackageInfo, names []string) []manager.PackageInfo {
+	if len(names) == 0 {
+		return packages
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		name, _, _ = strings.Cut(name, ":")
+		wanted[name] = true
+	}
+
+	var filtered []manager.PackageInfo
+	for _, pkg := range packages {
+		if wanted[pkg.Name] {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,141 @@
+package gem_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/gem"
+)
+
+func TestParseListOutput(t *testing.T) {
+	var inputParseListOutput string = strings.Join([]string{
+		`*** LOCAL GEMS ***`,
+		``,
+		`bundler (2.4.10, default: 2.4.1)`,
+		`json (default: 2.6.3)`,
+		`nokogiri (1.15.4 x86_64-linux)`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "bundler",
+			Version:        "2.4.10",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "gem",
+		},
+		{
+			Name:           "json",
+			Version:        "2.6.3",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "gem",
+		},
+		{
+			Name:           "nokogiri",
+			Version:        "1.15.4",
+			Status:         manager.PackageStatusInstalled,
+			Arch:           "x86_64-linux",
+			PackageManager: "gem",
+		},
+	}
+
+	actualPackageInfo := gem.ParseListOutput(inputParseListOutput, manager.PackageStatusInstalled, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseOutdatedOutput(t *testing.T) {
+	var inputParseOutdatedOutput string = strings.Join([]string{
+		`rake (13.0.6 < 13.1.0)`,
+		`rdoc (6.5.0 < 6.5.1)`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "rake",
+			Version:        "13.0.6",
+			NewVersion:     "13.1.0",
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: "gem",
+		},
+		{
+			Name:           "rdoc",
+			Version:        "6.5.0",
+			NewVersion:     "6.5.1",
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: "gem",
+		},
+	}
+
+	actualPackageInfo := gem.ParseOutdatedOutput(inputParseOutdatedOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseOutdatedOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseInstallOutput(t *testing.T) {
+	var inputParseInstallOutput string = strings.Join([]string{
+		`Fetching net-http-0.4.1.gem`,
+		`Successfully installed net-http-0.4.1`,
+		`Successfully installed nokogiri-1.15.4-x86_64-linux`,
+		`2 gems installed`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "net-http",
+			Version:        "0.4.1",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "gem",
+		},
+		{
+			Name:           "nokogiri",
+			Version:        "1.15.4",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "gem",
+		},
+	}
+
+	actualPackageInfo := gem.ParseInstallOutput(inputParseInstallOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseInstallOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParsePackageInfoOutput(t *testing.T) {
+	var inputParsePackageInfoOutput string = strings.Join([]string{
+		`*** LOCAL GEMS ***`,
+		``,
+		`rake (13.0.6)`,
+		`    Author: Hiroshi SHIBATA, Eric Hodel, Jim Weirich`,
+		`    Homepage: https://github.com/ruby/rake`,
+		`    License: MIT`,
+		`    Installed at (default): /usr/lib/ruby/gems/3.1.0`,
+		``,
+		`    Rake is a Make-like program implemented in Ruby`,
+	}, "\n")
+
+	var expectedPackageInfo = manager.PackageInfo{
+		Name:           "rake",
+		Version:        "13.0.6",
+		Status:         manager.PackageStatusInstalled,
+		License:        "MIT",
+		Homepage:       "https://github.com/ruby/rake",
+		Maintainer:     "Hiroshi SHIBATA, Eric Hodel, Jim Weirich",
+		PackageManager: "gem",
+		AdditionalData: map[string]string{
+			"installed_at": "/usr/lib/ruby/gems/3.1.0",
+		},
+	}
+
+	actualPackageInfo := gem.ParsePackageInfoOutput(inputParsePackageInfoOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParsePackageInfoOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
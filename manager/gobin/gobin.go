@@ -0,0 +1,332 @@
+// Package gobin provides an implementation of the syspkg manager interface for Go binaries installed with go install.
+// It provides a Go (golang) API interface for managing the programs installed by the go command.
+// This package is a wrapper around the go command line tool.
+//
+// `go install example.com/cmd@version` builds a Go program and installs its binary into $GOBIN, or $GOPATH/bin if GOBIN is not set.
+// Go records the module path and version a binary was built from in the binary itself, and `go version -m` prints this information.
+// This package uses it to inventory the installed binaries, and reinstalls them with @latest to upgrade them.
+// There is no central index of Go programs, so searching for packages is not supported.
+//
+// For more information about go install, visit:
+// - https://go.dev/ref/mod#go-install
+// - https://pkg.go.dev/cmd/go#hdr-Print_Go_version
+//
+// This package is part of the syspkg library.
+package gobin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "gobin"
+
+// gocmd is the go command line tool.
+var gocmd string = "go"
+
+// Constants used for go commands
+const (
+	ArgsModules string = "-m"
+	ArgsLatest  string = "@latest"
+)
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for go.
+// GOFLAGS is cleared so that flags set by the user (such as -mod=vendor) don't interfere with installing programs.
+var ENV_NonInteractive []string = []string{"LC_ALL=C", "GOFLAGS=", "GO111MODULE=on"}
+
+// EnvMirror is the environment variable setting the mirror of Options.Mirrors: the Go module proxy, such as https://goproxy.example.com.
+var EnvMirror string = "GOPROXY"
+
+// PackageManager implements the manager.PackageManager interface for Go binaries installed with go install.
+type PackageManager struct{}
+
+// IsAvailable checks if the go command is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(gocmd)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the gobin package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by gobin.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+	}
+}
+
+// BinDir returns the directory go install installs binaries into: $GOBIN, or the bin directory of the first GOPATH entry.
+func (a *PackageManager) BinDir() (string, error) {
+	cmd := exec.Command(gocmd, "env", "GOBIN", "GOPATH")
+	manager.SetEnv(cmd, nil, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	gobin, gopath, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if gobin = strings.TrimSpace(gobin); gobin != "" {
+		return gobin, nil
+	}
+	return filepath.Join(filepath.SplitList(strings.TrimSpace(gopath))[0], "bin"), nil
+}
+
+// Install builds and installs the provided programs, given by their package path (e.g. "golang.org/x/tools/gopls"), using go install.
+// Programs given without a version are installed at their latest version.
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// go install installs a specific version with path@version
+	pkgs, err := manager.TranslatePackageSpecs(pkgs, func(spec manager.PackageSpec) string { return spec.Name + "@" + spec.Version })
+	if err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// go install has no dry-run mode (-n still downloads and resolves modules), so only report what was requested
+	if opts.DryRun {
+		var packages []manager.PackageInfo
+		for _, pkg := range pkgs {
+			path, version, _ := strings.Cut(pkg, "@")
+			packages = append(packages, manager.PackageInfo{
+				Name:           filepath.Base(path),
+				Version:        version,
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"path": path},
+			})
+		}
+		return packages, nil
+	}
+
+	for _, pkg := range pkgs {
+		if !strings.Contains(pkg, "@") {
+			pkg += ArgsLatest
+		}
+		if err := a.run([]string{"install", pkg}, opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	return filterPackages(installed, pkgs), nil
+}
+
+// Delete removes the binaries of the provided programs, given by their binary name or package path, from the bin directory.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	packages := filterPackages(installed, pkgs)
+	for i := range packages {
+		if !opts.DryRun {
+			if opts.Verbose {
+				opts.Log().Debug("Removing binary", "package_manager", pm, "binary", packages[i].AdditionalData["binary"])
+			}
+			if err := os.Remove(packages[i].AdditionalData["binary"]); err != nil {
+				return nil, err
+			}
+		}
+		packages[i].Status = manager.PackageStatusAvailable
+	}
+	return packages, nil
+}
+
+// Refresh does nothing for gobin, as go always queries the module proxy directly.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	return nil
+}
+
+// Find is not supported, as there is no index of Go programs to search.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+
+// ListInstalled lists all Go binaries in the bin directory, with the module path and version they were built from.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	dir, err := a.BinDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	cmd := manager.Command(opts, gocmd, "version", ArgsModules, dir)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseVersionOutput(string(out), opts), nil
+}
+
+// ListUpgradable lists the installed Go binaries whose module has a newer version, using go list -m module@latest.
+// Binaries built from a local checkout (version "(devel)") are skipped.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]string)
+	var packages []manager.PackageInfo
+	for _, pkg := range installed {
+		module := pkg.AdditionalData["module"]
+		if module == "" || !strings.HasPrefix(pkg.Version, "v") {
+			continue
+		}
+
+		version, ok := latest[module]
+		if !ok {
+			version, err = a.latestVersion(module, opts)
+			if err != nil {
+				if opts != nil && opts.Verbose {
+					opts.Log().Debug("Failed to get the latest version", "package_manager", pm, "module", module, "error", err)
+				}
+				continue
+			}
+			latest[module] = version
+		}
+
+		if version != "" && version != pkg.Version {
+			pkg.NewVersion = version
+			pkg.Status = manager.PackageStatusUpgradable
+			packages = append(packages, pkg)
+		}
+	}
+	return packages, nil
+}
+
+// Upgrade reinstalls the provided programs (or all upgradable ones if none are given) at their latest version using go install.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	upgradable, err := a.ListUpgradable(opts)
+	if err != nil {
+		return nil, err
+	}
+	upgradable = filterPackages(upgradable, pkgs)
+	if opts.DryRun {
+		return upgradable, nil
+	}
+
+	for _, pkg := range upgradable {
+		args := []string{"install", pkg.AdditionalData["path"] + ArgsLatest}
+
+		opts.Log().Info("Running command", "package_manager", pm, "command", gocmd, "args", args)
+
+		if err := a.run(args, opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+	return upgradable, nil
+}
+
+// UpgradeAll reinstalls all upgradable programs at their latest version using go install.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// GetPackageInfo retrieves information about the specified installed program, given by its binary name or package path,
+// along with the latest version of its module.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+
+	found := filterPackages(installed, []string{pkg})
+	if len(found) == 0 {
+		return manager.PackageInfo{Name: pkg, Status: manager.PackageStatusUnknown, PackageManager: pm}, nil
+	}
+
+	info := found[0]
+	if module := info.AdditionalData["module"]; module != "" && strings.HasPrefix(info.Version, "v") {
+		version, err := a.latestVersion(module, opts)
+		if err != nil {
+			return manager.PackageInfo{}, err
+		}
+		if version != info.Version {
+			info.NewVersion = version
+			info.Status = manager.PackageStatusUpgradable
+		}
+	}
+	return info, nil
+}
+
+// latestVersion returns the latest version of the given module, as reported by the module proxy.
+func (a *PackageManager) latestVersion(module string, opts *manager.Options) (string, error) {
+	cmd := manager.Command(opts, gocmd, "list", ArgsModules, "-f", "{{.Version}}", module+ArgsLatest)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	// run outside of any module, so that the go.mod of the current directory is not used
+	cmd.Dir = os.TempDir()
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// run runs go with the given arguments, either attached to the terminal in interactive mode,
+// or non-interactively, logging the output in verbose mode.
+func (a *PackageManager) run(args []string, opts *manager.Options) error {
+	cmd := manager.Command(opts, gocmd, args...)
+	// run outside of any module, so that the go.mod of the current directory is not used
+	cmd.Dir = os.TempDir()
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return manager.Run(cmd)
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	out, err := manager.CombinedOutput(cmd)
+	if opts.Verbose || err != nil {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+// Package gobin provides a package manager implementation for Go binaries
+// using go install as the underlying package management tool.
+package gobin
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ParseVersionOutput parses the output of `go version -m dir` command and returns a list of installed Go binaries.
+// The name of each binary is its file name, and its version is the version of the main module it was built from.
+// The package path, module path, Go version and binary location are kept in AdditionalData.
+// Example msg:
+//
+//	/home/user/go/bin/gopls: go1.21.0
+//		path	golang.org/x/tools/gopls
+//		mod	golang.org/x/tools/gopls	v0.13.2	h1:Pyvx6MKvatbX3zzZVdGiTRUkhsV/ZK4gxnuYhKXBAoE=
+//		dep	golang.org/x/mod	v0.12.0	h1:rmsUpXtvNzj340zd98LZ4KntptpfRHwpFOHG188oHXc=
+//		build	-compiler=gc
+func ParseVersionOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		if line == "" {
+			continue
+		}
+
+		// binary lines are not indented, information lines are indented with a tab
+		if !strings.HasPrefix(line, "\t") {
+			binary, goVersion, found := strings.Cut(line, ": ")
+			if !found {
+				continue
+			}
+			packages = append(packages, manager.PackageInfo{
+				Name:           strings.TrimSuffix(filepath.Base(binary), ".exe"),
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"binary": binary, "go_version": strings.TrimSpace(goVersion)},
+			})
+			continue
+		}
+		if len(packages) == 0 {
+			continue
+		}
+
+		current := &packages[len(packages)-1]
+		fields := strings.Split(strings.TrimPrefix(line, "\t"), "\t")
+		switch {
+		case fields[0] == "path" && len(fields) > 1:
+			current.AdditionalData["path"] = fields[1]
+		case fields[0] == "mod" && len(fields) > 2:
+			current.AdditionalData["module"] = fields[1]
+			current.Version = fields[2]
+		}
+	}
+
+	return packages
+}
+
+// filterPackages returns the binaries matching one of the given programs, by binary name or package path
+// (with an optional version, e.g. "golang.org/x/tools/gopls@latest"), or all binaries if no programs are given.
+func filterPackages(packages []manager.PackageInfo, pkgs []string) []manager.PackageInfo {
+	if len(pkgs) == 0 {
+		return packages
+	}
+
+	wanted := make(map[string]bool)
+	for _, pkg := range pkgs {
+		path, _, _ := strings.Cut(pkg, "@")
+		wanted[path] = true
+	}
+
+	var filtered []manager.PackageInfo
+	for _, pkg := range packages {
+		if wanted[pkg.Name] || wanted[pkg.AdditionalData["path"]] {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,56 @@
+package gobin_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/gobin"
+)
+
+func TestParseVersionOutput(t *testing.T) {
+	var inputParseVersionOutput string = strings.Join([]string{
+		"/home/user/go/bin/gopls: go1.21.0",
+		"\tpath\tgolang.org/x/tools/gopls",
+		"\tmod\tgolang.org/x/tools/gopls\tv0.13.2\th1:Pyvx6MKvatbX3zzZVdGiTRUkhsV/ZK4gxnuYhKXBAoE=",
+		"\tdep\tgolang.org/x/mod\tv0.12.0\th1:rmsUpXtvNzj340zd98LZ4KntptpfRHwpFOHG188oHXc=",
+		"\tbuild\t-compiler=gc",
+		"/home/user/go/bin/mytool: go1.21.0",
+		"\tpath\texample.com/mytool",
+		"\tmod\texample.com/mytool\t(devel)\t",
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "gopls",
+			Version:        "v0.13.2",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "gobin",
+			AdditionalData: map[string]string{
+				"binary":     "/home/user/go/bin/gopls",
+				"go_version": "go1.21.0",
+				"path":       "golang.org/x/tools/gopls",
+				"module":     "golang.org/x/tools/gopls",
+			},
+		},
+		{
+			Name:           "mytool",
+			Version:        "(devel)",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "gobin",
+			AdditionalData: map[string]string{
+				"binary":     "/home/user/go/bin/mytool",
+				"go_version": "go1.21.0",
+				"path":       "example.com/mytool",
+				"module":     "example.com/mytool",
+			},
+		},
+	}
+
+	actualPackageInfo := gobin.ParseVersionOutput(inputParseVersionOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseVersionOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
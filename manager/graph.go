@@ -0,0 +1,100 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DependencyGraph is the graph of the dependencies of a package, or of its reverse dependencies, to export it, such as
+// to Graphviz with DOT.
+type DependencyGraph struct {
+	// Root is the package the graph is built from.
+	Root string `json:"root"`
+
+	// Reverse is set if the graph is built from the reverse dependencies of Root, the packages depending on it.
+	Reverse bool `json:"reverse,omitempty"`
+
+	// Nodes are the names of the packages of the graph, Root first, in the order they are found.
+	Nodes []string `json:"nodes"`
+
+	// Edges are the dependencies between the packages; they always go from a package to one it depends on, even in
+	// reverse graphs.
+	Edges []DependencyEdge `json:"edges"`
+
+	PackageManager string `json:"package_manager"`
+}
+
+// DependencyEdge is a dependency of a package on another one.
+type DependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Constraint is the version constraint of the dependency, such as ">= 2.34", if any.
+	Constraint string `json:"constraint,omitempty"`
+}
+
+// BuildDependencyGraph builds the graph of the dependencies of the package pkg of package manager pm, up to maxDepth
+// levels, with query returning the direct dependencies of a package, such as DependencyQuerier.GetDependencies, or
+// its reverse dependencies if reverse is set. An error querying pkg is returned, while the packages whose dependencies
+// can't be queried, such as virtual packages, are left without them.
+func BuildDependencyGraph(pm string, pkg string, query func(string) ([]PackageInfo, error), maxDepth int, reverse bool) (*DependencyGraph, error) {
+	graph := &DependencyGraph{Root: pkg, Reverse: reverse, Nodes: []string{pkg}, Edges: []DependencyEdge{}, PackageManager: pm}
+	seen := map[string]bool{pkg: true}
+	seenEdges := make(map[DependencyEdge]bool)
+
+	level := []string{pkg}
+	for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+		var nextLevel []string
+		for _, name := range level {
+			packages, err := query(name)
+			if err != nil {
+				if name == pkg {
+					return nil, err
+				}
+				continue
+			}
+
+			for _, p := range packages {
+				edge := DependencyEdge{From: name, To: p.Name, Constraint: strings.TrimSpace(p.AdditionalData["constraint"])}
+				if reverse {
+					edge.From, edge.To = p.Name, name
+				}
+				if !seenEdges[edge] {
+					seenEdges[edge] = true
+					graph.Edges = append(graph.Edges, edge)
+				}
+
+				if !seen[p.Name] {
+					seen[p.Name] = true
+					graph.Nodes = append(graph.Nodes, p.Name)
+					nextLevel = append(nextLevel, p.Name)
+				}
+			}
+		}
+		level = nextLevel
+	}
+	return graph, nil
+}
+
+// DOT returns the graph in the DOT language of Graphviz, such as
+//
+//	digraph "apt: vim" {
+//	  "vim" [style=bold];
+//	  "vim" -> "libgpm2" [label=">= 1.20.7"];
+//	}
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(g.PackageManager+": "+g.Root))
+	fmt.Fprintf(&b, "  %s [style=bold];\n", strconv.Quote(g.Root))
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s", strconv.Quote(edge.From), strconv.Quote(edge.To))
+		if edge.Constraint != "" {
+			fmt.Fprintf(&b, " [label=%s]", strconv.Quote(edge.Constraint))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
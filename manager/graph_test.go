@@ -0,0 +1,76 @@
+package manager_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestBuildDependencyGraph(t *testing.T) {
+	dependencies := map[string][]manager.PackageInfo{
+		"vim":         {{Name: "vim-runtime", AdditionalData: map[string]string{"constraint": "=9.0.1"}}, {Name: "libgpm2"}},
+		"vim-runtime": {{Name: "libgpm2"}},
+		"libgpm2":     {{Name: "libc6", AdditionalData: map[string]string{"constraint": ">= 2.34"}}},
+	}
+	query := func(pkg string) ([]manager.PackageInfo, error) {
+		if pkg == "libc6" {
+			return nil, errors.New("exit status 100")
+		}
+		return dependencies[pkg], nil
+	}
+
+	actual, err := manager.BuildDependencyGraph("apt", "vim", query, 2, false)
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph() error = %+v", err)
+	}
+	expected := &manager.DependencyGraph{
+		Root:  "vim",
+		Nodes: []string{"vim", "vim-runtime", "libgpm2", "libc6"},
+		Edges: []manager.DependencyEdge{
+			{From: "vim", To: "vim-runtime", Constraint: "=9.0.1"},
+			{From: "vim", To: "libgpm2"},
+			{From: "vim-runtime", To: "libgpm2"},
+			{From: "libgpm2", To: "libc6", Constraint: ">= 2.34"},
+		},
+		PackageManager: "apt",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("BuildDependencyGraph() = %+v, want %+v", actual, expected)
+	}
+
+	expectedDOT := `digraph "apt: vim" {
+  "vim" [style=bold];
+  "vim" -> "vim-runtime" [label="=9.0.1"];
+  "vim" -> "libgpm2";
+  "vim-runtime" -> "libgpm2";
+  "libgpm2" -> "libc6" [label=">= 2.34"];
+}
+`
+	if actualDOT := actual.DOT(); actualDOT != expectedDOT {
+		t.Errorf("DOT() = %s, want %s", actualDOT, expectedDOT)
+	}
+
+	reverseDependencies := map[string][]manager.PackageInfo{"libgpm2": {{Name: "vim"}, {Name: "vim-runtime"}}, "vim-runtime": {{Name: "vim"}}}
+	actual, err = manager.BuildDependencyGraph("apt", "libgpm2", func(pkg string) ([]manager.PackageInfo, error) {
+		return reverseDependencies[pkg], nil
+	}, 3, true)
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph() error = %+v", err)
+	}
+	expected = &manager.DependencyGraph{
+		Root:           "libgpm2",
+		Reverse:        true,
+		Nodes:          []string{"libgpm2", "vim", "vim-runtime"},
+		Edges:          []manager.DependencyEdge{{From: "vim", To: "libgpm2"}, {From: "vim-runtime", To: "libgpm2"}, {From: "vim", To: "vim-runtime"}},
+		PackageManager: "apt",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("BuildDependencyGraph(reverse) = %+v, want %+v", actual, expected)
+	}
+
+	if _, err := manager.BuildDependencyGraph("apt", "libc6", query, 2, false); err == nil {
+		t.Errorf("BuildDependencyGraph() error = nil, want an error")
+	}
+}
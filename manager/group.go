@@ -0,0 +1,33 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+// GroupInfo contains information about a group of packages installed together, such as a task of tasksel for apt,
+// a pattern of zypper or a group of pacman.
+type GroupInfo struct {
+	// Name identifies the group for the package manager, used to install it, such as "ssh-server" or "devel_basis".
+	Name string `json:"name"`
+
+	// Description is the human-readable description of the group, if any.
+	Description string `json:"description,omitempty"`
+
+	// Installed is set if the group, or all its packages, are installed.
+	Installed bool `json:"installed"`
+
+	// Packages are the names of the packages of the group, when the package manager lists them.
+	Packages []string `json:"packages,omitempty"`
+
+	// PackageManager is the name of the package manager installing this group, such as "apt" or "zypper".
+	PackageManager string `json:"package_manager"`
+}
+
+// SetGroup records the group the packages were installed or removed with in their AdditionalData, as "group",
+// and returns them.
+func SetGroup(packages []PackageInfo, group string) []PackageInfo {
+	for i := range packages {
+		if packages[i].AdditionalData == nil {
+			packages[i].AdditionalData = make(map[string]string)
+		}
+		packages[i].AdditionalData["group"] = group
+	}
+	return packages
+}
@@ -0,0 +1,225 @@
+// Package history records the package transactions (install, delete, upgrade) performed through syspkg,
+// so that they can be listed and rolled back later.
+//
+// Transactions are stored as JSON lines in a local file, one transaction per line, which keeps the store
+// append-only and readable with standard tools. Every transaction gets a sequential ID, starting at 1.
+//
+// This package is part of the syspkg library.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Operation is the kind of change performed by a transaction.
+type Operation string
+
+// Operations recorded in the history.
+const (
+	OperationInstall   Operation = "install"
+	OperationDelete    Operation = "delete"
+	OperationUpgrade   Operation = "upgrade"
+	OperationDowngrade Operation = "downgrade"
+)
+
+// ErrTransactionNotFound is returned when a transaction ID is not in the history.
+var ErrTransactionNotFound = errors.New("history: transaction not found")
+
+// ErrNotReversible is returned when the inverse of a transaction can't be computed,
+// e.g. for failed transactions, or upgrades whose previous versions weren't reported.
+var ErrNotReversible = errors.New("history: transaction can't be rolled back")
+
+// Transaction is a package operation performed by a package manager.
+type Transaction struct {
+	// ID is the sequential ID of the transaction, assigned by Store.Record.
+	ID int `json:"id"`
+
+	// Time is the time the transaction was recorded, assigned by Store.Record.
+	Time time.Time `json:"time"`
+
+	// PackageManager is the name of the package manager that performed the transaction.
+	PackageManager string `json:"package_manager"`
+
+	// Operation is the kind of change performed by the transaction.
+	Operation Operation `json:"operation"`
+
+	// Requested are the package names given to the package manager. It is empty when upgrading all packages.
+	Requested []string `json:"requested,omitempty"`
+
+	// Packages are the packages changed by the transaction, with their versions, as reported by the package manager.
+	// Package managers report no packages in interactive mode.
+	Packages []manager.PackageInfo `json:"packages,omitempty"`
+
+	// Error is the error returned by the package manager, if the transaction failed.
+	Error string `json:"error,omitempty"`
+
+	// RollbackOf is the ID of the transaction this transaction rolled back, if any.
+	RollbackOf int `json:"rollback_of,omitempty"`
+}
+
+// Success reports whether the transaction succeeded.
+func (tx Transaction) Success() bool {
+	return tx.Error == ""
+}
+
+// Store is a history of transactions stored in a JSON lines file.
+type Store struct {
+	path string
+}
+
+// NewStore returns a Store backed by the file at path. The file and its directory are created on the first Record.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the default location of the history file, history.jsonl in the syspkg state directory (see manager.StateDir).
+func DefaultPath() string {
+	return filepath.Join(manager.StateDir(), "history.jsonl")
+}
+
+// Path returns the path of the history file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Record appends the transaction to the history, assigning its ID and time, and returns the recorded transaction.
+func (s *Store) Record(tx Transaction) (Transaction, error) {
+	transactions, err := s.List()
+	if err != nil {
+		return tx, err
+	}
+
+	tx.ID = 1
+	if len(transactions) > 0 {
+		tx.ID = transactions[len(transactions)-1].ID + 1
+	}
+	tx.Time = time.Now().UTC()
+
+	line, err := json.Marshal(tx)
+	if err != nil {
+		return tx, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return tx, err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return tx, err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return tx, err
+}
+
+// List returns all transactions in the history, oldest first. A missing history file is an empty history.
+func (s *Store) List() ([]Transaction, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var transactions []Transaction
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var tx Transaction
+		if err := json.Unmarshal(scanner.Bytes(), &tx); err != nil {
+			return nil, fmt.Errorf("history: %s:%d: %w", s.path, line, err)
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions, scanner.Err()
+}
+
+// Get returns the transaction with the given ID.
+func (s *Store) Get(id int) (Transaction, error) {
+	transactions, err := s.List()
+	if err != nil {
+		return Transaction{}, err
+	}
+	for _, tx := range transactions {
+		if tx.ID == id {
+			return tx, nil
+		}
+	}
+	return Transaction{}, ErrTransactionNotFound
+}
+
+// Inverse returns the operation and package names that undo the transaction:
+// installed packages are deleted, deleted packages are installed again, and upgraded or downgraded packages are
+// downgraded back to their previous versions, given as "name=version" (see syspkg.Downgrader).
+// The packages reported by the package manager are used when available, as they include the dependencies
+// pulled in or removed by the transaction; otherwise the requested packages are used.
+// The packages an install only upgraded or downgraded, reported with their previous Version and a different
+// NewVersion, were installed before it, so they aren't deleted.
+func Inverse(tx Transaction) (Operation, []string, error) {
+	if !tx.Success() {
+		return "", nil, fmt.Errorf("%w: transaction %d failed", ErrNotReversible, tx.ID)
+	}
+	if tx.Operation == OperationUpgrade || tx.Operation == OperationDowngrade {
+		pkgs, err := previousVersions(tx)
+		if err != nil {
+			return "", nil, err
+		}
+		return OperationDowngrade, pkgs, nil
+	}
+
+	var pkgs []string
+	for _, pkg := range tx.Packages {
+		if tx.Operation == OperationInstall && pkg.NewVersion != "" && pkg.Version != pkg.NewVersion {
+			continue
+		}
+		pkgs = append(pkgs, pkg.Name)
+	}
+	if len(tx.Packages) == 0 {
+		pkgs = tx.Requested
+	}
+	if len(pkgs) == 0 {
+		return "", nil, fmt.Errorf("%w: transaction %d changed no packages", ErrNotReversible, tx.ID)
+	}
+
+	switch tx.Operation {
+	case OperationInstall:
+		return OperationDelete, pkgs, nil
+	case OperationDelete:
+		return OperationInstall, pkgs, nil
+	default:
+		return "", nil, fmt.Errorf("%w: %s transactions can't be undone", ErrNotReversible, tx.Operation)
+	}
+}
+
+// previousVersions returns the packages changed by an upgrade or a downgrade, as "name=version" with the versions
+// installed before it. The packages it reported without a previous version can't be changed back.
+func previousVersions(tx Transaction) ([]string, error) {
+	var pkgs []string
+	for _, pkg := range tx.Packages {
+		if pkg.NewVersion != "" && pkg.Version == pkg.NewVersion {
+			continue
+		}
+		if pkg.Version == "" || pkg.NewVersion == "" {
+			return nil, fmt.Errorf("%w: the previous version of %s is unknown", ErrNotReversible, pkg.Name)
+		}
+		pkgs = append(pkgs, pkg.Name+"="+pkg.Version)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("%w: transaction %d changed no packages", ErrNotReversible, tx.ID)
+	}
+	return pkgs, nil
+}
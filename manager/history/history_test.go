@@ -0,0 +1,142 @@
+package history_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/history"
+)
+
+func TestStore(t *testing.T) {
+	store := history.NewStore(filepath.Join(t.TempDir(), "syspkg", "history.jsonl"))
+
+	transactions, err := store.List()
+	if err != nil || transactions != nil {
+		t.Fatalf("List() = %+v, %+v, want empty history", transactions, err)
+	}
+
+	first, err := store.Record(history.Transaction{
+		PackageManager: "apt",
+		Operation:      history.OperationInstall,
+		Requested:      []string{"vim"},
+		Packages:       []manager.PackageInfo{{Name: "vim", Version: "2:8.2.3995-1ubuntu2.15", Status: manager.PackageStatusInstalled, PackageManager: "apt"}},
+	})
+	if err != nil {
+		t.Fatalf("Record() error = %+v", err)
+	}
+	second, err := store.Record(history.Transaction{
+		PackageManager: "apt",
+		Operation:      history.OperationDelete,
+		Requested:      []string{"nano"},
+		Error:          "exit status 100",
+	})
+	if err != nil {
+		t.Fatalf("Record() error = %+v", err)
+	}
+
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("Record() IDs = %d, %d, want 1, 2", first.ID, second.ID)
+	}
+
+	got, err := store.Get(1)
+	if err != nil {
+		t.Fatalf("Get() error = %+v", err)
+	}
+	if !reflect.DeepEqual(got.Packages, first.Packages) || !got.Time.Equal(first.Time) {
+		t.Errorf("Get() = %+v, want %+v", got, first)
+	}
+
+	if _, err := store.Get(3); !errors.Is(err, history.ErrTransactionNotFound) {
+		t.Errorf("Get() error = %+v, want %+v", err, history.ErrTransactionNotFound)
+	}
+}
+
+func TestInverse(t *testing.T) {
+	tests := []struct {
+		name    string
+		tx      history.Transaction
+		wantOp  history.Operation
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "install is undone by deleting the changed packages",
+			tx: history.Transaction{
+				Operation: history.OperationInstall,
+				Requested: []string{"vim"},
+				Packages:  []manager.PackageInfo{{Name: "vim"}, {Name: "vim-runtime"}},
+			},
+			wantOp: history.OperationDelete,
+			want:   []string{"vim", "vim-runtime"},
+		},
+		{
+			name: "install is undone by deleting the new packages, keeping the upgraded ones",
+			tx: history.Transaction{
+				Operation: history.OperationInstall,
+				Requested: []string{"foo"},
+				Packages: []manager.PackageInfo{
+					{Name: "foo", Version: "1.0-1", NewVersion: "1.0-1"},
+					{Name: "libssl3", Version: "3.0.2-0ubuntu1.8", NewVersion: "3.0.2-0ubuntu1.9"},
+				},
+			},
+			wantOp: history.OperationDelete,
+			want:   []string{"foo"},
+		},
+		{
+			name: "install that only upgraded packages",
+			tx: history.Transaction{
+				Operation: history.OperationInstall,
+				Requested: []string{"libssl3"},
+				Packages:  []manager.PackageInfo{{Name: "libssl3", Version: "3.0.2-0ubuntu1.8", NewVersion: "3.0.2-0ubuntu1.9"}},
+			},
+			wantErr: history.ErrNotReversible,
+		},
+		{
+			name:   "delete without reported packages is undone by installing the requested packages",
+			tx:     history.Transaction{Operation: history.OperationDelete, Requested: []string{"nano"}},
+			wantOp: history.OperationInstall,
+			want:   []string{"nano"},
+		},
+		{
+			name:    "failed transaction",
+			tx:      history.Transaction{Operation: history.OperationInstall, Requested: []string{"vim"}, Error: "exit status 100"},
+			wantErr: history.ErrNotReversible,
+		},
+		{
+			name:    "upgrade",
+			tx:      history.Transaction{Operation: history.OperationUpgrade, Packages: []manager.PackageInfo{{Name: "vim"}}},
+			wantErr: history.ErrNotReversible,
+		},
+		{
+			name: "upgrade is undone by downgrading to the previous versions",
+			tx: history.Transaction{
+				Operation: history.OperationUpgrade,
+				Packages: []manager.PackageInfo{
+					{Name: "vim", Version: "2:8.2.3995-1ubuntu2.15", NewVersion: "2:8.2.3995-1ubuntu2.16"},
+					{Name: "libssl3", Version: "3.0.2-0ubuntu1.8", NewVersion: "3.0.2-0ubuntu1.9"},
+				},
+			},
+			wantOp: history.OperationDowngrade,
+			want:   []string{"vim=2:8.2.3995-1ubuntu2.15", "libssl3=3.0.2-0ubuntu1.8"},
+		},
+		{
+			name:    "upgrade without reported packages",
+			tx:      history.Transaction{Operation: history.OperationUpgrade},
+			wantErr: history.ErrNotReversible,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, pkgs, err := history.Inverse(tt.tx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Inverse() error = %+v, want %+v", err, tt.wantErr)
+			}
+			if op != tt.wantOp || !reflect.DeepEqual(pkgs, tt.want) {
+				t.Errorf("Inverse() = %s %+v, want %s %+v", op, pkgs, tt.wantOp, tt.want)
+			}
+		})
+	}
+}
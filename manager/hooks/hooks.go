@@ -0,0 +1,231 @@
+// Package hooks runs the commands and webhooks registered to run before or after package operations, e.g. to notify
+// a chat channel after upgrading all packages, or to restart a service after its package is upgraded.
+//
+// Hooks are registered for an event, the phase and the operation joined by a dash, such as "pre-install" or
+// "post-upgrade", and can be restricted to some package managers or packages. Commands are run with sh -c, with the
+// details of the operation in SYSPKG_* environment variables; webhooks receive them as a JSON POST request.
+//
+// This package is part of the syspkg library.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// DefaultTimeout is how long a hook may run when it has no timeout.
+const DefaultTimeout = time.Minute
+
+// Phases of the operations, before and after they run.
+const (
+	PhasePre  = "pre"
+	PhasePost = "post"
+)
+
+// Failure policies of the hooks.
+const (
+	// FailureWarn logs the failures of the hook, and carries on. It is the default.
+	FailureWarn = "warn"
+
+	// FailureAbort stops running hooks when the hook fails, and makes Run return an error wrapping ErrAborted.
+	// A failing pre hook thus cancels its operation.
+	FailureAbort = "abort"
+)
+
+// ErrAborted is returned by Run when a hook with the FailureAbort policy fails.
+var ErrAborted = errors.New("hooks: aborted by a failing hook")
+
+// Hook is a command or webhook run before or after operations.
+type Hook struct {
+	// Event is when the hook runs: the phase and the operation joined by a dash, such as "pre-install" or
+	// "post-upgrade". The operation "*" matches all operations, e.g. "post-*".
+	Event string
+
+	// Command is the shell command run by the hook.
+	Command string
+
+	// Webhook is the URL the details of the operation are posted to, as JSON, if Command is empty.
+	Webhook string
+
+	// PackageManagers restricts the hook to the operations of these package managers, if not empty.
+	PackageManagers []string
+
+	// Packages restricts the hook to the operations on any of these packages, if not empty.
+	// Operations on all the packages, such as upgrading all packages, match once their packages are known, after they ran.
+	Packages []string
+
+	// Timeout is how long the hook may run; DefaultTimeout if zero.
+	Timeout time.Duration
+
+	// OnFailure is FailureWarn or FailureAbort; FailureWarn if empty.
+	OnFailure string
+}
+
+// Operation is an operation hooks run for.
+type Operation struct {
+	// Event is the event of the hooks run, set by Run.
+	Event string `json:"event"`
+
+	// Host is the name of the host the operation ran on, set by Run.
+	Host string `json:"host"`
+
+	// PackageManager is the name of the package manager running the operation.
+	PackageManager string `json:"package_manager"`
+
+	// Operation is the operation, such as "install" or "upgrade".
+	Operation string `json:"operation"`
+
+	// Packages are the names of the packages operated on: the requested ones before the operation, and the ones
+	// reported by the package manager after it if any.
+	Packages []string `json:"packages,omitempty"`
+
+	// Error is the error of the operation, after it failed.
+	Error string `json:"error,omitempty"`
+
+	// Text is a summary of the operation, such as "apt upgrade on web1 succeeded: nginx, openssl", set by Run.
+	// Chat webhooks such as Slack's show it as the message.
+	Text string `json:"text"`
+}
+
+// Validate checks that the hook has a valid event, one action, and a known failure policy.
+func (h Hook) Validate() error {
+	phase, operation, ok := strings.Cut(h.Event, "-")
+	if !ok || (phase != PhasePre && phase != PhasePost) || operation == "" {
+		return fmt.Errorf("invalid event %q, expected e.g. pre-install or post-upgrade", h.Event)
+	}
+	if (h.Command == "") == (h.Webhook == "") {
+		return errors.New("expected either a command or a webhook")
+	}
+	if h.Webhook != "" && !strings.HasPrefix(h.Webhook, "https://") && !strings.HasPrefix(h.Webhook, "http://") {
+		return fmt.Errorf("invalid webhook %q, expected an HTTP(S) URL", h.Webhook)
+	}
+	if h.OnFailure != "" && h.OnFailure != FailureWarn && h.OnFailure != FailureAbort {
+		return fmt.Errorf("unknown failure policy %q, expected %s or %s", h.OnFailure, FailureWarn, FailureAbort)
+	}
+	return nil
+}
+
+// Matches reports whether the hook runs for operation op in phase.
+func (h Hook) Matches(phase string, op Operation) bool {
+	hookPhase, operation, _ := strings.Cut(h.Event, "-")
+	if hookPhase != phase || (operation != "*" && operation != op.Operation) {
+		return false
+	}
+	if len(h.PackageManagers) > 0 && !slices.Contains(h.PackageManagers, op.PackageManager) {
+		return false
+	}
+	if len(h.Packages) > 0 {
+		for _, pkg := range op.Packages {
+			if slices.Contains(h.Packages, pkg) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Run runs the hooks matching operation op in phase, in order. Failing hooks are logged with logger (the default
+// slog logger if nil), unless their failure policy is FailureAbort: Run then stops and returns an error wrapping
+// ErrAborted.
+func Run(hooks []Hook, phase string, op Operation, logger manager.Logger) error {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	op.Event = phase + "-" + op.Operation
+	op.Host, _ = os.Hostname()
+	op.Text = summary(phase, op)
+
+	for _, h := range hooks {
+		if !h.Matches(phase, op) {
+			continue
+		}
+		err := h.run(op)
+		if err == nil {
+			continue
+		}
+		if h.OnFailure == FailureAbort {
+			return fmt.Errorf("%w: %s: %v", ErrAborted, h.action(), err)
+		}
+		logger.Warn("Hook failed", "event", op.Event, "hook", h.action(), "error", err)
+	}
+	return nil
+}
+
+// run runs the command or webhook of the hook for op.
+func (h Hook) run(op Operation) error {
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if h.Command != "" {
+		cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+		cmd.Env = append(os.Environ(),
+			"SYSPKG_HOOK_EVENT="+op.Event,
+			"SYSPKG_PACKAGE_MANAGER="+op.PackageManager,
+			"SYSPKG_OPERATION="+op.Operation,
+			"SYSPKG_PACKAGES="+strings.Join(op.Packages, " "),
+			"SYSPKG_ERROR="+op.Error,
+		)
+		cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+		return manager.Run(cmd)
+	}
+
+	body, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// action returns the command or webhook of the hook, to identify it in errors.
+func (h Hook) action() string {
+	if h.Command != "" {
+		return h.Command
+	}
+	return h.Webhook
+}
+
+// summary returns the text summarizing operation op in phase.
+func summary(phase string, op Operation) string {
+	status := "starting"
+	if phase == PhasePost {
+		status = "succeeded"
+		if op.Error != "" {
+			status = "failed (" + op.Error + ")"
+		}
+	}
+	text := fmt.Sprintf("%s %s on %s %s", op.PackageManager, op.Operation, op.Host, status)
+	if len(op.Packages) > 0 {
+		text += ": " + strings.Join(op.Packages, ", ")
+	}
+	return text
+}
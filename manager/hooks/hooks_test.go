@@ -0,0 +1,91 @@
+package hooks_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager/hooks"
+)
+
+func TestMatches(t *testing.T) {
+	op := hooks.Operation{PackageManager: "apt", Operation: "upgrade", Packages: []string{"nginx", "openssl"}}
+	tests := []struct {
+		hook  hooks.Hook
+		phase string
+		want  bool
+	}{
+		{hook: hooks.Hook{Event: "post-upgrade"}, phase: hooks.PhasePost, want: true},
+		{hook: hooks.Hook{Event: "post-upgrade"}, phase: hooks.PhasePre, want: false},
+		{hook: hooks.Hook{Event: "post-install"}, phase: hooks.PhasePost, want: false},
+		{hook: hooks.Hook{Event: "pre-*"}, phase: hooks.PhasePre, want: true},
+		{hook: hooks.Hook{Event: "post-upgrade", PackageManagers: []string{"snap"}}, phase: hooks.PhasePost, want: false},
+		{hook: hooks.Hook{Event: "post-upgrade", PackageManagers: []string{"apt"}, Packages: []string{"nginx"}}, phase: hooks.PhasePost, want: true},
+		{hook: hooks.Hook{Event: "post-upgrade", Packages: []string{"postgresql"}}, phase: hooks.PhasePost, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.hook.Matches(tt.phase, op); got != tt.want {
+			t.Errorf("Matches(%+v, %s) = %v, want %v", tt.hook, tt.phase, got, tt.want)
+		}
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "env")
+	hs := []hooks.Hook{
+		{Event: "post-install", Command: `echo "$SYSPKG_HOOK_EVENT $SYSPKG_PACKAGE_MANAGER $SYSPKG_PACKAGES" > ` + out},
+		{Event: "post-install", Command: "exit 1"},
+	}
+	op := hooks.Operation{PackageManager: "apt", Operation: "install", Packages: []string{"vim", "curl"}}
+	if err := hooks.Run(hs, hooks.PhasePost, op, nil); err != nil {
+		t.Fatalf("Run() error = %+v, want a warning only", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(data)), "post-install apt vim curl"; got != want {
+		t.Errorf("Run() ran the command with %q, want %q", got, want)
+	}
+
+	hs[1].OnFailure = hooks.FailureAbort
+	if err := hooks.Run(hs, hooks.PhasePost, op, nil); !errors.Is(err, hooks.ErrAborted) {
+		t.Errorf("Run() error = %+v, want %+v", err, hooks.ErrAborted)
+	}
+}
+
+func TestRunWebhook(t *testing.T) {
+	var got hooks.Operation
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	hs := []hooks.Hook{{Event: "post-upgrade", Webhook: server.URL, OnFailure: hooks.FailureAbort}}
+	op := hooks.Operation{PackageManager: "apt", Operation: "upgrade", Packages: []string{"nginx"}, Error: "exit status 100"}
+	if err := hooks.Run(hs, hooks.PhasePost, op, nil); err != nil {
+		t.Fatalf("Run() error = %+v", err)
+	}
+
+	host, _ := os.Hostname()
+	want := hooks.Operation{
+		Event:          "post-upgrade",
+		Host:           host,
+		PackageManager: "apt",
+		Operation:      "upgrade",
+		Packages:       []string{"nginx"},
+		Error:          "exit status 100",
+		Text:           "apt upgrade on " + host + " failed (exit status 100): nginx",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Run() posted %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,237 @@
+// Package integrity verifies package files, such as the .deb and .rpm files downloaded or installed locally, before
+// they are installed: their SHA256 checksums, against the ones provided by users or by the signed repository indexes,
+// and their signatures.
+//
+// .deb files are not signed themselves: apt verifies the signatures of the repository indexes, which list the checksums
+// of the packages. A .deb file is verified when its checksum is listed for its package and version by apt-cache show.
+// .rpm files are signed, and verified with rpm -K against the keys imported in the rpm database.
+//
+// This package is part of the syspkg library.
+package integrity
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ErrVerificationFailed is returned by Check for the package files that failed verification, or that couldn't be
+// verified when unverified files are not allowed.
+var ErrVerificationFailed = errors.New("package files failed verification")
+
+// Status is the result of the verification of a package file.
+type Status string
+
+// Constants representing the result of the verification of a package file.
+const (
+	// StatusVerified is used for files whose checksum or signature was verified.
+	StatusVerified Status = "verified"
+
+	// StatusUnverified is used for files that can be verified, but have no signature, and whose checksum is not known,
+	// such as the packages built locally.
+	StatusUnverified Status = "unverified"
+
+	// StatusFailed is used for files whose checksum or signature doesn't match.
+	StatusFailed Status = "failed"
+
+	// StatusUnsupported is used for the types of files that are not verified, such as .flatpakref files, unless their
+	// checksum is provided.
+	StatusUnsupported Status = "unsupported"
+)
+
+// Result is the result of the verification of a package file.
+type Result struct {
+	// Path is the path of the package file.
+	Path string `json:"path"`
+
+	// SHA256 is the SHA256 checksum of the file, in hexadecimal.
+	SHA256 string `json:"sha256"`
+
+	// Status is the result of the verification.
+	Status Status `json:"status"`
+
+	// Signature is the status of the signature of the file, if it was checked.
+	Signature manager.SignatureStatus `json:"signature,omitempty"`
+
+	// Reason describes how the file was verified, or why it failed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// SHA256 returns the SHA256 checksum of the file at path, in hexadecimal.
+func SHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ParseChecksums parses the content of a checksums file, in the format of sha256sum, and returns the checksums by
+// file name, without directories. Lines that are not checksums, such as comments, are skipped.
+// Example content:
+//
+//	5d41402abc4b2a76b9719d911017c592a1b0e1d8f7e6c2b4a0b3c5d6e7f80912  nano_7.2-1_amd64.deb
+//	7c211433f02071597741e6ff5a8ea34789abbf43d7d4d7fbd20c0a2bb1d3b6e0 *vim-9.0.1632-1.1.x86_64.rpm
+func ParseChecksums(content string) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			continue
+		}
+		if _, err := hex.DecodeString(fields[0]); err != nil {
+			continue
+		}
+		// sha256sum marks the files read in binary mode with *
+		checksums[filepath.Base(strings.TrimPrefix(fields[1], "*"))] = strings.ToLower(fields[0])
+	}
+	return checksums
+}
+
+// Verify verifies the package file at path: its checksum against checksums, by file name, if it is listed there, then
+// its checksum or signature with the package manager of its type, for .deb and .rpm files.
+func Verify(path string, checksums map[string]string, opts *manager.Options) (Result, error) {
+	sum, err := SHA256(path)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Path: path, SHA256: sum, Status: StatusUnsupported}
+
+	expected, listed := checksums[filepath.Base(path)]
+	if listed && expected != sum {
+		result.Status, result.Reason = StatusFailed, "checksum differs from the provided one, "+expected
+		return result, nil
+	}
+
+	pm, _ := manager.LocalPackageManager(path)
+	switch pm {
+	case "apt":
+		err = verifyDeb(path, &result, opts)
+	case "zypper":
+		err = verifyRPM(path, &result, opts)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	if listed && result.Status != StatusFailed && result.Status != StatusVerified {
+		result.Status, result.Reason = StatusVerified, "checksum matches the provided one"
+	}
+	return result, nil
+}
+
+// Check returns an ErrVerificationFailed error listing the files that failed verification, and the ones that couldn't
+// be verified unless allowUnverified is set, or nil if all files were verified.
+func Check(results []Result, allowUnverified bool) error {
+	var failed []string
+	for _, result := range results {
+		if result.Status == StatusFailed || (result.Status == StatusUnverified && !allowUnverified) {
+			failed = append(failed, fmt.Sprintf("%s (%s: %s)", result.Path, result.Status, result.Reason))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrVerificationFailed, strings.Join(failed, ", "))
+}
+
+// verifyDeb looks up the checksum of the .deb file at path in the repository indexes of apt, for the package and
+// version it contains.
+func verifyDeb(path string, result *Result, opts *manager.Options) error {
+	cmd := manager.Command(opts, "dpkg-deb", "--show", "--showformat=${Package}=${Version}", path)
+	manager.SetEnv(cmd, opts, nil, "LC_ALL=C")
+	spec, err := manager.Output(cmd)
+	if err != nil {
+		return fmt.Errorf("cannot read the control file of %s: %w", path, err)
+	}
+
+	cmd = manager.Command(opts, "apt-cache", "show", string(spec))
+	manager.SetEnv(cmd, opts, nil, "LC_ALL=C")
+	// apt-cache exits with 100 when the version is not in the indexes, e.g. for packages built locally
+	out, err := manager.Output(cmd)
+	if exitErr, ok := err.(*exec.ExitError); err != nil && (!ok || exitErr.ExitCode() != 100) {
+		return err
+	}
+
+	sums := ParseAptCacheChecksums(string(out))
+	switch {
+	case len(sums) == 0:
+		result.Status, result.Reason = StatusUnverified, string(spec)+" is not in the repository indexes"
+	case slices.Contains(sums, result.SHA256):
+		result.Status, result.Signature = StatusVerified, manager.SignatureValid
+		result.Reason = "checksum matches the signed repository index"
+	default:
+		result.Status, result.Signature = StatusFailed, manager.SignatureInvalid
+		result.Reason = "checksum differs from the signed repository index for " + string(spec)
+	}
+	return nil
+}
+
+// ParseAptCacheChecksums parses the output of `apt-cache show name=version` command, and returns the SHA256 checksums
+// of the package files, one per architecture and repository.
+// Example msg:
+//
+//	Package: nano
+//	Version: 7.2-1+deb12u1
+//	Filename: pool/main/n/nano/nano_7.2-1+deb12u1_amd64.deb
+//	SHA256: 2b2e3e0e8b6f4f7c0b8a3f1a6d2c9e4b5a7f8e1d3c6b9a2f5e8d1c4b7a0f3e6d
+func ParseAptCacheChecksums(msg string) []string {
+	var sums []string
+	scanner := bufio.NewScanner(strings.NewReader(msg))
+	for scanner.Scan() {
+		if sum, found := strings.CutPrefix(scanner.Text(), "SHA256: "); found {
+			sums = append(sums, strings.ToLower(strings.TrimSpace(sum)))
+		}
+	}
+	return sums
+}
+
+// verifyRPM checks the digests and the signature of the .rpm file at path with rpm -K.
+func verifyRPM(path string, result *Result, opts *manager.Options) error {
+	cmd := manager.Command(opts, "rpm", "-K", path)
+	manager.SetEnv(cmd, opts, nil, "LC_ALL=C")
+	// rpm exits with 1 when the digests or signatures don't match
+	out, err := manager.CombinedOutput(cmd)
+	if exitErr, ok := err.(*exec.ExitError); err != nil && (!ok || exitErr.ExitCode() != 1) {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	result.Status, result.Signature = ParseCheckSigOutput(string(out))
+	result.Reason = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(out)), path+":"))
+	return nil
+}
+
+// ParseCheckSigOutput parses the output of `rpm -K path` command, and returns the status of the file and of its
+// signature: files whose digests or signatures don't match failed, files without signature are unverified.
+// Example msg:
+//
+//	vim-9.0.1632-1.1.x86_64.rpm: digests signatures OK
+//	mypackage-1.0-1.x86_64.rpm: digests OK
+//	vim-9.0.1632-1.1.x86_64.rpm: digests SIGNATURES NOT OK
+func ParseCheckSigOutput(msg string) (Status, manager.SignatureStatus) {
+	switch {
+	case strings.Contains(msg, "NOT OK"):
+		return StatusFailed, manager.SignatureInvalid
+	case strings.Contains(msg, "signatures OK"):
+		return StatusVerified, manager.SignatureValid
+	case strings.Contains(msg, "OK"):
+		return StatusUnverified, manager.SignatureUnsigned
+	}
+	return StatusFailed, manager.SignatureUnknown
+}
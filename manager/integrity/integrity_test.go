@@ -0,0 +1,118 @@
+package integrity_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/integrity"
+)
+
+// sha256 of "hello\n"
+const helloSHA256 = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+
+func TestParseChecksums(t *testing.T) {
+	var inputChecksums string = strings.Join([]string{
+		`# checksums of the release`,
+		`5891B5B522D5DF086D0FF0B110FBD9D21BB4FC7163AF34D08286A2E846F6BE03  dist/nano_7.2-1_amd64.deb`,
+		`7c211433f02071597741e6ff5a8ea34789abbf43d7d4d7fbd20c0a2bb1d3b6e0 *vim-9.0.1632-1.1.x86_64.rpm`,
+		`d41d8cd98f00b204e9800998ecf8427e  empty.md5`,
+	}, "\n")
+
+	expectedChecksums := map[string]string{
+		"nano_7.2-1_amd64.deb":        helloSHA256,
+		"vim-9.0.1632-1.1.x86_64.rpm": "7c211433f02071597741e6ff5a8ea34789abbf43d7d4d7fbd20c0a2bb1d3b6e0",
+	}
+
+	actualChecksums := integrity.ParseChecksums(inputChecksums)
+	if !reflect.DeepEqual(expectedChecksums, actualChecksums) {
+		t.Errorf("ParseChecksums() = %+v, want %+v", actualChecksums, expectedChecksums)
+	}
+}
+
+func TestParseAptCacheChecksums(t *testing.T) {
+	var inputAptCacheShow string = strings.Join([]string{
+		`Package: nano`,
+		`Version: 7.2-1+deb12u1`,
+		`Filename: pool/main/n/nano/nano_7.2-1+deb12u1_amd64.deb`,
+		`SHA256: 2B2E3E0E8B6F4F7C0B8A3F1A6D2C9E4B5A7F8E1D3C6B9A2F5E8D1C4B7A0F3E6D`,
+		``,
+		`Package: nano`,
+		`Version: 7.2-1+deb12u1`,
+		`Filename: pool/main/n/nano/nano_7.2-1+deb12u1_amd64.deb`,
+		`SHA256: 7c211433f02071597741e6ff5a8ea34789abbf43d7d4d7fbd20c0a2bb1d3b6e0`,
+	}, "\n")
+
+	expectedSums := []string{
+		"2b2e3e0e8b6f4f7c0b8a3f1a6d2c9e4b5a7f8e1d3c6b9a2f5e8d1c4b7a0f3e6d",
+		"7c211433f02071597741e6ff5a8ea34789abbf43d7d4d7fbd20c0a2bb1d3b6e0",
+	}
+
+	actualSums := integrity.ParseAptCacheChecksums(inputAptCacheShow)
+	if !reflect.DeepEqual(expectedSums, actualSums) {
+		t.Errorf("ParseAptCacheChecksums() = %+v, want %+v", actualSums, expectedSums)
+	}
+}
+
+func TestParseCheckSigOutput(t *testing.T) {
+	tests := []struct {
+		msg       string
+		status    integrity.Status
+		signature manager.SignatureStatus
+	}{
+		{"vim-9.0.1632-1.1.x86_64.rpm: digests signatures OK\n", integrity.StatusVerified, manager.SignatureValid},
+		{"mypackage-1.0-1.x86_64.rpm: digests OK\n", integrity.StatusUnverified, manager.SignatureUnsigned},
+		{"vim-9.0.1632-1.1.x86_64.rpm: digests SIGNATURES NOT OK\n", integrity.StatusFailed, manager.SignatureInvalid},
+		{"error: broken.rpm: not an rpm package\n", integrity.StatusFailed, manager.SignatureUnknown},
+	}
+	for _, tt := range tests {
+		status, signature := integrity.ParseCheckSigOutput(tt.msg)
+		if status != tt.status || signature != tt.signature {
+			t.Errorf("ParseCheckSigOutput(%q) = %q, %q, want %q, %q", tt.msg, status, signature, tt.status, tt.signature)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello.flatpakref")
+	if err := os.WriteFile(path, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		checksums map[string]string
+		status    integrity.Status
+	}{
+		{nil, integrity.StatusUnsupported},
+		{map[string]string{"hello.flatpakref": helloSHA256}, integrity.StatusVerified},
+		{map[string]string{"hello.flatpakref": strings.Repeat("0", 64)}, integrity.StatusFailed},
+	}
+	for _, tt := range tests {
+		result, err := integrity.Verify(path, tt.checksums, &manager.Options{})
+		if err != nil || result.Status != tt.status || result.SHA256 != helloSHA256 {
+			t.Errorf("Verify(%+v) = %+v, %+v, want status %q", tt.checksums, result, err, tt.status)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	results := []integrity.Result{
+		{Path: "nano_7.2-1_amd64.deb", Status: integrity.StatusVerified},
+		{Path: "mypackage_1.0_amd64.deb", Status: integrity.StatusUnverified, Reason: "mypackage=1.0 is not in the repository indexes"},
+	}
+	if err := integrity.Check(results, true); err != nil {
+		t.Errorf("Check(allowUnverified) = %+v, want nil", err)
+	}
+	if err := integrity.Check(results, false); !errors.Is(err, integrity.ErrVerificationFailed) {
+		t.Errorf("Check() = %+v, want %+v", err, integrity.ErrVerificationFailed)
+	}
+
+	results = append(results, integrity.Result{Path: "vim.rpm", Status: integrity.StatusFailed})
+	if err := integrity.Check(results, true); !errors.Is(err, integrity.ErrVerificationFailed) {
+		t.Errorf("Check(allowUnverified) = %+v, want %+v", err, integrity.ErrVerificationFailed)
+	}
+}
@@ -0,0 +1,269 @@
+// Package yaml parses the subset of YAML used by the configuration files of syspkg, such as manifests:
+// block mappings and sequences, flow sequences of scalars ([a, b]), plain and quoted scalars, and comments.
+//
+// This package is part of the syspkg library.
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a significant line of a YAML document, without its indentation and comment.
+type yamlLine struct {
+	indent int
+	text   string
+	num    int
+}
+
+// yamlParser parses the supported subset of YAML. Anchors, tags, multi-line scalars and flow mappings are not supported.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// Parse parses a YAML document of the supported subset.
+// Mappings are returned as map[string]any, sequences as []any, and scalars as string (or nil for empty values).
+func Parse(data []byte) (any, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, " \r")
+		text := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", i+1)
+		}
+		indent := len(raw) - len(text)
+		text = stripComment(text)
+		if text == "" || text == "---" {
+			continue
+		}
+		p.lines = append(p.lines, yamlLine{indent: indent, text: text, num: i + 1})
+	}
+
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+	value, err := p.parseNode(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	return value, nil
+}
+
+// parseNode parses the block mapping or sequence starting at the current line.
+func (p *yamlParser) parseNode(indent int) (any, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+// parseSequence parses the items of a block sequence at the given indentation.
+func (p *yamlParser) parseSequence(indent int) ([]any, error) {
+	items := []any{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent || (l.indent == indent && !isSequenceItem(l.text)) {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+		switch {
+		case rest == "":
+			p.pos++
+			var item any
+			if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+				var err error
+				if item, err = p.parseNode(p.lines[p.pos].indent); err != nil {
+					return nil, err
+				}
+			}
+			items = append(items, item)
+		case isMappingEntry(rest):
+			// the item is a mapping starting on the same line as the dash: parse it at the indentation of its first key
+			p.lines[p.pos] = yamlLine{indent: indent + len(l.text) - len(rest), text: rest, num: l.num}
+			item, err := p.parseMapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		default:
+			item, err := parseValue(rest, l.num)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+// parseMapping parses the entries of a block mapping at the given indentation.
+func (p *yamlParser) parseMapping(indent int) (map[string]any, error) {
+	m := make(map[string]any)
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+		}
+
+		key, value, ok := splitMappingEntry(l.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\", got %q", l.num, l.text)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", l.num, key)
+		}
+		p.pos++
+
+		if value != "" {
+			v, err := parseValue(value, l.num)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			continue
+		}
+
+		// a block value is indented further, except sequences, which may be at the same indentation as their key
+		m[key] = nil
+		if p.pos < len(p.lines) {
+			next := p.lines[p.pos]
+			if next.indent > indent || (next.indent == indent && isSequenceItem(next.text)) {
+				v, err := p.parseNode(next.indent)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = v
+			}
+		}
+	}
+	return m, nil
+}
+
+// parseValue parses an inline value: a flow sequence of scalars, or a scalar.
+func parseValue(s string, num int) (any, error) {
+	switch {
+	case strings.HasPrefix(s, "{"):
+		return nil, fmt.Errorf("line %d: flow mappings are not supported", num)
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("line %d: unterminated flow sequence", num)
+		}
+		items := []any{}
+		inner := strings.TrimSpace(s[1 : len(s)-1])
+		if inner == "" {
+			return items, nil
+		}
+		for _, field := range strings.Split(inner, ",") {
+			item, err := parseScalar(strings.TrimSpace(field), num)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return parseScalar(s, num)
+	}
+}
+
+// parseScalar parses a plain, single-quoted or double-quoted scalar.
+func parseScalar(s string, num int) (any, error) {
+	switch {
+	case s == "" || s == "~" || s == "null":
+		return nil, nil
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid double-quoted string %s", num, s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("line %d: invalid single-quoted string %s", num, s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	default:
+		return s, nil
+	}
+}
+
+// splitMappingEntry splits a "key: value" line, where the key may be quoted.
+func splitMappingEntry(s string) (string, string, bool) {
+	var key, rest string
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+		end := strings.Index(s[1:], s[:1])
+		if end < 0 {
+			return "", "", false
+		}
+		key, rest = s[1:end+1], s[end+2:]
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		rest = rest[1:]
+	} else {
+		idx := strings.Index(s, ": ")
+		switch {
+		case idx > 0:
+			key, rest = s[:idx], s[idx+1:]
+		case strings.HasSuffix(s, ":"):
+			key = s[:len(s)-1]
+		default:
+			return "", "", false
+		}
+	}
+
+	if rest != "" && !strings.HasPrefix(rest, " ") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(rest), true
+}
+
+// isMappingEntry reports whether an inline sequence item starts a mapping.
+func isMappingEntry(s string) bool {
+	if strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{") {
+		return false
+	}
+	_, _, ok := splitMappingEntry(s)
+	return ok
+}
+
+// isSequenceItem reports whether a line is an item of a block sequence.
+func isSequenceItem(s string) bool {
+	return s == "-" || strings.HasPrefix(s, "- ")
+}
+
+// stripComment removes a trailing comment from a line, ignoring "#" inside quoted strings, including after escaped
+// quotes, or not preceded by a space.
+// Only quotes starting a value open a quoted string, so that apostrophes inside plain scalars are kept as is.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				// the escaped character, such as \", doesn't end the double-quoted string
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" [,", s[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' '):
+			return strings.TrimRight(s[:i], " ")
+		}
+	}
+	return s
+}
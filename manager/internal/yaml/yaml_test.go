@@ -0,0 +1,111 @@
+package yaml_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager/internal/yaml"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want any
+	}{
+		{
+			name: "nested mappings and sequences",
+			data: `# a manifest
+---
+packages:
+  apt:
+    - vim
+    - name: nginx
+      version: "1.24"
+  snap: [code, 'go']
+exclude:
+- kernel*
+hooks:
+`,
+			want: map[string]any{
+				"packages": map[string]any{
+					"apt":  []any{"vim", map[string]any{"name": "nginx", "version": "1.24"}},
+					"snap": []any{"code", "go"},
+				},
+				"exclude": []any{"kernel*"},
+				"hooks":   nil,
+			},
+		},
+		{
+			name: "scalars",
+			data: `plain: it's plain # a comment
+hash: C# not a comment
+double: "a \"quoted\" # value\n"
+single: 'it''s'
+empty: ""
+tilde: ~
+null: null
+flow: []
+"quoted key": value
+`,
+			want: map[string]any{
+				"plain":      "it's plain",
+				"hash":       "C# not a comment",
+				"double":     "a \"quoted\" # value\n",
+				"single":     "it's",
+				"empty":      "",
+				"tilde":      nil,
+				"null":       nil,
+				"flow":       []any{},
+				"quoted key": "value",
+			},
+		},
+		{
+			name: "sequence of sequences",
+			data: "-\n  - a\n  - b\n-\n- c\n",
+			want: []any{[]any{"a", "b"}, nil, "c"},
+		},
+		{
+			name: "empty document",
+			data: "# nothing\n\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yaml.Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %+v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "tab indentation", data: "a:\n\t- b\n", want: "line 2: tabs are not allowed in indentation"},
+		{name: "unexpected indentation", data: "a: b\n  c: d\n", want: "line 2: unexpected indentation"},
+		{name: "not a mapping entry", data: "a: b\nc\n", want: `line 2: expected "key: value", got "c"`},
+		{name: "duplicate key", data: "a: b\na: c\n", want: `line 2: duplicate key "a"`},
+		{name: "flow mapping", data: "a: {b: c}\n", want: "line 1: flow mappings are not supported"},
+		{name: "unterminated flow sequence", data: "a: [b, c\n", want: "line 1: unterminated flow sequence"},
+		{name: "invalid double-quoted string", data: "a: \"b\n", want: "line 1: invalid double-quoted string"},
+		{name: "invalid single-quoted string", data: "- 'b\n", want: "line 1: invalid single-quoted string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := yaml.Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %+v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+// KeyInfo contains information about a signing key trusted by a package manager to verify its repositories.
+type KeyInfo struct {
+	// ID identifies the key for the package manager, e.g. the fingerprint of an OpenPGP key, used to remove it.
+	ID string
+
+	// UserID is the owner of the key, such as "Docker Release (CE deb) <docker@docker.com>".
+	UserID string
+
+	// Keyring is where the key is stored, such as the keyring file or the remote using it. It can be empty.
+	Keyring string
+
+	// PackageManager is the name of the package manager trusting this key, such as "apt" or "zypper".
+	PackageManager string
+}
@@ -0,0 +1,64 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnknownPackageFile is returned for files that are not package files of a supported package manager.
+var ErrUnknownPackageFile = errors.New("unknown package file type")
+
+// LocalPackageTypes maps the extensions of package files to the package managers installing them.
+var LocalPackageTypes = map[string]string{
+	".deb":         "apt",
+	".rpm":         "zypper",
+	".apk":         "apk",
+	".pkg.tar.zst": "pacman",
+	".pkg.tar.xz":  "pacman",
+	".pkg.tar.gz":  "pacman",
+	".flatpakref":  "flatpak",
+	".flatpak":     "flatpak",
+	".snap":        "snap",
+}
+
+// LocalPackageManager returns the name of the package manager installing the package file at path, detected from its extension.
+func LocalPackageManager(path string) (string, error) {
+	name := strings.ToLower(filepath.Base(path))
+	for ext, pm := range LocalPackageTypes {
+		if strings.HasSuffix(name, ext) {
+			return pm, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownPackageFile, path)
+}
+
+// LocalPackageFiles checks that the package files at paths exist and are installed by the package manager pm,
+// and returns their absolute paths, which package managers tell apart from package names.
+func LocalPackageFiles(paths []string, pm string) ([]string, error) {
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if filePM, err := LocalPackageManager(path); err != nil {
+			return nil, err
+		} else if filePM != pm {
+			return nil, fmt.Errorf("%w: %s is a package file of %s, not %s", ErrUnknownPackageFile, path, filePM, pm)
+		}
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory, not a package file", path)
+		}
+		files = append(files, abs)
+	}
+	return files, nil
+}
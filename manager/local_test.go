@@ -0,0 +1,55 @@
+package manager_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestLocalPackageManager(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr error
+	}{
+		{path: "./debs/vim_2%3a8.2.3995-1ubuntu2.15_amd64.deb", want: "apt"},
+		{path: "/tmp/vim-9.0.1632-1.1.x86_64.rpm", want: "zypper"},
+		{path: "vim-9.0.2073-r0.apk", want: "apk"},
+		{path: "vim-9.0.2153-1-x86_64.pkg.tar.zst", want: "pacman"},
+		{path: "org.gimp.GIMP.flatpakref", want: "flatpak"},
+		{path: "org.gimp.GIMP.flatpak", want: "flatpak"},
+		{path: "hello_42.snap", want: "snap"},
+		{path: "vim.tar.gz", wantErr: manager.ErrUnknownPackageFile},
+	}
+	for _, tt := range tests {
+		got, err := manager.LocalPackageManager(tt.path)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("LocalPackageManager(%q) = %q, %+v, want %q, %+v", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLocalPackageFiles(t *testing.T) {
+	dir := t.TempDir()
+	deb := filepath.Join(dir, "vim_9.0_amd64.deb")
+	if err := os.WriteFile(deb, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := manager.LocalPackageFiles([]string{deb}, "apt")
+	want := []string{deb}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("LocalPackageFiles() = %+v, %+v, want %+v", got, err, want)
+	}
+
+	if _, err := manager.LocalPackageFiles([]string{deb}, "zypper"); !errors.Is(err, manager.ErrUnknownPackageFile) {
+		t.Errorf("LocalPackageFiles() error = %+v, want %+v", err, manager.ErrUnknownPackageFile)
+	}
+	if _, err := manager.LocalPackageFiles([]string{filepath.Join(dir, "missing.deb")}, "apt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LocalPackageFiles() error = %+v, want %+v", err, os.ErrNotExist)
+	}
+}
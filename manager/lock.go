@@ -0,0 +1,67 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// ErrLocked is returned, wrapped, when an operation failed because another process, such as unattended-upgrades,
+// holds the lock of the package manager, and didn't release it within Options.LockWait.
+var ErrLocked = errors.New("the package manager is locked by another process")
+
+// maxLockDelay is the longest delay between two attempts of a command failing because of a lock.
+const maxLockDelay = 30 * time.Second
+
+// RetryLocked runs cmd with run, and while it fails because the package manager is locked by another process, as
+// reported by locked, runs it again after a growing delay, until Options.LockWait elapsed since the first attempt.
+// The error of a command still failing because of the lock wraps ErrLocked.
+func RetryLocked(cmd *exec.Cmd, opts *Options, locked func(err error) bool, run func(cmd *exec.Cmd) ([]byte, error)) ([]byte, error) {
+	var wait time.Duration
+	if opts != nil {
+		wait = opts.LockWait
+	}
+	deadline := time.Now().Add(wait)
+	delay := time.Second
+	for {
+		out, err := run(cmd)
+		if err == nil || !locked(err) {
+			return out, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return out, fmt.Errorf("%w: %s", ErrLocked, lockMessage(err))
+		}
+		if delay > remaining {
+			delay = remaining
+		}
+		opts.Log().Warn("Waiting for the lock of the package manager", "command", cmd.Args[len(commandPrefix(opts))], "retry_in", delay, "error", lockMessage(err))
+		select {
+		case <-time.After(delay):
+		case <-opts.OperationContext().Done():
+			return out, fmt.Errorf("%w while waiting: %s", ErrInterrupted, lockMessage(err))
+		}
+		if delay *= 2; delay > maxLockDelay {
+			delay = maxLockDelay
+		}
+
+		// a command can only run once, so the next attempt runs a copy of it
+		cmd = Rerun(opts, cmd)
+	}
+}
+
+// lockMessage returns the first line of the standard error of a command failing because of a lock, which usually
+// tells which process holds it, or the error itself.
+func lockMessage(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if line, _, _ := bytes.Cut(bytes.TrimSpace(exitErr.Stderr), []byte("\n")); len(line) > 0 {
+			return string(line)
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,42 @@
+package manager_test
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestRetryLocked(t *testing.T) {
+	locked := func(err error) bool {
+		var exitErr *exec.ExitError
+		return errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "Could not get lock")
+	}
+	run := func(cmd *exec.Cmd) ([]byte, error) { return cmd.Output() }
+
+	// the lock is released after the first attempt
+	marker := filepath.Join(t.TempDir(), "attempted")
+	script := `if [ -e "$1" ]; then echo done; else touch "$1"; echo "E: Could not get lock /var/lib/dpkg/lock-frontend" >&2; exit 100; fi`
+	opts := &manager.Options{LockWait: 5 * time.Second}
+	out, err := manager.RetryLocked(exec.Command("sh", "-c", script, "sh", marker), opts, locked, run)
+	if err != nil || string(out) != "done\n" {
+		t.Errorf("RetryLocked() = %q, %+v, want %q", out, err, "done\n")
+	}
+
+	// the lock is never released
+	cmd := exec.Command("sh", "-c", `echo "E: Could not get lock /var/lib/dpkg/lock-frontend. It is held by process 42 (unattended-upgr)" >&2; exit 100`)
+	_, err = manager.RetryLocked(cmd, &manager.Options{}, locked, run)
+	if !errors.Is(err, manager.ErrLocked) || !strings.Contains(err.Error(), "unattended-upgr") {
+		t.Errorf("RetryLocked() error = %+v, want %+v with the holder of the lock", err, manager.ErrLocked)
+	}
+
+	// other failures are not retried
+	_, err = manager.RetryLocked(exec.Command("sh", "-c", "exit 1"), opts, locked, run)
+	if err == nil || errors.Is(err, manager.ErrLocked) {
+		t.Errorf("RetryLocked() error = %+v, want the exit error", err)
+	}
+}
@@ -0,0 +1,23 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import "log/slog"
+
+// Logger receives the logs of the package managers: the commands they run, warnings, and with Options.Verbose, the
+// output of the commands at the debug level. Its methods take a message followed by alternating keys and values,
+// such as "package_manager", "apt". *slog.Logger implements it.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Log returns the logger of the options: Logger if set, otherwise the default slog logger.
+// It can be called on nil options.
+func (o *Options) Log() Logger {
+	if o == nil || o.Logger == nil {
+		return slog.Default()
+	}
+	return o.Logger
+}
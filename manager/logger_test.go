@@ -0,0 +1,31 @@
+package manager_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/apt"
+)
+
+func TestOptionsLog(t *testing.T) {
+	var opts *manager.Options
+	if got := opts.Log(); got != slog.Default() {
+		t.Errorf("Log() = %+v, want the default slog logger", got)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	opts = &manager.Options{Verbose: true, Logger: logger}
+	if got := opts.Log(); got != logger {
+		t.Errorf("Log() = %+v, want %+v", got, logger)
+	}
+
+	apt.ParseInstallOutput("Setting up vim (2:8.2.3995-1ubuntu2.16) ...\n", opts)
+	want := `"package_manager":"apt","line":"Setting up vim (2:8.2.3995-1ubuntu2.16) ..."`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("ParseInstallOutput() logged %s, want %s", buf.String(), want)
+	}
+}
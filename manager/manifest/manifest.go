@@ -0,0 +1,391 @@
+// Package manifest reads declarative package manifests, which list the desired packages of each package manager,
+// and plans the operations needed to converge a system to them.
+//
+// A manifest is a YAML (or JSON) document such as:
+//
+//	version: 1
+//	packages:
+//	  apt:
+//	    - vim
+//	    - name: curl
+//	      version: ">= 7.81"
+//	    - name: nano
+//	      state: absent
+//	  npm: [typescript, eslint]
+//	  pip:
+//	    - name: requests
+//	      state: latest
+//
+// Packages are either a name, or a mapping with a name, an optional version constraint
+// (a version, optionally prefixed by one of =, ==, !=, <, <=, >, >=), and an optional state:
+// present (the default), absent or latest. Packages not listed in the manifest are left untouched.
+//
+// This package is part of the syspkg library.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/internal/yaml"
+)
+
+// Version is the manifest schema version supported by this package.
+const Version = 1
+
+// ErrInvalidManifest is returned, wrapped with the details, for manifests that don't match the schema.
+var ErrInvalidManifest = errors.New("manifest: invalid manifest")
+
+// State is the desired state of a package.
+type State string
+
+// States of a package in a manifest.
+const (
+	StatePresent State = "present"
+	StateAbsent  State = "absent"
+	StateLatest  State = "latest"
+)
+
+// Manifest is the desired set of packages, by package manager.
+type Manifest struct {
+	// Version is the schema version of the manifest.
+	Version int
+
+	// Packages are the desired packages, by package manager name.
+	Packages map[string][]Package
+}
+
+// Package is the desired state of a package.
+type Package struct {
+	// Name is the name of the package.
+	Name string
+
+	// Constraint is the version constraint of the package, if any.
+	Constraint Constraint
+
+	// State is the desired state of the package.
+	State State
+}
+
+// Constraint is a version constraint, such as ">= 7.81".
+type Constraint struct {
+	// Operator is one of =, !=, <, <=, >, >=, or empty for no constraint.
+	Operator string
+
+	// Version is the version to compare to.
+	Version string
+}
+
+// operators are the supported constraint operators, longest first so that prefixes are matched correctly.
+var operators = []string{"==", "!=", "<=", ">=", "=", "<", ">"}
+
+// ParseConstraint parses a version constraint. A version without operator means an exact version.
+func ParseConstraint(s string) (Constraint, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Constraint{}, nil
+	}
+
+	op := "="
+	for _, candidate := range operators {
+		if strings.HasPrefix(s, candidate) {
+			op, s = candidate, strings.TrimSpace(s[len(candidate):])
+			break
+		}
+	}
+	if op == "==" {
+		op = "="
+	}
+	if s == "" || strings.ContainsAny(s, " <>=!") {
+		return Constraint{}, fmt.Errorf("invalid version constraint %q", op+s)
+	}
+	return Constraint{Operator: op, Version: s}, nil
+}
+
+// IsZero reports whether there is no constraint.
+func (c Constraint) IsZero() bool {
+	return c.Operator == ""
+}
+
+// String returns the constraint as written in a manifest.
+func (c Constraint) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return c.Operator + " " + c.Version
+}
+
+// Satisfied reports whether the version satisfies the constraint, using manager.CompareVersions.
+func (c Constraint) Satisfied(version string) bool {
+	if c.IsZero() {
+		return true
+	}
+
+	cmp := manager.CompareVersions(version, c.Version)
+	switch c.Operator {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// Load reads and parses the manifest file at path.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates a manifest. JSON documents are parsed as JSON, anything else as YAML.
+func Parse(data []byte) (*Manifest, error) {
+	var doc any
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) {
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+		}
+	} else {
+		var err error
+		if doc, err = yaml.Parse(data); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+		}
+	}
+
+	m, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	return m, nil
+}
+
+// decode validates a parsed document against the manifest schema and converts it to a Manifest.
+func decode(doc any) (*Manifest, error) {
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, errors.New("the manifest must be a mapping")
+	}
+	for key := range root {
+		if key != "version" && key != "packages" {
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+	}
+
+	m := &Manifest{Packages: make(map[string][]Package)}
+	version, err := scalar(root["version"], "version")
+	if err != nil {
+		return nil, err
+	}
+	if m.Version, err = strconv.Atoi(version); err != nil || m.Version != Version {
+		return nil, fmt.Errorf("version: unsupported manifest version %q, expected %d", version, Version)
+	}
+
+	managers, ok := root["packages"].(map[string]any)
+	if !ok {
+		return nil, errors.New("packages: expected a mapping of package managers to packages")
+	}
+	for pm, value := range managers {
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("packages.%s: expected a list of packages", pm)
+		}
+
+		seen := make(map[string]bool)
+		for i, item := range items {
+			path := fmt.Sprintf("packages.%s[%d]", pm, i)
+			pkg, err := decodePackage(item, path)
+			if err != nil {
+				return nil, err
+			}
+			if seen[pkg.Name] {
+				return nil, fmt.Errorf("%s: duplicate package %q", path, pkg.Name)
+			}
+			seen[pkg.Name] = true
+			m.Packages[pm] = append(m.Packages[pm], pkg)
+		}
+	}
+	return m, nil
+}
+
+// decodePackage converts a package entry, either a name or a mapping, to a Package.
+func decodePackage(item any, path string) (Package, error) {
+	pkg := Package{State: StatePresent}
+
+	fields, ok := item.(map[string]any)
+	if !ok {
+		name, err := scalar(item, path)
+		if err != nil || name == "" {
+			return pkg, fmt.Errorf("%s: expected a package name or a mapping", path)
+		}
+		pkg.Name = name
+		return pkg, nil
+	}
+
+	for key, value := range fields {
+		s, err := scalar(value, path+"."+key)
+		if err != nil {
+			return pkg, err
+		}
+		switch key {
+		case "name":
+			pkg.Name = s
+		case "version":
+			if pkg.Constraint, err = ParseConstraint(s); err != nil {
+				return pkg, fmt.Errorf("%s.version: %v", path, err)
+			}
+		case "state":
+			if s != "" {
+				pkg.State = State(s)
+			}
+		default:
+			return pkg, fmt.Errorf("%s: unknown field %q", path, key)
+		}
+	}
+
+	switch {
+	case pkg.Name == "":
+		return pkg, fmt.Errorf("%s.name: missing package name", path)
+	case pkg.State != StatePresent && pkg.State != StateAbsent && pkg.State != StateLatest:
+		return pkg, fmt.Errorf("%s.state: invalid state %q, expected present, absent or latest", path, pkg.State)
+	case pkg.State != StatePresent && !pkg.Constraint.IsZero():
+		return pkg, fmt.Errorf("%s.version: a version constraint can only be used with the present state", path)
+	}
+	return pkg, nil
+}
+
+// scalar returns a scalar value of a parsed document as a string.
+func scalar(value any, path string) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("%s: expected a scalar value", path)
+	}
+}
+
+// Action is the kind of change needed to converge a package to its desired state.
+type Action string
+
+// Actions computed by Plan.
+const (
+	ActionInstall   Action = "install"
+	ActionDelete    Action = "delete"
+	ActionUpgrade   Action = "upgrade"
+	ActionDowngrade Action = "downgrade"
+)
+
+// Step is a change to a package needed to converge to a manifest.
+type Step struct {
+	// PackageManager is the name of the package manager of the package.
+	PackageManager string `json:"package_manager"`
+
+	// Action is the change to perform.
+	Action Action `json:"action"`
+
+	// Package is the name of the package.
+	Package string `json:"package"`
+
+	// CurrentVersion is the currently installed version of the package, if any.
+	CurrentVersion string `json:"current_version,omitempty"`
+
+	// Version is the version the package is expected to be at after the change, if known.
+	Version string `json:"version,omitempty"`
+
+	// Reason explains why the change is needed.
+	Reason string `json:"reason"`
+}
+
+// Plan computes the steps that converge the packages of a package manager to the manifest, given its installed and
+// upgradable packages. Held packages are never upgraded. Packages with an unsatisfied version constraint are upgraded when the available version
+// satisfies it or the installed version is too old, and downgraded otherwise. Steps are sorted by package name.
+func (m *Manifest) Plan(pm string, installed []manager.PackageInfo, upgradable []manager.PackageInfo) []Step {
+	current := make(map[string]string)
+	for _, pkg := range installed {
+		current[pkg.Name] = pkg.Version
+	}
+	available := make(map[string]string)
+	for _, pkg := range upgradable {
+		// held packages are not upgraded by their package manager
+		if pkg.Status == manager.PackageStatusHeld {
+			continue
+		}
+		available[pkg.Name] = pkg.NewVersion
+	}
+
+	var steps []Step
+	for _, pkg := range m.Packages[pm] {
+		version, isInstalled := current[pkg.Name]
+		step := Step{PackageManager: pm, Package: pkg.Name, CurrentVersion: version}
+
+		switch {
+		case pkg.State == StateAbsent:
+			if !isInstalled {
+				continue
+			}
+			step.Action, step.Reason = ActionDelete, "absent in the manifest"
+		case !isInstalled:
+			step.Action, step.Reason = ActionInstall, "not installed"
+			if !pkg.Constraint.IsZero() {
+				step.Reason += ", version " + pkg.Constraint.String()
+			}
+		case pkg.State == StateLatest:
+			newVersion, ok := available[pkg.Name]
+			if !ok {
+				continue
+			}
+			step.Action, step.Version, step.Reason = ActionUpgrade, newVersion, "newer version available"
+		case !pkg.Constraint.Satisfied(version):
+			step.Reason = "version " + pkg.Constraint.String() + " required"
+			newVersion, ok := available[pkg.Name]
+			switch {
+			case ok && pkg.Constraint.Satisfied(newVersion):
+				step.Action, step.Version = ActionUpgrade, newVersion
+			case manager.CompareVersions(version, pkg.Constraint.Version) < 0:
+				step.Action = ActionUpgrade
+			default:
+				step.Action = ActionDowngrade
+				if pkg.Constraint.Operator == "=" {
+					step.Version = pkg.Constraint.Version
+				}
+			}
+		default:
+			continue
+		}
+		steps = append(steps, step)
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Package < steps[j].Package })
+	return steps
+}
+
+// PackageManagers returns the names of the package managers listed in the manifest, sorted.
+func (m *Manifest) PackageManagers() []string {
+	var names []string
+	for name := range m.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
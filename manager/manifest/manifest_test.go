@@ -0,0 +1,133 @@
+package manifest_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/manifest"
+)
+
+func TestParse(t *testing.T) {
+	var inputManifest string = strings.Join([]string{
+		`# workstation packages`,
+		`---`,
+		`version: 1`,
+		`packages:`,
+		`  apt:`,
+		`    - vim`,
+		`    - name: curl`,
+		`      version: ">= 7.81"`,
+		`    - name: nano # not needed`,
+		`      state: absent`,
+		`  npm: [typescript, 'eslint']`,
+		`  pip:`,
+		`  - name: requests`,
+		`    state: latest`,
+	}, "\n")
+
+	var expectedManifest = &manifest.Manifest{
+		Version: 1,
+		Packages: map[string][]manifest.Package{
+			"apt": {
+				{Name: "vim", State: manifest.StatePresent},
+				{Name: "curl", Constraint: manifest.Constraint{Operator: ">=", Version: "7.81"}, State: manifest.StatePresent},
+				{Name: "nano", State: manifest.StateAbsent},
+			},
+			"npm": {
+				{Name: "typescript", State: manifest.StatePresent},
+				{Name: "eslint", State: manifest.StatePresent},
+			},
+			"pip": {
+				{Name: "requests", State: manifest.StateLatest},
+			},
+		},
+	}
+
+	actualManifest, err := manifest.Parse([]byte(inputManifest))
+	if err != nil {
+		t.Fatalf("Parse() error = %+v", err)
+	}
+	if !reflect.DeepEqual(expectedManifest, actualManifest) {
+		t.Errorf("Parse() = %+v, want %+v", actualManifest, expectedManifest)
+	}
+
+	actualManifest, err = manifest.Parse([]byte(`{"version": 1, "packages": {"apt": ["vim", {"name": "curl", "version": ">= 7.81"}, {"name": "nano", "state": "absent"}], "npm": ["typescript", "eslint"], "pip": [{"name": "requests", "state": "latest"}]}}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %+v", err)
+	}
+	if !reflect.DeepEqual(expectedManifest, actualManifest) {
+		t.Errorf("Parse() = %+v, want %+v", actualManifest, expectedManifest)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{name: "unsupported version", manifest: "version: 2\npackages:\n  apt: [vim]\n"},
+		{name: "unknown field", manifest: "version: 1\npkgs:\n  apt: [vim]\n"},
+		{name: "invalid state", manifest: "version: 1\npackages:\n  apt:\n    - name: vim\n      state: gone\n"},
+		{name: "constraint on absent package", manifest: "version: 1\npackages:\n  apt:\n    - name: vim\n      version: 9.0\n      state: absent\n"},
+		{name: "invalid constraint", manifest: "version: 1\npackages:\n  apt:\n    - name: vim\n      version: '>= '\n"},
+		{name: "duplicate package", manifest: "version: 1\npackages:\n  apt: [vim, vim]\n"},
+		{name: "bad indentation", manifest: "version: 1\npackages:\n  apt:\n    - vim\n      - curl\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := manifest.Parse([]byte(tt.manifest)); !errors.Is(err, manifest.ErrInvalidManifest) {
+				t.Errorf("Parse() error = %+v, want %+v", err, manifest.ErrInvalidManifest)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	m := &manifest.Manifest{
+		Version: 1,
+		Packages: map[string][]manifest.Package{
+			"apt": {
+				{Name: "vim", State: manifest.StatePresent},
+				{Name: "curl", Constraint: manifest.Constraint{Operator: ">=", Version: "7.81"}, State: manifest.StatePresent},
+				{Name: "nano", State: manifest.StateAbsent},
+				{Name: "openssl", State: manifest.StateLatest},
+				{Name: "htop", Constraint: manifest.Constraint{Operator: "<", Version: "3"}, State: manifest.StatePresent},
+				{Name: "git", State: manifest.StatePresent},
+				{Name: "tmux", State: manifest.StateLatest},
+				{Name: "less", Constraint: manifest.Constraint{Operator: "=", Version: "551-3ubuntu0.1"}, State: manifest.StatePresent},
+			},
+		},
+	}
+
+	installed := []manager.PackageInfo{
+		{Name: "curl", Version: "7.68.0-1ubuntu2.21"},
+		{Name: "nano", Version: "6.2-1"},
+		{Name: "openssl", Version: "3.0.2-0ubuntu1.12"},
+		{Name: "htop", Version: "3.0.5-7build2"},
+		{Name: "git", Version: "1:2.34.1-1ubuntu1.10"},
+		{Name: "tmux", Version: "3.2a-4ubuntu0.1"},
+		{Name: "less", Version: "590-2ubuntu2.1"},
+	}
+	upgradable := []manager.PackageInfo{
+		{Name: "curl", Version: "7.68.0-1ubuntu2.21", NewVersion: "7.81.0-1ubuntu1.15"},
+		{Name: "openssl", Version: "3.0.2-0ubuntu1.12", NewVersion: "3.0.2-0ubuntu1.14"},
+		{Name: "tmux", Version: "3.2a-4ubuntu0.1", NewVersion: "3.2a-4ubuntu0.2", Status: manager.PackageStatusHeld},
+	}
+
+	expectedSteps := []manifest.Step{
+		{PackageManager: "apt", Action: manifest.ActionUpgrade, Package: "curl", CurrentVersion: "7.68.0-1ubuntu2.21", Version: "7.81.0-1ubuntu1.15", Reason: "version >= 7.81 required"},
+		{PackageManager: "apt", Action: manifest.ActionDowngrade, Package: "htop", CurrentVersion: "3.0.5-7build2", Reason: "version < 3 required"},
+		{PackageManager: "apt", Action: manifest.ActionDowngrade, Package: "less", CurrentVersion: "590-2ubuntu2.1", Version: "551-3ubuntu0.1", Reason: "version = 551-3ubuntu0.1 required"},
+		{PackageManager: "apt", Action: manifest.ActionDelete, Package: "nano", CurrentVersion: "6.2-1", Reason: "absent in the manifest"},
+		{PackageManager: "apt", Action: manifest.ActionUpgrade, Package: "openssl", CurrentVersion: "3.0.2-0ubuntu1.12", Version: "3.0.2-0ubuntu1.14", Reason: "newer version available"},
+		{PackageManager: "apt", Action: manifest.ActionInstall, Package: "vim", Reason: "not installed"},
+	}
+
+	actualSteps := m.Plan("apt", installed, upgradable)
+	if !reflect.DeepEqual(expectedSteps, actualSteps) {
+		t.Errorf("Plan() = %+v, want %+v", actualSteps, expectedSteps)
+	}
+}
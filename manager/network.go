@@ -0,0 +1,39 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+// ProxyEnv returns the environment variables making commands download through Options.Proxy, except from the hosts
+// of Options.NoProxy, or nil if no proxy is set. Both the lowercase and uppercase variables are set, as tools differ
+// on which ones they honor. It can be called on nil options.
+func ProxyEnv(opts *Options) []string {
+	if opts == nil || opts.Proxy == "" {
+		return nil
+	}
+	env := []string{
+		"http_proxy=" + opts.Proxy,
+		"https_proxy=" + opts.Proxy,
+		"HTTP_PROXY=" + opts.Proxy,
+		"HTTPS_PROXY=" + opts.Proxy,
+	}
+	if opts.NoProxy != "" {
+		env = append(env, "no_proxy="+opts.NoProxy, "NO_PROXY="+opts.NoProxy)
+	}
+	return env
+}
+
+// MirrorEnv returns the environment variable named variable set to the mirror of the package manager pm in
+// Options.Mirrors, for package managers configured through the environment, or nil if no mirror is set.
+func MirrorEnv(opts *Options, pm string, variable string) []string {
+	if mirror := opts.Mirror(pm); mirror != "" {
+		return []string{variable + "=" + mirror}
+	}
+	return nil
+}
+
+// Mirror returns the mirror of the package manager pm in Options.Mirrors, or an empty string if none is set.
+// It can be called on nil options.
+func (o *Options) Mirror(pm string) string {
+	if o == nil {
+		return ""
+	}
+	return o.Mirrors[pm]
+}
@@ -0,0 +1,45 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestProxyEnv(t *testing.T) {
+	if env := manager.ProxyEnv(nil); env != nil {
+		t.Errorf("ProxyEnv() = %+v, want nil", env)
+	}
+
+	opts := &manager.Options{Proxy: "http://proxy.example.com:3128", NoProxy: "localhost"}
+	want := []string{
+		"http_proxy=http://proxy.example.com:3128",
+		"https_proxy=http://proxy.example.com:3128",
+		"HTTP_PROXY=http://proxy.example.com:3128",
+		"HTTPS_PROXY=http://proxy.example.com:3128",
+		"no_proxy=localhost",
+		"NO_PROXY=localhost",
+	}
+	if env := manager.ProxyEnv(opts); !reflect.DeepEqual(env, want) {
+		t.Errorf("ProxyEnv() = %+v, want %+v", env, want)
+	}
+
+	cmd := manager.Command(opts, "true")
+	if env := cmd.Environ(); env[len(env)-1] != "NO_PROXY=localhost" {
+		t.Errorf("Command() environment = %+v, want the proxy variables", env)
+	}
+}
+
+func TestMirrorEnv(t *testing.T) {
+	opts := &manager.Options{Mirrors: map[string]string{"pip": "https://pypi.example.com/simple"}}
+	if env := manager.MirrorEnv(opts, "pip", "PIP_INDEX_URL"); !reflect.DeepEqual(env, []string{"PIP_INDEX_URL=https://pypi.example.com/simple"}) {
+		t.Errorf("MirrorEnv() = %+v, want the pip mirror", env)
+	}
+	if env := manager.MirrorEnv(opts, "npm", "npm_config_registry"); env != nil {
+		t.Errorf("MirrorEnv() = %+v, want nil", env)
+	}
+	if env := manager.MirrorEnv(nil, "pip", "PIP_INDEX_URL"); env != nil {
+		t.Errorf("MirrorEnv() = %+v, want nil", env)
+	}
+}
@@ -0,0 +1,282 @@
+// Package nix provides an implementation of the syspkg manager interface for nix profiles.
+// It provides a Go (golang) API interface for interacting with the Nix package manager.
+// This package is a wrapper around the nix command line tool.
+//
+// Nix is a purely functional package manager, used by NixOS and available on most Linux distributions and macOS.
+// Packages are built into the Nix store (/nix/store) and made available to users through profiles.
+// This package manages the packages of the current user's profile with `nix profile`, which installs packages from flakes,
+// e.g. "nixpkgs#hello". Packages given without a flake reference are looked up in nixpkgs.
+// The nix command and flakes are still experimental features of Nix, so they are enabled on the command line.
+//
+// For more information about Nix, visit:
+// - https://nixos.org/manual/nix/stable/command-ref/new-cli/nix3-profile
+// - https://nixos.org/manual/nix/stable/command-ref/new-cli/nix3-search
+//
+// This package is part of the syspkg library.
+package nix
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "nix"
+
+// Constants used for nix commands
+const (
+	ArgsExperimentalFeatures string = "--extra-experimental-features"
+	ArgsFeatures             string = "nix-command flakes"
+	ArgsJSON                 string = "--json"
+	ArgsAllPackages          string = ".*"
+)
+
+// DefaultFlake is the flake used for packages given without a flake reference.
+var DefaultFlake string = "nixpkgs"
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for nix.
+var ENV_NonInteractive []string = []string{"LC_ALL=C", "NIX_CONFIG=accept-flake-config = false"}
+
+// PackageManager implements the manager.PackageManager interface for nix profiles.
+type PackageManager struct{}
+
+// IsAvailable checks if the nix package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(pm)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the nix package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by nix.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:  true,
+		Delete:  true,
+		DryRun:  true,
+		Upgrade: true,
+	}
+}
+
+// Install installs the provided packages into the user's profile using nix.
+// Packages can be given as flake references (e.g. "nixpkgs#hello" or "github:user/repo#tool"), or as plain names from nixpkgs.
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if err := manager.RejectVersionSpecs(pkgs); err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// nix profile install has no dry-run mode, so only report what was requested
+	if opts.DryRun {
+		var packages []manager.PackageInfo
+		for _, pkg := range pkgs {
+			packages = append(packages, manager.PackageInfo{
+				Name:           packageName(pkg),
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"flake": flakeRef(pkg)},
+			})
+		}
+		return packages, nil
+	}
+
+	args := []string{"profile", "install"}
+	for _, pkg := range pkgs {
+		args = append(args, flakeRef(pkg))
+	}
+	if err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+
+	// nix profile install does not report anything, so look up the installed versions
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	return filterPackages(installed, pkgs), nil
+}
+
+// Delete removes the provided packages from the user's profile using nix.
+// The packages stay in the Nix store until it is garbage collected.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// look up the installed versions first, as they can't be queried after removal
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	packages := filterPackages(installed, pkgs)
+	for i := range packages {
+		packages[i].Status = manager.PackageStatusAvailable
+	}
+	if opts.DryRun || len(packages) == 0 {
+		return packages, nil
+	}
+
+	args := []string{"profile", "remove"}
+	for _, pkg := range packages {
+		args = append(args, pkg.Name)
+	}
+	if err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+	return packages, nil
+}
+
+// Refresh does nothing for nix, as flakes are fetched again when their cached copy expires.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	return nil
+}
+
+// Find searches nixpkgs for packages matching the provided keywords using nix search.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{ArgsExperimentalFeatures, ArgsFeatures, "search", DefaultFlake, ArgsJSON}, keywords...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSearchOutput(out, opts)
+}
+
+// ListInstalled lists all packages installed in the user's profile using nix.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, ArgsExperimentalFeatures, ArgsFeatures, "profile", "list", ArgsJSON)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProfileListOutput(out, opts)
+}
+
+// ListUpgradable is not supported by nix, which can only tell which packages changed after upgrading them.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+
+// Upgrade upgrades the provided packages of the user's profile to the latest version of their flake using nix.
+// If no packages are given, all packages of the profile are upgraded.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// nix can't tell in advance what would be upgraded
+	if opts.DryRun {
+		return nil, manager.ErrOperationNotSupported
+	}
+
+	before, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"profile", "upgrade"}
+	if len(pkgs) == 0 {
+		args = append(args, ArgsAllPackages)
+	}
+	for _, pkg := range filterPackages(before, pkgs) {
+		args = append(args, pkg.Name)
+	}
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	if err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+
+	after, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	return diffVersions(before, after), nil
+}
+
+// UpgradeAll upgrades all packages of the user's profile using nix.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// GetPackageInfo retrieves information about the specified package from nixpkgs,
+// along with the version installed in the user's profile, if any.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	info := manager.PackageInfo{Name: packageName(pkg), Status: manager.PackageStatusUnknown, PackageManager: pm}
+
+	found, err := a.Find([]string{"^" + packageName(pkg) + "$"}, opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	if len(found) > 0 {
+		info = found[0]
+	}
+
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	for _, p := range filterPackages(installed, []string{pkg}) {
+		p.NewVersion = info.NewVersion
+		if p.NewVersion == p.Version {
+			p.NewVersion = ""
+		} else if p.NewVersion != "" {
+			p.Status = manager.PackageStatusUpgradable
+		}
+		return p, nil
+	}
+	return info, nil
+}
+
+// run runs nix with the given arguments (and the experimental features it needs), either attached to the terminal
+// in interactive mode, or non-interactively, logging the output in verbose mode.
+func (a *PackageManager) run(args []string, opts *manager.Options) error {
+	cmd := manager.Command(opts, pm, append([]string{ArgsExperimentalFeatures, ArgsFeatures}, args...)...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return manager.Run(cmd)
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.CombinedOutput(cmd)
+	if opts.Verbose || err != nil {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return err
+}
@@ -0,0 +1,216 @@
+// Package nix provides a package manager implementation for nix profiles
+// using nix as the underlying package management tool.
+package nix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// storePathPattern matches a Nix store path, e.g. "/nix/store/0c4ivxpdh7r6wf6i7gm9vvlmz8bwqc4a-hello-2.12.1",
+// capturing the package name and version. The version starts at the first hyphen followed by a digit.
+var storePathPattern = regexp.MustCompile(`^/nix/store/[0-9a-z]{32}-(.+?)(?:-(\d[^/]*))?$`)
+
+// profileElement is an element of a nix profile, as printed by `nix profile list --json`.
+type profileElement struct {
+	Active      bool     `json:"active"`
+	AttrPath    string   `json:"attrPath"`
+	OriginalURL string   `json:"originalUrl"`
+	URL         string   `json:"url"`
+	StorePaths  []string `json:"storePaths"`
+}
+
+// ParseProfileListOutput parses the output of `nix profile list --json` command
+// and returns the list of packages installed in the profile, sorted by name.
+// The flake reference of each package is kept in AdditionalData: "flake" for the reference it was installed from,
+// "locked_url" for the exact revision, and "attr_path" for the attribute of the flake.
+// Since Nix 2.20, elements are an object keyed by name; older versions print an array, in which case the name is taken from the attribute path.
+// Example msg:
+//
+//	{"elements":{"hello":{"active":true,"attrPath":"legacyPackages.x86_64-linux.hello","originalUrl":"flake:nixpkgs",
+//	"url":"github:NixOS/nixpkgs/9c0c84e3b1ad","storePaths":["/nix/store/0c4ivxpdh7r6wf6i7gm9vvlmz8bwqc4a-hello-2.12.1"],"priority":5}},"version":3}
+func ParseProfileListOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var profile struct {
+		Elements json.RawMessage `json:"elements"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &profile); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	elements := make(map[string]profileElement)
+	if bytes.HasPrefix(bytes.TrimSpace(profile.Elements), []byte("[")) {
+		var list []profileElement
+		if err := json.Unmarshal(profile.Elements, &list); err != nil {
+			return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+		}
+		for _, element := range list {
+			elements[attrName(element.AttrPath)] = element
+		}
+	} else if len(profile.Elements) > 0 {
+		if err := json.Unmarshal(profile.Elements, &elements); err != nil {
+			return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+		}
+	}
+
+	names := make([]string, 0, len(elements))
+	for name := range elements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		element := elements[name]
+		if !element.Active {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           name,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+			AdditionalData: map[string]string{
+				"flake":      element.OriginalURL,
+				"locked_url": element.URL,
+				"attr_path":  element.AttrPath,
+			},
+		}
+		if len(element.StorePaths) > 0 {
+			_, packageInfo.Version = parseStorePath(element.StorePaths[0])
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// ParseSearchOutput parses the output of `nix search nixpkgs --json keyword` command
+// and returns a list of packages that match the search query, sorted by attribute path.
+// Example msg:
+//
+//	{"legacyPackages.x86_64-linux.hello":{"description":"A program that produces a familiar, friendly greeting","pname":"hello","version":"2.12.1"}}
+func ParseSearchOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var results map[string]struct {
+		PName       string `json:"pname"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &results); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	attrPaths := make([]string, 0, len(results))
+	for attrPath := range results {
+		attrPaths = append(attrPaths, attrPath)
+	}
+	sort.Strings(attrPaths)
+
+	for _, attrPath := range attrPaths {
+		r := results[attrPath]
+		packages = append(packages, manager.PackageInfo{
+			Name:           attrName(attrPath),
+			NewVersion:     r.Version,
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: pm,
+			AdditionalData: map[string]string{
+				"flake":       DefaultFlake,
+				"attr_path":   attrPath,
+				"pname":       r.PName,
+				"description": r.Description,
+			},
+		})
+	}
+
+	return packages, nil
+}
+
+// parseStorePath returns the package name and version of a Nix store path.
+func parseStorePath(storePath string) (string, string) {
+	match := storePathPattern.FindStringSubmatch(storePath)
+	if match == nil {
+		return path.Base(storePath), ""
+	}
+	return match[1], match[2]
+}
+
+// attrName returns the last component of a flake attribute path, e.g. "hello" for "legacyPackages.x86_64-linux.hello".
+func attrName(attrPath string) string {
+	if idx := strings.LastIndex(attrPath, "."); idx >= 0 {
+		return attrPath[idx+1:]
+	}
+	return attrPath
+}
+
+// flakeRef returns the flake reference to install a package, e.g. "nixpkgs#hello" for "hello".
+// Packages already given as flake references are returned unchanged.
+func flakeRef(pkg string) string {
+	if strings.Contains(pkg, "#") {
+		return pkg
+	}
+	return DefaultFlake + "#" + pkg
+}
+
+// packageName returns the name a package gets in a profile, e.g. "hello" for "nixpkgs#hello" or "nixpkgs#python3Packages.requests".
+func packageName(pkg string) string {
+	if _, fragment, found := strings.Cut(pkg, "#"); found {
+		pkg = fragment
+	}
+	return attrName(pkg)
+}
+
+// filterPackages returns the packages whose name matches one of the given packages, or all packages if none are given.
+func filterPackages(packages []manager.PackageInfo, pkgs []string) []manager.PackageInfo {
+	if len(pkgs) == 0 {
+		return packages
+	}
+
+	wanted := make(map[string]bool)
+	for _, pkg := range pkgs {
+		wanted[packageName(pkg)] = true
+	}
+
+	var filtered []manager.PackageInfo
+	for _, pkg := range packages {
+		if wanted[pkg.Name] {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
+
+// diffVersions returns the packages whose version changed between the before and after lists,
+// with Version set to the old version and NewVersion set to the new one.
+func diffVersions(before, after []manager.PackageInfo) []manager.PackageInfo {
+	oldVersions := make(map[string]string)
+	for _, pkg := range before {
+		oldVersions[pkg.Name] = pkg.Version
+	}
+
+	var packages []manager.PackageInfo
+	for _, pkg := range after {
+		oldVersion, ok := oldVersions[pkg.Name]
+		if !ok || oldVersion == pkg.Version {
+			continue
+		}
+		pkg.NewVersion = pkg.Version
+		pkg.Version = oldVersion
+		packages = append(packages, pkg)
+	}
+	return packages
+}
@@ -0,0 +1,76 @@
+package nix_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/nix"
+)
+
+func TestParseProfileListOutput(t *testing.T) {
+	var inputParseProfileListOutput string = `{"elements":{"hello":{"active":true,"attrPath":"legacyPackages.x86_64-linux.hello","originalUrl":"flake:nixpkgs","url":"github:NixOS/nixpkgs/9c0c84e3b1ad","storePaths":["/nix/store/0c4ivxpdh7r6wf6i7gm9vvlmz8bwqc4a-hello-2.12.1"],"priority":5}},"version":3}`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "hello",
+			Version:        "2.12.1",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "nix",
+			AdditionalData: map[string]string{
+				"flake":      "flake:nixpkgs",
+				"locked_url": "github:NixOS/nixpkgs/9c0c84e3b1ad",
+				"attr_path":  "legacyPackages.x86_64-linux.hello",
+			},
+		},
+	}
+
+	actualPackageInfo, err := nix.ParseProfileListOutput([]byte(inputParseProfileListOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseProfileListOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseProfileListOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+
+	// before Nix 2.20, elements are an array
+	var inputParseProfileListOutputV2 string = `{"elements":[{"active":true,"attrPath":"legacyPackages.x86_64-linux.hello","originalUrl":"flake:nixpkgs","url":"github:NixOS/nixpkgs/9c0c84e3b1ad","storePaths":["/nix/store/0c4ivxpdh7r6wf6i7gm9vvlmz8bwqc4a-hello-2.12.1"],"priority":5}],"version":2}`
+
+	actualPackageInfo, err = nix.ParseProfileListOutput([]byte(inputParseProfileListOutputV2), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseProfileListOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseProfileListOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseSearchOutput(t *testing.T) {
+	var inputParseSearchOutput string = `{"legacyPackages.x86_64-linux.hello":{"description":"A program that produces a familiar, friendly greeting","pname":"hello","version":"2.12.1"}}`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "hello",
+			NewVersion:     "2.12.1",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "nix",
+			AdditionalData: map[string]string{
+				"flake":       "nixpkgs",
+				"attr_path":   "legacyPackages.x86_64-linux.hello",
+				"pname":       "hello",
+				"description": "A program that produces a familiar, friendly greeting",
+			},
+		},
+	}
+
+	actualPackageInfo, err := nix.ParseSearchOutput([]byte(inputParseSearchOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseSearchOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseSearchOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
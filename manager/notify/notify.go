@@ -0,0 +1,317 @@
+// Package notify alerts operators of the outcomes of syspkg operations, such as a completed upgrade, vulnerabilities
+// found by an audit, or a failure, by email or by posting to a chat webhook (Slack, Microsoft Teams, Discord) or to
+// any HTTP endpoint, so that headless servers running scheduled syspkg jobs don't fail silently.
+//
+// Unlike hooks, which run for the operations of each package manager, notifications are sent once per command, with
+// a subject and a text body summarizing all package managers.
+//
+// This package is part of the syspkg library.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Events notifications are sent for.
+const (
+	// EventUpgrade is sent when an upgrade of packages completed, successfully or not.
+	EventUpgrade = "upgrade"
+
+	// EventVulnerable is sent when an audit found vulnerable packages.
+	EventVulnerable = "vulnerable"
+
+	// EventFailure is sent when a command changing packages failed, or was interrupted.
+	EventFailure = "failure"
+)
+
+// Events are the events notifications can be sent for.
+var Events = []string{EventUpgrade, EventVulnerable, EventFailure}
+
+// Formats of the webhook payloads.
+const (
+	FormatJSON    = "json"
+	FormatSlack   = "slack"
+	FormatTeams   = "teams"
+	FormatDiscord = "discord"
+)
+
+// DefaultTimeout is how long sending a notification may take when the notifier has no timeout.
+const DefaultTimeout = 30 * time.Second
+
+// Message is a notification.
+type Message struct {
+	// Event is the event the notification is sent for, such as EventUpgrade.
+	Event string `json:"event"`
+
+	// Host is the name of the host the event happened on, set by Send.
+	Host string `json:"host"`
+
+	// Subject is a one-line summary, such as "12 packages upgraded on web1".
+	Subject string `json:"subject"`
+
+	// Body are the details, as plain text, such as the report of an upgrade.
+	Body string `json:"body,omitempty"`
+}
+
+// Notifier sends the notifications of some events by email or to a webhook.
+type Notifier struct {
+	// Events are the events notifications are sent for; all of them if empty.
+	Events []string
+
+	// Webhook is the URL notifications are posted to.
+	Webhook string
+
+	// Format is the format of the payloads posted to Webhook: FormatSlack, FormatTeams, FormatDiscord or
+	// FormatJSON, the Message itself. If empty, it is guessed from the host of Webhook, and defaults to FormatJSON.
+	Format string
+
+	// To are the addresses notifications are emailed to, if Webhook is empty.
+	To []string
+
+	// From is the sender address of the emails.
+	From string
+
+	// SMTP is the address of the mail server, as host:port; localhost:25 if empty.
+	SMTP string
+
+	// Username and Password authenticate to the mail server, if Username is set. The password is sent only over
+	// TLS, or to localhost.
+	Username string
+	Password string
+
+	// Timeout is how long sending a notification may take; DefaultTimeout if zero.
+	Timeout time.Duration
+}
+
+// Validate checks that the notifier has known events, and either a valid webhook or valid email addresses.
+func (n Notifier) Validate() error {
+	for _, event := range n.Events {
+		if !slices.Contains(Events, event) {
+			return fmt.Errorf("unknown event %q, expected %s", event, strings.Join(Events, ", "))
+		}
+	}
+	if (n.Webhook == "") == (len(n.To) == 0) {
+		return errors.New("expected either a webhook or email recipients")
+	}
+	if n.Webhook != "" {
+		if u, err := url.Parse(n.Webhook); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid webhook %q, expected an HTTP(S) URL", n.Webhook)
+		}
+		switch n.Format {
+		case "", FormatJSON, FormatSlack, FormatTeams, FormatDiscord:
+		default:
+			return fmt.Errorf("unknown format %q, expected %s, %s, %s or %s", n.Format, FormatSlack, FormatTeams, FormatDiscord, FormatJSON)
+		}
+		return nil
+	}
+	for _, address := range append([]string{n.From}, n.To...) {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return fmt.Errorf("invalid email address %q", address)
+		}
+	}
+	if n.SMTP != "" {
+		if _, _, err := net.SplitHostPort(n.SMTP); err != nil {
+			return fmt.Errorf("invalid SMTP server %q, expected host:port", n.SMTP)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the notifier sends the notifications of event.
+func (n Notifier) Matches(event string) bool {
+	return len(n.Events) == 0 || slices.Contains(n.Events, event)
+}
+
+// Send sends m to the notifiers matching its event, and returns the errors of the ones that failed, joined. The
+// failures are logged with logger as well (the default slog logger if nil), as notifications are usually sent
+// when the command is done.
+func Send(notifiers []Notifier, m Message, logger manager.Logger) error {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if m.Host == "" {
+		m.Host, _ = os.Hostname()
+	}
+
+	var errs []error
+	for _, n := range notifiers {
+		if !n.Matches(m.Event) {
+			continue
+		}
+		if err := n.Send(m); err != nil {
+			logger.Warn("Notification failed", "event", m.Event, "notifier", n.target(), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", n.target(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Send sends m with the notifier, whatever its event.
+func (n Notifier) Send(m Message) error {
+	timeout := n.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if n.Webhook != "" {
+		return n.post(ctx, m)
+	}
+	return n.email(ctx, m)
+}
+
+// post posts m to the webhook, in its format.
+func (n Notifier) post(ctx context.Context, m Message) error {
+	body, err := json.Marshal(Payload(n.format(), m))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// format returns the format of the webhook payloads: Format, or the one of the chat service of the webhook.
+func (n Notifier) format() string {
+	if n.Format != "" {
+		return n.Format
+	}
+	u, err := url.Parse(n.Webhook)
+	if err != nil {
+		return FormatJSON
+	}
+	host := u.Hostname()
+	switch {
+	case host == "hooks.slack.com":
+		return FormatSlack
+	case host == "discord.com" || host == "discordapp.com":
+		return FormatDiscord
+	case strings.HasSuffix(host, ".webhook.office.com") || host == "outlook.office.com":
+		return FormatTeams
+	}
+	return FormatJSON
+}
+
+// maxDiscordContent is the maximum length of the content of Discord messages.
+const maxDiscordContent = 2000
+
+// Payload returns the payload posted to webhooks in format for m: a message of the chat service, with the body as
+// preformatted text, or m itself for FormatJSON.
+func Payload(format string, m Message) any {
+	switch format {
+	case FormatSlack:
+		return map[string]string{"text": "*" + m.Subject + "*" + codeBlock(m.Body)}
+	case FormatDiscord:
+		content := "**" + m.Subject + "**" + codeBlock(m.Body)
+		if len(content) > maxDiscordContent {
+			body := m.Body[:max(0, maxDiscordContent-len(m.Subject)-16)]
+			content = "**" + m.Subject + "**" + codeBlock(strings.ToValidUTF8(body, "")+"…")
+		}
+		return map[string]string{"content": content}
+	case FormatTeams:
+		return map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  m.Subject,
+			"title":    m.Subject,
+			"text":     "<pre>" + strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(m.Body) + "</pre>",
+		}
+	}
+	return m
+}
+
+// codeBlock returns text as a Markdown code block on a new line, or nothing if text is empty.
+func codeBlock(text string) string {
+	if text = strings.TrimSpace(text); text == "" {
+		return ""
+	}
+	return "\n```\n" + strings.ReplaceAll(text, "```", "'''") + "\n```"
+}
+
+// email sends m by email to the recipients, through the SMTP server.
+func (n Notifier) email(ctx context.Context, m Message) error {
+	server := n.SMTP
+	if server == "" {
+		server = "localhost:25"
+	}
+	host, _, err := net.SplitHostPort(server)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+	// smtp.SendMail has no context, so it is abandoned when the timeout expires
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(server, auth, n.From, n.To, EmailMessage(n.From, n.To, m)) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("sending the email to %s: %w", server, ctx.Err())
+	}
+}
+
+// EmailMessage returns the email of m, from from to the recipients to, as plain text.
+func EmailMessage(from string, to []string, m Message) []byte {
+	var b bytes.Buffer
+	// header values must not contain line breaks, which would inject other headers
+	header := strings.NewReplacer("\r", " ", "\n", " ")
+	fmt.Fprintf(&b, "From: %s\r\n", header.Replace(from))
+	fmt.Fprintf(&b, "To: %s\r\n", header.Replace(strings.Join(to, ", ")))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", header.Replace(m.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	fmt.Fprintf(&b, "X-Syspkg-Event: %s\r\n", header.Replace(m.Event))
+	b.WriteString("\r\n")
+
+	body := m.Body
+	if body == "" {
+		body = m.Subject
+	}
+	for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		b.WriteString(strings.TrimRight(line, "\r") + "\r\n")
+	}
+	return b.Bytes()
+}
+
+// target returns the webhook or the recipients of the notifier, to identify it in errors.
+func (n Notifier) target() string {
+	if n.Webhook != "" {
+		return n.Webhook
+	}
+	return strings.Join(n.To, ", ")
+}
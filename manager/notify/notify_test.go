@@ -0,0 +1,179 @@
+package notify_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager/notify"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []notify.Notifier{
+		{Webhook: "https://hooks.slack.com/services/T000/B000/XXXX"},
+		{Events: []string{notify.EventFailure}, To: []string{"ops@example.com"}, From: "syspkg@example.com", SMTP: "smtp.example.com:587"},
+	}
+	for _, n := range valid {
+		if err := n.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %+v", n, err)
+		}
+	}
+
+	invalid := []notify.Notifier{
+		{},
+		{Webhook: "hooks.example.com"},
+		{Webhook: "https://hooks.example.com", Format: "irc"},
+		{Webhook: "https://hooks.example.com", Events: []string{"install"}},
+		{Webhook: "https://hooks.example.com", To: []string{"ops@example.com"}},
+		{To: []string{"ops"}, From: "syspkg@example.com"},
+		{To: []string{"ops@example.com"}, From: "syspkg@example.com", SMTP: "smtp.example.com"},
+	}
+	for _, n := range invalid {
+		if err := n.Validate(); err == nil {
+			t.Errorf("Validate(%+v) error = nil, want an error", n)
+		}
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Decode() error = %+v", err)
+		}
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	notifiers := []notify.Notifier{
+		{Webhook: server.URL, Format: notify.FormatSlack},
+		{Webhook: server.URL, Events: []string{notify.EventFailure}},
+		{Webhook: server.URL, Events: []string{notify.EventUpgrade}},
+	}
+	m := notify.Message{Event: notify.EventUpgrade, Host: "web1", Subject: "2 packages upgraded on web1", Body: "apt: 2 packages upgraded\n"}
+	if err := notify.Send(notifiers, m, nil); err != nil {
+		t.Fatalf("Send() error = %+v", err)
+	}
+
+	expectedPayloads := []map[string]any{
+		{"text": "*2 packages upgraded on web1*\n```\napt: 2 packages upgraded\n```"},
+		{"event": "upgrade", "host": "web1", "subject": "2 packages upgraded on web1", "body": "apt: 2 packages upgraded\n"},
+	}
+	if !reflect.DeepEqual(payloads, expectedPayloads) {
+		t.Errorf("Send() posted %+v, want %+v", payloads, expectedPayloads)
+	}
+
+	// failing notifiers return an error, without stopping the others
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+	payloads = nil
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if err := notify.Send([]notify.Notifier{{Webhook: failing.URL}, {Webhook: server.URL}}, m, discard); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Send() error = %+v, want the error of the failing webhook", err)
+	}
+	if len(payloads) != 1 {
+		t.Errorf("Send() posted %d payloads, want 1", len(payloads))
+	}
+}
+
+func TestPayload(t *testing.T) {
+	m := notify.Message{Subject: "Upgrade failed on web1", Body: "apt: <failed>"}
+
+	expectedTeams := map[string]string{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  "Upgrade failed on web1",
+		"title":    "Upgrade failed on web1",
+		"text":     "<pre>apt: &lt;failed&gt;</pre>",
+	}
+	if actual := notify.Payload(notify.FormatTeams, m); !reflect.DeepEqual(actual, expectedTeams) {
+		t.Errorf("Payload(teams) = %+v, want %+v", actual, expectedTeams)
+	}
+
+	m.Body = strings.Repeat("x", 3000)
+	discord := notify.Payload(notify.FormatDiscord, m).(map[string]string)
+	if content := discord["content"]; len(content) > 2000 || !strings.HasPrefix(content, "**Upgrade failed on web1**\n```\nxxx") {
+		t.Errorf("Payload(discord) = %d bytes %q..., want at most 2000 bytes", len(content), content[:40])
+	}
+}
+
+func TestSendEmail(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %+v", err)
+	}
+	defer listener.Close()
+
+	// a minimal SMTP server, receiving a single email
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var lines []string
+		for data := false; ; {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case data && line == ".":
+				data = false
+				reply("250 OK")
+			case data:
+				lines = append(lines, line)
+			case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+				reply("250 localhost")
+			case line == "DATA":
+				data = true
+				reply("354 Go ahead")
+			case line == "QUIT":
+				reply("221 Bye")
+				received <- lines
+				return
+			default:
+				lines = append(lines, line)
+				reply("250 OK")
+			}
+		}
+	}()
+
+	n := notify.Notifier{To: []string{"ops@example.com"}, From: "syspkg@example.com", SMTP: listener.Addr().String()}
+	m := notify.Message{Event: notify.EventVulnerable, Subject: "3 vulnerabilities found on web1\r\nBcc: x@example.com", Body: "apt: openssl 3.0.14-1 is affected by CVE-2024-5535\n"}
+	if err := n.Send(m); err != nil {
+		t.Fatalf("Send() error = %+v", err)
+	}
+
+	lines := <-received
+	for _, expected := range []string{
+		"MAIL FROM:<syspkg@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"Subject: 3 vulnerabilities found on web1  Bcc: x@example.com",
+		"X-Syspkg-Event: vulnerable",
+		"apt: openssl 3.0.14-1 is affected by CVE-2024-5535",
+	} {
+		found := false
+		for _, line := range lines {
+			found = found || line == expected
+		}
+		if !found {
+			t.Errorf("the email %q has no line %q", lines, expected)
+		}
+	}
+}
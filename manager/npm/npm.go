@@ -0,0 +1,323 @@
+// Package npm provides an implementation of the syspkg manager interface for globally installed npm packages.
+// It provides a Go (golang) API interface for interacting with the npm package manager.
+// This package is a wrapper around the npm command line tool.
+//
+// npm is the package manager of the Node.js JavaScript runtime, and the command line client of the npm registry.
+// Besides the dependencies of Node.js projects, npm can install packages globally (npm install --global),
+// which is how many command line tools written in JavaScript are distributed. Only these global packages are managed by this package.
+// npm provides JSON output (--json) for most of its commands, which is used by this package to parse results.
+//
+// For more information about npm, visit:
+// - https://docs.npmjs.com/cli/
+// - https://docs.npmjs.com/downloading-and-installing-packages-globally
+//
+// This package is part of the syspkg library.
+package npm
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "npm"
+
+// Constants used for npm commands
+const (
+	ArgsGlobal  string = "--global"
+	ArgsJSON    string = "--json"
+	ArgsDryRun  string = "--dry-run"
+	ArgsDepth0  string = "--depth=0"
+	ArgsNoFund  string = "--no-fund"
+	ArgsNoAudit string = "--no-audit"
+)
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for npm.
+var ENV_NonInteractive []string = []string{"LC_ALL=C", "NO_UPDATE_NOTIFIER=1", "npm_config_yes=true", "npm_config_color=false"}
+
+// EnvMirror is the environment variable setting the mirror of Options.Mirrors: the npm registry, such as https://npm.example.com.
+var EnvMirror string = "npm_config_registry"
+
+// PackageManager implements the manager.PackageManager interface for globally installed npm packages.
+type PackageManager struct{}
+
+// IsAvailable checks if the npm package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(pm)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the npm package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by npm.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:           true,
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+		Downgrade:        true,
+	}
+}
+
+// Install installs the provided packages globally using npm.
+// Packages can be given with a version or dist-tag, e.g. "typescript@5.1.6" or "typescript@next".
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// npm installs a specific version with name@version
+	pkgs, err := manager.TranslatePackageSpecs(pkgs, func(spec manager.PackageSpec) string { return spec.Name + "@" + spec.Version })
+	if err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// npm does not report what it would install in dry-run mode, so only report what was requested
+	if opts.DryRun {
+		var packages []manager.PackageInfo
+		for _, pkg := range pkgs {
+			packages = append(packages, manager.PackageInfo{
+				Name:           packageName(pkg),
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+			})
+		}
+		return packages, nil
+	}
+
+	args := append([]string{"install", ArgsGlobal, ArgsNoFund, ArgsNoAudit}, pkgs...)
+	if err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+
+	// npm install does not report installed versions, so look them up
+	return a.listGlobal(packageNames(pkgs), manager.PackageStatusInstalled, opts)
+}
+
+// Downgrade installs the provided packages, given as "name=version", at that version globally using npm.
+// npm replaces the installed version with the requested one, whether it is older or newer.
+func (a *PackageManager) Downgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if err := manager.RequireVersionSpecs(pkgs); err != nil {
+		return nil, err
+	}
+	return a.Install(pkgs, opts)
+}
+
+// Delete uninstalls the provided global packages using npm.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// look up the installed versions first, as they can't be queried after removal
+	packages, err := a.listGlobal(packageNames(pkgs), manager.PackageStatusAvailable, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return packages, nil
+	}
+
+	args := append([]string{"uninstall", ArgsGlobal}, pkgs...)
+	if err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+	return packages, nil
+}
+
+// Refresh does nothing for npm, as npm always queries the registry directly.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	return nil
+}
+
+// Find searches the npm registry for packages matching the provided keywords.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"search", ArgsJSON}, keywords...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseFindOutput(out, opts)
+}
+
+// ListInstalled lists all globally installed npm packages.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.listGlobal(nil, manager.PackageStatusInstalled, opts)
+}
+
+// ListUpgradable lists all globally installed npm packages that have a newer version available.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "outdated", ArgsGlobal, ArgsJSON)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// npm exits with 1 when some packages are outdated
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return nil, err
+		}
+	}
+
+	return ParseOutdatedOutput(out, opts)
+}
+
+// Upgrade upgrades the provided global packages using npm.
+// If no packages are given, all outdated global packages are upgraded.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	outdated, err := a.ListUpgradable(opts)
+	if err != nil {
+		return nil, err
+	}
+	outdated = filterPackages(outdated, pkgs)
+	if opts.DryRun || len(outdated) == 0 {
+		return outdated, nil
+	}
+
+	args := append([]string{"update", ArgsGlobal, ArgsNoFund, ArgsNoAudit}, pkgs...)
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	if err := a.run(args, opts); err != nil {
+		return nil, err
+	}
+	if opts.Interactive {
+		return nil, nil
+	}
+
+	// report the versions that are actually installed now
+	var names []string
+	for _, pkg := range outdated {
+		names = append(names, pkg.Name)
+	}
+	upgraded, err := a.listGlobal(names, manager.PackageStatusInstalled, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	oldVersions := make(map[string]string)
+	for _, pkg := range outdated {
+		oldVersions[pkg.Name] = pkg.Version
+	}
+	for i := range upgraded {
+		upgraded[i].NewVersion = upgraded[i].Version
+		upgraded[i].Version = oldVersions[upgraded[i].Name]
+	}
+	return upgraded, nil
+}
+
+// UpgradeAll upgrades all outdated global packages using npm.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// GetPackageInfo retrieves information about the specified package from the npm registry,
+// along with the globally installed version, if any.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "view", ArgsJSON, pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+
+	info, err := ParsePackageInfoOutput(out, opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+
+	installed, err := a.listGlobal([]string{info.Name}, manager.PackageStatusInstalled, opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	if len(installed) > 0 {
+		info.Version = installed[0].Version
+		info.Status = manager.PackageStatusInstalled
+		if info.Version != info.NewVersion {
+			info.Status = manager.PackageStatusUpgradable
+		} else {
+			info.NewVersion = ""
+		}
+	}
+	return info, nil
+}
+
+// listGlobal lists the given globally installed packages, or all of them if none are given,
+// and sets their status to the given status.
+func (a *PackageManager) listGlobal(pkgs []string, status manager.PackageStatus, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"ls", ArgsGlobal, ArgsDepth0, ArgsJSON}, pkgs...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// npm exits with 1 when some of the given packages are not installed
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 || len(out) == 0 {
+			return nil, err
+		}
+	}
+
+	packages, err := ParseListInstalledOutput(out, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range packages {
+		packages[i].Status = status
+	}
+	return packages, nil
+}
+
+// run runs npm with the given arguments, either attached to the terminal in interactive mode,
+// or non-interactively, logging the output in verbose mode.
+func (a *PackageManager) run(args []string, opts *manager.Options) error {
+	cmd := manager.Command(opts, pm, args...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return manager.Run(cmd)
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return err
+	}
+	if opts.Verbose {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
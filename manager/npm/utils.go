@@ -0,0 +1,249 @@
+// Package npm provides a package manager implementation for globally installed Node.js packages
+// using npm as the underlying package management tool.
+package npm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ParseFindOutput parses the output of `npm search --json keyword` command
+// and returns a list of packages that match the search query.
+// Example msg:
+//
+//	[
+//	  {"name": "typescript", "scope": "unscoped", "version": "5.1.6", "description": "TypeScript is a language for application scale JavaScript development"},
+//	  {"name": "@types/node", "scope": "types", "version": "20.4.5", "description": "TypeScript definitions for Node.js"}
+//	]
+func ParseFindOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var results []struct {
+		Name        string `json:"name"`
+		Scope       string `json:"scope"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &results); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	for _, r := range results {
+		// if name is empty, it might be not what we want
+		if r.Name == "" {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           r.Name,
+			NewVersion:     r.Version,
+			Category:       r.Scope,
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: pm,
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// ParseListInstalledOutput parses the output of `npm ls --global --depth=0 --json` command
+// and returns a list of installed packages, sorted by name.
+// Example msg:
+//
+//	{
+//	  "name": "lib",
+//	  "dependencies": {
+//	    "npm": {"version": "9.8.0", "overridden": false},
+//	    "typescript": {"version": "5.1.6", "overridden": false}
+//	  }
+//	}
+func ParseListInstalledOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var tree struct {
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+			Missing bool   `json:"missing"`
+		} `json:"dependencies"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &tree); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	for _, name := range sortedKeys(tree.Dependencies) {
+		dep := tree.Dependencies[name]
+		if dep.Missing || dep.Version == "" {
+			if opts != nil && opts.Verbose {
+				opts.Log().Debug("Package is not installed", "package_manager", pm, "package", name)
+			}
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           name,
+			Version:        dep.Version,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// ParseOutdatedOutput parses the output of `npm outdated --global --json` command
+// and returns a list of upgradable packages, sorted by name.
+// Example msg:
+//
+//	{
+//	  "typescript": {
+//	    "current": "5.1.3",
+//	    "wanted": "5.1.6",
+//	    "latest": "5.1.6",
+//	    "dependent": "global",
+//	    "location": "/usr/lib/node_modules/typescript"
+//	  }
+//	}
+func ParseOutdatedOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var outdated map[string]struct {
+		Current string `json:"current"`
+		Wanted  string `json:"wanted"`
+		Latest  string `json:"latest"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &outdated); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	for _, name := range sortedKeys(outdated) {
+		o := outdated[name]
+
+		newVersion := o.Wanted
+		if newVersion == "" {
+			newVersion = o.Latest
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           name,
+			Version:        o.Current,
+			NewVersion:     newVersion,
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: pm,
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// ParsePackageInfoOutput parses the output of `npm view --json packageName` command
+// and returns a manager.PackageInfo object containing the name and latest version of the package, its license,
+// homepage and maintainers. If the package specification matches several versions, npm returns an array, and the last
+// (newest) entry is used.
+// Example msg:
+//
+//	{
+//	  "name": "typescript",
+//	  "version": "5.1.6",
+//	  "description": "TypeScript is a language for application scale JavaScript development",
+//	  "license": "Apache-2.0",
+//	  "homepage": "https://www.typescriptlang.org/",
+//	  "maintainers": ["typescript-bot <typescript@microsoft.com>", "weswigham <wwigham@gmail.com>"]
+//	}
+func ParsePackageInfoOutput(msg []byte, opts *manager.Options) (manager.PackageInfo, error) {
+	type viewJSON struct {
+		Name        string   `json:"name"`
+		Version     string   `json:"version"`
+		License     string   `json:"license"`
+		Homepage    string   `json:"homepage"`
+		Maintainers []string `json:"maintainers"`
+	}
+	var view viewJSON
+
+	msg = bytes.TrimSpace(msg)
+	if bytes.HasPrefix(msg, []byte("[")) {
+		var views []viewJSON
+		if err := json.Unmarshal(msg, &views); err != nil {
+			return manager.PackageInfo{}, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+		}
+		if len(views) > 0 {
+			view = views[len(views)-1]
+		}
+	} else if err := json.Unmarshal(msg, &view); err != nil {
+		return manager.PackageInfo{}, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	return manager.PackageInfo{
+		Name:           view.Name,
+		NewVersion:     view.Version,
+		Status:         manager.PackageStatusAvailable,
+		License:        view.License,
+		Homepage:       view.Homepage,
+		Maintainer:     strings.Join(view.Maintainers, ", "),
+		PackageManager: pm,
+	}, nil
+}
+
+// packageName returns the name of an npm package specification, without its version or tag,
+// e.g. "typescript" for "typescript@5.1.6" and "@types/node" for "@types/node@20".
+func packageName(spec string) string {
+	if idx := strings.LastIndex(spec, "@"); idx > 0 {
+		return spec[:idx]
+	}
+	return spec
+}
+
+// packageNames returns the names of the given npm package specifications.
+func packageNames(specs []string) []string {
+	var names []string
+	for _, spec := range specs {
+		names = append(names, packageName(spec))
+	}
+	return names
+}
+
+// filterPackages returns the packages whose name is one of the given names, or all packages if no names are given.
+func filterPackages(packages []manager.PackageInfo, names []string) []manager.PackageInfo {
+	if len(names) == 0 {
+		return packages
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range packageNames(names) {
+		wanted[name] = true
+	}
+
+	var filtered []manager.PackageInfo
+	for _, pkg := range packages {
+		if wanted[pkg.Name] {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
+
+// sortedKeys returns the keys of a map, sorted alphabetically.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
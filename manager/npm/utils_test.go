@@ -0,0 +1,139 @@
+package npm_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/npm"
+)
+
+func TestParseListInstalledOutput(t *testing.T) {
+	var inputParseListInstalledOutput string = `{
+  "name": "lib",
+  "dependencies": {
+    "typescript": {"version": "5.1.6", "overridden": false},
+    "npm": {"version": "9.8.0", "overridden": false},
+    "left-pad": {"required": "*", "missing": true}
+  }
+}`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "npm",
+			Version:        "9.8.0",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "npm",
+		},
+		{
+			Name:           "typescript",
+			Version:        "5.1.6",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "npm",
+		},
+	}
+
+	actualPackageInfo, err := npm.ParseListInstalledOutput([]byte(inputParseListInstalledOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseListInstalledOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListInstalledOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseOutdatedOutput(t *testing.T) {
+	var inputParseOutdatedOutput string = `{
+  "typescript": {
+    "current": "5.1.3",
+    "wanted": "5.1.6",
+    "latest": "5.1.6",
+    "dependent": "global",
+    "location": "/usr/lib/node_modules/typescript"
+  }
+}`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "typescript",
+			Version:        "5.1.3",
+			NewVersion:     "5.1.6",
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: "npm",
+		},
+	}
+
+	actualPackageInfo, err := npm.ParseOutdatedOutput([]byte(inputParseOutdatedOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseOutdatedOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseOutdatedOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+
+	// npm outdated prints nothing when everything is up to date
+	actualPackageInfo, err = npm.ParseOutdatedOutput([]byte(""), &manager.Options{})
+	if err != nil || len(actualPackageInfo) != 0 {
+		t.Errorf("ParseOutdatedOutput() = %+v, %+v, want no packages and no error", actualPackageInfo, err)
+	}
+}
+
+func TestParseFindOutput(t *testing.T) {
+	var inputParseFindOutput string = `[
+  {"name": "typescript", "scope": "unscoped", "version": "5.1.6", "description": "TypeScript is a language for application scale JavaScript development"},
+  {"name": "@types/node", "scope": "types", "version": "20.4.5", "description": "TypeScript definitions for Node.js"}
+]`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "typescript",
+			NewVersion:     "5.1.6",
+			Category:       "unscoped",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "npm",
+		},
+		{
+			Name:           "@types/node",
+			NewVersion:     "20.4.5",
+			Category:       "types",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "npm",
+		},
+	}
+
+	actualPackageInfo, err := npm.ParseFindOutput([]byte(inputParseFindOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseFindOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseFindOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParsePackageInfoOutput(t *testing.T) {
+	var inputParsePackageInfoOutput string = `{
+  "name": "typescript",
+  "version": "5.1.6",
+  "license": "Apache-2.0",
+  "homepage": "https://www.typescriptlang.org/",
+  "maintainers": ["typescript-bot <typescript@microsoft.com>", "weswigham <wwigham@gmail.com>"]
+}`
+
+	var expectedPackageInfo = manager.PackageInfo{
+		Name:           "typescript",
+		NewVersion:     "5.1.6",
+		Status:         manager.PackageStatusAvailable,
+		License:        "Apache-2.0",
+		Homepage:       "https://www.typescriptlang.org/",
+		Maintainer:     "typescript-bot <typescript@microsoft.com>, weswigham <wwigham@gmail.com>",
+		PackageManager: "npm",
+	}
+
+	actualPackageInfo, err := npm.ParsePackageInfoOutput([]byte(inputParsePackageInfoOutput), &manager.Options{})
+	if err != nil || !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParsePackageInfoOutput() = %+v, %+v, want %+v", actualPackageInfo, err, expectedPackageInfo)
+	}
+}
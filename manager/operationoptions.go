@@ -0,0 +1,276 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+// SearchOptions are the options of searches and queries, such as Find, ListInstalled and GetPackageInfo. Unlike
+// Options, they only have the settings of these operations; they are converted to the Options of the package managers
+// with Options.
+type SearchOptions struct {
+	// Environment selects the environment to search, as Options.Environment.
+	Environment string
+
+	// Scope selects the installation to search, as Options.Scope.
+	Scope Scope
+
+	// Base are the options of how the commands of the operation are run, such as Context, Timeout and Verbose; its
+	// fields specific to other operations, such as AssumeYes and DryRun, are ignored.
+	Base Options
+}
+
+// SearchOption sets an option of SearchOptions.
+type SearchOption func(*SearchOptions)
+
+// NewSearchOptions returns the SearchOptions set by opts.
+func NewSearchOptions(opts ...SearchOption) *SearchOptions {
+	o := &SearchOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// SearchEnvironment sets SearchOptions.Environment.
+func SearchEnvironment(environment string) SearchOption {
+	return func(o *SearchOptions) { o.Environment = environment }
+}
+
+// SearchScope sets SearchOptions.Scope.
+func SearchScope(scope Scope) SearchOption {
+	return func(o *SearchOptions) { o.Scope = scope }
+}
+
+// SearchBase sets SearchOptions.Base.
+func SearchBase(base Options) SearchOption {
+	return func(o *SearchOptions) { o.Base = base }
+}
+
+// Options returns the Options of the search.
+func (o *SearchOptions) Options() *Options {
+	opts := o.Base
+	opts.Interactive, opts.AssumeYes, opts.DryRun = false, false, false
+	opts.DownloadOnly, opts.DownloadDir, opts.Architecture = false, "", ""
+	opts.Environment, opts.Scope = o.Environment, o.Scope
+	return &opts
+}
+
+// SearchOptionsFrom returns the SearchOptions of opts, or the default ones if nil.
+func SearchOptionsFrom(opts *Options) *SearchOptions {
+	if opts == nil {
+		return &SearchOptions{}
+	}
+	return &SearchOptions{Environment: opts.Environment, Scope: opts.Scope, Base: *opts}
+}
+
+// InstallOptions are the options of installs. Unlike Options, they only have the settings of installs; they are
+// converted to the Options of the package managers with Options.
+type InstallOptions struct {
+	// Interactive lets the package manager prompt the user, as Options.Interactive.
+	Interactive bool
+
+	// AssumeYes confirms the prompts of the package manager, as Options.AssumeYes.
+	AssumeYes bool
+
+	// DryRun simulates the install without making it, as Options.DryRun.
+	DryRun bool
+
+	// DownloadOnly downloads the packages to DownloadDir without installing them, as Options.DownloadOnly.
+	DownloadOnly bool
+
+	// DownloadDir is the directory where packages are downloaded, as Options.DownloadDir.
+	DownloadDir string
+
+	// Architecture is the architecture of the packages to install, as Options.Architecture.
+	Architecture string
+
+	// Environment selects the environment to install in, as Options.Environment.
+	Environment string
+
+	// Scope selects the installation to install in, as Options.Scope.
+	Scope Scope
+
+	// Base are the options of how the commands of the operation are run, such as Context, Timeout and Verbose; its
+	// fields set by the other fields of InstallOptions are ignored.
+	Base Options
+}
+
+// InstallOption sets an option of InstallOptions.
+type InstallOption func(*InstallOptions)
+
+// NewInstallOptions returns the InstallOptions set by opts.
+func NewInstallOptions(opts ...InstallOption) *InstallOptions {
+	o := &InstallOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// InstallInteractive sets InstallOptions.Interactive.
+func InstallInteractive() InstallOption {
+	return func(o *InstallOptions) { o.Interactive = true }
+}
+
+// InstallAssumeYes sets InstallOptions.AssumeYes.
+func InstallAssumeYes() InstallOption {
+	return func(o *InstallOptions) { o.AssumeYes = true }
+}
+
+// InstallDryRun sets InstallOptions.DryRun.
+func InstallDryRun() InstallOption {
+	return func(o *InstallOptions) { o.DryRun = true }
+}
+
+// InstallDownloadOnly sets InstallOptions.DownloadOnly, downloading the packages to dir, or the cache of the package
+// manager if empty.
+func InstallDownloadOnly(dir string) InstallOption {
+	return func(o *InstallOptions) { o.DownloadOnly, o.DownloadDir = true, dir }
+}
+
+// InstallArchitecture sets InstallOptions.Architecture.
+func InstallArchitecture(architecture string) InstallOption {
+	return func(o *InstallOptions) { o.Architecture = architecture }
+}
+
+// InstallEnvironment sets InstallOptions.Environment.
+func InstallEnvironment(environment string) InstallOption {
+	return func(o *InstallOptions) { o.Environment = environment }
+}
+
+// InstallScope sets InstallOptions.Scope.
+func InstallScope(scope Scope) InstallOption {
+	return func(o *InstallOptions) { o.Scope = scope }
+}
+
+// InstallBase sets InstallOptions.Base.
+func InstallBase(base Options) InstallOption {
+	return func(o *InstallOptions) { o.Base = base }
+}
+
+// Options returns the Options of the install.
+func (o *InstallOptions) Options() *Options {
+	opts := o.Base
+	opts.Interactive, opts.AssumeYes, opts.DryRun = o.Interactive, o.AssumeYes, o.DryRun
+	opts.DownloadOnly, opts.DownloadDir, opts.Architecture = o.DownloadOnly, o.DownloadDir, o.Architecture
+	opts.Environment, opts.Scope = o.Environment, o.Scope
+	return &opts
+}
+
+// InstallOptionsFrom returns the InstallOptions of opts, or the default ones if nil.
+func InstallOptionsFrom(opts *Options) *InstallOptions {
+	if opts == nil {
+		return &InstallOptions{}
+	}
+	return &InstallOptions{
+		Interactive:  opts.Interactive,
+		AssumeYes:    opts.AssumeYes,
+		DryRun:       opts.DryRun,
+		DownloadOnly: opts.DownloadOnly,
+		DownloadDir:  opts.DownloadDir,
+		Architecture: opts.Architecture,
+		Environment:  opts.Environment,
+		Scope:        opts.Scope,
+		Base:         *opts,
+	}
+}
+
+// UpgradeOptions are the options of upgrades. Unlike Options, they only have the settings of upgrades; they are
+// converted to the Options of the package managers with Options.
+type UpgradeOptions struct {
+	// Interactive lets the package manager prompt the user, as Options.Interactive.
+	Interactive bool
+
+	// AssumeYes confirms the prompts of the package manager, as Options.AssumeYes.
+	AssumeYes bool
+
+	// DryRun simulates the upgrade without making it, as Options.DryRun.
+	DryRun bool
+
+	// DownloadOnly downloads the upgrades to DownloadDir without installing them, as Options.DownloadOnly.
+	DownloadOnly bool
+
+	// DownloadDir is the directory where upgrades are downloaded, as Options.DownloadDir.
+	DownloadDir string
+
+	// Environment selects the environment to upgrade, as Options.Environment.
+	Environment string
+
+	// Scope selects the installation to upgrade, as Options.Scope.
+	Scope Scope
+
+	// Base are the options of how the commands of the operation are run, such as Context, Timeout and Verbose; its
+	// fields set by the other fields of UpgradeOptions, and Architecture, are ignored.
+	Base Options
+}
+
+// UpgradeOption sets an option of UpgradeOptions.
+type UpgradeOption func(*UpgradeOptions)
+
+// NewUpgradeOptions returns the UpgradeOptions set by opts.
+func NewUpgradeOptions(opts ...UpgradeOption) *UpgradeOptions {
+	o := &UpgradeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// UpgradeInteractive sets UpgradeOptions.Interactive.
+func UpgradeInteractive() UpgradeOption {
+	return func(o *UpgradeOptions) { o.Interactive = true }
+}
+
+// UpgradeAssumeYes sets UpgradeOptions.AssumeYes.
+func UpgradeAssumeYes() UpgradeOption {
+	return func(o *UpgradeOptions) { o.AssumeYes = true }
+}
+
+// UpgradeDryRun sets UpgradeOptions.DryRun.
+func UpgradeDryRun() UpgradeOption {
+	return func(o *UpgradeOptions) { o.DryRun = true }
+}
+
+// UpgradeDownloadOnly sets UpgradeOptions.DownloadOnly, downloading the upgrades to dir, or the cache of the package
+// manager if empty.
+func UpgradeDownloadOnly(dir string) UpgradeOption {
+	return func(o *UpgradeOptions) { o.DownloadOnly, o.DownloadDir = true, dir }
+}
+
+// UpgradeEnvironment sets UpgradeOptions.Environment.
+func UpgradeEnvironment(environment string) UpgradeOption {
+	return func(o *UpgradeOptions) { o.Environment = environment }
+}
+
+// UpgradeScope sets UpgradeOptions.Scope.
+func UpgradeScope(scope Scope) UpgradeOption {
+	return func(o *UpgradeOptions) { o.Scope = scope }
+}
+
+// UpgradeBase sets UpgradeOptions.Base.
+func UpgradeBase(base Options) UpgradeOption {
+	return func(o *UpgradeOptions) { o.Base = base }
+}
+
+// Options returns the Options of the upgrade.
+func (o *UpgradeOptions) Options() *Options {
+	opts := o.Base
+	opts.Interactive, opts.AssumeYes, opts.DryRun = o.Interactive, o.AssumeYes, o.DryRun
+	opts.DownloadOnly, opts.DownloadDir, opts.Architecture = o.DownloadOnly, o.DownloadDir, ""
+	opts.Environment, opts.Scope = o.Environment, o.Scope
+	return &opts
+}
+
+// UpgradeOptionsFrom returns the UpgradeOptions of opts, or the default ones if nil.
+func UpgradeOptionsFrom(opts *Options) *UpgradeOptions {
+	if opts == nil {
+		return &UpgradeOptions{}
+	}
+	return &UpgradeOptions{
+		Interactive:  opts.Interactive,
+		AssumeYes:    opts.AssumeYes,
+		DryRun:       opts.DryRun,
+		DownloadOnly: opts.DownloadOnly,
+		DownloadDir:  opts.DownloadDir,
+		Environment:  opts.Environment,
+		Scope:        opts.Scope,
+		Base:         *opts,
+	}
+}
@@ -0,0 +1,61 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestSearchOptions(t *testing.T) {
+	base := manager.Options{Verbose: true, Timeout: time.Minute, AssumeYes: true, DryRun: true, Scope: manager.ScopeSystem}
+	actual := manager.NewSearchOptions(manager.SearchBase(base), manager.SearchScope(manager.ScopeUser)).Options()
+	expected := &manager.Options{Verbose: true, Timeout: time.Minute, Scope: manager.ScopeUser}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Options() = %+v, want %+v", actual, expected)
+	}
+
+	legacy := &manager.Options{Verbose: true, Environment: "ml", Scope: manager.ScopeUser}
+	if actual := manager.SearchOptionsFrom(legacy).Options(); !reflect.DeepEqual(actual, legacy) {
+		t.Errorf("SearchOptionsFrom(%+v).Options() = %+v, want the same options", legacy, actual)
+	}
+	if actual := manager.SearchOptionsFrom(nil).Options(); !reflect.DeepEqual(actual, &manager.Options{}) {
+		t.Errorf("SearchOptionsFrom(nil).Options() = %+v, want the default options", actual)
+	}
+}
+
+func TestInstallOptions(t *testing.T) {
+	actual := manager.NewInstallOptions(
+		manager.InstallBase(manager.Options{Debug: true, DryRun: true}),
+		manager.InstallAssumeYes(),
+		manager.InstallDownloadOnly("/tmp/packages"),
+		manager.InstallArchitecture("i386"),
+	).Options()
+	expected := &manager.Options{Debug: true, AssumeYes: true, DownloadOnly: true, DownloadDir: "/tmp/packages", Architecture: "i386"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Options() = %+v, want %+v", actual, expected)
+	}
+
+	legacy := &manager.Options{Interactive: true, DryRun: true, Architecture: "arm64", Environment: "ml", RootDir: "/mnt"}
+	if actual := manager.InstallOptionsFrom(legacy).Options(); !reflect.DeepEqual(actual, legacy) {
+		t.Errorf("InstallOptionsFrom(%+v).Options() = %+v, want the same options", legacy, actual)
+	}
+}
+
+func TestUpgradeOptions(t *testing.T) {
+	actual := manager.NewUpgradeOptions(
+		manager.UpgradeBase(manager.Options{Architecture: "i386", Nice: 10}),
+		manager.UpgradeDryRun(),
+		manager.UpgradeEnvironment("ml"),
+	).Options()
+	expected := &manager.Options{DryRun: true, Environment: "ml", Nice: 10}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Options() = %+v, want %+v", actual, expected)
+	}
+
+	legacy := &manager.Options{AssumeYes: true, DownloadOnly: true, Scope: manager.ScopeSystem}
+	if actual := manager.UpgradeOptionsFrom(legacy).Options(); !reflect.DeepEqual(actual, legacy) {
+		t.Errorf("UpgradeOptionsFrom(%+v).Options() = %+v, want the same options", legacy, actual)
+	}
+}
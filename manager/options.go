@@ -1,7 +1,13 @@
 // Package manager provides utilities for managing the application.
 package manager
 
+import (
+	"context"
+	"time"
+)
+
 // Options represents the various configuration options for the application.
+// SearchOptions, InstallOptions and UpgradeOptions have only the settings of their operation, and convert to Options.
 type Options struct {
 	// Interactive indicates whether the application should run in interactive mode.
 	Interactive bool
@@ -18,6 +24,92 @@ type Options struct {
 	// Debug indicates whether the application should run in debug mode, providing more detailed information about its internal operations.
 	Debug bool
 
+	// Environment selects the environment to operate on, for package managers that manage several environments (e.g. conda).
+	// An empty value means the default (currently active) environment. Other package managers ignore it.
+	Environment string
+
+	// Scope selects the installation to operate on, per-user or system-wide, for package managers that have both
+	// (e.g. flatpak). An empty value means the default of the package manager. Other package managers ignore it.
+	Scope Scope
+
+	// DownloadOnly makes Install download the packages and their missing dependencies without installing them,
+	// for package managers implementing syspkg.Downloader. Other package managers ignore it.
+	DownloadOnly bool
+
+	// DownloadDir is the directory where packages are downloaded, instead of the cache of the package manager.
+	DownloadDir string
+
+	// RootDir is the root directory of the system to operate on instead of the running one, such as an image being
+	// built, or a system mounted from a rescue one. It is passed to the commands of the package managers supporting
+	// it, with their native option: apt -o Dir=, dpkg --root, rpm and zypper --root, apk --root and pacman --root.
+	// Other package managers ignore it.
+	RootDir string
+
+	// Architecture is the architecture of the packages to install or remove, such as i386 on an amd64 system, for
+	// package managers supporting foreign architectures: apt qualifies the package names as name:arch, and zypper as
+	// name.arch. The architecture must be enabled, e.g. with dpkg --add-architecture. An empty value means the native
+	// architecture, or the one given with the package names. Other package managers ignore it.
+	Architecture string
+
+	// Licenses makes ListInstalled and GetPackageInfo read the licenses of the installed packages, such as for SBOMs,
+	// for package managers reading them from a file per package rather than their database (apt). Other package
+	// managers report the licenses of their database anyway.
+	Licenses bool
+
+	// Context interrupts the commands run by operations when done, such as when syspkg is interrupted: they are sent
+	// SIGINT, and killed if still running after InterruptGracePeriod. Commands are never interrupted if nil.
+	Context context.Context
+
+	// Timeout is the maximum duration of each command run by an operation, after which the command is killed.
+	// Zero means no timeout.
+	Timeout time.Duration
+
+	// Nice is the niceness added to the commands run by operations, from 1 to 19, to run them at a lower CPU
+	// priority, through nice. Zero keeps the priority of syspkg.
+	Nice int
+
+	// IdleIO runs the commands of operations in the idle I/O scheduling class, through ionice, so that they only use
+	// the disks when no other process does. It has no effect where ionice is not available.
+	IdleIO bool
+
+	// Env are environment variables set for every command run by operations, overriding the ones of syspkg and the
+	// defaults of the package managers, by name. The package managers parse the output of their commands in the C
+	// locale, which Env should not override.
+	Env map[string]string
+
+	// LockWait is how long to wait for the lock of the package manager when another process holds it, e.g.
+	// unattended-upgrades, before failing with ErrLocked. Zero means failing at once.
+	LockWait time.Duration
+
+	// AutoRefresh makes operations failing because the package index is missing or stale refresh it, and run again once,
+	// for package managers detecting it (apt). Dry runs never refresh the index.
+	AutoRefresh bool
+
+	// Proxy is the URL of the HTTP proxy the package managers download through, for HTTP and HTTPS, such as
+	// http://proxy.example.com:3128. Package managers use their own proxy settings if empty.
+	Proxy string
+
+	// NoProxy is a comma-separated list of hosts and domains downloaded from without Proxy.
+	NoProxy string
+
+	// Mirrors are the URLs of the repositories or registries to download from instead of the default ones, by package
+	// manager name (e.g. "pip": "https://pypi.example.com/simple"). Package managers without a mirror setting ignore them.
+	Mirrors map[string]string
+
+	// Progress receives the progress of long-running operations such as installs and upgrades, if set.
+	// Only some package managers report progress, and only when not running interactively.
+	Progress ProgressReporter
+
+	// Wrapper runs the commands of operations through another command, if set, such as the fixture package recording
+	// them and their results, or replaying recorded results instead of running them.
+	Wrapper CommandWrapper
+
+	// Transcript records the commands run by operations, if set, such as to report them with their results.
+	Transcript *Transcript
+
+	// Logger receives the logs of the operations; the default slog logger if nil.
+	Logger Logger
+
 	// CustomCommandArgs is a slice of strings that can be used to pass additional custom arguments to the application.
 	CustomCommandArgs []string
 }
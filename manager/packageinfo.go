@@ -1,6 +1,8 @@
 // Package manager provides utilities for managing the application.
 package manager
 
+import "encoding/json"
+
 // PackageStatus represents the current status of a package in the system.
 type PackageStatus string
 
@@ -21,31 +23,108 @@ const (
 
 	// PackageStatusConfigFiles represents a package that has only configuration files remaining on the system.
 	PackageStatusConfigFiles PackageStatus = "config-files"
+
+	// PackageStatusBroken represents an installed package that failed verification, e.g. because some of its files are missing.
+	PackageStatusBroken PackageStatus = "broken"
+
+	// PackageStatusHeld represents an installed package held (pinned or locked) at its current version, which is not upgraded.
+	PackageStatusHeld PackageStatus = "held"
 )
 
 // PackageInfo contains information about a specific package.
 type PackageInfo struct {
 	// Name is the package name.
-	Name string
+	Name string `json:"name"`
 
 	// Version is the currently installed version of the package.
-	Version string
+	Version string `json:"version,omitempty"`
 
 	// NewVersion is the latest available version of the package. This field can be empty for installed and available packages.
-	NewVersion string
+	NewVersion string `json:"new_version,omitempty"`
 
 	// Status indicates the current PackageStatus of the package.
-	Status PackageStatus
+	Status PackageStatus `json:"status,omitempty"`
 
 	// Category is the category the package belongs to, such as "utilities" or "development".
-	Category string
+	Category string `json:"category,omitempty"`
 
 	// Arch is the architecture the package is built for, such as "amd64" or "arm64".
-	Arch string
+	Arch string `json:"arch,omitempty"`
+
+	// License is the license of the package as declared by the package manager, such as "GPL-3+" or "MIT".
+	// It is not necessarily a valid SPDX license expression.
+	License string `json:"license,omitempty"`
+
+	// Homepage is the URL of the website of the package, such as "https://www.vim.org/".
+	Homepage string `json:"homepage,omitempty"`
+
+	// Maintainer is the maintainer or publisher of the package, such as "Debian Vim Maintainers <team+vim@tracker.debian.org>".
+	Maintainer string `json:"maintainer,omitempty"`
+
+	// SizeInstalled is the disk space used by the installed package, in bytes, or 0 if unknown.
+	SizeInstalled int64 `json:"size_installed,omitempty"`
+
+	// SizeDownload is the size of the package file downloaded to install the package, in bytes, or 0 if unknown.
+	SizeDownload int64 `json:"size_download,omitempty"`
+
+	// Origin is where the package comes from, as named by the package manager: the origin of the apt repository, such
+	// as "Ubuntu" or "LP-PPA-deadsnakes", the flatpak remote, the snap publisher, the repository of zypper, pacman and
+	// portage, or the conda channel. It is empty if unknown, e.g. for packages installed from local files.
+	Origin string `json:"origin,omitempty"`
 
 	// PackageManager is the name of the package manager used to manage this package, such as "apt" or "yum".
-	PackageManager string
+	PackageManager string `json:"package_manager,omitempty"`
 
 	// AdditionalData is a map of key-value pairs that store any additional package-specific data.
-	AdditionalData map[string]string
+	AdditionalData map[string]string `json:"additional_data,omitempty"`
+}
+
+// UnmarshalJSON decodes a package, also accepting the field names used before PackageInfo had JSON tags,
+// so that the snapshots and history entries written by older versions of syspkg can still be read.
+func (p *PackageInfo) UnmarshalJSON(data []byte) error {
+	type packageInfo PackageInfo
+	var decoded struct {
+		packageInfo
+		LegacyNewVersion     string            `json:"NewVersion"`
+		LegacyPackageManager string            `json:"PackageManager"`
+		LegacyAdditionalData map[string]string `json:"AdditionalData"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*p = PackageInfo(decoded.packageInfo)
+	if p.NewVersion == "" {
+		p.NewVersion = decoded.LegacyNewVersion
+	}
+	if p.PackageManager == "" {
+		p.PackageManager = decoded.LegacyPackageManager
+	}
+	if p.AdditionalData == nil {
+		p.AdditionalData = decoded.LegacyAdditionalData
+	}
+	return nil
+}
+
+// QualifyMultiArch returns packages with the names of the ones listed for several architectures by the same package
+// manager, such as libc6 installed for both amd64 and i386, qualified with their architecture as "name:arch", so that
+// they are told apart when printed. The other packages are returned unchanged.
+func QualifyMultiArch(packages []PackageInfo) []PackageInfo {
+	archs := make(map[[2]string]map[string]bool)
+	for _, pkg := range packages {
+		key := [2]string{pkg.PackageManager, pkg.Name}
+		if archs[key] == nil {
+			archs[key] = make(map[string]bool)
+		}
+		archs[key][pkg.Arch] = true
+	}
+
+	qualified := make([]PackageInfo, len(packages))
+	for i, pkg := range packages {
+		if len(archs[[2]string{pkg.PackageManager, pkg.Name}]) > 1 && pkg.Arch != "" {
+			pkg.Name += ":" + pkg.Arch
+		}
+		qualified[i] = pkg
+	}
+	return qualified
 }
@@ -0,0 +1,64 @@
+package manager_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestPackageInfoJSON(t *testing.T) {
+	pkg := manager.PackageInfo{
+		Name:           "vim",
+		Version:        "2:8.2.3995-1ubuntu2.15",
+		NewVersion:     "2:8.2.3995-1ubuntu2.16",
+		Status:         manager.PackageStatusUpgradable,
+		Arch:           "amd64",
+		PackageManager: "apt",
+		AdditionalData: map[string]string{"severity": "medium"},
+	}
+
+	data, err := json.Marshal(pkg)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %+v", err)
+	}
+	want := `{"name":"vim","version":"2:8.2.3995-1ubuntu2.15","new_version":"2:8.2.3995-1ubuntu2.16","status":"upgradable","arch":"amd64","package_manager":"apt","additional_data":{"severity":"medium"}}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	for _, input := range []string{
+		want,
+		`{"Name":"vim","Version":"2:8.2.3995-1ubuntu2.15","NewVersion":"2:8.2.3995-1ubuntu2.16","Status":"upgradable","Category":"","Arch":"amd64","PackageManager":"apt","AdditionalData":{"severity":"medium"}}`,
+	} {
+		var decoded manager.PackageInfo
+		if err := json.Unmarshal([]byte(input), &decoded); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %+v", input, err)
+		}
+		if !reflect.DeepEqual(decoded, pkg) {
+			t.Errorf("json.Unmarshal(%s) = %+v, want %+v", input, decoded, pkg)
+		}
+	}
+}
+
+func TestQualifyMultiArch(t *testing.T) {
+	packages := []manager.PackageInfo{
+		{Name: "libc6", Arch: "amd64", PackageManager: "apt"},
+		{Name: "libc6", Arch: "i386", PackageManager: "apt"},
+		{Name: "vim", Arch: "amd64", PackageManager: "apt"},
+		{Name: "vim", Arch: "x86_64", PackageManager: "zypper"},
+	}
+	expected := []manager.PackageInfo{
+		{Name: "libc6:amd64", Arch: "amd64", PackageManager: "apt"},
+		{Name: "libc6:i386", Arch: "i386", PackageManager: "apt"},
+		{Name: "vim", Arch: "amd64", PackageManager: "apt"},
+		{Name: "vim", Arch: "x86_64", PackageManager: "zypper"},
+	}
+	if actual := manager.QualifyMultiArch(packages); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("QualifyMultiArch() = %+v, want %+v", actual, expected)
+	}
+	if packages[0].Name != "libc6" {
+		t.Errorf("QualifyMultiArch() modified its argument: %+v", packages[0])
+	}
+}
@@ -0,0 +1,371 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SortKey is the field lists of packages are sorted by.
+type SortKey string
+
+// SortKey constants define the orders of lists of packages.
+const (
+	// SortKeyName sorts packages by name, then by package manager.
+	SortKeyName SortKey = "name"
+
+	// SortKeySize sorts packages by installed size, largest first.
+	SortKeySize SortKey = "size"
+
+	// SortKeyVersion sorts packages by version, oldest first, comparing versions with CompareVersions.
+	SortKeyVersion SortKey = "version"
+
+	// SortKeyManager sorts packages by package manager, then by name.
+	SortKeyManager SortKey = "manager"
+)
+
+// SortKeys are the valid sort keys, in the order they are documented.
+var SortKeys = []SortKey{SortKeyName, SortKeySize, SortKeyVersion, SortKeyManager}
+
+// filterOperators are the operators of filter expressions, the longer ones first so that "!=" is not read as "!".
+var filterOperators = []string{"!=", "!~", ">=", "<=", "=", "~", ">", "<"}
+
+// PackageFilter is a condition on a field of packages, such as status=upgradable or name~^lib, parsed by
+// ParsePackageFilter.
+type PackageFilter struct {
+	// Field is the field of the package the condition is on, such as "name" or "status".
+	Field string
+
+	// Operator is the comparison: = and != compare values exactly, ~ and !~ match a regular expression, and
+	// >, >=, < and <= compare sizes, or versions with CompareVersions.
+	Operator string
+
+	// Value is the value the field is compared with.
+	Value string
+
+	pattern *regexp.Regexp
+	size    int64
+}
+
+// ParsePackageFilter parses a filter expression, a field, an operator and a value, such as "status=upgradable",
+// "name~^lib", "manager!=snap", "size>100MB" or "version<2.0". The fields are name, version, new_version, status,
+// category, arch, license, homepage, maintainer, manager, size and size_download.
+func ParsePackageFilter(expr string) (PackageFilter, error) {
+	i := strings.IndexAny(expr, "=!~<>")
+	if i <= 0 {
+		return PackageFilter{}, fmt.Errorf("invalid filter %q, expected a field, an operator and a value, e.g. status=upgradable", expr)
+	}
+
+	f := PackageFilter{Field: strings.TrimSpace(expr[:i])}
+	for _, op := range filterOperators {
+		if strings.HasPrefix(expr[i:], op) {
+			f.Operator, f.Value = op, expr[i+len(op):]
+			break
+		}
+	}
+	if f.Operator == "" {
+		return PackageFilter{}, fmt.Errorf("invalid filter %q: unknown operator", expr)
+	}
+	if _, ok := packageField(PackageInfo{}, f.Field); !ok {
+		return PackageFilter{}, fmt.Errorf("invalid filter %q: unknown field %q", expr, f.Field)
+	}
+
+	var err error
+	switch f.Operator {
+	case "~", "!~":
+		if f.pattern, err = regexp.Compile(f.Value); err != nil {
+			return PackageFilter{}, fmt.Errorf("invalid filter %q: %w", expr, err)
+		}
+	case ">", ">=", "<", "<=":
+		switch f.Field {
+		case "size", "size_download":
+			if f.size, err = ParseSize(f.Value); err != nil {
+				return PackageFilter{}, fmt.Errorf("invalid filter %q: %w", expr, err)
+			}
+		case "version", "new_version":
+		default:
+			return PackageFilter{}, fmt.Errorf("invalid filter %q: %s can't be compared with %s", expr, f.Field, f.Operator)
+		}
+	}
+	return f, nil
+}
+
+// Match reports whether pkg matches the filter. Packages whose size or version is unknown don't match comparisons.
+func (f PackageFilter) Match(pkg PackageInfo) bool {
+	value, _ := packageField(pkg, f.Field)
+	switch f.Operator {
+	case "=":
+		return value == f.Value
+	case "!=":
+		return value != f.Value
+	case "~":
+		return f.pattern.MatchString(value)
+	case "!~":
+		return !f.pattern.MatchString(value)
+	}
+
+	var order int
+	switch f.Field {
+	case "size", "size_download":
+		size := pkg.SizeInstalled
+		if f.Field == "size_download" {
+			size = pkg.SizeDownload
+		}
+		if size == 0 {
+			return false
+		}
+		order = cmp.Compare(size, f.size)
+	default:
+		if value == "" {
+			return false
+		}
+		order = CompareVersions(value, f.Value)
+	}
+	switch f.Operator {
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	case "<":
+		return order < 0
+	}
+	return order <= 0
+}
+
+// PackageListProcessor filters and sorts the lists of packages returned by package managers, such as the installed
+// packages or the results of a search, so that all the output formats list the same packages in the same order.
+type PackageListProcessor struct {
+	// SortBy is the order of the packages, or empty to keep the order of the package manager, or to rank them by
+	// Keywords.
+	SortBy SortKey
+
+	// Filters are the conditions the packages must all match to be kept.
+	Filters []PackageFilter
+
+	// Keywords are the keywords of a search: unless SortBy is set, the packages are ranked by how well their name
+	// matches them, as returned by SearchRank, and the packages of the same rank are interleaved across package
+	// managers.
+	Keywords []string
+
+	// Exact keeps only the packages whose name is one of the Keywords.
+	Exact bool
+
+	// Patterns keep only the packages whose name matches one of them, if any.
+	Patterns []*Pattern
+
+	// Limit is the maximum number of packages kept, once sorted, or 0 for no limit.
+	Limit int
+}
+
+// SearchRank constants rank the results of a search, the best matches first.
+const (
+	// SearchRankExact is the rank of packages whose name is a keyword, case-insensitively.
+	SearchRankExact = iota
+
+	// SearchRankPrefix is the rank of packages whose name starts with a keyword.
+	SearchRankPrefix
+
+	// SearchRankName is the rank of packages whose name contains a keyword.
+	SearchRankName
+
+	// SearchRankDescription is the rank of the other packages, found by the package manager from their description.
+	SearchRankDescription
+)
+
+// SearchRank returns the rank of a package found by searching keywords: SearchRankExact, SearchRankPrefix,
+// SearchRankName or SearchRankDescription, for the keyword it matches best. Flatpak applications are matched by
+// the last component of their IDs too, as returned by DuplicateKey.
+func SearchRank(pkg PackageInfo, keywords []string) int {
+	rank := SearchRankDescription
+	names := []string{strings.ToLower(pkg.Name), DuplicateKey(pkg)}
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+		for _, name := range names {
+			switch {
+			case name == keyword:
+				return SearchRankExact
+			case strings.HasPrefix(name, keyword):
+				rank = min(rank, SearchRankPrefix)
+			case strings.Contains(name, keyword):
+				rank = min(rank, SearchRankName)
+			}
+		}
+	}
+	return rank
+}
+
+// NewPackageListProcessor returns a processor sorting packages by sortBy, if not empty, and keeping the packages
+// matching all the filter expressions, parsed by ParsePackageFilter.
+func NewPackageListProcessor(sortBy string, filters []string) (*PackageListProcessor, error) {
+	p := &PackageListProcessor{SortBy: SortKey(sortBy)}
+	if sortBy != "" && !slices.Contains(SortKeys, p.SortBy) {
+		return nil, fmt.Errorf("invalid sort key %q, expected name, size, version or manager", sortBy)
+	}
+	for _, expr := range filters {
+		f, err := ParsePackageFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		p.Filters = append(p.Filters, f)
+	}
+	return p, nil
+}
+
+// Ordered reports whether the processor orders packages across package managers, by SortBy or by rank, so that
+// the packages of all package managers are listed together rather than one package manager after the other.
+func (p *PackageListProcessor) Ordered() bool {
+	return p != nil && (p.SortBy != "" || len(p.Keywords) > 0)
+}
+
+// Process returns the packages matching the filters, sorted, and at most Limit of them. A nil processor returns
+// the packages unchanged.
+func (p *PackageListProcessor) Process(packages []PackageInfo) []PackageInfo {
+	if p == nil {
+		return packages
+	}
+
+	if len(p.Filters) > 0 || p.Exact || len(p.Patterns) > 0 {
+		kept := make([]PackageInfo, 0, len(packages))
+		for _, pkg := range packages {
+			if p.match(pkg) {
+				kept = append(kept, pkg)
+			}
+		}
+		packages = kept
+	}
+
+	switch p.SortBy {
+	case SortKeySize:
+		SortBySize(packages)
+	case SortKeyName, SortKeyVersion, SortKeyManager:
+		sort.SliceStable(packages, func(i, j int) bool {
+			return comparePackages(packages[i], packages[j], p.SortBy) < 0
+		})
+	default:
+		if len(p.Keywords) > 0 {
+			packages = p.rank(packages)
+		}
+	}
+
+	if p.Limit > 0 && len(packages) > p.Limit {
+		packages = packages[:p.Limit]
+	}
+	return packages
+}
+
+// match reports whether pkg matches all the filters and one of the patterns, and is an exact match of the keywords
+// with Exact.
+func (p *PackageListProcessor) match(pkg PackageInfo) bool {
+	if p.Exact && SearchRank(pkg, p.Keywords) != SearchRankExact {
+		return false
+	}
+	if len(p.Patterns) > 0 && !slices.ContainsFunc(p.Patterns, func(pattern *Pattern) bool { return pattern.Match(pkg.Name) }) {
+		return false
+	}
+	for _, f := range p.Filters {
+		if !f.Match(pkg) {
+			return false
+		}
+	}
+	return true
+}
+
+// rank returns the packages ordered by SearchRank. The packages of the same rank are interleaved across package
+// managers, taken in turn from each package manager, sorted by name, in the order each package manager listed them,
+// so that no package manager's results push all the others down.
+func (p *PackageListProcessor) rank(packages []PackageInfo) []PackageInfo {
+	byRank := make([]map[string][]PackageInfo, SearchRankDescription+1)
+	for _, pkg := range packages {
+		rank := SearchRank(pkg, p.Keywords)
+		if byRank[rank] == nil {
+			byRank[rank] = make(map[string][]PackageInfo)
+		}
+		byRank[rank][pkg.PackageManager] = append(byRank[rank][pkg.PackageManager], pkg)
+	}
+
+	ranked := make([]PackageInfo, 0, len(packages))
+	for _, byManager := range byRank {
+		managers := make([]string, 0, len(byManager))
+		for pm := range byManager {
+			managers = append(managers, pm)
+		}
+		sort.Strings(managers)
+
+		for i := 0; len(byManager) > 0; i++ {
+			for _, pm := range managers {
+				pkgs, ok := byManager[pm]
+				if !ok {
+					continue
+				}
+				ranked = append(ranked, pkgs[i])
+				if i == len(pkgs)-1 {
+					delete(byManager, pm)
+				}
+			}
+		}
+	}
+	return ranked
+}
+
+// comparePackages compares two packages in the order of key, then by name and package manager.
+func comparePackages(a, b PackageInfo, key SortKey) int {
+	switch key {
+	case SortKeyVersion:
+		if order := CompareVersions(sortVersion(a), sortVersion(b)); order != 0 {
+			return order
+		}
+	case SortKeyManager:
+		if order := strings.Compare(a.PackageManager, b.PackageManager); order != 0 {
+			return order
+		}
+	}
+	if order := strings.Compare(a.Name, b.Name); order != 0 {
+		return order
+	}
+	return strings.Compare(a.PackageManager, b.PackageManager)
+}
+
+// sortVersion returns the version packages are sorted by: the installed one, or the available one for the packages
+// that are not installed, such as search results.
+func sortVersion(pkg PackageInfo) string {
+	if pkg.Version != "" {
+		return pkg.Version
+	}
+	return pkg.NewVersion
+}
+
+// packageField returns the value of a field of pkg, by the name used in filter expressions, and whether the field
+// exists. Sizes are returned in bytes.
+func packageField(pkg PackageInfo, field string) (string, bool) {
+	switch field {
+	case "name":
+		return pkg.Name, true
+	case "version":
+		return pkg.Version, true
+	case "new_version":
+		return pkg.NewVersion, true
+	case "status":
+		return string(pkg.Status), true
+	case "category":
+		return pkg.Category, true
+	case "arch":
+		return pkg.Arch, true
+	case "license":
+		return pkg.License, true
+	case "homepage":
+		return pkg.Homepage, true
+	case "maintainer":
+		return pkg.Maintainer, true
+	case "manager", "package_manager":
+		return pkg.PackageManager, true
+	case "size":
+		return fmt.Sprint(pkg.SizeInstalled), true
+	case "size_download":
+		return fmt.Sprint(pkg.SizeDownload), true
+	}
+	return "", false
+}
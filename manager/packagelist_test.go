@@ -0,0 +1,129 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestParsePackageFilter(t *testing.T) {
+	expectedFilter := manager.PackageFilter{Field: "status", Operator: "!=", Value: "held"}
+	actualFilter, err := manager.ParsePackageFilter("status!=held")
+	if err != nil || actualFilter.Field != expectedFilter.Field || actualFilter.Operator != expectedFilter.Operator || actualFilter.Value != expectedFilter.Value {
+		t.Errorf("ParsePackageFilter() = %+v, %+v, want %+v", actualFilter, err, expectedFilter)
+	}
+
+	for _, expr := range []string{"upgradable", "=upgradable", "color=red", "name~[", "name>vim", "size>huge"} {
+		if _, err := manager.ParsePackageFilter(expr); err == nil {
+			t.Errorf("ParsePackageFilter(%q) error = nil, want an error", expr)
+		}
+	}
+}
+
+func TestPackageListProcessor(t *testing.T) {
+	packages := []manager.PackageInfo{
+		{Name: "vim", Version: "9.0.1378", NewVersion: "9.0.1499", Status: manager.PackageStatusUpgradable, SizeInstalled: 3_800_000, PackageManager: "apt"},
+		{Name: "libc6", Version: "2.36-9", Status: manager.PackageStatusInstalled, SizeInstalled: 12_900_000, PackageManager: "apt"},
+		{Name: "libssl3", Version: "3.0.11-1", NewVersion: "3.0.13-1", Status: manager.PackageStatusUpgradable, SizeInstalled: 6_200_000, PackageManager: "apt"},
+		{Name: "firefox", Version: "124.0.1-1", Status: manager.PackageStatusInstalled, SizeInstalled: 260_000_000, PackageManager: "snap"},
+		{Name: "hello", Version: "2.10", Status: manager.PackageStatusInstalled, PackageManager: "snap"},
+	}
+
+	tests := []struct {
+		sortBy   string
+		filters  []string
+		expected []string
+	}{
+		{"", nil, []string{"vim", "libc6", "libssl3", "firefox", "hello"}},
+		{"name", nil, []string{"firefox", "hello", "libc6", "libssl3", "vim"}},
+		{"size", nil, []string{"firefox", "libc6", "libssl3", "vim", "hello"}},
+		{"version", nil, []string{"hello", "libc6", "libssl3", "vim", "firefox"}},
+		{"manager", nil, []string{"libc6", "libssl3", "vim", "firefox", "hello"}},
+		{"", []string{"status=upgradable"}, []string{"vim", "libssl3"}},
+		{"name", []string{"name~^lib", "status!=upgradable"}, []string{"libc6"}},
+		{"size", []string{"size>=5MB"}, []string{"firefox", "libc6", "libssl3"}},
+		{"", []string{"manager=snap", "version<100"}, []string{"hello"}},
+	}
+	for _, tt := range tests {
+		p, err := manager.NewPackageListProcessor(tt.sortBy, tt.filters)
+		if err != nil {
+			t.Fatalf("NewPackageListProcessor(%q, %q) error = %+v", tt.sortBy, tt.filters, err)
+		}
+
+		var actual []string
+		for _, pkg := range p.Process(append([]manager.PackageInfo(nil), packages...)) {
+			actual = append(actual, pkg.Name)
+		}
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("Process(%q, %q) = %+v, want %+v", tt.sortBy, tt.filters, actual, tt.expected)
+		}
+	}
+
+	glob, _ := manager.ParsePattern("lib*", false)
+	regex, _ := manager.ParsePattern("^(vim|hello)$", true)
+	p := &manager.PackageListProcessor{SortBy: manager.SortKeyName, Patterns: []*manager.Pattern{glob, regex}}
+	var actual []string
+	for _, pkg := range p.Process(append([]manager.PackageInfo(nil), packages...)) {
+		actual = append(actual, pkg.Name)
+	}
+	if expected := []string{"hello", "libc6", "libssl3", "vim"}; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Process(patterns) = %+v, want %+v", actual, expected)
+	}
+
+	if _, err := manager.NewPackageListProcessor("date", nil); err == nil {
+		t.Errorf("NewPackageListProcessor(date) error = nil, want an error")
+	}
+}
+
+func TestSearchRank(t *testing.T) {
+	tests := []struct {
+		pkg  manager.PackageInfo
+		rank int
+	}{
+		{manager.PackageInfo{Name: "Firefox", PackageManager: "snap"}, manager.SearchRankExact},
+		{manager.PackageInfo{Name: "org.mozilla.firefox", PackageManager: "flatpak"}, manager.SearchRankExact},
+		{manager.PackageInfo{Name: "firefox-esr", PackageManager: "apt"}, manager.SearchRankPrefix},
+		{manager.PackageInfo{Name: "webext-firefox", PackageManager: "apt"}, manager.SearchRankName},
+		{manager.PackageInfo{Name: "iceweasel", PackageManager: "apt"}, manager.SearchRankDescription},
+	}
+	for _, tt := range tests {
+		if rank := manager.SearchRank(tt.pkg, []string{"browser", "firefox"}); rank != tt.rank {
+			t.Errorf("SearchRank(%s) = %d, want %d", tt.pkg.Name, rank, tt.rank)
+		}
+	}
+}
+
+func TestPackageListProcessorRank(t *testing.T) {
+	packages := []manager.PackageInfo{
+		{Name: "vim-gtk3", PackageManager: "apt"},
+		{Name: "neovim", PackageManager: "apt"},
+		{Name: "vim", PackageManager: "apt"},
+		{Name: "vim-tiny", PackageManager: "apt"},
+		{Name: "vim-editor", PackageManager: "snap"},
+		{Name: "vi-improved", PackageManager: "snap"},
+		{Name: "vim", PackageManager: "snap"},
+		{Name: "vim-plug", PackageManager: "npm"},
+	}
+
+	tests := []struct {
+		exact    bool
+		limit    int
+		expected []string
+	}{
+		{false, 0, []string{"apt vim", "snap vim", "apt vim-gtk3", "npm vim-plug", "snap vim-editor", "apt vim-tiny", "apt neovim", "snap vi-improved"}},
+		{false, 4, []string{"apt vim", "snap vim", "apt vim-gtk3", "npm vim-plug"}},
+		{true, 0, []string{"apt vim", "snap vim"}},
+	}
+	for _, tt := range tests {
+		p := &manager.PackageListProcessor{Keywords: []string{"vim"}, Exact: tt.exact, Limit: tt.limit}
+
+		var actual []string
+		for _, pkg := range p.Process(append([]manager.PackageInfo(nil), packages...)) {
+			actual = append(actual, pkg.PackageManager+" "+pkg.Name)
+		}
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("Process(exact %t, limit %d) = %+v, want %+v", tt.exact, tt.limit, actual, tt.expected)
+		}
+	}
+}
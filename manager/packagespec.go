@@ -0,0 +1,108 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PackageSpec is a package to install, with an optional version.
+type PackageSpec struct {
+	// Name is the name of the package.
+	Name string
+
+	// Version is the exact version to install, or empty for the version chosen by the package manager (usually the newest).
+	Version string
+}
+
+// ParsePackageSpec parses a package specifier: "name", or "name=version" (also written "name==version").
+// Other syntaxes, such as version constraints ("name>=1.0") or the native specifiers of package managers
+// (npm's "name@version", rpm's "name-version"), are not parsed: the whole specifier is returned as the name,
+// to be passed unchanged to the package manager.
+func ParsePackageSpec(s string) (PackageSpec, error) {
+	name, version, found := strings.Cut(s, "=")
+	if !found || strings.ContainsAny(name, "<>!~") {
+		return PackageSpec{Name: s}, nil
+	}
+
+	version = strings.TrimPrefix(version, "=")
+	if name == "" || version == "" || strings.ContainsAny(version, "<>=!~") {
+		return PackageSpec{}, fmt.Errorf("%w: %q, expected name=version", ErrInvalidPackageSpec, s)
+	}
+	return PackageSpec{Name: name, Version: version}, nil
+}
+
+// String returns the specifier as "name=version", or "name" if no version is requested.
+func (s PackageSpec) String() string {
+	if s.Version == "" {
+		return s.Name
+	}
+	return s.Name + "=" + s.Version
+}
+
+// TranslatePackageSpecs rewrites the "name=version" specifiers of pkgs in the native syntax of a package manager,
+// as returned by format. Specifiers without a version are left untouched.
+func TranslatePackageSpecs(pkgs []string, format func(PackageSpec) string) ([]string, error) {
+	translated := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		spec, err := ParsePackageSpec(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if spec.Version != "" {
+			pkg = format(spec)
+		}
+		translated = append(translated, pkg)
+	}
+	return translated, nil
+}
+
+// RequireVersionSpecs returns an error wrapping ErrInvalidPackageSpec if any of pkgs doesn't request a specific version,
+// for operations such as downgrades that need one.
+func RequireVersionSpecs(pkgs []string) error {
+	for _, pkg := range pkgs {
+		spec, err := ParsePackageSpec(pkg)
+		if err != nil {
+			return err
+		}
+		if spec.Version == "" {
+			return fmt.Errorf("%w: %q, expected name=version", ErrInvalidPackageSpec, pkg)
+		}
+	}
+	return nil
+}
+
+// RejectVersionSpecs returns an error wrapping ErrVersionNotSupported if any of pkgs requests a specific version,
+// for package managers that can only install the version they choose.
+func RejectVersionSpecs(pkgs []string) error {
+	for _, pkg := range pkgs {
+		spec, err := ParsePackageSpec(pkg)
+		if err != nil {
+			return err
+		}
+		if spec.Version != "" {
+			return fmt.Errorf("%w: %s", ErrVersionNotSupported, pkg)
+		}
+	}
+	return nil
+}
+
+// QualifyArchitecture rewrites the package specifiers of pkgs for the architecture selected by opts.Architecture,
+// their names being qualified with it by qualify in the native syntax of a package manager, such as "libc6:i386"
+// for apt, and their versions kept. pkgs are returned unchanged if no architecture is selected. Invalid specifiers
+// and version constraints are left untouched.
+func QualifyArchitecture(pkgs []string, opts *Options, qualify func(name, arch string) string) []string {
+	if opts == nil || opts.Architecture == "" {
+		return pkgs
+	}
+	qualified := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		spec, err := ParsePackageSpec(pkg)
+		if err == nil && !strings.ContainsAny(spec.Name, "<>=!~") {
+			spec.Name = qualify(spec.Name, opts.Architecture)
+			pkg = spec.String()
+		}
+		qualified = append(qualified, pkg)
+	}
+	return qualified
+}
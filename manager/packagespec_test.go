@@ -0,0 +1,69 @@
+package manager_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestParsePackageSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    manager.PackageSpec
+		wantErr error
+	}{
+		{spec: "vim", want: manager.PackageSpec{Name: "vim"}},
+		{spec: "vim=2:8.2.3995-1ubuntu2.15", want: manager.PackageSpec{Name: "vim", Version: "2:8.2.3995-1ubuntu2.15"}},
+		{spec: "requests==2.31.0", want: manager.PackageSpec{Name: "requests", Version: "2.31.0"}},
+		{spec: "requests>=2.31", want: manager.PackageSpec{Name: "requests>=2.31"}},
+		{spec: "lodash@4.17.21", want: manager.PackageSpec{Name: "lodash@4.17.21"}},
+		{spec: "vim=", wantErr: manager.ErrInvalidPackageSpec},
+		{spec: "=1.0", wantErr: manager.ErrInvalidPackageSpec},
+	}
+	for _, tt := range tests {
+		got, err := manager.ParsePackageSpec(tt.spec)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("ParsePackageSpec(%q) = %+v, %+v, want %+v, %+v", tt.spec, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTranslatePackageSpecs(t *testing.T) {
+	got, err := manager.TranslatePackageSpecs([]string{"lodash", "typescript=5.3.3", "eslint@8"}, func(spec manager.PackageSpec) string {
+		return spec.Name + "@" + spec.Version
+	})
+	want := []string{"lodash", "typescript@5.3.3", "eslint@8"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("TranslatePackageSpecs() = %+v, %+v, want %+v", got, err, want)
+	}
+
+	if err := manager.RejectVersionSpecs([]string{"vim", "nano=6.2-1"}); !errors.Is(err, manager.ErrVersionNotSupported) {
+		t.Errorf("RejectVersionSpecs() = %+v, want %+v", err, manager.ErrVersionNotSupported)
+	}
+	if err := manager.RequireVersionSpecs([]string{"nano=6.2-1", "vim"}); !errors.Is(err, manager.ErrInvalidPackageSpec) {
+		t.Errorf("RequireVersionSpecs() = %+v, want %+v", err, manager.ErrInvalidPackageSpec)
+	}
+}
+
+func TestQualifyArchitecture(t *testing.T) {
+	pkgs := []string{"libc6", "wine=8.0-4", "libssl3:amd64", "requests>=2.31"}
+	qualify := func(name, arch string) string {
+		if strings.Contains(name, ":") {
+			return name
+		}
+		return name + ":" + arch
+	}
+
+	got := manager.QualifyArchitecture(pkgs, &manager.Options{Architecture: "i386"}, qualify)
+	want := []string{"libc6:i386", "wine:i386=8.0-4", "libssl3:amd64", "requests>=2.31"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QualifyArchitecture() = %+v, want %+v", got, want)
+	}
+
+	if got := manager.QualifyArchitecture(pkgs, &manager.Options{}, qualify); !reflect.DeepEqual(got, pkgs) {
+		t.Errorf("QualifyArchitecture() = %+v, want %+v", got, pkgs)
+	}
+}
@@ -0,0 +1,680 @@
+// Package pacman provides an implementation of the syspkg manager interface for the pacman package manager.
+// It provides a Go (golang) API interface for interacting with the pacman package manager.
+// This package is a wrapper around the pacman command line tool.
+//
+// pacman is the package manager of Arch Linux and its derivatives such as Manjaro and EndeavourOS.
+// It combines a simple binary package format with an easy-to-use build system, and keeps the system up to date by synchronizing package lists with a master server.
+// pacman tracks both explicitly installed packages and packages pulled in as dependencies, which makes it possible to find and remove orphans.
+//
+// For more information about pacman, visit:
+// - https://wiki.archlinux.org/title/Pacman
+// - https://man.archlinux.org/man/pacman.8
+//
+// This package is part of the syspkg library.
+package pacman
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "pacman"
+
+// Constants used for pacman commands
+const (
+	ArgsAssumeYes   string = "--noconfirm"
+	ArgsDryRun      string = "--print"
+	ArgsPrintFormat string = "--print-format=%n %v"
+	ArgsNeeded      string = "--needed"
+	ArgsRecursive   string = "--recursive"
+	ArgsNoSave      string = "--nosave"
+	ArgsQuiet       string = "--quiet"
+	ArgsCacheDir    string = "--cachedir"
+
+	ArgsDownloadPrintFormat string = "--print-format=%n %v %a %f"
+)
+
+// LockMessage is the message of pacman failing because another process holds the lock of its database.
+var LockMessage string = "unable to lock database"
+
+// CacheDir is the cache of pacman where packages are downloaded, unless Options.DownloadDir is set.
+var CacheDir string = "/var/cache/pacman/pkg"
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for pacman.
+var ENV_NonInteractive []string = []string{"LC_ALL=C"}
+
+// PackageManager implements the manager.PackageManager interface for the pacman package manager.
+type PackageManager struct{}
+
+// IsAvailable checks if the pacman package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(pm)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the pacman package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by pacman.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:         true,
+		RegexSearch:    true,
+		Delete:         true,
+		DryRun:         true,
+		RootDir:        true,
+		ListUpgradable: true,
+		Upgrade:        true,
+		Dependencies:   true,
+		FileOwner:      true,
+		FileList:       true,
+		Download:       true,
+		PackageNames:   true,
+		LocalInstall:   true,
+		Reboot:         true,
+		Verify:         true,
+		Groups:         true,
+	}
+}
+
+// Install installs the provided packages using the pacman package manager.
+// Packages that are already up to date are not reinstalled.
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if err := manager.RejectVersionSpecs(pkgs); err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.DownloadOnly {
+		return a.Download(pkgs, opts)
+	}
+
+	return a.install(append([]string{"-S", ArgsNeeded}, pkgs...), opts)
+}
+
+// InstallLocal installs the provided package files using pacman -U, which also installs the dependencies they need.
+func (a *PackageManager) InstallLocal(paths []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	files, err := manager.LocalPackageFiles(paths, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.install(append([]string{"-U"}, files...), opts)
+}
+
+// install runs the pacman install operation args, -S or -U with the packages to install.
+func (a *PackageManager) install(args []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun, ArgsPrintFormat)
+	}
+
+	// assume yes if not interactive, to avoid hanging
+	if !opts.Interactive {
+		args = append(args, ArgsAssumeYes)
+	}
+
+	cmd := manager.Command(opts, pm, args...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		err := manager.Run(cmd)
+		return nil, err
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := output(cmd, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return ParseDryRunOutput(string(out), manager.PackageStatusInstalled, opts), nil
+	}
+	return ParseInstallOutput(string(out), opts), nil
+}
+
+// Download downloads the provided packages and their missing dependencies using pacman -Sw,
+// into the cache of pacman or opts.DownloadDir. The files to download are listed first with pacman -Sp.
+func (a *PackageManager) Download(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if err := manager.RejectVersionSpecs(pkgs); err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	dir := CacheDir
+	var cacheArgs []string
+	if opts.DownloadDir != "" {
+		var err error
+		if dir, err = filepath.Abs(opts.DownloadDir); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		cacheArgs = []string{ArgsCacheDir, dir}
+	}
+
+	args := append(append([]string{"-Sp", ArgsDownloadPrintFormat}, cacheArgs...), pkgs...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, CheckExitError(err)
+	}
+	packages := ParseDownloadOutput(string(out), dir, opts)
+	if opts.DryRun {
+		return packages, nil
+	}
+
+	args = append(append([]string{"-Sw"}, cacheArgs...), pkgs...)
+	if !opts.Interactive {
+		args = append(args, ArgsAssumeYes)
+	}
+	cmd = manager.Command(opts, pm, args...)
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return packages, manager.Run(cmd)
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	if _, err := output(cmd, opts); err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+// Delete removes the provided packages, and the dependencies they no longer need, using the pacman package manager.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"-R", ArgsRecursive}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun, ArgsPrintFormat)
+	}
+	if !opts.Interactive {
+		args = append(args, ArgsAssumeYes)
+	}
+
+	cmd := manager.Command(opts, pm, args...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		err := manager.Run(cmd)
+		return nil, err
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := output(cmd, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return ParseDryRunOutput(string(out), manager.PackageStatusAvailable, opts), nil
+	}
+	return ParseDeletedOutput(string(out), opts), nil
+}
+
+// Refresh synchronizes the package databases using the pacman package manager.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	cmd := manager.Command(opts, pm, "-Sy")
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		err := manager.Run(cmd)
+		return err
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := output(cmd, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Verbose {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
+
+// Find searches the sync databases for packages matching the provided keywords using the pacman package manager.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"-Ss"}, keywords...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// pacman exits with 1 when nothing matches
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && len(out) == 0 && len(exitErr.Stderr) == 0 {
+			return nil, nil
+		}
+		return nil, CheckExitError(err)
+	}
+
+	return ParseFindOutput(string(out), opts), nil
+}
+
+// ListInstalled lists all installed packages using the pacman package manager.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "-Q")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	var packages []manager.PackageInfo
+	err := manager.StreamLines(cmd, func(line string) error {
+		if packageInfo, ok := parseInstalledLine(line); ok {
+			packages = append(packages, packageInfo)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// pacman -Q doesn't print the sizes, which pacman -Qi prints with the other information of every package
+	cmd = manager.Command(opts, pm, "-Qi")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	info, err := manager.Output(cmd)
+	if err != nil {
+		opts.Log().Debug("Failed to get the installed sizes", "package_manager", pm, "error", err)
+		return packages, nil
+	}
+	return manager.SetInstalledSizes(packages, ParseInstalledSizesOutput(string(info))), nil
+}
+
+// ListUpgradable lists all upgradable packages using the pacman package manager.
+// The result is based on the local copy of the sync databases, so Refresh should be called first for up-to-date results.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "-Qu")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// pacman exits with 1 when there is nothing to upgrade
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && len(out) == 0 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseListUpgradableOutput(string(out), opts), nil
+}
+
+// Upgrade upgrades the provided packages using the pacman package manager.
+// If no packages are given, a full system upgrade is performed.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := []string{"-Syu"}
+	if len(pkgs) > 0 {
+		args = append([]string{"-S", ArgsNeeded}, pkgs...)
+	}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun, ArgsPrintFormat)
+	}
+	if !opts.Interactive {
+		args = append(args, ArgsAssumeYes)
+	}
+
+	cmd := manager.Command(opts, pm, args...)
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		err := manager.Run(cmd)
+		return nil, err
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := output(cmd, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return ParseDryRunOutput(string(out), manager.PackageStatusInstalled, opts), nil
+	}
+	return ParseInstallOutput(string(out), opts), nil
+}
+
+// UpgradeAll performs a full system upgrade using the pacman package manager.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// Clean removes packages that are no longer installed from the pacman package cache.
+func (a *PackageManager) Clean(opts *manager.Options) error {
+	args := []string{"-Sc"}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+	if !opts.Interactive {
+		args = append(args, ArgsAssumeYes)
+	}
+
+	cmd := manager.Command(opts, pm, args...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		err := manager.Run(cmd)
+		return err
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := output(cmd, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Verbose {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
+
+// GetPackageInfo retrieves package information for the specified package using the pacman package manager.
+// The local database is queried first, falling back to the sync databases for packages that are not installed.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "-Qi", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err == nil {
+		info := ParsePackageInfoOutput(string(out), opts)
+		info.Status = manager.PackageStatusInstalled
+		return info, nil
+	}
+
+	cmd = manager.Command(opts, pm, "-Si", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err = manager.Output(cmd)
+	if err != nil {
+		return manager.PackageInfo{}, CheckExitError(err)
+	}
+	info := ParsePackageInfoOutput(string(out), opts)
+	info.Status = manager.PackageStatusAvailable
+	return info, nil
+}
+
+// ListPackageNames returns the names of the packages available in the sync databases, using pacman -Slq.
+func (a *PackageManager) ListPackageNames(opts *manager.Options) ([]string, error) {
+	cmd := manager.Command(opts, pm, "-Sl", ArgsQuiet)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// ListExplicit returns the names of the packages installed explicitly, rather than as dependencies, using pacman -Qqe.
+func (a *PackageManager) ListExplicit(opts *manager.Options) ([]string, error) {
+	cmd := manager.Command(opts, pm, "-Qe", ArgsQuiet)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// GetDependencies returns the packages the specified package directly depends on, from the "Depends On" field of pacman -Qi,
+// falling back to pacman -Si for packages that are not installed.
+func (a *PackageManager) GetDependencies(pkg string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "-Qi", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		cmd = manager.Command(opts, pm, "-Si", pkg)
+		manager.SetEnv(cmd, opts, ENV_NonInteractive)
+		out, err = manager.Output(cmd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ParseDependsOutput(string(out), opts), nil
+}
+
+// GetReverseDependencies returns the installed packages that directly depend on the specified package,
+// from the "Required By" field of pacman -Qi. The package must be installed.
+func (a *PackageManager) GetReverseDependencies(pkg string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "-Qi", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRequiredByOutput(string(out), opts), nil
+}
+
+// Owns returns the installed package owning the specified path, using pacman -Qo.
+func (a *PackageManager) Owns(path string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "-Qo", path)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// pacman exits with 1 when no package owns the path
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseOwnsOutput(string(out), opts), nil
+}
+
+// ListFiles returns the files and directories installed by the specified package, using pacman -Ql.
+func (a *PackageManager) ListFiles(pkg string, opts *manager.Options) ([]string, error) {
+	cmd := manager.Command(opts, pm, "-Ql", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListFilesOutput(string(out), opts), nil
+}
+
+// ListGroups lists the groups of the sync databases, such as "base-devel" or "gnome", with their packages, using
+// pacman -Sgg. A group is installed when all its packages are, as listed by pacman -Qg.
+func (a *PackageManager) ListGroups(opts *manager.Options) ([]manager.GroupInfo, error) {
+	cmd := manager.Command(opts, pm, "-Sgg")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	available, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd = manager.Command(opts, pm, "-Qg")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	installed, err := manager.Output(cmd)
+	if err != nil {
+		// pacman exits with 1 when no installed package belongs to a group
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 || len(installed) != 0 {
+			return nil, err
+		}
+	}
+
+	return ParseGroupsOutput(string(available), string(installed), opts), nil
+}
+
+// InstallGroup installs the packages of the provided groups using pacman -S, one group at a time, so that the packages
+// are reported with the group they were installed with. Packages that are already up to date are not reinstalled.
+func (a *PackageManager) InstallGroup(groups []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	for _, group := range groups {
+		installed, err := a.install([]string{"-S", ArgsNeeded, group}, opts)
+		packages = append(packages, manager.SetGroup(installed, group)...)
+		if err != nil {
+			return packages, err
+		}
+	}
+	return packages, nil
+}
+
+// RemoveGroup removes the installed packages of the provided groups, and the dependencies they no longer need,
+// using pacman -R, one group at a time, like Delete.
+func (a *PackageManager) RemoveGroup(groups []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	for _, group := range groups {
+		removed, err := a.Delete([]string{group}, opts)
+		packages = append(packages, manager.SetGroup(removed, group)...)
+		if err != nil {
+			return packages, err
+		}
+	}
+	return packages, nil
+}
+
+// NeedsReboot returns whether the system needs a reboot, because a kernel newer than the running one is installed.
+// pacman removes the modules of the running kernel when upgrading it, so they can't be loaded until the next boot.
+func (a *PackageManager) NeedsReboot(opts *manager.Options) (manager.RebootStatus, error) {
+	var status manager.RebootStatus
+	err := manager.CheckKernel(&status, a.Owns, opts)
+	return status, err
+}
+
+// AutoRemove removes orphaned packages, i.e. packages installed as dependencies that are no longer required by any package.
+func (a *PackageManager) AutoRemove(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "-Qdtq")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// pacman exits with 1 when there are no orphans
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && len(out) == 0 {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	orphans := strings.Fields(string(out))
+	if len(orphans) == 0 {
+		return nil, nil
+	}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	args := append([]string{"-R", ArgsRecursive, ArgsNoSave}, orphans...)
+	if opts.DryRun {
+		args = append(args, ArgsDryRun, ArgsPrintFormat)
+	}
+	if !opts.Interactive {
+		args = append(args, ArgsAssumeYes)
+	}
+
+	cmd = manager.Command(opts, pm, args...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		err := manager.Run(cmd)
+		return nil, err
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err = output(cmd, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return ParseDryRunOutput(string(out), manager.PackageStatusAvailable, opts), nil
+	}
+	return ParseDeletedOutput(string(out), opts), nil
+}
+
+// Verify checks the files of the provided packages, or of all installed packages if none are given, for missing files.
+// It returns the results of the packages that failed the check. Signatures are checked when packages are installed,
+// and are not recorded.
+func (a *PackageManager) Verify(pkgs []string, opts *manager.Options) ([]manager.VerificationResult, error) {
+	args := append([]string{"-Qk"}, pkgs...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	// pacman exits with 1 when missing files were found, which is not an error for us
+	out, err := manager.CombinedOutput(cmd)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return nil, err
+		}
+	}
+
+	if opts == nil {
+		opts = &manager.Options{}
+	}
+	return ParseVerifyOutput(string(out), opts), nil
+}
+
+// output runs a non-interactive pacman command and returns its standard output, with its error mapped by
+// CheckExitError. The command is retried while another process holds the lock of the pacman database, for up to
+// opts.LockWait.
+func output(cmd *exec.Cmd, opts *manager.Options) ([]byte, error) {
+	out, err := manager.RetryLocked(cmd, opts, isLocked, func(cmd *exec.Cmd) ([]byte, error) {
+		return manager.Output(cmd)
+	})
+	return out, CheckExitError(err)
+}
+
+// isLocked reports whether a pacman command failed because another process holds the lock of the pacman database.
+func isLocked(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && bytes.Contains(exitErr.Stderr, []byte(LockMessage))
+}
@@ -0,0 +1,730 @@
+// Package pacman provides a package manager implementation for Arch Linux based systems using
+// pacman as the underlying package management tool.
+package pacman
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Errors returned for the failures of pacman, which exits with 1 for all of them, told apart by their messages.
+var (
+	ErrLocked         = fmt.Errorf("pacman: %w", manager.ErrLocked)
+	ErrPrivileges     = errors.New("pacman: insufficient privileges, please run as root")
+	ErrTargetNotFound = errors.New("pacman: target not found")
+	ErrConflict       = errors.New("pacman: conflicting packages or files")
+)
+
+// Messages of pacman on its standard error, identifying its failures.
+var (
+	privilegesMessage     = "you cannot perform this operation unless you are root"
+	targetNotFoundMessage = regexp.MustCompile(`(?m)^error: (?:target not found: (.+)|package '(.+)' was not found)$`)
+	conflictMessages      = []string{"conflicting dependencies", "conflicting files", "are in conflict", "exists in filesystem"}
+)
+
+// CheckExitError maps the error of a failed pacman command to a descriptive error, from the messages pacman printed
+// on its standard error: the lock of the database held by another process, insufficient privileges, targets not found
+// in the databases (wrapping ErrTargetNotFound with their names), and conflicting packages or files.
+// Errors that aren't exit errors, or were already mapped, such as by manager.RetryLocked, are returned as is.
+func CheckExitError(err error) error {
+	var exitErr *exec.ExitError
+	if err == nil || errors.Is(err, manager.ErrLocked) || !errors.As(err, &exitErr) {
+		return err
+	}
+
+	stderr := string(exitErr.Stderr)
+	switch {
+	case strings.Contains(stderr, LockMessage):
+		return ErrLocked
+	case strings.Contains(stderr, privilegesMessage):
+		return ErrPrivileges
+	}
+	if matches := targetNotFoundMessage.FindAllStringSubmatch(stderr, -1); len(matches) > 0 {
+		targets := make([]string, 0, len(matches))
+		for _, match := range matches {
+			targets = append(targets, match[1]+match[2])
+		}
+		return fmt.Errorf("%w: %s", ErrTargetNotFound, strings.Join(targets, ", "))
+	}
+	for _, message := range conflictMessages {
+		if strings.Contains(stderr, message) {
+			return fmt.Errorf("%w: %s", ErrConflict, strings.TrimSpace(stderr))
+		}
+	}
+	if stderr = strings.TrimSpace(stderr); stderr != "" {
+		return fmt.Errorf("%s: command failed: %w: %s", pm, err, stderr)
+	}
+	return err
+}
+
+// ParseInstallOutput parses the output of `pacman -S packageName` command and returns a list of installed packages.
+// It extracts the package names and versions from the "Packages (N)" transaction summary.
+// Example msg:
+//
+//	resolving dependencies...
+//	looking for conflicting packages...
+//
+//	Packages (2) vim-runtime-9.0.1677-1  vim-9.0.1677-1
+//
+//	Total Download Size:    8.45 MiB
+//	Total Installed Size:  38.59 MiB
+//
+//	:: Proceed with installation? [Y/n]
+//	(2/2) installing vim                                [######################] 100%
+func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, spec := range parseTransactionPackages(msg, opts) {
+		name, version := splitPackageVersion(spec)
+
+		// if name is empty, it might be not what we want
+		if name == "" {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           name,
+			Version:        version,
+			NewVersion:     version,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseDeletedOutput parses the output of `pacman -R packageName` command
+// and returns a list of removed packages.
+// Example msg:
+//
+//	checking dependencies...
+//
+//	Packages (2) vim-runtime-9.0.1677-1  vim-9.0.1677-1
+//
+//	Total Removed Size:  38.59 MiB
+//
+//	:: Do you want to remove these packages? [Y/n]
+//	(1/2) removing vim                                  [######################] 100%
+func ParseDeletedOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, spec := range parseTransactionPackages(msg, opts) {
+		name, version := splitPackageVersion(spec)
+
+		// if name is empty, it might be not what we want
+		if name == "" {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           name,
+			Version:        version,
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: pm,
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseDryRunOutput parses the output of a pacman transaction run with `--print --print-format "%n %v"`,
+// and returns the packages that would be affected, with their status set to the given status.
+// Example msg:
+//
+//	vim-runtime 9.0.1677-1
+//	vim 9.0.1677-1
+func ParseDryRunOutput(msg string, status manager.PackageStatus, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		// skip informational lines such as ":: Synchronizing package databases..."
+		if strings.HasPrefix(line, "::") || strings.HasPrefix(line, "warning:") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           parts[0],
+			NewVersion:     parts[1],
+			Status:         status,
+			PackageManager: pm,
+		}
+		if status == manager.PackageStatusAvailable {
+			packageInfo.Version = parts[1]
+			packageInfo.NewVersion = ""
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseFindOutput parses the output of `pacman -Ss keyword` command
+// and returns a list of packages that match the search query.
+// Installed packages are marked as installed, or as upgradable when the installed version differs from the one in the repository.
+// Example msg:
+//
+//	extra/vim 9.0.1677-1 [installed]
+//	    Vi Improved, a highly configurable, improved version of the vi text editor
+//	extra/gvim 9.0.1677-1
+//	    Vi Improved, a highly configurable, improved version of the vi text editor (with advanced features, such as a GUI)
+//	core/vi 1:070224-6 [installed: 1:070224-5]
+//	    The original ex/vi text editor
+func ParseFindOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		// description lines are indented
+		if len(line) == 0 || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) < 2 || !strings.Contains(parts[0], "/") {
+			continue
+		}
+
+		repoName := strings.SplitN(parts[0], "/", 2)
+		packageInfo := manager.PackageInfo{
+			Name:           repoName[1],
+			NewVersion:     parts[1],
+			Category:       repoName[0],
+			Origin:         repoName[0],
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: pm,
+		}
+
+		if match := installedPattern.FindStringSubmatch(line); match != nil {
+			packageInfo.Status = manager.PackageStatusInstalled
+			packageInfo.Version = parts[1]
+			if match[1] != "" && match[1] != parts[1] {
+				packageInfo.Status = manager.PackageStatusUpgradable
+				packageInfo.Version = match[1]
+			}
+		}
+
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// installedPattern matches the "[installed]" and "[installed: oldVersion]" markers of `pacman -Ss` output.
+var installedPattern = regexp.MustCompile(`\[installed(?:: ([^\]]+))?\]`)
+
+// ParseListInstalledOutput parses the output of `pacman -Q` command
+// and returns a list of installed packages.
+// Example msg:
+//
+//	acl 2.3.1-3
+//	archlinux-keyring 20230704-1
+//	vim 9.0.1677-1
+func ParseListInstalledOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if packageInfo, ok := parseInstalledLine(line); ok {
+			packages = append(packages, packageInfo)
+		}
+	}
+
+	return packages
+}
+
+// parseInstalledLine parses a line of the output of `pacman -Q`, as parsed by ParseListInstalledOutput, and reports
+// whether it is a package.
+func parseInstalledLine(line string) (manager.PackageInfo, bool) {
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return manager.PackageInfo{}, false
+	}
+	return manager.PackageInfo{
+		Name:           parts[0],
+		Version:        parts[1],
+		Status:         manager.PackageStatusInstalled,
+		PackageManager: pm,
+	}, true
+}
+
+// ParseListUpgradableOutput parses the output of `pacman -Qu` command
+// and returns a list of upgradable packages.
+// Packages listed in IgnorePkg are reported by pacman with an "[ignored]" suffix and are skipped.
+// Example msg:
+//
+//	linux 6.4.3.arch1-1 -> 6.4.4.arch1-1
+//	vim 9.0.1677-1 -> 9.0.1700-1
+//	firefox 115.0.2-1 -> 115.0.3-1 [ignored]
+func ParseListUpgradableOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) < 4 || parts[2] != "->" {
+			continue
+		}
+
+		if strings.HasSuffix(line, "[ignored]") {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           parts[0],
+			Version:        parts[1],
+			NewVersion:     parts[3],
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: pm,
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParsePackageInfoOutput parses the output of `pacman -Qi packageName` or `pacman -Si packageName` command
+// and returns a manager.PackageInfo object containing package information such as name, version,
+// architecture, repository, license, homepage, packager, as maintainer, and installed and download sizes,
+// the latter only reported by `pacman -Si`.
+// Example msg:
+//
+//	Repository      : extra
+//	Name            : vim
+//	Version         : 9.0.1677-1
+//	Description     : Vi Improved, a highly configurable, improved version of the vi text editor
+//	Architecture    : x86_64
+//	URL             : https://www.vim.org
+//	Licenses        : custom:vim
+//	Download Size   : 1.94 MiB
+//	Installed Size  : 4.06 MiB
+//	Packager        : Levente Polyak <anthraxx@archlinux.org>
+func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageInfo {
+	var pkg manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		parts := strings.SplitN(line, " : ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		switch key {
+		case "Name":
+			pkg.Name = value
+		case "Version":
+			pkg.Version = value
+		case "Architecture":
+			pkg.Arch = value
+		case "Repository":
+			pkg.Category, pkg.Origin = value, value
+		case "URL":
+			pkg.Homepage = value
+		case "Licenses":
+			// several licenses are separated by two spaces
+			pkg.License = strings.Join(strings.Fields(value), " ")
+		case "Packager":
+			pkg.Maintainer = value
+		case "Installed Size":
+			pkg.SizeInstalled, _ = manager.ParseSize(value)
+		case "Download Size":
+			pkg.SizeDownload, _ = manager.ParseSize(value)
+		}
+	}
+
+	pkg.PackageManager = pm
+
+	return pkg
+}
+
+// ParseInstalledSizesOutput parses the output of `pacman -Qi` command, without package names, which prints the
+// information of all the installed packages, and returns their installed sizes in bytes, by package name.
+// Example msg:
+//
+//	Name            : acl
+//	Version         : 2.3.1-3
+//	Installed Size  : 333.81 KiB
+//
+//	Name            : vim
+//	Version         : 9.0.1677-1
+//	Installed Size  : 4.06 MiB
+func ParseInstalledSizesOutput(msg string) map[string]int64 {
+	sizes := make(map[string]int64)
+	var name string
+
+	for _, line := range strings.Split(msg, "\n") {
+		parts := strings.SplitN(line, " : ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		switch strings.TrimSpace(parts[0]) {
+		case "Name":
+			name = strings.TrimSpace(parts[1])
+		case "Installed Size":
+			if size, err := manager.ParseSize(parts[1]); err == nil && name != "" {
+				sizes[name] = size
+			}
+		}
+	}
+
+	return sizes
+}
+
+// ParseVerifyOutput parses the output of `pacman -Qk` command
+// and returns the results of the packages that have missing files.
+// Example msg:
+//
+//	warning: vim: /usr/bin/vim (No such file or directory)
+//	vim: 1733 total files, 1 missing file
+//	zlib: 19 total files, 0 missing files
+func ParseVerifyOutput(msg string, opts *manager.Options) []manager.VerificationResult {
+	var results []manager.VerificationResult
+	missing := make(map[string][]string)
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if match := verifyMissingPattern.FindStringSubmatch(line); match != nil {
+			missing[match[1]] = append(missing[match[1]], match[2])
+			continue
+		}
+
+		match := verifySummaryPattern.FindStringSubmatch(line)
+		if match == nil || match[2] == "0" {
+			continue
+		}
+
+		results = append(results, manager.VerificationResult{
+			Name:           match[1],
+			PackageManager: pm,
+			MissingFiles:   missing[match[1]],
+		})
+	}
+
+	return results
+}
+
+// ParseDependsOutput parses the "Depends On" field of the output of `pacman -Qi packageName` or `pacman -Si packageName` commands
+// and returns the direct dependencies of the package. Version constraints are kept in AdditionalData["constraint"].
+// Example msg:
+//
+//	Name            : vim
+//	Version         : 9.0.1677-1
+//	Depends On      : vim-runtime=9.0.1677-1  gpm  acl  glibc  libgcrypt  pcre
+//	                  zlib
+//	Required By     : None
+func ParseDependsOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, dep := range parseInfoList(msg, "Depends On") {
+		packageInfo := manager.PackageInfo{
+			Name:           dep,
+			Status:         manager.PackageStatusUnknown,
+			PackageManager: pm,
+		}
+		if idx := strings.IndexAny(dep, "<>="); idx > 0 {
+			packageInfo.Name = dep[:idx]
+			packageInfo.AdditionalData = map[string]string{"constraint": dep[idx:]}
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseRequiredByOutput parses the "Required By" field of the output of `pacman -Qi packageName` command
+// and returns the installed packages that depend on the package.
+// Example msg:
+//
+//	Name            : gpm
+//	Version         : 1.20.7.r38.ge82d1a6-5
+//	Depends On      : bash  procps-ng
+//	Required By     : vim
+func ParseRequiredByOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, name := range parseInfoList(msg, "Required By") {
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseOwnsOutput parses the output of `pacman -Qo path` command and returns the packages owning the path.
+// Example msg:
+//
+//	/usr/bin/vim is owned by vim 9.0.1677-1
+func ParseOwnsOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		_, owner, found := strings.Cut(line, " is owned by ")
+		if !found {
+			continue
+		}
+
+		fields := strings.Fields(owner)
+		if len(fields) != 2 {
+			continue
+		}
+		packages = append(packages, manager.PackageInfo{
+			Name:           fields[0],
+			Version:        fields[1],
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseGroupsOutput parses the output of `pacman -Sgg` command, listing the packages of the groups of the sync
+// databases, and of `pacman -Qg` command, listing the installed ones, both as "group package" lines. It returns the
+// groups with their packages, in the order of the sync databases, installed when all their packages are.
+// Example available:
+//
+//	base-devel autoconf
+//	base-devel automake
+//	xorg xorg-server
+//
+// Example installed:
+//
+//	base-devel autoconf
+//	base-devel automake
+func ParseGroupsOutput(available string, installed string, opts *manager.Options) []manager.GroupInfo {
+	var groups []manager.GroupInfo
+	index := make(map[string]int)
+
+	for _, line := range strings.Split(available, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		i, found := index[fields[0]]
+		if !found {
+			i = len(groups)
+			index[fields[0]] = i
+			groups = append(groups, manager.GroupInfo{Name: fields[0], PackageManager: pm})
+		}
+		groups[i].Packages = append(groups[i].Packages, fields[1])
+	}
+
+	counts := make(map[string]int)
+	for _, line := range strings.Split(installed, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			counts[fields[0]]++
+		}
+	}
+	for i := range groups {
+		groups[i].Installed = counts[groups[i].Name] >= len(groups[i].Packages)
+	}
+
+	return groups
+}
+
+// ParseListFilesOutput parses the output of `pacman -Ql packageName` command and returns the installed paths.
+// Directories are listed with a trailing slash, as pacman prints them.
+// Example msg:
+//
+//	vim /usr/
+//	vim /usr/bin/
+//	vim /usr/bin/vim
+func ParseListFilesOutput(msg string, opts *manager.Options) []string {
+	var files []string
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		_, path, found := strings.Cut(line, " ")
+		if !found || !strings.HasPrefix(path, "/") {
+			continue
+		}
+		files = append(files, path)
+	}
+
+	return files
+}
+
+// parseInfoList returns the items of a list field of `pacman -Qi` or `pacman -Si` output,
+// which may wrap over several indented lines. "None" is returned as an empty list.
+func parseInfoList(msg string, key string) []string {
+	var items []string
+	var inField bool
+
+	for _, line := range strings.Split(msg, "\n") {
+		parts := strings.SplitN(line, " : ", 2)
+		switch {
+		case len(parts) == 2 && strings.TrimSpace(parts[0]) != "":
+			inField = strings.TrimSpace(parts[0]) == key
+			if inField {
+				items = append(items, strings.Fields(parts[1])...)
+			}
+		case inField && strings.HasPrefix(line, " "):
+			items = append(items, strings.Fields(line)...)
+		default:
+			inField = false
+		}
+	}
+
+	if len(items) == 1 && items[0] == "None" {
+		return nil
+	}
+	return items
+}
+
+// verifySummaryPattern matches the per-package summary line of `pacman -Qk` output.
+var verifySummaryPattern = regexp.MustCompile(`^(\S+): \d+ total files?, (\d+) missing files?$`)
+
+// verifyMissingPattern matches the warnings about missing files of `pacman -Qk` output.
+var verifyMissingPattern = regexp.MustCompile(`^warning: (\S+): (.+) \(No such file or directory\)$`)
+
+// parseTransactionPackages extracts the package specifications ("name-version-release") listed in the
+// "Packages (N)" section of a pacman transaction summary. The list may wrap over several indented lines.
+func parseTransactionPackages(msg string, opts *manager.Options) []string {
+	var specs []string
+	var inSection bool
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		switch {
+		case strings.HasPrefix(line, "Packages ("):
+			inSection = true
+			if idx := strings.Index(line, ")"); idx >= 0 {
+				specs = append(specs, strings.Fields(line[idx+1:])...)
+			}
+		case inSection && strings.HasPrefix(line, " "):
+			specs = append(specs, strings.Fields(line)...)
+		default:
+			inSection = false
+		}
+	}
+
+	return specs
+}
+
+// splitPackageVersion splits a pacman package specification such as "vim-runtime-9.0.1677-1"
+// into its name ("vim-runtime") and version ("9.0.1677-1").
+func splitPackageVersion(spec string) (string, string) {
+	rel := strings.LastIndex(spec, "-")
+	if rel <= 0 {
+		return spec, ""
+	}
+	ver := strings.LastIndex(spec[:rel], "-")
+	if ver <= 0 {
+		return spec, ""
+	}
+	return spec[:ver], spec[ver+1:]
+}
+
+// ParseDownloadOutput parses the output of `pacman -Sp --print-format "%n %v %a %f" packageName` command and returns
+// the packages to download, with the path of their file in dir (the cache directory of pacman) in AdditionalData["path"].
+// Example msg:
+//
+//	vim-runtime 9.1.0000-1 x86_64 vim-runtime-9.1.0000-1-x86_64.pkg.tar.zst
+//	vim 9.1.0000-1 x86_64 vim-9.1.0000-1-x86_64.pkg.tar.zst
+func ParseDownloadOutput(msg string, dir string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		// skip informational lines such as ":: Synchronizing package databases..."
+		if strings.HasPrefix(line, "::") || strings.HasPrefix(line, "warning:") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) != 4 {
+			continue
+		}
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           parts[0],
+			Version:        parts[1],
+			Arch:           parts[2],
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: pm,
+			AdditionalData: map[string]string{"path": filepath.Join(dir, parts[3])},
+		})
+	}
+
+	return packages
+}
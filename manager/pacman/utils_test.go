@@ -0,0 +1,383 @@
+package pacman_test
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/pacman"
+)
+
+func TestParseInstallOutput(t *testing.T) {
+	var inputParseInstallOutput string = strings.Join([]string{
+		`resolving dependencies...`,
+		`looking for conflicting packages...`,
+		``,
+		`Packages (3) gpm-1.20.7.r38.ge82d1a6-4  vim-runtime-9.0.1677-1`,
+		`             vim-9.0.1677-1`,
+		``,
+		`Total Download Size:    8.45 MiB`,
+		`Total Installed Size:  38.59 MiB`,
+		``,
+		`:: Proceed with installation? [Y/n] `,
+		`(3/3) installing vim                                [######################] 100%`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "gpm",
+			Version:        "1.20.7.r38.ge82d1a6-4",
+			NewVersion:     "1.20.7.r38.ge82d1a6-4",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "pacman",
+		},
+		{
+			Name:           "vim-runtime",
+			Version:        "9.0.1677-1",
+			NewVersion:     "9.0.1677-1",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "pacman",
+		},
+		{
+			Name:           "vim",
+			Version:        "9.0.1677-1",
+			NewVersion:     "9.0.1677-1",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "pacman",
+		},
+	}
+
+	actualPackageInfo := pacman.ParseInstallOutput(inputParseInstallOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseInstallOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseDeletedOutput(t *testing.T) {
+	var inputParseDeletedOutput string = strings.Join([]string{
+		`checking dependencies...`,
+		``,
+		`Packages (1) vim-9.0.1677-1`,
+		``,
+		`Total Removed Size:  4.20 MiB`,
+		``,
+		`:: Do you want to remove these packages? [Y/n] `,
+		`(1/1) removing vim                                  [######################] 100%`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "vim",
+			Version:        "9.0.1677-1",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "pacman",
+		},
+	}
+
+	actualPackageInfo := pacman.ParseDeletedOutput(inputParseDeletedOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDeletedOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseFindOutput(t *testing.T) {
+	var inputParseFindOutput string = strings.Join([]string{
+		`extra/vim 9.0.1677-1 [installed]`,
+		`    Vi Improved, a highly configurable, improved version of the vi text editor`,
+		`extra/gvim 9.0.1677-1`,
+		`    Vi Improved, a highly configurable, improved version of the vi text editor (with advanced features, such as a GUI)`,
+		`core/vi 1:070224-6 [installed: 1:070224-5]`,
+		`    The original ex/vi text editor`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "vim",
+			Version:        "9.0.1677-1",
+			NewVersion:     "9.0.1677-1",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "extra",
+			Origin:         "extra",
+			PackageManager: "pacman",
+		},
+		{
+			Name:           "gvim",
+			NewVersion:     "9.0.1677-1",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "extra",
+			Origin:         "extra",
+			PackageManager: "pacman",
+		},
+		{
+			Name:           "vi",
+			Version:        "1:070224-5",
+			NewVersion:     "1:070224-6",
+			Status:         manager.PackageStatusUpgradable,
+			Category:       "core",
+			Origin:         "core",
+			PackageManager: "pacman",
+		},
+	}
+
+	actualPackageInfo := pacman.ParseFindOutput(inputParseFindOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseFindOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListUpgradableOutput(t *testing.T) {
+	var inputParseListUpgradableOutput string = strings.Join([]string{
+		`linux 6.4.3.arch1-1 -> 6.4.4.arch1-1`,
+		`vim 9.0.1677-1 -> 9.0.1700-1`,
+		`firefox 115.0.2-1 -> 115.0.3-1 [ignored]`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "linux",
+			Version:        "6.4.3.arch1-1",
+			NewVersion:     "6.4.4.arch1-1",
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: "pacman",
+		},
+		{
+			Name:           "vim",
+			Version:        "9.0.1677-1",
+			NewVersion:     "9.0.1700-1",
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: "pacman",
+		},
+	}
+
+	actualPackageInfo := pacman.ParseListUpgradableOutput(inputParseListUpgradableOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListUpgradableOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParsePackageInfoOutput(t *testing.T) {
+	var inputParsePackageInfoOutput string = strings.Join([]string{
+		`Repository      : extra`,
+		`Name            : vim`,
+		`Version         : 9.0.1677-1`,
+		`Description     : Vi Improved, a highly configurable, improved version of the vi text editor`,
+		`Architecture    : x86_64`,
+		`URL             : https://www.vim.org`,
+		`Licenses        : custom:vim  GPL-2.0-only`,
+		`Depends On      : vim-runtime=9.0.1677-1  gpm  acl  glibc  libgcrypt  zlib`,
+		`Download Size   : 1.94 MiB`,
+		`Installed Size  : 4.06 MiB`,
+		`Packager        : Levente Polyak <anthraxx@archlinux.org>`,
+	}, "\n")
+
+	var expectedPackageInfo = manager.PackageInfo{
+		Name:           "vim",
+		Version:        "9.0.1677-1",
+		Category:       "extra",
+		Origin:         "extra",
+		Arch:           "x86_64",
+		License:        "custom:vim GPL-2.0-only",
+		Homepage:       "https://www.vim.org",
+		Maintainer:     "Levente Polyak <anthraxx@archlinux.org>",
+		SizeInstalled:  4257218,
+		SizeDownload:   2034237,
+		PackageManager: "pacman",
+	}
+
+	actualPackageInfo := pacman.ParsePackageInfoOutput(inputParsePackageInfoOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParsePackageInfoOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseInstalledSizesOutput(t *testing.T) {
+	var inputParseInstalledSizesOutput string = strings.Join([]string{
+		`Name            : acl`,
+		`Version         : 2.3.1-3`,
+		`Installed Size  : 333.81 KiB`,
+		``,
+		`Name            : vim`,
+		`Version         : 9.0.1677-1`,
+		`Installed Size  : 4.06 MiB`,
+	}, "\n")
+
+	expectedSizes := map[string]int64{"acl": 341821, "vim": 4257218}
+
+	actualSizes := pacman.ParseInstalledSizesOutput(inputParseInstalledSizesOutput)
+	if !reflect.DeepEqual(expectedSizes, actualSizes) {
+		t.Errorf("ParseInstalledSizesOutput() = %+v, want %+v", actualSizes, expectedSizes)
+	}
+}
+
+func TestParseVerifyOutput(t *testing.T) {
+	var inputParseVerifyOutput string = strings.Join([]string{
+		`warning: vim: /usr/bin/vim (No such file or directory)`,
+		`vim: 1733 total files, 1 missing file`,
+		`zlib: 19 total files, 0 missing files`,
+	}, "\n")
+
+	var expectedResults = []manager.VerificationResult{
+		{
+			Name:           "vim",
+			PackageManager: "pacman",
+			MissingFiles:   []string{"/usr/bin/vim"},
+		},
+	}
+
+	actualResults := pacman.ParseVerifyOutput(inputParseVerifyOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedResults, actualResults) {
+		t.Errorf("ParseVerifyOutput() = %+v, want %+v", actualResults, expectedResults)
+	}
+}
+
+func TestParseDependsOutput(t *testing.T) {
+	var inputParseDependsOutput string = strings.Join([]string{
+		`Name            : vim`,
+		`Version         : 9.0.1677-1`,
+		`Depends On      : vim-runtime=9.0.1677-1  gpm  acl`,
+		`                  zlib`,
+		`Optional Deps   : python: Python language support`,
+		`Required By     : None`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "vim-runtime", Status: manager.PackageStatusUnknown, PackageManager: "pacman", AdditionalData: map[string]string{"constraint": "=9.0.1677-1"}},
+		{Name: "gpm", Status: manager.PackageStatusUnknown, PackageManager: "pacman"},
+		{Name: "acl", Status: manager.PackageStatusUnknown, PackageManager: "pacman"},
+		{Name: "zlib", Status: manager.PackageStatusUnknown, PackageManager: "pacman"},
+	}
+
+	actualPackageInfo := pacman.ParseDependsOutput(inputParseDependsOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDependsOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+
+	if actual := pacman.ParseRequiredByOutput(inputParseDependsOutput, &manager.Options{}); actual != nil {
+		t.Errorf("ParseRequiredByOutput() = %+v, want %+v", actual, nil)
+	}
+}
+
+func TestParseOwnsOutput(t *testing.T) {
+	var inputParseOwnsOutput string = "/usr/bin/vim is owned by vim 9.0.1677-1\n"
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "vim", Version: "9.0.1677-1", Status: manager.PackageStatusInstalled, PackageManager: "pacman"},
+	}
+
+	actualPackageInfo := pacman.ParseOwnsOutput(inputParseOwnsOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseOwnsOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListFilesOutput(t *testing.T) {
+	var inputParseListFilesOutput string = strings.Join([]string{
+		`vim /usr/`,
+		`vim /usr/bin/`,
+		`vim /usr/bin/vim`,
+	}, "\n")
+
+	var expectedFiles = []string{"/usr/", "/usr/bin/", "/usr/bin/vim"}
+
+	actualFiles := pacman.ParseListFilesOutput(inputParseListFilesOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedFiles, actualFiles) {
+		t.Errorf("ParseListFilesOutput() = %+v, want %+v", actualFiles, expectedFiles)
+	}
+}
+
+func TestParseDownloadOutput(t *testing.T) {
+	var inputParseDownloadOutput string = strings.Join([]string{
+		`vim-runtime 9.1.0000-1 x86_64 vim-runtime-9.1.0000-1-x86_64.pkg.tar.zst`,
+		`vim 9.1.0000-1 x86_64 vim-9.1.0000-1-x86_64.pkg.tar.zst`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "vim-runtime", Version: "9.1.0000-1", Arch: "x86_64", Status: manager.PackageStatusAvailable, PackageManager: "pacman", AdditionalData: map[string]string{"path": "/var/cache/pacman/pkg/vim-runtime-9.1.0000-1-x86_64.pkg.tar.zst"}},
+		{Name: "vim", Version: "9.1.0000-1", Arch: "x86_64", Status: manager.PackageStatusAvailable, PackageManager: "pacman", AdditionalData: map[string]string{"path": "/var/cache/pacman/pkg/vim-9.1.0000-1-x86_64.pkg.tar.zst"}},
+	}
+
+	actualPackageInfo := pacman.ParseDownloadOutput(inputParseDownloadOutput, "/var/cache/pacman/pkg", &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDownloadOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseGroupsOutput(t *testing.T) {
+	var inputAvailable string = strings.Join([]string{
+		`base-devel autoconf`,
+		`base-devel automake`,
+		`xorg xorg-server`,
+		`xorg xorg-xinit`,
+	}, "\n")
+	var inputInstalled string = strings.Join([]string{
+		`base-devel autoconf`,
+		`base-devel automake`,
+		`xorg xorg-server`,
+	}, "\n")
+
+	expectedGroups := []manager.GroupInfo{
+		{Name: "base-devel", Installed: true, Packages: []string{"autoconf", "automake"}, PackageManager: "pacman"},
+		{Name: "xorg", Packages: []string{"xorg-server", "xorg-xinit"}, PackageManager: "pacman"},
+	}
+
+	actualGroups := pacman.ParseGroupsOutput(inputAvailable, inputInstalled, &manager.Options{})
+	if !reflect.DeepEqual(expectedGroups, actualGroups) {
+		t.Errorf("ParseGroupsOutput() = %+v, want %+v", actualGroups, expectedGroups)
+	}
+}
+
+func TestCheckExitError(t *testing.T) {
+	tests := []struct {
+		name     string
+		stderr   string
+		expected error
+		message  string
+	}{
+		{name: "lock held", stderr: "error: failed to init transaction (unable to lock database)", expected: pacman.ErrLocked},
+		{name: "not root", stderr: "error: you cannot perform this operation unless you are root.", expected: pacman.ErrPrivileges},
+		{
+			name:     "targets not found",
+			stderr:   "error: target not found: foo\nerror: target not found: bar",
+			expected: pacman.ErrTargetNotFound,
+			message:  "pacman: target not found: foo, bar",
+		},
+		{name: "package not found", stderr: "error: package 'foo' was not found", expected: pacman.ErrTargetNotFound, message: "pacman: target not found: foo"},
+		{
+			name:     "conflicting packages",
+			stderr:   ":: iptables-nft and iptables are in conflict\nerror: unresolvable package conflicts detected\nerror: failed to prepare transaction (conflicting dependencies)",
+			expected: pacman.ErrConflict,
+		},
+		{name: "conflicting files", stderr: "error: failed to commit transaction (conflicting files)\nfoo: /usr/bin/foo exists in filesystem", expected: pacman.ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", `printf '%s\n' "$STDERR" >&2; exit 1`)
+			cmd.Env = []string{"STDERR=" + tt.stderr}
+			_, err := cmd.Output()
+			actual := pacman.CheckExitError(err)
+			if !errors.Is(actual, tt.expected) {
+				t.Errorf("CheckExitError() = %v, want %v", actual, tt.expected)
+			}
+			if tt.message != "" && actual.Error() != tt.message {
+				t.Errorf("CheckExitError() = %q, want %q", actual, tt.message)
+			}
+		})
+	}
+
+	if actual := pacman.CheckExitError(nil); actual != nil {
+		t.Errorf("CheckExitError(nil) = %v, want nil", actual)
+	}
+}
@@ -0,0 +1,169 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+)
+
+// Pattern matches package names with a glob, such as "python3-*", or a regular expression, such as "lib.*ssl".
+// Patterns are matched by syspkg rather than by the package managers, whose pattern syntaxes differ, except for the
+// regular expressions that can be passed to the package managers searching regular expressions, as told by Native.
+type Pattern struct {
+	// Expr is the pattern, as given.
+	Expr string
+
+	// Regex is set if Expr is a regular expression, rather than a glob.
+	Regex bool
+
+	re *regexp.Regexp
+}
+
+// IsGlob reports whether s contains the metacharacters of globs, *, ? or [, and is matched as a glob rather than
+// as a name.
+func IsGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// ParsePattern parses a glob, or a regular expression if regex is set. Globs match whole names, with the syntax of
+// path.Match: * matches any characters, ? one character, and [...] a class of characters. Regular expressions match
+// any part of names unless anchored with ^ and $, with the syntax of the regexp package. Patterns starting with "-"
+// are rejected, as package managers would read them as options, and so are patterns with control characters.
+func ParsePattern(expr string, regex bool) (*Pattern, error) {
+	if expr == "" || strings.HasPrefix(expr, "-") {
+		return nil, fmt.Errorf("invalid pattern %q: patterns can't be empty or start with -", expr)
+	}
+	if i := strings.IndexFunc(expr, func(r rune) bool { return r < ' ' || r == 0x7f }); i >= 0 {
+		return nil, fmt.Errorf("invalid pattern %q: control character at %d", expr, i)
+	}
+
+	p := &Pattern{Expr: expr, Regex: regex}
+	re := expr
+	if !regex {
+		if _, err := path.Match(expr, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", expr, err)
+		}
+		re = globToRegexp(expr)
+	}
+
+	var err error
+	if p.re, err = regexp.Compile(re); err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+	}
+	return p, nil
+}
+
+// Match reports whether name matches the pattern.
+func (p *Pattern) Match(name string) bool {
+	return p.re.MatchString(name)
+}
+
+// Keyword returns the keyword to search the package managers for the packages matching the pattern: the longest
+// literal part that all the matching names contain, such as "libssl" for "libssl.*-dev", or "python3-" for "python3-*".
+// Leading dashes are trimmed, as package managers would read the keyword as an option. It is empty for patterns
+// without such a part, such as ".*".
+func (p *Pattern) Keyword() string {
+	re, err := syntax.Parse(p.re.String(), syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	var keyword string
+	for _, literal := range requiredLiterals(re.Simplify()) {
+		if literal = strings.TrimLeft(literal, "-"); len(literal) > len(keyword) {
+			keyword = literal
+		}
+	}
+	return keyword
+}
+
+// MatchingNames returns the names of the packages matching one of the patterns, sorted and without duplicates, such
+// as the installed packages to delete for "php7.*".
+func MatchingNames(packages []PackageInfo, patterns []*Pattern) []string {
+	var names []string
+	for _, pkg := range packages {
+		if slices.ContainsFunc(patterns, func(p *Pattern) bool { return p.Match(pkg.Name) }) {
+			names = append(names, pkg.Name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// Native reports whether the pattern can be passed unchanged to the package managers searching POSIX extended
+// regular expressions (Capabilities.RegexSearch): regular expressions using only the syntax they share with the
+// regexp package, without escapes, flags, lazy quantifiers or named classes.
+func (p *Pattern) Native() bool {
+	if !p.Regex || strings.ContainsAny(p.Expr, `\`) || strings.Contains(p.Expr, "(?") || strings.Contains(p.Expr, "[:") {
+		return false
+	}
+	for _, lazy := range []string{"*?", "+?", "??", "}?"} {
+		if strings.Contains(p.Expr, lazy) {
+			return false
+		}
+	}
+	return true
+}
+
+// globToRegexp translates a glob, in the syntax of path.Match, to an anchored regular expression.
+func globToRegexp(glob string) string {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			re.WriteString(".*")
+		case '?':
+			re.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				re.WriteString(regexp.QuoteMeta(glob[i:]))
+				i = len(glob)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "^") {
+				class = "^" + strings.TrimPrefix(class, "^")
+			}
+			re.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			re.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	return re.String()
+}
+
+// requiredLiterals returns the literal strings that all the strings matched by re contain.
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return nil
+		}
+		return []string{string(re.Rune)}
+	case syntax.OpConcat, syntax.OpCapture:
+		var literals []string
+		for _, sub := range re.Sub {
+			literals = append(literals, requiredLiterals(sub)...)
+		}
+		return literals
+	case syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredLiterals(re.Sub[0])
+		}
+	}
+	return nil
+}
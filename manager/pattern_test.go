@@ -0,0 +1,98 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		expr  string
+		regex bool
+		name  string
+		match bool
+	}{
+		{"python3-*", false, "python3-requests", true},
+		{"python3-*", false, "libpython3-stdlib", false},
+		{"python3.1?", false, "python3.11", true},
+		{"lib[cm]6", false, "libm6", true},
+		{"vim", false, "vim-tiny", false},
+		{"g++", false, "g++", true},
+		{"lib.*ssl", true, "libssl3", true},
+		{"lib.*ssl", true, "python3-libssl", true},
+		{"lib.*ssl", true, "openssl", false},
+		{"^vim$", true, "vim-tiny", false},
+	}
+	for _, tt := range tests {
+		p, err := manager.ParsePattern(tt.expr, tt.regex)
+		if err != nil {
+			t.Fatalf("ParsePattern(%q, %t) error = %+v", tt.expr, tt.regex, err)
+		}
+		if match := p.Match(tt.name); match != tt.match {
+			t.Errorf("ParsePattern(%q, %t).Match(%q) = %t, want %t", tt.expr, tt.regex, tt.name, match, tt.match)
+		}
+	}
+
+	for _, expr := range []string{"", "-rf", "--config=/tmp/x", "lib[", "vim\n"} {
+		if _, err := manager.ParsePattern(expr, false); err == nil {
+			t.Errorf("ParsePattern(%q) error = nil, want an error", expr)
+		}
+	}
+	if _, err := manager.ParsePattern("lib(ssl", true); err == nil {
+		t.Errorf("ParsePattern(lib(ssl, regex) error = nil, want an error")
+	}
+}
+
+func TestPatternKeyword(t *testing.T) {
+	tests := []struct {
+		expr     string
+		regex    bool
+		expected string
+		native   bool
+	}{
+		{"python3-*", false, "python3-", false},
+		{"lib*-dev", false, "lib", false},
+		{"*-dev", false, "dev", false},
+		{"lib.*ssl", true, "lib", true},
+		{"libssl.*-dev", true, "libssl", true},
+		{"^(python3-)?django$", true, "django", true},
+		{"(gtk|qt)5", true, "5", true},
+		{".*", true, "", true},
+		{`lib\d+`, true, "lib", false},
+		{"(?i)vim", true, "", false},
+	}
+	for _, tt := range tests {
+		p, err := manager.ParsePattern(tt.expr, tt.regex)
+		if err != nil {
+			t.Fatalf("ParsePattern(%q, %t) error = %+v", tt.expr, tt.regex, err)
+		}
+		if actual := p.Keyword(); actual != tt.expected {
+			t.Errorf("ParsePattern(%q, %t).Keyword() = %q, want %q", tt.expr, tt.regex, actual, tt.expected)
+		}
+		if actual := p.Native(); actual != tt.native {
+			t.Errorf("ParsePattern(%q, %t).Native() = %t, want %t", tt.expr, tt.regex, actual, tt.native)
+		}
+	}
+}
+
+func TestMatchingNames(t *testing.T) {
+	packages := []manager.PackageInfo{
+		{Name: "php7.4-fpm"},
+		{Name: "php7.4-cli"},
+		{Name: "php8.2-cli"},
+		{Name: "php7.4-cli", Arch: "i386"},
+		{Name: "libphp7.4-embed"},
+	}
+	pattern, err := manager.ParsePattern("php7.*", false)
+	if err != nil {
+		t.Fatalf("ParsePattern(php7.*) error = %+v", err)
+	}
+
+	expectedNames := []string{"php7.4-cli", "php7.4-fpm"}
+	actualNames := manager.MatchingNames(packages, []*manager.Pattern{pattern})
+	if !reflect.DeepEqual(expectedNames, actualNames) {
+		t.Errorf("MatchingNames() = %+v, want %+v", actualNames, expectedNames)
+	}
+}
@@ -0,0 +1,288 @@
+// Package pip provides an implementation of the syspkg manager interface for the pip package manager.
+// It provides a Go (golang) API interface for interacting with pip, the package installer for Python.
+// This package is a wrapper around the pip3 (or pip) command line tool.
+//
+// pip installs packages from the Python Package Index (PyPI) into the Python environment it belongs to.
+// When a virtual environment is active (the VIRTUAL_ENV environment variable is set), the pip of that environment is used,
+// and only the packages of that environment are managed. Otherwise the pip found first in PATH is used,
+// which usually manages the packages of the system or user Python installation.
+// pip provides JSON output for listing packages (--format=json), which is used by this package to parse results.
+//
+// For more information about pip, visit:
+// - https://pip.pypa.io/en/stable/cli/
+// - https://docs.python.org/3/library/venv.html
+//
+// This package is part of the syspkg library.
+package pip
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "pip"
+
+// commands contains the names of the pip executable, in order of preference.
+var commands = []string{"pip3", "pip"}
+
+// Constants used for pip commands
+const (
+	ArgsFormatJSON string = "--format=json"
+	ArgsOutdated   string = "--outdated"
+	ArgsUpgrade    string = "--upgrade"
+	ArgsDryRun     string = "--dry-run"
+	ArgsYes        string = "--yes"
+)
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for pip.
+var ENV_NonInteractive []string = []string{"LC_ALL=C", "PIP_NO_INPUT=1", "PIP_DISABLE_PIP_VERSION_CHECK=1", "PYTHONIOENCODING=utf-8"}
+
+// EnvMirror is the environment variable setting the mirror of Options.Mirrors: the index of Python packages, a mirror of PyPI such as https://pypi.example.com/simple.
+var EnvMirror string = "PIP_INDEX_URL"
+
+// PackageManager implements the manager.PackageManager interface for the pip package manager.
+type PackageManager struct{}
+
+// IsAvailable checks if the pip package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	return a.command() != ""
+}
+
+// GetPackageManager returns the name of the pip package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by pip.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+		Downgrade:        true,
+	}
+}
+
+// VirtualEnv returns the path of the active Python virtual environment, or an empty string if none is active.
+// When a virtual environment is active, all operations apply to that environment only.
+func (a *PackageManager) VirtualEnv() string {
+	return os.Getenv("VIRTUAL_ENV")
+}
+
+// Install installs the provided packages using pip.
+// Packages can be given with a version specifier, e.g. "requests==2.31.0".
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// pip installs a specific version with name==version
+	pkgs, err := manager.TranslatePackageSpecs(pkgs, func(spec manager.PackageSpec) string { return spec.Name + "==" + spec.Version })
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"install"}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseInstallOutput(string(out), opts), nil
+}
+
+// Downgrade installs the provided packages, given as "name=version", at that version using pip.
+// pip replaces the installed version with the requested one, whether it is older or newer.
+func (a *PackageManager) Downgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if err := manager.RequireVersionSpecs(pkgs); err != nil {
+		return nil, err
+	}
+	return a.Install(pkgs, opts)
+}
+
+// Delete uninstalls the provided packages using pip.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"uninstall", ArgsYes}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	// pip uninstall has no dry-run mode, so only report what is installed
+	if opts.DryRun {
+		installed, err := a.ListInstalled(opts)
+		if err != nil {
+			return nil, err
+		}
+		var packages []manager.PackageInfo
+		for _, pkg := range filterPackages(installed, pkgs) {
+			pkg.Status = manager.PackageStatusAvailable
+			packages = append(packages, pkg)
+		}
+		return packages, nil
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseDeletedOutput(string(out), opts), nil
+}
+
+// Refresh does nothing for pip, as pip always queries the package index directly.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	return nil
+}
+
+// Find is not supported by pip, as PyPI no longer provides a search API.
+// Use GetPackageInfo to look up a package by its exact name instead.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+
+// ListInstalled lists all packages installed in the Python environment of pip.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, a.command(), "list", ArgsFormatJSON)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListOutput(out, manager.PackageStatusInstalled, opts)
+}
+
+// ListUpgradable lists all installed packages that have a newer version available on the package index.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, a.command(), "list", ArgsOutdated, ArgsFormatJSON)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListOutput(out, manager.PackageStatusUpgradable, opts)
+}
+
+// Upgrade upgrades the provided packages using pip.
+// If no packages are given, all outdated packages are upgraded.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	outdated, err := a.ListUpgradable(opts)
+	if err != nil {
+		return nil, err
+	}
+	outdated = filterPackages(outdated, pkgs)
+	if opts.DryRun || len(outdated) == 0 {
+		return outdated, nil
+	}
+
+	args := []string{"install", ArgsUpgrade}
+	for _, pkg := range outdated {
+		args = append(args, pkg.Name)
+	}
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", a.command(), "args", args)
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+
+	oldVersions := make(map[string]string)
+	for _, pkg := range outdated {
+		oldVersions[normalizeName(pkg.Name)] = pkg.Version
+	}
+	upgraded := ParseInstallOutput(string(out), opts)
+	for i := range upgraded {
+		upgraded[i].NewVersion = upgraded[i].Version
+		upgraded[i].Version = oldVersions[normalizeName(upgraded[i].Name)]
+	}
+	return upgraded, nil
+}
+
+// UpgradeAll upgrades all outdated packages using pip.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// GetPackageInfo retrieves information about the specified installed package using pip.
+// If the package is not installed, the latest version available on the package index is looked up.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	cmd := manager.Command(opts, a.command(), "show", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	out, err := manager.Output(cmd)
+	if err == nil {
+		return ParsePackageInfoOutput(string(out), opts), nil
+	}
+	// pip show exits with 1 when the package is not installed
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		return manager.PackageInfo{}, err
+	}
+
+	cmd = manager.Command(opts, a.command(), "index", "versions", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	out, err = manager.Output(cmd)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	return ParseIndexVersionsOutput(string(out), opts), nil
+}
+
+// command returns the pip executable to use: the one of the active virtual environment if any,
+// otherwise the first one found in PATH. It returns an empty string if pip is not available.
+func (a *PackageManager) command() string {
+	if venv := a.VirtualEnv(); venv != "" {
+		for _, name := range commands {
+			path := filepath.Join(venv, "bin", name)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+
+	for _, name := range commands {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// run runs pip with the given arguments, either attached to the terminal in interactive mode,
+// in which case no output is returned, or non-interactively, returning the standard output.
+func (a *PackageManager) run(args []string, opts *manager.Options) ([]byte, error) {
+	cmd := manager.Command(opts, a.command(), args...)
+
+	if opts.Interactive {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return nil, manager.Run(cmd)
+	}
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, manager.MirrorEnv(opts, pm, EnvMirror)...)
+	return manager.Output(cmd)
+}
@@ -0,0 +1,288 @@
+// Package pip provides a package manager implementation for Python packages
+// using pip as the underlying package management tool.
+package pip
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// nameNormalizer matches the runs of characters that are equivalent in Python package names, see PEP 503.
+var nameNormalizer = regexp.MustCompile(`[-_.]+`)
+
+// ParseInstallOutput parses the output of `pip install` command and returns a list of installed packages.
+// In dry-run mode (pip install --dry-run), the packages that would be installed are returned.
+// Packages that are already installed are not reported by pip, and thus not returned.
+// Example msg:
+//
+//	Collecting requests
+//	  Using cached requests-2.31.0-py3-none-any.whl (62 kB)
+//	Collecting urllib3<3,>=1.21.1 (from requests)
+//	  Using cached urllib3-2.0.4-py3-none-any.whl (123 kB)
+//	Installing collected packages: urllib3, requests
+//	Successfully installed requests-2.31.0 urllib3-2.0.4
+func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+
+		var list string
+		switch {
+		case strings.HasPrefix(line, "Successfully installed "):
+			list = strings.TrimPrefix(line, "Successfully installed ")
+		case strings.HasPrefix(line, "Would install "):
+			list = strings.TrimPrefix(line, "Would install ")
+		default:
+			continue
+		}
+
+		for _, field := range strings.Fields(list) {
+			name, version := splitNameVersion(field)
+			packages = append(packages, manager.PackageInfo{
+				Name:           name,
+				Version:        version,
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+			})
+		}
+	}
+
+	return packages
+}
+
+// ParseDeletedOutput parses the output of `pip uninstall --yes` command and returns a list of removed packages.
+// Example msg:
+//
+//	Found existing installation: requests 2.31.0
+//	Uninstalling requests-2.31.0:
+//	  Successfully uninstalled requests-2.31.0
+func ParseDeletedOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "Successfully uninstalled ") {
+			if opts != nil && opts.Verbose && line != "" {
+				opts.Log().Debug("Output", "package_manager", pm, "line", line)
+			}
+			continue
+		}
+
+		name, version := splitNameVersion(strings.TrimPrefix(line, "Successfully uninstalled "))
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Version:        version,
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseListOutput parses the output of `pip list --format=json` and `pip list --outdated --format=json` commands,
+// and returns a list of packages with the given status.
+// Example msg (of pip list --outdated --format=json):
+//
+//	[
+//	  {"name": "requests", "version": "2.28.2", "latest_version": "2.31.0", "latest_filetype": "wheel"},
+//	  {"name": "setuptools", "version": "65.5.0", "latest_version": "68.0.0", "latest_filetype": "wheel"}
+//	]
+func ParseListOutput(msg []byte, status manager.PackageStatus, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	var results []struct {
+		Name                    string `json:"name"`
+		Version                 string `json:"version"`
+		LatestVersion           string `json:"latest_version"`
+		EditableProjectLocation string `json:"editable_project_location"`
+	}
+
+	if len(bytes.TrimSpace(msg)) == 0 {
+		return packages, nil
+	}
+	if err := json.Unmarshal(msg, &results); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse JSON output: %w", pm, err)
+	}
+
+	for _, r := range results {
+		packageInfo := manager.PackageInfo{
+			Name:           r.Name,
+			Version:        r.Version,
+			NewVersion:     r.LatestVersion,
+			Status:         status,
+			PackageManager: pm,
+		}
+		if r.EditableProjectLocation != "" {
+			packageInfo.AdditionalData = map[string]string{"editable_project_location": r.EditableProjectLocation}
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// ParsePackageInfoOutput parses the output of `pip show packageName` command
+// and returns a manager.PackageInfo object containing the information of the installed package.
+// The author is the maintainer of the package, and the license expression, if any, is preferred to the license.
+// Fields not covered by manager.PackageInfo, such as the summary and the location, are kept in AdditionalData.
+// Example msg:
+//
+//	Name: requests
+//	Version: 2.31.0
+//	Summary: Python HTTP for Humans.
+//	Home-page: https://requests.readthedocs.io
+//	Author: Kenneth Reitz
+//	Author-email: me@kennethreitz.org
+//	License: Apache 2.0
+//	Location: /usr/lib/python3/dist-packages
+//	Requires: certifi, charset-normalizer, idna, urllib3
+//	Required-by:
+func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageInfo {
+	pkg := manager.PackageInfo{
+		Status:         manager.PackageStatusInstalled,
+		PackageManager: pm,
+		AdditionalData: map[string]string{},
+	}
+
+	var author, email, expression string
+	for _, line := range strings.Split(msg, "\n") {
+		// pip show separates multiple packages with "---"; only the first one is returned
+		if strings.TrimSpace(line) == "---" {
+			break
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Name":
+			pkg.Name = value
+		case "Version":
+			pkg.Version = value
+		case "Home-page":
+			pkg.Homepage = value
+		case "License":
+			pkg.License = value
+		case "License-Expression":
+			expression = value
+		case "Author":
+			author = value
+		case "Author-email":
+			email = value
+		case "Summary", "Location", "Requires", "Required-by":
+			if value != "" {
+				pkg.AdditionalData[strings.ToLower(key)] = value
+			}
+		}
+	}
+
+	if expression != "" {
+		pkg.License = expression
+	}
+	switch {
+	case author != "" && email != "" && !strings.Contains(email, "<"):
+		pkg.Maintainer = author + " <" + email + ">"
+	case author != "":
+		pkg.Maintainer = author
+	default:
+		// the email may already contain the name, e.g. "Kenneth Reitz <me@kennethreitz.org>"
+		pkg.Maintainer = email
+	}
+
+	return pkg
+}
+
+// ParseIndexVersionsOutput parses the output of `pip index versions packageName` command
+// and returns a manager.PackageInfo object containing the latest version available on the package index.
+// Example msg:
+//
+//	requests (2.31.0)
+//	Available versions: 2.31.0, 2.30.0, 2.29.0, 2.28.2
+//	  INSTALLED: 2.28.2
+//	  LATEST:    2.31.0
+func ParseIndexVersionsOutput(msg string, opts *manager.Options) manager.PackageInfo {
+	pkg := manager.PackageInfo{
+		Status:         manager.PackageStatusAvailable,
+		PackageManager: pm,
+	}
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case pkg.Name == "" && strings.HasSuffix(line, ")"):
+			if name, version, found := strings.Cut(strings.TrimSuffix(line, ")"), " ("); found {
+				pkg.Name = name
+				pkg.NewVersion = version
+			}
+		case strings.HasPrefix(line, "INSTALLED:"):
+			pkg.Version = strings.TrimSpace(strings.TrimPrefix(line, "INSTALLED:"))
+		case strings.HasPrefix(line, "LATEST:"):
+			pkg.NewVersion = strings.TrimSpace(strings.TrimPrefix(line, "LATEST:"))
+		}
+	}
+
+	if pkg.Version != "" {
+		pkg.Status = manager.PackageStatusInstalled
+		if pkg.Version != pkg.NewVersion {
+			pkg.Status = manager.PackageStatusUpgradable
+		} else {
+			pkg.NewVersion = ""
+		}
+	}
+
+	return pkg
+}
+
+// splitNameVersion splits a "name-version" string as printed by pip, e.g. "typing-extensions-4.7.1".
+// Versions never contain a hyphen, so the string is split at the last one.
+func splitNameVersion(s string) (string, string) {
+	if idx := strings.LastIndex(s, "-"); idx > 0 {
+		return s[:idx], s[idx+1:]
+	}
+	return s, ""
+}
+
+// normalizeName returns the normalized form of a Python package name, in which
+// "Typing_Extensions" and "typing-extensions" are the same package.
+func normalizeName(name string) string {
+	return strings.ToLower(nameNormalizer.ReplaceAllString(name, "-"))
+}
+
+// packageName returns the name of a pip requirement specifier, without its version or extras,
+// e.g. "requests" for "requests[socks]>=2.31".
+func packageName(spec string) string {
+	if idx := strings.IndexAny(spec, "[<>=!~;@ "); idx > 0 {
+		return spec[:idx]
+	}
+	return spec
+}
+
+// filterPackages returns the packages whose name is one of the given names, or all packages if no names are given.
+func filterPackages(packages []manager.PackageInfo, names []string) []manager.PackageInfo {
+	if len(names) == 0 {
+		return packages
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[normalizeName(packageName(name))] = true
+	}
+
+	var filtered []manager.PackageInfo
+	for _, pkg := range packages {
+		if wanted[normalizeName(pkg.Name)] {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
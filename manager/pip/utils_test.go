@@ -0,0 +1,144 @@
+package pip_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/pip"
+)
+
+func TestParseInstallOutput(t *testing.T) {
+	var inputParseInstallOutput string = strings.Join([]string{
+		`Collecting requests`,
+		`  Using cached requests-2.31.0-py3-none-any.whl (62 kB)`,
+		`Collecting typing-extensions`,
+		`  Using cached typing_extensions-4.7.1-py3-none-any.whl (33 kB)`,
+		`Installing collected packages: typing-extensions, requests`,
+		`Successfully installed requests-2.31.0 typing-extensions-4.7.1`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "requests",
+			Version:        "2.31.0",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "pip",
+		},
+		{
+			Name:           "typing-extensions",
+			Version:        "4.7.1",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "pip",
+		},
+	}
+
+	actualPackageInfo := pip.ParseInstallOutput(inputParseInstallOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseInstallOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseDeletedOutput(t *testing.T) {
+	var inputParseDeletedOutput string = strings.Join([]string{
+		`Found existing installation: requests 2.31.0`,
+		`Uninstalling requests-2.31.0:`,
+		`  Successfully uninstalled requests-2.31.0`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "requests",
+			Version:        "2.31.0",
+			Status:         manager.PackageStatusAvailable,
+			PackageManager: "pip",
+		},
+	}
+
+	actualPackageInfo := pip.ParseDeletedOutput(inputParseDeletedOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDeletedOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListOutput(t *testing.T) {
+	var inputParseListOutput string = `[{"name": "requests", "version": "2.28.2", "latest_version": "2.31.0", "latest_filetype": "wheel"}]`
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "requests",
+			Version:        "2.28.2",
+			NewVersion:     "2.31.0",
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: "pip",
+		},
+	}
+
+	actualPackageInfo, err := pip.ParseListOutput([]byte(inputParseListOutput), manager.PackageStatusUpgradable, &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseListOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParsePackageInfoOutput(t *testing.T) {
+	var inputParsePackageInfoOutput string = strings.Join([]string{
+		`Name: requests`,
+		`Version: 2.31.0`,
+		`Summary: Python HTTP for Humans.`,
+		`Home-page: https://requests.readthedocs.io`,
+		`Author: Kenneth Reitz`,
+		`Author-email: me@kennethreitz.org`,
+		`License: Apache 2.0`,
+		`Location: /usr/lib/python3/dist-packages`,
+		`Requires: certifi, idna, urllib3`,
+		`Required-by: `,
+	}, "\n")
+
+	var expectedPackageInfo = manager.PackageInfo{
+		Name:           "requests",
+		Version:        "2.31.0",
+		Status:         manager.PackageStatusInstalled,
+		License:        "Apache 2.0",
+		Homepage:       "https://requests.readthedocs.io",
+		Maintainer:     "Kenneth Reitz <me@kennethreitz.org>",
+		PackageManager: "pip",
+		AdditionalData: map[string]string{
+			"summary":  "Python HTTP for Humans.",
+			"location": "/usr/lib/python3/dist-packages",
+			"requires": "certifi, idna, urllib3",
+		},
+	}
+
+	actualPackageInfo := pip.ParsePackageInfoOutput(inputParsePackageInfoOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParsePackageInfoOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseIndexVersionsOutput(t *testing.T) {
+	var inputParseIndexVersionsOutput string = strings.Join([]string{
+		`requests (2.31.0)`,
+		`Available versions: 2.31.0, 2.30.0, 2.29.0, 2.28.2`,
+	}, "\n")
+
+	var expectedPackageInfo = manager.PackageInfo{
+		Name:           "requests",
+		NewVersion:     "2.31.0",
+		Status:         manager.PackageStatusAvailable,
+		PackageManager: "pip",
+	}
+
+	actualPackageInfo := pip.ParseIndexVersionsOutput(inputParseIndexVersionsOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseIndexVersionsOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
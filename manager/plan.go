@@ -0,0 +1,124 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+// PlanAction is the change an operation makes to a package.
+type PlanAction string
+
+// PlanAction constants define the changes made to packages.
+const (
+	// PlanActionInstall installs a package that is not installed.
+	PlanActionInstall PlanAction = "install"
+
+	// PlanActionUpgrade replaces an installed package with a newer version.
+	PlanActionUpgrade PlanAction = "upgrade"
+
+	// PlanActionDowngrade replaces an installed package with an older version.
+	PlanActionDowngrade PlanAction = "downgrade"
+
+	// PlanActionRemove removes an installed package.
+	PlanActionRemove PlanAction = "remove"
+)
+
+// PlanEntry is a change an operation makes to a package.
+type PlanEntry struct {
+	// Action is the change made to the package.
+	Action PlanAction `json:"action"`
+
+	// Name is the package name.
+	Name string `json:"name"`
+
+	// Version is the installed version of the package, for upgrades, downgrades and removals, if known.
+	Version string `json:"version,omitempty"`
+
+	// NewVersion is the version of the package after the operation, for installs, upgrades and downgrades, if known.
+	NewVersion string `json:"new_version,omitempty"`
+
+	// Arch is the architecture of the package, if known.
+	Arch string `json:"arch,omitempty"`
+
+	// DownloadSize is the number of bytes downloaded for the package, or 0 if unknown.
+	DownloadSize int64 `json:"download_size,omitempty"`
+
+	// InstalledSize is the disk space used by the installed package, in bytes, or 0 if unknown.
+	InstalledSize int64 `json:"installed_size,omitempty"`
+}
+
+// Plan describes the changes an operation would make to the packages of a package manager, without making them.
+type Plan struct {
+	// PackageManager is the name of the package manager the operation runs with.
+	PackageManager string `json:"package_manager"`
+
+	// Entries are the changes made to packages, in the order reported by the package manager.
+	Entries []PlanEntry `json:"entries"`
+
+	// DownloadSize is the number of bytes downloaded by the operation, or 0 if unknown.
+	DownloadSize int64 `json:"download_size,omitempty"`
+
+	// DiskSpace is the disk space used by the operation once done, in bytes: negative when it frees disk space,
+	// and 0 if unknown.
+	DiskSpace int64 `json:"disk_space,omitempty"`
+}
+
+// NewPlan returns the plan of an operation, install, upgrade or remove, from the packages it returns in dry-run mode.
+// The action of each package of an install or upgrade is told from its versions: packages replacing an installed
+// version are upgrades or downgrades, and other packages are installs, or upgrades for upgrade operations.
+func NewPlan(pm string, operation PlanAction, packages []PackageInfo) *Plan {
+	plan := &Plan{PackageManager: pm, Entries: []PlanEntry{}}
+	for _, pkg := range packages {
+		entry := PlanEntry{Action: operation, Name: pkg.Name, Version: pkg.Version, NewVersion: pkg.NewVersion, Arch: pkg.Arch}
+		switch {
+		case operation == PlanActionRemove:
+			if entry.Version == "" {
+				entry.Version = pkg.NewVersion
+			}
+			entry.NewVersion = ""
+		case pkg.Version != "" && pkg.NewVersion != "" && pkg.Version != pkg.NewVersion:
+			entry.Action = PlanActionUpgrade
+			if CompareVersions(pkg.NewVersion, pkg.Version) < 0 {
+				entry.Action = PlanActionDowngrade
+			}
+		default:
+			// package managers report either the new version only, or the same version twice
+			if entry.NewVersion == "" {
+				entry.NewVersion = pkg.Version
+			}
+			if operation == PlanActionInstall || entry.Version == entry.NewVersion {
+				entry.Version = ""
+			}
+		}
+		plan.Entries = append(plan.Entries, entry)
+	}
+	return plan
+}
+
+// Count returns the number of entries of the plan with the given action.
+func (p *Plan) Count(action PlanAction) int {
+	n := 0
+	for _, entry := range p.Entries {
+		if entry.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Packages returns the packages of the plan, as returned by operations: installed for installs, upgrades
+// and downgrades, and available for removals.
+func (p *Plan) Packages() []PackageInfo {
+	var packages []PackageInfo
+	for _, entry := range p.Entries {
+		pkg := PackageInfo{
+			Name:           entry.Name,
+			Version:        entry.Version,
+			NewVersion:     entry.NewVersion,
+			Status:         PackageStatusInstalled,
+			Arch:           entry.Arch,
+			PackageManager: p.PackageManager,
+		}
+		if entry.Action == PlanActionRemove {
+			pkg.Status = PackageStatusAvailable
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
@@ -0,0 +1,36 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestNewPlan(t *testing.T) {
+	packages := []manager.PackageInfo{
+		{Name: "vim", NewVersion: "9.1.0016-1", Status: manager.PackageStatusInstalled},
+		{Name: "libc6", Version: "2.39-0ubuntu8.3", NewVersion: "2.39-0ubuntu8.4", Arch: "amd64"},
+		{Name: "nginx", Version: "1.24.0-2", NewVersion: "1.22.1-9"},
+		{Name: "curl", Version: "8.5.0-2", NewVersion: "8.5.0-2"},
+	}
+	want := &manager.Plan{PackageManager: "apt", Entries: []manager.PlanEntry{
+		{Action: manager.PlanActionInstall, Name: "vim", NewVersion: "9.1.0016-1"},
+		{Action: manager.PlanActionUpgrade, Name: "libc6", Version: "2.39-0ubuntu8.3", NewVersion: "2.39-0ubuntu8.4", Arch: "amd64"},
+		{Action: manager.PlanActionDowngrade, Name: "nginx", Version: "1.24.0-2", NewVersion: "1.22.1-9"},
+		{Action: manager.PlanActionInstall, Name: "curl", NewVersion: "8.5.0-2"},
+	}}
+	plan := manager.NewPlan("apt", manager.PlanActionInstall, packages)
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("NewPlan() = %+v, want %+v", plan, want)
+	}
+	if n := plan.Count(manager.PlanActionInstall); n != 2 {
+		t.Errorf("Count() = %d, want 2", n)
+	}
+
+	plan = manager.NewPlan("pacman", manager.PlanActionRemove, []manager.PackageInfo{{Name: "nano", Version: "7.2-1", Status: manager.PackageStatusAvailable}})
+	wantPackages := []manager.PackageInfo{{Name: "nano", Version: "7.2-1", Status: manager.PackageStatusAvailable, PackageManager: "pacman"}}
+	if packages := plan.Packages(); !reflect.DeepEqual(packages, wantPackages) {
+		t.Errorf("Packages() = %+v, want %+v", packages, wantPackages)
+	}
+}
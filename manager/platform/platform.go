@@ -0,0 +1,228 @@
+// Package platform detects the platform syspkg runs on: the distribution, whether it runs in a container or a virtual
+// machine, under WSL, and its init system. syspkg uses it to skip probing the system package managers of other
+// distributions, such as pacman on Debian, and reports it with the package managers.
+//
+// This package is part of the syspkg library.
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Virtualization is the kind of environment syspkg runs in.
+type Virtualization string
+
+// Kinds of environments detected.
+const (
+	VirtualizationNone      Virtualization = "none"
+	VirtualizationContainer Virtualization = "container"
+	VirtualizationVM        Virtualization = "vm"
+)
+
+// Platform describes the platform syspkg runs on.
+type Platform struct {
+	// OS is the operating system, as runtime.GOOS, such as linux or darwin.
+	OS string `json:"os"`
+
+	// ID and IDLike are the identifiers of the distribution and of the ones it derives from, as found in os-release,
+	// such as "linuxmint" and ["ubuntu", "debian"]. Both are empty if unknown.
+	ID     string   `json:"id,omitempty"`
+	IDLike []string `json:"id_like,omitempty"`
+
+	// VersionID is the version of the distribution, such as "12" or "22.04".
+	VersionID string `json:"version_id,omitempty"`
+
+	// Name is the name of the distribution to display, such as "Debian GNU/Linux 12 (bookworm)".
+	Name string `json:"name,omitempty"`
+
+	// Virtualization tells whether syspkg runs in a container, a virtual machine or on bare metal, as far as it can
+	// tell without root privileges.
+	Virtualization Virtualization `json:"virtualization"`
+
+	// WSL is set under the Windows Subsystem for Linux.
+	WSL bool `json:"wsl,omitempty"`
+
+	// Init is the init system, such as systemd or openrc, or empty if unknown, as in most containers.
+	Init string `json:"init,omitempty"`
+}
+
+// families are the distributions the system package managers run on, by package manager name, matching the ID and
+// ID_LIKE of os-release, or their prefix, such as opensuse-leap.
+var families = map[string][]string{
+	"apk":     {"alpine"},
+	"apt":     {"debian", "ubuntu"},
+	"pacman":  {"arch"},
+	"portage": {"gentoo"},
+	"zypper":  {"suse", "opensuse", "sles"},
+}
+
+// hypervisors are the vendors and products of the firmware of virtual machines.
+var hypervisors = []string{"qemu", "kvm", "vmware", "virtualbox", "xen", "bochs", "parallels", "google compute engine", "amazon ec2"}
+
+// inits are the init systems recognized as the command of process 1.
+var inits = []string{"systemd", "openrc-init", "runit", "s6-svscan", "dinit", "upstart"}
+
+// Detect detects the running platform.
+func Detect() Platform {
+	return DetectAt("/")
+}
+
+// DetectAt detects the platform of the system whose root directory is root, reading its /etc, /proc, /run and /sys.
+func DetectAt(root string) Platform {
+	p := Platform{OS: runtime.GOOS, Virtualization: VirtualizationNone}
+	if p.OS != "linux" {
+		if p.OS == "darwin" {
+			p.Init = "launchd"
+		}
+		return p
+	}
+
+	for _, path := range []string{"etc/os-release", "usr/lib/os-release"} {
+		if data, err := os.ReadFile(filepath.Join(root, path)); err == nil {
+			p.setDistribution(data)
+			break
+		}
+	}
+
+	osrelease := strings.ToLower(readFile(root, "proc/sys/kernel/osrelease"))
+	p.WSL = strings.Contains(osrelease, "microsoft") || strings.Contains(osrelease, "wsl")
+
+	switch {
+	case exists(root, ".dockerenv"), exists(root, "run/.containerenv"), readFile(root, "run/systemd/container") != "",
+		containerCgroup(readFile(root, "proc/1/cgroup")):
+		p.Virtualization = VirtualizationContainer
+	case isHypervisor(readFile(root, "sys/class/dmi/id/sys_vendor")), isHypervisor(readFile(root, "sys/class/dmi/id/product_name")),
+		slices.Contains(cpuFlags(readFile(root, "proc/cpuinfo")), "hypervisor") && !p.WSL:
+		p.Virtualization = VirtualizationVM
+	}
+
+	switch comm := readFile(root, "proc/1/comm"); {
+	case exists(root, "run/systemd/system"):
+		p.Init = "systemd"
+	case exists(root, "run/openrc"):
+		p.Init = "openrc"
+	case slices.Contains(inits, comm):
+		p.Init = strings.TrimSuffix(comm, "-init")
+	}
+	return p
+}
+
+// FromOSRelease returns the platform of a Linux container whose os-release file is data, such as a container
+// inspected from the outside.
+func FromOSRelease(data []byte) Platform {
+	p := Platform{OS: "linux", Virtualization: VirtualizationContainer}
+	p.setDistribution(data)
+	return p
+}
+
+// setDistribution sets the distribution of the platform from the content of its os-release file.
+func (p *Platform) setDistribution(data []byte) {
+	fields := parseOSRelease(data)
+	p.ID, p.VersionID, p.Name = fields["ID"], fields["VERSION_ID"], fields["PRETTY_NAME"]
+	if like := strings.Fields(fields["ID_LIKE"]); len(like) > 0 {
+		p.IDLike = like
+	}
+}
+
+// Supports reports whether the named package manager may run on the platform: the system package managers only run
+// on their distributions, and winget only on Windows. It reports true for the other package managers, and on unknown
+// distributions.
+func (p Platform) Supports(pm string) bool {
+	if pm == "winget" {
+		return p.OS == "windows"
+	}
+	family, ok := families[pm]
+	if !ok || p.OS != "linux" || p.ID == "" {
+		return true
+	}
+	for _, id := range append([]string{p.ID}, p.IDLike...) {
+		for _, name := range family {
+			if id == name || strings.HasPrefix(id, name+"-") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String returns the name of the platform with its environment, such as "Debian GNU/Linux 12 (bookworm), container".
+func (p Platform) String() string {
+	parts := []string{p.Name}
+	if p.Name == "" {
+		parts[0] = p.OS
+	}
+	if p.WSL {
+		parts = append(parts, "WSL")
+	}
+	if p.Virtualization != VirtualizationNone {
+		parts = append(parts, string(p.Virtualization))
+	}
+	if p.Init != "" {
+		parts = append(parts, p.Init)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseOSRelease returns the fields of an os-release file.
+func parseOSRelease(data []byte) map[string]string {
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if key, value, found := strings.Cut(scanner.Text(), "="); found {
+			fields[key] = strings.Trim(value, `"'`)
+		}
+	}
+	return fields
+}
+
+// containerCgroup reports whether the cgroups of process 1 are the ones of a container.
+func containerCgroup(cgroup string) bool {
+	for _, name := range []string{"docker", "kubepods", "lxc", "containerd", "libpod"} {
+		if strings.Contains(cgroup, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHypervisor reports whether a vendor or product name of the firmware is the one of a virtual machine.
+func isHypervisor(name string) bool {
+	name = strings.ToLower(name)
+	for _, hypervisor := range hypervisors {
+		if strings.Contains(name, hypervisor) {
+			return true
+		}
+	}
+	return strings.Contains(name, "virtual machine")
+}
+
+// cpuFlags returns the flags of the first processor of /proc/cpuinfo.
+func cpuFlags(cpuinfo string) []string {
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		if key, value, found := strings.Cut(line, ":"); found && strings.TrimSpace(key) == "flags" {
+			return strings.Fields(value)
+		}
+	}
+	return nil
+}
+
+// readFile returns the trimmed content of the file at path under root, or an empty string if it can't be read.
+func readFile(root, path string) string {
+	data, err := os.ReadFile(filepath.Join(root, path))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// exists reports whether the file at path under root exists.
+func exists(root, path string) bool {
+	_, err := os.Stat(filepath.Join(root, path))
+	return err == nil
+}
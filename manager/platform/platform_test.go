@@ -0,0 +1,107 @@
+package platform_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/bluet/syspkg/manager/platform"
+)
+
+// writeFiles writes files under root, by path.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectAt(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the platform is only detected from files on Linux")
+	}
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected platform.Platform
+	}{
+		{
+			name: "container",
+			files: map[string]string{
+				"etc/os-release": "PRETTY_NAME=\"Ubuntu 22.04.3 LTS\"\nID=ubuntu\nID_LIKE=debian\nVERSION_ID=\"22.04\"\n",
+				".dockerenv":     "",
+				"proc/1/comm":    "bash\n",
+			},
+			expected: platform.Platform{OS: "linux", ID: "ubuntu", IDLike: []string{"debian"}, VersionID: "22.04", Name: "Ubuntu 22.04.3 LTS", Virtualization: platform.VirtualizationContainer},
+		},
+		{
+			name: "virtual machine",
+			files: map[string]string{
+				"usr/lib/os-release":           "ID=arch\nPRETTY_NAME=\"Arch Linux\"\n",
+				"sys/class/dmi/id/sys_vendor":  "QEMU\n",
+				"run/systemd/system/.keep":     "",
+				"proc/sys/kernel/osrelease":    "6.6.1-arch1-1\n",
+				"sys/class/dmi/id/bios_vendor": "SeaBIOS\n",
+			},
+			expected: platform.Platform{OS: "linux", ID: "arch", Name: "Arch Linux", Virtualization: platform.VirtualizationVM, Init: "systemd"},
+		},
+		{
+			name: "WSL",
+			files: map[string]string{
+				"etc/os-release":            "ID=opensuse-tumbleweed\nID_LIKE=\"opensuse suse\"\n",
+				"proc/sys/kernel/osrelease": "5.15.133.1-microsoft-standard-WSL2\n",
+				"proc/cpuinfo":              "processor\t: 0\nflags\t\t: fpu vme hypervisor\n",
+				"proc/1/comm":               "openrc-init\n",
+			},
+			expected: platform.Platform{OS: "linux", ID: "opensuse-tumbleweed", IDLike: []string{"opensuse", "suse"}, Virtualization: platform.VirtualizationNone, WSL: true, Init: "openrc"},
+		},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		writeFiles(t, root, tt.files)
+		if actual := platform.DetectAt(root); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("DetectAt(%s) = %#v, want %#v", tt.name, actual, tt.expected)
+		}
+	}
+}
+
+func TestSupports(t *testing.T) {
+	mint := platform.Platform{OS: "linux", ID: "linuxmint", IDLike: []string{"ubuntu", "debian"}}
+	leap := platform.Platform{OS: "linux", ID: "opensuse-leap", IDLike: []string{"suse", "opensuse"}}
+	unknown := platform.Platform{OS: "linux"}
+	tests := []struct {
+		platform platform.Platform
+		pm       string
+		expected bool
+	}{
+		{mint, "apt", true},
+		{mint, "pacman", false},
+		{mint, "flatpak", true},
+		{mint, "winget", false},
+		{leap, "zypper", true},
+		{leap, "apt", false},
+		{unknown, "pacman", true},
+		{platform.Platform{OS: "windows"}, "winget", true},
+	}
+	for _, tt := range tests {
+		if actual := tt.platform.Supports(tt.pm); actual != tt.expected {
+			t.Errorf("Platform{ID: %q}.Supports(%s) = %t, want %t", tt.platform.ID, tt.pm, actual, tt.expected)
+		}
+	}
+}
+
+func TestFromOSRelease(t *testing.T) {
+	actual := platform.FromOSRelease([]byte("NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.19.1\nPRETTY_NAME=\"Alpine Linux v3.19\"\n"))
+	expected := platform.Platform{OS: "linux", ID: "alpine", VersionID: "3.19.1", Name: "Alpine Linux v3.19", Virtualization: platform.VirtualizationContainer}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("FromOSRelease() = %#v, want %#v", actual, expected)
+	}
+}
@@ -0,0 +1,76 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrAmbiguousPackageManager is returned when a package could be installed by several package managers, and the
+// install policy requires choosing one explicitly.
+var ErrAmbiguousPackageManager = errors.New("several package managers could install the package")
+
+// InstallPolicy selects the package managers installing a package when several package managers are selected, so
+// that the same software is not installed twice, such as vim by both apt and snap.
+type InstallPolicy string
+
+// InstallPolicy constants define how packages are assigned to package managers.
+const (
+	// InstallPolicyBest installs each package with the package manager of highest priority providing it.
+	InstallPolicyBest InstallPolicy = "best"
+
+	// InstallPolicyFirst tries the package managers in order of priority, until one installs the package.
+	InstallPolicyFirst InstallPolicy = "first"
+
+	// InstallPolicyExplicit installs packages only with a single selected package manager, or the one they are
+	// targeted at, and fails otherwise with ErrAmbiguousPackageManager.
+	InstallPolicyExplicit InstallPolicy = "explicit"
+
+	// InstallPolicyAll installs each package with every selected package manager.
+	InstallPolicyAll InstallPolicy = "all"
+)
+
+// InstallPolicies are the valid install policies, the default one first.
+var InstallPolicies = []InstallPolicy{InstallPolicyBest, InstallPolicyFirst, InstallPolicyExplicit, InstallPolicyAll}
+
+// DefaultPriority is the order of preference of the package managers installing packages, for the ones missing from
+// the configured priority: the package managers of the system first, then the ones of sandboxed applications, and
+// the package managers of programming languages last.
+var DefaultPriority = []string{
+	"apt", "zypper", "pacman", "apk", "portage", "winget", "brew", "nix", "flatpak", "snap", "fwupd", "conda", "pip", "npm",
+	"cargo", "gem", "gobin",
+}
+
+// ParseInstallPolicy parses an install policy, returning InstallPolicyBest for an empty one.
+func ParseInstallPolicy(s string) (InstallPolicy, error) {
+	if s == "" {
+		return InstallPolicyBest, nil
+	}
+	if policy := InstallPolicy(s); slices.Contains(InstallPolicies, policy) {
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown install policy %q, expected best, first, explicit or all", s)
+}
+
+// SortByPriority sorts package manager names in order of preference: the ones of priority first, in its order, then
+// the others in the order of DefaultPriority, then by name.
+func SortByPriority(names []string, priority []string) {
+	rank := func(name string) int {
+		if i := slices.Index(priority, name); i >= 0 {
+			return i
+		}
+		if i := slices.Index(DefaultPriority, name); i >= 0 {
+			return len(priority) + i
+		}
+		return len(priority) + len(DefaultPriority)
+	}
+	slices.SortStableFunc(names, func(a, b string) int {
+		if order := cmp.Compare(rank(a), rank(b)); order != 0 {
+			return order
+		}
+		return strings.Compare(a, b)
+	})
+}
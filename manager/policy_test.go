@@ -0,0 +1,30 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestParseInstallPolicy(t *testing.T) {
+	if policy, err := manager.ParseInstallPolicy(""); err != nil || policy != manager.InstallPolicyBest {
+		t.Errorf("ParseInstallPolicy(\"\") = %q, %+v, want %q", policy, err, manager.InstallPolicyBest)
+	}
+	if policy, err := manager.ParseInstallPolicy("explicit"); err != nil || policy != manager.InstallPolicyExplicit {
+		t.Errorf("ParseInstallPolicy(explicit) = %q, %+v, want %q", policy, err, manager.InstallPolicyExplicit)
+	}
+	if _, err := manager.ParseInstallPolicy("everywhere"); err == nil {
+		t.Errorf("ParseInstallPolicy(everywhere) error = nil, want an error")
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	names := []string{"pip", "snap", "mystery", "apt", "flatpak", "another"}
+
+	expectedNames := []string{"snap", "apt", "flatpak", "pip", "another", "mystery"}
+	manager.SortByPriority(names, []string{"snap"})
+	if !reflect.DeepEqual(expectedNames, names) {
+		t.Errorf("SortByPriority() = %+v, want %+v", names, expectedNames)
+	}
+}
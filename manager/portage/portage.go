@@ -0,0 +1,268 @@
+// Package portage provides an implementation of the syspkg manager interface for the Portage package manager.
+// It provides a Go (golang) API interface for interacting with Portage, the package manager of Gentoo Linux.
+// This package is a wrapper around the emerge command line tool, and qlist from portage-utils.
+//
+// Portage builds packages from source according to ebuilds, and lets users customize each build with USE flags.
+// Packages are identified by their category and name (e.g. "app-editors/vim"), and the set of packages the user asked for
+// is kept in the world set (@world). Packages that are no longer needed by the world set are removed with emerge --depclean.
+// emerge can show what it would do with --pretend, which is used by this package for dry runs and to list upgradable packages.
+//
+// For more information about Portage, visit:
+// - https://wiki.gentoo.org/wiki/Portage
+// - https://wiki.gentoo.org/wiki/Emerge
+// - https://wiki.gentoo.org/wiki/Q_applets
+//
+// This package is part of the syspkg library.
+package portage
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "portage"
+
+// emerge is the command line interface of Portage.
+var emerge string = "emerge"
+
+// qlist is the tool from portage-utils used to list installed packages quickly.
+var qlist string = "qlist"
+
+// Constants used for emerge commands
+const (
+	ArgsAssumeNo   string = "--ask=n"
+	ArgsPretend    string = "--pretend"
+	ArgsVerbose    string = "--verbose"
+	ArgsNoColor    string = "--color=n"
+	ArgsNoSpinner  string = "--nospinner"
+	ArgsQuietBuild string = "--quiet-build=y"
+	ArgsUpdate     string = "--update"
+	ArgsDeep       string = "--deep"
+	ArgsNewUse     string = "--newuse"
+	ArgsDepClean   string = "--depclean"
+	ArgsSearch     string = "--search"
+	ArgsSync       string = "--sync"
+	ArgsNoDeps     string = "--nodeps"
+	ArgsOneShot    string = "--oneshot"
+	ArgsWorld      string = "@world"
+)
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for emerge.
+var ENV_NonInteractive []string = []string{"LC_ALL=C", "NOCOLOR=true"}
+
+// PackageManager implements the manager.PackageManager interface for the Portage package manager.
+type PackageManager struct{}
+
+// IsAvailable checks if the Portage package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(emerge)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the Portage package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by portage.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:           true,
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+	}
+}
+
+// Install builds and installs the provided packages using emerge, and adds them to the world set.
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// emerge installs a specific version with the =category/name-version atom
+	pkgs, err := manager.TranslatePackageSpecs(pkgs, func(spec manager.PackageSpec) string { return "=" + spec.Name + "-" + spec.Version })
+	if err != nil {
+		return nil, err
+	}
+
+	return a.merge(pkgs, opts)
+}
+
+// Delete removes the provided packages using emerge --depclean, which refuses to remove packages still needed by others.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.depclean(pkgs, opts)
+}
+
+// Refresh synchronizes the ebuild repositories using emerge --sync.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	out, err := a.run([]string{ArgsSync}, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Verbose && out != nil {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
+
+// Find searches for packages whose name matches the provided keywords using emerge --search.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{ArgsSearch, ArgsNoColor}, keywords...)
+	cmd := manager.Command(opts, emerge, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSearchOutput(string(out), opts), nil
+}
+
+// ListInstalled lists all installed packages using qlist.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, qlist, "-I", "-v")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListInstalledOutput(string(out), opts), nil
+}
+
+// ListUpgradable lists the packages that an update of the world set would upgrade, using emerge --pretend.
+// The result is based on the local copy of the ebuild repositories, so Refresh should be called first for up-to-date results.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, emerge, ArgsPretend, ArgsVerbose, ArgsNoColor, ArgsUpdate, ArgsDeep, ArgsNewUse, ArgsWorld)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []manager.PackageInfo
+	for _, pkg := range ParsePretendOutput(string(out), opts) {
+		if pkg.Status == manager.PackageStatusUpgradable {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages, nil
+}
+
+// Upgrade updates the provided packages, or the whole world set if no packages are given,
+// including their dependencies and the packages whose USE flags changed (emerge -uDN).
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	targets := pkgs
+	if len(targets) == 0 {
+		targets = []string{ArgsWorld}
+	}
+	args := append([]string{ArgsUpdate, ArgsDeep, ArgsNewUse}, targets...)
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", emerge, "args", args)
+
+	return a.merge(args, opts)
+}
+
+// UpgradeAll updates the whole world set using emerge -uDN @world.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// AutoRemove removes the packages that are not needed by the world set, using emerge --depclean.
+func (a *PackageManager) AutoRemove(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.depclean(nil, opts)
+}
+
+// GetPackageInfo retrieves information about the specified package using emerge --pretend,
+// including the USE flags it is (or would be) built with, in AdditionalData["use"].
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	cmd := manager.Command(opts, emerge, ArgsPretend, ArgsVerbose, ArgsNoColor, ArgsNoDeps, ArgsOneShot, pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+
+	packages := ParsePretendOutput(string(out), opts)
+	if len(packages) == 0 {
+		return manager.PackageInfo{Name: pkg, Status: manager.PackageStatusUnknown, PackageManager: pm}, nil
+	}
+	return packages[0], nil
+}
+
+// merge runs emerge to build and install packages (with --pretend in dry-run mode), and returns the merged packages.
+func (a *PackageManager) merge(args []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append([]string{ArgsPretend, ArgsVerbose}, args...)
+	} else {
+		args = append([]string{ArgsQuietBuild}, args...)
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return ParsePretendOutput(string(out), opts), nil
+	}
+	return ParseMergeOutput(string(out), opts), nil
+}
+
+// depclean runs emerge --depclean for the given packages, or for all packages not needed by the world set if none are given,
+// and returns the removed packages.
+func (a *PackageManager) depclean(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{ArgsDepClean}, pkgs...)
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.DryRun {
+		args = append([]string{ArgsPretend}, args...)
+	}
+
+	out, err := a.run(args, opts)
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return ParseDepcleanOutput(string(out), opts), nil
+}
+
+// run runs emerge with the given arguments, either attached to the terminal in interactive mode (asking for confirmation),
+// in which case no output is returned, or non-interactively, returning the standard output.
+func (a *PackageManager) run(args []string, opts *manager.Options) ([]byte, error) {
+	if opts.Interactive {
+		cmd := manager.Command(opts, emerge, append([]string{"--ask"}, args...)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return nil, manager.Run(cmd)
+	}
+
+	cmd := manager.Command(opts, emerge, append([]string{ArgsAssumeNo, ArgsNoColor, ArgsNoSpinner}, args...)...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	return manager.Output(cmd)
+}
@@ -0,0 +1,284 @@
+// Package portage provides a package manager implementation for Gentoo Linux
+// using emerge as the underlying package management tool.
+package portage
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// cpvPattern splits a "category/name-version" package string, e.g. "app-editors/vim-9.0.1627-r1",
+// capturing the category, the name and the version. The version starts at the last hyphen followed by a digit.
+var cpvPattern = regexp.MustCompile(`^([^/\s]+)/(\S+)-(\d[^-\s]*(?:-r\d+)?)$`)
+
+// pretendPattern matches the package lines of emerge --pretend, e.g.
+// `[ebuild     U  ] app-editors/vim-9.0.1627::gentoo [9.0.1503::gentoo] USE="acl nls -X" 16,943 KiB`,
+// capturing the merge type, the flags, the package, the repository, the replaced version and the rest of the line.
+var pretendPattern = regexp.MustCompile(`^\[(ebuild|binary)\s*([^\]]*)\]\s+(\S+?)(?:::(\S+))?(?:\s+\[([^\]]+)\])?(?:\s+(.*))?$`)
+
+// usePattern matches the USE flags of an emerge --pretend --verbose line.
+var usePattern = regexp.MustCompile(`\bUSE="([^"]*)"`)
+
+// completedPattern matches the lines reporting a merged package, e.g. ">>> Completed (1 of 2) app-misc/foo-1.0::gentoo".
+var completedPattern = regexp.MustCompile(`^>>> Completed \(\d+ of \d+\) (\S+?)(?:::(\S+))?$`)
+
+// ParsePretendOutput parses the output of `emerge --pretend --verbose` commands
+// and returns the list of packages that would be merged.
+// New packages are marked as available, upgraded packages as upgradable, and reinstalled packages as installed.
+// The USE flags are kept in AdditionalData["use"] and the repository as Origin.
+// Example msg:
+//
+//	These are the packages that would be merged, in order:
+//
+//	Calculating dependencies... done!
+//	[ebuild  N     ] app-misc/foo-1.0::gentoo  USE="-doc" 120 KiB
+//	[ebuild     U  ] app-editors/vim-9.0.1627::gentoo [9.0.1503::gentoo] USE="acl nls -X" 16,943 KiB
+//	[ebuild   R    ] sys-apps/sed-4.9::gentoo  USE="acl nls -static" 0 KiB
+func ParsePretendOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		match := pretendPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		category, name, version := splitCPV(match[3])
+		packageInfo := manager.PackageInfo{
+			Name:           category + "/" + name,
+			Category:       category,
+			PackageManager: pm,
+			AdditionalData: map[string]string{},
+		}
+		packageInfo.Origin = match[4]
+		if use := usePattern.FindStringSubmatch(match[6]); use != nil {
+			packageInfo.AdditionalData["use"] = use[1]
+		}
+
+		flags := match[2]
+		switch {
+		case strings.ContainsAny(flags, "UD"):
+			packageInfo.Version, _, _ = strings.Cut(match[5], "::")
+			packageInfo.NewVersion = version
+			packageInfo.Status = manager.PackageStatusUpgradable
+		case strings.Contains(flags, "R"):
+			packageInfo.Version = version
+			packageInfo.Status = manager.PackageStatusInstalled
+		default:
+			packageInfo.NewVersion = version
+			packageInfo.Status = manager.PackageStatusAvailable
+		}
+
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseMergeOutput parses the output of emerge commands that merge packages (install, upgrade)
+// and returns the list of merged packages, marked as installed.
+// Example msg:
+//
+//	>>> Emerging (1 of 2) app-misc/bar-2.0::gentoo
+//	>>> Installing (1 of 2) app-misc/bar-2.0::gentoo
+//	>>> Completed (1 of 2) app-misc/bar-2.0::gentoo
+//	>>> Emerging (2 of 2) app-misc/foo-1.0::gentoo
+//	>>> Completed (2 of 2) app-misc/foo-1.0::gentoo
+func ParseMergeOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		match := completedPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			if opts != nil && opts.Verbose && line != "" {
+				opts.Log().Debug("Output", "package_manager", pm, "line", line)
+			}
+			continue
+		}
+
+		category, name, version := splitCPV(match[1])
+		packageInfo := manager.PackageInfo{
+			Name:           category + "/" + name,
+			Version:        version,
+			Category:       category,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		}
+		packageInfo.Origin = match[2]
+		packages = append(packages, packageInfo)
+	}
+
+	return packages
+}
+
+// ParseDepcleanOutput parses the output of `emerge --depclean` command (with or without --pretend)
+// and returns the list of packages that are (or would be) removed, marked as available.
+// Example msg:
+//
+//	Calculating dependencies... done!
+//	>>> These are the packages that would be unmerged:
+//
+//	 app-misc/foo
+//	    selected: 1.0 1.1
+//	   protected: none
+//	     omitted: none
+func ParseDepcleanOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	var current string
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+
+		if key, value, found := strings.Cut(line, ":"); found && current != "" {
+			if key == "selected" && value != "" && strings.TrimSpace(value) != "none" {
+				category, _, _ := strings.Cut(current, "/")
+				for _, version := range strings.Fields(value) {
+					packages = append(packages, manager.PackageInfo{
+						Name:           current,
+						Version:        version,
+						Category:       category,
+						Status:         manager.PackageStatusAvailable,
+						PackageManager: pm,
+					})
+				}
+			}
+			continue
+		}
+
+		// package lines are bare "category/name" atoms
+		if strings.Count(line, "/") == 1 && !strings.ContainsAny(line, " :") {
+			current = line
+		} else {
+			current = ""
+		}
+	}
+
+	return packages
+}
+
+// ParseSearchOutput parses the output of `emerge --search keyword` command
+// and returns a list of packages that match the search query.
+// Example msg:
+//
+//	[ Results for search key : vim ]
+//	Searching...
+//
+//	*  app-editors/vim
+//	      Latest version available: 9.0.1627
+//	      Latest version installed: 9.0.1503
+//	      Size of files: 16,943 KiB
+//	      Homepage:      https://vim.org/ https://github.com/vim/vim
+//	      Description:   Vim, an improved vi-style text editor
+//	      License:       vim
+//
+//	*  app-vim/gentoo-syntax [ Not Installed ]
+//	      Latest version available: 20230525
+//	      Latest version installed: [ Not Installed ]
+//
+//	[ Applications found : 2 ]
+func ParseSearchOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "* ") {
+			fields := strings.Fields(strings.TrimPrefix(line, "*"))
+			if len(fields) == 0 {
+				continue
+			}
+			category, _, _ := strings.Cut(fields[0], "/")
+			packages = append(packages, manager.PackageInfo{
+				Name:           fields[0],
+				Category:       category,
+				Status:         manager.PackageStatusAvailable,
+				PackageManager: pm,
+			})
+			continue
+		}
+		if len(packages) == 0 {
+			continue
+		}
+
+		current := &packages[len(packages)-1]
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Latest version available":
+			current.NewVersion = value
+		case "Latest version installed":
+			if value != "[ Not Installed ]" {
+				current.Version = value
+			}
+		case "Homepage":
+			current.Homepage = value
+		case "License":
+			current.License = value
+		case "Size of files":
+			// the size of the source files downloaded to build the package
+			current.SizeDownload, _ = manager.ParseSize(value)
+		case "Description":
+			if current.AdditionalData == nil {
+				current.AdditionalData = map[string]string{}
+			}
+			current.AdditionalData["description"] = value
+		}
+	}
+
+	for i := range packages {
+		if packages[i].Version == "" {
+			continue
+		}
+		packages[i].Status = manager.PackageStatusInstalled
+		if packages[i].NewVersion != packages[i].Version {
+			packages[i].Status = manager.PackageStatusUpgradable
+		} else {
+			packages[i].NewVersion = ""
+		}
+	}
+
+	return packages
+}
+
+// ParseListInstalledOutput parses the output of `qlist -I -v` command and returns a list of installed packages.
+// Example msg:
+//
+//	app-editors/vim-9.0.1503
+//	sys-apps/sed-4.9
+func ParseListInstalledOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		category, name, version := splitCPV(line)
+		packages = append(packages, manager.PackageInfo{
+			Name:           category + "/" + name,
+			Version:        version,
+			Category:       category,
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// splitCPV splits a "category/name-version" package string into its category, name and version.
+func splitCPV(cpv string) (string, string, string) {
+	match := cpvPattern.FindStringSubmatch(cpv)
+	if match == nil {
+		category, name, _ := strings.Cut(cpv, "/")
+		return category, name, ""
+	}
+	return match[1], match[2], match[3]
+}
@@ -0,0 +1,178 @@
+package portage_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/portage"
+)
+
+func TestParsePretendOutput(t *testing.T) {
+	var inputParsePretendOutput string = strings.Join([]string{
+		`These are the packages that would be merged, in order:`,
+		``,
+		`Calculating dependencies... done!`,
+		`[ebuild  N     ] app-misc/foo-1.0::gentoo  USE="-doc" 120 KiB`,
+		`[ebuild     U  ] app-editors/vim-9.0.1627::gentoo [9.0.1503::gentoo] USE="acl nls -X" 16,943 KiB`,
+		`[ebuild   R    ] sys-apps/sed-4.9::gentoo  USE="acl nls -static" 0 KiB`,
+		``,
+		`Total: 3 packages (1 upgrade, 1 new, 1 reinstall), Size of downloads: 17,063 KiB`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "app-misc/foo",
+			NewVersion:     "1.0",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "app-misc",
+			PackageManager: "portage",
+			Origin:         "gentoo",
+			AdditionalData: map[string]string{"use": "-doc"},
+		},
+		{
+			Name:           "app-editors/vim",
+			Version:        "9.0.1503",
+			NewVersion:     "9.0.1627",
+			Status:         manager.PackageStatusUpgradable,
+			Category:       "app-editors",
+			PackageManager: "portage",
+			Origin:         "gentoo",
+			AdditionalData: map[string]string{"use": "acl nls -X"},
+		},
+		{
+			Name:           "sys-apps/sed",
+			Version:        "4.9",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "sys-apps",
+			PackageManager: "portage",
+			Origin:         "gentoo",
+			AdditionalData: map[string]string{"use": "acl nls -static"},
+		},
+	}
+
+	actualPackageInfo := portage.ParsePretendOutput(inputParsePretendOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParsePretendOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseDepcleanOutput(t *testing.T) {
+	var inputParseDepcleanOutput string = strings.Join([]string{
+		`Calculating dependencies... done!`,
+		`>>> These are the packages that would be unmerged:`,
+		``,
+		` app-misc/foo`,
+		`    selected: 1.0 1.1`,
+		`   protected: none`,
+		`     omitted: none`,
+		``,
+		`All selected packages: =app-misc/foo-1.0 =app-misc/foo-1.1`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "app-misc/foo",
+			Version:        "1.0",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "app-misc",
+			PackageManager: "portage",
+		},
+		{
+			Name:           "app-misc/foo",
+			Version:        "1.1",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "app-misc",
+			PackageManager: "portage",
+		},
+	}
+
+	actualPackageInfo := portage.ParseDepcleanOutput(inputParseDepcleanOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseDepcleanOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseSearchOutput(t *testing.T) {
+	var inputParseSearchOutput string = strings.Join([]string{
+		`[ Results for search key : vim ]`,
+		`Searching...`,
+		``,
+		`*  app-editors/vim`,
+		`      Latest version available: 9.0.1627`,
+		`      Latest version installed: 9.0.1503`,
+		`      Size of files: 16,943 KiB`,
+		`      Homepage:      https://vim.org/`,
+		`      Description:   Vim, an improved vi-style text editor`,
+		`      License:       vim`,
+		``,
+		`*  app-vim/gentoo-syntax [ Not Installed ]`,
+		`      Latest version available: 20230525`,
+		`      Latest version installed: [ Not Installed ]`,
+		``,
+		`[ Applications found : 2 ]`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "app-editors/vim",
+			Version:        "9.0.1503",
+			NewVersion:     "9.0.1627",
+			Status:         manager.PackageStatusUpgradable,
+			Category:       "app-editors",
+			License:        "vim",
+			Homepage:       "https://vim.org/",
+			SizeDownload:   17349632,
+			PackageManager: "portage",
+			AdditionalData: map[string]string{
+				"description": "Vim, an improved vi-style text editor",
+			},
+		},
+		{
+			Name:           "app-vim/gentoo-syntax",
+			NewVersion:     "20230525",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "app-vim",
+			PackageManager: "portage",
+		},
+	}
+
+	actualPackageInfo := portage.ParseSearchOutput(inputParseSearchOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseSearchOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListInstalledOutput(t *testing.T) {
+	var inputParseListInstalledOutput string = strings.Join([]string{
+		`app-editors/vim-9.0.1503`,
+		`net-misc/openssh-9.3_p2-r1`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "app-editors/vim",
+			Version:        "9.0.1503",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "app-editors",
+			PackageManager: "portage",
+		},
+		{
+			Name:           "net-misc/openssh",
+			Version:        "9.3_p2-r1",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "net-misc",
+			PackageManager: "portage",
+		},
+	}
+
+	actualPackageInfo := portage.ParseListInstalledOutput(inputParseListInstalledOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListInstalledOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
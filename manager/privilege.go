@@ -0,0 +1,45 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// EscalationTools are the privilege escalation tools used to run commands as root, in order of preference.
+var EscalationTools []string = []string{"sudo", "doas", "pkexec"}
+
+// ErrNoEscalation is returned when root privileges are required, but no privilege escalation tool can be used.
+var ErrNoEscalation = errors.New("root privileges are required, but no privilege escalation tool (sudo, doas or pkexec) can be used")
+
+// IsPrivileged reports whether the process runs with root privileges.
+// It is always true on Windows, where package managers request elevation themselves.
+func IsPrivileged() bool {
+	return runtime.GOOS == "windows" || os.Geteuid() == 0
+}
+
+// EscalationCommand returns the command prefix running a command as root with the first usable escalation tool,
+// such as ["/usr/bin/sudo"]. When nonInteractive is set, the tool must not prompt for a password, as there is no
+// terminal to prompt on: sudo and doas are run with -n, and only used if they don't need a password, and pkexec is skipped.
+func EscalationCommand(nonInteractive bool) ([]string, error) {
+	for _, tool := range EscalationTools {
+		path, err := exec.LookPath(tool)
+		if err != nil {
+			continue
+		}
+		if !nonInteractive {
+			return []string{path}, nil
+		}
+		if tool == "pkexec" {
+			continue
+		}
+		// check that no password is needed, rather than failing in the middle of the operation
+		if err := exec.Command(path, "-n", "true").Run(); err != nil {
+			continue
+		}
+		return []string{path, "-n"}, nil
+	}
+	return nil, ErrNoEscalation
+}
@@ -0,0 +1,101 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// ProgressPhase is the phase of an operation reported in a ProgressEvent.
+type ProgressPhase string
+
+// Phases of the operations reported by package managers.
+const (
+	ProgressPhaseDownload ProgressPhase = "download"
+	ProgressPhaseInstall  ProgressPhase = "install"
+	ProgressPhaseRemove   ProgressPhase = "remove"
+	ProgressPhaseError    ProgressPhase = "error"
+)
+
+// ProgressEvent is a progress update of a long-running operation, such as an install or an upgrade.
+type ProgressEvent struct {
+	// PackageManager is the name of the package manager running the operation.
+	PackageManager string `json:"package_manager"`
+
+	// Phase is the current phase of the operation.
+	Phase ProgressPhase `json:"phase"`
+
+	// Package is the package being processed, if known.
+	Package string `json:"package,omitempty"`
+
+	// Percent is the overall progress of the phase, from 0 to 100.
+	Percent float64 `json:"percent"`
+
+	// BytesDone and BytesTotal are the number of bytes downloaded so far and to download, for package managers reporting them.
+	BytesDone  int64 `json:"bytes_done,omitempty"`
+	BytesTotal int64 `json:"bytes_total,omitempty"`
+
+	// Message is the native progress message of the package manager, such as "Unpacking vim (amd64)".
+	Message string `json:"message,omitempty"`
+}
+
+// ProgressReporter receives the progress events of package manager operations.
+// Set it in Options.Progress; package managers that can't report progress ignore it.
+// Report is called from the goroutine running the operation, and must not block for long.
+type ProgressReporter interface {
+	Report(event ProgressEvent)
+}
+
+// ProgressReporterFunc adapts a function to the ProgressReporter interface.
+type ProgressReporterFunc func(event ProgressEvent)
+
+// Report calls f(event).
+func (f ProgressReporterFunc) Report(event ProgressEvent) {
+	f(event)
+}
+
+// StreamOutput runs cmd like cmd.Output, but also calls onLine with each line of its standard output as it is written,
+// so that package managers can parse their native progress lines while the command runs.
+func StreamOutput(cmd *exec.Cmd, onLine func(line string)) ([]byte, error) {
+	defer Release(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	if cmd.Stderr == nil {
+		cmd.Stderr = &stderr
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	reader := bufio.NewReader(stdout)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if line != "" {
+			out.WriteString(line)
+			onLine(strings.TrimRight(line, "\r\n"))
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			_ = cmd.Wait()
+			return out.Bytes(), readErr
+		}
+	}
+
+	err = cmd.Wait()
+	// keep the stderr of failed commands, as cmd.Output does
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && cmd.Stderr == &stderr {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return out.Bytes(), err
+}
@@ -0,0 +1,35 @@
+package manager_test
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestStreamOutput(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	var lines []string
+	out, err := manager.StreamOutput(exec.Command("sh", "-c", "echo one; echo two; printf three"), func(line string) {
+		lines = append(lines, line)
+	})
+	if err != nil {
+		t.Fatalf("StreamOutput() error = %+v", err)
+	}
+	if want := "one\ntwo\nthree"; string(out) != want {
+		t.Errorf("StreamOutput() = %q, want %q", out, want)
+	}
+	if want := []string{"one", "two", "three"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("StreamOutput() lines = %+v, want %+v", lines, want)
+	}
+
+	_, err = manager.StreamOutput(exec.Command("sh", "-c", "echo failed >&2; exit 2"), func(string) {})
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || string(exitErr.Stderr) != "failed\n" {
+		t.Errorf("StreamOutput() error = %+v, want an exit error with the stderr of the command", err)
+	}
+}
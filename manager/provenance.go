@@ -0,0 +1,87 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// OfficialOrigins are the origins of the packages of the distributions, and of the default repositories of the
+// package managers, by package manager name, as globs matched regardless of case. The packages of the other origins
+// come from third-party sources, such as PPAs, vendor repositories, or other flatpak remotes.
+var OfficialOrigins = map[string][]string{
+	"apt":     {"Debian", "Debian Backports", "Ubuntu", "UbuntuESM", "UbuntuESMApps", "Canonical", "Devuan", "Raspbian", "Raspberry Pi Foundation", "linuxmint", "Kali", "elementary", "pop-os-release", "pop-os-apps"},
+	"brew":    {"homebrew/core", "homebrew/cask"},
+	"conda":   {"pkgs/main", "pkgs/r", "pkgs/msys2", "defaults", "conda-forge"},
+	"flatpak": {"flathub", "fedora"},
+	"pacman":  {"core", "core-testing", "extra", "extra-testing", "multilib", "multilib-testing", "community", "community-testing", "testing"},
+	"portage": {"gentoo"},
+	"snap":    {"canonical"},
+	"winget":  {"winget", "msstore"},
+	"zypper":  {"repo-*", "openSUSE*", "*:repo-*", "SLE*", "*:SLE-*"},
+}
+
+// IsThirdParty reports whether packages of the origin come from a third-party source for the package manager pm,
+// rather than from an origin of OfficialOrigins. It reports false for unknown origins, and for the package managers
+// without official origins, such as the ones of programming languages, whose packages all come from their registry.
+func IsThirdParty(pm string, origin string) bool {
+	official, ok := OfficialOrigins[pm]
+	if !ok || origin == "" {
+		return false
+	}
+	for _, pattern := range official {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(origin)); matched {
+			return false
+		}
+	}
+	return true
+}
+
+// SourceAudit is the audit of an enabled repository, for security reviews: whether its packages come from a
+// third-party source, or aren't verified, and which installed packages come from it.
+type SourceAudit struct {
+	Repository RepositoryInfo `json:"repository"`
+
+	// ThirdParty is set if the origin of the repository isn't one of OfficialOrigins.
+	ThirdParty bool `json:"third_party"`
+
+	// Packages are the names of the installed packages coming from the repository, sorted.
+	Packages []string `json:"packages"`
+}
+
+// Flagged reports whether the repository needs a review, being either a third-party source or unsigned.
+func (a SourceAudit) Flagged() bool {
+	return a.ThirdParty || a.Repository.Unsigned
+}
+
+// AuditSources audits the enabled repositories of repos, with origins the origins of the installed packages by name,
+// as set in PackageInfo.Origin. A package comes from a repository if its origin is the one of the repository, or the
+// name of the repository for the ones without origin; the repositories sharing an origin, such as the ones of a
+// distribution, all list its packages.
+func AuditSources(repos []RepositoryInfo, origins map[string]string) []SourceAudit {
+	var audits []SourceAudit
+	for _, repo := range repos {
+		if !repo.Enabled {
+			continue
+		}
+		origin := repo.Origin
+		if origin == "" {
+			origin = repo.Name
+		}
+
+		packages := []string{}
+		for name, packageOrigin := range origins {
+			if packageOrigin == origin {
+				packages = append(packages, name)
+			}
+		}
+		sort.Strings(packages)
+		audits = append(audits, SourceAudit{
+			Repository: repo,
+			ThirdParty: IsThirdParty(repo.PackageManager, repo.Origin),
+			Packages:   packages,
+		})
+	}
+	return audits
+}
@@ -0,0 +1,58 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestIsThirdParty(t *testing.T) {
+	tests := []struct {
+		pm, origin string
+		expected   bool
+	}{
+		{"apt", "Ubuntu", false},
+		{"apt", "debian", false},
+		{"apt", "LP-PPA-deadsnakes", true},
+		{"apt", "Docker", true},
+		{"apt", "", false},
+		{"flatpak", "flathub", false},
+		{"flatpak", "flathub-beta", true},
+		{"snap", "Canonical", false},
+		{"snap", "mozilla", true},
+		{"zypper", "repo-oss", false},
+		{"zypper", "openSUSE:repo-non-oss", false},
+		{"zypper", "packman", true},
+		{"pacman", "chaotic-aur", true},
+		{"pip", "pypi", false},
+	}
+	for _, tt := range tests {
+		if actual := manager.IsThirdParty(tt.pm, tt.origin); actual != tt.expected {
+			t.Errorf("IsThirdParty(%q, %q) = %v, want %v", tt.pm, tt.origin, actual, tt.expected)
+		}
+	}
+}
+
+func TestAuditSources(t *testing.T) {
+	repos := []manager.RepositoryInfo{
+		{Name: "http://archive.ubuntu.com/ubuntu jammy/main", Origin: "Ubuntu", Enabled: true, PackageManager: "apt"},
+		{Name: "https://ppa.launchpadcontent.net/deadsnakes/ppa/ubuntu jammy/main", Origin: "LP-PPA-deadsnakes", Enabled: true, PackageManager: "apt"},
+		{Name: "http://repo.example.com/ubuntu ./", Origin: "Ubuntu", Enabled: true, Unsigned: true, PackageManager: "apt"},
+		{Name: "https://download.docker.com/linux/ubuntu jammy/stable", Origin: "Docker", Enabled: false, PackageManager: "apt"},
+	}
+	origins := map[string]string{"vim": "Ubuntu", "python3.12-venv": "LP-PPA-deadsnakes", "python3.12": "LP-PPA-deadsnakes", "bash": "Ubuntu"}
+
+	expected := []manager.SourceAudit{
+		{Repository: repos[0], Packages: []string{"bash", "vim"}},
+		{Repository: repos[1], ThirdParty: true, Packages: []string{"python3.12", "python3.12-venv"}},
+		{Repository: repos[2], Packages: []string{"bash", "vim"}},
+	}
+	actual := manager.AuditSources(repos, origins)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("AuditSources() = %+v, want %+v", actual, expected)
+	}
+	if actual[0].Flagged() || !actual[1].Flagged() || !actual[2].Flagged() {
+		t.Errorf("Flagged() = %v, %v, %v, want false, true, true", actual[0].Flagged(), actual[1].Flagged(), actual[2].Flagged())
+	}
+}
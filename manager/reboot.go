@@ -0,0 +1,89 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RebootStatus tells whether the system needs a reboot to use the packages upgraded by a package manager,
+// such as the kernel or the C library.
+type RebootStatus struct {
+	// Required is set if a reboot is required.
+	Required bool `json:"required"`
+
+	// Reasons describe why a reboot is required.
+	Reasons []string `json:"reasons,omitempty"`
+
+	// Packages are the names of the packages requiring the reboot, if known.
+	Packages []string `json:"packages,omitempty"`
+}
+
+// Add records that a reboot is required for reason, triggered by packages, if known.
+func (s *RebootStatus) Add(reason string, packages ...string) {
+	s.Required = true
+	s.Reasons = append(s.Reasons, reason)
+	for _, pkg := range packages {
+		if pkg != "" && !slices.Contains(s.Packages, pkg) {
+			s.Packages = append(s.Packages, pkg)
+		}
+	}
+}
+
+// OSReleaseFile is the release of the running kernel.
+var OSReleaseFile string = "/proc/sys/kernel/osrelease"
+
+// ModulesDirs are the directories of the modules of the installed kernels, with a subdirectory per kernel release.
+var ModulesDirs []string = []string{"/lib/modules", "/usr/lib/modules"}
+
+// CheckKernel adds to status the reboot required when a kernel newer than the running one is installed, or when the
+// modules of the running kernel were removed, as some distributions do on upgrades. The packages installing the newest
+// kernel are found with owns, if not nil. Systems without kernel modules, such as containers, never require a reboot.
+func CheckKernel(status *RebootStatus, owns func(path string, opts *Options) ([]PackageInfo, error), opts *Options) error {
+	out, err := os.ReadFile(OSReleaseFile)
+	if err != nil {
+		return err
+	}
+	running := strings.TrimSpace(string(out))
+
+	var newest, newestDir string
+	runningInstalled := false
+	for _, dir := range ModulesDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			// the modules directories of removed kernels may remain, with the modules built by DKMS only
+			path := filepath.Join(dir, entry.Name())
+			if _, err := os.Stat(filepath.Join(path, "kernel")); err != nil {
+				continue
+			}
+			if entry.Name() == running {
+				runningInstalled = true
+			}
+			if newest == "" || CompareVersions(entry.Name(), newest) > 0 {
+				newest, newestDir = entry.Name(), path
+			}
+		}
+	}
+	if newest == "" || (runningInstalled && CompareVersions(newest, running) <= 0) {
+		return nil
+	}
+
+	var packages []string
+	if owns != nil {
+		owners, err := owns(newestDir, opts)
+		if err != nil {
+			opts.Log().Debug("Cannot find the package of the kernel", "path", newestDir, "error", err)
+		}
+		for _, owner := range owners {
+			packages = append(packages, owner.Name)
+		}
+	}
+	status.Add(fmt.Sprintf("kernel %s is installed, but %s is running", newest, running), packages...)
+	return nil
+}
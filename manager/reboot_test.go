@@ -0,0 +1,75 @@
+package manager_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestCheckKernel(t *testing.T) {
+	dir := t.TempDir()
+	osRelease := filepath.Join(dir, "osrelease")
+	modules := filepath.Join(dir, "modules")
+	defer func(osReleaseFile string, modulesDirs []string) {
+		manager.OSReleaseFile, manager.ModulesDirs = osReleaseFile, modulesDirs
+	}(manager.OSReleaseFile, manager.ModulesDirs)
+	manager.OSReleaseFile, manager.ModulesDirs = osRelease, []string{modules}
+
+	owns := func(path string, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return []manager.PackageInfo{{Name: "linux-image-" + filepath.Base(path)}}, nil
+	}
+	install := func(release string, modules bool) {
+		path := filepath.Join(dir, "modules", release)
+		if modules {
+			path = filepath.Join(path, "kernel")
+		}
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(osRelease, []byte("6.1.0-26-amd64\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		release string
+		modules bool
+		want    manager.RebootStatus
+	}{
+		{"no kernels", "", false, manager.RebootStatus{}},
+		{"running kernel", "6.1.0-26-amd64", true, manager.RebootStatus{}},
+		{"older kernel", "6.1.0-25-amd64", true, manager.RebootStatus{}},
+		{"removed kernel", "6.1.0-28-amd64", false, manager.RebootStatus{}},
+		{"newer kernel", "6.1.0-27-amd64", true, manager.RebootStatus{
+			Required: true,
+			Reasons:  []string{"kernel 6.1.0-27-amd64 is installed, but 6.1.0-26-amd64 is running"},
+			Packages: []string{"linux-image-6.1.0-27-amd64"},
+		}},
+	}
+	for _, tt := range tests {
+		if tt.release != "" {
+			install(tt.release, tt.modules)
+		}
+		var status manager.RebootStatus
+		if err := manager.CheckKernel(&status, owns, &manager.Options{}); err != nil || !reflect.DeepEqual(status, tt.want) {
+			t.Errorf("%s: CheckKernel() = %+v, %+v, want %+v", tt.name, status, err, tt.want)
+		}
+	}
+
+	// the modules of the running kernel were removed by its upgrade
+	if err := os.RemoveAll(filepath.Join(modules, "6.1.0-26-amd64")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(modules, "6.1.0-27-amd64")); err != nil {
+		t.Fatal(err)
+	}
+	install("6.1.0-26-amd64", false)
+	var status manager.RebootStatus
+	if err := manager.CheckKernel(&status, nil, &manager.Options{}); err != nil || !status.Required {
+		t.Errorf("CheckKernel() = %+v, %+v, want a reboot required", status, err)
+	}
+}
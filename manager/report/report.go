@@ -0,0 +1,283 @@
+// Package report summarizes the upgrades of several package managers in a consolidated report: the packages each
+// package manager upgraded, from which version to which, its failures, whether a reboot is needed, and how long it
+// took. Reports are written as text, as Markdown to paste into change tickets, or as JSON.
+//
+// This package is part of the syspkg library.
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Formats of the reports.
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+// Report is the report of an upgrade run.
+type Report struct {
+	// Host is the name of the host the upgrade ran on.
+	Host string `json:"host"`
+
+	// Started is when the upgrade started.
+	Started time.Time `json:"started"`
+
+	// Duration is how long the upgrade took, in seconds.
+	Duration float64 `json:"duration"`
+
+	// Entries are the results of the package managers, sorted by name.
+	Entries []*Entry `json:"package_managers"`
+}
+
+// Entry is the result of the upgrade for a package manager.
+type Entry struct {
+	// PackageManager is the name of the package manager.
+	PackageManager string `json:"package_manager"`
+
+	// Packages are the upgraded packages, with their old Version and their NewVersion when known.
+	Packages []manager.PackageInfo `json:"packages"`
+
+	// Error is the error of the package manager, if it failed.
+	Error string `json:"error,omitempty"`
+
+	// Unsupported is set when the package manager doesn't support the upgrade.
+	Unsupported bool `json:"unsupported,omitempty"`
+
+	// Reboot tells whether the system needs a reboot after the upgrade, if the package manager can tell.
+	Reboot *manager.RebootStatus `json:"reboot,omitempty"`
+
+	// Duration is how long the package manager took, in seconds.
+	Duration float64 `json:"duration"`
+}
+
+// New returns an empty report of an upgrade starting now on this host.
+func New() *Report {
+	host, _ := os.Hostname()
+	return &Report{Host: host, Started: time.Now()}
+}
+
+// Add records the result of package manager pm, which upgraded packages, or failed with err, in duration, and
+// returns its entry. It isn't safe to call concurrently.
+func (r *Report) Add(pm string, packages []manager.PackageInfo, err error, duration time.Duration) *Entry {
+	entry := &Entry{PackageManager: pm, Packages: packages, Duration: duration.Seconds()}
+	if entry.Packages == nil {
+		entry.Packages = []manager.PackageInfo{}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Unsupported = errors.Is(err, manager.ErrOperationNotSupported)
+	}
+	r.Entries = append(r.Entries, entry)
+	sort.SliceStable(r.Entries, func(i, j int) bool { return r.Entries[i].PackageManager < r.Entries[j].PackageManager })
+	return entry
+}
+
+// Finish records the duration of the upgrade, until now.
+func (r *Report) Finish() {
+	r.Duration = time.Since(r.Started).Seconds()
+}
+
+// Upgraded returns the number of upgraded packages.
+func (r *Report) Upgraded() int {
+	n := 0
+	for _, entry := range r.Entries {
+		n += len(entry.Packages)
+	}
+	return n
+}
+
+// Failed returns the names of the package managers that failed, other than the ones not supporting the upgrade.
+func (r *Report) Failed() []string {
+	var failed []string
+	for _, entry := range r.Entries {
+		if entry.Error != "" && !entry.Unsupported {
+			failed = append(failed, entry.PackageManager)
+		}
+	}
+	return failed
+}
+
+// RebootRequired reports whether a package manager requires a reboot.
+func (r *Report) RebootRequired() bool {
+	for _, entry := range r.Entries {
+		if entry.Reboot != nil && entry.Reboot.Required {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the report in format: FormatText, FormatMarkdown or FormatJSON.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText:
+		return r.WriteText(w)
+	case FormatMarkdown:
+		return r.WriteMarkdown(w)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+	return fmt.Errorf("unknown report format %q, expected %s, %s or %s", format, FormatText, FormatMarkdown, FormatJSON)
+}
+
+// WriteFile writes the report to the file at path, in format, or in the format of the extension of path if format
+// is empty: Markdown for .md, JSON for .json, text otherwise.
+func (r *Report) WriteFile(path string, format string) error {
+	if format == "" {
+		format = FormatOf(path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// FormatOf returns the format of a report file, from its extension: Markdown for .md, JSON for .json, text otherwise.
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return FormatMarkdown
+	case ".json":
+		return FormatJSON
+	}
+	return FormatText
+}
+
+// WriteText writes the report as text, such as
+//
+//	Upgrade report of web1, 2026-10-16 03:00 UTC, 1m4s: 2 packages upgraded, reboot required
+//	apt: 2 packages upgraded in 58.1s, reboot required: linux-image-6.1.0-26-amd64
+//	  linux-image-amd64 6.1.112-1 -> 6.1.115-1
+//	  openssl 3.0.14-1~deb12u2 -> 3.0.15-1~deb12u1
+//	snap: failed in 3.2s: exit status 1
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Upgrade report of %s, %s, %s: %s\n", r.Host, r.Started.Format("2006-01-02 15:04 MST"), formatDuration(r.Duration), r.Summary())
+	for _, entry := range r.Entries {
+		fmt.Fprintf(&b, "%s: %s\n", entry.PackageManager, entry.outcome())
+		for _, pkg := range entry.Packages {
+			fmt.Fprintf(&b, "  %s\n", strings.TrimSpace(pkg.Name+" "+versions(pkg)))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMarkdown writes the report as Markdown: a table of the outcomes of the package managers, then a table of the
+// packages upgraded by each of them.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Upgrade report of %s\n\n", cell(r.Host))
+	fmt.Fprintf(&b, "Started %s, took %s: %s.\n\n", r.Started.Format("2006-01-02 15:04 MST"), formatDuration(r.Duration), r.Summary())
+	b.WriteString("| Package manager | Upgraded | Result | Reboot | Duration |\n")
+	b.WriteString("|---|---:|---|---|---:|\n")
+	for _, entry := range r.Entries {
+		result := "ok"
+		switch {
+		case entry.Unsupported:
+			result = "not supported"
+		case entry.Error != "":
+			result = "failed: " + entry.Error
+		}
+		reboot := "-"
+		if entry.Reboot != nil {
+			reboot = "no"
+			if entry.Reboot.Required {
+				reboot = "required"
+			}
+		}
+		fmt.Fprintf(&b, "| %s | %d | %s | %s | %s |\n", cell(entry.PackageManager), len(entry.Packages), cell(result), reboot, formatDuration(entry.Duration))
+	}
+
+	for _, entry := range r.Entries {
+		if len(entry.Packages) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", cell(entry.PackageManager))
+		b.WriteString("| Package | Old version | New version |\n")
+		b.WriteString("|---|---|---|\n")
+		for _, pkg := range entry.Packages {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", cell(pkg.Name), cell(pkg.Version), cell(pkg.NewVersion))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Summary returns the outcome of the whole upgrade, such as "3 packages upgraded, failed for snap, reboot required".
+func (r *Report) Summary() string {
+	parts := []string{plural(r.Upgraded(), "package") + " upgraded"}
+	if failed := r.Failed(); len(failed) > 0 {
+		parts = append(parts, "failed for "+strings.Join(failed, ", "))
+	}
+	if r.RebootRequired() {
+		parts = append(parts, "reboot required")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// outcome returns the outcome of the upgrade for the package manager, such as "2 packages upgraded in 58.1s".
+func (e *Entry) outcome() string {
+	switch {
+	case e.Unsupported:
+		return "not supported"
+	case e.Error != "":
+		return fmt.Sprintf("failed in %s: %s", formatDuration(e.Duration), e.Error)
+	}
+	outcome := fmt.Sprintf("%s upgraded in %s", plural(len(e.Packages), "package"), formatDuration(e.Duration))
+	if e.Reboot != nil && e.Reboot.Required {
+		outcome += ", reboot required"
+		if len(e.Reboot.Packages) > 0 {
+			outcome += ": " + strings.Join(e.Reboot.Packages, ", ")
+		}
+	}
+	return outcome
+}
+
+// versions returns the old and new versions of an upgraded package, such as "1.0 -> 1.1".
+func versions(pkg manager.PackageInfo) string {
+	switch {
+	case pkg.Version == "":
+		return pkg.NewVersion
+	case pkg.NewVersion == "" || pkg.NewVersion == pkg.Version:
+		return pkg.Version
+	}
+	return pkg.Version + " -> " + pkg.NewVersion
+}
+
+// plural returns n followed by noun, in the plural unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// formatDuration formats a duration in seconds, rounded to the tenth of a second.
+func formatDuration(seconds float64) string {
+	return (time.Duration(seconds*float64(time.Second)) / (100 * time.Millisecond) * (100 * time.Millisecond)).String()
+}
+
+// cell escapes the pipes and line breaks of the text of a Markdown table cell.
+func cell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r", "", "\n", "<br>").Replace(s)
+}
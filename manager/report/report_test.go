@@ -0,0 +1,97 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/report"
+)
+
+// newReport returns the report of an upgrade by apt requiring a reboot, and a failed upgrade by snap.
+func newReport() *report.Report {
+	r := &report.Report{Host: "web1", Started: time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC), Duration: 64.2}
+	apt := r.Add("apt", []manager.PackageInfo{
+		{Name: "linux-image-amd64", Version: "6.1.112-1", NewVersion: "6.1.115-1", PackageManager: "apt"},
+		{Name: "openssl", Version: "3.0.14-1", NewVersion: "3.0.15-1", PackageManager: "apt"},
+	}, nil, 58100*time.Millisecond)
+	apt.Reboot = &manager.RebootStatus{Required: true, Packages: []string{"linux-image-6.1.0-26-amd64"}}
+	r.Add("snap", nil, errors.New("exit status 1 | see log"), 3200*time.Millisecond)
+	r.Add("pip", nil, manager.ErrOperationNotSupported, 0)
+	return r
+}
+
+func TestWriteText(t *testing.T) {
+	var out bytes.Buffer
+	if err := newReport().Write(&out, report.FormatText); err != nil {
+		t.Fatalf("Write() error = %+v", err)
+	}
+
+	expected := strings.Join([]string{
+		"Upgrade report of web1, 2026-10-16 03:00 UTC, 1m4.2s: 2 packages upgraded, failed for snap, reboot required",
+		"apt: 2 packages upgraded in 58.1s, reboot required: linux-image-6.1.0-26-amd64",
+		"  linux-image-amd64 6.1.112-1 -> 6.1.115-1",
+		"  openssl 3.0.14-1 -> 3.0.15-1",
+		"pip: not supported",
+		"snap: failed in 3.2s: exit status 1 | see log",
+		"",
+	}, "\n")
+	if out.String() != expected {
+		t.Errorf("Write() = %q, want %q", out.String(), expected)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var out bytes.Buffer
+	if err := newReport().Write(&out, report.FormatMarkdown); err != nil {
+		t.Fatalf("Write() error = %+v", err)
+	}
+
+	for _, expectedLine := range []string{
+		"| apt | 2 | ok | required | 58.1s |",
+		`| snap | 0 | failed: exit status 1 \| see log | - | 3.2s |`,
+		"### apt",
+		"| openssl | 3.0.14-1 | 3.0.15-1 |",
+	} {
+		if !strings.Contains(out.String(), "\n"+expectedLine+"\n") {
+			t.Errorf("Write() = %q, want a line %q", out.String(), expectedLine)
+		}
+	}
+	if strings.Contains(out.String(), "### snap") {
+		t.Errorf("Write() = %q, want no table of packages for snap", out.String())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	r := newReport()
+	if err := r.Write(&out, report.FormatJSON); err != nil {
+		t.Fatalf("Write() error = %+v", err)
+	}
+
+	var actual report.Report
+	if err := json.Unmarshal(out.Bytes(), &actual); err != nil {
+		t.Fatalf("Unmarshal() error = %+v", err)
+	}
+	if !reflect.DeepEqual(&actual, r) {
+		t.Errorf("Write() = %+v, want %+v", &actual, r)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for path, expected := range map[string]string{
+		"report.md":   report.FormatMarkdown,
+		"report.JSON": report.FormatJSON,
+		"report.txt":  report.FormatText,
+		"report":      report.FormatText,
+	} {
+		if actual := report.FormatOf(path); actual != expected {
+			t.Errorf("FormatOf(%q) = %q, want %q", path, actual, expected)
+		}
+	}
+}
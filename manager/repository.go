@@ -0,0 +1,43 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+// Scope is the installation a package manager operates on, for package managers having both per-user
+// and system-wide installations.
+type Scope string
+
+// Scope constants define the installations of package managers.
+const (
+	// ScopeUser is the installation of the current user, such as ~/.local/share/flatpak.
+	ScopeUser Scope = "user"
+
+	// ScopeSystem is the system-wide installation, shared by all users.
+	ScopeSystem Scope = "system"
+)
+
+// RepositoryInfo contains information about a repository packages are installed from, such as an apt source or a flatpak remote.
+type RepositoryInfo struct {
+	// Name identifies the repository for the package manager, used to remove it, such as "flathub".
+	Name string `json:"name"`
+
+	// URL is the location of the repository.
+	URL string `json:"url,omitempty"`
+
+	// Title is the human-readable name of the repository, if any.
+	Title string `json:"title,omitempty"`
+
+	// Origin is the origin of the packages of the repository, as in PackageInfo.Origin, if known.
+	Origin string `json:"origin,omitempty"`
+
+	// Enabled is set if packages are installed from the repository.
+	Enabled bool `json:"enabled"`
+
+	// Unsigned is set if the packages of the repository aren't verified with a signature, such as the apt sources
+	// marked trusted=yes, the zypper repositories without gpgcheck, or the flatpak remotes with no-gpg-verify.
+	Unsigned bool `json:"unsigned,omitempty"`
+
+	// Scope is the installation the repository belongs to, for package managers having several.
+	Scope Scope `json:"scope,omitempty"`
+
+	// PackageManager is the name of the package manager using this repository, such as "flatpak".
+	PackageManager string `json:"package_manager"`
+}
@@ -0,0 +1,186 @@
+// Package restarts finds the processes still running the old versions of upgraded executables and libraries, and the
+// systemd services to restart so that they use the new ones, like needs-restarting and checkrestart.
+//
+// Package managers upgrade files by replacing them, so processes having mapped the old files keep using them, and
+// their memory maps list them as deleted. Reading the memory maps of processes of other users requires root privileges:
+// without them, only the processes of the current user are checked.
+//
+// This package is part of the syspkg library.
+package restarts
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ProcDir is the procfs mount point, where the processes are read from.
+var ProcDir string = "/proc"
+
+// LibraryDirs are the directories of the executables and libraries installed by package managers. Deleted files
+// elsewhere, such as temporary files or shared memory, don't mean that a process needs to be restarted.
+var LibraryDirs []string = []string{"/usr/", "/lib/", "/lib64/", "/lib32/", "/bin/", "/sbin/", "/opt/"}
+
+// NoRestart are the prefixes of the services that can't be restarted safely, as restarting them ends the user sessions.
+var NoRestart []string = []string{"dbus.service", "dbus-broker.service", "systemd-logind.service", "display-manager.service",
+	"gdm.service", "sddm.service", "lightdm.service", "getty@", "serial-getty@", "user@"}
+
+// Process is a process using deleted files of upgraded packages.
+type Process struct {
+	// PID is the process ID.
+	PID int `json:"pid"`
+
+	// Command is the name of the command of the process.
+	Command string `json:"command"`
+
+	// Service is the systemd service the process belongs to, if any, such as "ssh.service".
+	// Processes of user sessions have no service, and need to be restarted by their users.
+	Service string `json:"service,omitempty"`
+
+	// Files are the deleted executables and libraries the process uses.
+	Files []string `json:"files"`
+}
+
+// Scan returns the processes using deleted executables or libraries, sorted by PID.
+// The processes whose memory maps can't be read, e.g. without root privileges or when they exit meanwhile, are skipped.
+func Scan(opts *manager.Options) ([]Process, error) {
+	entries, err := os.ReadDir(ProcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join(ProcDir, entry.Name())
+
+		maps, err := os.ReadFile(filepath.Join(dir, "maps"))
+		if err != nil {
+			opts.Log().Debug("Cannot read the memory maps of a process", "pid", pid, "error", err)
+			continue
+		}
+		files := ParseMaps(string(maps))
+		if len(files) == 0 {
+			continue
+		}
+
+		process := Process{PID: pid, Files: files}
+		if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+			process.Command = strings.TrimSpace(string(comm))
+		}
+		if cgroup, err := os.ReadFile(filepath.Join(dir, "cgroup")); err == nil {
+			process.Service = ParseCgroup(string(cgroup))
+		}
+		processes = append(processes, process)
+	}
+
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+	return processes, nil
+}
+
+// ParseMaps parses the content of /proc/<pid>/maps, and returns the deleted files of LibraryDirs mapped by the process,
+// sorted and without duplicates.
+func ParseMaps(msg string) []string {
+	seen := make(map[string]bool)
+	var files []string
+
+	scanner := bufio.NewScanner(strings.NewReader(msg))
+	for scanner.Scan() {
+		// address perms offset dev inode path, where path may contain spaces and ends with " (deleted)"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 7 || fields[len(fields)-1] != "(deleted)" {
+			continue
+		}
+		path := strings.Join(fields[5:len(fields)-1], " ")
+		if seen[path] || !isLibrary(path) {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+
+	sort.Strings(files)
+	return files
+}
+
+// ParseCgroup parses the content of /proc/<pid>/cgroup, and returns the systemd service of the process,
+// or "" if the process doesn't belong to a system service.
+func ParseCgroup(msg string) string {
+	scanner := bufio.NewScanner(strings.NewReader(msg))
+	for scanner.Scan() {
+		// hierarchy-ID:controllers:path, the unified hierarchy and the systemd one of cgroup v1 name the units
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 || (parts[0] != "0" && parts[1] != "name=systemd") {
+			continue
+		}
+
+		service := ""
+		for _, unit := range strings.Split(parts[2], "/") {
+			// the units of user sessions run in the service manager of the user
+			if strings.HasPrefix(unit, "user@") {
+				return ""
+			}
+			if strings.HasSuffix(unit, ".service") {
+				service = unit
+			}
+		}
+		if service != "" {
+			return service
+		}
+	}
+	return ""
+}
+
+// Services returns the systemd services of the processes, sorted and without duplicates.
+func Services(processes []Process) []string {
+	seen := make(map[string]bool)
+	var services []string
+	for _, p := range processes {
+		if p.Service != "" && !seen[p.Service] {
+			seen[p.Service] = true
+			services = append(services, p.Service)
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+// Restartable reports whether service can be restarted safely, i.e. it doesn't match any of NoRestart.
+func Restartable(service string) bool {
+	for _, prefix := range NoRestart {
+		if strings.HasPrefix(service, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Restart restarts the systemd services with systemctl restart. In dry-run mode, nothing is restarted.
+func Restart(services []string, opts *manager.Options) error {
+	if len(services) == 0 || (opts != nil && opts.DryRun) {
+		return nil
+	}
+	cmd := manager.Command(opts, "systemctl", append([]string{"restart"}, services...)...)
+	if opts != nil && opts.Verbose {
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	}
+	return manager.Run(cmd)
+}
+
+// isLibrary reports whether path is in one of LibraryDirs.
+func isLibrary(path string) bool {
+	for _, dir := range LibraryDirs {
+		if strings.HasPrefix(path, dir) {
+			return true
+		}
+	}
+	return false
+}
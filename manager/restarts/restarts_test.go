@@ -0,0 +1,86 @@
+package restarts_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/restarts"
+)
+
+var inputMaps string = strings.Join([]string{
+	`55d0c8a4e000-55d0c8a56000 r--p 00000000 fe:01 1311015                    /usr/sbin/sshd (deleted)`,
+	`7f6c0a0f3000-7f6c0a119000 r--p 00000000 fe:01 1316153                    /usr/lib/x86_64-linux-gnu/libc.so.6 (deleted)`,
+	`7f6c0a119000-7f6c0a26e000 r-xp 00026000 fe:01 1316153                    /usr/lib/x86_64-linux-gnu/libc.so.6 (deleted)`,
+	`7f6c0a2c5000-7f6c0a2c7000 rw-p 00000000 fe:01 1316176                    /usr/lib/x86_64-linux-gnu/libz.so.1.2.13`,
+	`7f6c0a2d0000-7f6c0a2d8000 rw-s 00000000 00:01 2048                       /dev/shm/pulse-shm-42 (deleted)`,
+	`7f6c0a2e0000-7f6c0a2e8000 rw-s 00000000 00:01 2049                       /memfd:wayland-cursor (deleted)`,
+	`7ffc4e5b1000-7ffc4e5d2000 rw-p 00000000 00:00 0                          [stack]`,
+}, "\n")
+
+func TestParseMaps(t *testing.T) {
+	expectedFiles := []string{"/usr/lib/x86_64-linux-gnu/libc.so.6", "/usr/sbin/sshd"}
+	actualFiles := restarts.ParseMaps(inputMaps)
+	if !reflect.DeepEqual(expectedFiles, actualFiles) {
+		t.Errorf("ParseMaps() = %+v, want %+v", actualFiles, expectedFiles)
+	}
+}
+
+func TestParseCgroup(t *testing.T) {
+	tests := []struct {
+		cgroup string
+		want   string
+	}{
+		{"0::/system.slice/ssh.service\n", "ssh.service"},
+		{"12:pids:/system.slice\n1:name=systemd:/system.slice/cron.service\n", "cron.service"},
+		{"0::/user.slice/user-1000.slice/user@1000.service/app.slice/pipewire.service\n", ""},
+		{"0::/user.slice/user-1000.slice/session-3.scope\n", ""},
+		{"0::/init.scope\n", ""},
+	}
+	for _, tt := range tests {
+		if got := restarts.ParseCgroup(tt.cgroup); got != tt.want {
+			t.Errorf("ParseCgroup(%q) = %q, want %q", tt.cgroup, got, tt.want)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	defer func(procDir string) { restarts.ProcDir = procDir }(restarts.ProcDir)
+	restarts.ProcDir = dir
+
+	for pid, files := range map[string]map[string]string{
+		"812":  {"maps": inputMaps, "comm": "sshd\n", "cgroup": "0::/system.slice/ssh.service\n"},
+		"1042": {"maps": "7ffc4e5b1000-7ffc4e5d2000 rw-p 00000000 00:00 0 [stack]\n", "comm": "bash\n", "cgroup": "0::/init.scope\n"},
+		"self": {"maps": inputMaps},
+	} {
+		if err := os.Mkdir(filepath.Join(dir, pid), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, pid, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	expectedProcesses := []restarts.Process{{
+		PID:     812,
+		Command: "sshd",
+		Service: "ssh.service",
+		Files:   []string{"/usr/lib/x86_64-linux-gnu/libc.so.6", "/usr/sbin/sshd"},
+	}}
+	actualProcesses, err := restarts.Scan(&manager.Options{})
+	if err != nil || !reflect.DeepEqual(expectedProcesses, actualProcesses) {
+		t.Errorf("Scan() = %+v, %+v, want %+v", actualProcesses, err, expectedProcesses)
+	}
+	if services := restarts.Services(actualProcesses); !reflect.DeepEqual(services, []string{"ssh.service"}) {
+		t.Errorf("Services() = %+v, want [ssh.service]", services)
+	}
+	if restarts.Restartable("dbus.service") || !restarts.Restartable("ssh.service") {
+		t.Errorf("Restartable() is wrong for dbus.service or ssh.service")
+	}
+}
@@ -0,0 +1,320 @@
+// Package sbom exports the packages installed by each package manager as a Software Bill of Materials (SBOM),
+// in the CycloneDX 1.5 or SPDX 2.3 JSON format, for compliance and vulnerability tooling.
+//
+// Packages are identified by their package URL (purl, https://github.com/package-url/purl-spec), derived from the
+// package manager they were installed with. Their license, homepage and origin are exported when the package manager
+// reports them.
+//
+// This package is part of the syspkg library.
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ErrUnknownFormat is returned for SBOM formats other than CycloneDX and SPDX.
+var ErrUnknownFormat = errors.New("sbom: unknown format, expected cyclonedx or spdx")
+
+// Format is an SBOM document format.
+type Format string
+
+// Supported SBOM formats.
+const (
+	FormatCycloneDX Format = "cyclonedx"
+	FormatSPDX      Format = "spdx"
+)
+
+// ParseFormat parses the name of an SBOM format.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatCycloneDX, FormatSPDX:
+		return Format(s), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+	}
+}
+
+// purlTypes maps package manager names to package URL types. Package managers not listed use the "generic" type.
+var purlTypes = map[string]string{
+	"apk":     "apk",
+	"apt":     "deb",
+	"brew":    "brew",
+	"cargo":   "cargo",
+	"conda":   "conda",
+	"flatpak": "flatpak",
+	"gem":     "gem",
+	"gobin":   "golang",
+	"nix":     "nix",
+	"npm":     "npm",
+	"pacman":  "alpm",
+	"pip":     "pypi",
+	"portage": "ebuild",
+	"snap":    "snap",
+	"winget":  "winget",
+	"zypper":  "rpm",
+}
+
+// PackageURL returns the package URL of a package installed with the named package manager,
+// such as "pkg:deb/vim@2:8.2.3995-1ubuntu2.15?arch=amd64". The distribution is not detected, so no namespace is set.
+func PackageURL(pm string, pkg manager.PackageInfo) string {
+	purlType, ok := purlTypes[pm]
+	if !ok {
+		purlType = "generic"
+	}
+
+	// names such as Go module paths or scoped npm packages contain a namespace, whose slashes are kept;
+	// "@" separates the version, so it is escaped in names
+	segments := strings.Split(pkg.Name, "/")
+	for i := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segments[i]), "@", "%40")
+	}
+
+	purl := "pkg:" + purlType + "/" + strings.Join(segments, "/")
+	if pkg.Version != "" {
+		purl += "@" + url.PathEscape(pkg.Version)
+	}
+	if pkg.Arch != "" {
+		purl += "?arch=" + url.QueryEscape(pkg.Arch)
+	}
+	return purl
+}
+
+// Generate returns the SBOM of the installed packages, by package manager name, in the given format.
+// Components are sorted by package manager, then by name, so that the documents of identical systems only differ
+// by their creation time and serial number.
+func Generate(format Format, packages map[string][]manager.PackageInfo, created time.Time) ([]byte, error) {
+	var doc interface{}
+	switch format {
+	case FormatCycloneDX:
+		doc = newCycloneDX(sortedComponents(packages), created)
+	case FormatSPDX:
+		doc = newSPDX(sortedComponents(packages), created)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// component is an installed package along with the package manager it was installed with.
+type component struct {
+	pm  string
+	pkg manager.PackageInfo
+}
+
+// sortedComponents flattens the packages of all package managers, sorted by package manager then by name.
+func sortedComponents(packages map[string][]manager.PackageInfo) []component {
+	var components []component
+	for pm, pkgs := range packages {
+		for _, pkg := range pkgs {
+			components = append(components, component{pm, pkg})
+		}
+	}
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].pm != components[j].pm {
+			return components[i].pm < components[j].pm
+		}
+		if components[i].pkg.Name != components[j].pkg.Name {
+			return components[i].pkg.Name < components[j].pkg.Name
+		}
+		return components[i].pkg.Arch < components[j].pkg.Arch
+	})
+	return components
+}
+
+// newUUID returns a random (version 4) UUID, used to identify a document.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// CycloneDX is a CycloneDX 1.5 JSON document, limited to the fields syspkg fills.
+type CycloneDX struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     CycloneDXMetadata    `json:"metadata"`
+	Components   []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXMetadata describes when and how a CycloneDX document was created.
+type CycloneDXMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []CycloneDXComponent `json:"components"`
+	} `json:"tools"`
+}
+
+// CycloneDXComponent is a software component of a CycloneDX document.
+type CycloneDXComponent struct {
+	Type       string              `json:"type"`
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Licenses   []CycloneDXLicense  `json:"licenses,omitempty"`
+	Properties []CycloneDXProperty `json:"properties,omitempty"`
+}
+
+// CycloneDXLicense is the license of a CycloneDX component, given by name as reported by the package manager.
+type CycloneDXLicense struct {
+	License struct {
+		Name string `json:"name"`
+	} `json:"license"`
+}
+
+// CycloneDXProperty is a name-value property of a CycloneDX component.
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// newCycloneDX builds the CycloneDX document of the components.
+func newCycloneDX(components []component, created time.Time) CycloneDX {
+	doc := CycloneDX{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Components:   []CycloneDXComponent{},
+	}
+	doc.Metadata.Timestamp = created.UTC().Format(time.RFC3339)
+	doc.Metadata.Tools.Components = []CycloneDXComponent{{Type: "application", Name: "syspkg"}}
+
+	for _, c := range components {
+		purl := PackageURL(c.pm, c.pkg)
+		comp := CycloneDXComponent{
+			Type:    "library",
+			BOMRef:  purl,
+			Name:    c.pkg.Name,
+			Version: c.pkg.Version,
+			PURL:    purl,
+		}
+		if c.pkg.License != "" {
+			var l CycloneDXLicense
+			l.License.Name = c.pkg.License
+			comp.Licenses = []CycloneDXLicense{l}
+		}
+
+		comp.Properties = append(comp.Properties, CycloneDXProperty{Name: "syspkg:package_manager", Value: c.pm})
+		if c.pkg.Arch != "" {
+			comp.Properties = append(comp.Properties, CycloneDXProperty{Name: "syspkg:arch", Value: c.pkg.Arch})
+		}
+		if c.pkg.Category != "" {
+			comp.Properties = append(comp.Properties, CycloneDXProperty{Name: "syspkg:origin", Value: c.pkg.Category})
+		}
+		doc.Components = append(doc.Components, comp)
+	}
+	return doc
+}
+
+// SPDX is an SPDX 2.3 JSON document, limited to the fields syspkg fills.
+type SPDX struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+// SPDXCreationInfo describes when and how an SPDX document was created.
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// SPDXPackage is a package of an SPDX document.
+type SPDXPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	Homepage         string            `json:"homepage,omitempty"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	LicenseComments  string            `json:"licenseComments,omitempty"`
+	SourceInfo       string            `json:"sourceInfo,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+// SPDXExternalRef is a reference from an SPDX package to an external identifier, such as its package URL.
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// SPDXRelationship is a relationship between two elements of an SPDX document.
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxInvalidChars matches the characters not allowed in SPDX identifiers.
+var spdxInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// newSPDX builds the SPDX document of the components. Licenses reported by package managers are not necessarily valid
+// SPDX license expressions, so they are exported as comments, and the declared license is left as NOASSERTION.
+func newSPDX(components []component, created time.Time) SPDX {
+	doc := SPDX{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "syspkg-installed-packages",
+		DocumentNamespace: "https://spdx.org/spdxdocs/syspkg-" + newUUID(),
+		CreationInfo: SPDXCreationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: syspkg"},
+		},
+		Packages:      []SPDXPackage{},
+		Relationships: []SPDXRelationship{},
+	}
+
+	for i, c := range components {
+		id := fmt.Sprintf("SPDXRef-Package-%s-%s-%d", c.pm, spdxInvalidChars.ReplaceAllString(c.pkg.Name, "-"), i+1)
+		pkg := SPDXPackage{
+			Name:             c.pkg.Name,
+			SPDXID:           id,
+			VersionInfo:      c.pkg.Version,
+			DownloadLocation: "NOASSERTION",
+			Homepage:         c.pkg.Homepage,
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			ExternalRefs: []SPDXExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: PackageURL(c.pm, c.pkg)},
+			},
+		}
+		if c.pkg.License != "" {
+			pkg.LicenseComments = "License declared by " + c.pm + ": " + c.pkg.License
+		}
+		if c.pkg.Category != "" {
+			pkg.SourceInfo = "installed by " + c.pm + " from " + c.pkg.Category
+		}
+
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, SPDXRelationship{
+			SPDXElementID:      doc.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: id,
+		})
+	}
+	return doc
+}
@@ -0,0 +1,127 @@
+package sbom_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/sbom"
+)
+
+var packages = map[string][]manager.PackageInfo{
+	"npm": {
+		{Name: "@types/node", Version: "20.11.5"},
+	},
+	"apt": {
+		{Name: "vim", Version: "2:8.2.3995-1ubuntu2.15", Arch: "amd64", Category: "jammy-updates", License: "Vim", Homepage: "https://www.vim.org/"},
+		{Name: "curl", Version: "7.81.0-1ubuntu1.15", Arch: "amd64"},
+	},
+}
+
+var created = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestPackageURL(t *testing.T) {
+	tests := []struct {
+		pm   string
+		pkg  manager.PackageInfo
+		want string
+	}{
+		{"apt", manager.PackageInfo{Name: "vim", Version: "2:8.2.3995-1ubuntu2.15", Arch: "amd64"}, "pkg:deb/vim@2:8.2.3995-1ubuntu2.15?arch=amd64"},
+		{"pip", manager.PackageInfo{Name: "requests", Version: "2.31.0"}, "pkg:pypi/requests@2.31.0"},
+		{"npm", manager.PackageInfo{Name: "@types/node", Version: "20.11.5"}, "pkg:npm/%40types/node@20.11.5"},
+		{"gobin", manager.PackageInfo{Name: "golang.org/x/tools/cmd/goimports", Version: "v0.17.0"}, "pkg:golang/golang.org/x/tools/cmd/goimports@v0.17.0"},
+		{"fwupd", manager.PackageInfo{Name: "System Firmware"}, "pkg:generic/System%20Firmware"},
+	}
+
+	for _, tt := range tests {
+		if got := sbom.PackageURL(tt.pm, tt.pkg); got != tt.want {
+			t.Errorf("PackageURL(%q, %+v) = %q, want %q", tt.pm, tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateCycloneDX(t *testing.T) {
+	out, err := sbom.Generate(sbom.FormatCycloneDX, packages, created)
+	if err != nil {
+		t.Fatalf("Generate() error = %+v", err)
+	}
+
+	var doc sbom.CycloneDX
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Generate() returned invalid JSON: %+v", err)
+	}
+
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || doc.Metadata.Timestamp != "2024-03-01T12:00:00Z" {
+		t.Errorf("Generate() = %+v, want a CycloneDX 1.5 document created at %s", doc, created)
+	}
+
+	var names []string
+	for _, c := range doc.Components {
+		names = append(names, c.Name)
+	}
+	if want := []string{"curl", "vim", "@types/node"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Generate() components = %+v, want %+v", names, want)
+	}
+
+	vim := doc.Components[1]
+	if vim.PURL != "pkg:deb/vim@2:8.2.3995-1ubuntu2.15?arch=amd64" || len(vim.Licenses) != 1 || vim.Licenses[0].License.Name != "Vim" {
+		t.Errorf("Generate() component = %+v, want the purl and license of vim", vim)
+	}
+	wantProperties := []sbom.CycloneDXProperty{
+		{Name: "syspkg:package_manager", Value: "apt"},
+		{Name: "syspkg:arch", Value: "amd64"},
+		{Name: "syspkg:origin", Value: "jammy-updates"},
+	}
+	if !reflect.DeepEqual(vim.Properties, wantProperties) {
+		t.Errorf("Generate() properties = %+v, want %+v", vim.Properties, wantProperties)
+	}
+}
+
+func TestGenerateSPDX(t *testing.T) {
+	out, err := sbom.Generate(sbom.FormatSPDX, packages, created)
+	if err != nil {
+		t.Fatalf("Generate() error = %+v", err)
+	}
+
+	var doc sbom.SPDX
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Generate() returned invalid JSON: %+v", err)
+	}
+
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2024-03-01T12:00:00Z" {
+		t.Errorf("Generate() = %+v, want an SPDX 2.3 document created at %s", doc, created)
+	}
+	if len(doc.Packages) != 3 || len(doc.Relationships) != 3 {
+		t.Fatalf("Generate() = %d packages and %d relationships, want 3 of each", len(doc.Packages), len(doc.Relationships))
+	}
+
+	want := sbom.SPDXPackage{
+		Name:             "@types/node",
+		SPDXID:           "SPDXRef-Package-npm--types-node-3",
+		VersionInfo:      "20.11.5",
+		DownloadLocation: "NOASSERTION",
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  "NOASSERTION",
+		ExternalRefs: []sbom.SPDXExternalRef{
+			{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:npm/%40types/node@20.11.5"},
+		},
+	}
+	if !reflect.DeepEqual(doc.Packages[2], want) {
+		t.Errorf("Generate() package = %+v, want %+v", doc.Packages[2], want)
+	}
+	if doc.Relationships[2].RelatedSPDXElement != want.SPDXID {
+		t.Errorf("Generate() relationship = %+v, want the document to describe %s", doc.Relationships[2], want.SPDXID)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := sbom.ParseFormat("spdx"); err != nil || format != sbom.FormatSPDX {
+		t.Errorf("ParseFormat() = %q, %+v, want %q", format, err, sbom.FormatSPDX)
+	}
+	if _, err := sbom.ParseFormat("swid"); !errors.Is(err, sbom.ErrUnknownFormat) {
+		t.Errorf("ParseFormat() error = %+v, want %+v", err, sbom.ErrUnknownFormat)
+	}
+}
@@ -0,0 +1,285 @@
+// Package schedule installs the scheduled runs of unattended upgrades, as a systemd timer or as a cron entry, so that
+// syspkg upgrades the packages of all package managers on schedule, like unattended-upgrades does for APT.
+//
+// With systemd, a oneshot service running the upgrade command and a timer starting it are installed in
+// /etc/systemd/system, and the output of the runs is kept in the journal (journalctl -u syspkg-upgrade). Otherwise,
+// an entry of /etc/cron.d runs the command, appending its output to LogFile. Installing and removing the schedule
+// requires root privileges.
+//
+// This package is part of the syspkg library.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Name is the name of the systemd units and of the cron entry.
+const Name = "syspkg-upgrade"
+
+// Backends running the scheduled upgrades.
+const (
+	BackendSystemd = "systemd"
+	BackendCron    = "cron"
+)
+
+// LogFile is the file the output of the upgrades run by cron is appended to.
+var LogFile string = "/var/log/syspkg/upgrade.log"
+
+// ErrNotInstalled is returned when no scheduled upgrade is installed.
+var ErrNotInstalled = errors.New("schedule: no scheduled upgrade is installed")
+
+// Time is when the upgrades run: at a time of day, on some days of the week.
+type Time struct {
+	// Days are the days of the week the upgrades run on; every day if empty.
+	Days []time.Weekday
+
+	// At is the time of day the upgrades run at, as a duration since midnight.
+	At time.Duration
+}
+
+// ParseTime parses when the upgrades run: optional days, as accepted by manager.ParseWeekdays, followed by a time of
+// day, such as "03:00", "daily 03:00" or "Sat,Sun 04:30".
+func ParseTime(s string) (Time, error) {
+	var t Time
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return t, fmt.Errorf("invalid schedule %q, expected e.g. \"daily 03:00\" or \"Sat 04:30\"", s)
+	}
+	if len(fields) == 2 {
+		var err error
+		if t.Days, err = manager.ParseWeekdays(fields[0]); err != nil {
+			return t, fmt.Errorf("%v in schedule %q", err, s)
+		}
+	}
+	at, err := time.Parse("15:04", fields[len(fields)-1])
+	if err != nil {
+		return t, fmt.Errorf("invalid time in schedule %q, expected e.g. 03:00", s)
+	}
+	t.At = time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute
+	return t, nil
+}
+
+// OnCalendar returns the time as a calendar event of systemd timers, such as "Sat,Sun *-*-* 04:30:00".
+func (t Time) OnCalendar() string {
+	event := fmt.Sprintf("*-*-* %02d:%02d:00", int(t.At.Hours()), int(t.At.Minutes())%60)
+	if len(t.Days) == 0 {
+		return event
+	}
+	days := make([]string, len(t.Days))
+	for i, day := range t.Days {
+		days[i] = day.String()[:3]
+	}
+	return strings.Join(days, ",") + " " + event
+}
+
+// Cron returns the time as the schedule of a crontab entry, such as "30 4 * * 6,0".
+func (t Time) Cron() string {
+	days := "*"
+	if len(t.Days) > 0 {
+		numbers := make([]string, len(t.Days))
+		for i, day := range t.Days {
+			numbers[i] = fmt.Sprint(int(day))
+		}
+		days = strings.Join(numbers, ",")
+	}
+	return fmt.Sprintf("%d %d * * %s", int(t.At.Minutes())%60, int(t.At.Hours()), days)
+}
+
+// Scheduler installs and removes the scheduled upgrades with a backend.
+type Scheduler struct {
+	// Backend is BackendSystemd or BackendCron.
+	Backend string
+
+	// Root is the directory the files are installed relative to; the root directory if empty.
+	Root string
+
+	// Systemctl runs systemctl with the given arguments; systemctl itself if nil. It isn't run when Root is set.
+	Systemctl func(args ...string) error
+}
+
+// Detect returns a Scheduler with the systemd backend if systemd is the init system and systemctl is available,
+// and with the cron backend otherwise.
+func Detect() *Scheduler {
+	if _, err := os.Stat("/run/systemd/system"); err == nil {
+		if _, err := exec.LookPath("systemctl"); err == nil {
+			return &Scheduler{Backend: BackendSystemd}
+		}
+	}
+	return &Scheduler{Backend: BackendCron}
+}
+
+// Files returns the paths of the files installed by the backend.
+func (s *Scheduler) Files() []string {
+	if s.Backend == BackendSystemd {
+		return []string{s.path("/etc/systemd/system", Name+".service"), s.path("/etc/systemd/system", Name+".timer")}
+	}
+	return []string{s.path("/etc/cron.d", Name)}
+}
+
+// Install installs the schedule running command at time t, replacing an installed one, and returns the paths of the
+// installed files. With systemd, the timer is enabled and started.
+func (s *Scheduler) Install(t Time, command []string) ([]string, error) {
+	if len(command) == 0 {
+		return nil, errors.New("schedule: no command to run")
+	}
+
+	files := s.Files()
+	var contents []string
+	switch s.Backend {
+	case BackendSystemd:
+		contents = []string{ServiceUnit(command), TimerUnit(t)}
+	case BackendCron:
+		contents = []string{CronEntry(t, command)}
+	default:
+		return nil, fmt.Errorf("schedule: unknown backend %q, expected %s or %s", s.Backend, BackendSystemd, BackendCron)
+	}
+	for i, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(file, []byte(contents[i]), 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.Backend == BackendSystemd {
+		if err := s.systemctl("daemon-reload"); err != nil {
+			return files, err
+		}
+		if err := s.systemctl("enable", "--now", Name+".timer"); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// Remove stops and removes the installed schedule. It returns ErrNotInstalled if no schedule is installed.
+func (s *Scheduler) Remove() error {
+	if _, err := s.Installed(); err != nil {
+		return err
+	}
+	if s.Backend == BackendSystemd {
+		if err := s.systemctl("disable", "--now", Name+".timer"); err != nil {
+			return err
+		}
+	}
+	for _, file := range s.Files() {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if s.Backend == BackendSystemd {
+		return s.systemctl("daemon-reload")
+	}
+	return nil
+}
+
+// Installed returns the contents of the installed files, by path. It returns ErrNotInstalled if no schedule is
+// installed.
+func (s *Scheduler) Installed() (map[string]string, error) {
+	contents := make(map[string]string)
+	for _, file := range s.Files() {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		contents[file] = string(data)
+	}
+	if len(contents) == 0 {
+		return nil, ErrNotInstalled
+	}
+	return contents, nil
+}
+
+// ServiceUnit returns the systemd service running command once, logging its output to the journal.
+func ServiceUnit(command []string) string {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = quoteSystemd(arg)
+	}
+	return "# Installed by syspkg schedule\n" +
+		"[Unit]\n" +
+		"Description=Unattended upgrades of all package managers with syspkg\n" +
+		"Wants=network-online.target\n" +
+		"After=network-online.target\n" +
+		"\n" +
+		"[Service]\n" +
+		"Type=oneshot\n" +
+		"ExecStart=" + strings.Join(args, " ") + "\n" +
+		"SyslogIdentifier=" + Name + "\n" +
+		"Nice=10\n" +
+		"IOSchedulingClass=idle\n"
+}
+
+// TimerUnit returns the systemd timer starting the service at time t. Runs missed while the system was off are
+// run at boot, and runs are delayed randomly by up to 15 minutes, so that many systems don't hit the mirrors at once.
+func TimerUnit(t Time) string {
+	return "# Installed by syspkg schedule\n" +
+		"[Unit]\n" +
+		"Description=Scheduled unattended upgrades with syspkg\n" +
+		"\n" +
+		"[Timer]\n" +
+		"OnCalendar=" + t.OnCalendar() + "\n" +
+		"RandomizedDelaySec=15m\n" +
+		"Persistent=true\n" +
+		"\n" +
+		"[Install]\n" +
+		"WantedBy=timers.target\n"
+}
+
+// CronEntry returns the entry of /etc/cron.d running command as root at time t, appending its output to LogFile.
+func CronEntry(t Time, command []string) string {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = manager.QuoteShell(arg)
+	}
+	line := fmt.Sprintf("%s root mkdir -p %s && %s >> %s 2>&1", t.Cron(), manager.QuoteShell(filepath.Dir(LogFile)),
+		strings.Join(args, " "), manager.QuoteShell(LogFile))
+	// % starts the standard input of the command in crontabs
+	line = strings.ReplaceAll(line, "%", `\%`)
+	return "# Installed by syspkg schedule\n" +
+		"SHELL=/bin/sh\n" +
+		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin\n" +
+		line + "\n"
+}
+
+// path returns the path of a file of the backend, relative to Root.
+func (s *Scheduler) path(dir string, name string) string {
+	return filepath.Join(s.Root, dir, name)
+}
+
+// systemctl runs systemctl with the given arguments, unless the files are installed relative to Root.
+func (s *Scheduler) systemctl(args ...string) error {
+	if s.Root != "" {
+		return nil
+	}
+	if s.Systemctl != nil {
+		return s.Systemctl(args...)
+	}
+	out, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// quoteSystemd quotes an argument of a command line of a systemd unit, when it contains spaces, quotes or the
+// specifiers and variables systemd expands.
+func quoteSystemd(arg string) string {
+	arg = strings.ReplaceAll(strings.ReplaceAll(arg, "%", "%%"), "$", "$$")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(arg, `\`, `\\`), `"`, `\"`) + `"`
+}
@@ -0,0 +1,99 @@
+package schedule_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager/schedule"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedCalendar   string
+		expectedCronFields string
+	}{
+		{"03:00", "*-*-* 03:00:00", "0 3 * * *"},
+		{"daily 23:45", "*-*-* 23:45:00", "45 23 * * *"},
+		{"Sat,Sun 04:30", "Sat,Sun *-*-* 04:30:00", "30 4 * * 6,0"},
+		{"mon-wed 00:05", "Mon,Tue,Wed *-*-* 00:05:00", "5 0 * * 1,2,3"},
+	}
+	for _, tt := range tests {
+		actual, err := schedule.ParseTime(tt.input)
+		if err != nil {
+			t.Errorf("ParseTime(%q) error = %+v", tt.input, err)
+			continue
+		}
+		if calendar := actual.OnCalendar(); calendar != tt.expectedCalendar {
+			t.Errorf("ParseTime(%q).OnCalendar() = %q, want %q", tt.input, calendar, tt.expectedCalendar)
+		}
+		if cron := actual.Cron(); cron != tt.expectedCronFields {
+			t.Errorf("ParseTime(%q).Cron() = %q, want %q", tt.input, cron, tt.expectedCronFields)
+		}
+	}
+
+	for _, input := range []string{"", "weekends 03:00", "25:00", "daily 3am", "Sat Sun 03:00"} {
+		if _, err := schedule.ParseTime(input); err == nil {
+			t.Errorf("ParseTime(%q) error = nil, want an error", input)
+		}
+	}
+}
+
+func TestSchedulerSystemd(t *testing.T) {
+	var calls []string
+	s := &schedule.Scheduler{Backend: schedule.BackendSystemd, Root: t.TempDir()}
+	at, _ := schedule.ParseTime("Sat 04:30")
+	command := []string{"/usr/local/bin/syspkg", "-y", "upgrade", "--webhook", "https://hooks.example.com/a b?x=%1"}
+
+	files, err := s.Install(at, command)
+	if err != nil {
+		t.Fatalf("Install() error = %+v", err)
+	}
+	if !reflect.DeepEqual(files, s.Files()) {
+		t.Errorf("Install() = %+v, want %+v", files, s.Files())
+	}
+	installed, err := s.Installed()
+	if err != nil {
+		t.Fatalf("Installed() error = %+v", err)
+	}
+	service, timer := installed[files[0]], installed[files[1]]
+	if expected := `ExecStart=/usr/local/bin/syspkg -y upgrade --webhook "https://hooks.example.com/a b?x=%%1"`; !strings.Contains(service, expected+"\n") {
+		t.Errorf("service = %q, want it to contain %q", service, expected)
+	}
+	if expected := "OnCalendar=Sat *-*-* 04:30:00\n"; !strings.Contains(timer, expected) {
+		t.Errorf("timer = %q, want it to contain %q", timer, expected)
+	}
+
+	// systemctl isn't run for files installed relative to another root
+	s.Systemctl = func(args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return nil
+	}
+	if err := s.Remove(); err != nil {
+		t.Fatalf("Remove() error = %+v", err)
+	}
+	if len(calls) > 0 {
+		t.Errorf("Remove() ran systemctl %+v, want no calls", calls)
+	}
+	for _, file := range files {
+		if _, err := os.Stat(file); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Remove() kept %s", file)
+		}
+	}
+	if err := s.Remove(); !errors.Is(err, schedule.ErrNotInstalled) {
+		t.Errorf("Remove() error = %+v, want %+v", err, schedule.ErrNotInstalled)
+	}
+}
+
+func TestCronEntry(t *testing.T) {
+	at, _ := schedule.ParseTime("daily 03:00")
+	entry := schedule.CronEntry(at, []string{"/usr/bin/syspkg", "upgrade", "--exclude", "kernel*", "--webhook", "https://example.com/?q=100%"})
+
+	expectedLine := `0 3 * * * root mkdir -p /var/log/syspkg && /usr/bin/syspkg upgrade --exclude 'kernel*' --webhook 'https://example.com/?q=100\%' >> /var/log/syspkg/upgrade.log 2>&1`
+	if !strings.Contains(entry, "\n"+expectedLine+"\n") {
+		t.Errorf("CronEntry() = %q, want it to contain %q", entry, expectedLine)
+	}
+}
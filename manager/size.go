@@ -0,0 +1,81 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits are the multipliers of the units of sizes, lowercased. Decimal units, as printed by apt and flatpak,
+// are powers of 1000, binary units (KiB, MiB...) powers of 1024. Single letters are decimal units.
+var sizeUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": 1e3, "kb": 1e3, "kib": 1 << 10,
+	"m": 1e6, "mb": 1e6, "mib": 1 << 20,
+	"g": 1e9, "gb": 1e9, "gib": 1 << 30,
+	"t": 1e12, "tb": 1e12, "tib": 1 << 40,
+}
+
+// ParseSize parses a size, such as "59.2 MB", "3738 kB", "1.5GiB" or "171 B", and returns it in bytes.
+// Thousands separators (commas) are ignored.
+func ParseSize(s string) (int64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+	number, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, unit)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q, expected e.g. 500MB or 1.5GiB", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// FormatSize formats a size in bytes with a decimal unit, as package managers print them, such as "59.2 MB".
+func FormatSize(n int64) string {
+	if n < 0 {
+		return "-" + FormatSize(-n)
+	}
+	if n < 1000 {
+		return fmt.Sprintf("%d B", n)
+	}
+	size, unit := float64(n)/1000, 0
+	for size >= 1000 && unit < 3 {
+		size /= 1000
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", size, []string{"kB", "MB", "GB", "TB"}[unit])
+}
+
+// SetInstalledSizes sets the SizeInstalled of the packages from sizes, by package name, for the package managers
+// listing the sizes of the installed packages with another command, and returns them.
+func SetInstalledSizes(packages []PackageInfo, sizes map[string]int64) []PackageInfo {
+	for i := range packages {
+		if size, ok := sizes[packages[i].Name]; ok {
+			packages[i].SizeInstalled = size
+		}
+	}
+	return packages
+}
+
+// SortBySize sorts the packages by installed size, largest first, then by name and package manager.
+func SortBySize(packages []PackageInfo) {
+	sort.SliceStable(packages, func(i, j int) bool {
+		a, b := packages[i], packages[j]
+		if a.SizeInstalled != b.SizeInstalled {
+			return a.SizeInstalled > b.SizeInstalled
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.PackageManager < b.PackageManager
+	})
+}
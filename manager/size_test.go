@@ -0,0 +1,59 @@
+package manager_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{"171 B", 171},
+		{"3738 kB", 3738000},
+		{"59.2 MB", 59200000},
+		{"1,024 kB", 1024000},
+		{"1.5GiB", 1610612736},
+		{"500M", 500000000},
+		{"42", 42},
+	}
+	for _, tt := range tests {
+		if got, err := manager.ParseSize(tt.size); err != nil || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %+v, want %d", tt.size, got, err, tt.want)
+		}
+	}
+
+	for _, size := range []string{"", "MB", "12 parsecs", "-1 MB"} {
+		if _, err := manager.ParseSize(size); err == nil {
+			t.Errorf("ParseSize(%q) error = nil, want an error", size)
+		}
+	}
+
+	if got := manager.FormatSize(59200000); got != "59.2 MB" {
+		t.Errorf("FormatSize() = %q, want %q", got, "59.2 MB")
+	}
+}
+
+func TestSortBySize(t *testing.T) {
+	packages := manager.SetInstalledSizes([]manager.PackageInfo{
+		{Name: "vim", PackageManager: "apt"},
+		{Name: "firefox", PackageManager: "snap"},
+		{Name: "libc6", PackageManager: "apt"},
+		{Name: "hello", PackageManager: "snap"},
+	}, map[string]int64{"vim": 3_800_000, "firefox": 260_000_000, "libc6": 12_900_000})
+
+	expectedPackages := []manager.PackageInfo{
+		{Name: "firefox", PackageManager: "snap", SizeInstalled: 260_000_000},
+		{Name: "libc6", PackageManager: "apt", SizeInstalled: 12_900_000},
+		{Name: "vim", PackageManager: "apt", SizeInstalled: 3_800_000},
+		{Name: "hello", PackageManager: "snap"},
+	}
+
+	manager.SortBySize(packages)
+	if !reflect.DeepEqual(expectedPackages, packages) {
+		t.Errorf("SortBySize() = %+v, want %+v", packages, expectedPackages)
+	}
+}
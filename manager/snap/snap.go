@@ -16,9 +16,10 @@
 package snap
 
 import (
-	"log"
+	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/bluet/syspkg/manager"
 )
@@ -35,8 +36,13 @@ const (
 	ArgsPurge        string = "--purge"
 	ArgsAutoRemove   string = "--autoremove"
 	ArgsShowProgress string = "--show-progress"
+	ArgsTargetDir    string = "--target-directory="
+	ArgsDangerous    string = "--dangerous"
 )
 
+// SnapsDir is the directory of the snap files of the installed revisions, named <name>_<revision>.snap.
+var SnapsDir string = "/var/lib/snapd/snaps"
+
 // ENV_NonInteractive is an environment variable configuration to set non-interactive mode for package manager commands.
 var ENV_NonInteractive []string = []string{"LC_ALL=C"}
 
@@ -54,8 +60,77 @@ func (a *PackageManager) GetPackageManager() string {
 	return pm
 }
 
+// Capabilities returns the operations and options supported by snap.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:         true,
+		Delete:         true,
+		DryRun:         true,
+		ListUpgradable: true,
+		Upgrade:        true,
+		Downgrade:      true,
+		Download:       true,
+		LocalInstall:   true,
+		Verify:         true,
+	}
+}
+
 // Install installs the specified packages using the snap package manager with the provided options.
+// Each snap can be followed by the options selecting its channel or revision, e.g. "firefox", "--channel=latest/edge",
+// as parsed by ParseChannelSpecs. The installed snaps have their publisher as Origin, and their channel in AdditionalData.
 func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	specs, err := ParseChannelSpecs(pkgs)
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.DownloadOnly {
+		return a.Download(pkgs, opts)
+	}
+
+	var packages []manager.PackageInfo
+	for _, batch := range channelBatches(specs) {
+		installed, err := a.install(batch, opts)
+		packages = append(packages, installed...)
+		if err != nil {
+			return packages, err
+		}
+	}
+	return packages, nil
+}
+
+// InstallLocal installs the provided .snap files using snap install. The assertions of a snap, downloaded next to it
+// by snap download (e.g. hello_42.assert for hello_42.snap), are acknowledged first, so that it is installed as
+// if from the store. If any snap has no assertions, the snaps are installed with --dangerous, without signature verification.
+func (a *PackageManager) InstallLocal(paths []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	files, err := manager.LocalPackageFiles(paths, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	dangerous := false
+	for _, file := range files {
+		assert := strings.TrimSuffix(file, ".snap") + ".assert"
+		if _, err := os.Stat(assert); err != nil {
+			dangerous = true
+			continue
+		}
+		if opts != nil && opts.DryRun {
+			continue
+		}
+		opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", []string{"ack", assert})
+		if out, err := manager.Command(opts, pm, "ack", assert).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("snap ack %s: %w: %s", assert, err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	if dangerous {
+		files = append([]string{ArgsDangerous}, files...)
+	}
+	return a.install(files, opts)
+}
+
+// install runs snap install with the provided arguments, which are the snaps to install and extra options.
+func (a *PackageManager) install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
 	args := append([]string{"install", ArgsFixBroken}, pkgs...)
 
 	if opts == nil {
@@ -79,27 +154,72 @@ func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manage
 		args = append(args, ArgsShowProgress)
 	}
 
-	cmd := exec.Command(pm, args...)
-	// cmd.Env = append(os.Environ(), ENV_NonInteractive...)
+	cmd := manager.Command(opts, pm, args...)
+	// cmd.Env = append(cmd.Environ(), ENV_NonInteractive...)
 
-	log.Printf("Running command: %s %s", pm, args)
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	}
 
-	cmd.Env = append(os.Environ(), ENV_NonInteractive...)
-	out, err := cmd.Output()
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return nil, err
 	}
 	return ParseInstallOutput(string(out), opts), nil
 }
 
+// Download downloads the specified snaps and their assertions using snap download, into opts.DownloadDir or the current directory.
+// The assertions, needed to install the snaps offline with snap ack, are returned in AdditionalData["assert"].
+// Like with Install, each snap can be followed by the channel or revision to download.
+func (a *PackageManager) Download(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	specs, err := ParseChannelSpecs(pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	var packages []manager.PackageInfo
+	// snap download downloads one snap at a time
+	for _, spec := range specs {
+		args := []string{"download"}
+		if opts.DownloadDir != "" {
+			if err := os.MkdirAll(opts.DownloadDir, 0o755); err != nil {
+				return packages, err
+			}
+			args = append(args, ArgsTargetDir+opts.DownloadDir)
+		}
+		// the options installing the snap, such as --classic, don't apply to downloads
+		spec.Options = nil
+		args = append(args, spec.Args()...)
+
+		cmd := manager.Command(opts, pm, args...)
+
+		opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+		manager.SetEnv(cmd, opts, ENV_NonInteractive)
+		out, err := manager.CombinedOutput(cmd)
+		if err != nil {
+			return packages, err
+		}
+		packages = append(packages, ParseDownloadOutput(string(out), opts)...)
+	}
+	return packages, nil
+}
+
 // Delete removes the specified packages using the snap package manager with the provided options.
 func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
 	args := append([]string{"remove", ArgsFixBroken}, pkgs...)
@@ -125,21 +245,21 @@ func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager
 		args = append(args, ArgsShowProgress)
 	}
 
-	cmd := exec.Command(pm, args...)
-	// cmd.Env = append(os.Environ(), ENV_NonInteractive...)
+	cmd := manager.Command(opts, pm, args...)
+	// cmd.Env = append(cmd.Environ(), ENV_NonInteractive...)
 
-	log.Printf("Running command: %s %s", pm, args)
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	}
 
-	cmd.Env = append(os.Environ(), ENV_NonInteractive...)
-	out, err := cmd.Output()
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -154,10 +274,10 @@ func (a *PackageManager) Refresh(opts *manager.Options) error {
 // Find searches for packages matching the provided keywords using the snap package manager.
 func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
 	args := append([]string{"search"}, keywords...)
-	cmd := exec.Command("snap", args...)
-	cmd.Env = ENV_NonInteractive
+	cmd := manager.Command(opts, "snap", args...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
 
-	out, err := cmd.Output()
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -167,32 +287,62 @@ func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manag
 
 // ListInstalled lists all installed packages using the snap package manager.
 func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
-	cmd := exec.Command("snap", "list")
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	cmd := manager.Command(opts, "snap", "list")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return nil, err
 	}
 	return ParseListInstalledOutput(string(out), opts), nil
 }
 
+// Verify checks the provided snaps, or all installed snaps if none are given, and returns the results of the snaps
+// installed without an assertion signed by the store, e.g. with --dangerous, or whose snap file is missing.
+// The files of snaps can't be modified, since they are mounted read-only from their snap files.
+func (a *PackageManager) Verify(pkgs []string, opts *manager.Options) ([]manager.VerificationResult, error) {
+	installed, err := a.ListInstalled(opts)
+	if err != nil {
+		return nil, err
+	}
+	return manager.FilterVerificationResults(VerifySnaps(installed), pkgs), nil
+}
+
 // ListUpgradable lists all upgradable packages using the snap package manager.
 func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
-	cmd := exec.Command(pm, "refresh", "--list")
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	cmd := manager.Command(opts, pm, "refresh", "--list")
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return nil, err
 	}
 	return ParseListUpgradableOutput(string(out), opts), nil
 }
 
-// Upgrade upgrades the specified packages using the snap package manager with the provided options.
+// Upgrade upgrades the specified packages, or all packages if none are specified, using the snap package manager.
+// Like with Install, each snap can be followed by the options switching it to another channel or revision.
 func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
-	args := []string{"refresh"}
-	if len(pkgs) > 0 {
-		args = append(args, pkgs...)
+	specs, err := ParseChannelSpecs(pkgs)
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return a.refresh(nil, opts)
+	}
+
+	var packages []manager.PackageInfo
+	for _, batch := range channelBatches(specs) {
+		upgraded, err := a.refresh(batch, opts)
+		packages = append(packages, upgraded...)
+		if err != nil {
+			return packages, err
+		}
 	}
+	return packages, nil
+}
+
+// refresh runs snap refresh with the provided arguments, which are the snaps to upgrade and their options.
+func (a *PackageManager) refresh(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{"refresh"}, pkgs...)
 
 	if opts == nil {
 		opts = &manager.Options{
@@ -215,28 +365,107 @@ func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manage
 		args = append(args, ArgsShowProgress)
 	}
 
-	cmd := exec.Command(pm, args...)
-	// cmd.Env = append(os.Environ(), ENV_NonInteractive...)
+	cmd := manager.Command(opts, pm, args...)
+	// cmd.Env = append(cmd.Environ(), ENV_NonInteractive...)
 
-	log.Printf("Running command: %s %s", pm, args)
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
 
 	if opts.Interactive {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
-		err := cmd.Run()
+		err := manager.Run(cmd)
 		return nil, err
 	}
 
-	// cmd.Env = append(os.Environ(), ENV_NonInteractive...)
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	// cmd.Env = append(cmd.Environ(), ENV_NonInteractive...)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return nil, err
 	}
 	return ParseInstallOutput(string(out), opts), nil
 }
 
+// Downgrade reverts the specified snaps with snap revert, to the revision given as "name=revision", or as "name=version"
+// for a revision still kept on the system. Snaps given without a version are reverted to their previous revision.
+func (a *PackageManager) Downgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	var packages []manager.PackageInfo
+	// snap revert reverts one snap at a time
+	for _, pkg := range pkgs {
+		spec, err := manager.ParsePackageSpec(pkg)
+		if err != nil {
+			return packages, err
+		}
+		args := []string{"revert", spec.Name}
+		if spec.Version != "" {
+			revision, err := a.revision(spec, opts)
+			if err != nil {
+				return packages, err
+			}
+			args = append(args, "--revision="+revision)
+		}
+
+		// snap revert has no dry-run mode
+		if opts.DryRun {
+			opts.Log().Info("Dry run, not running command", "package_manager", pm, "command", pm, "args", args)
+			packages = append(packages, manager.PackageInfo{Name: spec.Name, NewVersion: spec.Version, Status: manager.PackageStatusInstalled, PackageManager: pm})
+			continue
+		}
+
+		cmd := manager.Command(opts, pm, args...)
+
+		opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+		if opts.Interactive {
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			cmd.Stdin = os.Stdin
+			if err := manager.Run(cmd); err != nil {
+				return packages, err
+			}
+			continue
+		}
+
+		manager.SetEnv(cmd, opts, ENV_NonInteractive)
+		out, err := manager.Output(cmd)
+		if err != nil {
+			return packages, err
+		}
+		packages = append(packages, ParseRevertOutput(string(out), opts)...)
+	}
+	return packages, nil
+}
+
+// revision returns the revision of a snap to revert to: the version of spec if it is a revision number, otherwise
+// the revision of the snap with that version among the ones kept on the system, as listed by snap list --all.
+func (a *PackageManager) revision(spec manager.PackageSpec, opts *manager.Options) (string, error) {
+	if strings.Trim(spec.Version, "0123456789") == "" {
+		return spec.Version, nil
+	}
+
+	cmd := manager.Command(opts, pm, "list", "--all", spec.Name)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return "", err
+	}
+	for _, pkg := range ParseListOutput(string(out), opts) {
+		if pkg.Name == spec.Name && pkg.Version == spec.Version {
+			return pkg.AdditionalData["revision"], nil
+		}
+	}
+	return "", fmt.Errorf("%w: no revision of %s with version %s is kept on the system", manager.ErrInvalidPackageSpec, spec.Name, spec.Version)
+}
+
 // UpgradeAll upgrades all upgradable packages using the snap package manager with the provided options.
 func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
 	return a.Upgrade(nil, opts)
@@ -244,9 +473,9 @@ func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInf
 
 // GetPackageInfo retrieves information about the specified package using the snap package manager.
 func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
-	cmd := exec.Command("snap", "info", pkg)
-	cmd.Env = ENV_NonInteractive
-	out, err := cmd.Output()
+	cmd := manager.Command(opts, "snap", "info", pkg)
+	cmd.Env = manager.Env(opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
 	if err != nil {
 		return manager.PackageInfo{}, err
 	}
@@ -2,17 +2,21 @@ package snap
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/bluet/syspkg/manager"
 )
 
-// ParseInstallOutput parses the output of `snap install` command
-// and returns a list of PackageInfo
+// ParseInstallOutput parses the output of `snap install` and `snap refresh` commands
+// and returns a list of PackageInfo, with the publisher as Origin and the channel in AdditionalData when printed.
 //
 // Example output:
 // snap "deja-dup" is already installed, see 'snap help refresh'
 // blablaland-desktop (edge) 1.0.1 from AdeDev installed
+// firefox 112.0.1-1 from Mozilla✓ refreshed
 func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo {
 	var packages []manager.PackageInfo
 
@@ -22,7 +26,7 @@ func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo
 
 	for _, line := range lines {
 		if opts.Verbose {
-			fmt.Printf("snap: %s", line)
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
 		}
 		if strings.HasPrefix(line, "snap \"") {
 			parts := strings.Fields(line)
@@ -38,21 +42,17 @@ func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo
 				PackageManager: pm,
 			}
 			packages = append(packages, packageInfo)
-		} else if strings.HasSuffix(line, "installed") {
-			parts := strings.Fields(line)
-			name := parts[0]
-			version := parts[2]
-			// if name is empty, it might be not what we want
-			if name == "" {
-				continue
-			}
-
+		} else if match := installedPattern.FindStringSubmatch(line); match != nil {
 			packageInfo := manager.PackageInfo{
-				Name:           name,
-				Version:        version,
+				Name:           match[1],
+				Version:        match[3],
 				Status:         manager.PackageStatusInstalled,
+				Origin:         publisher(match[4]),
 				PackageManager: pm,
 			}
+			if match[2] != "" {
+				packageInfo.AdditionalData = map[string]string{"channel": match[2]}
+			}
 			packages = append(packages, packageInfo)
 		}
 	}
@@ -60,6 +60,10 @@ func ParseInstallOutput(msg string, opts *manager.Options) []manager.PackageInfo
 	return packages
 }
 
+// installedPattern matches the lines of snap install and snap refresh telling that a snap was installed:
+// the name, the channel if not stable, the version and the publisher.
+var installedPattern = regexp.MustCompile(`^(\S+) (?:\(([^)]+)\) )?(\S+) from (\S+) (?:installed|refreshed)$`)
+
 // ParseDeletedOutput parses the output of `snap search` command
 // and returns a list of PackageInfo
 //
@@ -76,7 +80,7 @@ func ParseSearchOutput(msg string, opts *manager.Options) []manager.PackageInfo
 	// skip the first line
 	for _, line := range lines[1:] {
 		if opts.Verbose {
-			fmt.Printf("%s: %s", pm, line)
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
 		}
 		parts := strings.Fields(line)
 		if len(parts) < 5 {
@@ -103,13 +107,16 @@ func ParseSearchOutput(msg string, opts *manager.Options) []manager.PackageInfo
 
 // cspell: disable
 // ParsePackageInfoOutput parses the output of `snap info` command
-// and returns a list of PackageInfo
+// and returns a list of PackageInfo. The publisher is the maintainer of the snap, and its website, or its contact
+// if it is a URL, its homepage. The size of the installed revision is its installed size, and the size of the
+// release of the first channel with one its download size.
 //
 // Example msg:
 // name:      blablaland-desktop
 // summary:   Blablaland Desktop
 // publisher: AdeDev
 // store-url: https://snapcraft.io/blablaland-desktop
+// contact:   https://github.com/AdeDev/blablaland-desktop/issues
 // license:   unset
 // description: |
 //
@@ -126,6 +133,7 @@ func ParseSearchOutput(msg string, opts *manager.Options) []manager.PackageInfo
 // cspell: enable
 func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageInfo {
 	var pkg manager.PackageInfo
+	pkg.AdditionalData = map[string]string{}
 
 	// remove the last empty line
 	msg = strings.TrimSuffix(msg, "\n")
@@ -144,13 +152,31 @@ func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageIn
 			key := strings.TrimSpace(parts[0])
 			value := strings.TrimSpace(parts[1])
 
-			if key == "name" {
+			switch {
+			case key == "name":
 				pkg.Name = value
-			} else if strings.HasPrefix(key, "latest/") {
-				version := strings.Fields(value)[0]
-				if pkg.Version == "" {
-					pkg.Version = version
+			case key == "publisher":
+				pkg.Origin = publisher(value)
+				pkg.Maintainer = publisher(value)
+			case key == "license" && value != "unset":
+				pkg.License = value
+			case key == "website":
+				pkg.Homepage = value
+			case key == "contact" && pkg.Homepage == "" && strings.HasPrefix(value, "http"):
+				pkg.Homepage = value
+			case key == "tracking":
+				pkg.AdditionalData["channel"] = value
+			case key == "installed":
+				// installed: 1.0.1 (3) 112MB classic
+				pkg.Version, pkg.Status = "", manager.PackageStatusInstalled
+				pkg.SizeInstalled, _ = setRevision(&pkg, value)
+			case strings.HasPrefix(key, "latest/") && pkg.Version == "":
+				// channels without a release are listed with – (or ↑ when following a more stable one)
+				size, ok := setRevision(&pkg, value)
+				if _, tracking := pkg.AdditionalData["channel"]; ok && !tracking {
+					pkg.AdditionalData["channel"] = key
 				}
+				pkg.SizeDownload = size
 			}
 		}
 	}
@@ -160,6 +186,29 @@ func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageIn
 	return pkg
 }
 
+// setRevision sets the version, revision and confinement of pkg from a release of snap info, such as
+// "1.0.1 2021-06-08 (3) 112MB classic" for a channel, or "1.0.1 (3) 112MB -" for the installed snap, and returns
+// the size of the snap, following its revision, or 0 if it is unknown.
+// It returns false for channels without a release.
+func setRevision(pkg *manager.PackageInfo, release string) (int64, bool) {
+	fields := strings.Fields(release)
+	if len(fields) < 3 {
+		return 0, false
+	}
+	pkg.Version = fields[0]
+	var size int64
+	for i, field := range fields[1:] {
+		if strings.HasPrefix(field, "(") && strings.HasSuffix(field, ")") {
+			pkg.AdditionalData["revision"] = strings.Trim(field, "()")
+			if i+2 < len(fields) {
+				size, _ = manager.ParseSize(fields[i+2])
+			}
+		}
+	}
+	pkg.AdditionalData["confinement"] = confinement(fields[len(fields)-1])
+	return size, true
+}
+
 // ParseListUpgradableOutput parses the output of `snap refresh --list` command
 // and returns a list of PackageInfo
 //
@@ -197,8 +246,12 @@ func ParseListInstalledOutput(msg string, opts *manager.Options) []manager.Packa
 	return ParseListOutput(msg, opts)
 }
 
+// ParseListOutput parses the tables printed by `snap list`, `snap refresh --list` and `snap search`,
+// and returns a list of PackageInfo. The columns are found by their header, and the revision, channel (tracked
+// by installed snaps) and confinement are set in AdditionalData, and the publisher as Origin, when the table has them.
 func ParseListOutput(msg string, opts *manager.Options) []manager.PackageInfo {
 	var packages []manager.PackageInfo
+	columns := map[string]int{"Name": 0, "Version": 1}
 
 	// remove the last empty line
 	msg = strings.TrimSuffix(msg, "\n")
@@ -206,26 +259,264 @@ func ParseListOutput(msg string, opts *manager.Options) []manager.PackageInfo {
 
 	for _, line := range lines {
 		if opts.Verbose {
-			fmt.Printf("%s: %s", pm, line)
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
 		}
 		parts := strings.Fields(line)
 		if len(parts) < 5 {
 			continue
 		}
 
-		// skip the first line (header/title)
+		// the first line (header/title) names the columns
 		if parts[0] == "Name" {
+			columns = make(map[string]int)
+			for i, column := range parts {
+				columns[column] = i
+			}
 			continue
 		}
 
 		packageInfo := manager.PackageInfo{
 			Name:           parts[0],
-			Version:        parts[1],
+			Version:        parts[columns["Version"]],
 			Status:         manager.PackageStatusAvailable,
 			PackageManager: pm,
+			AdditionalData: map[string]string{},
+		}
+		for column, key := range map[string]string{"Rev": "revision", "Tracking": "channel"} {
+			if i, ok := columns[column]; ok && parts[i] != "-" {
+				packageInfo.AdditionalData[key] = parts[i]
+			}
+		}
+		if i, ok := columns["Publisher"]; ok && parts[i] != "-" {
+			packageInfo.Origin = publisher(parts[i])
+		}
+		if i, ok := columns["Notes"]; ok {
+			packageInfo.AdditionalData["confinement"] = confinement(parts[i])
 		}
 		packages = append(packages, packageInfo)
 	}
 
 	return packages
 }
+
+// ParseDownloadOutput parses the output of `snap download` command and returns the downloaded snap,
+// with the absolute path of the snap in AdditionalData["path"] and of its assertions in AdditionalData["assert"].
+//
+// Example output:
+// Fetching snap "hello"
+// Fetching assertions for "hello"
+// Install the snap with:
+//
+//	snap ack hello_42.assert
+//	snap install hello_42.snap
+func ParseDownloadOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	var assert string
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	var lines []string = strings.Split(string(msg), "\n")
+
+	for _, line := range lines {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "snap" {
+			continue
+		}
+		// the paths are relative to the current directory, unless a target directory is set
+		path, err := filepath.Abs(fields[2])
+		if err != nil {
+			continue
+		}
+
+		switch fields[1] {
+		case "ack":
+			assert = path
+		case "install":
+			// the file is named after the snap and its revision, e.g. hello_42.snap
+			name, revision, _ := strings.Cut(strings.TrimSuffix(filepath.Base(path), ".snap"), "_")
+			packageInfo := manager.PackageInfo{
+				Name:           name,
+				Version:        revision,
+				Status:         manager.PackageStatusAvailable,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"path": path},
+			}
+			if assert != "" {
+				packageInfo.AdditionalData["assert"] = assert
+			}
+			packages = append(packages, packageInfo)
+		}
+	}
+
+	return packages
+}
+
+// ParseRevertOutput parses the output of `snap revert` command and returns the reverted snap.
+//
+// Example output:
+// hello reverted to 2.10
+func ParseRevertOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+		name, version, found := strings.Cut(line, " reverted to ")
+		if !found || name == "" {
+			continue
+		}
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Version:        strings.TrimSpace(version),
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ChannelSpec is a snap to install or upgrade, with the options of snap install and snap refresh given after it,
+// such as the channel or revision to track.
+type ChannelSpec struct {
+	// Name is the name of the snap, or the path of a snap file.
+	Name string
+
+	// Channel is the channel to track, such as "latest/edge", if requested.
+	Channel string
+
+	// Revision is the revision to install, if requested.
+	Revision string
+
+	// Options are the other options of the snap, such as --classic.
+	Options []string
+}
+
+// channelOptions are the options of snap install selecting a risk level of the tracked track.
+var channelOptions = map[string]string{"--stable": "stable", "--candidate": "candidate", "--beta": "beta", "--edge": "edge"}
+
+// ParseChannelSpecs parses snap names, each followed by its options as accepted by snap install and snap refresh:
+// --channel=latest/edge (or --channel latest/edge), --stable, --candidate, --beta, --edge, --revision=42, and
+// other options such as --classic, which are passed unchanged. Versions can't be requested with name=version.
+func ParseChannelSpecs(pkgs []string) ([]ChannelSpec, error) {
+	var specs []ChannelSpec
+	for i := 0; i < len(pkgs); i++ {
+		arg := pkgs[i]
+		if !strings.HasPrefix(arg, "-") {
+			if err := manager.RejectVersionSpecs([]string{arg}); err != nil {
+				return nil, err
+			}
+			specs = append(specs, ChannelSpec{Name: arg})
+			continue
+		}
+		if len(specs) == 0 {
+			return nil, fmt.Errorf("%w: %q must follow a snap name", manager.ErrInvalidPackageSpec, arg)
+		}
+
+		spec := &specs[len(specs)-1]
+		option, value, hasValue := strings.Cut(arg, "=")
+		switch {
+		case option == "--channel" || option == "--revision":
+			if !hasValue {
+				if i+1 == len(pkgs) {
+					return nil, fmt.Errorf("%w: %s needs a value", manager.ErrInvalidPackageSpec, option)
+				}
+				i++
+				value = pkgs[i]
+			}
+			if option == "--channel" {
+				spec.Channel = value
+			} else {
+				spec.Revision = value
+			}
+		case channelOptions[arg] != "":
+			spec.Channel = channelOptions[arg]
+		default:
+			spec.Options = append(spec.Options, arg)
+		}
+	}
+	return specs, nil
+}
+
+// Args returns the arguments of snap install or snap refresh for the snap: its name, followed by its options.
+func (s ChannelSpec) Args() []string {
+	args := []string{s.Name}
+	if s.Channel != "" {
+		args = append(args, "--channel="+s.Channel)
+	}
+	if s.Revision != "" {
+		args = append(args, "--revision="+s.Revision)
+	}
+	return append(args, s.Options...)
+}
+
+// channelBatches groups the snaps by the commands installing or upgrading them: snap only accepts options for a
+// single snap, so each snap with options gets its own command, and the snaps without options share one.
+func channelBatches(specs []ChannelSpec) [][]string {
+	var batches [][]string
+	var plain []string
+	for _, spec := range specs {
+		if spec.Channel == "" && spec.Revision == "" && len(spec.Options) == 0 {
+			plain = append(plain, spec.Name)
+			continue
+		}
+		batches = append(batches, spec.Args())
+	}
+	if len(plain) > 0 {
+		batches = append([][]string{plain}, batches...)
+	}
+	return batches
+}
+
+// publisher returns the name of a publisher without the marks of verified publishers (✓, or ** without Unicode).
+func publisher(name string) string {
+	return strings.TrimRight(name, "✓*")
+}
+
+// confinement returns the confinement of a snap from the notes of snap list or snap search,
+// such as "classic" or "disabled,devmode": classic, devmode, jailmode, or strict by default.
+func confinement(notes string) string {
+	for _, note := range strings.Split(notes, ",") {
+		switch note {
+		case "classic", "devmode", "jailmode":
+			return note
+		}
+	}
+	return "strict"
+}
+
+// VerifySnaps checks the installed snaps, as returned by ParseListInstalledOutput, and returns the results of the snaps
+// having problems. Snaps installed without an assertion have a revision starting with "x", and are unsigned; the others
+// are signed by the store, and their snap file in SnapsDir must exist.
+func VerifySnaps(installed []manager.PackageInfo) []manager.VerificationResult {
+	var results []manager.VerificationResult
+	for _, pkg := range installed {
+		revision := pkg.AdditionalData["revision"]
+		if revision == "" {
+			continue
+		}
+
+		result := manager.VerificationResult{Name: pkg.Name, Version: pkg.Version, PackageManager: pm, Signature: manager.SignatureValid}
+		if strings.HasPrefix(revision, "x") {
+			// snaps tried from a directory with snap try have no snap file either
+			result.Signature = manager.SignatureUnsigned
+		} else if path := filepath.Join(SnapsDir, pkg.Name+"_"+revision+".snap"); !exists(path) {
+			result.MissingFiles = append(result.MissingFiles, path)
+		}
+		if result.Failed() {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// exists reports whether path exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
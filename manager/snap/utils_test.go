@@ -0,0 +1,155 @@
+package snap_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/snap"
+)
+
+func TestParseInstallOutput(t *testing.T) {
+	var inputParseInstallOutput string = strings.Join([]string{
+		`snap "deja-dup" is already installed, see 'snap help refresh'`,
+		`blablaland-desktop (edge) 1.0.1 from AdeDev installed`,
+		`firefox 112.0.1-1 from Mozilla✓ refreshed`,
+	}, "\n")
+
+	expectedPackageInfo := []manager.PackageInfo{
+		{Name: "deja-dup", Status: manager.PackageStatusInstalled, PackageManager: "snap"},
+		{Name: "blablaland-desktop", Version: "1.0.1", Status: manager.PackageStatusInstalled, PackageManager: "snap",
+			Origin: "AdeDev", AdditionalData: map[string]string{"channel": "edge"}},
+		{Name: "firefox", Version: "112.0.1-1", Status: manager.PackageStatusInstalled, PackageManager: "snap",
+			Origin: "Mozilla"},
+	}
+
+	actualPackageInfo := snap.ParseInstallOutput(inputParseInstallOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseInstallOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListInstalledOutput(t *testing.T) {
+	var inputParseListInstalledOutput string = strings.Join([]string{
+		`Name                Version   Rev    Tracking       Publisher    Notes`,
+		`blablaland-desktop  1.0.1     3      latest/edge    adedev       -`,
+		`code                1.85.1    151    latest/stable  vscode**     classic`,
+		`hello               2.10      38     latest/stable  canonical✓   disabled`,
+	}, "\n")
+
+	expectedPackageInfo := []manager.PackageInfo{
+		{Name: "blablaland-desktop", Version: "1.0.1", Status: manager.PackageStatusAvailable, PackageManager: "snap",
+			Origin: "adedev", AdditionalData: map[string]string{"revision": "3", "channel": "latest/edge", "confinement": "strict"}},
+		{Name: "code", Version: "1.85.1", Status: manager.PackageStatusAvailable, PackageManager: "snap",
+			Origin: "vscode", AdditionalData: map[string]string{"revision": "151", "channel": "latest/stable", "confinement": "classic"}},
+		{Name: "hello", Version: "2.10", Status: manager.PackageStatusAvailable, PackageManager: "snap",
+			Origin: "canonical", AdditionalData: map[string]string{"revision": "38", "channel": "latest/stable", "confinement": "strict"}},
+	}
+
+	actualPackageInfo := snap.ParseListInstalledOutput(inputParseListInstalledOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListInstalledOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParsePackageInfoOutput(t *testing.T) {
+	var inputParsePackageInfoOutput string = strings.Join([]string{
+		`name:      hello`,
+		`summary:   GNU Hello, the "hello world" snap`,
+		`publisher: Canonical✓`,
+		`contact:   https://github.com/canonical/hello-snap/issues`,
+		`website:   https://github.com/canonical/hello-snap`,
+		`license:   GPL-3.0`,
+		`snap-id:   buPKUD3TKqCOgLEjjHx5kSiCpIs5cMuQ`,
+		`tracking:     latest/candidate`,
+		`refresh-date: today at 10:22 UTC`,
+		`channels:`,
+		`  latest/stable:    2.10 2017-05-17 (38) 65kB -`,
+		`  latest/candidate: 2.10 2017-05-17 (38) 65kB -`,
+		`  latest/beta:      ↑`,
+		`  latest/edge:      –`,
+		`installed:          2.10            (38) 65kB -`,
+	}, "\n")
+
+	expectedPackageInfo := manager.PackageInfo{
+		Name:           "hello",
+		Version:        "2.10",
+		Status:         manager.PackageStatusInstalled,
+		License:        "GPL-3.0",
+		Homepage:       "https://github.com/canonical/hello-snap",
+		Maintainer:     "Canonical",
+		SizeInstalled:  65000,
+		SizeDownload:   65000,
+		Origin:         "Canonical",
+		PackageManager: "snap",
+		AdditionalData: map[string]string{"channel": "latest/candidate", "revision": "38", "confinement": "strict"},
+	}
+
+	actualPackageInfo := snap.ParsePackageInfoOutput(inputParsePackageInfoOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParsePackageInfoOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseChannelSpecs(t *testing.T) {
+	expectedSpecs := []snap.ChannelSpec{
+		{Name: "firefox", Channel: "latest/edge"},
+		{Name: "hello"},
+		{Name: "code", Channel: "beta", Options: []string{"--classic"}},
+		{Name: "core", Revision: "16202"},
+	}
+
+	actualSpecs, err := snap.ParseChannelSpecs([]string{"firefox", "--channel=latest/edge", "hello", "code", "--classic", "--beta", "core", "--revision", "16202"})
+	if err != nil || !reflect.DeepEqual(expectedSpecs, actualSpecs) {
+		t.Errorf("ParseChannelSpecs() = %+v, %+v, want %+v", actualSpecs, err, expectedSpecs)
+	}
+	if args := actualSpecs[2].Args(); !reflect.DeepEqual(args, []string{"code", "--channel=beta", "--classic"}) {
+		t.Errorf("Args() = %+v, want [code --channel=beta --classic]", args)
+	}
+
+	for _, pkgs := range [][]string{{"--edge", "firefox"}, {"firefox", "--channel"}, {"firefox=112.0"}} {
+		if _, err := snap.ParseChannelSpecs(pkgs); err == nil {
+			t.Errorf("ParseChannelSpecs(%q) error = nil, want an error", pkgs)
+		}
+	}
+	if _, err := snap.ParseChannelSpecs([]string{"firefox=112.0"}); !errors.Is(err, manager.ErrVersionNotSupported) {
+		t.Errorf("ParseChannelSpecs() error = %+v, want %+v", err, manager.ErrVersionNotSupported)
+	}
+}
+
+func TestParseRevertOutput(t *testing.T) {
+	expectedPackageInfo := []manager.PackageInfo{{Name: "hello", Version: "2.10", Status: manager.PackageStatusInstalled, PackageManager: "snap"}}
+	actualPackageInfo := snap.ParseRevertOutput("hello reverted to 2.10\n", &manager.Options{})
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseRevertOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestVerifySnaps(t *testing.T) {
+	dir := t.TempDir()
+	defer func(snapsDir string) { snap.SnapsDir = snapsDir }(snap.SnapsDir)
+	snap.SnapsDir = dir
+	if err := os.WriteFile(filepath.Join(dir, "hello_38.snap"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	installed := []manager.PackageInfo{
+		{Name: "hello", Version: "2.10", AdditionalData: map[string]string{"revision": "38"}},
+		{Name: "code", Version: "1.85.1", AdditionalData: map[string]string{"revision": "151"}},
+		{Name: "mysnap", Version: "0.1", AdditionalData: map[string]string{"revision": "x1"}},
+	}
+	expectedResults := []manager.VerificationResult{
+		{Name: "code", Version: "1.85.1", PackageManager: "snap", Signature: manager.SignatureValid,
+			MissingFiles: []string{filepath.Join(dir, "code_151.snap")}},
+		{Name: "mysnap", Version: "0.1", PackageManager: "snap", Signature: manager.SignatureUnsigned},
+	}
+
+	actualResults := snap.VerifySnaps(installed)
+	if !reflect.DeepEqual(expectedResults, actualResults) {
+		t.Errorf("VerifySnaps() = %+v, want %+v", actualResults, expectedResults)
+	}
+}
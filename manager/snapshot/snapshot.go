@@ -0,0 +1,195 @@
+// Package snapshot captures the sets of packages installed by each package manager, and computes the operations
+// needed to bring a system back to a saved snapshot.
+//
+// Snapshots are saved as JSON files, one per snapshot, in a directory (by default "snapshots" in the syspkg state directory).
+// Packages are identified by their name within each package manager.
+//
+// This package is part of the syspkg library.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// ErrInvalidName is returned for snapshot names that can't be used as file names.
+var ErrInvalidName = errors.New("snapshot: invalid snapshot name")
+
+// Snapshot is the set of packages installed by each package manager at a point in time.
+type Snapshot struct {
+	// Name is the name of the snapshot, which is also its file name.
+	Name string `json:"name"`
+
+	// Time is the time the snapshot was taken.
+	Time time.Time `json:"time"`
+
+	// Packages are the installed packages, by package manager name.
+	Packages map[string][]manager.PackageInfo `json:"packages"`
+}
+
+// Action is the kind of change needed to restore a package to its state in a snapshot.
+type Action string
+
+// Actions computed by Plan.
+const (
+	ActionInstall   Action = "install"
+	ActionDelete    Action = "delete"
+	ActionUpgrade   Action = "upgrade"
+	ActionDowngrade Action = "downgrade"
+)
+
+// Operation is a change to a package needed to restore a snapshot.
+type Operation struct {
+	// PackageManager is the name of the package manager of the package.
+	PackageManager string `json:"package_manager"`
+
+	// Action is the change to perform.
+	Action Action `json:"action"`
+
+	// Package is the name of the package.
+	Package string `json:"package"`
+
+	// Version is the version of the package in the snapshot. It is empty for deletions.
+	Version string `json:"version,omitempty"`
+
+	// CurrentVersion is the currently installed version of the package. It is empty for installations.
+	CurrentVersion string `json:"current_version,omitempty"`
+}
+
+// New returns an empty snapshot with the given name, taken now.
+func New(name string) *Snapshot {
+	return &Snapshot{
+		Name:     name,
+		Time:     time.Now().UTC(),
+		Packages: make(map[string][]manager.PackageInfo),
+	}
+}
+
+// Add records the installed packages of a package manager in the snapshot, replacing any previously added ones.
+func (s *Snapshot) Add(pm string, installed []manager.PackageInfo) {
+	if s.Packages == nil {
+		s.Packages = make(map[string][]manager.PackageInfo)
+	}
+	s.Packages[pm] = installed
+}
+
+// Plan computes the operations that bring the currently installed packages of a package manager back to the snapshot:
+// packages missing since the snapshot are installed, packages installed since the snapshot are deleted, and packages
+// whose version changed are upgraded or downgraded to the version in the snapshot.
+// Operations are sorted by action, then by package name. A package manager missing from the snapshot needs no operations.
+func (s *Snapshot) Plan(pm string, installed []manager.PackageInfo) []Operation {
+	saved, ok := s.Packages[pm]
+	if !ok {
+		return nil
+	}
+
+	current := make(map[string]string)
+	for _, pkg := range installed {
+		current[pkg.Name] = pkg.Version
+	}
+
+	var operations []Operation
+	wanted := make(map[string]bool)
+	for _, pkg := range saved {
+		wanted[pkg.Name] = true
+
+		version, ok := current[pkg.Name]
+		switch {
+		case !ok:
+			operations = append(operations, Operation{PackageManager: pm, Action: ActionInstall, Package: pkg.Name, Version: pkg.Version})
+		case manager.CompareVersions(version, pkg.Version) < 0:
+			operations = append(operations, Operation{PackageManager: pm, Action: ActionUpgrade, Package: pkg.Name, Version: pkg.Version, CurrentVersion: version})
+		case manager.CompareVersions(version, pkg.Version) > 0:
+			operations = append(operations, Operation{PackageManager: pm, Action: ActionDowngrade, Package: pkg.Name, Version: pkg.Version, CurrentVersion: version})
+		}
+	}
+	for _, pkg := range installed {
+		if !wanted[pkg.Name] {
+			operations = append(operations, Operation{PackageManager: pm, Action: ActionDelete, Package: pkg.Name, CurrentVersion: pkg.Version})
+		}
+	}
+
+	sort.SliceStable(operations, func(i, j int) bool {
+		if operations[i].Action != operations[j].Action {
+			return operations[i].Action < operations[j].Action
+		}
+		return operations[i].Package < operations[j].Package
+	})
+	return operations
+}
+
+// DefaultDir returns the default directory of the snapshots, "snapshots" in the syspkg state directory (see manager.StateDir).
+func DefaultDir() string {
+	return filepath.Join(manager.StateDir(), "snapshots")
+}
+
+// Save writes the snapshot to dir, replacing any snapshot with the same name.
+func Save(dir string, s *Snapshot) error {
+	path, err := snapshotPath(dir, s.Name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Load reads the snapshot with the given name from dir.
+func Load(dir string, name string) (*Snapshot, error) {
+	path, err := snapshotPath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("snapshot: %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// List returns the names of the snapshots saved in dir, sorted. A missing directory has no snapshots.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// snapshotPath returns the path of the snapshot file, rejecting names that would escape dir.
+func snapshotPath(dir string, name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
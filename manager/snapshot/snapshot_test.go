@@ -0,0 +1,70 @@
+package snapshot_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/snapshot"
+)
+
+func TestPlan(t *testing.T) {
+	s := snapshot.New("base")
+	s.Add("apt", []manager.PackageInfo{
+		{Name: "curl", Version: "7.81.0-1ubuntu1.15"},
+		{Name: "nano", Version: "6.2-1"},
+		{Name: "openssl", Version: "3.0.2-0ubuntu1.12"},
+		{Name: "vim", Version: "2:8.2.3995-1ubuntu2.16"},
+	})
+
+	installed := []manager.PackageInfo{
+		{Name: "curl", Version: "7.81.0-1ubuntu1.15"},
+		{Name: "htop", Version: "3.0.5-7build2"},
+		{Name: "openssl", Version: "3.0.2-0ubuntu1.14"},
+		{Name: "vim", Version: "2:8.2.3995-1ubuntu2.15"},
+	}
+
+	expectedOperations := []snapshot.Operation{
+		{PackageManager: "apt", Action: snapshot.ActionDelete, Package: "htop", CurrentVersion: "3.0.5-7build2"},
+		{PackageManager: "apt", Action: snapshot.ActionDowngrade, Package: "openssl", Version: "3.0.2-0ubuntu1.12", CurrentVersion: "3.0.2-0ubuntu1.14"},
+		{PackageManager: "apt", Action: snapshot.ActionInstall, Package: "nano", Version: "6.2-1"},
+		{PackageManager: "apt", Action: snapshot.ActionUpgrade, Package: "vim", Version: "2:8.2.3995-1ubuntu2.16", CurrentVersion: "2:8.2.3995-1ubuntu2.15"},
+	}
+
+	actualOperations := s.Plan("apt", installed)
+	if !reflect.DeepEqual(expectedOperations, actualOperations) {
+		t.Errorf("Plan() = %+v, want %+v", actualOperations, expectedOperations)
+	}
+
+	if actualOperations := s.Plan("snap", installed); actualOperations != nil {
+		t.Errorf("Plan() = %+v, want %+v", actualOperations, nil)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	s := snapshot.New("before-upgrade")
+	s.Add("apt", []manager.PackageInfo{{Name: "vim", Version: "2:8.2.3995-1ubuntu2.15", PackageManager: "apt"}})
+	if err := snapshot.Save(dir, s); err != nil {
+		t.Fatalf("Save() error = %+v", err)
+	}
+
+	loaded, err := snapshot.Load(dir, "before-upgrade")
+	if err != nil {
+		t.Fatalf("Load() error = %+v", err)
+	}
+	if !reflect.DeepEqual(s.Packages, loaded.Packages) || !s.Time.Equal(loaded.Time) {
+		t.Errorf("Load() = %+v, want %+v", loaded, s)
+	}
+
+	names, err := snapshot.List(dir)
+	if err != nil || !reflect.DeepEqual(names, []string{"before-upgrade"}) {
+		t.Errorf("List() = %+v, %+v, want %+v", names, err, []string{"before-upgrade"})
+	}
+
+	if err := snapshot.Save(dir, snapshot.New("../escape")); !errors.Is(err, snapshot.ErrInvalidName) {
+		t.Errorf("Save() error = %+v, want %+v", err, snapshot.ErrInvalidName)
+	}
+}
@@ -0,0 +1,27 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// StateDir returns the directory where syspkg keeps its persistent state, such as the transaction history and snapshots:
+// /var/lib/syspkg for root, and $XDG_STATE_HOME/syspkg (defaulting to ~/.local/state/syspkg) for other users.
+// On Windows, the state is kept in the user's local application data directory.
+func StateDir() string {
+	if runtime.GOOS == "windows" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			return filepath.Join(dir, "syspkg")
+		}
+	} else if os.Geteuid() == 0 {
+		return "/var/lib/syspkg"
+	}
+
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "syspkg")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "state", "syspkg")
+}
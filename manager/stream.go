@@ -0,0 +1,56 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// StreamLines runs cmd, and calls onLine with each line of its standard output as it is written, without keeping the
+// output in memory, so that package managers can parse the long outputs of commands listing every installed package
+// incrementally. If onLine returns an error, the command is killed and the error returned. The standard error of a
+// failed command is kept in its *exec.ExitError, as with cmd.Output.
+func StreamLines(cmd *exec.Cmd, onLine func(line string) error) error {
+	defer Release(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	if cmd.Stderr == nil {
+		cmd.Stderr = &stderr
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(stdout)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if line != "" {
+			if err := onLine(strings.TrimRight(line, "\r\n")); err != nil {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			_ = cmd.Wait()
+			return readErr
+		}
+	}
+
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && cmd.Stderr == &stderr {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return err
+}
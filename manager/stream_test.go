@@ -0,0 +1,45 @@
+package manager_test
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestStreamLines(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	var lines []string
+	err := manager.StreamLines(exec.Command("sh", "-c", "echo one; echo two; printf three"), func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamLines() error = %+v", err)
+	}
+	if want := []string{"one", "two", "three"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("StreamLines() lines = %+v, want %+v", lines, want)
+	}
+
+	// the command is stopped at the first error of the parser
+	errParse := errors.New("unexpected line")
+	lines = nil
+	err = manager.StreamLines(exec.Command("sh", "-c", "echo one; echo two; sleep 10"), func(line string) error {
+		lines = append(lines, line)
+		return errParse
+	})
+	if !errors.Is(err, errParse) || len(lines) != 1 {
+		t.Errorf("StreamLines() = %+v, lines %+v, want %+v after the first line", err, lines, errParse)
+	}
+
+	err = manager.StreamLines(exec.Command("sh", "-c", "echo failed >&2; exit 2"), func(string) error { return nil })
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || string(exitErr.Stderr) != "failed\n" {
+		t.Errorf("StreamLines() error = %+v, want an exit error with the stderr of the command", err)
+	}
+}
@@ -0,0 +1,194 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUpgradeDenied is returned, wrapped with the reason, for upgrades that the upgrade policy doesn't allow, such as
+// upgrades outside of the maintenance windows or of more packages than the maximum.
+var ErrUpgradeDenied = errors.New("upgrade denied by the upgrade policy")
+
+// UpgradePolicy restricts the upgrades of all packages, so that unattended upgrades leave pinned packages such as
+// the kernel alone, and only run when a disruption is acceptable. The zero value allows every upgrade.
+type UpgradePolicy struct {
+	// Exclude are the patterns of the names of packages that are never upgraded, such as "kernel*" or "docker-ce".
+	Exclude []*Pattern
+
+	// SecurityOnly only upgrades the packages fixing security issues.
+	SecurityOnly bool
+
+	// MaxPackages is the maximum number of packages upgraded at once; 0 means no maximum. Upgrades of more packages
+	// are denied rather than partially applied.
+	MaxPackages int
+
+	// Windows are the maintenance windows upgrades are allowed in. Upgrades are allowed at any time if empty.
+	Windows []MaintenanceWindow
+}
+
+// Selective reports whether the policy selects the packages to upgrade, so that the package manager must upgrade
+// specific packages rather than all of them.
+func (p *UpgradePolicy) Selective() bool {
+	return p != nil && (len(p.Exclude) > 0 || p.SecurityOnly)
+}
+
+// Allow returns an error wrapping ErrUpgradeDenied if upgrades are not allowed at time t, which is outside of all
+// the maintenance windows.
+func (p *UpgradePolicy) Allow(t time.Time) error {
+	if p == nil || len(p.Windows) == 0 {
+		return nil
+	}
+	for _, w := range p.Windows {
+		if w.Contains(t) {
+			return nil
+		}
+	}
+	windows := make([]string, len(p.Windows))
+	for i, w := range p.Windows {
+		windows[i] = w.String()
+	}
+	return fmt.Errorf("%w: %s is outside of the maintenance windows %s", ErrUpgradeDenied, t.Format("Mon 15:04"), strings.Join(windows, ", "))
+}
+
+// Select returns the packages of upgradable that the policy allows upgrading, and the ones it excludes. upgradable
+// must only list security updates when SecurityOnly is set. It returns an error wrapping ErrUpgradeDenied if more
+// than MaxPackages packages would be upgraded.
+func (p *UpgradePolicy) Select(upgradable []PackageInfo) ([]PackageInfo, []PackageInfo, error) {
+	if p == nil {
+		return upgradable, nil, nil
+	}
+	var selected, excluded []PackageInfo
+	for _, pkg := range upgradable {
+		if slices.ContainsFunc(p.Exclude, func(pattern *Pattern) bool { return pattern.Match(pkg.Name) }) {
+			excluded = append(excluded, pkg)
+		} else {
+			selected = append(selected, pkg)
+		}
+	}
+	if p.MaxPackages > 0 && len(selected) > p.MaxPackages {
+		return nil, excluded, fmt.Errorf("%w: %d packages to upgrade, more than the maximum of %d", ErrUpgradeDenied, len(selected), p.MaxPackages)
+	}
+	return selected, excluded, nil
+}
+
+// MaintenanceWindow is a recurring period of the week, in local time, during which upgrades are allowed.
+type MaintenanceWindow struct {
+	// Days are the days the window starts on; every day if empty.
+	Days []time.Weekday
+
+	// Start and End are the times of day the window starts and ends at, as durations since midnight. Windows
+	// ending before they start end on the next day, such as 22:00-02:00.
+	Start, End time.Duration
+}
+
+// weekdays are the abbreviated names of the days of the week, in the order of time.Weekday.
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseMaintenanceWindow parses a maintenance window: optional days, as a comma-separated list of days or ranges
+// of days such as "Sat,Sun" or "Mon-Fri", or "daily", followed by the times of day it starts and ends at, such as
+// "Sat 02:00-06:00" or "daily 22:00-02:00".
+func ParseMaintenanceWindow(s string) (MaintenanceWindow, error) {
+	var w MaintenanceWindow
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return w, fmt.Errorf("invalid maintenance window %q, expected e.g. \"Sat 02:00-06:00\"", s)
+	}
+
+	if len(fields) == 2 {
+		var err error
+		if w.Days, err = ParseWeekdays(fields[0]); err != nil {
+			return w, fmt.Errorf("%v in maintenance window %q", err, s)
+		}
+	}
+
+	start, end, ok := strings.Cut(fields[len(fields)-1], "-")
+	var err error
+	if w.Start, err = timeOfDay(start); err == nil && ok {
+		w.End, err = timeOfDay(end)
+	}
+	if err != nil || !ok || w.Start == w.End {
+		return w, fmt.Errorf("invalid times in maintenance window %q, expected e.g. 02:00-06:00", s)
+	}
+	return w, nil
+}
+
+// Contains reports whether the window contains time t.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	startsOn := func(day time.Weekday) bool { return len(w.Days) == 0 || slices.Contains(w.Days, day) }
+	if w.Start < w.End {
+		return startsOn(t.Weekday()) && now >= w.Start && now < w.End
+	}
+	// the window ends on the next day
+	return (startsOn(t.Weekday()) && now >= w.Start) || (startsOn((t.Weekday()+6)%7) && now < w.End)
+}
+
+// String returns the window in the syntax of ParseMaintenanceWindow.
+func (w MaintenanceWindow) String() string {
+	days := "daily"
+	if len(w.Days) > 0 {
+		names := make([]string, len(w.Days))
+		for i, day := range w.Days {
+			names[i] = day.String()[:3]
+		}
+		days = strings.Join(names, ",")
+	}
+	return fmt.Sprintf("%s %02d:%02d-%02d:%02d", days, int(w.Start.Hours()), int(w.Start.Minutes())%60, int(w.End.Hours()), int(w.End.Minutes())%60)
+}
+
+// ParseWeekdays parses days of the week, as a comma-separated list of days or ranges of days, abbreviated or not,
+// such as "Sat,Sun" or "Mon-Fri", or "daily" for every day, which returns no days.
+func ParseWeekdays(s string) ([]time.Weekday, error) {
+	if strings.EqualFold(s, "daily") {
+		return nil, nil
+	}
+	var days []time.Weekday
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, to := weekday(first), weekday(last)
+		if !isRange {
+			to = from
+		}
+		if from < 0 || to < 0 {
+			return nil, fmt.Errorf("invalid days %q, expected e.g. Sat,Sun, Mon-Fri or daily", s)
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			if !slices.Contains(days, day) {
+				days = append(days, day)
+			}
+			if day == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// weekday returns the day of the week of an abbreviated or full English name, or -1 if unknown.
+func weekday(name string) time.Weekday {
+	name = strings.ToLower(name)
+	for i, day := range weekdays {
+		if name == day || name == strings.ToLower(time.Weekday(i).String()) {
+			return time.Weekday(i)
+		}
+	}
+	return -1
+}
+
+// timeOfDay parses a time of day, such as 02:00 or 24:00, as a duration since midnight.
+func timeOfDay(s string) (time.Duration, error) {
+	hours, minutes, ok := strings.Cut(s, ":")
+	h, err := strconv.Atoi(hours)
+	if err != nil || !ok || len(minutes) != 2 || h < 0 || h > 24 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m > 59 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
@@ -0,0 +1,89 @@
+package manager_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected manager.MaintenanceWindow
+	}{
+		{"Sat 02:00-06:00", manager.MaintenanceWindow{Days: []time.Weekday{time.Saturday}, Start: 2 * time.Hour, End: 6 * time.Hour}},
+		{"fri-mon 23:30-24:00", manager.MaintenanceWindow{Days: []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday}, Start: 23*time.Hour + 30*time.Minute, End: 24 * time.Hour}},
+		{"daily 22:00-02:00", manager.MaintenanceWindow{Start: 22 * time.Hour, End: 2 * time.Hour}},
+		{"01:00-03:00", manager.MaintenanceWindow{Start: time.Hour, End: 3 * time.Hour}},
+	}
+	for _, tt := range tests {
+		actual, err := manager.ParseMaintenanceWindow(tt.input)
+		if err != nil {
+			t.Errorf("ParseMaintenanceWindow(%q) error = %+v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("ParseMaintenanceWindow(%q) = %+v, want %+v", tt.input, actual, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "weekends 02:00-06:00", "Sat 2:00", "Sat 02:00-25:00", "Sat 02:00-02:00", "Sat Sun 02:00-06:00"} {
+		if _, err := manager.ParseMaintenanceWindow(input); err == nil {
+			t.Errorf("ParseMaintenanceWindow(%q) error = nil, want an error", input)
+		}
+	}
+}
+
+func TestUpgradePolicyAllow(t *testing.T) {
+	weekend, _ := manager.ParseMaintenanceWindow("Sat,Sun 02:00-06:00")
+	nightly, _ := manager.ParseMaintenanceWindow("Fri 22:00-01:00")
+	policy := &manager.UpgradePolicy{Windows: []manager.MaintenanceWindow{weekend, nightly}}
+
+	// 2026-10-17 is a Saturday
+	for at, allowed := range map[string]bool{
+		"2026-10-17 02:00": true,
+		"2026-10-18 05:59": true,
+		"2026-10-17 06:00": false,
+		"2026-10-16 23:00": true,
+		"2026-10-17 00:30": true,
+		"2026-10-16 00:30": false,
+		"2026-10-19 03:00": false,
+	} {
+		now, _ := time.ParseInLocation("2006-01-02 15:04", at, time.Local)
+		if err := policy.Allow(now); (err == nil) != allowed {
+			t.Errorf("Allow(%s) error = %+v, want allowed %t", at, err, allowed)
+		} else if err != nil && !errors.Is(err, manager.ErrUpgradeDenied) {
+			t.Errorf("Allow(%s) error = %+v, want %+v", at, err, manager.ErrUpgradeDenied)
+		}
+	}
+
+	if err := (&manager.UpgradePolicy{}).Allow(time.Now()); err != nil {
+		t.Errorf("Allow() error = %+v, want upgrades allowed without maintenance windows", err)
+	}
+}
+
+func TestUpgradePolicySelect(t *testing.T) {
+	kernel, _ := manager.ParsePattern("linux-image-*", false)
+	docker, _ := manager.ParsePattern("docker-ce", false)
+	policy := &manager.UpgradePolicy{Exclude: []*manager.Pattern{kernel, docker}, MaxPackages: 2}
+	upgradable := []manager.PackageInfo{{Name: "linux-image-amd64"}, {Name: "openssl"}, {Name: "docker-ce"}, {Name: "docker-ce-cli"}}
+
+	expectedSelected := []manager.PackageInfo{{Name: "openssl"}, {Name: "docker-ce-cli"}}
+	expectedExcluded := []manager.PackageInfo{{Name: "linux-image-amd64"}, {Name: "docker-ce"}}
+	selected, excluded, err := policy.Select(upgradable)
+	if err != nil {
+		t.Fatalf("Select() error = %+v", err)
+	}
+	if !reflect.DeepEqual(selected, expectedSelected) || !reflect.DeepEqual(excluded, expectedExcluded) {
+		t.Errorf("Select() = %+v, %+v, want %+v, %+v", selected, excluded, expectedSelected, expectedExcluded)
+	}
+
+	// upgrades of more packages than the maximum are denied, rather than partially applied
+	policy.MaxPackages = 1
+	if _, _, err := policy.Select(upgradable); !errors.Is(err, manager.ErrUpgradeDenied) {
+		t.Errorf("Select() error = %+v, want %+v", err, manager.ErrUpgradeDenied)
+	}
+}
@@ -0,0 +1,167 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SignatureStatus is the status of the signature of an installed package.
+type SignatureStatus string
+
+// Constants representing the status of the signature of an installed package.
+const (
+	// SignatureUnknown is used when the package manager doesn't record the signatures of installed packages.
+	SignatureUnknown SignatureStatus = ""
+
+	// SignatureValid is used for packages signed with a trusted key.
+	SignatureValid SignatureStatus = "valid"
+
+	// SignatureInvalid is used for packages whose signature doesn't match their content, or was made with an untrusted key.
+	SignatureInvalid SignatureStatus = "invalid"
+
+	// SignatureUnsigned is used for packages installed without a signature, e.g. built locally.
+	SignatureUnsigned SignatureStatus = "unsigned"
+)
+
+// VerificationResult is the result of the verification of an installed package: its files that differ from the ones
+// it installed, and its signature.
+type VerificationResult struct {
+	// Name is the name of the package.
+	Name string `json:"name"`
+
+	// Version is the installed version of the package, if known.
+	Version string `json:"version,omitempty"`
+
+	// PackageManager is the name of the package manager the package is installed with.
+	PackageManager string `json:"package_manager"`
+
+	// ChecksumMismatches are the files whose content was modified, other than configuration files.
+	ChecksumMismatches []string `json:"checksum_mismatches,omitempty"`
+
+	// MissingFiles are the files that were removed.
+	MissingFiles []string `json:"missing_files,omitempty"`
+
+	// ModifiedConfigs are the configuration files whose content was modified, which is usually expected.
+	ModifiedConfigs []string `json:"modified_configs,omitempty"`
+
+	// Problems are the other differences found by the package manager, such as changed permissions or owners,
+	// e.g. "/usr/bin/sudo: mode, user changed".
+	Problems []string `json:"problems,omitempty"`
+
+	// Signature is the status of the signature of the package.
+	Signature SignatureStatus `json:"signature,omitempty"`
+}
+
+// Failed reports whether the package failed verification: some of its files are modified or missing, other than its
+// configuration files, or it is unsigned or its signature is invalid.
+func (r VerificationResult) Failed() bool {
+	return len(r.ChecksumMismatches) > 0 || len(r.MissingFiles) > 0 || len(r.Problems) > 0 ||
+		r.Signature == SignatureInvalid || r.Signature == SignatureUnsigned
+}
+
+// Summary describes the problems found in a few words, e.g. "1 checksum mismatch, 2 missing files",
+// or "no problems found".
+func (r VerificationResult) Summary() string {
+	var parts []string
+	for _, count := range []struct {
+		n        int
+		singular string
+		plural   string
+	}{
+		{len(r.ChecksumMismatches), "checksum mismatch", "checksum mismatches"},
+		{len(r.MissingFiles), "missing file", "missing files"},
+		{len(r.ModifiedConfigs), "modified config file", "modified config files"},
+		{len(r.Problems), "other problem", "other problems"},
+	} {
+		switch {
+		case count.n == 1:
+			parts = append(parts, "1 "+count.singular)
+		case count.n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.plural))
+		}
+	}
+	if r.Signature == SignatureInvalid || r.Signature == SignatureUnsigned {
+		parts = append(parts, string(r.Signature))
+	}
+	if len(parts) == 0 {
+		return "no problems found"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// verifyAttributes are the names of the attributes checked by rpm -V and dpkg -V, by their position in the flags.
+var verifyAttributes = []string{"size", "mode", "digest", "device", "link", "user", "group", "mtime", "capabilities"}
+
+// ParseVerifyOutput parses the output of `rpm -V` and `dpkg -V` commands, which use the same format, and adds the
+// files that differ to result. Each line has 9 flags telling which attributes of the file changed, such as its size
+// (S), mode (M) or digest (5), or "missing", then "c" for configuration files, then the path. Modification times
+// alone are not reported, since they change when files are touched.
+// Example msg:
+//
+//	??5??????   /usr/bin/lessecho
+//	missing     /usr/bin/lesspipe
+//	S.5....T.  c /etc/ssh/sshd_config
+//	.M.......    /usr/bin/sudo
+func ParseVerifyOutput(msg string, result *VerificationResult) {
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		// rpm and dpkg separate the flags, the attribute marker and the path with different spaces
+		i := strings.Index(line, " /")
+		if i < 9 {
+			continue
+		}
+		flags, path := line[:9], line[i+1:]
+		config := strings.TrimSpace(line[9:i]) == "c"
+
+		if strings.HasPrefix(flags, "missing") {
+			result.MissingFiles = append(result.MissingFiles, path)
+			continue
+		}
+
+		var changed []string
+		for i, flag := range flags {
+			if flag != '.' && flag != '?' && verifyAttributes[i] != "mtime" {
+				changed = append(changed, verifyAttributes[i])
+			}
+		}
+		switch {
+		case len(changed) == 0:
+			continue
+		case config:
+			result.ModifiedConfigs = append(result.ModifiedConfigs, path)
+		case strings.ContainsAny(flags, "S5"):
+			result.ChecksumMismatches = append(result.ChecksumMismatches, path)
+		default:
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: %s changed", path, strings.Join(changed, ", ")))
+		}
+	}
+}
+
+// FilterVerificationResults returns the results whose package name is one of the given names, or all results if no
+// names are given, for package managers verifying all packages at once.
+func FilterVerificationResults(results []VerificationResult, names []string) []VerificationResult {
+	if len(names) == 0 {
+		return results
+	}
+
+	var filtered []VerificationResult
+	for _, result := range results {
+		if slices.Contains(names, result.Name) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// VerifiedPaths returns the paths of the files listed in the output of `rpm -V` and `dpkg -V` commands, which don't
+// tell the packages owning them when all packages are verified.
+func VerifiedPaths(msg string) []string {
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+		if i := strings.Index(line, " /"); i >= 9 {
+			paths = append(paths, line[i+1:])
+		}
+	}
+	return paths
+}
@@ -0,0 +1,65 @@
+package manager_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestParseVerifyOutput(t *testing.T) {
+	var inputParseVerifyOutput string = strings.Join([]string{
+		// dpkg -V
+		`??5??????   /usr/bin/lessecho`,
+		`missing     /usr/bin/lesspipe`,
+		`??5?????? c /etc/default/ssh`,
+		// rpm -V
+		`S.5....T.  c /etc/ssh/sshd_config`,
+		`.M.......    /usr/bin/sudo`,
+		`.......T.    /usr/share/doc/sudo/README`,
+		`missing   c /etc/sudo.conf`,
+		`Unsatisfied dependencies for sudo-1.9.15p5-1.1.x86_64:`,
+	}, "\n")
+
+	expectedResult := manager.VerificationResult{
+		Name:               "sudo",
+		ChecksumMismatches: []string{"/usr/bin/lessecho"},
+		MissingFiles:       []string{"/usr/bin/lesspipe", "/etc/sudo.conf"},
+		ModifiedConfigs:    []string{"/etc/default/ssh", "/etc/ssh/sshd_config"},
+		Problems:           []string{"/usr/bin/sudo: mode changed"},
+	}
+
+	actualResult := manager.VerificationResult{Name: "sudo"}
+	manager.ParseVerifyOutput(inputParseVerifyOutput, &actualResult)
+	if !reflect.DeepEqual(expectedResult, actualResult) {
+		t.Errorf("ParseVerifyOutput() = %+v, want %+v", actualResult, expectedResult)
+	}
+
+	expectedPaths := []string{"/usr/bin/lessecho", "/usr/bin/lesspipe", "/etc/default/ssh", "/etc/ssh/sshd_config",
+		"/usr/bin/sudo", "/usr/share/doc/sudo/README", "/etc/sudo.conf"}
+	if paths := manager.VerifiedPaths(inputParseVerifyOutput); !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("VerifiedPaths() = %+v, want %+v", paths, expectedPaths)
+	}
+}
+
+func TestVerificationResult(t *testing.T) {
+	tests := []struct {
+		result  manager.VerificationResult
+		failed  bool
+		summary string
+	}{
+		{manager.VerificationResult{Signature: manager.SignatureValid}, false, "no problems found"},
+		{manager.VerificationResult{ModifiedConfigs: []string{"/etc/ssh/sshd_config"}}, false, "1 modified config file"},
+		{manager.VerificationResult{MissingFiles: []string{"/a", "/b"}, ChecksumMismatches: []string{"/c"}}, true, "1 checksum mismatch, 2 missing files"},
+		{manager.VerificationResult{Signature: manager.SignatureUnsigned}, true, "unsigned"},
+	}
+	for _, tt := range tests {
+		if failed := tt.result.Failed(); failed != tt.failed {
+			t.Errorf("%+v.Failed() = %v, want %v", tt.result, failed, tt.failed)
+		}
+		if summary := tt.result.Summary(); summary != tt.summary {
+			t.Errorf("%+v.Summary() = %q, want %q", tt.result, summary, tt.summary)
+		}
+	}
+}
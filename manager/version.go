@@ -0,0 +1,100 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+import "strings"
+
+// CompareVersions compares two package versions, and returns -1, 0 or 1 if a is older than, equal to, or newer than b.
+// It implements the Debian version comparison algorithm, which also gives sensible results for the version schemes of
+// most other package managers: an optional numeric epoch ("1:"), followed by alternating runs of non-digits, compared
+// lexically with letters sorting before other characters and "~" sorting before anything (even the end of the version),
+// and runs of digits, compared numerically.
+func CompareVersions(a, b string) int {
+	epochA, restA := splitEpoch(a)
+	epochB, restB := splitEpoch(b)
+	if c := compareNumeric(epochA, epochB); c != 0 {
+		return c
+	}
+	return compareFragments(restA, restB)
+}
+
+// splitEpoch splits "epoch:version" into its epoch (or "0") and version.
+func splitEpoch(v string) (string, string) {
+	if idx := strings.Index(v, ":"); idx > 0 && strings.Trim(v[:idx], "0123456789") == "" {
+		return v[:idx], v[idx+1:]
+	}
+	return "0", v
+}
+
+// compareFragments compares two versions made of alternating runs of non-digits and digits.
+func compareFragments(a, b string) int {
+	for a != "" || b != "" {
+		var na, nb string
+		na, a = splitRun(a, false)
+		nb, b = splitRun(b, false)
+		if c := compareNonDigits(na, nb); c != 0 {
+			return c
+		}
+
+		na, a = splitRun(a, true)
+		nb, b = splitRun(b, true)
+		if c := compareNumeric(na, nb); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// splitRun splits the leading run of digits (or non-digits) from s.
+func splitRun(s string, digits bool) (string, string) {
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9') == digits {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareNonDigits compares two runs of non-digits, character by character, using order.
+func compareNonDigits(a, b string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var ca, cb int
+		if i < len(a) {
+			ca = order(a[i])
+		}
+		if i < len(b) {
+			cb = order(b[i])
+		}
+		if ca != cb {
+			if ca < cb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// order returns the sort weight of a version character: "~" sorts before the end of the string,
+// which sorts before letters, which sort before other characters.
+func order(c byte) int {
+	switch {
+	case c == '~':
+		return -1
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+// compareNumeric compares two runs of digits numerically, without overflowing on long runs.
+func compareNumeric(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
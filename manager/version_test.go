@@ -0,0 +1,31 @@
+package manager_test
+
+import (
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"2:8.2.3995-1ubuntu2.15", "2:8.2.3995-1ubuntu2.16", -1},
+		{"1:1.0", "2.0", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0a", "1.0", 1},
+		{"1.0a", "1.0+", -1},
+		{"9.0.2127-r0", "9.0.2127-r1", -1},
+		{"007", "7", 0},
+		{"20231231235959999999999", "20231231235959999999998", 1},
+	}
+	for _, tt := range tests {
+		if got := manager.CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
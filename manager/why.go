@@ -0,0 +1,66 @@
+// Package manager provides utilities for managing the application.
+package manager
+
+// WhyInstalled explains why the installed package pkg is present: it returns the chains of installed packages, each
+// depending on the next one, from a package installed explicitly, such as vim, to pkg, such as
+// ["vim", "vim-runtime", "libgpm2"]. A chain of pkg alone means pkg is installed explicitly.
+//
+// The chains are found by following reverseDependencies, returning the installed packages directly depending on a
+// package, up to maxDepth levels. The packages installed explicitly are the ones of explicit, or, if explicit is nil,
+// for package managers not telling them, the ones no other installed package depends on. Only the shortest chains are
+// returned, unless all is set. No chains are returned for packages that nothing installed explicitly depends on, such
+// as the dependencies left behind by removed packages.
+func WhyInstalled(pkg string, explicit map[string]bool, reverseDependencies func(string) ([]PackageInfo, error), maxDepth int, all bool) ([][]string, error) {
+	if explicit != nil && explicit[pkg] {
+		return [][]string{{pkg}}, nil
+	}
+
+	// next is the package each found package depends on, on the way to pkg
+	next := make(map[string]string)
+	seen := map[string]bool{pkg: true}
+	var roots []string
+
+	level := []string{pkg}
+	for depth := 0; depth < maxDepth && len(level) > 0; depth++ {
+		var nextLevel []string
+		for _, name := range level {
+			dependents, err := reverseDependencies(name)
+			if err != nil {
+				return nil, err
+			}
+			if explicit == nil && len(dependents) == 0 {
+				roots = append(roots, name)
+				continue
+			}
+
+			for _, dependent := range dependents {
+				if seen[dependent.Name] {
+					continue
+				}
+				seen[dependent.Name] = true
+				next[dependent.Name] = name
+
+				if explicit != nil && explicit[dependent.Name] {
+					roots = append(roots, dependent.Name)
+				} else {
+					nextLevel = append(nextLevel, dependent.Name)
+				}
+			}
+		}
+		if len(roots) > 0 && !all {
+			break
+		}
+		level = nextLevel
+	}
+
+	chains := make([][]string, 0, len(roots))
+	for _, root := range roots {
+		chain := []string{root}
+		for name := root; name != pkg; {
+			name = next[name]
+			chain = append(chain, name)
+		}
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
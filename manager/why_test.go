@@ -0,0 +1,57 @@
+package manager_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+func TestWhyInstalled(t *testing.T) {
+	// vim -> vim-runtime -> libgpm2, emacs -> libgpm2, mc -> libgpm2, with mc depending on itself through mc-data
+	dependents := map[string][]string{
+		"libgpm2":     {"vim-runtime", "emacs", "mc"},
+		"vim-runtime": {"vim"},
+		"mc":          {"mc-data"},
+		"mc-data":     {"mc"},
+	}
+	reverseDependencies := func(pkg string) ([]manager.PackageInfo, error) {
+		var packages []manager.PackageInfo
+		for _, name := range dependents[pkg] {
+			packages = append(packages, manager.PackageInfo{Name: name})
+		}
+		return packages, nil
+	}
+
+	tests := []struct {
+		pkg      string
+		explicit map[string]bool
+		all      bool
+		expected [][]string
+	}{
+		{"libgpm2", map[string]bool{"vim": true, "emacs": true}, false, [][]string{{"emacs", "libgpm2"}}},
+		{"libgpm2", map[string]bool{"vim": true, "emacs": true}, true, [][]string{{"emacs", "libgpm2"}, {"vim", "vim-runtime", "libgpm2"}}},
+		{"vim", map[string]bool{"vim": true}, false, [][]string{{"vim"}}},
+		{"mc-data", map[string]bool{"vim": true}, false, [][]string{}},
+		// without explicit packages, the ones nothing depends on
+		{"libgpm2", nil, false, [][]string{{"emacs", "libgpm2"}}},
+		{"vim-runtime", nil, false, [][]string{{"vim", "vim-runtime"}}},
+		{"vim", nil, false, [][]string{{"vim"}}},
+	}
+	for _, tt := range tests {
+		actual, err := manager.WhyInstalled(tt.pkg, tt.explicit, reverseDependencies, 10, tt.all)
+		if err != nil {
+			t.Errorf("WhyInstalled(%s) error = %+v", tt.pkg, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("WhyInstalled(%s, %v, all=%v) = %+v, want %+v", tt.pkg, tt.explicit, tt.all, actual, tt.expected)
+		}
+	}
+
+	failing := func(string) ([]manager.PackageInfo, error) { return nil, errors.New("exit status 100") }
+	if _, err := manager.WhyInstalled("libgpm2", nil, failing, 10, false); err == nil {
+		t.Errorf("WhyInstalled() error = nil, want an error")
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !windows
+
+package winget
+
+// supported reports whether winget can exist on this platform; winget is only available on Windows.
+const supported = false
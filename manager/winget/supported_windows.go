@@ -0,0 +1,4 @@
+package winget
+
+// supported reports whether winget can exist on this platform.
+const supported = true
@@ -0,0 +1,261 @@
+// Package winget provides a package manager implementation for Windows
+// using winget as the underlying package management tool.
+package winget
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Exit codes returned by winget, as HRESULT values. See the winget documentation for the complete list.
+const (
+	ExitOK                    uint32 = 0x0
+	ExitInternalError         uint32 = 0x8A150001
+	ExitInvalidArguments      uint32 = 0x8A150002
+	ExitCommandFailed         uint32 = 0x8A150003
+	ExitNoApplicationsFound   uint32 = 0x8A150014
+	ExitMultipleApplications  uint32 = 0x8A150015
+	ExitUpdateNotApplicable   uint32 = 0x8A15002B
+	ExitPackageAlreadyInstall uint32 = 0x8A150061
+)
+
+// Errors returned for the winget exit codes.
+var (
+	ErrInternal         = errors.New("winget: internal error")
+	ErrInvalidArguments = errors.New("winget: invalid command line arguments")
+	ErrCommandFailed    = errors.New("winget: the command failed")
+	ErrNoPackagesFound  = errors.New("winget: no package found matching the input criteria")
+	ErrMultiplePackages = errors.New("winget: multiple packages found matching the input criteria, please use a package identifier")
+)
+
+// CheckExitError maps the exit code of a failed winget command to a descriptive error.
+// Exit codes reporting that there was nothing to do (the package is already installed, or has no applicable update)
+// are not treated as errors.
+func CheckExitError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return err
+	}
+
+	switch code := uint32(exitErr.ExitCode()); code {
+	case ExitUpdateNotApplicable, ExitPackageAlreadyInstall:
+		return nil
+	case ExitInternalError:
+		return ErrInternal
+	case ExitInvalidArguments:
+		return ErrInvalidArguments
+	case ExitCommandFailed:
+		return ErrCommandFailed
+	case ExitNoApplicationsFound:
+		return ErrNoPackagesFound
+	case ExitMultipleApplications:
+		return ErrMultiplePackages
+	default:
+		return fmt.Errorf("winget: exit code 0x%08X: %w", code, err)
+	}
+}
+
+// ParseListOutput parses the output of `winget list` and `winget upgrade` commands
+// and returns a list of installed packages. Packages with a newer version available are marked as upgradable.
+// The columns are Name, Id, Version, and optionally Available and Source; headers are translated, so they are identified by position.
+// Example msg:
+//
+//	Name               Id                     Version      Available    Source
+//	-----------------------------------------------------------------------------
+//	Git                Git.Git                2.41.0       2.42.0       winget
+//	Microsoft Edge     Microsoft.Edge         115.0.1901.… 116.0.1938.… winget
+//	7-Zip 22.01 (x64)  7zip.7zip              22.01                     winget
+//	2 upgrades available.
+func ParseListOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, table := range parseTables(msg) {
+		// the Available and Source columns are only shown when at least one row has a value for them
+		availableColumn := -1
+		switch table.columns {
+		case 5:
+			availableColumn = 3
+		case 4:
+			if isVersionColumn(table.rows, 3) {
+				availableColumn = 3
+			}
+		}
+
+		for _, row := range table.rows {
+			packageInfo := manager.PackageInfo{
+				Name:           row[0],
+				Version:        row[2],
+				Status:         manager.PackageStatusInstalled,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"id": row[1]},
+			}
+			if availableColumn >= 0 && row[availableColumn] != "" {
+				packageInfo.NewVersion = row[availableColumn]
+				packageInfo.Status = manager.PackageStatusUpgradable
+			}
+			if source := row[table.columns-1]; table.columns > 3 && table.columns-1 != availableColumn && source != "" {
+				packageInfo.Category, packageInfo.Origin = source, source
+			}
+			packages = append(packages, packageInfo)
+		}
+	}
+
+	if opts != nil && opts.Verbose {
+		opts.Log().Debug("Found packages", "package_manager", pm, "count", len(packages))
+	}
+	return packages
+}
+
+// ParseSearchOutput parses the output of `winget search keyword` command
+// and returns a list of packages that match the search query.
+// The columns are Name, Id, Version, optionally Match, and Source; headers are translated, so they are identified by position.
+// Example msg:
+//
+//	Name          Id                 Version  Match         Source
+//	----------------------------------------------------------------
+//	Git           Git.Git            2.42.0                 winget
+//	GitHub CLI    GitHub.cli         2.32.1   Tag: git      winget
+func ParseSearchOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, table := range parseTables(msg) {
+		for _, row := range table.rows {
+			packageInfo := manager.PackageInfo{
+				Name:           row[0],
+				NewVersion:     row[2],
+				Status:         manager.PackageStatusAvailable,
+				PackageManager: pm,
+				AdditionalData: map[string]string{"id": row[1]},
+			}
+			if table.columns > 3 {
+				packageInfo.Category = row[table.columns-1]
+				packageInfo.Origin = packageInfo.Category
+			}
+			packages = append(packages, packageInfo)
+		}
+	}
+
+	return packages
+}
+
+// table is a table printed by winget, with its rows split into columns.
+type table struct {
+	columns int
+	rows    [][]string
+}
+
+// parseTables splits the tables printed by winget into their columns.
+// Each table has a header line, followed by a line of dashes, and rows aligned with the header.
+// Column boundaries are taken from the positions of the header words, as winget pads columns with spaces,
+// and lines that are not aligned with the columns (such as the "2 upgrades available." summary) are skipped.
+func parseTables(msg string) []table {
+	var tables []table
+	var starts []int
+	var previous string
+
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimRight(line, "\r")
+		// winget draws a progress spinner with carriage returns before printing its output
+		if idx := strings.LastIndex(line, "\r"); idx >= 0 {
+			line = line[idx+1:]
+		}
+
+		if line != "" && strings.Trim(line, "-") == "" {
+			starts = columnStarts(previous)
+			tables = append(tables, table{columns: len(starts)})
+			previous = line
+			continue
+		}
+		previous = line
+
+		if len(tables) == 0 || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		row, ok := splitColumns(line, starts)
+		if !ok || row[1] == "" {
+			continue
+		}
+		current := &tables[len(tables)-1]
+		current.rows = append(current.rows, row)
+	}
+
+	return tables
+}
+
+// columnStarts returns the positions (in runes) at which the words of the header line start.
+func columnStarts(header string) []int {
+	var starts []int
+	inWord := false
+	for i, r := range []rune(header) {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		if !inWord {
+			starts = append(starts, i)
+			inWord = true
+		}
+	}
+	return starts
+}
+
+// splitColumns splits a table row at the given column positions (in runes) and trims the values.
+// It returns false if the line is not aligned with the columns, i.e. if a value overlaps the start of the next column.
+func splitColumns(line string, starts []int) ([]string, bool) {
+	runes := []rune(line)
+	columns := make([]string, len(starts))
+	for i, start := range starts {
+		if start >= len(runes) {
+			break
+		}
+		if start > 0 && !unicode.IsSpace(runes[start-1]) {
+			return nil, false
+		}
+		end := len(runes)
+		if i+1 < len(starts) && starts[i+1] < end {
+			end = starts[i+1]
+		}
+		columns[i] = strings.TrimSpace(string(runes[start:end]))
+	}
+	return columns, true
+}
+
+// isVersionColumn reports whether the non-empty values of the given column look like versions rather than source names.
+func isVersionColumn(rows [][]string, column int) bool {
+	for _, row := range rows {
+		if value := row[column]; value != "" && !unicode.IsDigit([]rune(value)[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterPackages returns the packages whose identifier is one of the given identifiers, or all packages if none are given.
+func filterPackages(packages []manager.PackageInfo, ids []string) []manager.PackageInfo {
+	if len(ids) == 0 {
+		return packages
+	}
+
+	wanted := make(map[string]bool)
+	for _, id := range ids {
+		wanted[strings.ToLower(id)] = true
+	}
+
+	var filtered []manager.PackageInfo
+	for _, pkg := range packages {
+		if wanted[strings.ToLower(pkg.AdditionalData["id"])] {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
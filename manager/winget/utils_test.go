@@ -0,0 +1,119 @@
+package winget_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/winget"
+)
+
+func TestParseListOutput(t *testing.T) {
+	var inputParseListOutput string = strings.Join([]string{
+		"\r   - \r   \\ \r                                                                                                                        \rName               Id                     Version      Available    Source",
+		`-----------------------------------------------------------------------------`,
+		`Git                Git.Git                2.41.0       2.42.0       winget`,
+		`7-Zip 22.01 (x64)  7zip.7zip              22.01                     winget`,
+		`Contoso Tool       ARP\Machine\X64\Cont…  1.0`,
+		`1 upgrade available.`,
+	}, "\r\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "Git",
+			Version:        "2.41.0",
+			NewVersion:     "2.42.0",
+			Status:         manager.PackageStatusUpgradable,
+			Category:       "winget",
+			Origin:         "winget",
+			PackageManager: "winget",
+			AdditionalData: map[string]string{"id": "Git.Git"},
+		},
+		{
+			Name:           "7-Zip 22.01 (x64)",
+			Version:        "22.01",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "winget",
+			Origin:         "winget",
+			PackageManager: "winget",
+			AdditionalData: map[string]string{"id": "7zip.7zip"},
+		},
+		{
+			Name:           "Contoso Tool",
+			Version:        "1.0",
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: "winget",
+			AdditionalData: map[string]string{"id": `ARP\Machine\X64\Cont…`},
+		},
+	}
+
+	actualPackageInfo := winget.ParseListOutput(inputParseListOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListOutputTranslated(t *testing.T) {
+	// a German system, without the Available column
+	var inputParseListOutput string = strings.Join([]string{
+		`Name               ID                     Version      Quelle`,
+		`--------------------------------------------------------------`,
+		`Git                Git.Git                2.42.0       winget`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "Git",
+			Version:        "2.42.0",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "winget",
+			Origin:         "winget",
+			PackageManager: "winget",
+			AdditionalData: map[string]string{"id": "Git.Git"},
+		},
+	}
+
+	actualPackageInfo := winget.ParseListOutput(inputParseListOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseSearchOutput(t *testing.T) {
+	var inputParseSearchOutput string = strings.Join([]string{
+		`Name          Id                 Version  Match         Source`,
+		`----------------------------------------------------------------`,
+		`Git           Git.Git            2.42.0                 winget`,
+		`GitHub CLI    GitHub.cli         2.32.1   Tag: git      winget`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "Git",
+			NewVersion:     "2.42.0",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "winget",
+			Origin:         "winget",
+			PackageManager: "winget",
+			AdditionalData: map[string]string{"id": "Git.Git"},
+		},
+		{
+			Name:           "GitHub CLI",
+			NewVersion:     "2.32.1",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "winget",
+			Origin:         "winget",
+			PackageManager: "winget",
+			AdditionalData: map[string]string{"id": "GitHub.cli"},
+		},
+	}
+
+	actualPackageInfo := winget.ParseSearchOutput(inputParseSearchOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseSearchOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
@@ -0,0 +1,335 @@
+// Package winget provides an implementation of the syspkg manager interface for the Windows Package Manager (winget).
+// It provides a Go (golang) API interface for interacting with winget.
+// This package is a wrapper around the winget command line tool.
+//
+// winget is the package manager of Windows 10 and later. It installs applications from the winget community repository
+// and the Microsoft Store, and also lists applications installed by other means (from "Apps & features").
+// winget has no machine-readable output, and its table headers are translated into the language of the system,
+// so its output is parsed by column position rather than by header names.
+// winget reports failures with HRESULT exit codes, which are mapped to the errors defined by this package.
+//
+// For more information about winget, visit:
+// - https://learn.microsoft.com/en-us/windows/package-manager/winget/
+// - https://github.com/microsoft/winget-cli/blob/master/doc/windows/package-manager/winget/returnCodes.md
+//
+// This package is part of the syspkg library.
+package winget
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "winget"
+
+// Constants used for winget commands
+const (
+	ArgsAcceptSourceAgreements  string = "--accept-source-agreements"
+	ArgsAcceptPackageAgreements string = "--accept-package-agreements"
+	ArgsDisableInteractivity    string = "--disable-interactivity"
+	ArgsSilent                  string = "--silent"
+	ArgsExact                   string = "--exact"
+	ArgsID                      string = "--id"
+	ArgsAll                     string = "--all"
+	ArgsVersion                 string = "--version"
+)
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for winget.
+// winget has no environment variables of its own; non-interactive mode is set with ArgsDisableInteractivity.
+var ENV_NonInteractive []string = []string{}
+
+// PackageManager implements the manager.PackageManager interface for the Windows Package Manager.
+type PackageManager struct{}
+
+// IsAvailable checks if the winget package manager is available on the system.
+// It always returns false on other platforms than Windows.
+func (a *PackageManager) IsAvailable() bool {
+	if !supported {
+		return false
+	}
+	_, err := exec.LookPath(pm)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the winget package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by winget.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:           true,
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+	}
+}
+
+// Install installs the provided packages, given by their winget package identifier (e.g. "Git.Git"), using winget.
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	var packages []manager.PackageInfo
+	for _, pkg := range pkgs {
+		spec, err := manager.ParsePackageSpec(pkg)
+		if err != nil {
+			return nil, err
+		}
+
+		// winget install has no dry-run mode, so only report what would be installed
+		if opts.DryRun {
+			found, err := a.query("search", spec.Name, opts)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range found {
+				p.Version = p.NewVersion
+				p.NewVersion = ""
+				p.Status = manager.PackageStatusInstalled
+				packages = append(packages, p)
+			}
+			continue
+		}
+
+		args := []string{"install", ArgsID, spec.Name, ArgsExact, ArgsAcceptPackageAgreements, ArgsAcceptSourceAgreements}
+		if spec.Version != "" {
+			args = append(args, ArgsVersion, spec.Version)
+		}
+		if err := a.run(args, opts); err != nil {
+			return nil, err
+		}
+		if opts.Interactive {
+			continue
+		}
+
+		installed, err := a.query("list", spec.Name, opts)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, installed...)
+	}
+
+	return packages, nil
+}
+
+// Delete uninstalls the provided packages, given by their winget package identifier, using winget.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	var packages []manager.PackageInfo
+	for _, pkg := range pkgs {
+		// look up the installed version first, as it can't be queried after removal
+		installed, err := a.query("list", pkg, opts)
+		if err != nil {
+			return nil, err
+		}
+		if !opts.DryRun {
+			args := []string{"uninstall", ArgsID, pkg, ArgsExact, ArgsAcceptSourceAgreements}
+			if err := a.run(args, opts); err != nil {
+				return nil, err
+			}
+		}
+		for _, p := range installed {
+			p.NewVersion = ""
+			p.Status = manager.PackageStatusAvailable
+			packages = append(packages, p)
+		}
+	}
+
+	if opts.Interactive {
+		return nil, nil
+	}
+	return packages, nil
+}
+
+// Refresh updates the package sources of winget.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+	return a.run([]string{"source", "update"}, opts)
+}
+
+// Find searches for packages matching the provided keywords using winget.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "search", strings.Join(keywords, " "), ArgsAcceptSourceAgreements, ArgsDisableInteractivity)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err); err == ErrNoPackagesFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ParseSearchOutput(string(out), opts), nil
+}
+
+// ListInstalled lists all installed applications known to winget,
+// including the ones not installed by winget itself.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "list", ArgsAcceptSourceAgreements, ArgsDisableInteractivity)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err); err == ErrNoPackagesFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ParseListOutput(string(out), opts), nil
+}
+
+// ListUpgradable lists all installed applications that have a newer version available using winget.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, "upgrade", ArgsAcceptSourceAgreements, ArgsDisableInteractivity)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err); err == ErrNoPackagesFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ParseListOutput(string(out), opts), nil
+}
+
+// Upgrade upgrades the provided packages, given by their winget package identifier, using winget.
+// If no packages are given, all upgradable packages are upgraded.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	upgradable, err := a.ListUpgradable(opts)
+	if err != nil {
+		return nil, err
+	}
+	upgradable = filterPackages(upgradable, pkgs)
+	if opts.DryRun || len(upgradable) == 0 {
+		return upgradable, nil
+	}
+
+	var commands [][]string
+	if len(pkgs) == 0 {
+		commands = append(commands, []string{"upgrade", ArgsAll, ArgsAcceptPackageAgreements, ArgsAcceptSourceAgreements})
+	}
+	for _, pkg := range pkgs {
+		commands = append(commands, []string{"upgrade", ArgsID, pkg, ArgsExact, ArgsAcceptPackageAgreements, ArgsAcceptSourceAgreements})
+	}
+
+	for _, args := range commands {
+		opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+		if err := a.run(args, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Interactive {
+		return nil, nil
+	}
+	return upgradable, nil
+}
+
+// UpgradeAll upgrades all upgradable packages using winget.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// GetPackageInfo retrieves information about the specified package, given by its winget package identifier,
+// along with the installed version, if any.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	info := manager.PackageInfo{Name: pkg, Status: manager.PackageStatusUnknown, PackageManager: pm}
+
+	found, err := a.query("search", pkg, opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	if len(found) > 0 {
+		info = found[0]
+	}
+
+	installed, err := a.query("list", pkg, opts)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	if len(installed) > 0 {
+		// winget list only reports a newer version for upgradable packages
+		info.Name = installed[0].Name
+		info.Version = installed[0].Version
+		info.NewVersion = installed[0].NewVersion
+		info.Status = installed[0].Status
+	}
+	return info, nil
+}
+
+// query looks up a single package by its exact winget package identifier with the given command ("list" or "search").
+func (a *PackageManager) query(command string, id string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, command, ArgsID, id, ArgsExact, ArgsAcceptSourceAgreements, ArgsDisableInteractivity)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err); err == ErrNoPackagesFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if command == "search" {
+		return ParseSearchOutput(string(out), nil), nil
+	}
+	return ParseListOutput(string(out), nil), nil
+}
+
+// run runs winget with the given arguments, either attached to the terminal in interactive mode,
+// or silently and non-interactively, logging the output in verbose mode.
+func (a *PackageManager) run(args []string, opts *manager.Options) error {
+	if opts.Interactive {
+		cmd := manager.Command(opts, pm, args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return CheckExitError(manager.Run(cmd))
+	}
+
+	args = append(args, ArgsDisableInteractivity)
+	if args[0] != "source" {
+		args = append(args, ArgsSilent)
+	}
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	out, err := manager.Output(cmd)
+	if opts.Verbose {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return CheckExitError(err)
+}
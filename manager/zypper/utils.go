@@ -0,0 +1,797 @@
+// Package zypper provides a package manager implementation for openSUSE and SUSE Linux Enterprise
+// using zypper as the underlying package management tool.
+package zypper
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// Exit codes returned by zypper, as documented in zypper(8).
+// Codes 100 and above are informational: the command itself succeeded (or partially succeeded),
+// but zypper wants to tell the caller something about the result.
+const (
+	ExitOK                 int = 0
+	ExitBug                int = 1
+	ExitSyntaxError        int = 2
+	ExitInvalidArgs        int = 3
+	ExitZyppError          int = 4
+	ExitPrivileges         int = 5
+	ExitNoRepos            int = 6
+	ExitZyppLocked         int = 7
+	ExitCommitError        int = 8
+	ExitInfUpdateNeeded    int = 100
+	ExitInfSecUpdateNeeded int = 101
+	ExitInfRebootNeeded    int = 102
+	ExitInfRestartNeeded   int = 103
+	ExitInfCapNotFound     int = 104
+	ExitOnSignal           int = 105
+	ExitInfReposSkipped    int = 106
+	ExitInfRPMScriptFailed int = 107
+)
+
+// Errors returned for the non-informational zypper exit codes.
+var (
+	ErrPrivileges    = errors.New("zypper: insufficient privileges, please run as root")
+	ErrNoRepos       = errors.New("zypper: no repositories are defined")
+	ErrLocked        = fmt.Errorf("zypper: %w", manager.ErrLocked)
+	ErrCommitFailed  = errors.New("zypper: the transaction failed to commit")
+	ErrNotFound      = errors.New("zypper: some of the requested packages were not found")
+	ErrInterrupted   = errors.New("zypper: interrupted by a signal")
+	ErrInvalidSyntax = errors.New("zypper: invalid command syntax or arguments")
+)
+
+// CheckExitError maps the exit code of a failed zypper command to a descriptive error.
+// Informational exit codes, which zypper uses to report a successful command with
+// additional information (updates or reboot needed, some repositories skipped, ...), are not treated as errors.
+func CheckExitError(err error, opts *manager.Options) error {
+	if err == nil {
+		return nil
+	}
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return err
+	}
+
+	switch exitErr.ExitCode() {
+	case ExitInfUpdateNeeded, ExitInfSecUpdateNeeded, ExitInfRebootNeeded, ExitInfRestartNeeded, ExitInfReposSkipped:
+		return nil
+	case ExitInfRPMScriptFailed:
+		opts.Log().Warn("The transaction succeeded but some RPM scriptlets failed", "package_manager", pm)
+		return nil
+	case ExitSyntaxError, ExitInvalidArgs:
+		return ErrInvalidSyntax
+	case ExitPrivileges:
+		return ErrPrivileges
+	case ExitNoRepos:
+		return ErrNoRepos
+	case ExitZyppLocked:
+		return ErrLocked
+	case ExitCommitError:
+		return ErrCommitFailed
+	case ExitInfCapNotFound:
+		return ErrNotFound
+	case ExitOnSignal:
+		return ErrInterrupted
+	default:
+		return fmt.Errorf("%s: command failed: %w: %s", pm, err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+}
+
+// xmlStream is the root element of zypper's XML output.
+type xmlStream struct {
+	Messages       []xmlMessage      `xml:"message"`
+	SearchResult   xmlSolvableList   `xml:"search-result>solvable-list"`
+	UpdateList     []xmlUpdate       `xml:"update-status>update-list>update"`
+	InstallSummary xmlInstallSummary `xml:"install-summary"`
+	Repositories   []xmlRepository   `xml:"repo-list>repo"`
+}
+
+// xmlMessage is a message emitted by zypper in XML output mode.
+type xmlMessage struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// xmlSolvableList is a list of solvables (packages, patches, patterns, ...).
+type xmlSolvableList struct {
+	Solvables []xmlSolvable `xml:"solvable"`
+}
+
+// xmlSolvable describes a single package in zypper's XML output.
+type xmlSolvable struct {
+	Status     string `xml:"status,attr"`
+	Name       string `xml:"name,attr"`
+	Kind       string `xml:"kind,attr"`
+	Edition    string `xml:"edition,attr"`
+	EditionOld string `xml:"edition-old,attr"`
+	Arch       string `xml:"arch,attr"`
+	Repository string `xml:"repository,attr"`
+	Summary    string `xml:"summary,attr"`
+}
+
+// xmlUpdate describes a single available update in `zypper list-updates` XML output.
+type xmlUpdate struct {
+	Kind       string `xml:"kind,attr"`
+	Name       string `xml:"name,attr"`
+	Edition    string `xml:"edition,attr"`
+	EditionOld string `xml:"edition-old,attr"`
+	Arch       string `xml:"arch,attr"`
+	Category   string `xml:"category,attr"`
+	Severity   string `xml:"severity,attr"`
+	Source     struct {
+		Alias string `xml:"alias,attr"`
+	} `xml:"source"`
+	Issues []struct {
+		Type string `xml:"type,attr"`
+		ID   string `xml:"id,attr"`
+	} `xml:"issue-list>issue"`
+}
+
+// xmlRepository describes a single repository in `zypper repos` XML output.
+type xmlRepository struct {
+	Alias    string `xml:"alias,attr"`
+	Name     string `xml:"name,attr"`
+	Enabled  string `xml:"enabled,attr"`
+	GPGCheck string `xml:"gpgcheck,attr"`
+	URL      string `xml:"url"`
+}
+
+// xmlInstallSummary is the transaction summary printed by install, remove and update commands.
+type xmlInstallSummary struct {
+	DownloadSize   int64           `xml:"download-size,attr"`
+	SpaceUsageDiff int64           `xml:"space-usage-diff,attr"`
+	ToInstall      xmlSolvableList `xml:"to-install"`
+	ToReinstall    xmlSolvableList `xml:"to-reinstall"`
+	ToUpgrade      xmlSolvableList `xml:"to-upgrade"`
+	ToDowngrade    xmlSolvableList `xml:"to-downgrade"`
+	ToRemove       xmlSolvableList `xml:"to-remove"`
+}
+
+// parseXMLStream decodes zypper's XML output, and logs the messages it contains when verbose mode is on.
+func parseXMLStream(msg []byte, opts *manager.Options) (xmlStream, error) {
+	var stream xmlStream
+	if err := xml.Unmarshal(msg, &stream); err != nil {
+		return stream, fmt.Errorf("%s: failed to parse XML output: %w", pm, err)
+	}
+
+	if opts != nil && opts.Verbose {
+		for _, m := range stream.Messages {
+			opts.Log().Debug("Message", "package_manager", pm, "type", m.Type, "text", strings.TrimSpace(m.Text))
+		}
+	}
+
+	return stream, nil
+}
+
+// ParseSearchOutput parses the output of `zypper --xmlout search --details` command
+// and returns a list of packages that match the search query.
+// Example msg:
+//
+//	<?xml version='1.0'?>
+//	<stream>
+//	<search-result version="0.0">
+//	<solvable-list>
+//	<solvable status="installed" name="vim" kind="package" edition="9.0.1572-1.1" arch="x86_64" repository="(System Packages)"/>
+//	<solvable status="other-version" name="vim" kind="package" edition="9.0.1632-1.1" arch="x86_64" repository="repo-oss"/>
+//	<solvable status="not-installed" name="gvim" kind="package" edition="9.0.1632-1.1" arch="x86_64" repository="repo-oss"/>
+//	</solvable-list>
+//	</search-result>
+//	</stream>
+func ParseSearchOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+
+	stream, err := parseXMLStream(msg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range stream.SearchResult.Solvables {
+		// if name is empty, it might be not what we want
+		if s.Name == "" {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           s.Name,
+			Arch:           s.Arch,
+			Category:       s.Repository,
+			Origin:         origin(s.Repository),
+			PackageManager: pm,
+		}
+
+		switch s.Status {
+		case "installed":
+			packageInfo.Status = manager.PackageStatusInstalled
+			packageInfo.Version = s.Edition
+		case "not-installed", "other-version":
+			packageInfo.Status = manager.PackageStatusAvailable
+			packageInfo.NewVersion = s.Edition
+		default:
+			packageInfo.Status = manager.PackageStatusUnknown
+			packageInfo.Version = s.Edition
+		}
+
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// ParsePatternsOutput parses the output of `zypper --xmlout search --type=pattern` command and returns the patterns,
+// with their summary as description.
+// Example msg:
+//
+//	<?xml version='1.0'?>
+//	<stream>
+//	<search-result version="0.0">
+//	<solvable-list>
+//	<solvable status="installed" name="base" summary="Minimal Base System" kind="pattern"/>
+//	<solvable status="not-installed" name="devel_basis" summary="Base Development" kind="pattern"/>
+//	</solvable-list>
+//	</search-result>
+//	</stream>
+func ParsePatternsOutput(msg []byte, opts *manager.Options) ([]manager.GroupInfo, error) {
+	var groups []manager.GroupInfo
+
+	stream, err := parseXMLStream(msg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range stream.SearchResult.Solvables {
+		if s.Name == "" || s.Kind != "pattern" {
+			continue
+		}
+
+		groups = append(groups, manager.GroupInfo{
+			Name:           s.Name,
+			Description:    s.Summary,
+			Installed:      s.Status == "installed",
+			PackageManager: pm,
+		})
+	}
+
+	return groups, nil
+}
+
+// ParseReposOutput parses the output of `zypper --xmlout repos` command and returns the repositories, named by their
+// alias, the origin of their packages, and titled by their name. The repositories without gpgcheck are unsigned.
+// Example msg:
+//
+//	<?xml version='1.0'?>
+//	<stream>
+//	<repo-list>
+//	<repo alias="repo-oss" name="Main Repository" type="rpm-md" priority="99" enabled="1" autorefresh="1" gpgcheck="1">
+//	<url>http://download.opensuse.org/tumbleweed/repo/oss/</url>
+//	</repo>
+//	</repo-list>
+//	</stream>
+func ParseReposOutput(msg []byte, opts *manager.Options) ([]manager.RepositoryInfo, error) {
+	var repos []manager.RepositoryInfo
+
+	stream, err := parseXMLStream(msg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range stream.Repositories {
+		if r.Alias == "" {
+			continue
+		}
+		repos = append(repos, manager.RepositoryInfo{
+			Name:           r.Alias,
+			URL:            strings.TrimSpace(r.URL),
+			Title:          r.Name,
+			Origin:         r.Alias,
+			Enabled:        r.Enabled == "1",
+			Unsigned:       r.GPGCheck == "0",
+			PackageManager: pm,
+		})
+	}
+
+	return repos, nil
+}
+
+// ParseListUpdatesOutput parses the output of `zypper --xmlout list-updates` command
+// and returns a list of upgradable packages.
+// Example msg:
+//
+//	<?xml version='1.0'?>
+//	<stream>
+//	<update-status version="0.6">
+//	<update-list>
+//	<update kind="package" name="vim" edition="9.0.1632-1.1" arch="x86_64" edition-old="9.0.1572-1.1">
+//	<summary>Vi IMproved</summary>
+//	<source url="http://download.opensuse.org/tumbleweed/repo/oss" alias="repo-oss"/>
+//	</update>
+//	</update-list>
+//	</update-status>
+//	</stream>
+func ParseListUpdatesOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+
+	stream, err := parseXMLStream(msg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range stream.UpdateList {
+		// if name is empty, it might be not what we want
+		if u.Name == "" {
+			continue
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           u.Name,
+			Version:        u.EditionOld,
+			NewVersion:     u.Edition,
+			Arch:           u.Arch,
+			Category:       u.Source.Alias,
+			Origin:         origin(u.Source.Alias),
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: pm,
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// ParseListPatchesOutput parses the output of `zypper --xmlout list-patches` command and returns the needed patches,
+// with the CVEs they fix in AdditionalData["cve"] (comma-separated), and their category and severity.
+// Example msg:
+//
+//	<?xml version='1.0'?>
+//	<stream>
+//	<update-status version="0.6">
+//	<update-list>
+//	<update kind="patch" name="openSUSE-SLE-15.5-2024-283" edition="1" arch="noarch" status="needed" category="security" severity="moderate">
+//	<summary>Security update for openssl-3</summary>
+//	<source url="http://download.opensuse.org/update/leap/15.5/sle" alias="repo-sle-update"/>
+//	<issue-list>
+//	<issue type="cve" id="CVE-2024-0727"/>
+//	<issue type="bugzilla" id="1219243"/>
+//	</issue-list>
+//	</update>
+//	</update-list>
+//	</update-status>
+//	</stream>
+func ParseListPatchesOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+
+	stream, err := parseXMLStream(msg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range stream.UpdateList {
+		// if name is empty, it might be not what we want
+		if u.Name == "" {
+			continue
+		}
+
+		var cves []string
+		for _, issue := range u.Issues {
+			if issue.Type == "cve" {
+				cves = append(cves, issue.ID)
+			}
+		}
+
+		packageInfo := manager.PackageInfo{
+			Name:           u.Name,
+			NewVersion:     u.Edition,
+			Arch:           u.Arch,
+			Category:       u.Source.Alias,
+			Origin:         origin(u.Source.Alias),
+			Status:         manager.PackageStatusUpgradable,
+			PackageManager: pm,
+			AdditionalData: map[string]string{
+				"patch_category": u.Category,
+				"severity":       u.Severity,
+			},
+		}
+		if len(cves) > 0 {
+			packageInfo.AdditionalData["cve"] = strings.Join(cves, ",")
+		}
+		packages = append(packages, packageInfo)
+	}
+
+	return packages, nil
+}
+
+// ParseInstallSummaryOutput parses the output of `zypper --xmlout install|remove|update` commands
+// and returns the list of packages changed by the transaction.
+// Installed, reinstalled, upgraded and downgraded packages are reported as installed, removed packages as available.
+// Example msg:
+//
+//	<?xml version='1.0'?>
+//	<stream>
+//	<message type="info">Loading repository data...</message>
+//	<install-summary download-size="1906411" space-usage-diff="3840779" packages-to-change="2">
+//	<to-install>
+//	<solvable type="package" name="vim" arch="x86_64" edition="9.0.1632-1.1" repository="repo-oss"/>
+//	</to-install>
+//	<to-upgrade>
+//	<solvable type="package" name="vim-data-common" arch="noarch" edition="9.0.1632-1.1" edition-old="9.0.1572-1.1" repository="repo-oss"/>
+//	</to-upgrade>
+//	</install-summary>
+//	</stream>
+func ParseInstallSummaryOutput(msg []byte, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+
+	stream, err := parseXMLStream(msg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := stream.InstallSummary
+	sections := []struct {
+		solvables []xmlSolvable
+		status    manager.PackageStatus
+	}{
+		{summary.ToInstall.Solvables, manager.PackageStatusInstalled},
+		{summary.ToReinstall.Solvables, manager.PackageStatusInstalled},
+		{summary.ToUpgrade.Solvables, manager.PackageStatusInstalled},
+		{summary.ToDowngrade.Solvables, manager.PackageStatusInstalled},
+		{summary.ToRemove.Solvables, manager.PackageStatusAvailable},
+	}
+
+	for _, section := range sections {
+		for _, s := range section.solvables {
+			// if name is empty, it might be not what we want
+			if s.Name == "" {
+				continue
+			}
+
+			packageInfo := manager.PackageInfo{
+				Name:           s.Name,
+				Arch:           s.Arch,
+				Category:       s.Repository,
+				Origin:         origin(s.Repository),
+				Status:         section.status,
+				PackageManager: pm,
+			}
+
+			packageInfo.Version = s.Edition
+			if section.status != manager.PackageStatusAvailable {
+				packageInfo.NewVersion = s.Edition
+				if s.EditionOld != "" {
+					packageInfo.Version = s.EditionOld
+				}
+			}
+
+			packages = append(packages, packageInfo)
+		}
+	}
+
+	return packages, nil
+}
+
+// ParsePlanOutput parses the output of `zypper --xmlout install|remove|update --dry-run` commands, and returns the plan
+// of the transaction: the packages it installs, upgrades, downgrades and removes, with their versions, and the total
+// download size and disk space used. Reinstalled packages are not changed, and are left out.
+// Example msg:
+//
+//	<?xml version='1.0'?>
+//	<stream>
+//	<install-summary download-size="1906411" space-usage-diff="3840779" packages-to-change="2">
+//	<to-install>
+//	<solvable type="package" name="vim" arch="x86_64" edition="9.0.1632-1.1" repository="repo-oss"/>
+//	</to-install>
+//	<to-upgrade>
+//	<solvable type="package" name="vim-data-common" arch="noarch" edition="9.0.1632-1.1" edition-old="9.0.1572-1.1" repository="repo-oss"/>
+//	</to-upgrade>
+//	</install-summary>
+//	</stream>
+func ParsePlanOutput(msg []byte, opts *manager.Options) (*manager.Plan, error) {
+	stream, err := parseXMLStream(msg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := stream.InstallSummary
+	plan := &manager.Plan{
+		PackageManager: pm,
+		Entries:        []manager.PlanEntry{},
+		DownloadSize:   summary.DownloadSize,
+		DiskSpace:      summary.SpaceUsageDiff,
+	}
+	sections := []struct {
+		solvables []xmlSolvable
+		action    manager.PlanAction
+	}{
+		{summary.ToInstall.Solvables, manager.PlanActionInstall},
+		{summary.ToUpgrade.Solvables, manager.PlanActionUpgrade},
+		{summary.ToDowngrade.Solvables, manager.PlanActionDowngrade},
+		{summary.ToRemove.Solvables, manager.PlanActionRemove},
+	}
+	for _, section := range sections {
+		for _, s := range section.solvables {
+			if s.Name == "" {
+				continue
+			}
+			entry := manager.PlanEntry{Action: section.action, Name: s.Name, Arch: s.Arch, Version: s.EditionOld, NewVersion: s.Edition}
+			if section.action == manager.PlanActionRemove {
+				entry.Version, entry.NewVersion = s.Edition, ""
+			}
+			plan.Entries = append(plan.Entries, entry)
+		}
+	}
+	return plan, nil
+}
+
+// ParsePackageInfoOutput parses the output of `zypper info packageName` command
+// and returns a manager.PackageInfo object containing package information such as name, version,
+// architecture, repository, installation status, upstream URL, as homepage, vendor, as maintainer, and installed size.
+// zypper does not provide an XML mode for this command, so the text output is parsed.
+// Example msg:
+//
+//	Information for package vim:
+//	----------------------------
+//	Repository     : repo-oss
+//	Name           : vim
+//	Version        : 9.0.1632-1.1
+//	Arch           : x86_64
+//	Vendor         : openSUSE
+//	Installed Size : 3.6 MiB
+//	Installed      : Yes (automatically)
+//	Status         : out-of-date (version 9.0.1572-1.1 installed)
+//	Upstream URL   : https://www.vim.org/
+func ParsePackageInfoOutput(msg string, opts *manager.Options) manager.PackageInfo {
+	var pkg manager.PackageInfo
+	var installed bool
+	var status string
+
+	// remove the last empty line
+	msg = strings.TrimSuffix(msg, "\n")
+	lines := strings.Split(msg, "\n")
+
+	for _, line := range lines {
+		parts := strings.SplitN(line, " : ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		switch key {
+		case "Name":
+			pkg.Name = value
+		case "Version":
+			pkg.Version = value
+		case "Arch":
+			pkg.Arch = value
+		case "Repository":
+			pkg.Category, pkg.Origin = value, origin(value)
+		case "Installed":
+			installed = strings.HasPrefix(value, "Yes")
+		case "Status":
+			status = value
+		case "Upstream URL":
+			pkg.Homepage = value
+		case "Vendor":
+			pkg.Maintainer = value
+		case "Installed Size":
+			pkg.SizeInstalled, _ = manager.ParseSize(value)
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(status, "out-of-date"):
+		pkg.Status = manager.PackageStatusUpgradable
+		pkg.NewVersion = pkg.Version
+		if start, end := strings.Index(status, "(version "), strings.Index(status, " installed)"); start >= 0 && end > start {
+			pkg.Version = status[start+len("(version ") : end]
+		}
+	case installed:
+		pkg.Status = manager.PackageStatusInstalled
+	default:
+		pkg.Status = manager.PackageStatusAvailable
+	}
+
+	pkg.PackageManager = pm
+
+	return pkg
+}
+
+// ParseRPMQueryOutput parses the output of rpm queries using the `%{NAME} %{VERSION}-%{RELEASE} %{ARCH}\n` query format,
+// such as `rpm -qf path`, and returns the installed packages. Lines not matching the format, such as rpm notes, are skipped.
+// Example msg:
+//
+//	vim 9.0.1572-1.1 x86_64
+//	file /usr/bin/foo is not owned by any package
+func ParseRPMQueryOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		packages = append(packages, manager.PackageInfo{
+			Name:           fields[0],
+			Version:        fields[1],
+			Arch:           fields[2],
+			Status:         manager.PackageStatusInstalled,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseRPMSizesOutput parses the output of `rpm -qa --queryformat "%{NAME}\t%{SIZE}\n"` command and returns the
+// installed sizes of the packages in bytes, by package name.
+// Example msg:
+//
+//	vim	3776411
+//	zypper	7362150
+func ParseRPMSizesOutput(msg string) map[string]int64 {
+	sizes := make(map[string]int64)
+
+	for _, line := range strings.Split(msg, "\n") {
+		name, value, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+		if size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			sizes[name] = size
+		}
+	}
+
+	return sizes
+}
+
+// ParseSignaturesOutput parses the output of rpm queries using the `%{NAME}\t%{RSAHEADER:pgpsig}\t%{DSAHEADER:pgpsig}\n`
+// query format, and returns the status of the signatures of the packages by name: packages having neither an RSA nor
+// a DSA header signature are unsigned, and the others valid, since rpm checks the signatures when installing packages.
+// The gpg-pubkey pseudo-packages of the imported keys are skipped.
+// Example msg:
+//
+//	vim	RSA/SHA512, Tue May 23 12:00:00 2023, Key ID 35a2f86e29b700a4	(none)
+//	mypackage	(none)	(none)
+func ParseSignaturesOutput(msg string, opts *manager.Options) map[string]manager.SignatureStatus {
+	signatures := make(map[string]manager.SignatureStatus)
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[0] == "gpg-pubkey" {
+			continue
+		}
+		signatures[fields[0]] = manager.SignatureValid
+		if fields[1] == "(none)" && fields[2] == "(none)" {
+			signatures[fields[0]] = manager.SignatureUnsigned
+		}
+	}
+
+	return signatures
+}
+
+// ParseListFilesOutput parses the output of `rpm -ql packageName` command and returns the installed paths.
+// Packages without files are reported by rpm as "(contains no files)", which is skipped.
+// Example msg:
+//
+//	/usr/bin/vim
+//	/usr/share/doc/packages/vim
+func ParseListFilesOutput(msg string, opts *manager.Options) []string {
+	var files []string
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		if !strings.HasPrefix(line, "/") {
+			continue
+		}
+		files = append(files, line)
+	}
+
+	return files
+}
+
+// ParseLocksOutput parses the output of `zypper locks` command and returns the locked packages, with their status set to held.
+// Locks of other types (patterns, patches, ...) are skipped. The columns are located by their header, as they vary between
+// zypper versions and options.
+// Example msg:
+//
+//	# | Name | Type    | Repository
+//	--+------+---------+-----------
+//	1 | vim  | package | (any)
+func ParseLocksOutput(msg string, opts *manager.Options) []manager.PackageInfo {
+	var packages []manager.PackageInfo
+	nameCol, typeCol := -1, -1
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		fields := strings.Split(line, "|")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		if nameCol < 0 {
+			for i, field := range fields {
+				switch field {
+				case "Name":
+					nameCol = i
+				case "Type":
+					typeCol = i
+				}
+			}
+			continue
+		}
+
+		if len(fields) <= nameCol || strings.HasPrefix(line, "--") {
+			continue
+		}
+		if typeCol >= 0 && typeCol < len(fields) && fields[typeCol] != "package" {
+			continue
+		}
+
+		packages = append(packages, manager.PackageInfo{
+			Name:           fields[nameCol],
+			Status:         manager.PackageStatusHeld,
+			PackageManager: pm,
+		})
+	}
+
+	return packages
+}
+
+// ParseKeysOutput parses the output of `rpm -q gpg-pubkey --queryformat "%{VERSION}-%{RELEASE} %{SUMMARY}\n"` command
+// and returns the imported signing keys, identified by the version and release of their gpg-pubkey package.
+// Example msg:
+//
+//	3dbdc284-53674dd4 gpg(openSUSE Project Signing Key <opensuse@opensuse.org>)
+//	29b700a4-62b07e22 gpg(openSUSE Project Signing Key <opensuse@opensuse.org>)
+func ParseKeysOutput(msg string, opts *manager.Options) []manager.KeyInfo {
+	var keys []manager.KeyInfo
+
+	for _, line := range strings.Split(msg, "\n") {
+		if opts != nil && opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "line", line)
+		}
+
+		id, summary, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if id == "" {
+			continue
+		}
+
+		keys = append(keys, manager.KeyInfo{
+			ID:             id,
+			UserID:         strings.TrimSuffix(strings.TrimPrefix(summary, "gpg("), ")"),
+			PackageManager: pm,
+		})
+	}
+
+	return keys
+}
+
+// origin returns the origin of the packages of a repository: the repository, or nothing for the installed packages
+// no repository provides, listed in the "(System Packages)" or "@System" pseudo-repository.
+func origin(repository string) string {
+	if repository == "(System Packages)" || repository == "@System" {
+		return ""
+	}
+	return repository
+}
@@ -0,0 +1,485 @@
+package zypper_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/zypper"
+)
+
+func TestParseSearchOutput(t *testing.T) {
+	var inputParseSearchOutput string = strings.Join([]string{
+		`<?xml version='1.0'?>`,
+		`<stream>`,
+		`<message type="info">Loading repository data...</message>`,
+		`<message type="info">Reading installed packages...</message>`,
+		`<search-result version="0.0">`,
+		`<solvable-list>`,
+		`<solvable status="installed" name="vim" kind="package" edition="9.0.1572-1.1" arch="x86_64" repository="(System Packages)"/>`,
+		`<solvable status="other-version" name="vim" kind="package" edition="9.0.1632-1.1" arch="x86_64" repository="repo-oss"/>`,
+		`<solvable status="not-installed" name="gvim" kind="package" edition="9.0.1632-1.1" arch="x86_64" repository="repo-oss"/>`,
+		`</solvable-list>`,
+		`</search-result>`,
+		`</stream>`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "vim",
+			Version:        "9.0.1572-1.1",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "(System Packages)",
+			Arch:           "x86_64",
+			PackageManager: "zypper",
+		},
+		{
+			Name:           "vim",
+			NewVersion:     "9.0.1632-1.1",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "repo-oss",
+			Origin:         "repo-oss",
+			Arch:           "x86_64",
+			PackageManager: "zypper",
+		},
+		{
+			Name:           "gvim",
+			NewVersion:     "9.0.1632-1.1",
+			Status:         manager.PackageStatusAvailable,
+			Category:       "repo-oss",
+			Origin:         "repo-oss",
+			Arch:           "x86_64",
+			PackageManager: "zypper",
+		},
+	}
+
+	actualPackageInfo, err := zypper.ParseSearchOutput([]byte(inputParseSearchOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseSearchOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseSearchOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseListUpdatesOutput(t *testing.T) {
+	var inputParseListUpdatesOutput string = strings.Join([]string{
+		`<?xml version='1.0'?>`,
+		`<stream>`,
+		`<update-status version="0.6">`,
+		`<update-list>`,
+		`<update kind="package" name="vim" edition="9.0.1632-1.1" arch="x86_64" edition-old="9.0.1572-1.1">`,
+		`<summary>Vi IMproved</summary>`,
+		`<description>Vim (Vi IMproved) is an almost compatible version of the UNIX editor vi.</description>`,
+		`<license></license>`,
+		`<source url="http://download.opensuse.org/tumbleweed/repo/oss" alias="repo-oss"/>`,
+		`</update>`,
+		`</update-list>`,
+		`</update-status>`,
+		`</stream>`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "vim",
+			Version:        "9.0.1572-1.1",
+			NewVersion:     "9.0.1632-1.1",
+			Status:         manager.PackageStatusUpgradable,
+			Category:       "repo-oss",
+			Origin:         "repo-oss",
+			Arch:           "x86_64",
+			PackageManager: "zypper",
+		},
+	}
+
+	actualPackageInfo, err := zypper.ParseListUpdatesOutput([]byte(inputParseListUpdatesOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseListUpdatesOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListUpdatesOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseReposOutput(t *testing.T) {
+	var inputParseReposOutput string = strings.Join([]string{
+		`<?xml version='1.0'?>`,
+		`<stream>`,
+		`<repo-list>`,
+		`<repo alias="repo-oss" name="Main Repository" type="rpm-md" priority="99" enabled="1" autorefresh="1" gpgcheck="1" repo_gpgcheck="1" pkg_gpgcheck="1">`,
+		`<url>http://download.opensuse.org/tumbleweed/repo/oss/</url>`,
+		`</repo>`,
+		`<repo alias="internal" name="Internal Packages" type="rpm-md" priority="90" enabled="1" autorefresh="0" gpgcheck="0">`,
+		`<url>http://repo.example.com/suse/</url>`,
+		`</repo>`,
+		`<repo alias="repo-debug" name="Debug Repository" type="NONE" priority="99" enabled="0" autorefresh="1" gpgcheck="1">`,
+		`<url>http://download.opensuse.org/debug/tumbleweed/repo/oss/</url>`,
+		`</repo>`,
+		`</repo-list>`,
+		`</stream>`,
+	}, "\n")
+
+	var expectedRepos = []manager.RepositoryInfo{
+		{Name: "repo-oss", URL: "http://download.opensuse.org/tumbleweed/repo/oss/", Title: "Main Repository", Origin: "repo-oss", Enabled: true, PackageManager: "zypper"},
+		{Name: "internal", URL: "http://repo.example.com/suse/", Title: "Internal Packages", Origin: "internal", Enabled: true, Unsigned: true, PackageManager: "zypper"},
+		{Name: "repo-debug", URL: "http://download.opensuse.org/debug/tumbleweed/repo/oss/", Title: "Debug Repository", Origin: "repo-debug", PackageManager: "zypper"},
+	}
+
+	actualRepos, err := zypper.ParseReposOutput([]byte(inputParseReposOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseReposOutput() error = %+v", err)
+	}
+	if !reflect.DeepEqual(expectedRepos, actualRepos) {
+		t.Errorf("ParseReposOutput() = %+v, want %+v", actualRepos, expectedRepos)
+	}
+}
+
+func TestParseListPatchesOutput(t *testing.T) {
+	var inputParseListPatchesOutput string = strings.Join([]string{
+		`<?xml version='1.0'?>`,
+		`<stream>`,
+		`<update-status version="0.6">`,
+		`<update-list>`,
+		`<update kind="patch" name="openSUSE-SLE-15.5-2024-283" edition="1" arch="noarch" status="needed" category="security" severity="moderate">`,
+		`<summary>Security update for openssl-3</summary>`,
+		`<source url="http://download.opensuse.org/update/leap/15.5/sle" alias="repo-sle-update"/>`,
+		`<issue-list>`,
+		`<issue type="cve" id="CVE-2024-0727"/>`,
+		`<issue type="bugzilla" id="1219243"/>`,
+		`<issue type="cve" id="CVE-2023-6237"/>`,
+		`</issue-list>`,
+		`</update>`,
+		`</update-list>`,
+		`</update-status>`,
+		`</stream>`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "openSUSE-SLE-15.5-2024-283",
+			NewVersion:     "1",
+			Status:         manager.PackageStatusUpgradable,
+			Category:       "repo-sle-update",
+			Origin:         "repo-sle-update",
+			Arch:           "noarch",
+			PackageManager: "zypper",
+			AdditionalData: map[string]string{
+				"patch_category": "security",
+				"severity":       "moderate",
+				"cve":            "CVE-2024-0727,CVE-2023-6237",
+			},
+		},
+	}
+
+	actualPackageInfo, err := zypper.ParseListPatchesOutput([]byte(inputParseListPatchesOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseListPatchesOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseListPatchesOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseInstallSummaryOutput(t *testing.T) {
+	var inputParseInstallSummaryOutput string = strings.Join([]string{
+		`<?xml version='1.0'?>`,
+		`<stream>`,
+		`<message type="info">Loading repository data...</message>`,
+		`<install-summary download-size="1906411" space-usage-diff="3840779" packages-to-change="3">`,
+		`<to-install>`,
+		`<solvable type="package" name="vim" arch="x86_64" edition="9.0.1632-1.1" summary="Vi IMproved" repository="repo-oss"/>`,
+		`</to-install>`,
+		`<to-upgrade>`,
+		`<solvable type="package" name="vim-data-common" arch="noarch" edition="9.0.1632-1.1" edition-old="9.0.1572-1.1" repository="repo-oss"/>`,
+		`</to-upgrade>`,
+		`<to-remove>`,
+		`<solvable type="package" name="vim-small" arch="x86_64" edition="9.0.1572-1.1"/>`,
+		`</to-remove>`,
+		`</install-summary>`,
+		`</stream>`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{
+			Name:           "vim",
+			Version:        "9.0.1632-1.1",
+			NewVersion:     "9.0.1632-1.1",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "repo-oss",
+			Origin:         "repo-oss",
+			Arch:           "x86_64",
+			PackageManager: "zypper",
+		},
+		{
+			Name:           "vim-data-common",
+			Version:        "9.0.1572-1.1",
+			NewVersion:     "9.0.1632-1.1",
+			Status:         manager.PackageStatusInstalled,
+			Category:       "repo-oss",
+			Origin:         "repo-oss",
+			Arch:           "noarch",
+			PackageManager: "zypper",
+		},
+		{
+			Name:           "vim-small",
+			Version:        "9.0.1572-1.1",
+			Status:         manager.PackageStatusAvailable,
+			Arch:           "x86_64",
+			PackageManager: "zypper",
+		},
+	}
+
+	actualPackageInfo, err := zypper.ParseInstallSummaryOutput([]byte(inputParseInstallSummaryOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParseInstallSummaryOutput() error = %+v", err)
+	}
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseInstallSummaryOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParsePlanOutput(t *testing.T) {
+	var inputParsePlanOutput string = strings.Join([]string{
+		`<?xml version='1.0'?>`,
+		`<stream>`,
+		`<install-summary download-size="1906411" space-usage-diff="3840779" packages-to-change="3">`,
+		`<to-install>`,
+		`<solvable type="package" name="vim" arch="x86_64" edition="9.0.1632-1.1" repository="repo-oss"/>`,
+		`</to-install>`,
+		`<to-upgrade>`,
+		`<solvable type="package" name="vim-data-common" arch="noarch" edition="9.0.1632-1.1" edition-old="9.0.1572-1.1" repository="repo-oss"/>`,
+		`</to-upgrade>`,
+		`<to-remove>`,
+		`<solvable type="package" name="vim-small" arch="x86_64" edition="9.0.1572-1.1"/>`,
+		`</to-remove>`,
+		`</install-summary>`,
+		`</stream>`,
+	}, "\n")
+
+	expectedPlan := &manager.Plan{
+		PackageManager: "zypper",
+		Entries: []manager.PlanEntry{
+			{Action: manager.PlanActionInstall, Name: "vim", Arch: "x86_64", NewVersion: "9.0.1632-1.1"},
+			{Action: manager.PlanActionUpgrade, Name: "vim-data-common", Arch: "noarch", Version: "9.0.1572-1.1", NewVersion: "9.0.1632-1.1"},
+			{Action: manager.PlanActionRemove, Name: "vim-small", Arch: "x86_64", Version: "9.0.1572-1.1"},
+		},
+		DownloadSize: 1906411,
+		DiskSpace:    3840779,
+	}
+
+	actualPlan, err := zypper.ParsePlanOutput([]byte(inputParsePlanOutput), &manager.Options{})
+	if err != nil {
+		t.Fatalf("ParsePlanOutput() error = %+v", err)
+	}
+	if !reflect.DeepEqual(expectedPlan, actualPlan) {
+		t.Errorf("ParsePlanOutput() = %+v, want %+v", actualPlan, expectedPlan)
+	}
+}
+
+func TestParsePackageInfoOutput(t *testing.T) {
+	var inputParsePackageInfoOutput string = strings.Join([]string{
+		`Loading repository data...`,
+		`Reading installed packages...`,
+		``,
+		``,
+		`Information for package vim:`,
+		`----------------------------`,
+		`Repository     : repo-oss`,
+		`Name           : vim`,
+		`Version        : 9.0.1632-1.1`,
+		`Arch           : x86_64`,
+		`Vendor         : openSUSE`,
+		`Installed Size : 3.6 MiB`,
+		`Installed      : Yes (automatically)`,
+		`Status         : out-of-date (version 9.0.1572-1.1 installed)`,
+		`Source package : vim-9.0.1632-1.1.src`,
+		`Upstream URL   : https://www.vim.org/`,
+		`Summary        : Vi IMproved`,
+	}, "\n")
+
+	var expectedPackageInfo = manager.PackageInfo{
+		Name:           "vim",
+		Version:        "9.0.1572-1.1",
+		NewVersion:     "9.0.1632-1.1",
+		Status:         manager.PackageStatusUpgradable,
+		Category:       "repo-oss",
+		Origin:         "repo-oss",
+		Arch:           "x86_64",
+		Homepage:       "https://www.vim.org/",
+		Maintainer:     "openSUSE",
+		SizeInstalled:  3774873,
+		PackageManager: "zypper",
+	}
+
+	actualPackageInfo := zypper.ParsePackageInfoOutput(inputParsePackageInfoOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParsePackageInfoOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestCheckExitError(t *testing.T) {
+	tests := []struct {
+		name     string
+		exitCode string
+		want     error
+	}{
+		{name: "success", exitCode: "0", want: nil},
+		{name: "update needed is informational", exitCode: "100", want: nil},
+		{name: "reboot needed is informational", exitCode: "102", want: nil},
+		{name: "locked", exitCode: "7", want: zypper.ErrLocked},
+		{name: "capability not found", exitCode: "104", want: zypper.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := exec.Command("sh", "-c", "exit "+tt.exitCode).Run()
+			if got := zypper.CheckExitError(err, &manager.Options{}); got != tt.want {
+				t.Errorf("CheckExitError() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	opts := &manager.Options{Logger: slog.New(slog.NewTextHandler(&buf, nil))}
+	err := exec.Command("sh", "-c", "exit 107").Run()
+	if got := zypper.CheckExitError(err, opts); got != nil {
+		t.Errorf("CheckExitError() = %+v, want nil", got)
+	}
+	if want := "some RPM scriptlets failed"; !strings.Contains(buf.String(), want) {
+		t.Errorf("CheckExitError() logged %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseRPMQueryOutput(t *testing.T) {
+	var inputParseRPMQueryOutput string = strings.Join([]string{
+		`vim 9.0.1572-1.1 x86_64`,
+		`file /usr/bin/foo is not owned by any package`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "vim", Version: "9.0.1572-1.1", Arch: "x86_64", Status: manager.PackageStatusInstalled, PackageManager: "zypper"},
+	}
+
+	actualPackageInfo := zypper.ParseRPMQueryOutput(inputParseRPMQueryOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseRPMQueryOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+}
+
+func TestParseRPMSizesOutput(t *testing.T) {
+	expectedSizes := map[string]int64{"vim": 3776411, "zypper": 7362150}
+
+	actualSizes := zypper.ParseRPMSizesOutput("vim\t3776411\nzypper\t7362150\n")
+	if !reflect.DeepEqual(expectedSizes, actualSizes) {
+		t.Errorf("ParseRPMSizesOutput() = %+v, want %+v", actualSizes, expectedSizes)
+	}
+}
+
+func TestParseListFilesOutput(t *testing.T) {
+	var inputParseListFilesOutput string = strings.Join([]string{
+		`/usr/bin/vim`,
+		`/usr/share/doc/packages/vim`,
+	}, "\n")
+
+	var expectedFiles = []string{"/usr/bin/vim", "/usr/share/doc/packages/vim"}
+
+	actualFiles := zypper.ParseListFilesOutput(inputParseListFilesOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedFiles, actualFiles) {
+		t.Errorf("ParseListFilesOutput() = %+v, want %+v", actualFiles, expectedFiles)
+	}
+
+	if actualFiles := zypper.ParseListFilesOutput("(contains no files)\n", &manager.Options{}); actualFiles != nil {
+		t.Errorf("ParseListFilesOutput() = %+v, want %+v", actualFiles, nil)
+	}
+}
+
+func TestParseLocksOutput(t *testing.T) {
+	var inputParseLocksOutput string = strings.Join([]string{
+		`# | Name       | Type    | Repository`,
+		`--+------------+---------+-----------`,
+		`1 | vim        | package | (any)`,
+		`2 | devel_basis | pattern | (any)`,
+	}, "\n")
+
+	var expectedPackageInfo = []manager.PackageInfo{
+		{Name: "vim", Status: manager.PackageStatusHeld, PackageManager: "zypper"},
+	}
+
+	actualPackageInfo := zypper.ParseLocksOutput(inputParseLocksOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedPackageInfo, actualPackageInfo) {
+		t.Errorf("ParseLocksOutput() = %+v, want %+v", actualPackageInfo, expectedPackageInfo)
+	}
+
+	if actualPackageInfo := zypper.ParseLocksOutput("There are no package locks defined.\n", &manager.Options{}); actualPackageInfo != nil {
+		t.Errorf("ParseLocksOutput() = %+v, want %+v", actualPackageInfo, nil)
+	}
+}
+
+func TestParseKeysOutput(t *testing.T) {
+	var inputParseKeysOutput string = strings.Join([]string{
+		`3dbdc284-53674dd4 gpg(openSUSE Project Signing Key <opensuse@opensuse.org>)`,
+		`29b700a4-62b07e22 gpg(openSUSE Project Signing Key <opensuse@opensuse.org>)`,
+		``,
+	}, "\n")
+
+	var expectedKeys = []manager.KeyInfo{
+		{ID: "3dbdc284-53674dd4", UserID: "openSUSE Project Signing Key <opensuse@opensuse.org>", PackageManager: "zypper"},
+		{ID: "29b700a4-62b07e22", UserID: "openSUSE Project Signing Key <opensuse@opensuse.org>", PackageManager: "zypper"},
+	}
+
+	actualKeys := zypper.ParseKeysOutput(inputParseKeysOutput, &manager.Options{})
+
+	if !reflect.DeepEqual(expectedKeys, actualKeys) {
+		t.Errorf("ParseKeysOutput() = %+v, want %+v", actualKeys, expectedKeys)
+	}
+}
+
+func TestParseSignaturesOutput(t *testing.T) {
+	var inputParseSignaturesOutput string = strings.Join([]string{
+		"vim\tRSA/SHA512, Tue May 23 12:00:00 2023, Key ID 35a2f86e29b700a4\t(none)",
+		"mypackage\t(none)\t(none)",
+		"gpg-pubkey\t(none)\t(none)",
+	}, "\n")
+
+	expectedSignatures := map[string]manager.SignatureStatus{"vim": manager.SignatureValid, "mypackage": manager.SignatureUnsigned}
+	actualSignatures := zypper.ParseSignaturesOutput(inputParseSignaturesOutput, &manager.Options{})
+	if !reflect.DeepEqual(expectedSignatures, actualSignatures) {
+		t.Errorf("ParseSignaturesOutput() = %+v, want %+v", actualSignatures, expectedSignatures)
+	}
+}
+
+func TestParsePatternsOutput(t *testing.T) {
+	var inputParsePatternsOutput string = strings.Join([]string{
+		`<?xml version='1.0'?>`,
+		`<stream>`,
+		`<message type="info">Loading repository data...</message>`,
+		`<search-result version="0.0">`,
+		`<solvable-list>`,
+		`<solvable status="installed" name="base" summary="Minimal Base System" kind="pattern"/>`,
+		`<solvable status="not-installed" name="devel_basis" summary="Base Development" kind="pattern"/>`,
+		`</solvable-list>`,
+		`</search-result>`,
+		`</stream>`,
+	}, "\n")
+
+	expectedGroups := []manager.GroupInfo{
+		{Name: "base", Description: "Minimal Base System", Installed: true, PackageManager: "zypper"},
+		{Name: "devel_basis", Description: "Base Development", PackageManager: "zypper"},
+	}
+
+	actualGroups, err := zypper.ParsePatternsOutput([]byte(inputParsePatternsOutput), &manager.Options{})
+	if err != nil || !reflect.DeepEqual(expectedGroups, actualGroups) {
+		t.Errorf("ParsePatternsOutput() = %+v, %+v, want %+v", actualGroups, err, expectedGroups)
+	}
+}
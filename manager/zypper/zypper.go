@@ -0,0 +1,784 @@
+// Package zypper provides an implementation of the syspkg manager interface for the zypper package manager.
+// It provides a Go (golang) API interface for interacting with the zypper package manager.
+// This package is a wrapper around the zypper command line tool.
+//
+// Zypper is the command line package manager of openSUSE and SUSE Linux Enterprise (SLES), built on top of the libzypp library.
+// It handles installing, updating and removing RPM packages, patches and patterns, and managing repositories.
+// Zypper provides a machine-readable XML output mode (--xmlout), which is used by this package whenever available
+// instead of parsing the human-readable, and possibly localized, text output.
+//
+// For more information about zypper, visit:
+// - https://en.opensuse.org/SDB:Zypper_usage
+// - https://doc.opensuse.org/documentation/leap/reference/html/book-reference/cha-sw-cl.html
+//
+// This package is part of the syspkg library.
+package zypper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+var pm string = "zypper"
+
+// Constants used for zypper commands
+const (
+	ArgsNonInteractive string = "--non-interactive"
+	ArgsXMLOut         string = "--xmlout"
+	ArgsDryRun         string = "--dry-run"
+	ArgsCleanDeps      string = "--clean-deps"
+	ArgsQuiet          string = "--quiet"
+	ArgsDetails        string = "--details"
+	ArgsInstalledOnly  string = "--installed-only"
+	ArgsPackagesOnly   string = "--type=package"
+	ArgsPatternsOnly   string = "--type=pattern"
+	ArgsOldPackage     string = "--oldpackage"
+	ArgsDownloadOnly   string = "--download-only"
+	ArgsPkgCacheDir    string = "--pkg-cache-dir"
+
+	ArgsCategorySecurity string = "--category=security"
+)
+
+// rpmKeyQueryFormat is the rpm --queryformat used to query the imported signing keys, which are gpg-pubkey packages.
+const rpmKeyQueryFormat string = "%{VERSION}-%{RELEASE} %{SUMMARY}\n"
+
+// rpmQueryFormat is the rpm --queryformat used to query installed packages.
+const rpmQueryFormat string = "%{NAME} %{VERSION}-%{RELEASE} %{ARCH}\n"
+
+// rpmSizeFormat is the rpm --queryformat used to query the installed sizes of installed packages, parsed by ParseRPMSizesOutput.
+const rpmSizeFormat string = "%{NAME}\t%{SIZE}\n"
+
+// rpmSignatureFormat is the rpm --queryformat used to query the signatures of installed packages, parsed by ParseSignaturesOutput.
+const rpmSignatureFormat string = "%{NAME}\t%{RSAHEADER:pgpsig}\t%{DSAHEADER:pgpsig}\n"
+
+// ENV_NonInteractive contains environment variables used to set non-interactive mode for zypper.
+var ENV_NonInteractive []string = []string{"LC_ALL=C"}
+
+// PackagesCacheDir is the cache of zypper where packages are downloaded, by repository, unless Options.DownloadDir is set.
+var PackagesCacheDir string = "/var/cache/zypp/packages"
+
+// PackageManager implements the manager.PackageManager interface for the zypper package manager.
+type PackageManager struct{}
+
+// IsAvailable checks if the zypper package manager is available on the system.
+func (a *PackageManager) IsAvailable() bool {
+	_, err := exec.LookPath(pm)
+	return err == nil
+}
+
+// GetPackageManager returns the name of the zypper package manager.
+func (a *PackageManager) GetPackageManager() string {
+	return pm
+}
+
+// Capabilities returns the operations and options supported by zypper.
+func (a *PackageManager) Capabilities() manager.Capabilities {
+	return manager.Capabilities{
+		Search:           true,
+		Delete:           true,
+		VersionedInstall: true,
+		DryRun:           true,
+		RootDir:          true,
+		Architecture:     true,
+		ListUpgradable:   true,
+		Upgrade:          true,
+		Downgrade:        true,
+		Hold:             true,
+		SecurityUpdates:  true,
+		FileOwner:        true,
+		FileList:         true,
+		Keys:             true,
+		Download:         true,
+		LocalInstall:     true,
+		Reboot:           true,
+		Verify:           true,
+		Groups:           true,
+	}
+}
+
+// Install installs the provided packages using the zypper package manager.
+func (a *PackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	// zypper installs a specific version with name=version, and for an architecture with name.arch
+	pkgs, err := manager.TranslatePackageSpecs(manager.QualifyArchitecture(pkgs, opts, qualifyArch), manager.PackageSpec.String)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts != nil && opts.DownloadOnly {
+		packages, err := a.runTransaction("install", append([]string{ArgsDownloadOnly}, pkgs...), opts)
+		if err != nil || opts.DryRun {
+			return packages, err
+		}
+		dir := PackagesCacheDir
+		if opts.DownloadDir != "" {
+			dir = opts.DownloadDir
+		}
+		return locateDownloads(packages, dir)
+	}
+
+	return a.runTransaction("install", pkgs, opts)
+}
+
+// Download downloads the provided packages and their missing dependencies using zypper install --download-only,
+// into the cache of zypper or opts.DownloadDir, where they are stored by repository.
+func (a *PackageManager) Download(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var downloadOpts manager.Options
+	if opts != nil {
+		downloadOpts = *opts
+	}
+	downloadOpts.DownloadOnly = true
+	return a.Install(pkgs, &downloadOpts)
+}
+
+// InstallLocal installs the provided .rpm files using zypper install, which also installs the dependencies they need.
+func (a *PackageManager) InstallLocal(paths []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	files, err := manager.LocalPackageFiles(paths, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.runTransaction("install", files, opts)
+}
+
+// locateDownloads finds the files of the downloaded packages in dir, searched recursively, and sets their path in AdditionalData["path"].
+// The files are named after the name, version and architecture of the packages, e.g. vim-9.0.1632-1.1.x86_64.rpm.
+func locateDownloads(packages []manager.PackageInfo, dir string) ([]manager.PackageInfo, error) {
+	wanted := make(map[string]int)
+	for i, pkg := range packages {
+		wanted[fmt.Sprintf("%s-%s.%s.rpm", pkg.Name, pkg.NewVersion, pkg.Arch)] = i
+		packages[i].Version = pkg.NewVersion
+		packages[i].NewVersion = ""
+		packages[i].Status = manager.PackageStatusAvailable
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if i, ok := wanted[d.Name()]; ok && !d.IsDir() {
+			if packages[i].AdditionalData == nil {
+				packages[i].AdditionalData = make(map[string]string)
+			}
+			packages[i].AdditionalData["path"] = path
+		}
+		return nil
+	})
+	return packages, err
+}
+
+// Downgrade installs the provided packages, given as "name=version", at that version using the zypper package manager,
+// allowing it to be older than the installed one.
+func (a *PackageManager) Downgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if err := manager.RequireVersionSpecs(pkgs); err != nil {
+		return nil, err
+	}
+	pkgs, err := manager.TranslatePackageSpecs(manager.QualifyArchitecture(pkgs, opts, qualifyArch), manager.PackageSpec.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.runTransaction("install", append([]string{ArgsOldPackage}, pkgs...), opts)
+}
+
+// Delete removes the provided packages, and the dependencies they no longer need, using the zypper package manager.
+func (a *PackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.runTransaction("remove", append([]string{ArgsCleanDeps}, manager.QualifyArchitecture(pkgs, opts, qualifyArch)...), opts)
+}
+
+// Refresh refreshes all enabled repositories using the zypper package manager.
+func (a *PackageManager) Refresh(opts *manager.Options) error {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if opts.Interactive {
+		cmd := manager.Command(opts, pm, "refresh")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return CheckExitError(manager.Run(cmd), opts)
+	}
+
+	cmd := manager.Command(opts, pm, ArgsNonInteractive, "refresh")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, lockEnv(opts)...)
+	out, err := output(cmd, opts)
+	if err = CheckExitError(err, opts); err != nil {
+		return err
+	}
+	if opts.Verbose {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
+
+// Find searches for packages matching the provided keywords using the zypper package manager.
+func (a *PackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	args := append([]string{ArgsNonInteractive, ArgsXMLOut, "search", ArgsDetails, ArgsPackagesOnly}, keywords...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err, opts); err != nil {
+		// zypper exits with 104 when nothing matches the keywords, which is an empty result of a search
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ParseSearchOutput(out, opts)
+}
+
+// ListInstalled lists all installed packages using the zypper package manager.
+func (a *PackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, ArgsNonInteractive, ArgsXMLOut, "search", ArgsDetails, ArgsPackagesOnly, ArgsInstalledOnly)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err, opts); err != nil {
+		return nil, err
+	}
+	packages, err := ParseSearchOutput(out, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// zypper search doesn't report the sizes, which rpm records for the installed packages
+	cmd = manager.Command(opts, "rpm", "-qa", "--queryformat", rpmSizeFormat)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	sizes, err := manager.Output(cmd)
+	if err != nil {
+		opts.Log().Debug("Failed to get the installed sizes", "package_manager", pm, "error", err)
+		return packages, nil
+	}
+	return manager.SetInstalledSizes(packages, ParseRPMSizesOutput(string(sizes))), nil
+}
+
+// ListUpgradable lists all upgradable packages using the zypper package manager.
+func (a *PackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, ArgsNonInteractive, ArgsXMLOut, "list-updates", ArgsPackagesOnly)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err, opts); err != nil {
+		return nil, err
+	}
+
+	return ParseListUpdatesOutput(out, opts)
+}
+
+// ListSecurityUpdates lists the needed security patches using zypper list-patches, with the CVEs they fix and their severity.
+func (a *PackageManager) ListSecurityUpdates(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, ArgsNonInteractive, ArgsXMLOut, "list-patches", ArgsCategorySecurity)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err, opts); err != nil {
+		return nil, err
+	}
+
+	return ParseListPatchesOutput(out, opts)
+}
+
+// Upgrade upgrades the provided packages using the zypper package manager.
+// If no packages are given, all installed packages are updated.
+func (a *PackageManager) Upgrade(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.runTransaction("update", pkgs, opts)
+}
+
+// PlanInstall returns the changes installing the provided packages would make, using zypper install --dry-run.
+func (a *PackageManager) PlanInstall(pkgs []string, opts *manager.Options) (*manager.Plan, error) {
+	pkgs, err := manager.TranslatePackageSpecs(manager.QualifyArchitecture(pkgs, opts, qualifyArch), manager.PackageSpec.String)
+	if err != nil {
+		return nil, err
+	}
+	return a.plan("install", pkgs, opts)
+}
+
+// PlanUpgrade returns the changes upgrading the provided packages, or all packages if none are given, would make.
+func (a *PackageManager) PlanUpgrade(pkgs []string, opts *manager.Options) (*manager.Plan, error) {
+	return a.plan("update", pkgs, opts)
+}
+
+// PlanRemove returns the changes removing the provided packages, and the dependencies they no longer need, would make.
+func (a *PackageManager) PlanRemove(pkgs []string, opts *manager.Options) (*manager.Plan, error) {
+	return a.plan("remove", append([]string{ArgsCleanDeps}, manager.QualifyArchitecture(pkgs, opts, qualifyArch)...), opts)
+}
+
+// qualifyArch qualifies the name of a package with the architecture arch, as name.arch.
+func qualifyArch(name, arch string) string {
+	return name + "." + arch
+}
+
+// plan runs a transaction command in dry-run mode, and returns the plan of the transaction from its XML summary.
+func (a *PackageManager) plan(command string, args []string, opts *manager.Options) (*manager.Plan, error) {
+	args = append([]string{ArgsNonInteractive, ArgsXMLOut, command, ArgsDryRun}, args...)
+	cmd := manager.Command(opts, pm, args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, lockEnv(opts)...)
+
+	out, err := output(cmd, opts)
+	if err = CheckExitError(err, opts); err != nil {
+		return nil, err
+	}
+	return ParsePlanOutput(out, opts)
+}
+
+// UpgradeAll upgrades all installed packages using the zypper package manager.
+func (a *PackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.Upgrade(nil, opts)
+}
+
+// Clean cleans the local package caches of all repositories used by the zypper package manager.
+func (a *PackageManager) Clean(opts *manager.Options) error {
+	cmd := manager.Command(opts, pm, ArgsNonInteractive, "clean", "--all")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, lockEnv(opts)...)
+
+	out, err := output(cmd, opts)
+	if err = CheckExitError(err, opts); err != nil {
+		return err
+	}
+	if opts != nil && opts.Verbose {
+		opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+	}
+	return nil
+}
+
+// GetPackageInfo retrieves package information for the specified package using the zypper package manager.
+func (a *PackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, ArgsNonInteractive, "info", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err, opts); err != nil {
+		return manager.PackageInfo{}, err
+	}
+	info := ParsePackageInfoOutput(string(out), opts)
+
+	// zypper info doesn't report the license, which rpm records for the installed packages
+	if info.Name != "" && info.Status != manager.PackageStatusAvailable {
+		cmd = manager.Command(opts, "rpm", "-q", "--queryformat", "%{LICENSE}", info.Name)
+		manager.SetEnv(cmd, opts, ENV_NonInteractive)
+		if license, err := manager.Output(cmd); err == nil {
+			info.License = strings.TrimSpace(string(license))
+		}
+	}
+	return info, nil
+}
+
+// Owns returns the installed packages owning the specified path, using rpm -qf, as zypper has no such query.
+func (a *PackageManager) Owns(path string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, "rpm", "-qf", "--queryformat", rpmQueryFormat, path)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// rpm exits with 1 when no package owns the path
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseRPMQueryOutput(string(out), opts), nil
+}
+
+// ListFiles returns the files and directories installed by the specified package, using rpm -ql.
+func (a *PackageManager) ListFiles(pkg string, opts *manager.Options) ([]string, error) {
+	cmd := manager.Command(opts, "rpm", "-ql", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListFilesOutput(string(out), opts), nil
+}
+
+// ListGroups lists the patterns of zypper, such as "devel_basis" or "kde", which install a set of packages,
+// and whether they are installed.
+func (a *PackageManager) ListGroups(opts *manager.Options) ([]manager.GroupInfo, error) {
+	cmd := manager.Command(opts, pm, ArgsNonInteractive, ArgsXMLOut, "search", ArgsPatternsOnly)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err, opts); err != nil {
+		return nil, err
+	}
+	return ParsePatternsOutput(out, opts)
+}
+
+// InstallGroup installs the provided patterns using zypper install --type=pattern, one pattern at a time, so that the
+// packages are reported with the pattern they were installed with.
+func (a *PackageManager) InstallGroup(groups []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	for _, group := range groups {
+		installed, err := a.runTransaction("install", []string{ArgsPatternsOnly, group}, opts)
+		packages = append(packages, manager.SetGroup(installed, group)...)
+		if err != nil {
+			return packages, err
+		}
+	}
+	return packages, nil
+}
+
+// RemoveGroup removes the provided patterns, and the packages they no longer need, using zypper remove --type=pattern
+// --clean-deps, one pattern at a time.
+func (a *PackageManager) RemoveGroup(groups []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var packages []manager.PackageInfo
+	for _, group := range groups {
+		removed, err := a.runTransaction("remove", []string{ArgsCleanDeps, ArgsPatternsOnly, group}, opts)
+		packages = append(packages, manager.SetGroup(removed, group)...)
+		if err != nil {
+			return packages, err
+		}
+	}
+	return packages, nil
+}
+
+// Verify checks the files of the provided packages, or of all installed packages if none are given, against the
+// checksums recorded by rpm, using rpm -V, and returns the results of the packages with modified or missing files,
+// or without signature. rpm -V doesn't tell the packages of the files, so they are verified one by one, once found
+// with rpm -qf when all packages are verified. The signatures of packages are checked by rpm when they are installed.
+func (a *PackageManager) Verify(pkgs []string, opts *manager.Options) ([]manager.VerificationResult, error) {
+	signatures, err := rpmSignatures(pkgs, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pkgs) == 0 {
+		out, err := rpmVerify("-a", opts)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		if paths := manager.VerifiedPaths(out); len(paths) > 0 {
+			cmd := manager.Command(opts, "rpm", append([]string{"-qf", "--queryformat", rpmQueryFormat}, paths...)...)
+			manager.SetEnv(cmd, opts, ENV_NonInteractive)
+			// rpm exits with 1 when no package owns some of the paths
+			owners, err := manager.Output(cmd)
+			if exitErr, ok := err.(*exec.ExitError); err != nil && (!ok || exitErr.ExitCode() != 1) {
+				return nil, err
+			}
+			for _, owner := range ParseRPMQueryOutput(string(owners), opts) {
+				if !seen[owner.Name] {
+					seen[owner.Name] = true
+					pkgs = append(pkgs, owner.Name)
+				}
+			}
+		}
+		for name, signature := range signatures {
+			if signature == manager.SignatureUnsigned && !seen[name] {
+				seen[name] = true
+				pkgs = append(pkgs, name)
+			}
+		}
+		sort.Strings(pkgs)
+	}
+
+	var results []manager.VerificationResult
+	for _, pkg := range pkgs {
+		out, err := rpmVerify(pkg, opts)
+		if err != nil {
+			return nil, err
+		}
+		result := manager.VerificationResult{Name: pkg, PackageManager: pm, Signature: signatures[pkg]}
+		manager.ParseVerifyOutput(out, &result)
+		if len(manager.VerifiedPaths(out)) > 0 || result.Failed() {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// NeedsReboot returns whether the system needs a reboot, because core libraries or services were upgraded, as reported
+// by zypper needs-rebooting, or because a kernel newer than the running one is installed.
+func (a *PackageManager) NeedsReboot(opts *manager.Options) (manager.RebootStatus, error) {
+	var status manager.RebootStatus
+	cmd := manager.Command(opts, pm, "--non-interactive", "needs-rebooting")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	// zypper exits with ExitInfRebootNeeded when a reboot is needed
+	err := manager.Run(cmd)
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == ExitInfRebootNeeded {
+		status.Add("core libraries or services were upgraded")
+	} else if err := CheckExitError(err, opts); err != nil {
+		return status, err
+	}
+	err = manager.CheckKernel(&status, a.Owns, opts)
+	return status, err
+}
+
+// Hold locks the provided packages using zypper addlock, so that zypper neither upgrades nor removes them.
+// Locks are not simulated in dry-run mode: the packages that would be locked are returned.
+func (a *PackageManager) Hold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.lock("addlock", pkgs, manager.PackageStatusHeld, opts)
+}
+
+// Unhold removes the locks on the provided packages using zypper removelock.
+func (a *PackageManager) Unhold(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return a.lock("removelock", pkgs, manager.PackageStatusInstalled, opts)
+}
+
+// ListHeld lists the locked packages using zypper locks.
+func (a *PackageManager) ListHeld(opts *manager.Options) ([]manager.PackageInfo, error) {
+	cmd := manager.Command(opts, pm, ArgsNonInteractive, "locks")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err, opts); err != nil {
+		return nil, err
+	}
+	return ParseLocksOutput(string(out), opts), nil
+}
+
+// lock runs the given lock command (addlock or removelock) for the provided packages,
+// and returns them with the given status.
+func (a *PackageManager) lock(command string, pkgs []string, status manager.PackageStatus, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	if !opts.DryRun {
+		args := append([]string{ArgsNonInteractive, command}, pkgs...)
+		cmd := manager.Command(opts, pm, args...)
+		manager.SetEnv(cmd, opts, ENV_NonInteractive, lockEnv(opts)...)
+
+		out, err := output(cmd, opts)
+		if err = CheckExitError(err, opts); err != nil {
+			return nil, err
+		}
+		if opts.Verbose {
+			opts.Log().Debug("Output", "package_manager", pm, "output", string(out))
+		}
+	}
+
+	var packages []manager.PackageInfo
+	for _, name := range pkgs {
+		packages = append(packages, manager.PackageInfo{
+			Name:           name,
+			Status:         status,
+			PackageManager: pm,
+		})
+	}
+	return packages, nil
+}
+
+// runTransaction runs a package transaction command (install, remove, update) with the given arguments,
+// and returns the packages changed by the transaction, as reported by zypper's XML install summary.
+func (a *PackageManager) runTransaction(command string, args []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	args = append([]string{command}, args...)
+	if opts.DryRun {
+		args = append(args, ArgsDryRun)
+	}
+
+	// global options must precede the command
+	if opts.DownloadOnly && opts.DownloadDir != "" {
+		args = append([]string{ArgsPkgCacheDir, opts.DownloadDir}, args...)
+	}
+
+	if opts.Interactive {
+		cmd := manager.Command(opts, pm, args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		return nil, CheckExitError(manager.Run(cmd), opts)
+	}
+
+	args = append([]string{ArgsNonInteractive, ArgsXMLOut}, args...)
+	cmd := manager.Command(opts, pm, args...)
+
+	opts.Log().Info("Running command", "package_manager", pm, "command", pm, "args", args)
+
+	manager.SetEnv(cmd, opts, ENV_NonInteractive, lockEnv(opts)...)
+	out, err := output(cmd, opts)
+	if err = CheckExitError(err, opts); err != nil {
+		return nil, err
+	}
+	return ParseInstallSummaryOutput(out, opts)
+}
+
+// ImportKey imports the OpenPGP key file at source into the rpm database using rpm --import, and returns the keys it added.
+// name is ignored, as rpm has a single keyring. Imports are not simulated in dry-run mode: nothing is imported or returned.
+func (a *PackageManager) ImportKey(name string, source string, opts *manager.Options) ([]manager.KeyInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+	if opts.DryRun {
+		return nil, nil
+	}
+
+	before, err := a.ListKeys(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := manager.Command(opts, "rpm", "--import", source)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+	if out, err := manager.CombinedOutput(cmd); err != nil {
+		return nil, fmt.Errorf("rpm --import %s: %w: %s", source, err, out)
+	}
+
+	after, err := a.ListKeys(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, key := range before {
+		known[key.ID] = true
+	}
+	var keys []manager.KeyInfo
+	for _, key := range after {
+		if !known[key.ID] {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// ListRepositories lists the repositories of zypper, including the disabled ones, using zypper repos.
+func (a *PackageManager) ListRepositories(opts *manager.Options) ([]manager.RepositoryInfo, error) {
+	cmd := manager.Command(opts, pm, ArgsNonInteractive, ArgsXMLOut, "repos")
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err = CheckExitError(err, opts); err != nil {
+		return nil, err
+	}
+	return ParseReposOutput(out, opts)
+}
+
+// ListKeys lists the signing keys imported into the rpm database, identified by the version and release of their gpg-pubkey package.
+func (a *PackageManager) ListKeys(opts *manager.Options) ([]manager.KeyInfo, error) {
+	cmd := manager.Command(opts, "rpm", "-q", "gpg-pubkey", "--queryformat", rpmKeyQueryFormat)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		// rpm exits with 1 when no key is imported
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseKeysOutput(string(out), opts), nil
+}
+
+// RemoveKey removes the signing key with the specified ID from the rpm database, by erasing its gpg-pubkey package.
+func (a *PackageManager) RemoveKey(id string, opts *manager.Options) ([]manager.KeyInfo, error) {
+	if opts == nil {
+		opts = &manager.Options{
+			DryRun:      false,
+			Interactive: false,
+			Verbose:     false,
+		}
+	}
+
+	id = strings.TrimPrefix(id, "gpg-pubkey-")
+	keys, err := a.ListKeys(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []manager.KeyInfo
+	for _, key := range keys {
+		if key.ID == id || strings.HasPrefix(key.ID, id+"-") {
+			removed = append(removed, key)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, fmt.Errorf("no key %s found", id)
+	}
+	if opts.DryRun {
+		return removed, nil
+	}
+
+	for _, key := range removed {
+		cmd := manager.Command(opts, "rpm", "-e", "gpg-pubkey-"+key.ID)
+		manager.SetEnv(cmd, opts, ENV_NonInteractive)
+		if out, err := manager.CombinedOutput(cmd); err != nil {
+			return nil, fmt.Errorf("rpm -e gpg-pubkey-%s: %w: %s", key.ID, err, out)
+		}
+	}
+	return removed, nil
+}
+
+// rpmVerify returns the output of rpm -V for pkg, or for all installed packages if pkg is "-a".
+func rpmVerify(pkg string, opts *manager.Options) (string, error) {
+	cmd := manager.Command(opts, "rpm", "-V", pkg)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	// rpm exits with 1 when some files differ, which is not an error for us
+	out, err := manager.Output(cmd)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 || len(out) == 0 {
+			return "", err
+		}
+	}
+	return string(out), nil
+}
+
+// rpmSignatures returns the status of the signatures of the provided packages, or of all installed packages if none
+// are given, by name.
+func rpmSignatures(pkgs []string, opts *manager.Options) (map[string]manager.SignatureStatus, error) {
+	args := append([]string{"-q", "--queryformat", rpmSignatureFormat}, pkgs...)
+	if len(pkgs) == 0 {
+		args = append(args, "-a")
+	}
+	cmd := manager.Command(opts, "rpm", args...)
+	manager.SetEnv(cmd, opts, ENV_NonInteractive)
+
+	out, err := manager.Output(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSignaturesOutput(string(out), opts), nil
+}
+
+// output runs a non-interactive zypper command and returns its standard output.
+// The command is retried while another process holds the lock of libzypp, for up to opts.LockWait.
+func output(cmd *exec.Cmd, opts *manager.Options) ([]byte, error) {
+	return manager.RetryLocked(cmd, opts, isLocked, func(cmd *exec.Cmd) ([]byte, error) {
+		return manager.Output(cmd)
+	})
+}
+
+// isLocked reports whether a zypper command failed because another process holds the lock of libzypp.
+func isLocked(err error) bool {
+	exitErr, ok := err.(*exec.ExitError)
+	return ok && exitErr.ExitCode() == ExitZyppLocked
+}
+
+// lockEnv returns the environment variables making libzypp itself wait for its lock for up to opts.LockWait, if set.
+func lockEnv(opts *manager.Options) []string {
+	if opts == nil || opts.LockWait <= 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("ZYPP_LOCK_TIMEOUT=%d", int(opts.LockWait.Seconds()))}
+}
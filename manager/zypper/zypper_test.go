@@ -0,0 +1,34 @@
+package zypper_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/zypper"
+)
+
+func TestFindNoMatches(t *testing.T) {
+	// a fake zypper, finding nothing like the real one: exit code 104 (ZYPPER_EXIT_INF_CAP_NOT_FOUND)
+	dir := t.TempDir()
+	script := `#!/bin/sh
+cat <<'XML'
+<?xml version='1.0'?>
+<stream>
+<message type="info">No matching items found.</message>
+</stream>
+XML
+exit 104
+`
+	if err := os.WriteFile(filepath.Join(dir, "zypper"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	zypperManager := &zypper.PackageManager{}
+	packages, err := zypperManager.Find([]string{"no-such-package"}, &manager.Options{})
+	if err != nil || len(packages) != 0 {
+		t.Errorf("Find() = %+v, %+v, want no packages and no error", packages, err)
+	}
+}
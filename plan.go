@@ -0,0 +1,79 @@
+package syspkg
+
+import "github.com/bluet/syspkg/manager"
+
+// Planner is implemented by package managers that can report the changes of an operation in detail, without
+// making them. It is optional: PlanInstall, PlanUpgrade and PlanRemove work with any package manager supporting
+// dry runs, by running the operation with Options.DryRun.
+type Planner interface {
+	// PlanInstall returns the changes installing the specified packages would make, including their dependencies.
+	PlanInstall(pkgs []string, opts *manager.Options) (*manager.Plan, error)
+
+	// PlanUpgrade returns the changes upgrading the specified packages, or all packages if none are specified, would make.
+	PlanUpgrade(pkgs []string, opts *manager.Options) (*manager.Plan, error)
+
+	// PlanRemove returns the changes removing the specified packages would make, including their dependencies.
+	PlanRemove(pkgs []string, opts *manager.Options) (*manager.Plan, error)
+}
+
+// PlanInstall returns the changes installing pkgs with pm would make, with its Planner if implemented, otherwise by
+// running Install in dry-run mode. It returns manager.ErrOperationNotSupported if pm doesn't support dry runs.
+func PlanInstall(pm PackageManager, pkgs []string, opts *manager.Options) (*manager.Plan, error) {
+	if p, ok := pm.(Planner); ok {
+		return p.PlanInstall(pkgs, opts)
+	}
+	return dryRun(pm, manager.PlanActionInstall, opts, func(opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.Install(pkgs, opts)
+	})
+}
+
+// PlanUpgrade returns the changes upgrading pkgs with pm, or all packages if pkgs is empty, would make, with its
+// Planner if implemented, otherwise by running the upgrade in dry-run mode. It returns manager.ErrOperationNotSupported
+// if pm doesn't support dry runs, or upgrading specific packages when pkgs isn't empty.
+func PlanUpgrade(pm PackageManager, pkgs []string, opts *manager.Options) (*manager.Plan, error) {
+	if p, ok := pm.(Planner); ok {
+		return p.PlanUpgrade(pkgs, opts)
+	}
+	return dryRun(pm, manager.PlanActionUpgrade, opts, func(opts *manager.Options) ([]manager.PackageInfo, error) {
+		if len(pkgs) == 0 {
+			return pm.UpgradeAll(opts)
+		}
+		u, ok := pm.(Upgrader)
+		if !ok {
+			return nil, manager.ErrOperationNotSupported
+		}
+		return u.Upgrade(pkgs, opts)
+	})
+}
+
+// PlanRemove returns the changes removing pkgs with pm would make, with its Planner if implemented, otherwise by
+// running Delete in dry-run mode. It returns manager.ErrOperationNotSupported if pm doesn't support dry runs.
+func PlanRemove(pm PackageManager, pkgs []string, opts *manager.Options) (*manager.Plan, error) {
+	if p, ok := pm.(Planner); ok {
+		return p.PlanRemove(pkgs, opts)
+	}
+	return dryRun(pm, manager.PlanActionRemove, opts, func(opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.Delete(pkgs, opts)
+	})
+}
+
+// dryRun runs an operation of pm with a non-interactive dry-run copy of opts, and returns its plan.
+// Package managers not supporting dry runs would make the changes, so the operation isn't run for them.
+func dryRun(pm PackageManager, operation manager.PlanAction, opts *manager.Options, run func(opts *manager.Options) ([]manager.PackageInfo, error)) (*manager.Plan, error) {
+	if !CapabilitiesOf(pm).DryRun {
+		return nil, manager.ErrOperationNotSupported
+	}
+
+	var dryRunOpts manager.Options
+	if opts != nil {
+		dryRunOpts = *opts
+	}
+	// interactive commands print their output rather than returning the packages
+	dryRunOpts.DryRun, dryRunOpts.Interactive, dryRunOpts.AssumeYes = true, false, true
+
+	packages, err := run(&dryRunOpts)
+	if err != nil {
+		return nil, err
+	}
+	return manager.NewPlan(pm.GetPackageManager(), operation, packages), nil
+}
@@ -0,0 +1,221 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bluet/syspkg"
+	syspkgv1 "github.com/bluet/syspkg/api/syspkg/v1"
+	"github.com/bluet/syspkg/manager"
+)
+
+// ErrUnknownPackageManager is returned, wrapped, for the package managers the server doesn't serve.
+var ErrUnknownPackageManager = errors.New("remote: unknown package manager")
+
+// ErrNoResult is returned when the server ends an operation without sending its result.
+var ErrNoResult = errors.New("remote: the server sent no result")
+
+// Error is an error returned by the server. It wraps the error of the syspkg library of its gRPC code, if any, such as
+// manager.ErrOperationNotSupported for Unimplemented, so that it can be checked with errors.Is like a local error.
+type Error struct {
+	// Code is the gRPC code of the error.
+	Code codes.Code
+
+	// Message is the message of the error.
+	Message string
+}
+
+// Error returns the message of the error.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the error of the syspkg library of the code of the error, or nil.
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case codes.NotFound:
+		return ErrUnknownPackageManager
+	case codes.Unimplemented:
+		return manager.ErrOperationNotSupported
+	case codes.InvalidArgument:
+		return manager.ErrInvalidPackageSpec
+	case codes.Unavailable:
+		return manager.ErrLocked
+	case codes.Canceled:
+		return manager.ErrInterrupted
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// Client calls the syspkg gRPC API of a host, such as served by syspkg-grpcd.
+type Client struct {
+	conn    grpc.ClientConnInterface
+	service syspkgv1.PackageManagerServiceClient
+}
+
+// Dial returns a Client of the server at target, such as "unix:///run/syspkg/grpcd.sock" or "host:50051", connecting
+// with opts, such as grpc.WithTransportCredentials. The connection is made by the first call.
+func Dial(target string, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns a Client calling the server through conn.
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn, service: syspkgv1.NewPackageManagerServiceClient(conn)}
+}
+
+// Close closes the connection of the client, if it has one to close.
+func (c *Client) Close() error {
+	if closer, ok := c.conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// PackageManagers returns the package managers of the server, with their capabilities, by name.
+func (c *Client) PackageManagers(ctx context.Context) (map[string]manager.Capabilities, error) {
+	resp, err := c.service.ListPackageManagers(ctx, &syspkgv1.ListPackageManagersRequest{})
+	if err != nil {
+		return nil, clientError(err)
+	}
+	pms := make(map[string]manager.Capabilities, len(resp.GetPackageManagers()))
+	for name, capabilities := range resp.GetPackageManagers() {
+		pms[name] = fromCapabilities(capabilities)
+	}
+	return pms, nil
+}
+
+// PackageManager returns the package manager name of the server. Its operations send their Options to the server,
+// except Context, replaced by the context of the operation, and Progress, which receives the progress events the
+// server streams.
+func (c *Client) PackageManager(name string) syspkg.ContextPackageManager {
+	return &packageManager{client: c, name: name}
+}
+
+// packageManager is a package manager of the server.
+type packageManager struct {
+	client *Client
+	name   string
+}
+
+// IsAvailable returns true: the package managers of the server are checked by their operations.
+func (pm *packageManager) IsAvailable() bool {
+	return true
+}
+
+// GetPackageManager returns the name of the package manager.
+func (pm *packageManager) GetPackageManager() string {
+	return pm.name
+}
+
+// Install installs pkgs.
+func (pm *packageManager) Install(ctx context.Context, pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return pm.operation(ctx, pm.client.service.Install, pkgs, opts)
+}
+
+// Delete removes pkgs.
+func (pm *packageManager) Delete(ctx context.Context, pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return pm.operation(ctx, pm.client.service.Delete, pkgs, opts)
+}
+
+// Upgrade upgrades pkgs, or all the upgradable packages if none are given.
+func (pm *packageManager) Upgrade(ctx context.Context, pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return pm.operation(ctx, pm.client.service.Upgrade, pkgs, opts)
+}
+
+// UpgradeAll upgrades all the upgradable packages.
+func (pm *packageManager) UpgradeAll(ctx context.Context, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return pm.Upgrade(ctx, nil, opts)
+}
+
+// Find searches the packages matching keywords.
+func (pm *packageManager) Find(ctx context.Context, keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	resp, err := pm.client.service.Find(ctx, &syspkgv1.FindRequest{PackageManager: pm.name, Keywords: keywords, Options: toOptions(opts)})
+	return packages(resp, err)
+}
+
+// ListInstalled lists the installed packages.
+func (pm *packageManager) ListInstalled(ctx context.Context, opts *manager.Options) ([]manager.PackageInfo, error) {
+	resp, err := pm.client.service.ListInstalled(ctx, &syspkgv1.ListRequest{PackageManager: pm.name, Options: toOptions(opts)})
+	return packages(resp, err)
+}
+
+// ListUpgradable lists the packages with a newer version available.
+func (pm *packageManager) ListUpgradable(ctx context.Context, opts *manager.Options) ([]manager.PackageInfo, error) {
+	resp, err := pm.client.service.ListUpgradable(ctx, &syspkgv1.ListRequest{PackageManager: pm.name, Options: toOptions(opts)})
+	return packages(resp, err)
+}
+
+// GetPackageInfo returns information about pkg.
+func (pm *packageManager) GetPackageInfo(ctx context.Context, pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	resp, err := pm.client.service.GetPackageInfo(ctx, &syspkgv1.GetPackageInfoRequest{PackageManager: pm.name, Package: pkg, Options: toOptions(opts)})
+	pkgs, err := packages(resp, err)
+	if err != nil {
+		return manager.PackageInfo{}, err
+	}
+	if len(pkgs) == 0 {
+		return manager.PackageInfo{}, ErrNoResult
+	}
+	return pkgs[0], nil
+}
+
+// Refresh refreshes the package indexes.
+func (pm *packageManager) Refresh(ctx context.Context, opts *manager.Options) error {
+	_, err := pm.client.service.Refresh(ctx, &syspkgv1.RefreshRequest{PackageManager: pm.name, Options: toOptions(opts)})
+	return clientError(err)
+}
+
+// operation runs the changing operation call of the server on pkgs, reporting the progress events it streams to
+// opts.Progress, and returns its result.
+func (pm *packageManager) operation(ctx context.Context, call func(ctx context.Context, in *syspkgv1.PackagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[syspkgv1.OperationUpdate], error), pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	stream, err := call(ctx, &syspkgv1.PackagesRequest{PackageManager: pm.name, Packages: pkgs, Options: toOptions(opts)})
+	if err != nil {
+		return nil, clientError(err)
+	}
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return nil, ErrNoResult
+		}
+		if err != nil {
+			return nil, clientError(err)
+		}
+		if progress := update.GetProgress(); progress != nil {
+			if opts != nil && opts.Progress != nil {
+				opts.Progress.Report(fromProgressEvent(progress))
+			}
+			continue
+		}
+		return fromPackagesResponse(update.GetResult()), nil
+	}
+}
+
+// packages returns the packages of resp, or the error of the call returning it.
+func packages(resp *syspkgv1.PackagesResponse, err error) ([]manager.PackageInfo, error) {
+	if err != nil {
+		return nil, clientError(err)
+	}
+	return fromPackagesResponse(resp), nil
+}
+
+// clientError returns the gRPC status error err as an Error, and other errors, such as failing to connect, as is.
+func clientError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return &Error{Code: st.Code(), Message: st.Message()}
+	}
+	return err
+}
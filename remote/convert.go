@@ -0,0 +1,159 @@
+package remote
+
+import (
+	"time"
+
+	syspkgv1 "github.com/bluet/syspkg/api/syspkg/v1"
+	"github.com/bluet/syspkg/manager"
+)
+
+// packageStatuses are the protobuf statuses of the packages, by status.
+var packageStatuses = map[manager.PackageStatus]syspkgv1.PackageStatus{
+	manager.PackageStatusInstalled:   syspkgv1.PackageStatus_PACKAGE_STATUS_INSTALLED,
+	manager.PackageStatusUpgradable:  syspkgv1.PackageStatus_PACKAGE_STATUS_UPGRADABLE,
+	manager.PackageStatusAvailable:   syspkgv1.PackageStatus_PACKAGE_STATUS_AVAILABLE,
+	manager.PackageStatusUnknown:     syspkgv1.PackageStatus_PACKAGE_STATUS_UNKNOWN,
+	manager.PackageStatusConfigFiles: syspkgv1.PackageStatus_PACKAGE_STATUS_CONFIG_FILES,
+	manager.PackageStatusBroken:      syspkgv1.PackageStatus_PACKAGE_STATUS_BROKEN,
+	manager.PackageStatusHeld:        syspkgv1.PackageStatus_PACKAGE_STATUS_HELD,
+}
+
+// progressPhases are the protobuf phases of the operations, by phase.
+var progressPhases = map[manager.ProgressPhase]syspkgv1.ProgressPhase{
+	manager.ProgressPhaseDownload: syspkgv1.ProgressPhase_PROGRESS_PHASE_DOWNLOAD,
+	manager.ProgressPhaseInstall:  syspkgv1.ProgressPhase_PROGRESS_PHASE_INSTALL,
+	manager.ProgressPhaseRemove:   syspkgv1.ProgressPhase_PROGRESS_PHASE_REMOVE,
+	manager.ProgressPhaseError:    syspkgv1.ProgressPhase_PROGRESS_PHASE_ERROR,
+}
+
+// toPackagesResponse returns pkgs as a protobuf response.
+func toPackagesResponse(pkgs []manager.PackageInfo) *syspkgv1.PackagesResponse {
+	resp := &syspkgv1.PackagesResponse{Packages: make([]*syspkgv1.PackageInfo, 0, len(pkgs))}
+	for _, pkg := range pkgs {
+		resp.Packages = append(resp.Packages, &syspkgv1.PackageInfo{
+			Name:           pkg.Name,
+			Version:        pkg.Version,
+			NewVersion:     pkg.NewVersion,
+			Status:         packageStatuses[pkg.Status],
+			Category:       pkg.Category,
+			Arch:           pkg.Arch,
+			PackageManager: pkg.PackageManager,
+			AdditionalData: pkg.AdditionalData,
+		})
+	}
+	return resp
+}
+
+// fromPackagesResponse returns the packages of resp.
+func fromPackagesResponse(resp *syspkgv1.PackagesResponse) []manager.PackageInfo {
+	pkgs := make([]manager.PackageInfo, 0, len(resp.GetPackages()))
+	for _, pkg := range resp.GetPackages() {
+		info := manager.PackageInfo{
+			Name:           pkg.GetName(),
+			Version:        pkg.GetVersion(),
+			NewVersion:     pkg.GetNewVersion(),
+			Category:       pkg.GetCategory(),
+			Arch:           pkg.GetArch(),
+			PackageManager: pkg.GetPackageManager(),
+			AdditionalData: pkg.GetAdditionalData(),
+		}
+		for status, value := range packageStatuses {
+			if value == pkg.GetStatus() {
+				info.Status = status
+			}
+		}
+		pkgs = append(pkgs, info)
+	}
+	return pkgs
+}
+
+// toProgressEvent returns event as a protobuf message.
+func toProgressEvent(event manager.ProgressEvent) *syspkgv1.ProgressEvent {
+	return &syspkgv1.ProgressEvent{
+		PackageManager: event.PackageManager,
+		Phase:          progressPhases[event.Phase],
+		Package:        event.Package,
+		Percent:        event.Percent,
+		BytesDone:      event.BytesDone,
+		BytesTotal:     event.BytesTotal,
+		Message:        event.Message,
+	}
+}
+
+// fromProgressEvent returns the progress event of msg.
+func fromProgressEvent(msg *syspkgv1.ProgressEvent) manager.ProgressEvent {
+	event := manager.ProgressEvent{
+		PackageManager: msg.GetPackageManager(),
+		Package:        msg.GetPackage(),
+		Percent:        msg.GetPercent(),
+		BytesDone:      msg.GetBytesDone(),
+		BytesTotal:     msg.GetBytesTotal(),
+		Message:        msg.GetMessage(),
+	}
+	for phase, value := range progressPhases {
+		if value == msg.GetPhase() {
+			event.Phase = phase
+		}
+	}
+	return event
+}
+
+// toOptions returns the options of opts sent to the server, without DownloadDir and CustomCommandArgs, which it
+// doesn't accept; nil options are the defaults. The timeout is rounded up to the second.
+func toOptions(opts *manager.Options) *syspkgv1.Options {
+	if opts == nil {
+		return nil
+	}
+	return &syspkgv1.Options{
+		DryRun:         opts.DryRun,
+		Verbose:        opts.Verbose,
+		AssumeYes:      opts.AssumeYes,
+		Environment:    opts.Environment,
+		DownloadOnly:   opts.DownloadOnly,
+		TimeoutSeconds: int64((opts.Timeout + time.Second - 1) / time.Second),
+	}
+}
+
+// toCapabilities returns c as a protobuf message.
+func toCapabilities(c manager.Capabilities) *syspkgv1.Capabilities {
+	return &syspkgv1.Capabilities{
+		Search:           c.Search,
+		Delete:           c.Delete,
+		VersionedInstall: c.VersionedInstall,
+		DryRun:           c.DryRun,
+		ListUpgradable:   c.ListUpgradable,
+		Upgrade:          c.Upgrade,
+		Downgrade:        c.Downgrade,
+		Hold:             c.Hold,
+		SecurityUpdates:  c.SecurityUpdates,
+		Dependencies:     c.Dependencies,
+		FileOwner:        c.FileOwner,
+		FileList:         c.FileList,
+		Keys:             c.Keys,
+		Download:         c.Download,
+		PackageNames:     c.PackageNames,
+		LocalInstall:     c.LocalInstall,
+	}
+}
+
+// fromCapabilities returns the capabilities of msg.
+func fromCapabilities(msg *syspkgv1.Capabilities) manager.Capabilities {
+	return manager.Capabilities{
+		Search:           msg.GetSearch(),
+		Delete:           msg.GetDelete(),
+		VersionedInstall: msg.GetVersionedInstall(),
+		DryRun:           msg.GetDryRun(),
+		ListUpgradable:   msg.GetListUpgradable(),
+		Upgrade:          msg.GetUpgrade(),
+		Downgrade:        msg.GetDowngrade(),
+		Hold:             msg.GetHold(),
+		SecurityUpdates:  msg.GetSecurityUpdates(),
+		Dependencies:     msg.GetDependencies(),
+		FileOwner:        msg.GetFileOwner(),
+		FileList:         msg.GetFileList(),
+		Keys:             msg.GetKeys(),
+		Download:         msg.GetDownload(),
+		PackageNames:     msg.GetPackageNames(),
+		LocalInstall:     msg.GetLocalInstall(),
+	}
+}
@@ -0,0 +1,149 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bluet/syspkg"
+	syspkgv1 "github.com/bluet/syspkg/api/syspkg/v1"
+	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/remote"
+)
+
+// fakePackageManager is a package manager finding and installing any package, reporting the progress of installs,
+// and supporting no other operation.
+type fakePackageManager struct{}
+
+func (f *fakePackageManager) IsAvailable() bool         { return true }
+func (f *fakePackageManager) GetPackageManager() string { return "fake" }
+func (f *fakePackageManager) Install(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	var installed []manager.PackageInfo
+	for _, pkg := range pkgs {
+		opts.Progress.Report(manager.ProgressEvent{PackageManager: "fake", Phase: manager.ProgressPhaseInstall, Package: pkg, Percent: 100})
+		installed = append(installed, manager.PackageInfo{Name: pkg, Version: "1.0", NewVersion: "1.0", Status: manager.PackageStatusInstalled, PackageManager: "fake"})
+	}
+	return installed, nil
+}
+func (f *fakePackageManager) Delete(pkgs []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+func (f *fakePackageManager) Find(keywords []string, opts *manager.Options) ([]manager.PackageInfo, error) {
+	return []manager.PackageInfo{{
+		Name:           keywords[0],
+		NewVersion:     "1.0",
+		Status:         manager.PackageStatusAvailable,
+		PackageManager: "fake",
+		AdditionalData: map[string]string{"environment": opts.Environment},
+	}}, nil
+}
+func (f *fakePackageManager) ListInstalled(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+func (f *fakePackageManager) ListUpgradable(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+func (f *fakePackageManager) UpgradeAll(opts *manager.Options) ([]manager.PackageInfo, error) {
+	return nil, manager.ErrOperationNotSupported
+}
+func (f *fakePackageManager) Refresh(opts *manager.Options) error { return nil }
+func (f *fakePackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	return manager.PackageInfo{}, manager.ErrOperationNotSupported
+}
+
+// progressRecorder records the progress events it receives.
+type progressRecorder struct {
+	events []manager.ProgressEvent
+}
+
+func (r *progressRecorder) Report(event manager.ProgressEvent) {
+	r.events = append(r.events, event)
+}
+
+// newClient returns a client of a server of pms, served in memory until the test ends.
+func newClient(t *testing.T, pms map[string]syspkg.PackageManager) *remote.Client {
+	l := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	syspkgv1.RegisterPackageManagerServiceServer(server, &remote.Server{PackageManagers: pms})
+	go func() { _ = server.Serve(l) }()
+	t.Cleanup(server.Stop)
+
+	client, err := remote.Dial("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t, map[string]syspkg.PackageManager{"fake": &fakePackageManager{}})
+
+	pms, err := client.PackageManagers(ctx)
+	if err != nil {
+		t.Fatalf("PackageManagers() error = %v", err)
+	}
+	if expected := map[string]manager.Capabilities{"fake": syspkg.CapabilitiesOf(&fakePackageManager{})}; !reflect.DeepEqual(pms, expected) {
+		t.Errorf("PackageManagers() = %+v, want %+v", pms, expected)
+	}
+
+	pm := client.PackageManager("fake")
+	found, err := pm.Find(ctx, []string{"vim"}, &manager.Options{Environment: "base"})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	expectedFound := []manager.PackageInfo{{
+		Name:           "vim",
+		NewVersion:     "1.0",
+		Status:         manager.PackageStatusAvailable,
+		PackageManager: "fake",
+		AdditionalData: map[string]string{"environment": "base"},
+	}}
+	if !reflect.DeepEqual(found, expectedFound) {
+		t.Errorf("Find() = %+v, want %+v", found, expectedFound)
+	}
+
+	progress := &progressRecorder{}
+	installed, err := pm.Install(ctx, []string{"vim", "git"}, &manager.Options{Progress: progress})
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	expectedInstalled := []manager.PackageInfo{
+		{Name: "vim", Version: "1.0", NewVersion: "1.0", Status: manager.PackageStatusInstalled, PackageManager: "fake"},
+		{Name: "git", Version: "1.0", NewVersion: "1.0", Status: manager.PackageStatusInstalled, PackageManager: "fake"},
+	}
+	if !reflect.DeepEqual(installed, expectedInstalled) {
+		t.Errorf("Install() = %+v, want %+v", installed, expectedInstalled)
+	}
+	expectedEvents := []manager.ProgressEvent{
+		{PackageManager: "fake", Phase: manager.ProgressPhaseInstall, Package: "vim", Percent: 100},
+		{PackageManager: "fake", Phase: manager.ProgressPhaseInstall, Package: "git", Percent: 100},
+	}
+	if !reflect.DeepEqual(progress.events, expectedEvents) {
+		t.Errorf("Install() progress = %+v, want %+v", progress.events, expectedEvents)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t, map[string]syspkg.PackageManager{"fake": &fakePackageManager{}})
+
+	if _, err := client.PackageManager("fake").Delete(ctx, []string{"vim"}, nil); !errors.Is(err, manager.ErrOperationNotSupported) {
+		t.Errorf("Delete() error = %v, want %v", err, manager.ErrOperationNotSupported)
+	}
+	if _, err := client.PackageManager("fake").UpgradeAll(ctx, nil); !errors.Is(err, manager.ErrOperationNotSupported) {
+		t.Errorf("UpgradeAll() error = %v, want %v", err, manager.ErrOperationNotSupported)
+	}
+	if _, err := client.PackageManager("apt").ListInstalled(ctx, nil); !errors.Is(err, remote.ErrUnknownPackageManager) {
+		t.Errorf("ListInstalled() error = %v, want %v", err, remote.ErrUnknownPackageManager)
+	}
+}
@@ -0,0 +1,201 @@
+// Package remote implements the syspkg gRPC API, defined in api/syspkg/v1, for the remote control of the package
+// managers of a host by orchestration systems: Server runs the operations of the package managers of the host it
+// runs on, served by the syspkg-grpcd command, and Client calls them on a remote host, with the types of the syspkg
+// library. Changing operations stream their progress events to Options.Progress of the client before their result.
+//
+// This package is part of the syspkg library.
+package remote
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bluet/syspkg"
+	syspkgv1 "github.com/bluet/syspkg/api/syspkg/v1"
+	"github.com/bluet/syspkg/manager"
+)
+
+// Server runs the operations of the syspkg gRPC API with the package managers of the host.
+// Register it with syspkgv1.RegisterPackageManagerServiceServer.
+type Server struct {
+	syspkgv1.UnimplementedPackageManagerServiceServer
+
+	// PackageManagers are the package managers served, by name.
+	PackageManagers map[string]syspkg.PackageManager
+}
+
+// ListPackageManagers returns the package managers served, with their capabilities.
+func (s *Server) ListPackageManagers(ctx context.Context, req *syspkgv1.ListPackageManagersRequest) (*syspkgv1.ListPackageManagersResponse, error) {
+	resp := &syspkgv1.ListPackageManagersResponse{PackageManagers: make(map[string]*syspkgv1.Capabilities, len(s.PackageManagers))}
+	for name, pm := range s.PackageManagers {
+		resp.PackageManagers[name] = toCapabilities(syspkg.CapabilitiesOf(pm))
+	}
+	return resp, nil
+}
+
+// Find searches the packages matching the keywords of req.
+func (s *Server) Find(ctx context.Context, req *syspkgv1.FindRequest) (*syspkgv1.PackagesResponse, error) {
+	return s.query(ctx, req.GetPackageManager(), req.GetOptions(), func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.Find(req.GetKeywords(), opts)
+	})
+}
+
+// ListInstalled lists the installed packages.
+func (s *Server) ListInstalled(ctx context.Context, req *syspkgv1.ListRequest) (*syspkgv1.PackagesResponse, error) {
+	return s.query(ctx, req.GetPackageManager(), req.GetOptions(), syspkg.PackageManager.ListInstalled)
+}
+
+// ListUpgradable lists the packages with a newer version available.
+func (s *Server) ListUpgradable(ctx context.Context, req *syspkgv1.ListRequest) (*syspkgv1.PackagesResponse, error) {
+	return s.query(ctx, req.GetPackageManager(), req.GetOptions(), syspkg.PackageManager.ListUpgradable)
+}
+
+// GetPackageInfo returns information about the package of req.
+func (s *Server) GetPackageInfo(ctx context.Context, req *syspkgv1.GetPackageInfoRequest) (*syspkgv1.PackagesResponse, error) {
+	return s.query(ctx, req.GetPackageManager(), req.GetOptions(), func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		info, err := pm.GetPackageInfo(req.GetPackage(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return []manager.PackageInfo{info}, nil
+	})
+}
+
+// Install installs the packages of req, streaming its progress.
+func (s *Server) Install(req *syspkgv1.PackagesRequest, stream grpc.ServerStreamingServer[syspkgv1.OperationUpdate]) error {
+	return s.operation(req, stream, func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.Install(req.GetPackages(), opts)
+	})
+}
+
+// Delete removes the packages of req, streaming its progress.
+func (s *Server) Delete(req *syspkgv1.PackagesRequest, stream grpc.ServerStreamingServer[syspkgv1.OperationUpdate]) error {
+	return s.operation(req, stream, func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		return pm.Delete(req.GetPackages(), opts)
+	})
+}
+
+// Upgrade upgrades the packages of req, or all the upgradable packages if none are given, streaming its progress.
+// Upgrading specific packages requires package managers implementing syspkg.Upgrader.
+func (s *Server) Upgrade(req *syspkgv1.PackagesRequest, stream grpc.ServerStreamingServer[syspkgv1.OperationUpdate]) error {
+	return s.operation(req, stream, func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error) {
+		if len(req.GetPackages()) == 0 {
+			return pm.UpgradeAll(opts)
+		}
+		upgrader, ok := pm.(syspkg.Upgrader)
+		if !ok {
+			return nil, manager.ErrOperationNotSupported
+		}
+		return upgrader.Upgrade(req.GetPackages(), opts)
+	})
+}
+
+// Refresh refreshes the package indexes.
+func (s *Server) Refresh(ctx context.Context, req *syspkgv1.RefreshRequest) (*syspkgv1.RefreshResponse, error) {
+	pm, err := s.packageManager(req.GetPackageManager())
+	if err != nil {
+		return nil, err
+	}
+	if err := pm.Refresh(fromOptions(ctx, req.GetOptions())); err != nil {
+		return nil, statusError(err)
+	}
+	return &syspkgv1.RefreshResponse{}, nil
+}
+
+// query runs a query with the package manager name.
+func (s *Server) query(ctx context.Context, name string, o *syspkgv1.Options, query func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error)) (*syspkgv1.PackagesResponse, error) {
+	pm, err := s.packageManager(name)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := query(pm, fromOptions(ctx, o))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toPackagesResponse(pkgs), nil
+}
+
+// operation runs a changing operation with the package manager of req, sending its progress events to stream, then
+// its result.
+func (s *Server) operation(req *syspkgv1.PackagesRequest, stream grpc.ServerStreamingServer[syspkgv1.OperationUpdate], operation func(pm syspkg.PackageManager, opts *manager.Options) ([]manager.PackageInfo, error)) error {
+	pm, err := s.packageManager(req.GetPackageManager())
+	if err != nil {
+		return err
+	}
+	opts := fromOptions(stream.Context(), req.GetOptions())
+	opts.Progress = &streamReporter{stream: stream}
+	pkgs, err := operation(pm, opts)
+	if err != nil {
+		return statusError(err)
+	}
+	return stream.Send(&syspkgv1.OperationUpdate{Update: &syspkgv1.OperationUpdate_Result{Result: toPackagesResponse(pkgs)}})
+}
+
+// packageManager returns the package manager name, or a NotFound error if it isn't served.
+func (s *Server) packageManager(name string) (syspkg.PackageManager, error) {
+	pm, ok := s.PackageManagers[name]
+	if !ok {
+		names := make([]string, 0, len(s.PackageManagers))
+		for name := range s.PackageManagers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, status.Errorf(codes.NotFound, "unknown package manager %q, expected one of %v", name, names)
+	}
+	return pm, nil
+}
+
+// streamReporter sends the progress events of an operation to its stream. The events that can't be sent, once the
+// client is gone, are dropped, as the operation is then interrupted through the context of the stream.
+type streamReporter struct {
+	mu     sync.Mutex
+	stream grpc.ServerStreamingServer[syspkgv1.OperationUpdate]
+}
+
+// Report sends event to the stream.
+func (r *streamReporter) Report(event manager.ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.stream.Send(&syspkgv1.OperationUpdate{Update: &syspkgv1.OperationUpdate_Progress{Progress: toProgressEvent(event)}})
+}
+
+// statusError returns err as a gRPC status error, with the code of its kind: Unimplemented for the operations the
+// package manager doesn't support, Unavailable when it is locked by another process, Canceled when the operation
+// was interrupted, and Unknown otherwise.
+func statusError(err error) error {
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, manager.ErrOperationNotSupported), errors.Is(err, manager.ErrVersionNotSupported):
+		code = codes.Unimplemented
+	case errors.Is(err, manager.ErrInvalidPackageSpec):
+		code = codes.InvalidArgument
+	case errors.Is(err, manager.ErrLocked):
+		code = codes.Unavailable
+	case errors.Is(err, manager.ErrInterrupted), errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
+
+// fromOptions returns the options of an operation run with ctx. The download directory and the custom arguments of
+// the commands aren't options of the API, not to write files or pass arguments as the user of the server.
+func fromOptions(ctx context.Context, o *syspkgv1.Options) *manager.Options {
+	return &manager.Options{
+		Context:      ctx,
+		DryRun:       o.GetDryRun(),
+		Verbose:      o.GetVerbose(),
+		AssumeYes:    o.GetAssumeYes(),
+		Environment:  o.GetEnvironment(),
+		DownloadOnly: o.GetDownloadOnly(),
+		Timeout:      time.Duration(o.GetTimeoutSeconds()) * time.Second,
+	}
+}
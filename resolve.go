@@ -0,0 +1,81 @@
+package syspkg
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bluet/syspkg/manager"
+)
+
+// InstallOrder returns the names of pms in their order of preference to install packages, the package managers of
+// priority first, as sorted by manager.SortByPriority.
+func InstallOrder(pms map[string]PackageManager, priority []string) []string {
+	names := make([]string, 0, len(pms))
+	for name := range pms {
+		names = append(names, name)
+	}
+	manager.SortByPriority(names, priority)
+	return names
+}
+
+// ResolveInstall assigns pkgs to the package managers of pms installing them, following policy, and returns the
+// packages by package manager name:
+//   - manager.InstallPolicyBest assigns each package to the package manager of highest priority providing it, as
+//     found with GetPackageInfo;
+//   - manager.InstallPolicyFirst assigns them to the package manager of highest priority, the caller trying the
+//     next ones of InstallOrder for the packages it fails to install;
+//   - manager.InstallPolicyExplicit assigns them to the only package manager of pms, and fails with
+//     manager.ErrAmbiguousPackageManager if there are several;
+//   - manager.InstallPolicyAll assigns them to every package manager.
+//
+// With a single package manager, every policy assigns all the packages to it. The packages that can't be assigned
+// are left out, and reported in the returned error.
+func ResolveInstall(pms map[string]PackageManager, pkgs []string, policy manager.InstallPolicy, priority []string, opts *manager.Options) (map[string][]string, error) {
+	resolved := make(map[string][]string)
+	if len(pkgs) == 0 {
+		return resolved, nil
+	}
+	order := InstallOrder(pms, priority)
+	if len(order) == 0 {
+		return resolved, errors.New("no package manager selected")
+	}
+
+	switch {
+	case len(order) == 1 || policy == manager.InstallPolicyFirst:
+		resolved[order[0]] = pkgs
+	case policy == manager.InstallPolicyAll:
+		for _, name := range order {
+			resolved[name] = pkgs
+		}
+	case policy == manager.InstallPolicyExplicit:
+		return resolved, fmt.Errorf("%w: select one of %v, or prefix the packages with one, e.g. %s:%s",
+			manager.ErrAmbiguousPackageManager, order, order[0], pkgs[0])
+	default:
+		var errs []error
+		for _, pkg := range pkgs {
+			name, err := bestPackageManager(pms, order, pkg, opts)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			resolved[name] = append(resolved[name], pkg)
+		}
+		return resolved, errors.Join(errs...)
+	}
+	return resolved, nil
+}
+
+// bestPackageManager returns the first package manager of order providing pkg, a name or a "name=version" specifier.
+func bestPackageManager(pms map[string]PackageManager, order []string, pkg string, opts *manager.Options) (string, error) {
+	spec, err := manager.ParsePackageSpec(pkg)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range order {
+		if info, err := pms[name].GetPackageInfo(spec.Name, opts); err == nil && info.Name != "" {
+			opts.Log().Debug("Resolved the package manager installing a package", "package", pkg, "package_manager", name)
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no package manager of %v provides %s", order, pkg)
+}
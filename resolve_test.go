@@ -0,0 +1,62 @@
+package syspkg_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager"
+)
+
+// providingPackageManager is a package manager providing a fixed set of packages.
+type providingPackageManager struct {
+	syspkg.PackageManager
+	packages []string
+}
+
+func (pm *providingPackageManager) GetPackageInfo(pkg string, opts *manager.Options) (manager.PackageInfo, error) {
+	for _, name := range pm.packages {
+		if name == pkg {
+			return manager.PackageInfo{Name: name}, nil
+		}
+	}
+	return manager.PackageInfo{}, errors.New("package not found")
+}
+
+func TestResolveInstall(t *testing.T) {
+	pms := map[string]syspkg.PackageManager{
+		"apt":  &providingPackageManager{packages: []string{"vim", "htop"}},
+		"snap": &providingPackageManager{packages: []string{"vim", "code"}},
+		"pip":  &providingPackageManager{packages: []string{"requests"}},
+	}
+	pkgs := []string{"vim", "code", "requests=2.31.0"}
+
+	tests := []struct {
+		policy   manager.InstallPolicy
+		priority []string
+		expected map[string][]string
+	}{
+		{manager.InstallPolicyBest, nil, map[string][]string{"apt": {"vim"}, "snap": {"code"}, "pip": {"requests=2.31.0"}}},
+		{manager.InstallPolicyBest, []string{"snap"}, map[string][]string{"snap": {"vim", "code"}, "pip": {"requests=2.31.0"}}},
+		{manager.InstallPolicyFirst, []string{"pip"}, map[string][]string{"pip": pkgs}},
+		{manager.InstallPolicyAll, nil, map[string][]string{"apt": pkgs, "snap": pkgs, "pip": pkgs}},
+	}
+	for _, tt := range tests {
+		actual, err := syspkg.ResolveInstall(pms, pkgs, tt.policy, tt.priority, nil)
+		if err != nil {
+			t.Fatalf("ResolveInstall(%s, %v) error = %+v", tt.policy, tt.priority, err)
+		}
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("ResolveInstall(%s, %v) = %+v, want %+v", tt.policy, tt.priority, actual, tt.expected)
+		}
+	}
+
+	if _, err := syspkg.ResolveInstall(pms, pkgs, manager.InstallPolicyExplicit, nil, nil); !errors.Is(err, manager.ErrAmbiguousPackageManager) {
+		t.Errorf("ResolveInstall(explicit) error = %+v, want %+v", err, manager.ErrAmbiguousPackageManager)
+	}
+	actual, err := syspkg.ResolveInstall(pms, []string{"htop", "emacs"}, manager.InstallPolicyBest, nil, nil)
+	if expected := map[string][]string{"apt": {"htop"}}; err == nil || !reflect.DeepEqual(expected, actual) {
+		t.Errorf("ResolveInstall(htop, emacs) = %+v, %+v, want %+v and an error for emacs", actual, err, expected)
+	}
+}
@@ -1,5 +1,5 @@
 // Package syspkg provides a unified interface for interacting with multiple package management systems.
-// It allows you to query, install, and remove packages, and supports package managers like Apt, Snap, and Flatpak.
+// It allows you to query, install, and remove packages, and supports package managers like Apt, Pacman, Zypper, Snap, and Flatpak.
 //
 // To get started, create a new SysPkg instance by calling the New() function with the desired IncludeOptions.
 // After obtaining a SysPkg instance, you can use the FindPackageManagers() function to find available package managers
@@ -15,37 +15,133 @@
 //	    log.Fatal(err)
 //	}
 //	aptManager := sysPkg.GetPackageManager("apt")
+//
+// To run operations with all the selected package managers at once, with a context, use a Client created with
+// NewClient instead.
 package syspkg
 
 import (
 	"errors"
-	"log"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/bluet/syspkg/manager"
+	"github.com/bluet/syspkg/manager/apk"
 	"github.com/bluet/syspkg/manager/apt"
+	"github.com/bluet/syspkg/manager/brew"
+	"github.com/bluet/syspkg/manager/cargo"
+	"github.com/bluet/syspkg/manager/conda"
 	"github.com/bluet/syspkg/manager/flatpak"
+	"github.com/bluet/syspkg/manager/fwupd"
+	"github.com/bluet/syspkg/manager/gem"
+	"github.com/bluet/syspkg/manager/gobin"
+	"github.com/bluet/syspkg/manager/nix"
+	"github.com/bluet/syspkg/manager/npm"
+	"github.com/bluet/syspkg/manager/pacman"
+	"github.com/bluet/syspkg/manager/pip"
+	"github.com/bluet/syspkg/manager/platform"
+	"github.com/bluet/syspkg/manager/portage"
 	"github.com/bluet/syspkg/manager/snap"
-	// "github.com/bluet/syspkg/zypper"
+	"github.com/bluet/syspkg/manager/winget"
+	"github.com/bluet/syspkg/manager/zypper"
 	// "github.com/bluet/syspkg/dnf"
-	// "github.com/bluet/syspkg/apk"
 )
 
 // PackageInfo represents a package's information.
 type PackageInfo = manager.PackageInfo
 
 // IncludeOptions specifies which package managers to include when creating a SysPkg instance.
+// Package managers can be selected one by one, or by category with Categories.
 type IncludeOptions struct {
 	AllAvailable bool
 	Apk          bool
 	Apt          bool
+	Brew         bool
+	Cargo        bool
+	Conda        bool
 	Dnf          bool
 	Flatpak      bool
+	Fwupd        bool
+	Gem          bool
+	Gobin        bool
+	Nix          bool
+	Npm          bool
+	Pacman       bool
+	Pip          bool
+	Portage      bool
 	Snap         bool
+	Winget       bool
 	Zypper       bool
+
+	// Categories includes all package managers belonging to any of the given categories.
+	Categories []manager.Category
+
+	// PackageManagers are the package managers to use, by name, instead of finding them among the supported ones,
+	// such as a fixed set of package managers, or fakes in tests. The ones named after a supported package manager
+	// are included like it, and the others with AllAvailable only. They are probed with IsAvailable on every platform.
+	PackageManagers map[string]PackageManager
+
+	// Priority is the order of preference of the package managers, completed by manager.DefaultPriority, such as
+	// the priority of the configuration file: GetPackageManager("") returns the available one of highest priority.
+	Priority []string
+
+	// AvailabilityTTL is how long the SysPkg instance created with these options caches the availability of the
+	// package managers: DefaultAvailabilityTTL if zero, and never if negative. RefreshPackageManagers probes them
+	// again.
+	AvailabilityTTL time.Duration
+
+	// Platform is the platform the package managers are found on: the one given to New if nil, or the detected one.
+	// The system package managers of other distributions, such as pacman on Debian, are not probed.
+	Platform *platform.Platform
+
+	// Available probes the availability of the package managers by name instead of their IsAvailable method, if
+	// set, such as to find the package managers of a container rather than the ones of the host.
+	Available func(name string) bool
+
+	// Logger receives the logs of the discovery of the package managers; the default slog logger if nil.
+	Logger manager.Logger
 }
 
+// DefaultAvailabilityTTL is how long a SysPkg instance caches the availability of the package managers, unless
+// IncludeOptions.AvailabilityTTL is set.
+const DefaultAvailabilityTTL = 5 * time.Minute
+
 type sysPkgImpl struct {
 	pms map[string]PackageManager
+
+	// fixed are the package managers of IncludeOptions.PackageManagers, found instead of the supported ones if set.
+	fixed map[string]PackageManager
+
+	// priority is the order of preference of the package managers, for GetPackageManager("").
+	priority []string
+
+	// ttl is how long the availability of the package managers is cached, or never if negative.
+	ttl time.Duration
+
+	// available probes the availability of the package managers instead of IsAvailable, if set.
+	available func(name string) bool
+
+	// platform is the platform the package managers are found on, if given to New.
+	platform *platform.Platform
+
+	mu     sync.Mutex
+	probed map[string]probe
+}
+
+// probe is the cached availability of a package manager.
+type probe struct {
+	available bool
+	at        time.Time
+}
+
+// candidate is a package manager FindPackageManagers may find.
+type candidate struct {
+	managerName string
+	manager     PackageManager
+	category    manager.Category
+	include     bool
 }
 
 // make sure sysPkgImpl implements SysPkg
@@ -53,38 +149,77 @@ var _ SysPkg = (*sysPkgImpl)(nil)
 
 // New creates a new SysPkg instance with the specified IncludeOptions.
 func New(include IncludeOptions) (SysPkg, error) {
-	impl := &sysPkgImpl{}
+	impl, err := newSysPkg(include)
+	if err != nil {
+		return nil, err
+	}
+	return impl, nil
+}
+
+// newSysPkg returns the SysPkg instance of New, with the package managers it found.
+func newSysPkg(include IncludeOptions) (*sysPkgImpl, error) {
+	impl := &sysPkgImpl{fixed: include.PackageManagers, priority: include.Priority, ttl: include.AvailabilityTTL, available: include.Available, platform: include.Platform}
+	if impl.ttl == 0 {
+		impl.ttl = DefaultAvailabilityTTL
+	}
 	pms, err := impl.FindPackageManagers(include)
 	if err != nil {
 		return nil, err
 	}
 
-	return &sysPkgImpl{
-		pms: pms,
-	}, nil
+	impl.pms = pms
+	return impl, nil
 }
 
 // FindPackageManagers returns a map of available package managers based on the specified IncludeOptions.
+// The availability of each package manager is probed once per availability TTL of the instance.
 func (s *sysPkgImpl) FindPackageManagers(include IncludeOptions) (map[string]PackageManager, error) {
 	var pms = make(map[string]PackageManager)
-	managerList := []struct {
-		managerName string
-		manager     PackageManager
-		include     bool
-	}{
-		{"apt", &apt.PackageManager{}, include.Apt},
-		{"flatpak", &flatpak.PackageManager{}, include.Flatpak},
-		{"snap", &snap.PackageManager{}, include.Snap},
-		// {"apk", &apk.PackageManager{}, include.Apk},
+	managerList := []candidate{
+		{"apk", &apk.PackageManager{}, manager.CategorySystem, include.Apk},
+		{"apt", &apt.PackageManager{}, manager.CategorySystem, include.Apt},
+		{"brew", &brew.PackageManager{}, manager.CategoryUser, include.Brew},
+		{"cargo", &cargo.PackageManager{}, manager.CategoryLanguage, include.Cargo},
+		{"conda", &conda.PackageManager{}, manager.CategoryLanguage, include.Conda},
+		{"flatpak", &flatpak.PackageManager{}, manager.CategoryUniversal, include.Flatpak},
+		{"fwupd", &fwupd.PackageManager{}, manager.CategoryFirmware, include.Fwupd},
+		{"gem", &gem.PackageManager{}, manager.CategoryLanguage, include.Gem},
+		{"gobin", &gobin.PackageManager{}, manager.CategoryLanguage, include.Gobin},
+		{"nix", &nix.PackageManager{}, manager.CategoryUser, include.Nix},
+		{"npm", &npm.PackageManager{}, manager.CategoryLanguage, include.Npm},
+		{"pacman", &pacman.PackageManager{}, manager.CategorySystem, include.Pacman},
+		{"pip", &pip.PackageManager{}, manager.CategoryLanguage, include.Pip},
+		{"portage", &portage.PackageManager{}, manager.CategorySystem, include.Portage},
+		{"snap", &snap.PackageManager{}, manager.CategoryUniversal, include.Snap},
+		{"winget", &winget.PackageManager{}, manager.CategorySystem, include.Winget},
+		{"zypper", &zypper.PackageManager{}, manager.CategorySystem, include.Zypper},
 		// {"dnf", &dnf.PackageManager{}, include.Dnf},
-		// {"zypper", &zypper.PackageManager{}, include.Zypper},
+	}
+	if s.fixed != nil {
+		managerList = fixedCandidates(s.fixed, managerList)
 	}
 
+	logger := include.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	p := include.Platform
+	if p == nil {
+		p = s.platform
+	}
+	if p == nil {
+		detected := platform.Detect()
+		p = &detected
+	}
 	for _, m := range managerList {
-		if include.AllAvailable || m.include {
-			if m.manager.IsAvailable() {
+		if include.AllAvailable || m.include || slices.Contains(include.Categories, m.category) {
+			if s.fixed == nil && !p.Supports(m.managerName) {
+				logger.Debug("Package manager is not probed on this platform", "package_manager", m.managerName, "platform", p.ID)
+				continue
+			}
+			if s.isAvailable(m.managerName, m.manager) {
 				pms[m.managerName] = m.manager
-				log.Printf("%s manager is available", m.managerName)
+				logger.Debug("Package manager is available", "package_manager", m.managerName)
 			}
 		}
 	}
@@ -96,13 +231,59 @@ func (s *sysPkgImpl) FindPackageManagers(include IncludeOptions) (map[string]Pac
 	return pms, nil
 }
 
-// GetPackageManager returns a PackageManager instance by its name (e.g., "apt", "snap", "flatpak", etc.).
+// fixedCandidates returns the candidates of the fixed package managers, with the category and the inclusion of the
+// supported package managers of the same name, and included only by AllAvailable otherwise.
+func fixedCandidates(fixed map[string]PackageManager, supported []candidate) []candidate {
+	var candidates []candidate
+	for name, pm := range fixed {
+		c := candidate{managerName: name, manager: pm}
+		if i := slices.IndexFunc(supported, func(m candidate) bool { return m.managerName == name }); i >= 0 {
+			c.category, c.include = supported[i].category, supported[i].include
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// isAvailable returns the availability of a package manager, probed with IsAvailable, or IncludeOptions.Available,
+// unless cached.
+func (s *sysPkgImpl) isAvailable(name string, pm PackageManager) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.probed[name]; ok && s.ttl >= 0 && time.Since(p.at) < s.ttl {
+		return p.available
+	}
+	var available bool
+	if s.available != nil {
+		available = s.available(name)
+	} else {
+		available = pm.IsAvailable()
+	}
+	if s.probed == nil {
+		s.probed = make(map[string]probe)
+	}
+	s.probed[name] = probe{available: available, at: time.Now()}
+	return available
+}
+
+// GetPackageManager returns a PackageManager instance by its name (e.g., "apt", "snap", "flatpak", etc.), or the
+// available one of highest priority if name is empty.
 func (s *sysPkgImpl) GetPackageManager(name string) PackageManager {
+	if name == "" {
+		if order := InstallOrder(s.pms, s.priority); len(order) > 0 {
+			return s.pms[order[0]]
+		}
+	}
 	return s.pms[name]
 }
 
-// RefreshPackageManagers refreshes the internal list of available package managers, and returns the new list.
+// RefreshPackageManagers probes the availability of the package managers again, refreshes the internal list of
+// available package managers, and returns the new list.
 func (s *sysPkgImpl) RefreshPackageManagers(include IncludeOptions) (map[string]PackageManager, error) {
+	s.mu.Lock()
+	s.probed = nil
+	s.mu.Unlock()
+
 	pms, err := s.FindPackageManagers(include)
 	if err != nil {
 		return nil, err
@@ -111,3 +292,47 @@ func (s *sysPkgImpl) RefreshPackageManagers(include IncludeOptions) (map[string]
 	s.pms = pms
 	return pms, nil
 }
+
+// CapabilitiesOf returns the operations and options supported by a package manager: the ones it reports if it
+// implements CapabilityReporter, otherwise the optional interfaces it implements, assuming that its core operations work.
+func CapabilitiesOf(pm PackageManager) manager.Capabilities {
+	if r, ok := pm.(CapabilityReporter); ok {
+		return r.Capabilities()
+	}
+
+	_, upgrade := pm.(Upgrader)
+	_, downgrade := pm.(Downgrader)
+	_, hold := pm.(Holder)
+	_, security := pm.(SecurityUpdateLister)
+	_, dependencies := pm.(DependencyQuerier)
+	_, fileOwner := pm.(FileOwnerQuerier)
+	_, fileList := pm.(FileLister)
+	_, keys := pm.(KeyManager)
+	_, download := pm.(Downloader)
+	_, packageNames := pm.(PackageNameLister)
+	_, localInstall := pm.(LocalInstaller)
+	_, repositories := pm.(RepositoryManager)
+	_, reboot := pm.(RebootChecker)
+	_, verify := pm.(Verifier)
+	_, groups := pm.(GroupManager)
+	return manager.Capabilities{
+		Search:          true,
+		Delete:          true,
+		ListUpgradable:  true,
+		Upgrade:         upgrade,
+		Downgrade:       downgrade,
+		Hold:            hold,
+		SecurityUpdates: security,
+		Dependencies:    dependencies,
+		FileOwner:       fileOwner,
+		FileList:        fileList,
+		Keys:            keys,
+		Download:        download,
+		PackageNames:    packageNames,
+		LocalInstall:    localInstall,
+		Repositories:    repositories,
+		Reboot:          reboot,
+		Verify:          verify,
+		Groups:          groups,
+	}
+}
@@ -3,9 +3,11 @@ package syspkg_test
 
 import (
 	"log"
+	"reflect"
 	"testing"
 
 	"github.com/bluet/syspkg"
+	"github.com/bluet/syspkg/manager/platform"
 	"github.com/bluet/syspkg/osinfo"
 )
 
@@ -134,3 +136,99 @@ func TestNewPackageManager(t *testing.T) {
 	// 	t.Fatal("NewPackageManager() returned a nil manager")
 	// }
 }
+
+func TestFindPackageManagersPlatform(t *testing.T) {
+	// the system package managers of other distributions are not probed, even if installed
+	alpine := &platform.Platform{OS: "linux", ID: "alpine"}
+	s, err := syspkg.New(syspkg.IncludeOptions{Apt: true, Pacman: true, Zypper: true, Platform: alpine})
+	if err == nil {
+		t.Errorf("New() = %+v, want no package manager found on %s", s, alpine.ID)
+	}
+}
+
+// probedPackageManager is a package manager counting the probes of its availability.
+type probedPackageManager struct {
+	syspkg.PackageManager
+	available bool
+	probes    int
+}
+
+func (pm *probedPackageManager) IsAvailable() bool {
+	pm.probes++
+	return pm.available
+}
+
+func TestFindPackageManagersCached(t *testing.T) {
+	apt := &probedPackageManager{available: true}
+	custom := &probedPackageManager{available: true}
+	missing := &probedPackageManager{}
+	fixed := map[string]syspkg.PackageManager{"apt": apt, "custom": custom, "missing": missing}
+
+	s, err := syspkg.New(syspkg.IncludeOptions{Apt: true, PackageManagers: fixed})
+	if err != nil {
+		t.Fatalf("New() error = %+v", err)
+	}
+	if s.GetPackageManager("apt") != apt || s.GetPackageManager("custom") != nil {
+		t.Errorf("New() = %+v, want only the fixed apt", s)
+	}
+
+	pms, err := s.FindPackageManagers(syspkg.IncludeOptions{AllAvailable: true})
+	if err != nil {
+		t.Fatalf("FindPackageManagers() error = %+v", err)
+	}
+	if expected := map[string]syspkg.PackageManager{"apt": apt, "custom": custom}; !reflect.DeepEqual(pms, expected) {
+		t.Errorf("FindPackageManagers() = %+v, want %+v", pms, expected)
+	}
+	if apt.probes != 1 || custom.probes != 1 || missing.probes != 1 {
+		t.Errorf("FindPackageManagers() probed %d, %d and %d times, want the cached availability", apt.probes, custom.probes, missing.probes)
+	}
+
+	if _, err := s.RefreshPackageManagers(syspkg.IncludeOptions{AllAvailable: true}); err != nil {
+		t.Fatalf("RefreshPackageManagers() error = %+v", err)
+	}
+	if apt.probes != 2 || missing.probes != 2 {
+		t.Errorf("RefreshPackageManagers() probed %d and %d times, want the package managers probed again", apt.probes, missing.probes)
+	}
+}
+
+func TestFindPackageManagersAvailable(t *testing.T) {
+	// the package managers are probed with Available instead of IsAvailable, such as inside a container
+	apt := &probedPackageManager{available: true}
+	apk := &probedPackageManager{}
+	fixed := map[string]syspkg.PackageManager{"apt": apt, "apk": apk}
+	available := func(name string) bool { return name == "apk" }
+
+	s, err := syspkg.New(syspkg.IncludeOptions{AllAvailable: true, PackageManagers: fixed, Available: available})
+	if err != nil {
+		t.Fatalf("New() error = %+v", err)
+	}
+	if s.GetPackageManager("apk") != apk || s.GetPackageManager("apt") != nil {
+		t.Errorf("New() = %+v, want only apk", s)
+	}
+	if apt.probes != 0 || apk.probes != 0 {
+		t.Errorf("New() probed IsAvailable %d and %d times, want no probes", apt.probes, apk.probes)
+	}
+}
+
+func TestGetPackageManagerPriority(t *testing.T) {
+	apt := &probedPackageManager{available: true}
+	pip := &probedPackageManager{available: true}
+	fixed := map[string]syspkg.PackageManager{"apt": apt, "pip": pip}
+
+	tests := []struct {
+		priority []string
+		expected syspkg.PackageManager
+	}{
+		{nil, apt},
+		{[]string{"pip"}, pip},
+	}
+	for _, tt := range tests {
+		s, err := syspkg.New(syspkg.IncludeOptions{AllAvailable: true, PackageManagers: fixed, Priority: tt.priority})
+		if err != nil {
+			t.Fatalf("New() error = %+v", err)
+		}
+		if actual := s.GetPackageManager(""); actual != tt.expected {
+			t.Errorf("GetPackageManager(\"\") with priority %v = %+v, want %+v", tt.priority, actual, tt.expected)
+		}
+	}
+}
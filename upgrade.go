@@ -0,0 +1,27 @@
+package syspkg
+
+import "github.com/bluet/syspkg/manager"
+
+// SelectUpgrades returns the upgradable packages of pm that policy allows upgrading, and the ones it excludes: the
+// security updates listed with its SecurityUpdateLister when policy.SecurityOnly is set, otherwise the packages listed
+// by ListUpgradable. It returns manager.ErrOperationNotSupported if policy.SecurityOnly is set and pm can't tell
+// security updates apart, and an error wrapping manager.ErrUpgradeDenied if the policy denies the upgrade. The
+// maintenance windows are not checked, see manager.UpgradePolicy.Allow. Policies that are Selective must then upgrade
+// the selected packages with the Upgrader of pm, rather than with UpgradeAll.
+func SelectUpgrades(pm PackageManager, policy *manager.UpgradePolicy, opts *manager.Options) ([]manager.PackageInfo, []manager.PackageInfo, error) {
+	var upgradable []manager.PackageInfo
+	var err error
+	if policy != nil && policy.SecurityOnly {
+		lister, ok := pm.(SecurityUpdateLister)
+		if !ok {
+			return nil, nil, manager.ErrOperationNotSupported
+		}
+		upgradable, err = lister.ListSecurityUpdates(opts)
+	} else {
+		upgradable, err = pm.ListUpgradable(opts)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return policy.Select(upgradable)
+}